@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/datawire/dlib/dlog"
@@ -36,7 +37,7 @@ func (fs *fwdState) Target() InterceptTarget {
 	return fs.intercept
 }
 
-func (fs *fwdState) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, headers http.Header) (*restapi.InterceptInfo, error) {
+func (fs *fwdState) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, _ netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
 	// The OSS agent is either intercepting or it isn't. There's no way to tell what it is that's being intercepted.
 	fw := fs.forwarder
 	if containerPort == 0 {
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"net/http"
+	"net/netip"
 
 	"github.com/blang/semver/v4"
 	"github.com/puzpuzpuz/xsync/v3"
@@ -45,7 +46,7 @@ type ContainerState interface {
 type InterceptState interface {
 	State
 	Target() InterceptTarget
-	InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, headers http.Header) (*restapi.InterceptInfo, error)
+	InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, sourceIP netip.Addr, headers http.Header) (*restapi.InterceptInfo, error)
 }
 
 // State of the Traffic Agent.
@@ -126,14 +127,14 @@ func (s *state) HandleIntercepts(ctx context.Context, iis []*manager.InterceptIn
 	return rs
 }
 
-func (s *state) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, headers http.Header) (*restapi.InterceptInfo, error) {
+func (s *state) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, sourceIP netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
 	if containerPort == 0 && len(s.interceptStates) == 1 {
 		containerPort = s.interceptStates[0].Target().ContainerPort()
 	}
 	for _, is := range s.interceptStates {
 		ic := is.Target()
 		if containerPort == ic.ContainerPort() && ic.Protocol() == core.ProtocolTCP {
-			return is.InterceptInfo(ctx, callerID, path, containerPort, headers)
+			return is.InterceptInfo(ctx, callerID, path, containerPort, sourceIP, headers)
 		}
 	}
 
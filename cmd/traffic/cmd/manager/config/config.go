@@ -30,6 +30,10 @@ type Watcher interface {
 	Run(ctx context.Context) error
 	GetClientConfigYaml() []byte
 	GetAgentEnv() AgentEnv
+
+	// GetConfigVersion returns the resourceVersion of the last observed traffic-manager ConfigMap, or
+	// an empty string if no ConfigMap has been observed or if it has been deleted.
+	GetConfigVersion() string
 }
 
 type AgentEnv struct {
@@ -40,6 +44,13 @@ type config struct {
 	sync.RWMutex
 	namespace string
 
+	// data is the data of the ConfigMap that was last seen. It's retained so that the
+	// configuration can be refreshed when the manager's environment changes.
+	data map[string]string
+
+	// configVersion is the resourceVersion of the last observed ConfigMap.
+	configVersion string
+
 	clientYAML []byte
 	agentEnv   AgentEnv
 }
@@ -57,23 +68,35 @@ func (c *config) Run(ctx context.Context) error {
 	// The WatchConfig will perform a http GET call to the kubernetes API server, and that connection will not remain open forever
 	// so when it closes, the watch must start over. This goes on until the context is cancelled.
 	api := k8sapi.GetK8sInterface(ctx).CoreV1()
+	envCh := managerutil.SubscribeEnv(ctx)
 	for ctx.Err() == nil {
 		w, err := api.ConfigMaps(c.namespace).Watch(ctx, meta.SingleObject(meta.ObjectMeta{Name: cfgConfigMapName}))
 		if err != nil {
 			return fmt.Errorf("unable to create configmap watcher for %s.%s: %v", cfgConfigMapName, c.namespace, err)
 		}
-		if !c.configMapEventHandler(ctx, w.ResultChan()) {
+		if !c.configMapEventHandler(ctx, w.ResultChan(), envCh) {
 			return nil
 		}
 	}
 	return nil
 }
 
-func (c *config) configMapEventHandler(ctx context.Context, evCh <-chan watch.Event) bool {
+func (c *config) configMapEventHandler(ctx context.Context, evCh <-chan watch.Event, envCh <-chan struct{}) bool {
 	for {
 		select {
 		case <-ctx.Done():
 			return false
+		case _, ok := <-envCh:
+			if !ok {
+				// The subscription ends when the context is done.
+				return false
+			}
+			// The environment has changed, so the client config must be amended again.
+			dlog.Debug(ctx, "Environment changed, refreshing config")
+			c.RLock()
+			version, data := c.configVersion, c.data
+			c.RUnlock()
+			c.refreshFile(ctx, version, data)
 		case event, ok := <-evCh:
 			if !ok {
 				return true // restart watcher
@@ -82,12 +105,12 @@ func (c *config) configMapEventHandler(ctx context.Context, evCh <-chan watch.Ev
 			case watch.Deleted:
 				if m, ok := event.Object.(*core.ConfigMap); ok {
 					dlog.Debugf(ctx, "%s %s", event.Type, m.Name)
-					c.refreshFile(ctx, nil)
+					c.refreshFile(ctx, "", nil)
 				}
 			case watch.Added, watch.Modified:
 				if m, ok := event.Object.(*core.ConfigMap); ok {
 					dlog.Debugf(ctx, "%s %s", event.Type, m.Name)
-					c.refreshFile(ctx, m.Data)
+					c.refreshFile(ctx, m.ResourceVersion, m.Data)
 				}
 			}
 		}
@@ -109,11 +132,15 @@ func AmendClientConfig(ctx context.Context, cfg client.Config) bool {
 	return false
 }
 
-func (c *config) refreshFile(ctx context.Context, data map[string]string) {
+func (c *config) refreshFile(ctx context.Context, version string, data map[string]string) {
 	c.Lock()
+	c.configVersion = version
+	c.data = data
 	if yml, ok := data[clientConfigFileName]; ok {
+		yml = interpolate(ctx, yml)
 		c.clientYAML = []byte(yml)
-		cfg, err := client.ParseConfigYAML(ctx, clientConfigFileName, c.clientYAML)
+		pCtx := client.WithStrictConfig(ctx, managerutil.GetEnv(ctx).ClientStrictConfig)
+		cfg, err := client.ParseConfigYAML(pCtx, clientConfigFileName, c.clientYAML)
 		if err != nil {
 			dlog.Errorf(ctx, "failed to unmarshal YAML from %s: %v", clientConfigFileName, err)
 		} else if AmendClientConfigFunc(ctx, cfg) {
@@ -141,8 +168,18 @@ func (c *config) refreshFile(ctx context.Context, data map[string]string) {
 	c.Unlock()
 }
 
-func (c *config) GetAgentEnv() AgentEnv {
-	return c.agentEnv
+func (c *config) GetAgentEnv() (ret AgentEnv) {
+	c.RLock()
+	ret = c.agentEnv
+	c.RUnlock()
+	return
+}
+
+func (c *config) GetConfigVersion() (ret string) {
+	c.RLock()
+	ret = c.configVersion
+	c.RUnlock()
+	return
 }
 
 func (c *config) GetClientConfigYaml() (ret []byte) {
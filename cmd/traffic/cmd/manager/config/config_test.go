@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+func Test_config_configMapEventHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagedNamespaces: []string{"a"}})
+	envCh := managerutil.SubscribeEnv(ctx)
+	evCh := make(chan watch.Event)
+	c := &config{}
+
+	done := make(chan bool)
+	go func() {
+		done <- c.configMapEventHandler(ctx, evCh, envCh)
+	}()
+
+	cm := func(version string, data map[string]string) *core.ConfigMap {
+		return &core.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: cfgConfigMapName, ResourceVersion: version}, Data: data}
+	}
+
+	evCh <- watch.Event{Type: watch.Added, Object: cm("1", map[string]string{
+		clientConfigFileName: "timeouts:\n  agentInstall: 2m\n",
+	})}
+	assert.Eventually(t, func() bool {
+		cfg, err := client.ParseConfigYAML(ctx, clientConfigFileName, c.GetClientConfigYaml())
+		return err == nil && assert.ObjectsAreEqual([]string{"a"}, cfg.Cluster().MappedNamespaces)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "1", c.GetConfigVersion())
+
+	// A change of the managed namespaces in the environment amends the client config with the new namespaces.
+	managerutil.UpdateEnv(ctx, func(env *managerutil.Env) {
+		env.ManagedNamespaces = []string{"a", "b"}
+	})
+	assert.Eventually(t, func() bool {
+		cfg, err := client.ParseConfigYAML(ctx, clientConfigFileName, c.GetClientConfigYaml())
+		return err == nil && assert.ObjectsAreEqual([]string{"a", "b"}, cfg.Cluster().MappedNamespaces)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "1", c.GetConfigVersion())
+
+	// The handler returns when the context is done and the subscription channel is closed.
+	cancel()
+	select {
+	case restart := <-done:
+		assert.False(t, restart)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "handler did not return")
+	}
+}
+
+func Test_interpolate(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{
+		ManagerNamespace:  "ambassador",
+		ManagedNamespaces: []string{"a", "b"},
+		ServerPort:        8081,
+	})
+	tests := []struct {
+		name string
+		yml  string
+		want string
+	}{
+		{
+			name: "resolved",
+			yml:  "cluster:\n  defaultManagerNamespace: ${MANAGER_NAMESPACE}\n  mappedNamespaces: [${MANAGED_NAMESPACES}]\n",
+			want: "cluster:\n  defaultManagerNamespace: ambassador\n  mappedNamespaces: [a,b]\n",
+		},
+		{
+			name: "unresolved",
+			yml:  "images:\n  registry: ${REGISTRY}/${MANAGER_NAMESPACE}\n",
+			want: "images:\n  registry: ${REGISTRY}/ambassador\n",
+		},
+		{
+			name: "escaped",
+			yml:  "images:\n  agentImage: $${MANAGER_NAMESPACE}:${SERVER_PORT}\n",
+			want: "images:\n  agentImage: ${MANAGER_NAMESPACE}:8081\n",
+		},
+		{
+			name: "no placeholders",
+			yml:  "images:\n  agentImage: tel2:$version$\n",
+			want: "images:\n  agentImage: tel2:$version$\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, interpolate(ctx, tt.yml))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+)
+
+// placeholderRx matches a ${NAME} placeholder, optionally escaped with a leading '$'.
+var placeholderRx = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)}`) //nolint:gochecknoglobals // constant
+
+// placeholders is the set of placeholders that can be used in the client.yaml of the traffic-manager
+// ConfigMap, and the functions that resolve them from the manager's environment.
+var placeholders = map[string]func(*managerutil.Env) string{ //nolint:gochecknoglobals // constant
+	"MANAGER_NAMESPACE":  func(env *managerutil.Env) string { return env.ManagerNamespace },
+	"MANAGED_NAMESPACES": func(env *managerutil.Env) string { return strings.Join(env.ManagedNamespaces, ",") },
+	"SERVER_PORT":        func(env *managerutil.Env) string { return strconv.Itoa(int(env.ServerPort)) },
+	"AGENT_PORT":         func(env *managerutil.Env) string { return strconv.Itoa(int(env.AgentPort)) },
+}
+
+// interpolate replaces the ${NAME} placeholders in the given YAML with the values of the manager's
+// environment. Only placeholders with a known name are replaced. Others are logged and retained
+// verbatim. A placeholder is escaped by doubling its '$', so "$${NAME}" becomes "${NAME}".
+func interpolate(ctx context.Context, yml string) string {
+	env := managerutil.GetEnv(ctx)
+	return placeholderRx.ReplaceAllStringFunc(yml, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		name := m[2 : len(m)-1]
+		if resolve, ok := placeholders[name]; ok {
+			return resolve(env)
+		}
+		dlog.Warnf(ctx, "unable to resolve placeholder %s in %s", m, clientConfigFileName)
+		return m
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/blang/semver/v4"
 
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 )
 
 func validateClient(client *rpc.ClientInfo) string {
@@ -29,6 +30,13 @@ func validateClient(client *rpc.ClientInfo) string {
 	if sv.Major < 2 || sv.Major == 2 && sv.Minor < 6 {
 		return "client version must be at least 2.6.0"
 	}
+	return validateMetadata(client.Metadata)
+}
+
+func validateMetadata(md map[string]string) string {
+	if err := client.ValidateSessionMetadata(md); err != nil {
+		return err.Error()
+	}
 	return ""
 }
 
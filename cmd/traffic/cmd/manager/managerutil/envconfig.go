@@ -7,6 +7,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -71,6 +73,11 @@ type Env struct {
 	ClientDnsIncludeSuffixes             []string       `env:"CLIENT_DNS_INCLUDE_SUFFIXES,       		parser=split-trim,  default="`
 	ClientConnectionTTL                  time.Duration  `env:"CLIENT_CONNECTION_TTL,              		parser=time.ParseDuration"`
 
+	ClientMinVersion         *semver.Version `env:"CLIENT_MIN_VERSION,         parser=version, default="`
+	ClientRecommendedVersion *semver.Version `env:"CLIENT_RECOMMENDED_VERSION, parser=version, default="`
+	ClientAllowClientList    bool            `env:"CLIENT_ALLOW_CLIENT_LIST,   parser=bool,    default=false"`
+	ClientStrictConfig       bool            `env:"CLIENT_STRICT_CONFIG,       parser=bool,    default=false"`
+
 	EnabledWorkloadKinds []workload.Kind `env:"ENABLED_WORKLOAD_KINDS, parser=split-trim, default=Deployment StatefulSet ReplicaSet"`
 
 	// For testing only
@@ -306,13 +313,64 @@ func LoadEnvInto(env any, lookupFunc func(string) (string, bool)) (any, error) {
 	return ptr.Interface(), nil
 }
 
+// envHolder holds the current Env and the channels of those that subscribe to changes of it.
+type envHolder struct {
+	env         atomic.Pointer[Env]
+	lock        sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
 func WithEnv(ctx context.Context, env *Env) context.Context {
-	return context.WithValue(ctx, envKey{}, env)
+	h := &envHolder{}
+	h.env.Store(env)
+	return context.WithValue(ctx, envKey{}, h)
 }
 
+// GetEnv returns the current Env. The returned value is replaced, not modified, when the Env is updated
+// using UpdateEnv.
 func GetEnv(ctx context.Context) *Env {
-	if env, ok := ctx.Value(envKey{}).(*Env); ok {
-		return env
+	if h, ok := ctx.Value(envKey{}).(*envHolder); ok {
+		return h.env.Load()
 	}
 	panic("no Env has been set")
 }
+
+// UpdateEnv calls the given function with a copy of the current Env, makes the modified copy the current
+// Env, and notifies all subscribers of the change.
+func UpdateEnv(ctx context.Context, update func(*Env)) {
+	h := ctx.Value(envKey{}).(*envHolder)
+	h.lock.Lock()
+	env := *h.env.Load()
+	update(&env)
+	h.env.Store(&env)
+	for ch := range h.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// The subscriber has a pending notification already.
+		}
+	}
+	h.lock.Unlock()
+}
+
+// SubscribeEnv returns a channel that receives a value when the Env has been updated. Notifications
+// are coalesced, so one value may represent several updates. The subscription ends, and the channel
+// is closed, when the given context is done.
+func SubscribeEnv(ctx context.Context) <-chan struct{} {
+	h := ctx.Value(envKey{}).(*envHolder)
+	ch := make(chan struct{}, 1)
+	h.lock.Lock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[chan struct{}]struct{})
+	}
+	h.subscribers[ch] = struct{}{}
+	h.lock.Unlock()
+	go func() {
+		<-ctx.Done()
+		h.lock.Lock()
+		delete(h.subscribers, ch)
+		h.lock.Unlock()
+		close(ch)
+	}()
+	return ch
+}
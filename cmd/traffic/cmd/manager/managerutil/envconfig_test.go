@@ -107,3 +107,39 @@ func TestEnvconfig(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateEnv(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	orig := &managerutil.Env{ManagedNamespaces: []string{"a"}}
+	ctx = managerutil.WithEnv(ctx, orig)
+	ch := managerutil.SubscribeEnv(ctx)
+
+	managerutil.UpdateEnv(ctx, func(env *managerutil.Env) {
+		env.ManagedNamespaces = []string{"a", "b"}
+	})
+	managerutil.UpdateEnv(ctx, func(env *managerutil.Env) {
+		env.ManagedNamespaces = append(env.ManagedNamespaces, "c")
+	})
+	assert.Equal(t, []string{"a", "b", "c"}, managerutil.GetEnv(ctx).ManagedNamespaces)
+	assert.Equal(t, []string{"a"}, orig.ManagedNamespaces, "the original Env must not be modified")
+
+	// Notifications are coalesced.
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+	}
+	select {
+	case <-ch:
+		t.Fatal("notifications were not coalesced")
+	default:
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok, "channel not closed when context is done")
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed when context is done")
+	}
+}
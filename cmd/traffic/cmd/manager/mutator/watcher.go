@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"slices"
-	"strings"
 	"sync"
 	"time"
 
@@ -251,8 +250,8 @@ func (c *configWatcher) triggerRollout(ctx context.Context, wl k8sapi.Workload,
 		return
 	}
 
-	restartAnnotation := generateRestartAnnotationPatch(wl.GetPodTemplate())
-	if err := wl.Patch(ctx, types.JSONPatchType, []byte(restartAnnotation)); err != nil {
+	restartAnnotation := workload.RestartAnnotationPatch(wl.GetPodTemplate(), time.Now())
+	if err := wl.Patch(ctx, types.JSONPatchType, restartAnnotation); err != nil {
 		err = fmt.Errorf("unable to patch %s %s.%s: %v", wl.GetKind(), wl.GetName(), wl.GetNamespace(), err)
 		dlog.Error(ctx, err)
 		return
@@ -260,32 +259,6 @@ func (c *configWatcher) triggerRollout(ctx context.Context, wl k8sapi.Workload,
 	dlog.Infof(ctx, "Successfully rolled out %s.%s", wl.GetName(), wl.GetNamespace())
 }
 
-// generateRestartAnnotationPatch generates a JSON patch that adds or updates the annotation
-// We need to use this particular patch type because argo-rollouts does not support strategic merge patches.
-func generateRestartAnnotationPatch(podTemplate *core.PodTemplateSpec) string {
-	basePointer := "/spec/template/metadata/annotations"
-	pointer := fmt.Sprintf(
-		basePointer+"/%s",
-		strings.ReplaceAll(workload.AnnRestartedAt, "/", "~1"),
-	)
-
-	if _, ok := podTemplate.Annotations[workload.AnnRestartedAt]; ok {
-		return fmt.Sprintf(
-			`[{"op": "replace", "path": "%s", "value": "%s"}]`, pointer, time.Now().Format(time.RFC3339),
-		)
-	}
-
-	if len(podTemplate.Annotations) == 0 {
-		return fmt.Sprintf(
-			`[{"op": "add", "path": "%s", "value": {}}, {"op": "add", "path": "%s", "value": "%s"}]`, basePointer, pointer, time.Now().Format(time.RFC3339),
-		)
-	}
-
-	return fmt.Sprintf(
-		`[{"op": "add", "path": "%s", "value": "%s"}]`, pointer, time.Now().Format(time.RFC3339),
-	)
-}
-
 func triggerRolloutReplicaSet(ctx context.Context, wl k8sapi.Workload, rs *appsv1.ReplicaSet) {
 	// Rollout of a replicatset will not recreate the pods. In order for that to happen, the
 	// set must be scaled down and then up again.
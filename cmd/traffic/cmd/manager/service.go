@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -136,9 +137,18 @@ func (s *service) runConfigWatcher(ctx context.Context) error {
 	return s.configWatcher.Run(ctx)
 }
 
-// Version returns the version information of the Manager.
-func (*service) Version(context.Context, *empty.Empty) (*rpc.VersionInfo2, error) {
-	return &rpc.VersionInfo2{Name: DisplayName, Version: version.Version}, nil
+// Version returns the version information of the Manager, along with the minimum and recommended
+// client versions when they have been configured.
+func (*service) Version(ctx context.Context, _ *empty.Empty) (*rpc.VersionInfo2, error) {
+	vi := &rpc.VersionInfo2{Name: DisplayName, Version: version.Version}
+	env := managerutil.GetEnv(ctx)
+	if v := env.ClientMinVersion; v != nil {
+		vi.MinClientVersion = v.String()
+	}
+	if v := env.ClientRecommendedVersion; v != nil {
+		vi.RecommendedClientVersion = v.String()
+	}
+	return vi, nil
 }
 
 func (s *service) GetAgentImageFQN(ctx context.Context, _ *empty.Empty) (*rpc.AgentImageFQN, error) {
@@ -245,6 +255,26 @@ func (s *service) GetClientConfig(ctx context.Context, _ *empty.Empty) (*rpc.CLI
 	}, nil
 }
 
+// GetAgentEnv returns the settings that are applied to the environment of intercepted containers.
+func (s *service) GetAgentEnv(ctx context.Context, session *rpc.SessionInfo) (*rpc.AgentEnv, error) {
+	ctx = managerutil.WithSessionInfo(ctx, session)
+	dlog.Debug(ctx, "GetAgentEnv called")
+
+	return &rpc.AgentEnv{
+		Excluded: s.configWatcher.GetAgentEnv().Excluded,
+	}, nil
+}
+
+// GetWorkloadInterceptHistory returns the intercepts of a workload that the traffic-manager has recorded, most recent first.
+func (s *service) GetWorkloadInterceptHistory(ctx context.Context, request *rpc.WorkloadInterceptHistoryRequest) (*rpc.WorkloadInterceptHistory, error) {
+	ctx = managerutil.WithSessionInfo(ctx, request.GetSession())
+	dlog.Debugf(ctx, "GetWorkloadInterceptHistory called: %s.%s", request.Name, request.Namespace)
+	if s.state.GetClient(request.Session.GetSessionId()) == nil {
+		return nil, status.Errorf(codes.NotFound, "Client session %q not found", request.Session.GetSessionId())
+	}
+	return &rpc.WorkloadInterceptHistory{Entries: s.state.GetWorkloadInterceptHistory(request.Name, request.Namespace)}, nil
+}
+
 // Remain indicates that the session is still valid.
 func (s *service) Remain(ctx context.Context, req *rpc.RemainRequest) (*empty.Empty, error) {
 	// ctx = WithSessionInfo(ctx, req.GetSession())
@@ -270,6 +300,23 @@ func (s *service) Depart(ctx context.Context, session *rpc.SessionInfo) (*empty.
 	return &empty.Empty{}, nil
 }
 
+// ListClients returns the clients that have a session with the manager. The caller must be a client with a
+// session, and the listing must be allowed by the manager's configuration.
+func (s *service) ListClients(ctx context.Context, session *rpc.SessionInfo) (*rpc.ConnectedClients, error) {
+	if err := checkCompat(ctx, "ListClients", "2.22.0"); err != nil {
+		return nil, err
+	}
+	ctx = managerutil.WithSessionInfo(ctx, session)
+	dlog.Debug(ctx, "ListClients called")
+	if s.state.GetClient(session.GetSessionId()) == nil {
+		return nil, status.Errorf(codes.NotFound, "Client session %q not found", session.GetSessionId())
+	}
+	if !managerutil.GetEnv(ctx).ClientAllowClientList {
+		return nil, status.Error(codes.PermissionDenied, "listing of connected clients is disabled by the traffic-manager")
+	}
+	return &rpc.ConnectedClients{Clients: s.state.GetConnectedClients()}, nil
+}
+
 // WatchAgentPods notifies a client of the set of known Agents.
 func (s *service) WatchAgentPods(session *rpc.SessionInfo, stream rpc.Manager_WatchAgentPodsServer) error {
 	ctx := managerutil.WithSessionInfo(stream.Context(), session)
@@ -366,6 +413,35 @@ func (s *service) WatchAgentsNS(request *rpc.AgentsRequest, stream rpc.Manager_W
 	return s.watchAgents(ctx, func(_ string, a *rpc.AgentInfo) bool { return slices.Contains(request.Namespaces, a.Namespace) }, stream)
 }
 
+// GetAgentVersions returns the name and version of the known agents in the namespaces given in the request.
+// A workload is listed once, even when it has several agents.
+func (s *service) GetAgentVersions(ctx context.Context, request *rpc.AgentsRequest) (*rpc.AgentVersions, error) {
+	if err := checkCompat(ctx, "GetAgentVersions", "2.22.0"); err != nil {
+		return nil, err
+	}
+	ctx = managerutil.WithSessionInfo(ctx, request.Session)
+	dlog.Debug(ctx, "GetAgentVersions called")
+	if s.state.GetClient(request.Session.GetSessionId()) == nil {
+		return nil, status.Errorf(codes.NotFound, "Client session %q not found", request.Session.GetSessionId())
+	}
+	agents := s.state.LoadMatchingAgents(func(_ string, a *rpc.AgentInfo) bool { return slices.Contains(request.Namespaces, a.Namespace) })
+	avs := make([]*rpc.AgentVersion, 0, len(agents))
+	for _, a := range agents {
+		avs = append(avs, &rpc.AgentVersion{Name: a.Name, Namespace: a.Namespace, Version: a.Version})
+	}
+	slices.SortFunc(avs, func(a, b *rpc.AgentVersion) int {
+		if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Version, b.Version)
+	})
+	avs = slices.CompactFunc(avs, func(a, b *rpc.AgentVersion) bool { return a.Name == b.Name && a.Namespace == b.Namespace })
+	return &rpc.AgentVersions{Agents: avs}, nil
+}
+
 func infosEqual(a, b *rpc.AgentInfo) bool {
 	if a == nil || b == nil {
 		return a == b
@@ -9,7 +9,9 @@ import (
 	"github.com/go-json-experiment/json"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/proto"
 	empty "google.golang.org/protobuf/types/known/emptypb"
@@ -24,6 +26,7 @@ import (
 	"github.com/datawire/dlib/dhttp"
 	"github.com/datawire/dlib/dlog"
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/config"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/mutator"
 	testdata "github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/test"
@@ -66,6 +69,13 @@ func TestConnect(t *testing.T) {
 	_, err = client.Depart(ctx, aliceSess1)
 	require.NoError(err)
 
+	// A client with invalid metadata is rejected
+
+	badClient := proto.Clone(testClients["alice"]).(*rpc.ClientInfo)
+	badClient.Metadata = map[string]string{"": "empty key"}
+	_, err = client.ArriveAsClient(ctx, badClient)
+	require.Equal(codes.InvalidArgument, status.Code(err))
+
 	// Alice arrives and sees no agents or intercepts
 
 	aliceSess2, err := client.ArriveAsClient(ctx, testClients["alice"])
@@ -152,6 +162,24 @@ func TestConnect(t *testing.T) {
 	require.Len(aSnapA.Agents, 3)
 	t.Logf("=> client[alice] agent snapshot = %s", dumps(aSnapA))
 
+	// Alice lists the agent versions. The two demo agents belong to the same workload.
+
+	avs, err := client.GetAgentVersions(ctx, &rpc.AgentsRequest{Session: aliceSess2, Namespaces: []string{"default"}})
+	require.NoError(err)
+	require.Len(avs.Agents, 2)
+	require.Equal("demo", avs.Agents[0].Name)
+	require.Equal("hello", avs.Agents[1].Name)
+	require.Equal("1", avs.Agents[1].Version)
+
+	avs, err = client.GetAgentVersions(ctx, &rpc.AgentsRequest{Session: aliceSess2, Namespaces: []string{"other"}})
+	require.NoError(err)
+	require.Empty(avs.Agents)
+
+	// An unknown session cannot list the agent versions.
+
+	_, err = client.GetAgentVersions(ctx, &rpc.AgentsRequest{Session: &rpc.SessionInfo{SessionId: "unknown"}, Namespaces: []string{"default"}})
+	require.Equal(codes.NotFound, status.Code(err))
+
 	// Alice remains
 
 	_, err = client.Remain(ctx, &rpc.RemainRequest{Session: aliceSess2})
@@ -424,3 +452,27 @@ func Test_hasDomainSuffix(t *testing.T) {
 		})
 	}
 }
+
+type fakeConfigWatcher struct {
+	config.Watcher
+	agentEnv config.AgentEnv
+}
+
+func (w *fakeConfigWatcher) GetAgentEnv() config.AgentEnv {
+	return w.agentEnv
+}
+
+func Test_service_GetAgentEnv(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	excluded := []string{"DATABASE_PASSWORD", "AWS_SECRET_ACCESS_KEY", "KUBERNETES_SERVICE_HOST"}
+	s := &service{configWatcher: &fakeConfigWatcher{agentEnv: config.AgentEnv{Excluded: excluded}}}
+
+	ae, err := s.GetAgentEnv(ctx, &rpc.SessionInfo{SessionId: "session"})
+	require.NoError(t, err)
+	require.Equal(t, excluded, ae.Excluded)
+
+	// The excluded variables are the ones that are removed from the environment of an intercept.
+	env := map[string]string{"DATABASE_PASSWORD": "secret", "DATABASE_HOST": "db", "KUBERNETES_SERVICE_HOST": "10.0.0.1"}
+	s.removeExcludedEnvVars(env)
+	require.Equal(t, map[string]string{"DATABASE_HOST": "db"}, env)
+}
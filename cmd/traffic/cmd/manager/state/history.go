@@ -0,0 +1,85 @@
+package state
+
+import (
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// maxWorkloadInterceptHistory is the number of intercepts that are recorded for each workload.
+const maxWorkloadInterceptHistory = 20
+
+type historyEntry struct {
+	interceptID string
+	entry       *rpc.InterceptHistoryEntry
+}
+
+// workloadInterceptHistory is the recorded intercepts of one workload, oldest first.
+type workloadInterceptHistory struct {
+	sync.Mutex
+	entries []historyEntry
+}
+
+func historyKey(name, namespace string) string {
+	return name + "." + namespace
+}
+
+// recordInterceptStarted adds the given intercept, created by the given client, to the history of its workload,
+// dropping the oldest entry when the history is full.
+func (s *state) recordInterceptStarted(client *rpc.ClientInfo, cept *rpc.InterceptInfo) {
+	spec := cept.Spec
+	h, _ := s.interceptHistory.LoadOrCompute(historyKey(spec.Agent, spec.Namespace), func() *workloadInterceptHistory {
+		return &workloadInterceptHistory{}
+	})
+	h.Lock()
+	defer h.Unlock()
+	if len(h.entries) >= maxWorkloadInterceptHistory {
+		h.entries = slices.Delete(h.entries, 0, len(h.entries)-maxWorkloadInterceptHistory+1)
+	}
+	h.entries = append(h.entries, historyEntry{
+		interceptID: cept.Id,
+		entry: &rpc.InterceptHistoryEntry{
+			Name:           spec.Name,
+			Client:         client.Name,
+			PortIdentifier: spec.PortIdentifier,
+			StartedAt:      timestamppb.Now(),
+		},
+	})
+}
+
+// recordInterceptEnded sets the end time of the given intercept in the history of its workload.
+func (s *state) recordInterceptEnded(cept *rpc.InterceptInfo) {
+	spec := cept.Spec
+	h, ok := s.interceptHistory.Load(historyKey(spec.Agent, spec.Namespace))
+	if !ok {
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if he := h.entries[i]; he.interceptID == cept.Id && he.entry.EndedAt == nil {
+			he.entry.EndedAt = timestamppb.Now()
+			return
+		}
+	}
+}
+
+// GetWorkloadInterceptHistory returns the recorded intercepts of the given workload, most recent first.
+func (s *state) GetWorkloadInterceptHistory(name, namespace string) []*rpc.InterceptHistoryEntry {
+	h, ok := s.interceptHistory.Load(historyKey(name, namespace))
+	if !ok {
+		return nil
+	}
+	h.Lock()
+	defer h.Unlock()
+	n := len(h.entries)
+	es := make([]*rpc.InterceptHistoryEntry, n)
+	for i, he := range h.entries {
+		es[n-1-i] = proto.Clone(he.entry).(*rpc.InterceptHistoryEntry)
+	}
+	return es
+}
@@ -155,7 +155,8 @@ func newSessionState(ctx context.Context, now time.Time) sessionState {
 
 type clientSessionState struct {
 	sessionState
-	pool *tunnel.Pool
+	pool        *tunnel.Pool
+	connectedAt time.Time
 
 	consumptionMetrics *SessionConsumptionMetrics
 }
@@ -164,10 +165,16 @@ func (css *clientSessionState) ConsumptionMetrics() *SessionConsumptionMetrics {
 	return css.consumptionMetrics
 }
 
+// ConnectedAt returns the time when the client arrived.
+func (css *clientSessionState) ConnectedAt() time.Time {
+	return css.connectedAt
+}
+
 func newClientSessionState(ctx context.Context, ts time.Time) *clientSessionState {
 	return &clientSessionState{
 		sessionState: newSessionState(ctx, ts),
 		pool:         tunnel.NewPool(),
+		connectedAt:  ts,
 
 		consumptionMetrics: NewSessionConsumptionMetrics(),
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -52,11 +53,13 @@ type State interface {
 	GetOrGenerateAgentConfig(ctx context.Context, name, namespace string) (agentconfig.SidecarExt, error)
 	GetActiveAgent(sessionID string) *rpc.AgentInfo
 	GetAllClients() map[string]*rpc.ClientInfo
+	GetConnectedClients() []*rpc.ConnectedClient
 	GetClient(sessionID string) *rpc.ClientInfo
 	GetSession(string) SessionState
 	GetSessionConsumptionMetrics(string) *SessionConsumptionMetrics
 	GetAllSessionConsumptionMetrics() map[string]*SessionConsumptionMetrics
 	GetIntercept(string) (*rpc.InterceptInfo, bool)
+	GetWorkloadInterceptHistory(name, namespace string) []*rpc.InterceptHistoryEntry
 	GetConnectCounter() *prometheus.CounterVec
 	GetConnectActiveStatus() *prometheus.GaugeVec
 	GetInterceptCounter() *prometheus.CounterVec
@@ -71,6 +74,7 @@ type State interface {
 	DropIntercept(string)
 	RestoreAppContainer(context.Context, *rpc.InterceptInfo) error
 	FinalizeIntercept(ctx context.Context, intercept *rpc.InterceptInfo)
+	LoadMatchingAgents(filter func(string, *rpc.AgentInfo) bool) map[string]*rpc.AgentInfo
 	LoadMatchingIntercepts(filter func(string, *rpc.InterceptInfo) bool) map[string]*rpc.InterceptInfo
 	RemoveSession(context.Context, string)
 	SessionDone(string) (<-chan struct{}, error)
@@ -134,6 +138,7 @@ type state struct {
 	sessions                   *xsync.MapOf[string, SessionState]                         // info for all sessions, keyed by session id
 	agentsByName               *xsync.MapOf[string, *xsync.MapOf[string, *rpc.AgentInfo]] // indexed copy of `agents`
 	interceptStates            *xsync.MapOf[string, *interceptState]
+	interceptHistory           *xsync.MapOf[string, *workloadInterceptHistory] // recorded intercepts, keyed by workload name.namespace
 	timedLogLevel              log.TimedLevel
 	llSubs                     *loglevelSubscribers
 	workloadWatchers           *xsync.MapOf[string, workload.Watcher] // workload watchers, created on demand and keyed by namespace
@@ -162,6 +167,7 @@ func NewState(ctx context.Context) State {
 		sessions:         xsync.NewMapOf[string, SessionState](),
 		agentsByName:     xsync.NewMapOf[string, *xsync.MapOf[string, *rpc.AgentInfo]](),
 		interceptStates:  xsync.NewMapOf[string, *interceptState](),
+		interceptHistory: xsync.NewMapOf[string, *workloadInterceptHistory](),
 		workloadWatchers: xsync.NewMapOf[string, workload.Watcher](),
 		timedLogLevel:    log.NewTimedLevel(loglevel, log.SetLevel),
 		llSubs:           newLoglevelSubscribers(),
@@ -378,6 +384,40 @@ func (s *state) GetAllClients() map[string]*rpc.ClientInfo {
 	return s.clients.LoadAll()
 }
 
+// GetConnectedClients returns the clients that have a session, sorted by name and connect time, with the number
+// of intercepts that each one has.
+func (s *state) GetConnectedClients() []*rpc.ConnectedClient {
+	interceptCounts := make(map[string]int32)
+	for _, ii := range s.intercepts.LoadAll() {
+		if ii.Disposition != rpc.InterceptDispositionType_REMOVED {
+			interceptCounts[ii.ClientSession.SessionId]++
+		}
+	}
+	clients := s.clients.LoadAll()
+	ccs := make([]*rpc.ConnectedClient, 0, len(clients))
+	for sessionID, client := range clients {
+		cc := &rpc.ConnectedClient{
+			Name:           client.Name,
+			InstallId:      client.InstallId,
+			Namespace:      client.Namespace,
+			Version:        client.Version,
+			InterceptCount: interceptCounts[sessionID],
+			Metadata:       client.Metadata,
+		}
+		if css, ok := s.GetSession(sessionID).(*clientSessionState); ok {
+			cc.ConnectedAt = timestamppb.New(css.ConnectedAt())
+		}
+		ccs = append(ccs, cc)
+	}
+	slices.SortFunc(ccs, func(a, b *rpc.ConnectedClient) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return a.ConnectedAt.AsTime().Compare(b.ConnectedAt.AsTime())
+	})
+	return ccs
+}
+
 func (s *state) CountAgents() int {
 	return s.agents.CountAll()
 }
@@ -461,6 +501,11 @@ func (s *state) getAllAgents() map[string]*rpc.AgentInfo {
 	return s.agents.LoadAll()
 }
 
+// LoadMatchingAgents returns the agents that match the given filter, keyed by session ID.
+func (s *state) LoadMatchingAgents(filter func(string, *rpc.AgentInfo) bool) map[string]*rpc.AgentInfo {
+	return s.agents.LoadAllMatching(filter)
+}
+
 func (s *state) HasAgent(name, namespace string) bool {
 	_, ok := s.agentsByName.Load(name)
 	return ok
@@ -551,6 +596,7 @@ func (s *state) AddIntercept(ctx context.Context, sessionID, managerID string, c
 	}
 
 	s.interceptStates.Store(interceptID, newInterceptState(cept.Id))
+	s.recordInterceptStarted(client, cept)
 	return client, cept, nil
 }
 
@@ -658,6 +704,7 @@ func (s *state) RemoveIntercept(ctx context.Context, interceptID string) {
 // is not removed from the subscription list.
 func (s *state) FinalizeIntercept(_ context.Context, intercept *rpc.InterceptInfo) {
 	if is, ok := s.interceptStates.LoadAndDelete(intercept.Id); ok {
+		s.recordInterceptEnded(intercept)
 		is.terminate(s.backgroundCtx, intercept)
 	}
 }
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -25,12 +26,13 @@ type suiteState struct {
 func (s *suiteState) SetupTest() {
 	s.ctx = dlog.NewTestContext(s.T(), false)
 	s.state = &state{
-		backgroundCtx:   s.ctx,
-		sessions:        xsync.NewMapOf[string, SessionState](),
-		agentsByName:    xsync.NewMapOf[string, *xsync.MapOf[string, *manager.AgentInfo]](),
-		interceptStates: xsync.NewMapOf[string, *interceptState](),
-		timedLogLevel:   log.NewTimedLevel("debug", log.SetLevel),
-		llSubs:          newLoglevelSubscribers(),
+		backgroundCtx:    s.ctx,
+		sessions:         xsync.NewMapOf[string, SessionState](),
+		agentsByName:     xsync.NewMapOf[string, *xsync.MapOf[string, *manager.AgentInfo]](),
+		interceptStates:  xsync.NewMapOf[string, *interceptState](),
+		interceptHistory: xsync.NewMapOf[string, *workloadInterceptHistory](),
+		timedLogLevel:    log.NewTimedLevel("debug", log.SetLevel),
+		llSubs:           newLoglevelSubscribers(),
 	}
 }
 
@@ -171,6 +173,90 @@ func (s *suiteState) TestAddClient() {
 	assert.Equal(s.T(), 1, s.state.sessions.Size())
 }
 
+func (s *suiteState) TestGetConnectedClients() {
+	// given
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	s.state.addClient("session-1", &manager.ClientInfo{
+		Name:      "bob@host",
+		InstallId: "1",
+		Namespace: "a",
+		Version:   "2.22.0",
+		ApiKey:    "xxxx",
+		Metadata:  map[string]string{"build": "1234"},
+	}, now)
+	s.state.addClient("session-2", &manager.ClientInfo{Name: "alice@host", InstallId: "2", Namespace: "b", Version: "2.21.1"}, now.Add(time.Minute))
+	s.state.intercepts.Store("session-1:echo", &manager.InterceptInfo{
+		ClientSession: &manager.SessionInfo{SessionId: "session-1"},
+		Disposition:   manager.InterceptDispositionType_ACTIVE,
+	})
+	s.state.intercepts.Store("session-1:hello", &manager.InterceptInfo{
+		ClientSession: &manager.SessionInfo{SessionId: "session-1"},
+		Disposition:   manager.InterceptDispositionType_REMOVED,
+	})
+
+	// when
+	ccs := s.state.GetConnectedClients()
+
+	// then
+	s.Require().Len(ccs, 2)
+	assert.Equal(s.T(), "alice@host", ccs[0].Name)
+	assert.Equal(s.T(), "2", ccs[0].InstallId)
+	assert.Equal(s.T(), "2.21.1", ccs[0].Version)
+	assert.Equal(s.T(), now.Add(time.Minute), ccs[0].ConnectedAt.AsTime())
+	assert.Equal(s.T(), int32(0), ccs[0].InterceptCount)
+	assert.Equal(s.T(), "bob@host", ccs[1].Name)
+	assert.Equal(s.T(), "a", ccs[1].Namespace)
+	assert.Equal(s.T(), now, ccs[1].ConnectedAt.AsTime())
+	assert.Equal(s.T(), int32(1), ccs[1].InterceptCount)
+	assert.Equal(s.T(), map[string]string{"build": "1234"}, ccs[1].Metadata)
+	assert.Empty(s.T(), ccs[0].Metadata)
+}
+
+func (s *suiteState) TestWorkloadInterceptHistory() {
+	// given
+	s.state.self = s.state
+	now := time.Now()
+	s.state.addClient("session-1", &manager.ClientInfo{Name: "bob@host", InstallId: "1", Namespace: "a"}, now)
+	s.state.addClient("session-2", &manager.ClientInfo{Name: "alice@host", InstallId: "2", Namespace: "a"}, now)
+	intercept := func(sessionID, name, agent string) {
+		_, _, err := s.state.AddIntercept(s.ctx, sessionID, "manager", &manager.CreateInterceptRequest{
+			InterceptSpec: &manager.InterceptSpec{Name: name, Agent: agent, Namespace: "a", PortIdentifier: "http"},
+		})
+		s.Require().NoError(err)
+	}
+
+	// when
+	intercept("session-1", "echo", "echo")
+	intercept("session-1", "hello", "hello")
+	s.state.RemoveIntercept(s.ctx, "session-1:echo")
+	intercept("session-2", "echo-2", "echo")
+
+	// then
+	es := s.state.GetWorkloadInterceptHistory("echo", "a")
+	s.Require().Len(es, 2)
+	assert.Equal(s.T(), "echo-2", es[0].Name)
+	assert.Equal(s.T(), "alice@host", es[0].Client)
+	assert.Nil(s.T(), es[0].EndedAt)
+	assert.Equal(s.T(), "echo", es[1].Name)
+	assert.Equal(s.T(), "bob@host", es[1].Client)
+	assert.Equal(s.T(), "http", es[1].PortIdentifier)
+	assert.NotNil(s.T(), es[1].StartedAt)
+	assert.NotNil(s.T(), es[1].EndedAt)
+	assert.Len(s.T(), s.state.GetWorkloadInterceptHistory("hello", "a"), 1)
+	assert.Empty(s.T(), s.state.GetWorkloadInterceptHistory("echo", "b"))
+
+	// The oldest entries are dropped when the history is full.
+	for i := range maxWorkloadInterceptHistory {
+		name := fmt.Sprintf("hello-%d", i)
+		intercept("session-2", name, "hello")
+		s.state.RemoveIntercept(s.ctx, "session-2:"+name)
+	}
+	es = s.state.GetWorkloadInterceptHistory("hello", "a")
+	s.Require().Len(es, maxWorkloadInterceptHistory)
+	assert.Equal(s.T(), fmt.Sprintf("hello-%d", maxWorkloadInterceptHistory-1), es[0].Name)
+	assert.Equal(s.T(), "hello-0", es[maxWorkloadInterceptHistory-1].Name)
+}
+
 func (s *suiteState) TestRemoveSession() {
 	// given
 	now := time.Now()
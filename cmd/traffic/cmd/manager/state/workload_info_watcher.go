@@ -185,6 +185,13 @@ func rpcWorkloadState(s workload.State) (state rpc.WorkloadInfo_State) {
 }
 
 func rpcWorkload(wl k8sapi.Workload, as rpc.WorkloadInfo_AgentState, iClients []*rpc.WorkloadInfo_Intercept) *rpc.WorkloadInfo {
+	var reason string
+	if as == rpc.WorkloadInfo_NO_AGENT_UNSPECIFIED {
+		// A workload without an agent may have failed to get one.
+		if reason = workload.AgentInjectionFailure(wl); reason != "" {
+			as = rpc.WorkloadInfo_INJECTION_FAILED
+		}
+	}
 	return &rpc.WorkloadInfo{
 		Kind:             rpcKind(wl.GetKind()),
 		Name:             wl.GetName(),
@@ -192,6 +199,7 @@ func rpcWorkload(wl k8sapi.Workload, as rpc.WorkloadInfo_AgentState, iClients []
 		Uid:              string(wl.GetUID()),
 		State:            rpcWorkloadState(workload.GetWorkloadState(wl)),
 		AgentState:       as,
+		AgentStateReason: reason,
 		InterceptClients: iClients,
 	}
 }
@@ -301,6 +309,7 @@ func (wf *workloadInfoWatcher) handleAgentSnapshot(ctx context.Context, ais map[
 			dlog.Debugf(ctx, "WorkloadInfoEvent: AgentInfo %s.%s %s %s", a.Name, a.Namespace, as, w.Workload.State)
 			if wl.AgentState != as {
 				wl.AgentState = as
+				wl.AgentStateReason = ""
 				wl.InterceptClients = iClients
 				wf.resetTicker()
 			}
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func Test_rpcWorkload_injectionFailed(t *testing.T) {
+	const msg = `admission webhook "agent-injector-ambassador.getambassador.io" denied the request: unable to resolve port "http"`
+	wl := k8sapi.Deployment(&appsv1.Deployment{
+		ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "default"},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{
+			Type:    appsv1.DeploymentReplicaFailure,
+			Status:  core.ConditionTrue,
+			Message: msg,
+		}}},
+	})
+
+	wi := rpcWorkload(wl, rpc.WorkloadInfo_NO_AGENT_UNSPECIFIED, nil)
+	assert.Equal(t, rpc.WorkloadInfo_INJECTION_FAILED, wi.AgentState)
+	assert.Equal(t, msg, wi.AgentStateReason)
+	assert.Equal(t, rpc.WorkloadInfo_FAILURE, wi.State)
+
+	// An agent that is present takes precedence over an old failure.
+	wi = rpcWorkload(wl, rpc.WorkloadInfo_INSTALLED, nil)
+	assert.Equal(t, rpc.WorkloadInfo_INSTALLED, wi.AgentState)
+	assert.Empty(t, wi.AgentStateReason)
+
+	wi = rpcWorkload(k8sapi.Deployment(&appsv1.Deployment{}), rpc.WorkloadInfo_NO_AGENT_UNSPECIFIED, nil)
+	assert.Equal(t, rpc.WorkloadInfo_NO_AGENT_UNSPECIFIED, wi.AgentState)
+	assert.Empty(t, wi.AgentStateReason)
+}
@@ -204,7 +204,9 @@ func (ac *client) startDialWatcherReady(ctx context.Context) error {
 
 type Clients interface {
 	GetClient(netip.Addr) tunnel.Provider
-	WatchAgentPods(ctx context.Context, rmc manager.ManagerClient) error
+	// WatchAgentPods watches the agent pods using the manager client returned by the given function. The
+	// function is called each time that the watch is started, so that a client that replaced another is used.
+	WatchAgentPods(ctx context.Context, rmc func() manager.ManagerClient) error
 	WaitForIP(ctx context.Context, timeout time.Duration, ip netip.Addr) error
 	WaitForWorkload(ctx context.Context, timeout time.Duration, name string) error
 	GetWorkloadClient(workload string) (ag tunnel.Provider)
@@ -299,7 +301,7 @@ func (s *clients) hasWaiterFor(info *manager.AgentPodInfo) bool {
 	return false
 }
 
-func (s *clients) WatchAgentPods(ctx context.Context, rmc manager.ManagerClient) error {
+func (s *clients) WatchAgentPods(ctx context.Context, rmc func() manager.ManagerClient) error {
 	dlog.Debug(ctx, "WatchAgentPods starting")
 	defer func() {
 		dlog.Debugf(ctx, "WatchAgentPods ending with %d clients still active", s.clients.Size())
@@ -313,7 +315,7 @@ func (s *clients) WatchAgentPods(ctx context.Context, rmc manager.ManagerClient)
 
 outer:
 	for ctx.Err() == nil {
-		as, err := rmc.WatchAgentPods(ctx, s.session)
+		as, err := rmc().WatchAgentPods(ctx, s.session)
 		switch status.Code(err) {
 		case codes.OK:
 		case codes.Unavailable:
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"crypto/x509"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// CABundleData returns the PEM encoded certificates of the CABundle. The CABundle is considered to be
+// inline PEM when it starts with a PEM header, and a path to a file otherwise. A nil slice is returned
+// when no CABundle is configured. An error is returned when the file cannot be read or when the bundle
+// doesn't contain any valid certificate.
+func (cc *Cluster) CABundleData() ([]byte, error) {
+	bundle := strings.TrimSpace(cc.CABundle)
+	if bundle == "" {
+		return nil, nil
+	}
+	var data []byte
+	if strings.HasPrefix(bundle, "-----BEGIN") {
+		data = []byte(bundle + "\n")
+	} else {
+		var err error
+		if data, err = os.ReadFile(bundle); err != nil {
+			return nil, errcat.Config.Newf("unable to read cluster.caBundle: %v", err)
+		}
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return nil, errcat.Config.New("cluster.caBundle contains no valid PEM encoded certificates")
+	}
+	return data, nil
+}
+
+// WithCABundle returns a copy of the given rest.Config that trusts the certificates of the given PEM
+// encoded bundle in addition to the certificate authority that the config already trusts. The given
+// config is not modified.
+func WithCABundle(rc *rest.Config, bundle []byte) (*rest.Config, error) {
+	rc = rest.CopyConfig(rc)
+	tc := &rc.TLSClientConfig
+	caData := tc.CAData
+	if tc.CAFile != "" {
+		// A CAFile takes precedence over CAData, so it must be read and merged.
+		var err error
+		if caData, err = os.ReadFile(tc.CAFile); err != nil {
+			return nil, err
+		}
+		tc.CAFile = ""
+	}
+	// Create a new slice so that the backing array of the given config's CAData isn't modified.
+	merged := make([]byte, 0, len(caData)+len(bundle)+1)
+	merged = append(merged, caData...)
+	if len(merged) > 0 && !bytes.HasSuffix(merged, []byte("\n")) {
+		merged = append(merged, '\n')
+	}
+	tc.CAData = append(merged, bundle...)
+	return rc, nil
+}
+
+// ApplyCABundle returns a copy of the given rest.Config that trusts the certificates of the configured
+// CABundle, or the given config unmodified when no CABundle is configured.
+func (cc *Cluster) ApplyCABundle(rc *rest.Config) (*rest.Config, error) {
+	bundle, err := cc.CABundleData()
+	if err != nil || bundle == nil {
+		return rc, err
+	}
+	return WithCABundle(rc, bundle)
+}
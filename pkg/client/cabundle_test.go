@@ -0,0 +1,136 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+func certPEM(t *testing.T, srv *httptest.Server) []byte {
+	t.Helper()
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+}
+
+// otherCAPEM returns a self-signed CA certificate that is unrelated to the certificate of any httptest server.
+func otherCAPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCluster_CABundleData(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	caPEM := certPEM(t, srv)
+
+	data, err := (&Cluster{}).CABundleData()
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	data, err = (&Cluster{CABundle: string(caPEM)}).CABundleData()
+	require.NoError(t, err)
+	assert.Equal(t, caPEM, data)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, caPEM, 0o600))
+	data, err = (&Cluster{CABundle: path}).CABundleData()
+	require.NoError(t, err)
+	assert.Equal(t, caPEM, data)
+
+	_, err = (&Cluster{CABundle: "-----BEGIN CERTIFICATE-----\nbogus\n-----END CERTIFICATE-----"}).CABundleData()
+	require.Error(t, err)
+	assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+
+	_, err = (&Cluster{CABundle: filepath.Join(t.TempDir(), "missing.pem")}).CABundleData()
+	require.Error(t, err)
+	assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+}
+
+func TestWithCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// The kubeconfig trusts another CA than the one used by the server, e.g. because a proxy fronts the API server.
+	otherPEM := otherCAPEM(t)
+	caFile := filepath.Join(t.TempDir(), "kube-ca.pem")
+	require.NoError(t, os.WriteFile(caFile, otherPEM, 0o600))
+
+	get := func(rc *rest.Config) error {
+		hc, err := rest.HTTPClientFor(rc)
+		require.NoError(t, err)
+		rsp, err := hc.Get(srv.URL)
+		if err == nil {
+			rsp.Body.Close()
+		}
+		return err
+	}
+
+	for name, tls := range map[string]rest.TLSClientConfig{
+		"CAData": {CAData: otherPEM},
+		"CAFile": {CAFile: caFile},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rc := &rest.Config{Host: srv.URL, TLSClientConfig: tls}
+			require.Error(t, get(rc), "server must not be trusted without the bundle")
+
+			brc, err := WithCABundle(rc, certPEM(t, srv))
+			require.NoError(t, err)
+			require.NoError(t, get(brc))
+			assert.Empty(t, brc.CAFile)
+			assert.Contains(t, string(brc.CAData), string(otherPEM), "the kubeconfig CA must still be trusted")
+
+			// The original config is unchanged.
+			assert.Equal(t, tls, rc.TLSClientConfig)
+		})
+	}
+}
+
+func TestCluster_ApplyCABundle(t *testing.T) {
+	otherPEM := otherCAPEM(t)
+	rc := &rest.Config{Host: "https://example.com", TLSClientConfig: rest.TLSClientConfig{CAData: otherPEM}}
+
+	arc, err := (&Cluster{}).ApplyCABundle(rc)
+	require.NoError(t, err)
+	assert.Same(t, rc, arc)
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	arc, err = (&Cluster{CABundle: string(certPEM(t, srv))}).ApplyCABundle(rc)
+	require.NoError(t, err)
+	assert.NotSame(t, rc, arc)
+	assert.Contains(t, string(arc.CAData), string(otherPEM))
+	assert.Contains(t, string(arc.CAData), string(certPEM(t, srv)))
+
+	_, err = (&Cluster{CABundle: filepath.Join(t.TempDir(), "missing.pem")}).ApplyCABundle(rc)
+	assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+}
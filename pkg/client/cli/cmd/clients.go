@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func clientsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clients",
+		Short: "Show the clients that are connected to the traffic-manager",
+	}
+	cmd.AddCommand(clientsList())
+	return cmd
+}
+
+func clientsList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List the clients that are connected to the traffic-manager",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE:              runClientsList,
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+}
+
+func runClientsList(cmd *cobra.Command, _ []string) error {
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	rsp, err := daemon.GetUserClient(ctx).ConnectedClients(ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
+	clients := rsp.Clients
+	if output.WantsFormatted(cmd) {
+		output.Object(ctx, clients, false)
+		return nil
+	}
+	if len(clients) == 0 {
+		ioutil.Println(output.Out(ctx), "No clients are connected")
+		return nil
+	}
+	nameLen, nsLen := len("NAME"), len("NAMESPACE")
+	for _, c := range clients {
+		nameLen = max(nameLen, len(c.Name))
+		nsLen = max(nsLen, len(c.Namespace))
+	}
+	out := output.Out(ctx)
+	ioutil.Printf(out, "%-*s  %-*s  %-10s  %-20s  %-36s  %s\n",
+		nameLen, "NAME", nsLen, "NAMESPACE", "INTERCEPTS", "CONNECTED", "INSTALL ID", "METADATA")
+	for _, c := range clients {
+		connected := ""
+		if c.ConnectedAt != nil {
+			connected = c.ConnectedAt.AsTime().Local().Format(time.DateTime)
+		}
+		ioutil.Printf(out, "%-*s  %-*s  %-10d  %-20s  %-36s  %s\n",
+			nameLen, c.Name, nsLen, c.Namespace, c.InterceptCount, connected, c.InstallId, formatMetadata(c.Metadata))
+	}
+	return nil
+}
+
+// formatMetadata returns the given session metadata as a comma separated list of key=value pairs, sorted by key.
+func formatMetadata(md map[string]string) string {
+	kvs := make([]string, 0, len(md))
+	for _, k := range slices.Sorted(maps.Keys(md)) {
+		kvs = append(kvs, k+"="+md[k])
+	}
+	return strings.Join(kvs, ",")
+}
@@ -9,10 +9,14 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
 )
 
 func connectCmd() *cobra.Command {
 	var request *daemon.CobraRequest
+	var dryRun bool
 
 	cmd := &cobra.Command{
 		Use:   "connect [flags] [-- <command to run while connected>]",
@@ -25,6 +29,12 @@ func connectCmd() *cobra.Command {
 			if err := request.CommitFlags(cmd); err != nil {
 				return err
 			}
+			if dryRun {
+				if len(args) > 0 {
+					return errcat.User.New("--dry-run cannot be combined with a command")
+				}
+				return runConnectDryRun(cmd, request)
+			}
 			return connect.RunConnect(cmd, args)
 		},
 		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
@@ -36,5 +46,34 @@ func connectCmd() *cobra.Command {
 		},
 	}
 	request = daemon.InitRequest(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, ``+
+		`Show the DNS search domains that the connection would use, without connecting`)
 	return cmd
 }
+
+// runConnectDryRun prints the DNS search domains that a session created from the given request would
+// start out with. Only the user daemon is needed for this; no session is created.
+func runConnectDryRun(cmd *cobra.Command, request *daemon.CobraRequest) error {
+	cmd.Annotations = map[string]string{ann.UserDaemon: ann.Required}
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	preview, err := daemon.GetUserClient(ctx).PreviewDNSDomains(ctx, &request.ConnectRequest)
+	if err != nil {
+		return err
+	}
+	if output.WantsFormatted(cmd) {
+		output.Object(ctx, preview, false)
+		return nil
+	}
+	out := output.Out(ctx)
+	ioutil.Println(out, "DNS search domains:")
+	for _, domain := range preview.Domains {
+		ioutil.Printf(out, "  %s\n", domain)
+	}
+	if preview.AllNamespaces {
+		ioutil.Println(out, "All namespaces that the client can access are added when connected")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func historyCmd() *cobra.Command {
+	var namespace string
+	cmd := &cobra.Command{
+		Use:   "history <workload>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the intercepts of a workload that the traffic-manager has recorded",
+		Long: "Show the intercepts of a workload that the traffic-manager has recorded, regardless of what client " +
+			"created them, most recent first.",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(cmd, args[0], namespace)
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "The namespace of the workload")
+	return cmd
+}
+
+func runHistory(cmd *cobra.Command, workload, namespace string) error {
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	rsp, err := daemon.GetUserClient(ctx).WorkloadInterceptHistory(ctx, &connector.WorkloadInterceptHistoryRequest{
+		Name:      workload,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return err
+	}
+	if output.WantsFormatted(cmd) {
+		output.Object(ctx, rsp, false)
+		return nil
+	}
+	out := output.Out(ctx)
+	if rsp.Note != "" {
+		ioutil.Println(out, rsp.Note)
+		return nil
+	}
+	entries := rsp.Entries
+	if len(entries) == 0 {
+		ioutil.Printf(out, "No intercepts of %s have been recorded\n", workload)
+		return nil
+	}
+	nameLen, clientLen, portLen := len("NAME"), len("CLIENT"), len("PORT")
+	for _, e := range entries {
+		nameLen = max(nameLen, len(e.Name))
+		clientLen = max(clientLen, len(e.Client))
+		portLen = max(portLen, len(e.PortIdentifier))
+	}
+	ioutil.Printf(out, "%-*s  %-*s  %-*s  %-20s  %s\n", nameLen, "NAME", clientLen, "CLIENT", portLen, "PORT", "STARTED", "DURATION")
+	for _, e := range entries {
+		started := ""
+		duration := ""
+		if e.StartedAt != nil {
+			st := e.StartedAt.AsTime()
+			started = st.Local().Format(time.DateTime)
+			if e.EndedAt != nil {
+				duration = e.EndedAt.AsTime().Sub(st).Round(time.Second).String()
+			} else {
+				duration = "active"
+			}
+		}
+		ioutil.Printf(out, "%-*s  %-*s  %-*s  %-20s  %s\n", nameLen, e.Name, clientLen, e.Client, portLen, e.PortIdentifier, started, duration)
+	}
+	return nil
+}
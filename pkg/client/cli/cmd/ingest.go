@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ingest"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/intercept"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
 )
 
 func ingestCmd() *cobra.Command {
@@ -24,5 +31,55 @@ func ingestCmd() *cobra.Command {
 		ValidArgsFunction: intercept.ValidArgs, // a list that this command shares with intercept
 	}
 	ic.AddFlags(cmd)
+	cmd.AddCommand(ingestList())
 	return cmd
 }
+
+func ingestList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List active ingests grouped by workload",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			r, err := daemon.GetUserClient(ctx).IngestsByWorkload(ctx, &emptypb.Empty{})
+			if err != nil {
+				return err
+			}
+			infos := make(map[string][]*ingest.Info, len(r.Workloads))
+			for wl, wi := range r.Workloads {
+				for _, ii := range wi.Ingests {
+					infos[wl] = append(infos[wl], ingest.NewInfo(ctx, ii, nil))
+				}
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, infos, false)
+				return nil
+			}
+			out := output.Out(ctx)
+			if len(infos) == 0 {
+				fmt.Fprintln(out, "No active ingests")
+				return nil
+			}
+			wls := make([]string, 0, len(infos))
+			for wl := range infos {
+				wls = append(wls, wl)
+			}
+			slices.Sort(wls)
+			for _, wl := range wls {
+				fmt.Fprintf(out, "%s:\n", wl)
+				for _, info := range infos[wl] {
+					_, _ = info.WriteTo(out)
+				}
+			}
+			return nil
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+}
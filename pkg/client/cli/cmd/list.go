@@ -3,6 +3,9 @@ package cmd
 import (
 	"context"
 	"io"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
@@ -26,6 +29,9 @@ type listCommand struct {
 	onlyInterceptable bool
 	debug             bool
 	namespace         string
+	kinds             []string
+	limit             int32
+	pods              bool
 	watch             bool
 }
 
@@ -49,6 +55,10 @@ func list() *cobra.Command {
 	flags.BoolVarP(&s.onlyInterceptable, "only-interceptable", "o", true, "interceptable workloads only")
 	flags.BoolVar(&s.debug, "debug", false, "include debugging information")
 	flags.StringVarP(&s.namespace, "namespace", "n", "", "If present, the namespace scope for this CLI request")
+	flags.StringSliceVar(&s.kinds, "kind", nil,
+		`kinds of workloads to list, e.g. "ReplicaSet". Defaults to the list.defaultKinds of the config`)
+	flags.Int32Var(&s.limit, "limit", 0, "maximum number of workloads to list, 0 means no limit")
+	flags.BoolVar(&s.pods, "pods", false, "include the pods of each workload")
 
 	flags.BoolVarP(&s.watch, "watch", "w", false, "watch a namespace. --agents and --intercepts are disabled if this flag is set")
 	wf := flags.Lookup("watch")
@@ -77,6 +87,9 @@ func list() *cobra.Command {
 	return cmd
 }
 
+// workloadKinds are the kinds of workloads that can be listed.
+var workloadKinds = []string{"Deployment", "ReplicaSet", "StatefulSet", "Rollout"} //nolint:gochecknoglobals // constant
+
 type watchWorkloadStreamResponse struct {
 	workloadInfoSnapshot *connector.WorkloadInfoSnapshot
 	err                  error
@@ -87,6 +100,11 @@ func (s *listCommand) list(cmd *cobra.Command, _ []string) error {
 	if err := connect.InitCommand(cmd); err != nil {
 		return err
 	}
+	for _, kind := range s.kinds {
+		if !slices.ContainsFunc(workloadKinds, func(k string) bool { return strings.EqualFold(k, kind) }) {
+			return errcat.User.Newf("invalid workload kind %q, must be one of %s", kind, strings.Join(workloadKinds, ", "))
+		}
+	}
 	stdout := cmd.OutOrStdout()
 	ctx := cmd.Context()
 	userD := daemon.GetUserClient(ctx)
@@ -114,15 +132,36 @@ func (s *listCommand) list(cmd *cobra.Command, _ []string) error {
 
 	formattedOutput := output.WantsFormatted(cmd)
 	if !output.WantsStream(cmd) {
-		r, err := userD.List(ctx, &connector.ListRequest{Filter: filter, Namespace: s.namespace}, grpc.MaxCallRecvMsgSize(int(maxRecSize)))
+		r, err := userD.List(ctx, &connector.ListRequest{
+			Filter:      filter,
+			Namespace:   s.namespace,
+			Kinds:       s.kinds,
+			Limit:       s.limit,
+			IncludePods: s.pods,
+		}, grpc.MaxCallRecvMsgSize(int(maxRecSize)))
 		if err != nil {
 			return err
 		}
 		s.printList(ctx, r.Workloads, stdout, formattedOutput)
+		if r.Truncated && !formattedOutput {
+			ioutil.Printf(stdout, "Showing %d of %d workloads\n", len(r.Workloads), r.Total)
+		}
+		if !formattedOutput {
+			for _, ns := range slices.Sorted(maps.Keys(r.ExposureErrors)) {
+				ioutil.Printf(cmd.ErrOrStderr(), "Unable to tell which workloads in namespace %s have ports that can be intercepted: %s\n",
+					ns, r.ExposureErrors[ns])
+			}
+			for _, ns := range slices.Sorted(maps.Keys(r.PodErrors)) {
+				ioutil.Printf(cmd.ErrOrStderr(), "Unable to list the pods in namespace %s: %s\n", ns, r.PodErrors[ns])
+			}
+		}
 		return nil
 	}
 
-	stream, streamErr := userD.WatchWorkloads(ctx, &connector.WatchWorkloadsRequest{Namespaces: []string{s.namespace}}, grpc.MaxCallRecvMsgSize(int(maxRecSize)))
+	stream, streamErr := userD.WatchWorkloads(ctx, &connector.WatchWorkloadsRequest{
+		Namespaces: []string{s.namespace},
+		Kinds:      s.kinds,
+	}, grpc.MaxCallRecvMsgSize(int(maxRecSize)))
 	if streamErr != nil {
 		return streamErr
 	}
@@ -172,7 +211,10 @@ func (s *listCommand) printList(ctx context.Context, workloads []*connector.Work
 		if iis, igs := workload.InterceptInfos, workload.IngestInfos; len(iis)+len(igs) > 0 {
 			return intercept.DescribeIntercepts(ctx, iis, igs, nil, s.debug)
 		}
-		if workload.NotInterceptableReason == "Progressing" {
+		if workload.AgentInjectionFailure != "" {
+			return "traffic-agent injection failed: " + workload.AgentInjectionFailure
+		}
+		if workload.NotInterceptableCode == connector.WorkloadInfo_PROGRESSING {
 			return "progressing..."
 		}
 		if workload.AgentVersion != "" {
@@ -198,23 +240,32 @@ func (s *listCommand) printList(ctx context.Context, workloads []*connector.Work
 			}
 			ns = depNs
 		}
-		nameLen := 0
-		for _, dep := range workloads {
-			n := dep.Name
-			nl := len(n)
+		displayName := func(workload *connector.WorkloadInfo) string {
+			n := workload.Name
 			if includeNs {
-				nl += len(dep.Namespace) + 1
+				if workload.NamespaceAlias != "" {
+					n += "." + workload.NamespaceAlias
+				} else {
+					n += "." + workload.Namespace
+				}
 			}
-			if nl > nameLen {
+			return n
+		}
+		nameLen := 0
+		for _, dep := range workloads {
+			if nl := len(displayName(dep)); nl > nameLen {
 				nameLen = nl
 			}
 		}
 		for _, workload := range workloads {
-			n := workload.Name
-			if includeNs {
-				n += "." + workload.Namespace
+			ioutil.Printf(stdout, "%-*s: %s\n", nameLen, displayName(workload), state(workload))
+			for _, pod := range workload.Pods {
+				ready := "not ready"
+				if pod.Ready {
+					ready = "ready"
+				}
+				ioutil.Printf(stdout, "%-*s  %s: %s, %s, node %q\n", nameLen, "", pod.Name, pod.Phase, ready, pod.Node)
 			}
-			ioutil.Printf(stdout, "%-*s: %s\n", nameLen, n, state(workload))
 		}
 	}
 }
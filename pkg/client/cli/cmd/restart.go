@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+type restartCommand struct {
+	namespace string
+	kind      string
+}
+
+func restart() *cobra.Command {
+	rc := &restartCommand{}
+	cmd := &cobra.Command{
+		Use:   "restart [flags] <workload>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Trigger a rolling restart of a workload",
+		Long: `Trigger a rolling restart of a workload.
+
+The restart re-injects or refreshes the traffic-agent of the workload without uninstalling it. Only
+workloads in mapped namespaces can be restarted, and only Deployments, StatefulSets, and Rollouts
+support a rolling restart.`,
+		RunE: rc.run,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		ValidArgsFunction: validWorkloads,
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&rc.namespace, "namespace", "n", "", "The namespace of the workload. Defaults to the connected namespace")
+	flags.StringVar(&rc.kind, "kind", "", `The kind of the workload, e.g. "StatefulSet". Found automatically when not given`)
+	return cmd
+}
+
+func (rc *restartCommand) run(cmd *cobra.Command, args []string) error {
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	_, err := daemon.GetUserClient(ctx).RestartWorkload(ctx, &connector.RestartWorkloadRequest{
+		Name:      args[0],
+		Namespace: rc.namespace,
+		Kind:      rc.kind,
+	})
+	if err != nil {
+		return err
+	}
+	ioutil.Printf(cmd.OutOrStdout(), "Triggered a rolling restart of %s\n", args[0])
+	return nil
+}
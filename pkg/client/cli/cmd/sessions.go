@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func sessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Maintain the sessions that are cached for the daemons",
+	}
+	cmd.AddCommand(sessionsPrune())
+	return cmd
+}
+
+func sessionsPrune() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Args:  cobra.NoArgs,
+		Short: "Remove cached sessions that the traffic-manager no longer knows",
+		Long: `Remove cached sessions that the traffic-manager no longer knows.
+
+Each cached session is validated against the traffic-manager of the current connection. Sessions
+that were cached for other kube contexts, or that cannot be validated because the traffic-manager
+doesn't respond, are left untouched and reported.`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE:              runSessionsPrune,
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+}
+
+func runSessionsPrune(cmd *cobra.Command, _ []string) error {
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	rsp, err := daemon.GetUserClient(ctx).PruneSessions(ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
+	if output.WantsFormatted(cmd) {
+		output.Object(ctx, rsp, false)
+		return nil
+	}
+	out := output.Out(ctx)
+	printSessions := func(title string, css []*connector.CachedSession) {
+		ioutil.Printf(out, "%s: %d\n", title, len(css))
+		for _, cs := range css {
+			if cs.SessionId == "" {
+				ioutil.Printf(out, "  %s: %s\n", cs.File, cs.Reason)
+			} else if cs.Reason == "" {
+				ioutil.Printf(out, "  %s: session %s in context %s\n", cs.File, cs.SessionId, cs.KubeContext)
+			} else {
+				ioutil.Printf(out, "  %s: session %s in context %s, %s\n", cs.File, cs.SessionId, cs.KubeContext, cs.Reason)
+			}
+		}
+	}
+	printSessions("Pruned", rsp.Pruned)
+	printSessions("Unreachable", rsp.Unreachable)
+	ioutil.Printf(out, "Valid: %d\n", len(rsp.Valid))
+	return nil
+}
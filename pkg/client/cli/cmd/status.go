@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/netip"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-json-experiment/json"
 	"github.com/spf13/cobra"
@@ -66,8 +68,13 @@ type UserDaemonStatus struct {
 	Namespace         string                   `json:"namespace,omitempty"`
 	ManagerNamespace  string                   `json:"manager_namespace,omitempty"`
 	MappedNamespaces  []string                 `json:"mapped_namespaces,omitempty"`
+	Metadata          map[string]string        `json:"metadata,omitempty"`
 	Ingests           []ConnectStatusIngest    `json:"ingests,omitempty"`
 	Intercepts        []ConnectStatusIntercept `json:"intercepts,omitempty"`
+	IngressInfo       *ConnectStatusIngress    `json:"ingress_info,omitempty"`
+	IdleRemoved       []ConnectStatusIdle      `json:"idle_removed_intercepts,omitempty"`
+	Draining          []ConnectStatusDraining  `json:"draining_intercepts,omitempty"`
+	WatcherRetries    []ConnectStatusRetry     `json:"watcher_retries,omitempty"`
 	versionName       string
 }
 
@@ -91,8 +98,36 @@ type ConnectStatusIngest struct {
 }
 
 type ConnectStatusIntercept struct {
-	Name   string `json:"name,omitempty"`
-	Client string `json:"client,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Client     string `json:"client,omitempty"`
+	Global     bool   `json:"global,omitempty"`
+	Group      string `json:"group,omitempty"`
+	Mirror     bool   `json:"mirror,omitempty"`
+	SetupError string `json:"setup_error,omitempty"`
+}
+
+type ConnectStatusIdle struct {
+	Name        string    `json:"name,omitempty"`
+	IdleTimeout string    `json:"idle_timeout,omitempty"`
+	RemovedAt   time.Time `json:"removed_at"`
+}
+
+type ConnectStatusDraining struct {
+	Name      string    `json:"name,omitempty"`
+	DrainedAt time.Time `json:"drained_at"`
+}
+
+type ConnectStatusRetry struct {
+	Namespace string    `json:"namespace,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Failures  int32     `json:"failures,omitempty"`
+	RetryAt   time.Time `json:"retry_at"`
+}
+
+type ConnectStatusIngress struct {
+	Hosts         []string  `json:"hosts,omitempty"`
+	LastRefreshed time.Time `json:"last_refreshed"`
+	Stale         bool      `json:"stale,omitempty"`
 }
 
 const (
@@ -299,13 +334,53 @@ func getStatusInfo(ctx context.Context, di *daemon.Info) (*StatusInfo, error) {
 		}
 		for _, icept := range status.GetIntercepts().GetIntercepts() {
 			us.Intercepts = append(us.Intercepts, ConnectStatusIntercept{
-				Name:   icept.Spec.Name,
-				Client: icept.Spec.Client,
+				Name:       icept.Spec.Name,
+				Client:     icept.Spec.Client,
+				Global:     icept.Spec.AllConnections,
+				Group:      status.InterceptGroups[icept.Spec.Name],
+				Mirror:     icept.Spec.Mirror,
+				SetupError: icept.SetupError,
 			})
 		}
+		// Keep the intercepts of a group together.
+		sort.SliceStable(us.Intercepts, func(i, j int) bool {
+			return us.Intercepts[i].Group < us.Intercepts[j].Group
+		})
 		us.Namespace = status.Namespace
 		us.ManagerNamespace = status.ManagerNamespace
 		us.MappedNamespaces = status.MappedNamespaces
+		us.Metadata = status.Metadata
+		if ii := status.IngressInfo; ii != nil {
+			ci := &ConnectStatusIngress{
+				LastRefreshed: ii.LastRefreshed.AsTime(),
+				Stale:         ii.Stale,
+			}
+			for _, ig := range ii.Ingresses {
+				ci.Hosts = append(ci.Hosts, fmt.Sprintf("%s -> %s:%d", ig.L5Host, ig.Host, ig.Port))
+			}
+			us.IngressInfo = ci
+		}
+		for _, ir := range status.IdleRemovedIntercepts {
+			us.IdleRemoved = append(us.IdleRemoved, ConnectStatusIdle{
+				Name:        ir.Name,
+				IdleTimeout: ir.IdleTimeout.AsDuration().String(),
+				RemovedAt:   ir.RemovedAt.AsTime(),
+			})
+		}
+		for _, di := range status.DrainingIntercepts {
+			us.Draining = append(us.Draining, ConnectStatusDraining{
+				Name:      di.Name,
+				DrainedAt: di.DrainedAt.AsTime(),
+			})
+		}
+		for _, wr := range status.WatcherRetries {
+			us.WatcherRetries = append(us.WatcherRetries, ConnectStatusRetry{
+				Namespace: wr.Namespace,
+				Error:     wr.Error,
+				Failures:  wr.Failures,
+				RetryAt:   wr.RetryAt.AsTime(),
+			})
+		}
 	case connector.ConnectInfo_UNAUTHORIZED:
 		us.Status = "Not authorized to connect"
 		us.Error = status.ErrorText
@@ -316,12 +391,16 @@ func getStatusInfo(ctx context.Context, di *daemon.Info) (*StatusInfo, error) {
 		us.Status = "Connected, but must restart"
 	case connector.ConnectInfo_DISCONNECTED:
 		us.Status = "Not connected"
+		us.Error = status.ErrorText
 	case connector.ConnectInfo_CLUSTER_FAILED:
 		us.Status = "Not connected, error talking to cluster"
 		us.Error = status.ErrorText
 	case connector.ConnectInfo_TRAFFIC_MANAGER_FAILED:
 		us.Status = "Not connected, error talking to in-cluster Telepresence traffic-manager"
 		us.Error = status.ErrorText
+	case connector.ConnectInfo_TRAFFIC_MANAGER_NOT_FOUND:
+		us.Status = "Not connected, in-cluster Telepresence traffic-manager not found"
+		us.Error = status.ErrorText
 	}
 
 	rStatus := status.DaemonStatus
@@ -549,6 +628,9 @@ func (cs *UserDaemonStatus) print(kvf *ioutil.KeyValueFormatter) {
 	if len(cs.MappedNamespaces) > 0 {
 		kvf.Add("Mapped namespaces", fmt.Sprintf("%v", cs.MappedNamespaces))
 	}
+	if len(cs.Metadata) > 0 {
+		kvf.Add("Metadata", formatMetadata(cs.Metadata))
+	}
 	if cs.Hostname != "" {
 		kvf.Add("Hostname", cs.Hostname)
 	}
@@ -569,10 +651,72 @@ func (cs *UserDaemonStatus) print(kvf *ioutil.KeyValueFormatter) {
 		subKvf := ioutil.DefaultKeyValueFormatter()
 		subKvf.Indent = "  "
 		for _, intercept := range cs.Intercepts {
-			subKvf.Add(intercept.Name, intercept.Client)
+			kind := "matched requests"
+			if intercept.Global {
+				kind = "all TCP connections"
+			}
+			if intercept.Mirror {
+				kind += ", mirrored"
+			}
+			if intercept.Group != "" {
+				kind += ", group " + intercept.Group
+			}
+			subKvf.Add(intercept.Name, fmt.Sprintf("%s (%s)", intercept.Client, kind))
 		}
 		subKvf.Println(out)
 		kvf.Add("Intercepts", out.String())
+
+		var failed []ConnectStatusIntercept
+		for _, intercept := range cs.Intercepts {
+			if intercept.SetupError != "" {
+				failed = append(failed, intercept)
+			}
+		}
+		if fl := len(failed); fl > 0 {
+			out = &strings.Builder{}
+			ioutil.Printf(out, "%d total", fl)
+			for _, intercept := range failed {
+				ioutil.Printf(out, "\n  intercept %s: %s", intercept.Name, intercept.SetupError)
+			}
+			kvf.Add("Setup errors", out.String())
+		}
+	}
+	if il := len(cs.IdleRemoved); il > 0 {
+		out := &strings.Builder{}
+		ioutil.Printf(out, "%d total", il)
+		for _, ir := range cs.IdleRemoved {
+			ioutil.Printf(out, "\n  %s: no traffic for %s, removed %s", ir.Name, ir.IdleTimeout, ir.RemovedAt.Local().Format(time.DateTime))
+		}
+		kvf.Add("Removed idle intercepts", out.String())
+	}
+	if dl := len(cs.Draining); dl > 0 {
+		out := &strings.Builder{}
+		ioutil.Printf(out, "%d total", dl)
+		for _, di := range cs.Draining {
+			drainsIn := max(time.Until(di.DrainedAt), 0).Round(time.Second)
+			ioutil.Printf(out, "\n  %s: removed in %s", di.Name, drainsIn)
+		}
+		kvf.Add("Draining intercepts", out.String())
+	}
+	if wl := len(cs.WatcherRetries); wl > 0 {
+		out := &strings.Builder{}
+		ioutil.Printf(out, "%d total", wl)
+		for _, wr := range cs.WatcherRetries {
+			retryIn := max(time.Until(wr.RetryAt), 0).Round(time.Second)
+			ioutil.Printf(out, "\n  namespace %s retrying in %s after %d failures: %s", wr.Namespace, retryIn, wr.Failures, wr.Error)
+		}
+		kvf.Add("Workload watcher retries", out.String())
+	}
+	if ii := cs.IngressInfo; ii != nil {
+		out := &strings.Builder{}
+		ioutil.Printf(out, "%d total, refreshed %s", len(ii.Hosts), ii.LastRefreshed.Local().Format(time.DateTime))
+		if ii.Stale {
+			ioutil.Print(out, " (stale)")
+		}
+		for _, host := range ii.Hosts {
+			ioutil.Printf(out, "\n  %s", host)
+		}
+		kvf.Add("Ingress info", out.String())
 	}
 }
 
@@ -135,10 +135,10 @@ func OnlySubcommands(cmd *cobra.Command, args []string) error {
 
 func WithSubCommands(ctx context.Context) context.Context {
 	return MergeSubCommands(ctx,
-		configCmd(), connectCmd(), gatherLogs(), genYAML(), helmCmd(),
-		ingestCmd(), interceptCmd(), kubeauthCmd(), leave(), list(), listContexts(), listNamespaces(), loglevel(), quit(), statusCmd(),
+		clientsCmd(), configCmd(), connectCmd(), gatherLogs(), genYAML(), helmCmd(),
+		historyCmd(), ingestCmd(), interceptCmd(), kubeauthCmd(), leave(), list(), listContexts(), listNamespaces(), loglevel(), quit(), restart(), sessionsCmd(), statusCmd(),
 		dockerRunCmd(), curlCmd(),
-		uninstall(), version(), listNamespaces(), listContexts(),
+		uninstall(), version(), listNamespaces(), listContexts(), workloadWatchCmd(),
 	)
 }
 
@@ -22,6 +22,7 @@ const allAgentsFlag = "all-agents"
 type uninstallCommand struct {
 	agent     bool
 	allAgents bool
+	force     bool
 }
 
 func uninstall() *cobra.Command {
@@ -38,6 +39,7 @@ func uninstall() *cobra.Command {
 	}
 	flags := cmd.Flags()
 	flags.BoolVarP(&ui.allAgents, allAgentsFlag, "a", false, "uninstall intercept agent on all workloads")
+	flags.BoolVar(&ui.force, "force", false, "uninstall agents also when other clients are intercepting them")
 
 	// Hidden from help but will yield a deprecation warning if used
 	flags.BoolVarP(&ui.agent, "agent", "d", false, "")
@@ -66,6 +68,7 @@ func (u *uninstallCommand) run(cmd *cobra.Command, args []string) error {
 	}
 	ur := &connector.UninstallRequest{
 		UninstallType: 0,
+		Force:         u.force,
 	}
 	if u.allAgents {
 		ur.UninstallType = connector.UninstallRequest_ALL_AGENTS
@@ -78,7 +81,14 @@ func (u *uninstallCommand) run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	return errcat.FromResult(r)
+	if err = errcat.FromResult(r); err != nil {
+		return err
+	}
+	if len(r.Data) > 0 {
+		// Summary of the agents that were removed.
+		ioutil.Print(cmd.OutOrStdout(), string(r.Data))
+	}
+	return nil
 }
 
 func validWorkloads(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func workloadWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workload-watch",
+		Short: "Pause and resume the notifications of clients that watch the workloads",
+	}
+	cmd.AddCommand(workloadWatchPause(), workloadWatchResume())
+	return cmd
+}
+
+func workloadWatchPause() *cobra.Command {
+	var duration time.Duration
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Args:  cobra.NoArgs,
+		Short: "Pause the notifications of clients that watch the workloads",
+		Long: `Pause the notifications of clients that watch the workloads, e.g. during a large helm upgrade or rollout
+where the intermediate states of the workloads are of no interest.
+
+The pause ends when "telepresence workload-watch resume" is called with the ID that this command prints, or
+automatically when the given duration has passed. The watching clients then get one update that reflects all
+changes that were made while the watch was paused.`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			rsp, err := daemon.GetUserClient(ctx).PauseWorkloadWatch(ctx, &connector.PauseWorkloadWatchRequest{
+				Duration: durationpb.New(duration),
+			})
+			if err != nil {
+				return err
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, rsp, false)
+				return nil
+			}
+			ioutil.Printf(output.Out(ctx), "Workload watch paused with ID %s. The pause ends in %s.\n", rsp.Id, rsp.Duration.AsDuration())
+			return nil
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+	cmd.Flags().DurationVar(&duration, "duration", 0,
+		"The duration after which the pause ends automatically. It cannot exceed 10 minutes, which is also the default.")
+	return cmd
+}
+
+func workloadWatchResume() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume <id>",
+		Args:  cobra.ExactArgs(1),
+		Short: "End a pause made using \"telepresence workload-watch pause\"",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			_, err := daemon.GetUserClient(ctx).ResumeWorkloadWatch(ctx, &connector.ResumeWorkloadWatchRequest{Id: args[0]})
+			return err
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+}
@@ -362,6 +362,19 @@ func EnsureSession(ctx context.Context, useLine string, required bool) (context.
 	return daemon.WithSession(ctx, s), nil
 }
 
+// namespaceChangesMessage describes the namespaces that a connect to an already connected session started and
+// stopped watching, or returns the empty string when the namespaces didn't change.
+func namespaceChangesMessage(ci *connector.ConnectInfo) string {
+	var parts []string
+	if len(ci.AddedNamespaces) > 0 {
+		parts = append(parts, "now watching: "+strings.Join(ci.AddedNamespaces, ", "))
+	}
+	if len(ci.RemovedNamespaces) > 0 {
+		parts = append(parts, "stopped watching: "+strings.Join(ci.RemovedNamespaces, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
 func connectSession(ctx context.Context, useLine string, userD daemon.UserClient, request *daemon.Request, required bool) (*daemon.Session, error) {
 	var ci *connector.ConnectInfo
 	var err error
@@ -433,8 +446,14 @@ func connectSession(ctx context.Context, useLine string, userD daemon.UserClient
 			if err != nil {
 				dlog.Error(ctx, err)
 			}
+			if ci.VersionWarning != "" {
+				ioutil.Printf(output.Info(ctx), "Warning: %s\n", ci.VersionWarning)
+			}
 			return session(ci, true), nil
 		case connector.ConnectInfo_ALREADY_CONNECTED:
+			if msg := namespaceChangesMessage(ci); msg != "" {
+				ioutil.Println(output.Info(ctx), msg)
+			}
 			return session(ci, false), nil
 		case connector.ConnectInfo_MUST_RESTART:
 			msg = "Cluster configuration changed, please quit telepresence and reconnect"
@@ -455,6 +474,9 @@ func connectSession(ctx context.Context, useLine string, userD daemon.UserClient
 		if ci.Error != connector.ConnectInfo_DISCONNECTED {
 			return connectResult(ci)
 		}
+		if ci.ErrorText != "" {
+			ioutil.Printf(output.Info(ctx), "The previous session ended: %s\n", ci.ErrorText)
+		}
 		if required {
 			ioutil.Printf(output.Info(ctx),
 				`Warning: You are executing the %q command without a preceding "telepresence connect", causing an implicit `+
@@ -483,7 +505,7 @@ func connectSession(ctx context.Context, useLine string, userD daemon.UserClient
 			return nil, errcat.NoDaemonLogs.New(err)
 		}
 	}
-	if ci, err = userD.Connect(ctx, &request.ConnectRequest); err != nil {
+	if ci, err = connectWithProgress(ctx, userD, &request.ConnectRequest); err != nil {
 		if !userD.Containerized() {
 			file := userD.DaemonID().InfoFileName()
 			dlog.Debugf(ctx, "Deleting daemon info %s due to connect error: %v", file, err)
@@ -0,0 +1,72 @@
+package connect
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/spinner"
+)
+
+// connectWithProgress calls Connect on the user daemon and uses a spinner to render the phases that the
+// daemon reports while connecting. A plain Connect is made when the daemon doesn't report progress.
+func connectWithProgress(ctx context.Context, userD daemon.UserClient, cr *connector.ConnectRequest) (*connector.ConnectInfo, error) {
+	wCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := userD.WatchConnectProgress(wCtx, &emptypb.Empty{})
+	if err == nil {
+		// The first message confirms that the subscription is in place.
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		dlog.Debugf(ctx, "connect progress is not available: %v", err)
+		return userD.Connect(ctx, cr)
+	}
+
+	spin := spinner.New(ctx, "connecting")
+	rendered := make(chan struct{})
+	go func() {
+		defer close(rendered)
+		for {
+			cp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if msg := connectPhaseMessage(cp.Phase); msg != "" {
+				spin.Message(msg)
+			}
+		}
+	}()
+	ci, err := userD.Connect(ctx, cr)
+	cancel()
+	<-rendered
+
+	switch {
+	case err != nil:
+		return nil, spin.Error(err)
+	case ci.Error == connector.ConnectInfo_UNSPECIFIED, ci.Error == connector.ConnectInfo_ALREADY_CONNECTED:
+		spin.Done()
+	default:
+		_ = spin.Error(errors.New(ci.ErrorText))
+	}
+	return ci, nil
+}
+
+func connectPhaseMessage(phase connector.ConnectProgress_Phase) string {
+	switch phase {
+	case connector.ConnectProgress_CONNECTING_TO_CLUSTER:
+		return "connecting to cluster"
+	case connector.ConnectProgress_CHECKING_PERMISSIONS:
+		return "checking permissions"
+	case connector.ConnectProgress_CONNECTING_TO_MANAGER:
+		return "connecting to traffic manager"
+	case connector.ConnectProgress_WAITING_FOR_NETWORK:
+		return "waiting for network"
+	default:
+		return ""
+	}
+}
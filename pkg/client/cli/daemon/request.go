@@ -24,6 +24,7 @@ import (
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/global"
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/slice"
@@ -56,6 +57,9 @@ type Request struct {
 
 	// vnats holds the string version for the --vnat flag values.
 	vnats []string
+
+	// onExisting holds the string version of the --on-existing flag value.
+	onExisting string
 }
 
 type CobraRequest struct {
@@ -77,8 +81,23 @@ func InitRequest(cmd *cobra.Command) *CobraRequest {
 		"mapped-namespaces", nil, ``+
 			`Comma separated list of namespaces considered by DNS resolver and NAT for outbound connections. `+
 			`Defaults to all namespaces`)
+	nwFlags.StringSliceVar(&cr.WorkloadPrefixes,
+		"workload-prefixes", nil, ``+
+			`Comma separated list of name prefixes. Only workloads whose name starts with one of them are watched. `+
+			`Defaults to all workloads`)
+	nwFlags.StringToStringVar(&cr.Metadata,
+		"metadata", nil, ``+
+			`Comma separated list of key=value labels to attach to the session, e.g. a build id. `+
+			`The labels are sent to the traffic-manager and shown by telepresence status`)
 	nwFlags.StringVar(&cr.ManagerNamespace, "manager-namespace", "", `The namespace where the traffic manager is to be found. `+
 		`Overrides any other manager namespace set in config`)
+	nwFlags.BoolVar(&cr.CheckPermissions, "check-permissions", false, ``+
+		`Check that the RBAC permissions needed by the session are granted before connecting`)
+	nwFlags.BoolVar(&cr.ForceNewSession, "force-new-session", false, ``+
+		`Depart from the session that the traffic-manager knows this daemon by, and arrive as a new client`)
+	nwFlags.StringVar(&cr.onExisting, "on-existing", "reuse", ``+
+		`What to do when the daemon is already connected. One of "reuse" (keep the session), "error" (fail), or `+
+		`"reconnect" (end the session and connect again)`)
 	nwFlags.StringSliceVar(&cr.AlsoProxy,
 		"also-proxy", nil, ``+
 			`Additional comma separated list of CIDR to proxy`)
@@ -153,6 +172,13 @@ func (cr *CobraRequest) CommitFlags(cmd *cobra.Command) error {
 		return err
 	}
 
+	if cr.OnExisting, err = parseOnExisting(cr.onExisting); err != nil {
+		return errcat.User.New(err)
+	}
+	if err = client.ValidateSessionMetadata(cr.Metadata); err != nil {
+		return errcat.User.New(err)
+	}
+
 	// A --vnat CIDR is the same as --proxy-via CIDR=local
 	for _, vnat := range cr.vnats {
 		cr.proxyVia = append(cr.proxyVia, vnat+"=local")
@@ -199,6 +225,16 @@ func (cr *Request) Commit(ctx context.Context) (context.Context, error) {
 	return context.WithValue(ctx, requestKey{}, cr), nil
 }
 
+func parseOnExisting(s string) (connector.ConnectRequest_OnExisting, error) {
+	if s == "" {
+		return connector.ConnectRequest_REUSE, nil
+	}
+	if v, ok := connector.ConnectRequest_OnExisting_value[strings.ToUpper(s)]; ok {
+		return connector.ConnectRequest_OnExisting(v), nil
+	}
+	return 0, fmt.Errorf(`invalid --on-existing %q, must be one of "reuse", "error", or "reconnect"`, s)
+}
+
 type prefixViaWL struct {
 	subnet   netip.Prefix
 	symbolic string
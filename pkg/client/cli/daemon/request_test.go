@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
 )
 
@@ -200,3 +201,29 @@ func Test_parseProxyVias(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseOnExisting(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    connector.ConnectRequest_OnExisting
+		wantErr bool
+	}{
+		{value: "", want: connector.ConnectRequest_REUSE},
+		{value: "reuse", want: connector.ConnectRequest_REUSE},
+		{value: "error", want: connector.ConnectRequest_ERROR},
+		{value: "Reconnect", want: connector.ConnectRequest_RECONNECT},
+		{value: "replace", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseOnExisting(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseOnExisting(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseOnExisting(%q) got = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,31 +2,33 @@ package env
 
 import (
 	"github.com/spf13/pflag"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/envsyntax"
 )
 
 type Flags struct {
-	File   string // --env-file
-	Syntax Syntax // --env-syntax
-	JSON   string // --env-json
+	File   string           // --env-file
+	Syntax envsyntax.Syntax // --env-syntax
+	JSON   string           // --env-json
 }
 
 func (f *Flags) AddFlags(flagSet *pflag.FlagSet) {
 	flagSet.StringVarP(&f.File, "env-file", "e", "", ``+
 		`Also emit the remote environment to an file. The syntax used in the file can be determined using flag --env-syntax`)
 
-	flagSet.Var(&f.Syntax, "env-syntax", `Syntax used for env-file. One of `+SyntaxUsage())
+	flagSet.Var(&f.Syntax, "env-syntax", `Syntax used for env-file. One of `+envsyntax.SyntaxUsage())
 
 	flagSet.StringVarP(&f.JSON, "env-json", "j", "", `Also emit the remote environment to a file as a JSON blob.`)
 }
 
 func (f *Flags) PerhapsWrite(env map[string]string) error {
 	if f.File != "" {
-		if err := f.Syntax.writeFile(f.File, env); err != nil {
+		if err := f.Syntax.WriteFile(f.File, env); err != nil {
 			return err
 		}
 	}
 	if f.JSON != "" {
-		if err := SyntaxJSON.writeFile(f.JSON, env); err != nil {
+		if err := envsyntax.SyntaxJSON.WriteFile(f.JSON, env); err != nil {
 			return err
 		}
 	}
@@ -3,6 +3,8 @@ package ingest
 import (
 	"context"
 	"io"
+	"strconv"
+	"strings"
 
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/mount"
@@ -15,6 +17,8 @@ type Info struct {
 	Environment  map[string]string `json:"environment,omitempty"     yaml:"environment,omitempty"`
 	Mount        *mount.Info       `json:"mount,omitempty"           yaml:"mount,omitempty"`
 	PodIP        string            `json:"pod_ip,omitempty"          yaml:"pod_ip,omitempty"`
+	LocalPorts   []string          `json:"local_ports,omitempty"     yaml:"local_ports,omitempty"`
+	MountPort    int32             `json:"mount_port,omitempty"      yaml:"mount_port,omitempty"`
 }
 
 func NewInfo(ctx context.Context, ii *rpc.IngestInfo, mountError error) *Info {
@@ -30,6 +34,8 @@ func NewInfo(ctx context.Context, ii *rpc.IngestInfo, mountError error) *Info {
 		Mount:        m,
 		PodIP:        ii.PodIp,
 		Environment:  ii.Environment,
+		LocalPorts:   ii.LocalPorts,
+		MountPort:    ii.LocalMountPort,
 	}
 }
 
@@ -45,5 +51,11 @@ func (ii *Info) WriteTo(w io.Writer) (int64, error) {
 			kvf.Add("Volume Mount Error", m.Error)
 		}
 	}
+	if ii.MountPort != 0 {
+		kvf.Add("Volume Mount Port", strconv.Itoa(int(ii.MountPort)))
+	}
+	if len(ii.LocalPorts) > 0 {
+		kvf.Add("Local Ports", strings.Join(ii.LocalPorts, ", "))
+	}
 	return kvf.WriteTo(w)
 }
@@ -2,11 +2,13 @@ package intercept
 
 import (
 	"errors"
+	"net/netip"
 	"os"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/kubernetes"
@@ -24,6 +26,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/dos"
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 )
 
 type Command struct {
@@ -39,8 +42,31 @@ type Command struct {
 
 	Replace bool // whether --replace was passed
 
+	Force bool // whether --force was passed
+
+	Mirror bool // whether --mirror was passed
+
+	AllConnections bool // whether --all-connections was passed
+
+	WaitForReady time.Duration // --wait-for-ready
+
+	IdleTimeout *time.Duration // --idle-timeout, nil unless given
+
+	DrainPeriod *time.Duration // --drain-period, nil unless given
+
 	ToPod []string // --to-pod
 
+	SourceCIDRs []string // --source-cidr
+
+	GRPCMethods []string // --grpc-method
+
+	PortHeaderFlags []string                    // --port-header
+	PortHeaders     map[int32]map[string]string // PortHeaderFlags keyed by container port
+
+	Preset string // --preset
+
+	GenerateHeader bool // --generate-header
+
 	Cmdline []string // Command[1:]
 
 	Mechanism       string // --mechanism tcp
@@ -77,12 +103,34 @@ func (c *Command) AddFlags(cmd *cobra.Command) {
 		`Use this to, for example, access proxy/helper sidecars in the intercepted pod. The default protocol is TCP. `+
 		`Use <port>/UDP for UDP ports`)
 
+	flagSet.StringSliceVar(&c.SourceCIDRs, "source-cidr", nil, ``+
+		`Only consider requests that originate from the given IP or CIDR intercepted when the API server is `+
+		`consulted. Can be repeated or given as a comma separated list.`)
+
+	flagSet.StringSliceVar(&c.GRPCMethods, "grpc-method", nil, ``+
+		`Only consider gRPC requests for the given method, e.g. /pkg.Service/Method, intercepted when the API `+
+		`server is consulted. A trailing "*" matches all methods with the given prefix, e.g. /pkg.Service/*. `+
+		`Can be repeated or given as a comma separated list.`)
+
+	flagSet.StringArrayVar(&c.PortHeaderFlags, "port-header", nil, ``+
+		`A <container port>:<name>=<value> header that requests for the given container port must have to be `+
+		`considered intercepted when the API server is consulted. The headers of a port are used instead of `+
+		`the headers of the intercept for requests to that port. Can be repeated.`)
+
 	c.EnvFlags.AddFlags(flagSet)
 	c.MountFlags.AddFlags(flagSet, false)
 	c.DockerFlags.AddFlags(flagSet, "intercepted")
 
 	flagSet.StringVar(&c.Mechanism, "mechanism", "tcp", "Which extension `mechanism` to use")
 
+	flagSet.StringVar(&c.Preset, "preset", "", ``+
+		`Name of an intercept preset of the client configuration. The options of the preset are used `+
+		`for the options that aren't given.`)
+
+	flagSet.BoolVar(&c.GenerateHeader, "generate-header", false, ``+
+		`Generate an x-telepresence-intercept-id header for an intercept that uses --mechanism=http without any `+
+		`--http-header, so that only the requests that carry the generated header are intercepted.`)
+
 	flagSet.StringVar(&c.WaitMessage, "wait-message", "", "Message to print when intercept handler has started")
 
 	flagSet.BoolVar(&c.DetailedOutput, "detailed-output", false,
@@ -92,6 +140,32 @@ func (c *Command) AddFlags(cmd *cobra.Command) {
 		`Indicates if the traffic-agent should replace application containers in workload pods. `+
 			`The default behavior is for the agent sidecar to be installed alongside existing containers.`)
 
+	flagSet.BoolVar(&c.Force, "force", false, ``+
+		`Create the intercept even if another client already intercepts the same traffic of the workload.`)
+
+	flagSet.BoolVar(&c.Mirror, "mirror", false, ``+
+		`Send a copy of the intercepted TCP traffic to the local handler instead of redirecting it. The workload `+
+		`keeps serving the traffic and the responses of the local handler are discarded. Cannot be combined with `+
+		`--replace, and requires traffic-agents of version 2.22.0 or later.`)
+
+	flagSet.BoolVar(&c.AllConnections, "all-connections", false, ``+
+		`Consider all requests for the intercepted port intercepted when the API server is consulted, so that `+
+		`every connection is forwarded regardless of HTTP request matching. Cannot be combined with --source-cidr, `+
+		`--grpc-method, or --port-header.`)
+
+	flagSet.DurationVar(&c.WaitForReady, "wait-for-ready", 0, ``+
+		`Wait for at most the given duration for the workload to become available before intercepting it. `+
+		`The default is to not wait.`)
+
+	flagSet.Duration("idle-timeout", 0, ``+
+		`Remove the intercept when it receives no traffic for the given duration. Zero means never. `+
+		`The default is the intercept.idleTimeout of the client configuration.`)
+
+	flagSet.Duration("drain-period", 0, ``+
+		`Let the connections that are in-flight when the intercept is removed drain for the given duration `+
+		`before they're closed. New connections are no longer intercepted during that time. Zero means that `+
+		`they're closed immediately. The default is the intercept.drainPeriod of the client configuration.`)
+
 	_ = cmd.RegisterFlagCompletionFunc("container", ingest.AutocompleteContainer)
 	_ = cmd.RegisterFlagCompletionFunc("service", autocompleteService)
 }
@@ -108,12 +182,62 @@ func (c *Command) Validate(cmd *cobra.Command, positional []string) error {
 	if c.AgentName == "" {
 		c.AgentName = c.Name
 	}
-	if c.Port == "" {
+	flags := cmd.Flags()
+	if c.Preset != "" && !flags.Changed("mechanism") {
+		// Let the preset decide the mechanism. The user daemon uses "tcp" when it doesn't.
+		c.Mechanism = ""
+	}
+	if c.Port == "" && c.Preset == "" {
 		// Port defaults to the targeted container port unless a default is explicitly set in the client config.
 		if dp := client.GetConfig(cmd.Context()).Intercept().DefaultPort; dp != 0 {
 			c.Port = strconv.Itoa(dp)
 		}
 	}
+	if flags.Changed("idle-timeout") {
+		it, err := flags.GetDuration("idle-timeout")
+		if err != nil {
+			return err
+		}
+		if it < 0 {
+			return errcat.User.New("--idle-timeout cannot be negative")
+		}
+		c.IdleTimeout = &it
+	}
+	if flags.Changed("drain-period") {
+		dp, err := flags.GetDuration("drain-period")
+		if err != nil {
+			return err
+		}
+		if dp < 0 {
+			return errcat.User.New("--drain-period cannot be negative")
+		}
+		c.DrainPeriod = &dp
+	}
+	for _, sc := range c.SourceCIDRs {
+		if _, err := netip.ParsePrefix(sc); err != nil {
+			if _, err = netip.ParseAddr(sc); err != nil {
+				return errcat.User.Newf("--source-cidr %s is not a valid IP or CIDR", sc)
+			}
+		}
+	}
+	for _, gm := range c.GRPCMethods {
+		if _, err := matcher.NewGRPCMethod(gm); err != nil {
+			return errcat.User.Newf("--grpc-method: %v", err)
+		}
+	}
+	if err := c.parsePortHeaders(); err != nil {
+		return err
+	}
+	if c.AllConnections {
+		for _, f := range []string{"source-cidr", "grpc-method", "port-header"} {
+			if flags.Changed(f) {
+				return errcat.User.Newf("--all-connections cannot be used with --%s", f)
+			}
+		}
+	}
+	if c.Mirror && c.Replace {
+		return errcat.User.New("--mirror cannot be used with --replace")
+	}
 	if err := c.MountFlags.Validate(cmd); err != nil {
 		return err
 	}
@@ -123,6 +247,40 @@ func (c *Command) Validate(cmd *cobra.Command, positional []string) error {
 	return c.DockerFlags.Validate(c.Cmdline)
 }
 
+// parsePortHeaders parses the PortHeaderFlags into PortHeaders.
+func (c *Command) parsePortHeaders() error {
+	c.PortHeaders = nil
+	for _, ph := range c.PortHeaderFlags {
+		ps, header, ok := strings.Cut(ph, ":")
+		if !ok {
+			return errcat.User.Newf("--port-header %s is not in the form <container port>:<name>=<value>", ph)
+		}
+		port, err := strconv.ParseUint(ps, 10, 16)
+		if err != nil || port == 0 {
+			return errcat.User.Newf("--port-header %s has an invalid container port %q", ph, ps)
+		}
+		name, value, ok := strings.Cut(header, "=")
+		if !ok || name == "" {
+			return errcat.User.Newf("--port-header %s is not in the form <container port>:<name>=<value>", ph)
+		}
+		if c.PortHeaders == nil {
+			c.PortHeaders = make(map[int32]map[string]string)
+		}
+		hs, ok := c.PortHeaders[int32(port)]
+		if !ok {
+			hs = make(map[string]string)
+			c.PortHeaders[int32(port)] = hs
+		}
+		hs[name] = value
+	}
+	for port, hs := range c.PortHeaders {
+		if _, err := matcher.NewRequestFromMap(hs); err != nil {
+			return errcat.User.Newf("--port-header for port %d: %v", port, err)
+		}
+	}
+	return nil
+}
+
 func (c *Command) Run(cmd *cobra.Command, positional []string) error {
 	if err := c.Validate(cmd, positional); err != nil {
 		return err
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"net"
+	"slices"
 	"strings"
 
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
@@ -21,27 +23,29 @@ type Ingress struct {
 }
 
 type Info struct {
-	ID            string            `json:"id,omitempty"              yaml:"id,omitempty"`
-	Name          string            `json:"name,omitempty"            yaml:"name,omitempty"`
-	Disposition   string            `json:"disposition,omitempty"     yaml:"disposition,omitempty"`
-	Message       string            `json:"message,omitempty"         yaml:"message,omitempty"`
-	WorkloadKind  string            `json:"workload_kind,omitempty"   yaml:"workload_kind,omitempty"`
-	TargetHost    string            `json:"target_host,omitempty"     yaml:"target_host,omitempty"`
-	TargetPort    int32             `json:"target_port,omitempty"     yaml:"target_port,omitempty"`
-	ServiceUID    string            `json:"service_uid,omitempty"     yaml:"service_uid,omitempty"`
-	ServicePortID string            `json:"service_port_id,omitempty" yaml:"service_port_id,omitempty"` // ServicePortID is deprecated. Use PortID
-	PortID        string            `json:"port_id,omitempty"         yaml:"port_id,omitempty"`
-	ContainerPort int32             `json:"container_port,omitempty"  yaml:"container_port,omitempty"`
-	Environment   map[string]string `json:"environment,omitempty"     yaml:"environment,omitempty"`
-	Mount         *mount.Info       `json:"mount,omitempty"           yaml:"mount,omitempty"`
-	FilterDesc    string            `json:"filter_desc,omitempty"     yaml:"filter_desc,omitempty"`
-	Metadata      map[string]string `json:"metadata,omitempty"        yaml:"metadata,omitempty"`
-	HttpFilter    []string          `json:"http_filter,omitempty"     yaml:"http_filter,omitempty"`
-	Global        bool              `json:"global,omitempty"          yaml:"global,omitempty"`
-	PreviewURL    string            `json:"preview_url,omitempty"     yaml:"preview_url,omitempty"`
-	Ingress       *Ingress          `json:"ingress,omitempty"         yaml:"ingress,omitempty"`
-	PodIP         string            `json:"pod_ip,omitempty"          yaml:"pod_ip,omitempty"`
-	debug         bool
+	ID               string            `json:"id,omitempty"                yaml:"id,omitempty"`
+	Name             string            `json:"name,omitempty"              yaml:"name,omitempty"`
+	Disposition      string            `json:"disposition,omitempty"       yaml:"disposition,omitempty"`
+	Message          string            `json:"message,omitempty"           yaml:"message,omitempty"`
+	WorkloadKind     string            `json:"workload_kind,omitempty"     yaml:"workload_kind,omitempty"`
+	TargetHost       string            `json:"target_host,omitempty"       yaml:"target_host,omitempty"`
+	TargetPort       int32             `json:"target_port,omitempty"       yaml:"target_port,omitempty"`
+	ServiceUID       string            `json:"service_uid,omitempty"       yaml:"service_uid,omitempty"`
+	ServicePortID    string            `json:"service_port_id,omitempty"   yaml:"service_port_id,omitempty"` // ServicePortID is deprecated. Use PortID
+	PortID           string            `json:"port_id,omitempty"           yaml:"port_id,omitempty"`
+	ContainerPort    int32             `json:"container_port,omitempty"    yaml:"container_port,omitempty"`
+	Environment      map[string]string `json:"environment,omitempty"       yaml:"environment,omitempty"`
+	Mount            *mount.Info       `json:"mount,omitempty"             yaml:"mount,omitempty"`
+	FilterDesc       string            `json:"filter_desc,omitempty"       yaml:"filter_desc,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"          yaml:"metadata,omitempty"`
+	HttpFilter       []string          `json:"http_filter,omitempty"       yaml:"http_filter,omitempty"`
+	Global           bool              `json:"global,omitempty"            yaml:"global,omitempty"`
+	Mirror           bool              `json:"mirror,omitempty"            yaml:"mirror,omitempty"`
+	PreviewURL       string            `json:"preview_url,omitempty"       yaml:"preview_url,omitempty"`
+	Ingress          *Ingress          `json:"ingress,omitempty"           yaml:"ingress,omitempty"`
+	PodIP            string            `json:"pod_ip,omitempty"            yaml:"pod_ip,omitempty"`
+	GeneratedHeaders map[string]string `json:"generated_headers,omitempty" yaml:"generated_headers,omitempty"`
+	debug            bool
 }
 
 func NewIngress(ps *manager.PreviewSpec) *Ingress {
@@ -93,6 +97,7 @@ func NewInfo(ctx context.Context, ii *manager.InterceptInfo, ro bool, mountError
 		Metadata:      ii.Metadata,
 		HttpFilter:    spec.MechanismArgs,
 		Global:        spec.Mechanism == "tcp",
+		Mirror:        spec.Mirror,
 		PreviewURL:    PreviewURL(ii.PreviewDomain),
 		Ingress:       NewIngress(ii.PreviewSpec),
 	}
@@ -155,14 +160,23 @@ func (ii *Info) WriteTo(w io.Writer) (int64, error) {
 	}
 
 	kvf.Add("Intercepting", func() string {
-		if ii.FilterDesc != "" {
-			return ii.FilterDesc
+		var desc string
+		switch {
+		case ii.FilterDesc != "":
+			desc = ii.FilterDesc
+		case ii.Global:
+			desc = `using mechanism "tcp"`
+		default:
+			desc = fmt.Sprintf("using mechanism=%q with args=%q", "http", ii.HttpFilter)
 		}
-		if ii.Global {
-			return `using mechanism "tcp"`
+		if ii.Mirror {
+			desc += ", mirroring the traffic"
 		}
-		return fmt.Sprintf("using mechanism=%q with args=%q", "http", ii.HttpFilter)
+		return desc
 	}())
+	for _, k := range slices.Sorted(maps.Keys(ii.GeneratedHeaders)) {
+		kvf.Add("Request header", fmt.Sprintf("%s: %s", k, ii.GeneratedHeaders[k]))
+	}
 	if ii.ServiceUID == "" {
 		kvf.Add("Address", iputil.JoinHostPort(ii.PodIP, uint16(ii.ContainerPort)))
 	}
@@ -0,0 +1,96 @@
+package intercept
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/spinner"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+// waitForReady waits until the workload watcher of the user daemon reports that the workload with the given
+// name in the given namespace is available, or until the timeout expires. The progress is reported using a
+// spinner.
+func waitForReady(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ud := daemon.GetUserClient(ctx)
+	stream, err := ud.WatchWorkloads(ctx, &connector.WatchWorkloadsRequest{
+		Namespaces: []string{namespace},
+		// The workload can be of any kind, so the list.defaultKinds of the client configuration don't apply.
+		Kinds: []string{
+			string(workload.DeploymentKind),
+			string(workload.ReplicaSetKind),
+			string(workload.StatefulSetKind),
+			string(workload.RolloutKind),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	spin := spinner.New(ctx, "waiting for workload "+name+" to become ready")
+	state, err := awaitReady(ctx, stream.Recv, namespace, name, spin.Message)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = errcat.User.Newf("timeout after %s waiting for workload %s.%s to become ready (state: %s)", timeout, name, namespace, state)
+		}
+		return spin.Error(err)
+	}
+	spin.Done()
+	return nil
+}
+
+// awaitReady receives workload snapshots until one of them reports the workload with the given name and namespace
+// as available. The progress function is called with the state of the workload each time that state changes. The
+// last known state is returned.
+//
+// An error is returned if the workload doesn't exist once the initial synchronization of the namespace completes,
+// or when it cannot be intercepted for a reason that waiting won't remedy.
+func awaitReady(
+	ctx context.Context,
+	recv func() (*connector.WorkloadInfoSnapshot, error),
+	namespace, name string,
+	progress func(string),
+) (string, error) {
+	state := "Unknown"
+	synced := false
+	for {
+		snap, err := recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return state, err
+		}
+		synced = synced || slices.Contains(snap.SyncedNamespaces, namespace)
+		idx := slices.IndexFunc(snap.Workloads, func(wi *connector.WorkloadInfo) bool {
+			return wi.Name == name && wi.Namespace == namespace
+		})
+		if idx < 0 {
+			if synced {
+				return state, errcat.User.Newf("workload %s.%s not found", name, namespace)
+			}
+			continue
+		}
+		wi := snap.Workloads[idx]
+		reason := wi.NotInterceptableReason
+		switch wi.NotInterceptableCode {
+		case connector.WorkloadInfo_INTERCEPTABLE:
+			return "Available", nil
+		case connector.WorkloadInfo_PROGRESSING, connector.WorkloadInfo_NOT_AVAILABLE:
+			// The workload may still become available.
+		default:
+			return reason, errcat.User.Newf("workload %s.%s cannot be intercepted: %s", name, namespace, reason)
+		}
+		if reason != state {
+			state = reason
+			progress(state)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package intercept
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+)
+
+func snapshots(snaps ...*connector.WorkloadInfoSnapshot) func() (*connector.WorkloadInfoSnapshot, error) {
+	return func() (*connector.WorkloadInfoSnapshot, error) {
+		if len(snaps) == 0 {
+			return nil, io.EOF
+		}
+		snap := snaps[0]
+		snaps = snaps[1:]
+		return snap, nil
+	}
+}
+
+func echoSnapshot(reason string, synced ...string) *connector.WorkloadInfoSnapshot {
+	var code connector.WorkloadInfo_NotInterceptableCode
+	switch reason {
+	case "":
+	case "Progressing":
+		code = connector.WorkloadInfo_PROGRESSING
+	case "Failure":
+		code = connector.WorkloadInfo_NOT_AVAILABLE
+	default:
+		code = connector.WorkloadInfo_NOT_EXPOSED
+	}
+	return &connector.WorkloadInfoSnapshot{
+		Workloads: []*connector.WorkloadInfo{
+			{Name: "other", Namespace: "default"},
+			{Name: "echo", Namespace: "default", NotInterceptableCode: code, NotInterceptableReason: reason},
+		},
+		SyncedNamespaces: synced,
+	}
+}
+
+func Test_awaitReady(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+
+	t.Run("progressing to available", func(t *testing.T) {
+		var progress []string
+		state, err := awaitReady(ctx, snapshots(
+			echoSnapshot("Progressing", "default"),
+			echoSnapshot("Progressing"),
+			echoSnapshot("Failure"),
+			echoSnapshot("Progressing"),
+			echoSnapshot(""),
+		), "default", "echo", func(s string) { progress = append(progress, s) })
+		require.NoError(t, err)
+		assert.Equal(t, "Available", state)
+		assert.Equal(t, []string{"Progressing", "Failure", "Progressing"}, progress)
+	})
+
+	t.Run("not exposed", func(t *testing.T) {
+		var progress []string
+		state, err := awaitReady(ctx, snapshots(
+			echoSnapshot("Progressing", "default"),
+			echoSnapshot("No service selects the workload"),
+			echoSnapshot(""),
+		), "default", "echo", func(s string) { progress = append(progress, s) })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be intercepted: No service selects the workload")
+		assert.Equal(t, "No service selects the workload", state)
+		assert.Equal(t, []string{"Progressing"}, progress)
+	})
+
+	t.Run("not found after sync", func(t *testing.T) {
+		_, err := awaitReady(ctx, snapshots(
+			&connector.WorkloadInfoSnapshot{},
+			&connector.WorkloadInfoSnapshot{SyncedNamespaces: []string{"default"}},
+		), "default", "echo", func(string) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cCtx, cancel := context.WithCancel(ctx)
+		recv := snapshots(echoSnapshot("Progressing", "default"))
+		state, err := awaitReady(cCtx, func() (*connector.WorkloadInfoSnapshot, error) {
+			snap, err := recv()
+			if err != nil {
+				cancel()
+			}
+			return snap, err
+		}, "default", "echo", func(string) {})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "Progressing", state)
+	})
+}
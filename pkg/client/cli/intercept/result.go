@@ -67,10 +67,15 @@ func Result(r *connector.InterceptResult, err error) error {
 		msg = fmt.Sprintf("Intercept named %q not found", r.ErrorText)
 	case common.InterceptError_MOUNT_POINT_BUSY:
 		msg = fmt.Sprintf("Mount point already in use by intercept %q", r.ErrorText)
-	case common.InterceptError_MISCONFIGURED_WORKLOAD:
+	case common.InterceptError_MISCONFIGURED_WORKLOAD, common.InterceptError_INTERCEPT_CONFLICT, common.InterceptError_INVALID_VALUE:
 		msg = r.ErrorText
 	case common.InterceptError_UNKNOWN_FLAG:
 		msg = fmt.Sprintf("Unknown flag: %s", r.ErrorText)
+	case common.InterceptError_UNKNOWN_PRESET:
+		msg = fmt.Sprintf("Unknown intercept preset %q", r.ErrorText)
+	case common.InterceptError_TOO_MANY_INTERCEPTS:
+		msg = fmt.Sprintf("Cannot have more than %s concurrent intercepts in a session. Leave an intercept first, or "+
+			"increase the intercept.maxConcurrent setting of the client configuration.", r.ErrorText)
 	default:
 		msg = fmt.Sprintf("Unknown error code %d", r.Error)
 	}
@@ -10,6 +10,7 @@ import (
 
 	grpcCodes "google.golang.org/grpc/codes"
 	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/datawire/dlib/dlog"
@@ -68,8 +69,10 @@ func (s *state) SetSelf(self State) {
 
 func (s *state) CreateRequest(ctx context.Context) (*connector.CreateInterceptRequest, error) {
 	spec := &manager.InterceptSpec{
-		Name:    s.Name(),
-		Replace: s.Replace,
+		Name:           s.Name(),
+		Replace:        s.Replace,
+		Mirror:         s.Mirror,
+		AllConnections: s.AllConnections,
 	}
 	ir := &connector.CreateInterceptRequest{
 		Spec:           spec,
@@ -77,12 +80,30 @@ func (s *state) CreateRequest(ctx context.Context) (*connector.CreateInterceptRe
 		LocalMountPort: int32(s.MountFlags.LocalMountPort),
 		MountPoint:     s.MountFlags.Mount,
 		MountReadOnly:  s.MountFlags.ReadOnly,
+		MountReadWrite: s.MountFlags.ReadWrite,
+		Force:          s.Force,
+		Preset:         s.Preset,
+		GenerateHeader: s.GenerateHeader,
+	}
+	if s.IdleTimeout != nil {
+		ir.IdleTimeout = durationpb.New(*s.IdleTimeout)
+	}
+	if s.DrainPeriod != nil {
+		ir.DrainPeriod = durationpb.New(*s.DrainPeriod)
 	}
 
 	spec.ServiceName = s.ServiceName
 	spec.ContainerName = s.ContainerName
 	spec.Mechanism = s.Mechanism
 	spec.MechanismArgs = s.MechanismArgs
+	spec.SourceCidrs = s.SourceCIDRs
+	spec.GrpcMethods = s.GRPCMethods
+	for port, hs := range s.PortHeaders {
+		if spec.PortHeaders == nil {
+			spec.PortHeaders = make(map[int32]*manager.PortHeaders, len(s.PortHeaders))
+		}
+		spec.PortHeaders[port] = &manager.PortHeaders{Headers: hs}
+	}
 	spec.Agent = s.AgentName
 	spec.TargetHost = "127.0.0.1"
 
@@ -188,6 +209,16 @@ func (s *state) create(ctx context.Context) (acquired bool, err error) {
 		}
 	}()
 
+	if s.WaitForReady > 0 && ir.Spec.Agent != "" {
+		ns := ir.Spec.Namespace
+		if ns == "" {
+			ns = s.status.Namespace
+		}
+		if err = waitForReady(ctx, ns, ir.Spec.Agent, s.WaitForReady); err != nil {
+			return false, err
+		}
+	}
+
 	// Submit the request
 	r, err := ud.CreateIntercept(ctx, ir)
 	if err = Result(r, err); err != nil {
@@ -235,6 +266,7 @@ func (s *state) create(ctx context.Context) (acquired bool, err error) {
 	}
 
 	s.info = NewInfo(ctx, intercept, s.MountFlags.ReadOnly, s.mountError)
+	s.info.GeneratedHeaders = r.GeneratedHeaders
 	if !s.Silent {
 		if detailedOutput {
 			output.Object(ctx, s.info, true)
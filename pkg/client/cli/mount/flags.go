@@ -23,13 +23,15 @@ type Flags struct {
 	Mount          string // --mount // "true", "false", or desired mount point
 	Enabled        bool
 	ReadOnly       bool
+	ReadWrite      bool
 }
 
 func (f *Flags) AddFlags(flagSet *pflag.FlagSet, forceReadOnly bool) {
 	mountText := `The absolute path for the root directory where volumes will be mounted, $TELEPRESENCE_ROOT. Use "true" to ` +
 		`have Telepresence pick a random mount point (default). Use "false" to disable filesystem mounting entirely.`
 	if !forceReadOnly {
-		mountText += ` Append ":ro" to mount everything read-only.`
+		mountText += ` Append ":ro" to mount everything read-only, or ":rw" to mount everything read-write even when ` +
+			`an intercept preset mounts read-only.`
 	}
 	flagSet.StringVar(&f.Mount, "mount", "true", mountText)
 
@@ -47,9 +49,13 @@ func (f *Flags) Validate(cmd *cobra.Command) error {
 		f.Mount = "" // Get rid of the default string "true"
 		f.Enabled = true
 	} else if len(f.Mount) > 0 {
-		if strings.HasSuffix(f.Mount, ":ro") {
+		switch {
+		case strings.HasSuffix(f.Mount, ":ro"):
 			f.ReadOnly = true
 			f.Mount = f.Mount[:len(f.Mount)-3]
+		case strings.HasSuffix(f.Mount, ":rw") && !f.ReadOnly:
+			f.ReadWrite = true
+			f.Mount = f.Mount[:len(f.Mount)-3]
 		}
 		doMount, err := strconv.ParseBool(f.Mount)
 		if err != nil {
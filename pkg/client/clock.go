@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/datawire/dlib/dtime"
+)
+
+// Clock is the clock used by the time based loops of the daemons. Tests use WithClock to replace it
+// with a fake clock, so that time can be advanced without real sleeps. Unlike dtime, which only fakes
+// the current time, a Clock also provides timers and tickers. The default Clock tells time using dtime,
+// so that the two always agree.
+type Clock = clock.WithTicker
+
+// dtimeClock is a real clock that tells time using dtime.
+type dtimeClock struct {
+	clock.RealClock
+}
+
+func (dtimeClock) Now() time.Time {
+	return dtime.Now()
+}
+
+func (dtimeClock) Since(t time.Time) time.Duration {
+	return dtime.Now().Sub(t)
+}
+
+type clockKey struct{}
+
+// WithClock returns a context with the given Clock.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// GetClock returns the Clock of the given context, or a real clock that tells time using dtime if no
+// Clock has been set.
+func GetClock(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return dtimeClock{}
+}
+
+// SleepWithContext pauses the current goroutine for at least the duration d, or until the context is
+// done, using the Clock of the context.
+func SleepWithContext(ctx context.Context, d time.Duration) {
+	t := GetClock(ctx).NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C():
+	}
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/datawire/dlib/dtime"
+)
+
+func TestGetClock(t *testing.T) {
+	ft := dtime.NewFakeTime()
+	dtime.SetNow(ft.Now)
+	t.Cleanup(func() { dtime.SetNow(time.Now) })
+
+	// The default clock agrees with dtime.
+	clk := GetClock(context.Background())
+	assert.True(t, ft.Now().Equal(clk.Now()))
+	start := clk.Now()
+	ft.Step(time.Minute)
+	assert.Equal(t, time.Minute, clk.Since(start))
+
+	// A clock set in the context takes precedence.
+	fc := clocktesting.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.True(t, fc.Now().Equal(GetClock(WithClock(context.Background(), fc)).Now()))
+}
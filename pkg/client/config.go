@@ -20,6 +20,7 @@ import (
 	"github.com/go-json-experiment/json"
 	"github.com/go-json-experiment/json/jsontext"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/yaml"
@@ -108,6 +109,12 @@ type Config interface {
 	Cluster() *Cluster
 	DNS() *DNS
 	Routing() *Routing
+	Audit() *Audit
+	Workloads() *Workloads
+	List() *List
+	Namespaces() *Namespaces
+	Session() *Session
+	Telemetry() *Telemetry
 	DestructiveMerge(Config)
 	Merge(priority Config) Config
 }
@@ -124,6 +131,12 @@ type BaseConfig struct {
 	ClusterV         Cluster         `json:"cluster,omitzero"`
 	DNSV             DNS             `json:"dns,omitzero"`
 	RoutingV         Routing         `json:"routing,omitzero"`
+	AuditV           Audit           `json:"audit,omitzero"`
+	WorkloadsV       Workloads       `json:"workloads,omitzero"`
+	ListV            List            `json:"list,omitzero"`
+	NamespacesV      Namespaces      `json:"namespaces,omitzero"`
+	SessionV         Session         `json:"session,omitzero"`
+	TelemetryV       Telemetry       `json:"telemetry,omitzero"`
 
 	// This is actually a traffic-manager setting, and controls
 	// the agent's connection to the client.
@@ -174,6 +187,30 @@ func (c *BaseConfig) Routing() *Routing {
 	return &c.RoutingV
 }
 
+func (c *BaseConfig) Audit() *Audit {
+	return &c.AuditV
+}
+
+func (c *BaseConfig) Workloads() *Workloads {
+	return &c.WorkloadsV
+}
+
+func (c *BaseConfig) List() *List {
+	return &c.ListV
+}
+
+func (c *BaseConfig) Namespaces() *Namespaces {
+	return &c.NamespacesV
+}
+
+func (c *BaseConfig) Session() *Session {
+	return &c.SessionV
+}
+
+func (c *BaseConfig) Telemetry() *Telemetry {
+	return &c.TelemetryV
+}
+
 func (c *BaseConfig) MarshalYAML() ([]byte, error) {
 	data, err := MarshalJSON(c)
 	if err == nil {
@@ -218,8 +255,11 @@ func UnmarshalJSONConfig(data []byte, rejectUnknown bool) (Config, error) {
 	return cfg, nil
 }
 
-func ParseConfigYAML(ctx context.Context, path string, data []byte) (Config, error) {
-	data, err := yaml.YAMLToJSON(data)
+// ParseConfigYAML parses the given YAML into a Config. Unknown keys are logged and ignored unless strict
+// parsing is enabled using WithStrictConfig or the TELEPRESENCE_STRICT_CONFIG environment variable, in which
+// case they result in an error that reports the offending key and its location.
+func ParseConfigYAML(ctx context.Context, path string, yml []byte) (Config, error) {
+	data, err := yaml.YAMLToJSON(yml)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +267,9 @@ func ParseConfigYAML(ctx context.Context, path string, data []byte) (Config, err
 	if err != nil {
 		var semanticErr *json.SemanticError
 		if errors.As(err, &semanticErr) && strings.Contains(semanticErr.Error(), "unknown object member name ") {
+			if IsStrictConfig(ctx) {
+				return nil, unknownKeyError(path, yml, semanticErr)
+			}
 			s := semanticErr.Error()
 			// Strip unnecessarily verbose text from the message, but retain the type.
 			if m := regexp.MustCompile(`json:.+ of type (.*)$`).FindStringSubmatch(s); len(m) == 2 {
@@ -262,6 +305,12 @@ func (c *BaseConfig) DestructiveMerge(lc Config) {
 	c.ClusterV.merge(lc.Cluster())
 	c.DNSV.merge(lc.DNS())
 	c.RoutingV.merge(lc.Routing())
+	c.AuditV.merge(lc.Audit())
+	c.WorkloadsV.merge(lc.Workloads())
+	c.ListV.merge(lc.List())
+	c.NamespacesV.merge(lc.Namespaces())
+	c.SessionV.merge(lc.Session())
+	c.TelemetryV.merge(lc.Telemetry())
 }
 
 func (c *BaseConfig) Merge(lc Config) Config {
@@ -359,6 +408,10 @@ type Timeouts struct {
 	PrivateFtpShutdown time.Duration `json:"ftpShutdown"`
 	// PrivateContainerShutdown max time to wait for a docker container to stop before forcing termination.
 	PrivateContainerShutdown time.Duration `json:"containerShutdown"`
+	// PrivatePodDaemonConnect max total time that a pod daemon will keep retrying to connect to the traffic-manager.
+	PrivatePodDaemonConnect time.Duration `json:"podDaemonConnect"`
+	// PrivateRootDaemonDial max time to wait for the root daemon to accept a connection on its socket.
+	PrivateRootDaemonDial time.Duration `json:"rootDaemonDial"`
 }
 
 type TimeoutID int
@@ -376,6 +429,8 @@ const (
 	TimeoutFtpReadWrite
 	TimeoutFtpShutdown
 	TimeoutContainerShutdown
+	TimeoutPodDaemonConnect
+	TimeoutRootDaemonDial
 )
 
 type timeoutContext struct {
@@ -424,6 +479,10 @@ func (t *Timeouts) Get(timeoutID TimeoutID) time.Duration {
 		timeoutVal = t.PrivateFtpShutdown
 	case TimeoutContainerShutdown:
 		timeoutVal = t.PrivateContainerShutdown
+	case TimeoutPodDaemonConnect:
+		timeoutVal = t.PrivatePodDaemonConnect
+	case TimeoutRootDaemonDial:
+		timeoutVal = t.PrivateRootDaemonDial
 	default:
 		panic("should not happen")
 	}
@@ -487,6 +546,12 @@ func (e timeoutError) Error() string {
 	case TimeoutContainerShutdown:
 		yamlName = "containerShutdown"
 		humanName = "Docker container shutdown grace period"
+	case TimeoutPodDaemonConnect:
+		yamlName = "podDaemonConnect"
+		humanName = "pod daemon connect retry period"
+	case TimeoutRootDaemonDial:
+		yamlName = "rootDaemonDial"
+		humanName = "root daemon socket dial"
 	default:
 		panic("should not happen")
 	}
@@ -518,6 +583,8 @@ const (
 	defaultTimeoutsFtpReadWrite          = 1 * time.Minute
 	defaultTimeoutsFtpShutdown           = 2 * time.Minute
 	defaultTimeoutsContainerShutdown     = 0
+	defaultTimeoutsPodDaemonConnect      = 2 * time.Minute
+	defaultTimeoutsRootDaemonDial        = 10 * time.Second
 	maxTimeoutsConnectivityCheck         = 5 * time.Second
 )
 
@@ -534,6 +601,8 @@ var defaultTimeouts = Timeouts{ //nolint:gochecknoglobals // constant
 	PrivateFtpReadWrite:          defaultTimeoutsFtpReadWrite,
 	PrivateFtpShutdown:           defaultTimeoutsFtpShutdown,
 	PrivateContainerShutdown:     defaultTimeoutsContainerShutdown,
+	PrivatePodDaemonConnect:      defaultTimeoutsPodDaemonConnect,
+	PrivateRootDaemonDial:        defaultTimeoutsRootDaemonDial,
 }
 
 func (t *Timeouts) defaults() DefaultsAware {
@@ -579,6 +648,9 @@ var defaultLogLevels = LogLevels{ //nolint:gochecknoglobals // constant
 type LogLevels struct {
 	UserDaemon logrus.Level `json:"userDaemon"`
 	RootDaemon logrus.Level `json:"rootDaemon"`
+
+	// Subsystems overrides the level of the daemons for individual subsystems, keyed by subsystem name.
+	Subsystems map[string]logrus.Level `json:"subsystems"`
 }
 
 func (ll *LogLevels) defaults() DefaultsAware {
@@ -592,7 +664,7 @@ func (ll *LogLevels) merge(o *LogLevels) {
 
 // IsZero controls whether this element will be included in marshalled output.
 func (ll *LogLevels) IsZero() bool {
-	return ll == nil || *ll == defaultLogLevels
+	return ll == nil || isDefault(ll)
 }
 
 func (ll *LogLevels) MarshalJSONV2(out *jsontext.Encoder, opts json.Options) error {
@@ -682,6 +754,10 @@ type Grpc struct {
 	// MaxReceiveSize is the maximum message size in bytes the client can receive in a gRPC call or stream message.
 	// Overrides the gRPC default of 4MB.
 	MaxReceiveSizeV resource.Quantity `json:"maxReceiveSize"`
+
+	// Compression is the name of the compressor, e.g. "gzip", that is used for the calls to the traffic-manager.
+	// Calls aren't compressed when it's empty.
+	Compression string `json:"compression"`
 }
 
 func (g *Grpc) MaxReceiveSize() int64 {
@@ -697,11 +773,270 @@ func (g *Grpc) merge(o *Grpc) {
 	if !o.MaxReceiveSizeV.IsZero() {
 		g.MaxReceiveSizeV = o.MaxReceiveSizeV
 	}
+	if o.Compression != "" {
+		g.Compression = o.Compression
+	}
 }
 
 // IsZero controls whether this element will be included in marshalled output.
 func (g *Grpc) IsZero() bool {
-	return g == nil || g.MaxReceiveSizeV.IsZero()
+	return g == nil || g.MaxReceiveSizeV.IsZero() && g.Compression == ""
+}
+
+const (
+	defaultAuditMaxSize  = 10 * 1024 * 1024
+	defaultAuditMaxFiles = 3
+)
+
+type Audit struct {
+	// File is the path of the workload event audit log. Auditing is disabled when it's empty.
+	File string `json:"file"`
+
+	// MaxSizeV is the size that the audit log may reach before it's rotated.
+	MaxSizeV resource.Quantity `json:"maxSize"`
+
+	// MaxFilesV is the number of rotated audit logs to keep.
+	MaxFilesV int `json:"maxFiles"`
+}
+
+func (a *Audit) MaxSize() int64 {
+	if !a.MaxSizeV.IsZero() {
+		if mz, ok := a.MaxSizeV.AsInt64(); ok && mz > 0 {
+			return mz
+		}
+	}
+	return defaultAuditMaxSize
+}
+
+func (a *Audit) MaxFiles() int {
+	if a.MaxFilesV > 0 {
+		return a.MaxFilesV
+	}
+	return defaultAuditMaxFiles
+}
+
+func (a *Audit) merge(o *Audit) {
+	if o.File != "" {
+		a.File = o.File
+	}
+	if !o.MaxSizeV.IsZero() {
+		a.MaxSizeV = o.MaxSizeV
+	}
+	if o.MaxFilesV != 0 {
+		a.MaxFilesV = o.MaxFilesV
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (a *Audit) IsZero() bool {
+	return a == nil || a.File == "" && a.MaxSizeV.IsZero() && a.MaxFilesV == 0
+}
+
+type Workloads struct {
+	// Prewarm controls whether the workload watchers of all mapped namespaces are started in the background
+	// when a session starts, instead of when the workloads are first listed.
+	Prewarm bool `json:"prewarm"`
+
+	// NamePrefixes limits the workloads that are watched to those whose name starts with one of the prefixes.
+	// All workloads are watched when it's empty.
+	NamePrefixes []string `json:"namePrefixes"`
+
+	// ResyncPeriod is the interval at which the informers of the workload watchers resync their caches,
+	// causing all workloads to be reconsidered. The informers never resync when it's zero.
+	ResyncPeriod time.Duration `json:"resyncPeriod"`
+}
+
+func (w *Workloads) merge(o *Workloads) {
+	if o.Prewarm {
+		w.Prewarm = true
+	}
+	if len(o.NamePrefixes) > 0 {
+		w.NamePrefixes = o.NamePrefixes
+	}
+	if o.ResyncPeriod != 0 {
+		w.ResyncPeriod = o.ResyncPeriod
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (w *Workloads) IsZero() bool {
+	return w == nil || !w.Prewarm && len(w.NamePrefixes) == 0 && w.ResyncPeriod == 0
+}
+
+type List struct {
+	// DefaultKinds are the kinds of workloads, e.g. "Deployment", that are listed unless other kinds are
+	// requested explicitly. All kinds except ReplicaSet are listed when it's empty.
+	DefaultKinds []string `json:"defaultKinds"`
+}
+
+func (l *List) merge(o *List) {
+	if len(o.DefaultKinds) > 0 {
+		l.DefaultKinds = o.DefaultKinds
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (l *List) IsZero() bool {
+	return l == nil || len(l.DefaultKinds) == 0
+}
+
+type Namespaces struct {
+	// AutoMapOnIntercept controls whether the namespace of an intercept or ingest is added to the mapped
+	// namespaces for the duration of the session when it isn't mapped.
+	AutoMapOnIntercept bool `json:"autoMapOnIntercept"`
+
+	// Aliases maps namespaces to the names that are displayed for them, e.g. by telepresence list.
+	Aliases map[string]string `json:"aliases"`
+}
+
+func (n *Namespaces) merge(o *Namespaces) {
+	if o.AutoMapOnIntercept {
+		n.AutoMapOnIntercept = true
+	}
+	if len(o.Aliases) > 0 {
+		n.Aliases = o.Aliases
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (n *Namespaces) IsZero() bool {
+	return n == nil || !n.AutoMapOnIntercept && len(n.Aliases) == 0
+}
+
+type Session struct {
+	// MaxLifetime is the duration after which a session is ended, regardless of its activity. Sessions
+	// are never ended because of their age when it's zero.
+	MaxLifetime time.Duration `json:"maxLifetime"`
+
+	// ExpiredCodes are the names of the gRPC status codes that, when returned by the traffic-manager's
+	// Remain, mean that the session has expired. The defaultSessionExpiredCodes are used when it's empty.
+	ExpiredCodes []string `json:"expiredCodes"`
+
+	// TransientCodes are the names of the gRPC status codes that, when returned by the traffic-manager's
+	// Remain, are considered transient and never expire the session, even when they are also listed in
+	// ExpiredCodes. The defaultSessionTransientCodes are used when it's empty.
+	TransientCodes []string `json:"transientCodes"`
+
+	// ManagerRefreshInterval is the interval between the checks of the name and version that the traffic-manager
+	// reports. They are only checked when the connection to the traffic-manager is reestablished when it's zero.
+	ManagerRefreshInterval time.Duration `json:"managerRefreshInterval"`
+
+	// StartAfter maps the name of a session service, e.g. "intercept-port-forward", to the names of the services
+	// that must be ready before it starts, e.g. "agents". All services start concurrently when it's empty.
+	StartAfter map[string][]string `json:"startAfter"`
+
+	// StartAfterTimeout is the maximum time that a service waits for the services given by StartAfter. The service
+	// starts anyway when they aren't ready by then. The defaultSessionStartAfterTimeout is used when it's zero.
+	StartAfterTimeout time.Duration `json:"startAfterTimeout"`
+}
+
+var (
+	defaultSessionExpiredCodes   = []codes.Code{codes.NotFound, codes.Unavailable} //nolint:gochecknoglobals // constant
+	defaultSessionTransientCodes = []codes.Code{codes.DeadlineExceeded}            //nolint:gochecknoglobals // constant
+)
+
+const defaultSessionStartAfterTimeout = 30 * time.Second
+
+func (s *Session) merge(o *Session) {
+	if o.MaxLifetime != 0 {
+		s.MaxLifetime = o.MaxLifetime
+	}
+	if len(o.ExpiredCodes) > 0 {
+		s.ExpiredCodes = o.ExpiredCodes
+	}
+	if len(o.TransientCodes) > 0 {
+		s.TransientCodes = o.TransientCodes
+	}
+	if o.ManagerRefreshInterval != 0 {
+		s.ManagerRefreshInterval = o.ManagerRefreshInterval
+	}
+	if len(o.StartAfter) > 0 {
+		s.StartAfter = o.StartAfter
+	}
+	if o.StartAfterTimeout != 0 {
+		s.StartAfterTimeout = o.StartAfterTimeout
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (s *Session) IsZero() bool {
+	return s == nil || s.MaxLifetime == 0 && len(s.ExpiredCodes) == 0 && len(s.TransientCodes) == 0 && s.ManagerRefreshInterval == 0 &&
+		len(s.StartAfter) == 0 && s.StartAfterTimeout == 0
+}
+
+// GetStartAfterTimeout returns the StartAfterTimeout, or the defaultSessionStartAfterTimeout when it's zero.
+func (s *Session) GetStartAfterTimeout() time.Duration {
+	if s.StartAfterTimeout > 0 {
+		return s.StartAfterTimeout
+	}
+	return defaultSessionStartAfterTimeout
+}
+
+// IsExpiredCode returns true if a Remain that fails with the given code means that the session has expired.
+func (s *Session) IsExpiredCode(code codes.Code) bool {
+	return !s.IsTransientCode(code) && slices.Contains(grpcCodes(s.ExpiredCodes, defaultSessionExpiredCodes), code)
+}
+
+// IsTransientCode returns true if a Remain that fails with the given code is considered transient.
+func (s *Session) IsTransientCode(code codes.Code) bool {
+	return slices.Contains(grpcCodes(s.TransientCodes, defaultSessionTransientCodes), code)
+}
+
+func (s *Session) UnmarshalJSONV2(in *jsontext.Decoder, opts json.Options) error {
+	// Prevent that the original object is cleared when an empty object is decoded. See LogLevels.UnmarshalJSONV2.
+	type wt Session
+	wp := (*wt)(s)
+	if err := json.UnmarshalDecode(in, &wp, opts); err != nil {
+		return err
+	}
+	for _, name := range slices.Concat(s.ExpiredCodes, s.TransientCodes) {
+		if _, ok := ParseGRPCCode(name); !ok {
+			return fmt.Errorf("invalid gRPC status code %q", name)
+		}
+	}
+	return nil
+}
+
+// ParseGRPCCode returns the gRPC status code with the given name. The name is matched case-insensitively
+// and may be given in either the Go form, e.g. "NotFound", or in the canonical form, e.g. "NOT_FOUND".
+func ParseGRPCCode(name string) (codes.Code, bool) {
+	name = strings.ReplaceAll(name, "_", "")
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), name) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+func grpcCodes(names []string, defaults []codes.Code) []codes.Code {
+	if len(names) == 0 {
+		return defaults
+	}
+	cs := make([]codes.Code, 0, len(names))
+	for _, name := range names {
+		if c, ok := ParseGRPCCode(name); ok {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+type Telemetry struct {
+	// RateLimit is the window within which repeated reports of the same kind are coalesced into one report.
+	// Reports are never coalesced when it's zero.
+	RateLimit time.Duration `json:"rateLimit"`
+}
+
+func (t *Telemetry) merge(o *Telemetry) {
+	if o.RateLimit != 0 {
+		t.RateLimit = o.RateLimit
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (t *Telemetry) IsZero() bool {
+	return t == nil || t.RateLimit == 0
 }
 
 type TelepresenceAPI struct {
@@ -744,9 +1079,16 @@ func (tm *Telemount) UnmarshalJSONV2(in *jsontext.Decoder, opts json.Options) er
 	return json.UnmarshalDecode(in, &wp, opts)
 }
 
+const (
+	defaultInterceptReconcileInterval = 30 * time.Second
+	defaultInterceptHistorySize       = 20
+)
+
 var defaultIntercept = Intercept{ //nolint:gochecknoglobals // constant
 	AppProtocolStrategy: k8sapi.Http2Probe,
 	Telemount:           defaultTelemount,
+	ReconcileInterval:   defaultInterceptReconcileInterval,
+	HistorySize:         defaultInterceptHistorySize,
 }
 
 type DockerImage struct {
@@ -762,6 +1104,50 @@ type Intercept struct {
 	DefaultPort         int                        `json:"defaultPort"`
 	UseFtp              bool                       `json:"useFtp"`
 	Telemount           Telemount                  `json:"telemount,omitzero"`
+	ReconcileInterval   time.Duration              `json:"reconcileInterval"`
+
+	// IdleTimeout is the time that an intercept can remain without traffic before it's removed. Zero means
+	// that intercepts are never removed due to inactivity. Can be overridden for each intercept.
+	IdleTimeout time.Duration `json:"idleTimeout"`
+
+	// DrainPeriod is the time that the in-flight connections of a removed intercept are allowed to drain before
+	// they're closed. Zero means that they're closed immediately. Can be overridden for each intercept.
+	DrainPeriod time.Duration `json:"drainPeriod"`
+
+	// MaxConcurrent is the maximum number of intercepts that a session can have at the same time. Zero means
+	// unlimited.
+	MaxConcurrent int `json:"maxConcurrent"`
+
+	// HistorySize is the number of ended intercepts that a session remembers. Zero disables the history.
+	HistorySize int `json:"historySize"`
+
+	// Presets are named sets of intercept options that can be referenced when an intercept is created.
+	Presets map[string]*InterceptPreset `json:"presets"`
+}
+
+// InterceptPreset is a named set of intercept options. The options of a preset are used for the options
+// that aren't given when the intercept is created.
+type InterceptPreset struct {
+	// Mechanism and MechanismArgs decide which requests to intercept, e.g. by matching headers.
+	Mechanism     string   `json:"mechanism,omitzero"`
+	MechanismArgs []string `json:"mechanismArgs,omitzero"`
+
+	// Port identifies the service or container port to intercept.
+	Port string `json:"port,omitzero"`
+
+	// TargetPort is the local port that the intercepted traffic is sent to.
+	TargetPort int `json:"targetPort,omitzero"`
+
+	// LocalPorts are ports of the intercepted pod that are made available on localhost.
+	LocalPorts []string `json:"localPorts,omitzero"`
+
+	// Env is added to the environment of the intercept, replacing the variables of the intercepted
+	// container that have the same names.
+	Env map[string]string `json:"env,omitzero"`
+
+	// MountReadOnly and LocalMountPort control how the volumes of the intercepted container are mounted.
+	MountReadOnly  bool `json:"mountReadOnly,omitzero"`
+	LocalMountPort int  `json:"localMountPort,omitzero"`
 }
 
 func (ic *Intercept) defaults() DefaultsAware {
@@ -775,7 +1161,7 @@ func (ic *Intercept) merge(o *Intercept) {
 
 // IsZero controls whether this element will be included in marshalled output.
 func (ic *Intercept) IsZero() bool {
-	return ic == nil || *ic == defaultIntercept
+	return ic == nil || isDefault(ic)
 }
 
 func (ic *Intercept) MarshalJSONV2(out *jsontext.Encoder, opts json.Options) error {
@@ -798,6 +1184,44 @@ type Cluster struct {
 	ForceSPDY               bool     `json:"forceSPDY"`
 	AgentPortForward        bool     `json:"agentPortForward"`
 
+	// AgentConfigMap is the name of the ConfigMap where the traffic-manager stores the agent configurations.
+	// An empty string means that the default name is used.
+	AgentConfigMap string `json:"agentConfigMap"`
+
+	// ManagerService is the name of the service that the traffic-manager is reached through.
+	ManagerService string `json:"managerService"`
+
+	// KeepDeletedNamespaces controls whether a mapped namespace that is deleted from the cluster remains
+	// mapped, so that it is mapped again if it is recreated, or if it is dropped from the mapped namespaces.
+	KeepDeletedNamespaces bool `json:"keepDeletedNamespaces"`
+
+	// CABundle is a path to a file containing PEM encoded certificates, or inline PEM encoded certificates,
+	// that are trusted in addition to the certificate authority of the kubeconfig when connecting to the
+	// cluster's API server.
+	CABundle string `json:"caBundle"`
+
+	// ManagerKubeconfig is a path to a kubeconfig for the cluster where the traffic-manager is installed, when that
+	// cluster differs from the one where the workloads are. An empty string means that the default kubeconfig is used
+	// when ManagerContext is set, and that the traffic-manager is in the workload cluster otherwise.
+	ManagerKubeconfig string `json:"managerKubeconfig"`
+
+	// ManagerContext is the kubeconfig context of the cluster where the traffic-manager is installed. An empty string
+	// means that the current context of the ManagerKubeconfig is used.
+	ManagerContext string `json:"managerContext"`
+
+	// RootSessionAttempts is the number of times that the root daemon is asked to connect to the session before
+	// giving up when it keeps running another session. The root daemon is disconnected between the attempts.
+	RootSessionAttempts int `json:"rootSessionAttempts"`
+
+	// RootDaemonRedact lists the kubeconfig fields, e.g. "exec", that are removed from the kubeconfig that is
+	// passed to the root daemon when ConnectFromRootDaemon is false, because the root daemon then doesn't use
+	// the kubeconfig to reach the cluster.
+	RootDaemonRedact []string `json:"rootDaemonRedact"`
+
+	// MergeStrategies maps dot separated config keys to the strategy used when the value that is reported by the
+	// cluster is merged with the local value. Only the strategies in the config reported by the cluster are used.
+	MergeStrategies map[string]MergeStrategy `json:"mergeStrategies"`
+
 	// deprecated, use Routing.VirtualSubnet
 	OldVirtualIPSubnet string `json:"virtualIPSubnet"`
 }
@@ -806,10 +1230,17 @@ type Cluster struct {
 // Hence, we don't default to "ambassador" but to empty, so that it can check that no default has been given.
 const defaultDefaultManagerNamespace = ""
 
+const defaultManagerService = "traffic-manager"
+
+const defaultRootSessionAttempts = 2
+
 var defaultCluster = Cluster{ //nolint:gochecknoglobals // constant
 	DefaultManagerNamespace: defaultDefaultManagerNamespace,
+	ManagerService:          defaultManagerService,
 	ConnectFromRootDaemon:   true,
 	AgentPortForward:        true,
+	KeepDeletedNamespaces:   true,
+	RootSessionAttempts:     defaultRootSessionAttempts,
 }
 
 func (cc *Cluster) defaults() DefaultsAware {
@@ -928,6 +1359,7 @@ func (d *DNS) Equal(o *DNS) bool {
 	return o.LocalIP == d.LocalIP &&
 		o.RemoteIP == d.RemoteIP &&
 		o.LookupTimeout == d.LookupTimeout &&
+		o.ServiceSubdomain == d.ServiceSubdomain &&
 		slices.Equal(o.IncludeSuffixes, d.IncludeSuffixes) &&
 		slices.Equal(o.ExcludeSuffixes, d.ExcludeSuffixes) &&
 		slices.Equal(o.Excludes, d.Excludes) &&
@@ -942,8 +1374,12 @@ var DefaultExcludeSuffixes = []string{ //nolint:gochecknoglobals // constant
 	".ru",
 }
 
+// DefaultServiceSubdomain is the subdomain that Kubernetes uses for services unless configured otherwise.
+const DefaultServiceSubdomain = "svc"
+
 var defaultDNS = DNS{ //nolint:gochecknoglobals // constant
-	ExcludeSuffixes: DefaultExcludeSuffixes,
+	ExcludeSuffixes:  DefaultExcludeSuffixes,
+	ServiceSubdomain: DefaultServiceSubdomain,
 }
 
 func (d *DNS) defaults() DefaultsAware {
@@ -1093,37 +1529,40 @@ type RoutingSnake struct {
 }
 
 type DNS struct {
-	Error           string        `json:"error"`
-	LocalIP         netip.Addr    `json:"localIP"`
-	RemoteIP        netip.Addr    `json:"remoteIP"`
-	IncludeSuffixes []string      `json:"includeSuffixes"`
-	ExcludeSuffixes []string      `json:"excludeSuffixes"`
-	Excludes        []string      `json:"excludes"`
-	Mappings        DNSMappings   `json:"mappings"`
-	LookupTimeout   time.Duration `json:"lookupTimeout"`
+	Error            string        `json:"error"`
+	LocalIP          netip.Addr    `json:"localIP"`
+	RemoteIP         netip.Addr    `json:"remoteIP"`
+	IncludeSuffixes  []string      `json:"includeSuffixes"`
+	ExcludeSuffixes  []string      `json:"excludeSuffixes"`
+	Excludes         []string      `json:"excludes"`
+	Mappings         DNSMappings   `json:"mappings"`
+	LookupTimeout    time.Duration `json:"lookupTimeout"`
+	ServiceSubdomain string        `json:"serviceSubdomain"`
 }
 
 // DNSSnake is the same as DNS but with snake_case json/yaml names.
 type DNSSnake struct {
-	Error           string        `json:"error"`
-	LocalIP         netip.Addr    `json:"local_ip"`
-	RemoteIP        netip.Addr    `json:"remote_ip"`
-	IncludeSuffixes []string      `json:"include_suffixes"`
-	ExcludeSuffixes []string      `json:"exclude_suffixes"`
-	Excludes        []string      `json:"excludes"`
-	Mappings        DNSMappings   `json:"mappings"`
-	LookupTimeout   time.Duration `json:"lookup_timeout"`
+	Error            string        `json:"error"`
+	LocalIP          netip.Addr    `json:"local_ip"`
+	RemoteIP         netip.Addr    `json:"remote_ip"`
+	IncludeSuffixes  []string      `json:"include_suffixes"`
+	ExcludeSuffixes  []string      `json:"exclude_suffixes"`
+	Excludes         []string      `json:"excludes"`
+	Mappings         DNSMappings   `json:"mappings"`
+	LookupTimeout    time.Duration `json:"lookup_timeout"`
+	ServiceSubdomain string        `json:"service_subdomain"`
 }
 
 func (d *DNS) ToRPC() *daemon.DNSConfig {
 	rd := daemon.DNSConfig{
-		LocalIp:         d.LocalIP.AsSlice(),
-		RemoteIp:        d.RemoteIP.AsSlice(),
-		ExcludeSuffixes: d.ExcludeSuffixes,
-		IncludeSuffixes: d.IncludeSuffixes,
-		Excludes:        d.Excludes,
-		LookupTimeout:   durationpb.New(d.LookupTimeout),
-		Error:           d.Error,
+		LocalIp:          d.LocalIP.AsSlice(),
+		RemoteIp:         d.RemoteIP.AsSlice(),
+		ExcludeSuffixes:  d.ExcludeSuffixes,
+		IncludeSuffixes:  d.IncludeSuffixes,
+		Excludes:         d.Excludes,
+		LookupTimeout:    durationpb.New(d.LookupTimeout),
+		Error:            d.Error,
+		ServiceSubdomain: d.ServiceSubdomain,
 	}
 	if len(d.Mappings) > 0 {
 		rd.Mappings = make([]*daemon.DNSMapping, len(d.Mappings))
@@ -1139,14 +1578,15 @@ func (d *DNS) ToRPC() *daemon.DNSConfig {
 
 func (d *DNS) ToSnake() *DNSSnake {
 	return &DNSSnake{
-		LocalIP:         d.LocalIP,
-		RemoteIP:        d.RemoteIP,
-		ExcludeSuffixes: d.ExcludeSuffixes,
-		IncludeSuffixes: d.IncludeSuffixes,
-		Excludes:        d.Excludes,
-		Mappings:        d.Mappings,
-		LookupTimeout:   d.LookupTimeout,
-		Error:           d.Error,
+		LocalIP:          d.LocalIP,
+		RemoteIP:         d.RemoteIP,
+		ExcludeSuffixes:  d.ExcludeSuffixes,
+		IncludeSuffixes:  d.IncludeSuffixes,
+		Excludes:         d.Excludes,
+		Mappings:         d.Mappings,
+		LookupTimeout:    d.LookupTimeout,
+		Error:            d.Error,
+		ServiceSubdomain: d.ServiceSubdomain,
 	}
 }
 
@@ -1166,11 +1606,12 @@ func MappingsFromRPC(mappings []*daemon.DNSMapping) DNSMappings {
 
 func DNSFromRPC(s *daemon.DNSConfig) *DNS {
 	c := DNS{
-		ExcludeSuffixes: s.ExcludeSuffixes,
-		IncludeSuffixes: s.IncludeSuffixes,
-		Excludes:        s.Excludes,
-		Mappings:        MappingsFromRPC(s.Mappings),
-		Error:           s.Error,
+		ExcludeSuffixes:  s.ExcludeSuffixes,
+		IncludeSuffixes:  s.IncludeSuffixes,
+		Excludes:         s.Excludes,
+		Mappings:         MappingsFromRPC(s.Mappings),
+		Error:            s.Error,
+		ServiceSubdomain: s.ServiceSubdomain,
 	}
 	if ip, ok := netip.AddrFromSlice(s.LocalIp); ok {
 		c.LocalIP = ip
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// MergeStrategy controls how the value of a config key that is reported by the cluster is merged with the
+// value of the local config.
+type MergeStrategy string
+
+const (
+	// MergeOverride lets the local value override the cluster value. This is the default strategy.
+	MergeOverride MergeStrategy = "override"
+
+	// MergeUnion merges the local list with the cluster list, so that the result contains the elements of both.
+	MergeUnion MergeStrategy = "union"
+
+	// MergeClusterWins lets the cluster value take priority over the local value.
+	MergeClusterWins MergeStrategy = "clusterWins"
+)
+
+// defaultMergeStrategies are the strategies of keys that aren't merged using MergeOverride, unless the cluster
+// declares otherwise.
+var defaultMergeStrategies = map[string]MergeStrategy{ //nolint:gochecknoglobals // constant
+	"routing.neverProxySubnets": MergeUnion,
+}
+
+func (ms *MergeStrategy) UnmarshalText(data []byte) error {
+	switch s := MergeStrategy(data); s {
+	case MergeOverride, MergeUnion, MergeClusterWins:
+		*ms = s
+		return nil
+	default:
+		return fmt.Errorf("invalid merge strategy %q, must be one of %q, %q, or %q", s, MergeOverride, MergeUnion, MergeClusterWins)
+	}
+}
+
+// localOnlyKeys are the keys that refer to files, credentials, or clusters of the local machine. Their values
+// are never taken from the config reported by the cluster, and merge strategies declared for them, or for a key
+// that contains them, are ignored.
+var localOnlyKeys = []string{ //nolint:gochecknoglobals // constant
+	"audit.file",
+	"cluster.caBundle",
+	"cluster.managerContext",
+	"cluster.managerKubeconfig",
+}
+
+// isLocalOnly returns true if the given dot separated key is, contains, or is contained in, a local-only key.
+func isLocalOnly(key string) bool {
+	return slices.ContainsFunc(localOnlyKeys, func(lk string) bool {
+		return key == lk || strings.HasPrefix(lk, key+".") || strings.HasPrefix(key, lk+".")
+	})
+}
+
+// MergeClusterConfig merges the config that is reported by the cluster with the local config. The local values
+// take priority, except for the keys that have another MergeStrategy. Those strategies are declared by the
+// cluster config using cluster.mergeStrategies, which maps dot separated keys, such as "timeouts.helm",
+// to a strategy. The strategies declared in the local config are ignored, because they would allow the local
+// config to override a cluster policy. The values of the local-only keys, such as cluster.caBundle, are always
+// those of the local config.
+func MergeClusterConfig(clusterCfg, localCfg Config) (Config, error) {
+	cm, err := configAsMap(clusterCfg)
+	if err != nil {
+		return nil, err
+	}
+	stripped := false
+	for _, key := range localOnlyKeys {
+		path := strings.Split(key, ".")
+		if _, ok := getPath(cm, path); ok {
+			deletePath(cm, path)
+			stripped = true
+		}
+	}
+	strategies := maps.Clone(defaultMergeStrategies)
+	maps.Copy(strategies, clusterCfg.Cluster().MergeStrategies)
+	if stripped {
+		data, err := json.Marshal(cm)
+		if err != nil {
+			return nil, err
+		}
+		if clusterCfg, err = UnmarshalJSONConfig(data, false); err != nil {
+			return nil, err
+		}
+	}
+	cfg := clusterCfg.Merge(localCfg)
+	lm, err := configAsMap(localCfg)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := configAsMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, key := range slices.Sorted(maps.Keys(strategies)) {
+		if isLocalOnly(key) {
+			continue
+		}
+		path := strings.Split(key, ".")
+		cv, inCluster := getPath(cm, path)
+		switch strategies[key] {
+		case MergeClusterWins:
+			mv, inMerged := getPath(mm, path)
+			if inCluster == inMerged && reflect.DeepEqual(cv, mv) {
+				continue
+			}
+			if inCluster {
+				setPath(mm, path, cv)
+			} else {
+				// The cluster uses the default value.
+				deletePath(mm, path)
+			}
+		case MergeUnion:
+			lv, inLocal := getPath(lm, path)
+			if !(inCluster && inLocal) {
+				// Nothing to merge. The merged value is the one that is present, if any.
+				continue
+			}
+			cl, ok := cv.([]any)
+			if !ok {
+				return nil, fmt.Errorf("unable to merge %s using strategy %q: the value is not a list", key, MergeUnion)
+			}
+			ll, ok := lv.([]any)
+			if !ok {
+				return nil, fmt.Errorf("unable to merge %s using strategy %q: the value is not a list", key, MergeUnion)
+			}
+			union := slices.Clone(cl)
+			for _, e := range ll {
+				if !slices.ContainsFunc(union, func(u any) bool { return reflect.DeepEqual(u, e) }) {
+					union = append(union, e)
+				}
+			}
+			setPath(mm, path, union)
+		default:
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		return cfg, nil
+	}
+	data, err := json.Marshal(mm)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalJSONConfig(data, false)
+}
+
+// configAsMap returns the generic JSON representation of the given config. Keys that have default values
+// are absent.
+func configAsMap(cfg Config) (map[string]any, error) {
+	data, err := MarshalJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func getPath(m map[string]any, path []string) (any, bool) {
+	for i, k := range path {
+		v, ok := m[k]
+		if !ok || i == len(path)-1 {
+			return v, ok
+		}
+		if m, ok = v.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func setPath(m map[string]any, path []string, v any) {
+	last := len(path) - 1
+	for _, k := range path[:last] {
+		sm, ok := m[k].(map[string]any)
+		if !ok {
+			sm = make(map[string]any)
+			m[k] = sm
+		}
+		m = sm
+	}
+	m[path[last]] = v
+}
+
+func deletePath(m map[string]any, path []string) {
+	last := len(path) - 1
+	for _, k := range path[:last] {
+		var ok bool
+		if m, ok = m[k].(map[string]any); !ok {
+			return
+		}
+	}
+	delete(m, path[last])
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseTestConfig(t *testing.T, data string) Config {
+	t.Helper()
+	cfg, err := ParseConfigYAML(context.Background(), "config.yml", []byte(data))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestMergeClusterConfig(t *testing.T) {
+	local := parseTestConfig(t, `
+timeouts:
+  helm: 3m
+  intercept: 20s
+cluster:
+  mergeStrategies:
+    timeouts.intercept: override
+routing:
+  neverProxySubnets: [10.0.0.0/16, 10.2.0.0/16]
+  alsoProxySubnets: [10.8.0.0/16]
+  allowConflictingSubnets: [10.9.0.0/16]
+`)
+
+	t.Run("override", func(t *testing.T) {
+		cluster := parseTestConfig(t, `
+timeouts:
+  helm: 5m
+routing:
+  alsoProxySubnets: [10.1.0.0/16]
+cluster:
+  mergeStrategies:
+    routing.neverProxySubnets: override
+`)
+		cfg, err := MergeClusterConfig(cluster, local)
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Minute, cfg.Timeouts().PrivateHelm)
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.8.0.0/16")}, cfg.Routing().AlsoProxy)
+		assert.Equal(t, local.Routing().NeverProxy, cfg.Routing().NeverProxy)
+	})
+
+	t.Run("union", func(t *testing.T) {
+		// The neverProxySubnets use the union strategy unless declared otherwise.
+		cluster := parseTestConfig(t, `
+routing:
+  neverProxySubnets: [10.1.0.0/16, 10.2.0.0/16]
+  alsoProxySubnets: [10.1.0.0/16]
+cluster:
+  mergeStrategies:
+    routing.alsoProxySubnets: union
+`)
+		cfg, err := MergeClusterConfig(cluster, local)
+		require.NoError(t, err)
+		assert.Equal(t, []netip.Prefix{
+			netip.MustParsePrefix("10.1.0.0/16"),
+			netip.MustParsePrefix("10.2.0.0/16"),
+			netip.MustParsePrefix("10.0.0.0/16"),
+		}, cfg.Routing().NeverProxy)
+		assert.Equal(t, []netip.Prefix{
+			netip.MustParsePrefix("10.1.0.0/16"),
+			netip.MustParsePrefix("10.8.0.0/16"),
+		}, cfg.Routing().AlsoProxy)
+		assert.Equal(t, 3*time.Minute, cfg.Timeouts().PrivateHelm)
+
+		cluster = parseTestConfig(t, `
+timeouts:
+  helm: 5m
+cluster:
+  mergeStrategies:
+    timeouts.helm: union
+`)
+		_, err = MergeClusterConfig(cluster, local)
+		assert.Error(t, err)
+	})
+
+	t.Run("clusterWins", func(t *testing.T) {
+		// The allowConflictingSubnets of the cluster is the default, and wins over the local value.
+		cluster := parseTestConfig(t, `
+timeouts:
+  helm: 5m
+routing:
+  neverProxySubnets: [10.1.0.0/16]
+cluster:
+  mergeStrategies:
+    timeouts.helm: clusterWins
+    routing.neverProxySubnets: clusterWins
+    routing.allowConflictingSubnets: clusterWins
+`)
+		cfg, err := MergeClusterConfig(cluster, local)
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Minute, cfg.Timeouts().PrivateHelm)
+		assert.Equal(t, 20*time.Second, cfg.Timeouts().PrivateIntercept)
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}, cfg.Routing().NeverProxy)
+		assert.Empty(t, cfg.Routing().AllowConflicting)
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.8.0.0/16")}, cfg.Routing().AlsoProxy)
+	})
+
+	t.Run("local strategies are ignored", func(t *testing.T) {
+		cluster := parseTestConfig(t, `
+timeouts:
+  intercept: 40s
+cluster:
+  mergeStrategies:
+    timeouts.intercept: clusterWins
+`)
+		cfg, err := MergeClusterConfig(cluster, local)
+		require.NoError(t, err)
+		assert.Equal(t, 40*time.Second, cfg.Timeouts().PrivateIntercept)
+	})
+
+	t.Run("local-only keys", func(t *testing.T) {
+		cluster := parseTestConfig(t, `
+timeouts:
+  helm: 5m
+audit:
+  file: /tmp/cluster-audit.log
+cluster:
+  caBundle: /tmp/cluster-ca.pem
+  managerKubeconfig: /tmp/cluster-kubeconfig
+  mergeStrategies:
+    audit: clusterWins
+    cluster.caBundle: clusterWins
+    timeouts.helm: clusterWins
+`)
+		localCA := parseTestConfig(t, `
+cluster:
+  caBundle: /tmp/local-ca.pem
+`)
+		cfg, err := MergeClusterConfig(cluster, localCA)
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp/local-ca.pem", cfg.Cluster().CABundle)
+		assert.Empty(t, cfg.Cluster().ManagerKubeconfig)
+		assert.Empty(t, cfg.Audit().File)
+		assert.Equal(t, 5*time.Minute, cfg.Timeouts().PrivateHelm)
+	})
+}
+
+func TestMergeStrategy_UnmarshalText(t *testing.T) {
+	_, err := ParseConfigYAML(context.Background(), "config.yml", []byte(`
+cluster:
+  mergeStrategies:
+    timeouts.intercept: localWins
+`))
+	assert.ErrorContains(t, err, "invalid merge strategy")
+}
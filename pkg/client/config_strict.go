@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+	"gopkg.in/yaml.v3"
+)
+
+type strictConfigKey struct{}
+
+// WithStrictConfig returns a context that makes ParseConfigYAML reject unknown keys when strict is true, and
+// ignore them when it's false. It takes precedence over the TELEPRESENCE_STRICT_CONFIG environment variable.
+func WithStrictConfig(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictConfigKey{}, strict)
+}
+
+// IsStrictConfig returns true if ParseConfigYAML should reject unknown keys.
+func IsStrictConfig(ctx context.Context) bool {
+	if strict, ok := ctx.Value(strictConfigKey{}).(bool); ok {
+		return strict
+	}
+	if env := GetEnv(ctx); env != nil {
+		return env.StrictConfig
+	}
+	return false
+}
+
+var unknownMemberRx = regexp.MustCompile(`unknown object member name "([^"]*)"`) //nolint:gochecknoglobals // constant
+
+// unknownKeyError returns an error that reports the key that the given error rejected, the path of that key
+// in the configuration, and the line of the given YAML where the key is found.
+func unknownKeyError(path string, yml []byte, err *json.SemanticError) error {
+	var key string
+	if m := unknownMemberRx.FindStringSubmatch(err.Error()); m != nil {
+		key = m[1]
+	}
+
+	// Convert the JSON pointer to the dot separated form used in the documentation.
+	var segments []string
+	for _, s := range strings.Split(string(err.JSONPointer), "/") {
+		if s != "" {
+			segments = append(segments, strings.NewReplacer("~1", "/", "~0", "~").Replace(s))
+		}
+	}
+	if key != "" && (len(segments) == 0 || segments[len(segments)-1] != key) {
+		segments = append(segments, key)
+	}
+	if key == "" && len(segments) > 0 {
+		key = segments[len(segments)-1]
+	}
+
+	msg := fmt.Sprintf("%s: unknown key %q", path, key)
+	if len(segments) > 1 {
+		msg += " at " + strings.Join(segments, ".")
+	}
+	if line := keyLine(yml, segments); line > 0 {
+		msg += fmt.Sprintf(" (line %d)", line)
+	}
+	return errors.New(msg)
+}
+
+// keyLine returns the number of the line of the given YAML that declares the key at the given path, or zero if
+// the path isn't found. A path segment that selects an element of a sequence is the index of that element.
+func keyLine(yml []byte, path []string) int {
+	if len(path) == 0 {
+		return 0
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(yml, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+	n := doc.Content[0]
+	line := 0
+	for _, seg := range path {
+		for n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		switch n.Kind {
+		case yaml.MappingNode:
+			var value *yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if k := n.Content[i]; k.Value == seg {
+					line = k.Line
+					value = n.Content[i+1]
+					break
+				}
+			}
+			if value == nil {
+				return 0
+			}
+			n = value
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return 0
+			}
+			n = n.Content[i]
+			line = n.Line
+		default:
+			return 0
+		}
+	}
+	return line
+}
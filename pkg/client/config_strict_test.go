@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+func TestParseConfigYAML_strict(t *testing.T) {
+	ctx := WithStrictConfig(dlog.NewTestContext(t, false), true)
+
+	// Unknown keys are rejected, and the error reports the key and where it is.
+	_, err := ParseConfigYAML(ctx, "config.yml", []byte(`---
+logLevels:
+  userDaemon: debug
+intercept:
+  defaultPrt: 8080
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `config.yml: unknown key "defaultPrt"`)
+	assert.Contains(t, err.Error(), "at intercept.defaultPrt")
+	assert.Contains(t, err.Error(), "(line 5)")
+
+	_, err = ParseConfigYAML(ctx, "config.yml", []byte(`---
+timeout:
+  intercept: 30s
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `config.yml: unknown key "timeout" (line 2)`)
+
+	// The line is the one of the key at the reported path, also when the key name is used elsewhere first.
+	_, err = ParseConfigYAML(ctx, "config.yml", []byte(`---
+logLevels:
+  subsystems:
+    agents: debug
+intercept:
+  agents: 3
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at intercept.agents")
+	assert.Contains(t, err.Error(), "(line 6)")
+
+	// Valid configurations are accepted.
+	cfg, err := ParseConfigYAML(ctx, "config.yml", []byte(`---
+logLevels:
+  userDaemon: debug
+intercept:
+  defaultPort: 8080
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Intercept().DefaultPort)
+}
+
+func TestParseConfigYAML_notStrict(t *testing.T) {
+	// Unknown keys are ignored by default.
+	cfg, err := ParseConfigYAML(dlog.NewTestContext(t, false), "config.yml", []byte(`---
+intercept:
+  defaultPrt: 8080
+  useFtp: true
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.Intercept().UseFtp)
+	assert.Zero(t, cfg.Intercept().DefaultPort)
+}
+
+func TestIsStrictConfig(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, IsStrictConfig(ctx))
+
+	ctx = WithEnv(ctx, &Env{StrictConfig: true})
+	assert.True(t, IsStrictConfig(ctx))
+
+	// The context setting takes precedence over the environment.
+	assert.False(t, IsStrictConfig(WithStrictConfig(ctx, false)))
+}
+
+func Test_keyLine(t *testing.T) {
+	yml := []byte(`---
+c: 0
+a:
+  - "b": 1
+    c: 2
+d:
+  c: 3
+`)
+	assert.Equal(t, 3, keyLine(yml, []string{"a"}))
+	assert.Equal(t, 4, keyLine(yml, []string{"a", "0", "b"}))
+	assert.Equal(t, 5, keyLine(yml, []string{"a", "0", "c"}))
+	assert.Equal(t, 7, keyLine(yml, []string{"d", "c"}))
+	assert.Equal(t, 2, keyLine(yml, []string{"c"}))
+	assert.Zero(t, keyLine(yml, []string{"d", "b"}))
+	assert.Zero(t, keyLine(yml, []string{"a", "1", "b"}))
+	assert.Zero(t, keyLine(yml, nil))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/datawire/dlib/dlog"
@@ -104,6 +105,13 @@ func Test_ConfigMarshalYAML(t *testing.T) {
 	cfg.Timeouts().PrivateTrafficManagerAPI = defaultTimeoutsTrafficManagerAPI + 20*time.Second
 	cfg.LogLevels().UserDaemon = logrus.TraceLevel
 	cfg.Grpc().MaxReceiveSizeV, _ = resource.ParseQuantity("20Mi")
+	cfg.Grpc().Compression = "gzip"
+	cfg.Namespaces().Aliases = map[string]string{"prod-team-xyz-1234": "prod"}
+	cfg.List().DefaultKinds = []string{"Deployment", "ReplicaSet"}
+	cfg.Session().MaxLifetime = 8 * time.Hour
+	cfg.Session().TransientCodes = []string{"DeadlineExceeded", "Internal"}
+	cfg.Session().ManagerRefreshInterval = time.Minute
+	cfg.Telemetry().RateLimit = 30 * time.Second
 	cfg.TelepresenceAPI().Port = 4567
 	cfg.Intercept().AppProtocolStrategy = k8sapi.PortName
 	cfg.Intercept().DefaultPort = 9080
@@ -180,3 +188,94 @@ logLevels:
 	require.NoError(t, err)
 	require.Equal(t, cfg.LogLevels().UserDaemon, logrus.DebugLevel)
 }
+
+func Test_ConfigUnmarshalSubsystemLogLevels(t *testing.T) {
+	config := []byte(`---
+logLevels:
+  userDaemon: info
+  subsystems:
+    workload: debug
+    dns: warning
+`)
+	cfg, err := ParseConfigYAML(context.Background(), "config.yml", config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]logrus.Level{"workload": logrus.DebugLevel, "dns": logrus.WarnLevel}, cfg.LogLevels().Subsystems)
+
+	cfgBytes, err := cfg.MarshalYAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(cfgBytes), "workload: debug")
+}
+
+func Test_ConfigUnmarshalInterceptPresets(t *testing.T) {
+	config := []byte(`---
+intercept:
+  presets:
+    orders:
+      port: http
+      targetPort: 8081
+      mechanismArgs: [--http-header=x-user=me]
+      env:
+        LOG_LEVEL: debug
+      mountReadOnly: true
+`)
+	cfg, err := ParseConfigYAML(context.Background(), "config.yml", config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*InterceptPreset{"orders": {
+		Port:          "http",
+		TargetPort:    8081,
+		MechanismArgs: []string{"--http-header=x-user=me"},
+		Env:           map[string]string{"LOG_LEVEL": "debug"},
+		MountReadOnly: true,
+	}}, cfg.Intercept().Presets)
+	assert.False(t, cfg.Intercept().IsZero())
+
+	cfgBytes, err := cfg.MarshalYAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(cfgBytes), "targetPort: 8081")
+	assert.NotContains(t, string(cfgBytes), "localMountPort")
+}
+
+func Test_ConfigUnmarshalSessionCodes(t *testing.T) {
+	cfg, err := ParseConfigYAML(context.Background(), "config.yml", []byte(`---
+session:
+  expiredCodes: [NOT_FOUND]
+  transientCodes: [unavailable, Internal]
+`))
+	require.NoError(t, err)
+	ss := cfg.Session()
+	assert.True(t, ss.IsExpiredCode(codes.NotFound))
+	assert.False(t, ss.IsExpiredCode(codes.Unavailable))
+	assert.True(t, ss.IsTransientCode(codes.Unavailable))
+	assert.True(t, ss.IsTransientCode(codes.Internal))
+	assert.False(t, ss.IsTransientCode(codes.DeadlineExceeded))
+
+	// The defaults are used when no codes are configured.
+	ss = GetDefaultConfig().Session()
+	assert.True(t, ss.IsExpiredCode(codes.NotFound))
+	assert.True(t, ss.IsExpiredCode(codes.Unavailable))
+	assert.True(t, ss.IsTransientCode(codes.DeadlineExceeded))
+	assert.False(t, ss.IsExpiredCode(codes.Internal))
+	assert.False(t, ss.IsTransientCode(codes.Internal))
+
+	_, err = ParseConfigYAML(context.Background(), "config.yml", []byte(`---
+session:
+  expiredCodes: [Gone]
+`))
+	assert.ErrorContains(t, err, `invalid gRPC status code "Gone"`)
+}
+
+func Test_DNSRPCRoundTrip(t *testing.T) {
+	config := []byte(`---
+dns:
+  serviceSubdomain: services
+  lookupTimeout: 8s
+`)
+	cfg, err := ParseConfigYAML(context.Background(), "config.yml", config)
+	require.NoError(t, err)
+	dns := cfg.DNS()
+	require.Equal(t, "services", dns.ServiceSubdomain)
+
+	rt := DNSFromRPC(dns.ToRPC())
+	assert.Equal(t, "services", rt.ServiceSubdomain)
+	assert.True(t, dns.Equal(rt))
+}
@@ -9,7 +9,8 @@ import (
 
 // ReloadDaemonLogLevel calls SetLevel with the log level defined
 // for the rootDaemon or userDaemon
-// depending on the root flag. Assumes that the config has already been reloaded.
+// depending on the root flag, and SetSubsystemLevels with the levels defined
+// for the subsystems. Assumes that the config has already been reloaded.
 func ReloadDaemonLogLevel(c context.Context, root bool) error {
 	newCfg := GetConfig(c)
 	var level string
@@ -19,6 +20,7 @@ func ReloadDaemonLogLevel(c context.Context, root bool) error {
 		level = newCfg.LogLevels().UserDaemon.String()
 	}
 	log.SetLevel(c, level)
+	log.SetSubsystemLevels(c, newCfg.LogLevels().Subsystems)
 	dlog.Info(c, "Configuration reloaded")
 	return nil
 }
@@ -24,6 +24,9 @@ type Env struct {
 	// The address that the user daemon is listening to (unless it is started by the client and uses a named pipe or unix socket).
 	UserDaemonAddress string `env:"TELEPRESENCE_USER_DAEMON_ADDRESS, parser=possibly-empty-string,default="`
 	ScoutDisable      bool   `env:"SCOUT_DISABLE, parser=strconv.ParseBool, default=0"`
+
+	// StrictConfig makes the parsing of the client configuration reject unknown keys instead of ignoring them.
+	StrictConfig bool `env:"TELEPRESENCE_STRICT_CONFIG, parser=strconv.ParseBool, default=0"`
 }
 
 type envKey struct{}
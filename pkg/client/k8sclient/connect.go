@@ -9,6 +9,8 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // register the gzip compressor
 	empty "google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/datawire/dlib/dlog"
@@ -16,11 +18,16 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/portforward"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 )
 
 func ConnectToManager(ctx context.Context, namespace string) (*grpc.ClientConn, manager.ManagerClient, *manager.VersionInfo2, error) {
-	grpcAddr := net.JoinHostPort("svc/traffic-manager."+namespace, "api")
-	conn, err := dialClusterGRPC(ctx, grpcAddr)
+	grpcAddr := net.JoinHostPort("svc/"+client.GetConfig(ctx).Cluster().ManagerService+"."+namespace, "api")
+	opts, err := managerDialOptions(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	conn, err := dialClusterGRPC(ctx, grpcAddr, opts...)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -56,10 +63,25 @@ func ConnectToAgent(
 	return conn, mClient, vi, err
 }
 
-func dialClusterGRPC(ctx context.Context, address string) (*grpc.ClientConn, error) {
-	return grpc.NewClient(portforward.K8sPFScheme+":///"+address, grpc.WithContextDialer(portforward.Dialer(ctx)),
+// managerDialOptions returns the options to use when dialing the traffic-manager, in addition to those
+// used for all connections to the cluster.
+func managerDialOptions(ctx context.Context) ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	if c := client.GetConfig(ctx).Grpc().Compression; c != "" {
+		if encoding.GetCompressor(c) == nil {
+			return nil, errcat.Config.Newf("grpc.compression %q is not a supported compressor", c)
+		}
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(c)))
+	}
+	return opts, nil
+}
+
+func dialClusterGRPC(ctx context.Context, address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.NewClient(portforward.K8sPFScheme+":///"+address, append([]grpc.DialOption{
+		grpc.WithContextDialer(portforward.Dialer(ctx)),
 		grpc.WithResolvers(portforward.NewResolver(ctx)),
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)...)
 }
 
 func getVersion(ctx context.Context, gc versionAPI) (*manager.VersionInfo2, error) {
@@ -0,0 +1,129 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+func Test_managerDialOptions(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	cfg := client.GetDefaultConfig()
+	ctx = client.WithConfig(ctx, cfg)
+
+	opts, err := managerDialOptions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, opts)
+
+	cfg.Grpc().Compression = "gzip"
+	opts, err = managerDialOptions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, opts, 1)
+
+	cfg.Grpc().Compression = "lzma"
+	_, err = managerDialOptions(ctx)
+	require.Error(t, err)
+	assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+}
+
+// snapshotServer sends a snapshot of the given workloads to each client that watches them.
+type snapshotServer struct {
+	manager.UnimplementedManagerServer
+	delta *manager.WorkloadEventsDelta
+}
+
+func (s *snapshotServer) WatchWorkloads(_ *manager.WorkloadEventsRequest, stream grpc.ServerStreamingServer[manager.WorkloadEventsDelta]) error {
+	return stream.Send(s.delta)
+}
+
+// countingConn counts the bytes that are read from a connection.
+type countingConn struct {
+	net.Conn
+	read *atomic.Int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+// BenchmarkWatchWorkloadsSnapshot measures the transfer of the initial workload snapshot of a namespace with
+// many workloads, with and without compression. The bytes that the client reads are reported as wire-B/op.
+func BenchmarkWatchWorkloadsSnapshot(b *testing.B) {
+	const workloadCount = 5000
+	delta := &manager.WorkloadEventsDelta{Events: make([]*manager.WorkloadEvent, workloadCount)}
+	for i := range workloadCount {
+		delta.Events[i] = &manager.WorkloadEvent{
+			Type: manager.WorkloadEvent_ADDED_UNSPECIFIED,
+			Workload: &manager.WorkloadInfo{
+				Kind:       manager.WorkloadInfo_DEPLOYMENT,
+				Name:       fmt.Sprintf("echo-server-%d", i),
+				Namespace:  "large-namespace",
+				Uid:        fmt.Sprintf("9c5e7a1b-3f2d-4e8a-b6c0-%012d", i),
+				AgentState: manager.WorkloadInfo_NO_AGENT_UNSPECIFIED,
+				State:      manager.WorkloadInfo_AVAILABLE,
+			},
+		}
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	manager.RegisterManagerServer(srv, &snapshotServer{delta: delta})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	for _, compression := range []string{"", "gzip"} {
+		name := compression
+		if name == "" {
+			name = "none"
+		}
+		b.Run(name, func(b *testing.B) {
+			ctx := dlog.NewTestContext(b, false)
+			cfg := client.GetDefaultConfig()
+			cfg.Grpc().Compression = compression
+			ctx = client.WithConfig(ctx, cfg)
+			opts, err := managerDialOptions(ctx)
+			require.NoError(b, err)
+
+			var read atomic.Int64
+			conn, err := grpc.NewClient("passthrough:///bufnet", append(opts,
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					c, err := lis.DialContext(ctx)
+					if err != nil {
+						return nil, err
+					}
+					return &countingConn{Conn: c, read: &read}, nil
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(64*1024*1024)))...)
+			require.NoError(b, err)
+			defer conn.Close()
+			mc := manager.NewManagerClient(conn)
+
+			b.ResetTimer()
+			read.Store(0)
+			for range b.N {
+				stream, err := mc.WatchWorkloads(ctx, &manager.WorkloadEventsRequest{Namespace: "large-namespace"})
+				require.NoError(b, err)
+				d, err := stream.Recv()
+				require.NoError(b, err)
+				require.Len(b, d.Events, workloadCount)
+			}
+			b.ReportMetric(float64(read.Load())/float64(b.N), "wire-B/op")
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package k8sclient
+
+import (
+	"context"
+	"strings"
+
+	auth "k8s.io/api/authorization/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+type permission struct {
+	auth.ResourceAttributes
+	optional bool
+}
+
+// sessionPermissions returns the permissions that a session uses. The workload permissions are needed in each of
+// the given namespaces, or cluster-wide when no namespaces are given. The traffic-manager permissions, which include
+// the permission to get the given managerService, are omitted when the managerNamespace is empty.
+func sessionPermissions(managerService, managerNamespace string, namespaces []string) []permission {
+	var ps []permission
+	add := func(optional bool, ra auth.ResourceAttributes) {
+		ps = append(ps, permission{ResourceAttributes: ra, optional: optional})
+	}
+	verbs := []string{"get", "list", "watch"}
+	if managerNamespace != "" {
+		add(false, auth.ResourceAttributes{Namespace: managerNamespace, Verb: "get", Resource: "services", Name: managerService})
+		for _, v := range verbs {
+			add(false, auth.ResourceAttributes{Namespace: managerNamespace, Verb: v, Resource: "pods"})
+		}
+		add(false, auth.ResourceAttributes{Namespace: managerNamespace, Verb: "create", Resource: "pods", Subresource: "portforward"})
+	}
+	if len(namespaces) == 0 {
+		// Without permission to watch namespaces, a session can only use the namespace of the kubeconfig.
+		add(true, auth.ResourceAttributes{Verb: "list", Resource: "namespaces"})
+		add(true, auth.ResourceAttributes{Verb: "watch", Resource: "namespaces"})
+		namespaces = []string{""}
+	}
+	for _, ns := range namespaces {
+		for _, v := range verbs {
+			add(false, auth.ResourceAttributes{Namespace: ns, Verb: v, Resource: "services"})
+			for _, r := range []string{"deployments", "replicasets", "statefulsets"} {
+				add(false, auth.ResourceAttributes{Namespace: ns, Verb: v, Group: "apps", Resource: r})
+			}
+		}
+		// Used when uninstalling traffic-agents.
+		add(true, auth.ResourceAttributes{Namespace: ns, Verb: "get", Resource: "configmaps"})
+		add(true, auth.ResourceAttributes{Namespace: ns, Verb: "update", Resource: "configmaps"})
+		// Used when gathering logs.
+		add(true, auth.ResourceAttributes{Namespace: ns, Verb: "get", Resource: "pods", Subresource: "log"})
+	}
+	return ps
+}
+
+// CheckPermissions issues a SelfSubjectAccessReview for each permission that a session uses in the given namespaces,
+// or cluster-wide when no namespaces are given, and returns a report of those that aren't allowed. The permissions
+// needed to connect to the traffic-manager service, named by the cluster.managerService setting, are checked unless
+// the managerNamespace is empty. A permission that can't
+// be reviewed is reported as missing, with the error as the reason. An error is returned only when the context ends
+// before all permissions are checked.
+func CheckPermissions(ctx context.Context, managerNamespace string, namespaces []string) (*connector.PermissionsReport, error) {
+	authHandler := k8sapi.GetK8sInterface(ctx).AuthorizationV1().SelfSubjectAccessReviews()
+	report := &connector.PermissionsReport{}
+	for _, p := range sessionPermissions(client.GetConfig(ctx).Cluster().ManagerService, managerNamespace, namespaces) {
+		ra := p.ResourceAttributes
+		review := auth.SelfSubjectAccessReview{Spec: auth.SelfSubjectAccessReviewSpec{ResourceAttributes: &ra}}
+		ar, err := authHandler.Create(ctx, &review, meta.CreateOptions{})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var reason string
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case ar.Status.Allowed:
+			continue
+		default:
+			reason = ar.Status.Reason
+		}
+		report.Missing = append(report.Missing, &connector.MissingPermission{
+			Namespace:   ra.Namespace,
+			Verb:        ra.Verb,
+			Group:       ra.Group,
+			Resource:    ra.Resource,
+			Subresource: ra.Subresource,
+			Name:        ra.Name,
+			Reason:      reason,
+			Optional:    p.optional,
+		})
+	}
+	return report, nil
+}
+
+// MissingPermissionsError returns an error that lists the missing permissions of the given report that aren't
+// optional, or nil when no such permission is missing.
+func MissingPermissionsError(report *connector.PermissionsReport) error {
+	var sb strings.Builder
+	for _, mp := range report.Missing {
+		if mp.Optional {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("missing RBAC permissions:")
+		}
+		sb.WriteString("\n  can-i ")
+		sb.WriteString(mp.Verb)
+		sb.WriteByte(' ')
+		sb.WriteString(mp.Resource)
+		if mp.Subresource != "" {
+			sb.WriteByte('/')
+			sb.WriteString(mp.Subresource)
+		}
+		if mp.Group != "" {
+			sb.WriteByte('.')
+			sb.WriteString(mp.Group)
+		}
+		if mp.Name != "" {
+			sb.WriteByte(' ')
+			sb.WriteString(mp.Name)
+		}
+		if mp.Namespace != "" {
+			sb.WriteString(" in namespace ")
+			sb.WriteString(mp.Namespace)
+		}
+		if mp.Reason != "" {
+			sb.WriteString(": ")
+			sb.WriteString(mp.Reason)
+		}
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	return errcat.User.New(sb.String())
+}
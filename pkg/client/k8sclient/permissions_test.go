@@ -0,0 +1,100 @@
+package k8sclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// restrictedClientset returns a fake clientset that allows everything except what the given deny function denies.
+func restrictedClientset(deny func(*auth.ResourceAttributes) bool) *fake.Clientset {
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*auth.SelfSubjectAccessReview)
+		ra := review.Spec.ResourceAttributes
+		if ra.Resource == "configmaps" {
+			return true, nil, errors.New("connection refused")
+		}
+		review.Status.Allowed = !deny(ra)
+		if !review.Status.Allowed {
+			review.Status.Reason = "RBAC: access denied"
+		}
+		return true, review, nil
+	})
+	return cs
+}
+
+func TestCheckPermissions(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	cs := restrictedClientset(func(ra *auth.ResourceAttributes) bool {
+		return ra.Subresource == "portforward" || (ra.Resource == "statefulsets" && ra.Verb == "watch")
+	})
+	ctx = k8sapi.WithK8sInterface(ctx, cs)
+
+	report, err := CheckPermissions(ctx, "ambassador", []string{"dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []*connector.MissingPermission{
+		{Namespace: "ambassador", Verb: "create", Resource: "pods", Subresource: "portforward", Reason: "RBAC: access denied"},
+		{Namespace: "dev", Verb: "watch", Group: "apps", Resource: "statefulsets", Reason: "RBAC: access denied"},
+		{Namespace: "dev", Verb: "get", Resource: "configmaps", Reason: "connection refused", Optional: true},
+		{Namespace: "dev", Verb: "update", Resource: "configmaps", Reason: "connection refused", Optional: true},
+	}, report.Missing)
+
+	// Optional permissions are not included in the error.
+	err = MissingPermissionsError(report)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Equal(t, `missing RBAC permissions:
+  can-i create pods/portforward in namespace ambassador: RBAC: access denied
+  can-i watch statefulsets.apps in namespace dev: RBAC: access denied`, err.Error())
+}
+
+func TestCheckPermissions_clusterWide(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	cs := restrictedClientset(func(ra *auth.ResourceAttributes) bool {
+		return ra.Resource == "namespaces" && ra.Verb == "watch"
+	})
+	ctx = k8sapi.WithK8sInterface(ctx, cs)
+
+	// Without a manager namespace and namespaces, the traffic-manager isn't checked and the workloads are checked cluster-wide.
+	report, err := CheckPermissions(ctx, "", nil)
+	require.NoError(t, err)
+	require.Len(t, report.Missing, 3)
+	assert.Equal(t, "namespaces", report.Missing[0].Resource)
+	assert.Equal(t, "watch", report.Missing[0].Verb)
+	for _, mp := range report.Missing {
+		assert.Empty(t, mp.Namespace)
+		assert.True(t, mp.Optional)
+	}
+	assert.NoError(t, MissingPermissionsError(report))
+}
+
+func TestCheckPermissions_managerService(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	cfg.Cluster().ManagerService = "tm"
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+	cs := restrictedClientset(func(ra *auth.ResourceAttributes) bool {
+		return ra.Resource == "services" && ra.Name != ""
+	})
+	ctx = k8sapi.WithK8sInterface(ctx, cs)
+
+	// The service that is checked is the one named by the cluster.managerService setting.
+	report, err := CheckPermissions(ctx, "ambassador", []string{"dev"})
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Missing)
+	assert.Equal(t, &connector.MissingPermission{
+		Namespace: "ambassador", Verb: "get", Resource: "services", Name: "tm", Reason: "RBAC: access denied",
+	}, report.Missing[0])
+}
@@ -74,6 +74,7 @@ func InitContext(ctx context.Context, name string, strategy RotationStrategy, ca
 	}
 	tlog.SetLogrusLevel(logger, level.String(), false)
 	ctx = tlog.WithLevelSetter(ctx, logger)
+	tlog.SetSubsystemLevels(ctx, logLevels.Subsystems)
 	return ctx, nil
 }
 
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// HasManagerCluster returns true when the traffic-manager is configured to be found in a cluster other than the
+// one where the workloads are, by means of the ManagerKubeconfig or ManagerContext.
+func (cc *Cluster) HasManagerCluster() bool {
+	return cc.ManagerKubeconfig != "" || cc.ManagerContext != ""
+}
+
+// ManagerRestConfig returns the rest.Config of the cluster where the traffic-manager is installed. The config is
+// loaded from the ManagerKubeconfig, or from the default kubeconfig when it's empty, using the ManagerContext, or
+// the current context of that kubeconfig when it's empty. The config trusts the certificates of the CABundle, just
+// like the config of the workload cluster. A nil config is returned when HasManagerCluster is false.
+func (cc *Cluster) ManagerRestConfig() (*rest.Config, error) {
+	if !cc.HasManagerCluster() {
+		return nil, nil
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cc.ManagerKubeconfig != "" {
+		rules.ExplicitPath = cc.ManagerKubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cc.ManagerContext}
+	rc, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, errcat.Config.Newf("unable to load the traffic-manager cluster from cluster.managerKubeconfig %q and cluster.managerContext %q: %v",
+			cc.ManagerKubeconfig, cc.ManagerContext, err)
+	}
+	return cc.ApplyCABundle(rc)
+}
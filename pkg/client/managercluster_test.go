@@ -0,0 +1,80 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+const hubAndSpokeKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: hub
+  cluster:
+    server: https://hub.example.com
+- name: spoke
+  cluster:
+    server: https://spoke.example.com
+contexts:
+- name: hub
+  context:
+    cluster: hub
+    user: dev
+- name: spoke
+  context:
+    cluster: spoke
+    user: dev
+current-context: spoke
+users:
+- name: dev
+  user:
+    token: secret
+`
+
+func TestCluster_ManagerRestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(hubAndSpokeKubeconfig), 0o600))
+
+	// Not configured.
+	cc := &Cluster{}
+	assert.False(t, cc.HasManagerCluster())
+	rc, err := cc.ManagerRestConfig()
+	require.NoError(t, err)
+	assert.Nil(t, rc)
+
+	// The current context of the kubeconfig is used when no context is given.
+	cc = &Cluster{ManagerKubeconfig: path}
+	assert.True(t, cc.HasManagerCluster())
+	rc, err = cc.ManagerRestConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://spoke.example.com", rc.Host)
+
+	cc = &Cluster{ManagerKubeconfig: path, ManagerContext: "hub"}
+	rc, err = cc.ManagerRestConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://hub.example.com", rc.Host)
+
+	// The default kubeconfig is used when only a context is given.
+	t.Setenv("KUBECONFIG", path)
+	cc = &Cluster{ManagerContext: "hub"}
+	rc, err = cc.ManagerRestConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://hub.example.com", rc.Host)
+
+	// The certificates of the CABundle are trusted.
+	caPEM := otherCAPEM(t)
+	cc = &Cluster{ManagerKubeconfig: path, ManagerContext: "hub", CABundle: string(caPEM)}
+	rc, err = cc.ManagerRestConfig()
+	require.NoError(t, err)
+	assert.Contains(t, string(rc.CAData), string(caPEM))
+
+	cc = &Cluster{ManagerKubeconfig: path, ManagerContext: "missing"}
+	_, err = cc.ManagerRestConfig()
+	require.Error(t, err)
+	assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+}
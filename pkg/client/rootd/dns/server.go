@@ -64,6 +64,17 @@ var DefaultExcludeSuffixes = client.DefaultExcludeSuffixes //nolint:gochecknoglo
 type nsAndDomains struct {
 	domains   []string
 	namespace string
+
+	// includeSuffixes and excludeSuffixes replace the ones of the DNS config unless they're empty.
+	includeSuffixes []string
+	excludeSuffixes []string
+}
+
+func (das *nsAndDomains) equal(o *nsAndDomains) bool {
+	return das.namespace == o.namespace &&
+		slices.Equal(das.domains, o.domains) &&
+		slices.Equal(das.includeSuffixes, o.includeSuffixes) &&
+		slices.Equal(das.excludeSuffixes, o.excludeSuffixes)
 }
 
 // Server is a DNS server which implements the github.com/miekg/dns Handler interface.
@@ -288,6 +299,14 @@ func (s *Server) isDomainExcluded(name string) bool {
 	return slices.Contains(s.ExcludeSuffixes, "."+name)
 }
 
+// serviceSubdomain returns the configured service subdomain, or "svc" when none is configured.
+func (s *Server) serviceSubdomain() string {
+	if s.ServiceSubdomain != "" {
+		return s.ServiceSubdomain
+	}
+	return client.DefaultServiceSubdomain
+}
+
 func (s *Server) resolveInCluster(c context.Context, q *dns.Question) (result dnsproxy.RRs, rCode int, err error) {
 	query := q.Name
 	if query == "localhost." {
@@ -381,11 +400,15 @@ func (s *Server) SetClusterDNS(dns *manager.DNS, remoteIP netip.Addr) {
 	s.Unlock()
 }
 
-// SetTopLevelDomainsAndSearchPath updates the DNS top level domains and the search path used by the resolver.
-func (s *Server) SetTopLevelDomainsAndSearchPath(ctx context.Context, domains []string, namespace string) {
+// SetTopLevelDomainsAndSearchPath updates the DNS top level domains and the search path used by the resolver. The
+// include and exclude suffixes of the DNS config are replaced by the given ones, unless they're empty. Domains that
+// match an exclude suffix are never routed to this resolver.
+func (s *Server) SetTopLevelDomainsAndSearchPath(ctx context.Context, domains []string, namespace string, includeSuffixes, excludeSuffixes []string) {
 	das := nsAndDomains{
-		domains:   domains,
-		namespace: namespace,
+		domains:         domains,
+		namespace:       namespace,
+		includeSuffixes: includeSuffixes,
+		excludeSuffixes: excludeSuffixes,
 	}
 	select {
 	case <-ctx.Done():
@@ -460,39 +483,17 @@ func (s *Server) processSearchPaths(g *dgroup.Group, processor func(context.Cont
 			domains:   []string{},
 			namespace: "",
 		}
-		unchanged := func(das nsAndDomains) bool {
-			return das.namespace == prevDas.namespace && slices.Equal(das.domains, prevDas.domains)
-		}
-
 		for {
 			select {
 			case <-c.Done():
 				return nil
 			case das := <-s.nsAndDomainsCh:
 				// Only interested in the last one, and only if it differs
-				if len(s.nsAndDomainsCh) > 0 || unchanged(das) {
+				if len(s.nsAndDomainsCh) > 0 || das.equal(&prevDas) {
 					continue
 				}
 				prevDas = das
-
-				routes := make(map[string]struct{}, len(das.domains))
-				for _, domain := range das.domains {
-					if domain != "" && !s.isDomainExcluded(domain) {
-						routes[domain] = struct{}{}
-					}
-				}
-				if !s.isDomainExcluded("svc") {
-					routes["svc"] = struct{}{}
-				}
-				s.Lock()
-				s.routes = routes
-
-				// The connected namespace must be included as a search path for the cases
-				// where it's up to the traffic-manager to resolve. It cannot resolve a single
-				// label name intended for other namespaces.
-				s.search = []string{tel2SubDomain, das.namespace}
-				s.Unlock()
-
+				s.applyNsAndDomains(&das)
 				if err := processor(c, dev); err != nil {
 					return err
 				}
@@ -501,6 +502,34 @@ func (s *Server) processSearchPaths(g *dgroup.Group, processor func(context.Cont
 	})
 }
 
+// applyNsAndDomains updates the suffixes, the routes, and the search path from the given nsAndDomains.
+func (s *Server) applyNsAndDomains(das *nsAndDomains) {
+	s.Lock()
+	defer s.Unlock()
+	if len(das.includeSuffixes) > 0 {
+		s.IncludeSuffixes = sliceToLower(slices.Clone(das.includeSuffixes))
+	}
+	if len(das.excludeSuffixes) > 0 {
+		s.ExcludeSuffixes = sliceToLower(slices.Clone(das.excludeSuffixes))
+	}
+
+	routes := make(map[string]struct{}, len(das.domains))
+	for _, domain := range das.domains {
+		if domain != "" && !s.isDomainExcluded(domain) {
+			routes[domain] = struct{}{}
+		}
+	}
+	if svcDomain := s.serviceSubdomain(); !s.isDomainExcluded(svcDomain) {
+		routes[svcDomain] = struct{}{}
+	}
+	s.routes = routes
+
+	// The connected namespace must be included as a search path for the cases
+	// where it's up to the traffic-manager to resolve. It cannot resolve a single
+	// label name intended for other namespaces.
+	s.search = []string{tel2SubDomain, das.namespace}
+}
+
 func (s *Server) flushDNS() {
 	s.cache.Range(func(key cacheKey, _ *cacheEntry) bool {
 		if old, ok := s.cache.LoadAndDelete(key); ok {
@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/datawire/dlib/dlog"
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 )
 
 type suiteServer struct {
@@ -130,3 +132,30 @@ func (s *suiteServer) TestIsExcluded() {
 func TestServerTestSuite(t *testing.T) {
 	suite.Run(t, new(suiteServer))
 }
+
+func TestServer_applyNsAndDomains_suffixes(t *testing.T) {
+	s := NewServer(&client.DNS{}, nil)
+	s.ctx = dlog.NewTestContext(t, false)
+	s.applyNsAndDomains(&nsAndDomains{
+		domains:         []string{"blue", "corp"},
+		namespace:       "blue",
+		includeSuffixes: []string{".svc.corp"},
+		excludeSuffixes: []string{".corp", ".COM"},
+	})
+
+	// The namespace "corp" is mapped, but it's excluded, so it isn't routed to the cluster.
+	assert.Equal(t, map[string]struct{}{"blue": {}, "svc": {}}, s.routes)
+	assert.Equal(t, []string{".corp", ".com"}, s.ExcludeSuffixes)
+
+	// Names in the excluded domains bypass the cluster, unless they match a more specific include suffix.
+	assert.False(t, s.shouldDoClusterLookup("intranet.corp."))
+	assert.False(t, s.shouldDoClusterLookup("example.com."))
+	assert.True(t, s.shouldDoClusterLookup("db.svc.corp."))
+	assert.True(t, s.shouldDoClusterLookup("echo.blue."))
+
+	// Empty suffixes retain the current ones.
+	s.applyNsAndDomains(&nsAndDomains{domains: []string{"corp"}, namespace: "blue"})
+	assert.Equal(t, []string{".svc.corp"}, s.IncludeSuffixes)
+	assert.Equal(t, []string{".corp", ".com"}, s.ExcludeSuffixes)
+	assert.Equal(t, map[string]struct{}{"svc": {}}, s.routes)
+}
@@ -86,7 +86,7 @@ func (rd *InProcSession) GetNetworkConfig(ctx context.Context, _ *empty.Empty, _
 }
 
 func (rd *InProcSession) SetDNSTopLevelDomains(ctx context.Context, in *rpc.Domains, _ ...grpc.CallOption) (*empty.Empty, error) {
-	rd.SetTopLevelDomains(ctx, in.Domains)
+	rd.SetTopLevelDomains(ctx, in)
 	return &empty.Empty{}, nil
 }
 
@@ -112,7 +112,7 @@ func (rd *InProcSession) TranslateEnvIPs(ctx context.Context, in *rpc.Environmen
 
 func (rd *InProcSession) WaitForNetwork(ctx context.Context, _ *empty.Empty, _ ...grpc.CallOption) (*empty.Empty, error) {
 	if err, ok := <-rd.networkReady(ctx); ok {
-		return &empty.Empty{}, status.Error(codes.Unavailable, err.Error())
+		return &empty.Empty{}, networkError(err)
 	}
 	return &empty.Empty{}, nil
 }
@@ -121,6 +121,20 @@ func (rd *InProcSession) WaitForAgentIP(ctx context.Context, request *rpc.WaitFo
 	return rd.waitForAgentIP(ctx, request)
 }
 
+func (rd *InProcSession) GetDialActivity(context.Context, *empty.Empty, ...grpc.CallOption) (*rpc.DialActivity, error) {
+	return rd.getDialActivity(), nil
+}
+
+func (rd *InProcSession) GetRoutingTable(context.Context, *empty.Empty, ...grpc.CallOption) (*rpc.RoutingTable, error) {
+	return rd.getRoutingTable(), nil
+}
+
+// SetManagerClient makes the session use the given manager client instead of the one that it was created with.
+// It's called when the user daemon reconnects to the traffic-manager.
+func (rd *InProcSession) SetManagerClient(mc manager.ManagerClient) {
+	rd.setManagerClient(&userdToManagerShortcut{mc})
+}
+
 // NewInProcSession returns a root daemon session suitable to use in-process (from the user daemon) and is primarily intended for
 // when the user daemon runs in a docker container with NET_ADMIN capabilities.
 func NewInProcSession(
@@ -0,0 +1,73 @@
+package rootd
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+)
+
+// getRoutingTable returns the effective routing table of the session, assembled from the subnets that
+// are currently routed by the VIF and the subnets that the session was configured with.
+func (s *Session) getRoutingTable() *rpc.RoutingTable {
+	s.routingLock.RLock()
+	defer s.routingLock.RUnlock()
+	var routed []netip.Prefix
+	if s.tunVif != nil {
+		routed = s.tunVif.Router.GetRoutedSubnets()
+	}
+	return s.routingTable(routed)
+}
+
+// routingTable returns a table with one route for each of the given routed subnets, each effective
+// never-proxy subnet, and each subnet that is routed via a workload. The routes are sorted by subnet.
+// The caller must hold the routingLock.
+func (s *Session) routingTable(routed []netip.Prefix) *rpc.RoutingTable {
+	routes := make([]*rpc.Route, 0, len(routed)+len(s.effectiveNeverProxy)+len(s.localTranslationSubnets))
+	for _, sn := range routed {
+		routes = append(routes, &rpc.Route{
+			Subnet: sn.String(),
+			Action: rpc.Route_PROXY,
+			Source: s.subnetSource(sn),
+		})
+	}
+	for _, sn := range s.effectiveNeverProxy {
+		routes = append(routes, &rpc.Route{
+			Subnet: sn.String(),
+			Action: rpc.Route_NEVER_PROXY,
+			Source: "never-proxy",
+		})
+	}
+	for _, sn := range s.localTranslationSubnets {
+		routes = append(routes, &rpc.Route{
+			Subnet:   sn.String(),
+			Action:   rpc.Route_VIA_WORKLOAD,
+			Source:   s.subnetSource(sn.Prefix),
+			Workload: sn.workload,
+		})
+	}
+	slices.SortStableFunc(routes, func(a, b *rpc.Route) int {
+		if c := strings.Compare(a.Subnet, b.Subnet); c != 0 {
+			return c
+		}
+		return int(a.Action - b.Action)
+	})
+	return &rpc.RoutingTable{Routes: routes}
+}
+
+// subnetSource returns the origin of the given subnet.
+func (s *Session) subnetSource(sn netip.Prefix) string {
+	switch {
+	case sn == s.serviceSubnet:
+		return "service"
+	case slices.Contains(s.podSubnets, sn):
+		return "pods"
+	case slices.Contains(s.alsoProxySubnets, sn):
+		return "also-proxy"
+	case sn == s.dnsServerSubnet:
+		return "dns"
+	default:
+		return "cluster"
+	}
+}
@@ -0,0 +1,64 @@
+package rootd
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+func TestSession_routingTable(t *testing.T) {
+	pfx := netip.MustParsePrefix
+	s := &Session{
+		serviceSubnet:       pfx("10.96.0.0/12"),
+		podSubnets:          []netip.Prefix{pfx("10.244.0.0/16"), pfx("10.245.0.0/16")},
+		alsoProxySubnets:    []netip.Prefix{pfx("192.168.10.0/24")},
+		neverProxySubnets:   []netip.Prefix{pfx("10.244.3.0/24"), pfx("172.16.0.0/12")},
+		effectiveNeverProxy: []netip.Prefix{pfx("10.244.3.0/24")},
+		localTranslationSubnets: []agentSubnet{
+			{Prefix: pfx("10.245.0.0/16"), workload: "echo"},
+		},
+	}
+	routed := []netip.Prefix{s.serviceSubnet, s.podSubnets[0], s.alsoProxySubnets[0], pfx("10.100.0.0/16")}
+
+	assert.Equal(t, []*rpc.Route{
+		{Subnet: "10.100.0.0/16", Action: rpc.Route_PROXY, Source: "cluster"},
+		{Subnet: "10.244.0.0/16", Action: rpc.Route_PROXY, Source: "pods"},
+		{Subnet: "10.244.3.0/24", Action: rpc.Route_NEVER_PROXY, Source: "never-proxy"},
+		{Subnet: "10.245.0.0/16", Action: rpc.Route_VIA_WORKLOAD, Source: "pods", Workload: "echo"},
+		{Subnet: "10.96.0.0/12", Action: rpc.Route_PROXY, Source: "service"},
+		{Subnet: "192.168.10.0/24", Action: rpc.Route_PROXY, Source: "also-proxy"},
+	}, s.routingTable(routed).Routes)
+}
+
+func TestSession_routingTable_empty(t *testing.T) {
+	s := &Session{}
+	assert.Empty(t, s.routingTable(nil).Routes)
+}
+
+func TestSession_getRoutingTable_concurrentClusterInfo(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &Session{}
+	mgrInfo := &manager.ClusterInfo{Routing: &manager.Routing{
+		AlsoProxySubnets: []*manager.IPNet{iputil.PrefixToRPC(netip.MustParsePrefix("192.168.10.0/24"))},
+	}}
+
+	// The routing table can be read while the traffic-manager reports new cluster info.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			assert.NoError(t, s.readAdditionalRouting(ctx, mgrInfo))
+		}
+	}()
+	for range 100 {
+		s.getRoutingTable()
+	}
+	<-done
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("192.168.10.0/24")}, s.alsoProxySubnets)
+}
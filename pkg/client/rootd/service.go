@@ -163,7 +163,7 @@ func (s *Service) Quit(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, e
 
 func (s *Service) SetDNSTopLevelDomains(ctx context.Context, domains *rpc.Domains) (*emptypb.Empty, error) {
 	err := s.WithSession(func(ctx context.Context, session *Session) error {
-		session.SetTopLevelDomains(ctx, domains.Domains)
+		session.SetTopLevelDomains(ctx, domains)
 		return nil
 	})
 	return &emptypb.Empty{}, err
@@ -226,7 +226,7 @@ func (s *Service) TranslateEnvIPs(ctx context.Context, environment *rpc.Environm
 func (s *Service) WaitForNetwork(ctx context.Context, e *emptypb.Empty) (*emptypb.Empty, error) {
 	err := s.WithSession(func(ctx context.Context, session *Session) error {
 		if err, ok := <-session.networkReady(ctx); ok {
-			return status.Error(codes.Unavailable, err.Error())
+			return networkError(err)
 		}
 		return nil
 	})
@@ -283,6 +283,22 @@ func (s *Service) WaitForAgentIP(ctx context.Context, request *rpc.WaitForAgentI
 	return rsp, err
 }
 
+func (s *Service) GetDialActivity(_ context.Context, _ *emptypb.Empty) (rsp *rpc.DialActivity, err error) {
+	err = s.WithSession(func(_ context.Context, session *Session) error {
+		rsp = session.getDialActivity()
+		return nil
+	})
+	return rsp, err
+}
+
+func (s *Service) GetRoutingTable(_ context.Context, _ *emptypb.Empty) (rsp *rpc.RoutingTable, err error) {
+	err = s.WithSession(func(_ context.Context, session *Session) error {
+		rsp = session.getRoutingTable()
+		return nil
+	})
+	return rsp, err
+}
+
 func (s *Service) SetLogLevel(ctx context.Context, request *manager.LogLevelRequest) (*emptypb.Empty, error) {
 	duration := time.Duration(0)
 	if request.Duration != nil {
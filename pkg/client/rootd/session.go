@@ -26,6 +26,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	empty "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -48,6 +49,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
 	"github.com/telepresenceio/telepresence/v2/pkg/slice"
 	"github.com/telepresenceio/telepresence/v2/pkg/subnet"
 	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
@@ -92,7 +94,12 @@ type Session struct {
 	// agentClients provides the gRPC tunnel to traffic-agents in the connected namespace
 	agentClients agentpf.Clients
 
-	// managerClient provides the gRPC tunnel to the traffic-manager
+	// dialActivity records the dial requests received from traffic-agents by the agentClients
+	dialActivity *tunnel.DialActivity
+
+	// managerClient provides the gRPC tunnel to the traffic-manager. It's replaced when the user daemon
+	// reconnects to the traffic-manager, so it must be accessed using getManagerClient.
+	managerLock   sync.RWMutex
 	managerClient connector.ManagerProxyClient
 
 	// managerVersion is the version of the connected traffic-manager
@@ -117,6 +124,10 @@ type Session struct {
 	// dnsLocalAddr is the address of the local DNS Service.
 	dnsLocalAddr *net.UDPAddr
 
+	// routingLock protects the tunVif and the subnets below while they are updated with the cluster info
+	// reported by the traffic-manager, so that the routing table can be read concurrently.
+	routingLock sync.RWMutex
+
 	// serviceSubnet reported by the traffic-manager
 	serviceSubnet netip.Prefix
 
@@ -220,7 +231,12 @@ func createK8sConfig(ctx context.Context, kubeFlags map[string]string, kubeData
 	if err != nil {
 		return nil, err
 	}
-	return config.ClientConfig()
+	rc, err := config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Trust the certificates of the cluster.caBundle, just like the user daemon does.
+	return client.GetConfig(ctx).Cluster().ApplyCABundle(rc)
 }
 
 // connectToManager connects to the traffic-manager and asserts that its version is compatible.
@@ -254,8 +270,13 @@ func connectToManager(
 	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, acs)
 
 	clientConfig := client.GetConfig(ctx)
-	if !clientConfig.Cluster().ConnectFromRootDaemon {
-		dlog.Debug(ctx, "ConnectFromRootDaemon is disabled")
+	if cc := clientConfig.Cluster(); !cc.ConnectFromRootDaemon || cc.HasManagerCluster() {
+		if cc.ConnectFromRootDaemon {
+			// The kubeconfig passed to the root daemon is for the workload cluster, not the manager cluster.
+			dlog.Debug(ctx, "ConnectFromRootDaemon is not used with a separate manager cluster")
+		} else {
+			dlog.Debug(ctx, "ConnectFromRootDaemon is disabled")
+		}
 		conn, mp, v, err := connectToUserDaemon(ctx)
 		return ctx, conn, mp, v, err
 	}
@@ -348,10 +369,35 @@ func NewSession(c context.Context, mi *rpc.NetworkConfig) (context.Context, *Ses
 
 func nope() bool { return false }
 
+// realManagerClientProvider is implemented by a manager proxy client that is a shortcut to the manager client
+// of the user daemon.
+type realManagerClientProvider interface {
+	RealManagerClient() manager.ManagerClient
+}
+
+func (s *Session) getManagerClient() connector.ManagerProxyClient {
+	s.managerLock.RLock()
+	defer s.managerLock.RUnlock()
+	return s.managerClient
+}
+
+func (s *Session) setManagerClient(mc connector.ManagerProxyClient) {
+	s.managerLock.Lock()
+	s.managerClient = mc
+	s.managerLock.Unlock()
+}
+
+// realManagerClient returns the manager client of the user daemon that the current manager client is a
+// shortcut to.
+func (s *Session) realManagerClient() manager.ManagerClient {
+	return s.getManagerClient().(realManagerClientProvider).RealManagerClient()
+}
+
 func newSession(c context.Context, mi *rpc.NetworkConfig, mc connector.ManagerProxyClient, ver semver.Version, isPodDaemon bool) (context.Context, *Session, error) {
 	dlog.Debugf(c, "Creating session with id %v", mi.Session)
 	s := &Session{
 		handlers:              tunnel.NewPool(),
+		dialActivity:          tunnel.NewDialActivity(),
 		rndSource:             rand.NewSource(time.Now().UnixNano()),
 		session:               mi.Session,
 		namespace:             mi.Namespace,
@@ -388,7 +434,7 @@ func newSession(c context.Context, mi *rpc.NetworkConfig, mc connector.ManagerPr
 	dlog.Infof(c, "allow-conflicting subnets %v", s.allowConflictingSubnets)
 
 	s.dnsServer = dns.NewServer(cfg.DNS(), s.clusterLookup)
-	s.SetTopLevelDomains(c, nil)
+	s.SetTopLevelDomains(c, &rpc.Domains{})
 	return c, s, nil
 }
 
@@ -397,7 +443,7 @@ func (s *Session) clusterLookup(ctx context.Context, q *dns2.Question) (dnsproxy
 	dlog.Debugf(ctx, "Lookup %s %q", dns2.TypeToString[q.Qtype], q.Name)
 	s.dnsLookups++
 
-	r, err := s.managerClient.LookupDNS(ctx, &manager.DNSRequest{
+	r, err := s.getManagerClient().LookupDNS(ctx, &manager.DNSRequest{
 		Session: s.session,
 		Name:    q.Name,
 		Type:    uint32(q.Qtype),
@@ -576,11 +622,25 @@ func (s *Session) networkReady(ctx context.Context) <-chan error {
 	return rdy
 }
 
+// networkError converts an error that prevented the network from being set up into a gRPC status. A failure
+// to create the TUN device gets the code PermissionDenied when it's caused by missing permissions, and
+// FailedPrecondition otherwise, so that clients can tell those failures apart without parsing the message.
+func networkError(err error) error {
+	var te *vif.OpenTunError
+	if errors.As(err, &te) {
+		if errors.Is(err, os.ErrPermission) {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
 func (s *Session) watchClusterInfo(ctx context.Context) error {
 	backoff := 100 * time.Millisecond
 
 	for ctx.Err() == nil {
-		infoStream, err := s.managerClient.WatchClusterInfo(ctx, s.session)
+		infoStream, err := s.getManagerClient().WatchClusterInfo(ctx, s.session)
 		if err != nil {
 			err = fmt.Errorf("error when calling WatchClusterInfo: %w", err)
 			dlog.Warn(ctx, err)
@@ -717,7 +777,15 @@ func (s *Session) defaultRouteDNS(ctx context.Context, mgrInfo *manager.ClusterI
 	return dnsAddr, subnets, nil
 }
 
-func (s *Session) onClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInfo) (err error) {
+func (s *Session) onClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInfo) error {
+	s.routingLock.Lock()
+	defer s.routingLock.Unlock()
+	return s.applyClusterInfo(ctx, mgrInfo)
+}
+
+// applyClusterInfo updates the subnets and routes of the session from the given cluster info. The caller
+// must hold the routingLock.
+func (s *Session) applyClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInfo) (err error) {
 	if s.podDaemon {
 		return nil
 	}
@@ -838,7 +906,7 @@ func (s *Session) onClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInf
 			dlog.Errorf(ctx, "activateProxyViaWorkloads: %v", aErr)
 			return err
 		}
-		return s.onClusterInfo(ctx, mgrInfo)
+		return s.applyClusterInfo(ctx, mgrInfo)
 	}
 
 	dlog.Debugf(ctx, "UpdatinRoutes %s, %s, %s", proxy, s.effectiveNeverProxy, neverProxyOverrides)
@@ -898,6 +966,8 @@ func (s *Session) alsoProxyVia() bool {
 }
 
 func (s *Session) readAdditionalRouting(ctx context.Context, mgrInfo *manager.ClusterInfo) error {
+	s.routingLock.Lock()
+	defer s.routingLock.Unlock()
 	if r := mgrInfo.Routing; r != nil {
 		sns, err := validateSubnets("also-proxy", iputil.RPCsToPrefixes(r.AlsoProxySubnets), s.alsoProxyVia)
 		if err != nil {
@@ -1045,20 +1115,23 @@ func (s *Session) run(c context.Context, initErrs chan error) error {
 }
 
 func (s *Session) Start(c context.Context, g *dgroup.Group) error {
-	if rmc, ok := s.managerClient.(interface{ RealManagerClient() manager.ManagerClient }); ok {
+	if _, ok := s.getManagerClient().(realManagerClientProvider); ok {
 		clusterCfg := client.GetConfig(c).Cluster()
 		if clusterCfg.AgentPortForward && clusterCfg.ConnectFromRootDaemon {
 			if k8sclient.CanPortForward(c, s.namespace) {
 				s.agentClients = agentpf.NewClients(s.session)
 				g.Go("agentPods", func(ctx context.Context) error {
-					return s.agentClients.WatchAgentPods(ctx, rmc.RealManagerClient())
+					return s.agentClients.WatchAgentPods(tunnel.WithDialActivity(ctx, s.dialActivity), s.realManagerClient)
 				})
 			} else {
 				dlog.Infof(c, "Agent port-forwards are disabled. Client is not permitted to do port-forward to namespace %s", s.namespace)
 			}
 		}
 	}
-	if err := s.activateProxyViaWorkloads(c); err != nil {
+	s.routingLock.Lock()
+	err := s.activateProxyViaWorkloads(c)
+	s.routingLock.Unlock()
+	if err != nil {
 		return err
 	}
 	if s.podDaemon {
@@ -1114,7 +1187,7 @@ func (s *Session) Start(c context.Context, g *dgroup.Group) error {
 		if s.tunVif != nil {
 			dev = s.tunVif.Device
 		}
-		return s.dnsServer.Worker(ctx, dev, s.configureDNS)
+		return s.dnsServer.Worker(log.WithSubsystem(ctx, log.SubsystemDNS), dev, s.configureDNS)
 	})
 
 	if s.tunVif != nil {
@@ -1168,7 +1241,7 @@ func (s *Session) activateProxyViaWorkloads(ctx context.Context) error {
 			return errcat.User.Newf("Agent port-forwards are disabled. Client is not permitted to do proxy-via %s", wlName)
 		}
 		dlog.Debugf(ctx, "Ensuring proxy-via agent in %s", wlName)
-		_, err := s.managerClient.EnsureAgent(ctx, &manager.EnsureAgentRequest{
+		_, err := s.getManagerClient().EnsureAgent(ctx, &manager.EnsureAgentRequest{
 			Session: s.session,
 			Name:    wlName,
 		})
@@ -1264,8 +1337,8 @@ func (s *Session) waitForProxyViaWorkloads(ctx context.Context) error {
 	return nil
 }
 
-func (s *Session) SetTopLevelDomains(ctx context.Context, topLevelDomains []string) {
-	s.dnsServer.SetTopLevelDomainsAndSearchPath(ctx, topLevelDomains, s.namespace)
+func (s *Session) SetTopLevelDomains(ctx context.Context, domains *rpc.Domains) {
+	s.dnsServer.SetTopLevelDomainsAndSearchPath(ctx, domains.Domains, s.namespace, domains.IncludeSuffixes, domains.ExcludeSuffixes)
 }
 
 func (s *Session) SetExcludes(ctx context.Context, excludes []string) {
@@ -1326,6 +1399,15 @@ func (s *Session) waitForAgentIP(ctx context.Context, request *rpc.WaitForAgentI
 	return &rpc.WaitForAgentIPResponse{LocalIp: ip.AsSlice()}, nil
 }
 
+func (s *Session) getDialActivity() *rpc.DialActivity {
+	snapshot := s.dialActivity.Snapshot(time.Now())
+	da := &rpc.DialActivity{LastDial: make(map[string]*timestamppb.Timestamp, len(snapshot))}
+	for dst, at := range snapshot {
+		da.LastDial[dst.String()] = timestamppb.New(at)
+	}
+	return da
+}
+
 func (s *Session) Done() <-chan struct{} {
 	return s.done
 }
@@ -120,7 +120,7 @@ func (s *Session) streamCreator(ctx context.Context) tunnel.StreamCreator {
 			if tp != nil {
 				dlog.Debugf(c, "Opening traffic-agent tunnel for id %s", id)
 			} else {
-				tp = tunnel.ManagerProxyProvider(s.managerClient)
+				tp = tunnel.ManagerProxyProvider(s.getManagerClient())
 				dlog.Debugf(c, "Opening traffic-manager tunnel for id %s", id)
 			}
 		}
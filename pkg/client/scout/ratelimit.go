@@ -0,0 +1,85 @@
+package scout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a Reporter that coalesces repeated reports of the same kind that are made within a window, and
+// passes the remaining reports on to the Reporter that it wraps.
+type rateLimiter struct {
+	Reporter
+	window time.Duration
+	now    func() time.Time
+
+	sync.Mutex
+	passed    map[string]time.Time // time of the last report of each kind that was passed on
+	coalesced map[string]int       // number of reports of each kind that were coalesced since then
+}
+
+// WithRateLimit returns a context with a Reporter that coalesces the reports made using the Reporter of the given
+// context. A report of a kind that has been reported within the given window is coalesced with the report that
+// follows the window, which gets a "coalesced" entry with the number of reports that it represents. The kind of
+// a report is its action, except for the "connect" and "connect_error" actions. Those are never coalesced with
+// reports that differ in their entries, only deduplicated. The count of reports that are coalesced after the last
+// report of a kind is lost. The given context is returned when the window isn't positive or when it has no Reporter.
+func WithRateLimit(ctx context.Context, window time.Duration) context.Context {
+	r := getReporter(ctx)
+	if r == nil || window <= 0 {
+		return ctx
+	}
+	if rl, ok := r.(*rateLimiter); ok {
+		// Replace the limiter rather than stacking them.
+		r = rl.Reporter
+	}
+	return WithReporter(ctx, newRateLimiter(r, window, time.Now))
+}
+
+func newRateLimiter(r Reporter, window time.Duration, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		Reporter:  r,
+		window:    window,
+		now:       now,
+		passed:    make(map[string]time.Time),
+		coalesced: make(map[string]int),
+	}
+}
+
+// reportKind returns the kind of the given report. Reports of the same kind are coalesced.
+func reportKind(action string, entries []Entry) string {
+	switch action {
+	case "connect", "connect_error":
+		return fmt.Sprintf("%s%v", action, entries)
+	default:
+		return action
+	}
+}
+
+func (r *rateLimiter) Report(ctx context.Context, action string, entries ...Entry) {
+	kind := reportKind(action, entries)
+	now := r.now()
+	r.Lock()
+	if t, ok := r.passed[kind]; ok && now.Sub(t) < r.window {
+		r.coalesced[kind]++
+		r.Unlock()
+		return
+	}
+	n := r.coalesced[kind]
+	delete(r.coalesced, kind)
+	r.passed[kind] = now
+
+	// Forget kinds that can no longer be coalesced, so that the maps don't grow with each distinct report.
+	for k, t := range r.passed {
+		if now.Sub(t) >= r.window && r.coalesced[k] == 0 {
+			delete(r.passed, k)
+		}
+	}
+	r.Unlock()
+
+	if n > 0 {
+		entries = append(entries[:len(entries):len(entries)], Entry{Key: "coalesced", Value: n + 1})
+	}
+	r.Reporter.Report(ctx, action, entries...)
+}
@@ -0,0 +1,106 @@
+package scout
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type report struct {
+	action  string
+	entries []Entry
+}
+
+// recorder is a Reporter that records the reports that it receives.
+type recorder struct {
+	Reporter
+	sync.Mutex
+	reports []report
+}
+
+func (r *recorder) Report(_ context.Context, action string, entries ...Entry) {
+	r.Lock()
+	r.reports = append(r.reports, report{action, entries})
+	r.Unlock()
+}
+
+func Test_rateLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	rec := &recorder{}
+	rl := newRateLimiter(rec, time.Minute, func() time.Time { return now })
+
+	rl.Report(ctx, "retry_storm", Entry{Key: "attempts", Value: 5})
+	rl.Report(ctx, "retry_storm", Entry{Key: "attempts", Value: 6})
+	rl.Report(ctx, "retry_storm", Entry{Key: "attempts", Value: 7})
+	rl.Report(ctx, "intercept_idle_removed")
+	require.Len(t, rec.reports, 2)
+	assert.Equal(t, report{"retry_storm", []Entry{{Key: "attempts", Value: 5}}}, rec.reports[0])
+	assert.Equal(t, report{"intercept_idle_removed", nil}, rec.reports[1])
+
+	// The first report after the window represents the coalesced reports too.
+	now = now.Add(time.Minute)
+	rl.Report(ctx, "retry_storm", Entry{Key: "attempts", Value: 8})
+	require.Len(t, rec.reports, 3)
+	assert.Equal(t, report{"retry_storm", []Entry{{Key: "attempts", Value: 8}, {Key: "coalesced", Value: 3}}}, rec.reports[2])
+
+	now = now.Add(time.Minute)
+	rl.Report(ctx, "retry_storm", Entry{Key: "attempts", Value: 9})
+	require.Len(t, rec.reports, 4)
+	assert.Equal(t, report{"retry_storm", []Entry{{Key: "attempts", Value: 9}}}, rec.reports[3])
+}
+
+func Test_rateLimiter_connect(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	rec := &recorder{}
+	rl := newRateLimiter(rec, time.Minute, func() time.Time { return now })
+
+	failed := []Entry{{Key: "error", Value: "connection refused"}, {Key: "error_type", Value: "TRAFFIC_MANAGER_FAILED"}}
+	rl.Report(ctx, "connect_error", failed...)
+	rl.Report(ctx, "connect_error", failed...)
+	rl.Report(ctx, "connect_error", Entry{Key: "error", Value: "not found"}, Entry{Key: "error_type", Value: "TRAFFIC_MANAGER_NOT_FOUND"})
+	rl.Report(ctx, "connect", Entry{Key: "time_to_connect", Value: 1.5})
+	rl.Report(ctx, "connect", Entry{Key: "time_to_connect", Value: 2.5})
+
+	// Only the identical connect_error was deduplicated.
+	require.Len(t, rec.reports, 4)
+	assert.Equal(t, "connect_error", rec.reports[0].action)
+	assert.Equal(t, "connect_error", rec.reports[1].action)
+	assert.Equal(t, "connect", rec.reports[2].action)
+	assert.Equal(t, "connect", rec.reports[3].action)
+
+	now = now.Add(time.Minute)
+	rl.Report(ctx, "connect_error", failed...)
+	require.Len(t, rec.reports, 5)
+	assert.Equal(t, append(failed, Entry{Key: "coalesced", Value: 2}), rec.reports[4].entries)
+	assert.Len(t, failed, 2, "the entries of the caller were modified")
+
+	// Kinds that can no longer be coalesced are forgotten.
+	rl.Lock()
+	assert.Len(t, rl.passed, 1)
+	rl.Unlock()
+}
+
+func TestWithRateLimit(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithRateLimit(ctx, time.Minute), "no reporter")
+
+	rec := &recorder{}
+	ctx = WithReporter(ctx, rec)
+	assert.Equal(t, ctx, WithRateLimit(ctx, 0), "no window")
+
+	ctx = WithRateLimit(WithRateLimit(ctx, time.Hour), time.Minute)
+	rl, ok := getReporter(ctx).(*rateLimiter)
+	require.True(t, ok)
+	assert.Same(t, rec, rl.Reporter, "limiters are not stacked")
+	assert.Equal(t, time.Minute, rl.window)
+
+	Report(ctx, "used_gather_logs")
+	Report(ctx, "used_gather_logs")
+	assert.Len(t, rec.reports, 1)
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+const (
+	// MaxSessionMetadataEntries is the maximum number of entries in the metadata of a session.
+	MaxSessionMetadataEntries = 32
+
+	// MaxSessionMetadataKeyLength is the maximum length, in bytes, of a key in the metadata of a session.
+	MaxSessionMetadataKeyLength = 63
+
+	// MaxSessionMetadataValueLength is the maximum length, in bytes, of a value in the metadata of a session.
+	MaxSessionMetadataValueLength = 256
+)
+
+// ValidateSessionMetadata returns an error if the given metadata has too many entries, or if one of its
+// keys is empty or too long, or if one of its values is too long.
+func ValidateSessionMetadata(md map[string]string) error {
+	if len(md) > MaxSessionMetadataEntries {
+		return fmt.Errorf("session metadata has %d entries, the maximum is %d", len(md), MaxSessionMetadataEntries)
+	}
+	for _, k := range slices.Sorted(maps.Keys(md)) {
+		switch {
+		case k == "":
+			return errors.New("session metadata key must not be empty")
+		case len(k) > MaxSessionMetadataKeyLength:
+			return fmt.Errorf("session metadata key %q is longer than %d bytes", k, MaxSessionMetadataKeyLength)
+		case len(md[k]) > MaxSessionMetadataValueLength:
+			return fmt.Errorf("value of session metadata key %q is longer than %d bytes", k, MaxSessionMetadataValueLength)
+		}
+	}
+	return nil
+}
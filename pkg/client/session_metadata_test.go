@@ -0,0 +1,41 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSessionMetadata(t *testing.T) {
+	tooMany := make(map[string]string, MaxSessionMetadataEntries+1)
+	for i := range MaxSessionMetadataEntries + 1 {
+		tooMany[fmt.Sprintf("key-%d", i)] = "value"
+	}
+	tests := []struct {
+		name    string
+		md      map[string]string
+		wantErr string
+	}{
+		{"nil", nil, ""},
+		{"valid", map[string]string{"build": "1234", "purpose": ""}, ""},
+		{"max sizes", map[string]string{
+			strings.Repeat("k", MaxSessionMetadataKeyLength): strings.Repeat("v", MaxSessionMetadataValueLength),
+		}, ""},
+		{"empty key", map[string]string{"": "x"}, "session metadata key must not be empty"},
+		{"long key", map[string]string{strings.Repeat("k", MaxSessionMetadataKeyLength+1): "x"}, "is longer than 63 bytes"},
+		{"long value", map[string]string{"build": strings.Repeat("v", MaxSessionMetadataValueLength+1)}, `value of session metadata key "build" is longer than 256 bytes`},
+		{"too many", tooMany, "session metadata has 33 entries, the maximum is 32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSessionMetadata(tt.md)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -37,8 +37,23 @@ func errNotExist(socketName string) error {
 	}
 }
 
+// defaultReadyTimeout is the time that Dial waits for the socket to accept the connection.
+const defaultReadyTimeout = 3 * time.Second
+
 // Dial dials the given socket and returns the resulting connection.
 func Dial(ctx context.Context, socketName string, waitForSocket bool, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return DialWithTimeout(ctx, socketName, waitForSocket, defaultReadyTimeout, opts...)
+}
+
+// DialWithTimeout dials the given socket and returns the resulting connection. A socket that doesn't accept the
+// connection within the given readyTimeout is considered unresponsive and is removed.
+func DialWithTimeout(
+	ctx context.Context,
+	socketName string,
+	waitForSocket bool,
+	readyTimeout time.Duration,
+	opts ...grpc.DialOption,
+) (*grpc.ClientConn, error) {
 	if waitForSocket {
 		err := WaitForSocket(ctx, socketName, 5*time.Second)
 		if err != nil {
@@ -77,7 +92,7 @@ func Dial(ctx context.Context, socketName string, waitForSocket bool, opts ...gr
 	}, &b)
 
 	if err == nil {
-		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, readyTimeout)
 		err = waitUntilReady(ctx, conn)
 		cancel()
 		if err != nil {
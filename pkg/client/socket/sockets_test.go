@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -68,6 +69,22 @@ func TestDialSocket(t *testing.T) {
 		assert.Contains(t, err.Error(), "dial unix "+sockname)
 		assert.Contains(t, err.Error(), "this usually means that the process has locked up")
 	})
+	t.Run("HangWithTimeout", func(t *testing.T) {
+		sockname := filepath.Join(t.TempDir(), "hang.sock")
+		listener, err := net.Listen("unix", sockname)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer listener.Close()
+
+		ctx := dlog.NewTestContext(t, false)
+		start := time.Now()
+		conn, err := socket.DialWithTimeout(ctx, sockname, true, 200*time.Millisecond)
+		assert.Nil(t, conn)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 2*time.Second)
+		assert.Contains(t, err.Error(), "this usually means that the process has locked up")
+	})
 	t.Run("NotExist", func(t *testing.T) {
 		ctx := dlog.NewTestContext(t, false)
 		sockname := filepath.Join(t.TempDir(), "not-exist.sock")
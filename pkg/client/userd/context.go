@@ -2,6 +2,8 @@ package userd
 
 import (
 	"context"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 )
 
 type sessionKey struct{}
@@ -16,3 +18,18 @@ func GetSession(ctx context.Context) Session {
 	}
 	return nil
 }
+
+type connectProgressKey struct{}
+
+// WithConnectProgress returns a context where calls to ReportConnectProgress are passed on to the given function.
+func WithConnectProgress(ctx context.Context, report func(connector.ConnectProgress_Phase)) context.Context {
+	return context.WithValue(ctx, connectProgressKey{}, report)
+}
+
+// ReportConnectProgress reports that the connect has reached the given phase. It's a no-op unless the context was
+// created using WithConnectProgress.
+func ReportConnectProgress(ctx context.Context, phase connector.ConnectProgress_Phase) {
+	if report, ok := ctx.Value(connectProgressKey{}).(func(connector.ConnectProgress_Phase)); ok {
+		report(phase)
+	}
+}
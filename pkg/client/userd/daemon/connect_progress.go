@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"sync"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+)
+
+// connectProgressBuffer is the number of phases that can be queued for a subscriber before further
+// phases are dropped.
+const connectProgressBuffer = 8
+
+// connectProgress keeps track of the phase of the current connect and passes phase changes on to its
+// subscribers. The zero value is ready to use.
+type connectProgress struct {
+	sync.Mutex
+	phase       rpc.ConnectProgress_Phase
+	subscribers map[chan rpc.ConnectProgress_Phase]struct{}
+}
+
+// set sets the current phase and passes it on to all subscribers.
+func (cp *connectProgress) set(phase rpc.ConnectProgress_Phase) {
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.phase == phase {
+		return
+	}
+	cp.phase = phase
+	for ch := range cp.subscribers {
+		select {
+		case ch <- phase:
+		default:
+		}
+	}
+}
+
+// subscribe returns the current phase together with a channel that receives the phase changes, and a
+// function that ends the subscription.
+func (cp *connectProgress) subscribe() (rpc.ConnectProgress_Phase, <-chan rpc.ConnectProgress_Phase, func()) {
+	ch := make(chan rpc.ConnectProgress_Phase, connectProgressBuffer)
+	cp.Lock()
+	defer cp.Unlock()
+	if cp.subscribers == nil {
+		cp.subscribers = make(map[chan rpc.ConnectProgress_Phase]struct{})
+	}
+	cp.subscribers[ch] = struct{}{}
+	return cp.phase, ch, func() {
+		cp.Lock()
+		delete(cp.subscribers, ch)
+		cp.Unlock()
+	}
+}
@@ -0,0 +1,34 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+)
+
+func Test_connectProgress(t *testing.T) {
+	var cp connectProgress
+	cp.set(rpc.ConnectProgress_CONNECTING_TO_CLUSTER)
+
+	// A subscriber gets the current phase, followed by the changes.
+	phase, ch, unsubscribe := cp.subscribe()
+	assert.Equal(t, rpc.ConnectProgress_CONNECTING_TO_CLUSTER, phase)
+	cp.set(rpc.ConnectProgress_CONNECTING_TO_CLUSTER)
+	cp.set(rpc.ConnectProgress_CONNECTING_TO_MANAGER)
+	cp.set(rpc.ConnectProgress_WAITING_FOR_NETWORK)
+	cp.set(rpc.ConnectProgress_UNSPECIFIED)
+	assert.Equal(t, rpc.ConnectProgress_CONNECTING_TO_MANAGER, <-ch)
+	assert.Equal(t, rpc.ConnectProgress_WAITING_FOR_NETWORK, <-ch)
+	assert.Equal(t, rpc.ConnectProgress_UNSPECIFIED, <-ch)
+
+	// A slow subscriber doesn't block the connect.
+	for i := 0; i < 2*connectProgressBuffer; i++ {
+		cp.set(rpc.ConnectProgress_Phase(1 + i%4))
+	}
+	assert.Len(t, ch, connectProgressBuffer)
+
+	unsubscribe()
+	assert.Empty(t, cp.subscribers)
+}
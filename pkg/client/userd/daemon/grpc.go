@@ -13,6 +13,7 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/socket"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
 )
@@ -127,6 +129,22 @@ func (s *service) Connect(ctx context.Context, cr *rpc.ConnectRequest) (result *
 	return result, err
 }
 
+func (s *service) WatchConnectProgress(_ *empty.Empty, stream rpc.Connector_WatchConnectProgressServer) error {
+	phase, ch, unsubscribe := s.connectProgress.subscribe()
+	defer unsubscribe()
+	ctx := stream.Context()
+	for {
+		if err := stream.Send(&rpc.ConnectProgress{Phase: phase}); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case phase = <-ch:
+		}
+	}
+}
+
 func (s *service) Disconnect(ctx context.Context, ex *empty.Empty) (*empty.Empty, error) {
 	s.LogCall(ctx, "Disconnect", func(ctx context.Context) {
 		s.cancelSession()
@@ -143,7 +161,7 @@ func (s *service) Status(ctx context.Context, ex *empty.Empty) (result *rpc.Conn
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
 		if s.session == nil {
-			result = &rpc.ConnectInfo{Error: rpc.ConnectInfo_DISCONNECTED}
+			result = &rpc.ConnectInfo{Error: rpc.ConnectInfo_DISCONNECTED, ErrorText: s.sessionEndReason}
 			_ = s.withRootDaemon(c, func(c context.Context, dc daemon.DaemonClient) error {
 				result.DaemonStatus, err = dc.Status(c, ex)
 				return nil
@@ -264,6 +282,65 @@ func (s *service) CreateIntercept(c context.Context, ir *rpc.CreateInterceptRequ
 	return
 }
 
+func (s *service) CreateInterceptGroup(c context.Context, gr *rpc.CreateInterceptGroupRequest) (result *rpc.InterceptGroupResult, err error) {
+	ok := false
+	defer func() {
+		var action string
+		if ok {
+			action = "connector_create_intercept_group_success"
+		} else {
+			action = "connector_create_intercept_group_fail"
+		}
+		scout.Report(c, action, scout.Entry{Key: "intercept_count", Value: len(gr.Intercepts)})
+	}()
+	err = s.WithSession(c, "CreateInterceptGroup", func(c context.Context, session userd.Session) error {
+		r, err := session.AddInterceptGroup(c, gr)
+		if err != nil {
+			return err
+		}
+		result = r
+		rs := r.Results
+		ok = len(rs) > 0 && rs[0].Error == common.InterceptError_UNSPECIFIED
+		return nil
+	})
+	return
+}
+
+func (s *service) ConnectAndIntercept(c context.Context, cr *rpc.ConnectAndInterceptRequest) (result *rpc.ConnectAndInterceptResponse, err error) {
+	var ci *rpc.ConnectInfo
+	s.LogCall(c, "ConnectAndIntercept", func(c context.Context) {
+		if err = s.PostConnectRequest(c, crImpl{ConnectRequest: cr.Connect}); err == nil {
+			ci, err = s.ReadConnectResponse(c)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	result = &rpc.ConnectAndInterceptResponse{ConnectInfo: ci}
+	if ci.Error != rpc.ConnectInfo_UNSPECIFIED && ci.Error != rpc.ConnectInfo_ALREADY_CONNECTED {
+		return result, nil
+	}
+
+	ir := cr.Intercept
+	var entries []scout.Entry
+	ok := false
+	defer func() {
+		var action string
+		if ok {
+			action = "connector_create_intercept_success"
+		} else {
+			action = "connector_create_intercept_fail"
+		}
+		scout.Report(c, action, entries...)
+	}()
+	err = s.WithSession(c, "ConnectAndIntercept", func(c context.Context, session userd.Session) error {
+		result.Intercept = session.AddInterceptAndWait(c, ir, cr.Timeout.AsDuration())
+		entries, ok = s.scoutInterceptEntries(c, ir.GetSpec(), result.Intercept)
+		return nil
+	})
+	return result, err
+}
+
 func (s *service) RemoveIntercept(c context.Context, rr *manager.RemoveInterceptRequest2) (result *rpc.InterceptResult, err error) {
 	var spec *manager.InterceptSpec
 	var entries []scout.Entry
@@ -323,7 +400,7 @@ func (s *service) RemoveInterceptor(ctx context.Context, interceptor *rpc.Interc
 
 func (s *service) List(c context.Context, lr *rpc.ListRequest) (result *rpc.WorkloadInfoSnapshot, err error) {
 	err = s.WithSession(c, "List", func(c context.Context, session userd.Session) error {
-		result, err = session.WorkloadInfoSnapshot(c, []string{lr.Namespace}, lr.Filter)
+		result, err = session.WorkloadInfoSnapshot(c, []string{lr.Namespace}, lr.Filter, lr.Kinds, int(lr.Limit), lr.IncludePods)
 		return err
 	})
 	return
@@ -363,6 +440,25 @@ func (s *service) WatchWorkloads(wr *rpc.WatchWorkloadsRequest, stream rpc.Conne
 	return session.WatchWorkloads(sessionCtx, wr, stream)
 }
 
+func (s *service) PauseWorkloadWatch(ctx context.Context, rq *rpc.PauseWorkloadWatchRequest) (response *rpc.PauseWorkloadWatchResponse, err error) {
+	err = s.WithSession(ctx, "PauseWorkloadWatch", func(ctx context.Context, session userd.Session) error {
+		id, d := session.PauseWorkloadWatchFor(ctx, rq.Duration.AsDuration())
+		response = &rpc.PauseWorkloadWatchResponse{Id: id, Duration: durationpb.New(d)}
+		return nil
+	})
+	return response, err
+}
+
+func (s *service) ResumeWorkloadWatch(ctx context.Context, rq *rpc.ResumeWorkloadWatchRequest) (*empty.Empty, error) {
+	err := s.WithSession(ctx, "ResumeWorkloadWatch", func(ctx context.Context, session userd.Session) error {
+		if !session.ResumeWorkloadWatchPause(ctx, rq.Id) {
+			return status.Errorf(codes.NotFound, "no workload watch pause with id %q", rq.Id)
+		}
+		return nil
+	})
+	return &empty.Empty{}, err
+}
+
 func (s *service) Uninstall(c context.Context, ur *rpc.UninstallRequest) (result *common.Result, err error) {
 	err = s.WithSession(c, "Uninstall", func(c context.Context, session userd.Session) error {
 		result, err = session.Uninstall(c, ur)
@@ -387,6 +483,18 @@ func (s *service) GetConfig(ctx context.Context, _ *empty.Empty) (cfg *rpc.Clien
 	return
 }
 
+func (s *service) ExportDiagnostics(ctx context.Context, _ *empty.Empty) (result *rpc.DiagnosticsBundle, err error) {
+	err = s.WithSession(ctx, "ExportDiagnostics", func(c context.Context, session userd.Session) error {
+		data, err := session.ExportDiagnostics(c)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		result = &rpc.DiagnosticsBundle{Data: data, Format: "zip"}
+		return nil
+	})
+	return result, err
+}
+
 func (s *service) GatherLogs(ctx context.Context, request *rpc.LogsRequest) (result *rpc.LogsResponse, err error) {
 	err = s.WithSession(ctx, "GatherLogs", func(c context.Context, session userd.Session) error {
 		result, err = session.GatherLogs(c, request)
@@ -541,6 +649,31 @@ func (s *service) GetAgentConfig(ctx context.Context, request *manager.AgentConf
 	return rsp, err
 }
 
+func (s *service) ActiveForwarders(ctx context.Context, _ *empty.Empty) (rsp *rpc.ActiveForwardersResponse, err error) {
+	err = s.WithSession(ctx, "ActiveForwarders", func(ctx context.Context, session userd.Session) error {
+		rsp = &rpc.ActiveForwardersResponse{Forwarders: session.ActiveForwarders(ctx)}
+		return nil
+	})
+	return rsp, err
+}
+
+func (s *service) CheckPermissions(ctx context.Context, cr *rpc.ConnectRequest) (report *rpc.PermissionsReport, err error) {
+	s.sessionLock.RLock()
+	connected := s.session != nil
+	s.sessionLock.RUnlock()
+	if connected {
+		err = s.WithSession(ctx, "CheckPermissions", func(ctx context.Context, session userd.Session) error {
+			report, err = session.CheckPermissions(ctx)
+			return err
+		})
+		return report, err
+	}
+	s.LogCall(ctx, "CheckPermissions", func(ctx context.Context) {
+		report, err = k8s.CheckPermissions(ctx, cr)
+	})
+	return report, err
+}
+
 func (s *service) GetClusterSubnets(ctx context.Context, _ *empty.Empty) (cs *rpc.ClusterSubnets, err error) {
 	podSubnets := []*manager.IPNet{}
 	svcSubnets := []*manager.IPNet{}
@@ -624,6 +757,152 @@ func (s *service) LeaveIngest(ctx context.Context, request *rpc.IngestIdentifier
 	return response, err
 }
 
+func (s *service) IngestsByWorkload(ctx context.Context, _ *empty.Empty) (response *rpc.IngestsByWorkloadResponse, err error) {
+	err = s.WithSession(ctx, "IngestsByWorkload", func(ctx context.Context, session userd.Session) error {
+		igMap := session.IngestsByWorkload(ctx)
+		response = &rpc.IngestsByWorkloadResponse{Workloads: make(map[string]*rpc.WorkloadIngests, len(igMap))}
+		for wl, igs := range igMap {
+			response.Workloads[wl] = &rpc.WorkloadIngests{Ingests: igs}
+		}
+		return nil
+	})
+	return response, err
+}
+
+func (s *service) ValidateInterceptSpec(ctx context.Context, ir *rpc.CreateInterceptRequest) (result *rpc.InterceptValidationResult, err error) {
+	err = s.WithSession(ctx, "ValidateInterceptSpec", func(ctx context.Context, session userd.Session) error {
+		result = &rpc.InterceptValidationResult{Errors: session.ValidateInterceptSpec(ctx, ir)}
+		return nil
+	})
+	return result, err
+}
+
+func (s *service) RecentIntercepts(ctx context.Context, _ *empty.Empty) (response *rpc.RecentInterceptsResponse, err error) {
+	err = s.WithSession(ctx, "RecentIntercepts", func(ctx context.Context, session userd.Session) error {
+		response = &rpc.RecentInterceptsResponse{Intercepts: session.RecentIntercepts(ctx)}
+		return nil
+	})
+	return response, err
+}
+
+func (s *service) WorkloadInterceptHistory(ctx context.Context, rq *rpc.WorkloadInterceptHistoryRequest) (response *rpc.WorkloadInterceptHistoryResponse, err error) {
+	err = s.WithSession(ctx, "WorkloadInterceptHistory", func(ctx context.Context, session userd.Session) error {
+		response, err = session.WorkloadInterceptHistory(ctx, rq)
+		return err
+	})
+	return response, err
+}
+
+func (s *service) ExportActiveConfig(ctx context.Context, _ *empty.Empty) (response *rpc.ActiveConfig, err error) {
+	err = s.WithSession(ctx, "ExportActiveConfig", func(ctx context.Context, session userd.Session) error {
+		response = session.ExportActiveConfig(ctx)
+		return nil
+	})
+	return response, err
+}
+
+func (s *service) ImportActiveConfig(ctx context.Context, cfg *rpc.ActiveConfig) (response *rpc.ImportActiveConfigResponse, err error) {
+	err = s.WithSession(ctx, "ImportActiveConfig", func(ctx context.Context, session userd.Session) error {
+		response = session.ImportActiveConfig(ctx, cfg)
+		return nil
+	})
+	return response, err
+}
+
+func (s *service) InterceptEnvironment(ctx context.Context, rq *rpc.InterceptEnvironmentRequest) (response *rpc.InterceptEnvironmentResponse, err error) {
+	err = s.WithSession(ctx, "InterceptEnvironment", func(ctx context.Context, session userd.Session) error {
+		data, err := session.InterceptEnvironment(ctx, rq.Id, rq.Syntax)
+		if err != nil {
+			return err
+		}
+		response = &rpc.InterceptEnvironmentResponse{Data: data}
+		return nil
+	})
+	return response, err
+}
+
+func (s *service) StreamAgentLogs(rq *rpc.AgentLogsRequest, stream rpc.Connector_StreamAgentLogsServer) error {
+	var sessionCtx context.Context
+	var session userd.Session
+
+	err := s.WithSession(stream.Context(), "StreamAgentLogs", func(c context.Context, s userd.Session) error {
+		session, sessionCtx = s, c
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return session.StreamAgentLogs(sessionCtx, rq, stream)
+}
+
+func (s *service) WatchSessionEvents(_ *empty.Empty, stream rpc.Connector_WatchSessionEventsServer) error {
+	var session userd.Session
+	err := s.WithSession(stream.Context(), "WatchSessionEvents", func(_ context.Context, s userd.Session) error {
+		session = s
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for ev := range session.Events(stream.Context()) {
+		if err = stream.Send(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) ConnectedClients(ctx context.Context, _ *empty.Empty) (result *manager.ConnectedClients, err error) {
+	err = s.WithSession(ctx, "ConnectedClients", func(ctx context.Context, session userd.Session) error {
+		ccs, err := session.ConnectedClients(ctx)
+		if err != nil {
+			return err
+		}
+		result = &manager.ConnectedClients{Clients: ccs}
+		return nil
+	})
+	return result, err
+}
+
+func (s *service) AgentEnv(ctx context.Context, _ *empty.Empty) (result *manager.AgentEnv, err error) {
+	err = s.WithSession(ctx, "AgentEnv", func(ctx context.Context, session userd.Session) error {
+		result, err = session.AgentEnv(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (s *service) RoutingTable(ctx context.Context, _ *empty.Empty) (result *daemon.RoutingTable, err error) {
+	err = s.WithSession(ctx, "RoutingTable", func(ctx context.Context, session userd.Session) error {
+		result, err = session.RootDaemon().GetRoutingTable(ctx, &empty.Empty{})
+		return err
+	})
+	return result, err
+}
+
+func (s *service) PruneSessions(ctx context.Context, _ *empty.Empty) (result *rpc.PruneSessionsResponse, err error) {
+	err = s.WithSession(ctx, "PruneSessions", func(ctx context.Context, session userd.Session) error {
+		result, err = session.PruneSessions(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (s *service) RestartWorkload(ctx context.Context, rq *rpc.RestartWorkloadRequest) (*empty.Empty, error) {
+	err := s.WithSession(ctx, "RestartWorkload", func(ctx context.Context, session userd.Session) error {
+		return session.RestartWorkload(ctx, rq.Namespace, rq.Kind, rq.Name)
+	})
+	return &empty.Empty{}, err
+}
+
+func (s *service) PreviewDNSDomains(ctx context.Context, cr *rpc.ConnectRequest) (*rpc.DNSDomainsPreview, error) {
+	var result *rpc.DNSDomainsPreview
+	s.LogCall(ctx, "PreviewDNSDomains", func(ctx context.Context) {
+		result = k8s.PreviewDNSDomains(ctx, cr)
+	})
+	return result, nil
+}
+
 func (s *service) withRootDaemon(ctx context.Context, f func(ctx context.Context, daemonClient daemon.DaemonClient) error) error {
 	if s.rootSessionInProc {
 		return status.Error(codes.Unavailable, "root daemon is embedded")
@@ -74,10 +74,21 @@ type service struct {
 	sessionQuitting int32 // atomic boolean. True if non-zero.
 	sessionLock     sync.RWMutex
 
+	// sessionEndReason tells why the last session ended when it wasn't ended by a disconnect. It's reported by
+	// Status until a new session is started.
+	sessionEndReason string
+
+	// replacedSession is a session that was cancelled by a connect request with on_existing RECONNECT. Its end
+	// doesn't quit the process when the simplified session management is in effect.
+	replacedSession userd.Session
+
 	// These are used to communicate between the various goroutines.
 	connectRequest  chan userd.ConnectRequest // server-grpc.connect() -> connectWorker
 	connectResponse chan *rpc.ConnectInfo     // connectWorker -> server-grpc.connect()
 
+	// The phase of the connect that is in progress, reported to WatchConnectProgress streams.
+	connectProgress connectProgress
+
 	fuseFtpMgr remotefs.FuseFTPManager
 
 	// Run root session in-process
@@ -226,6 +237,9 @@ func (s *service) ManageSessions(c context.Context) error {
 		case <-c.Done():
 			return nil
 		case cr := <-s.connectRequest:
+			if cr.Request().OnExisting == rpc.ConnectRequest_RECONNECT {
+				s.cancelSessionForReconnect(c)
+			}
 			rsp := s.startSession(c, cr, &wg)
 			select {
 			case s.connectResponse <- rsp:
@@ -243,6 +257,13 @@ func (s *service) startSession(parentCtx context.Context, cr userd.ConnectReques
 	defer s.sessionLock.Unlock()
 
 	if s.session != nil {
+		if cr.Request().OnExisting == rpc.ConnectRequest_ERROR {
+			return &rpc.ConnectInfo{
+				Error:         rpc.ConnectInfo_SESSION_EXISTS,
+				ErrorText:     "a session already exists; disconnect before connecting again",
+				ErrorCategory: int32(errcat.User),
+			}
+		}
 		// UpdateStatus sets rpc.ConnectInfo_ALREADY_CONNECTED if successful
 		return s.session.UpdateStatus(s.sessionContext, cr)
 	}
@@ -276,7 +297,8 @@ func (s *service) startSession(parentCtx context.Context, cr userd.ConnectReques
 	}
 	go runAliveAndCancellation(ctx, cancel, daemonID)
 
-	ctx, session, rsp := userd.GetNewSessionFunc(ctx)(ctx, cr, config)
+	ctx, session, rsp := userd.GetNewSessionFunc(ctx)(userd.WithConnectProgress(ctx, s.connectProgress.set), cr, config)
+	s.connectProgress.set(rpc.ConnectProgress_UNSPECIFIED)
 	if ctx.Err() != nil || rsp.Error != rpc.ConnectInfo_UNSPECIFIED {
 		cancel()
 		if s.rootSessionInProc {
@@ -286,6 +308,7 @@ func (s *service) startSession(parentCtx context.Context, cr userd.ConnectReques
 		return rsp
 	}
 	s.session = session
+	s.sessionEndReason = ""
 	s.sessionContext = userd.WithSession(ctx, session)
 	s.sessionCancel = func() {
 		cancel()
@@ -296,11 +319,16 @@ func (s *service) startSession(parentCtx context.Context, cr userd.ConnectReques
 	// the session is running. The s.sessionCancel is called from Disconnect
 	wg.Add(1)
 	go func(cr userd.ConnectRequest) {
+		var endReason string
 		defer func() {
 			s.sessionLock.Lock()
-			s.self.SetManagerClient(nil)
-			s.session = nil
-			s.sessionCancel = nil
+			if s.session == nil || s.session == session {
+				// Leave a session that has replaced this one alone.
+				s.self.SetManagerClient(nil)
+				s.session = nil
+				s.sessionCancel = nil
+				s.sessionEndReason = endReason
+			}
 			s.sessionLock.Unlock()
 			_ = client.ReloadDaemonLogLevel(parentCtx, false)
 			wg.Done()
@@ -316,10 +344,14 @@ func (s *service) startSession(parentCtx context.Context, cr userd.ConnectReques
 				}
 				return
 			}
-
-			dlog.Error(ctx, err)
+			if errors.Is(err, trafficmgr.ErrSessionMaxLifetime) {
+				dlog.Info(ctx, err)
+				endReason = trafficmgr.ErrSessionMaxLifetime.Error()
+			} else {
+				dlog.Error(ctx, err)
+			}
 		}
-		if s.rootSessionInProc {
+		if s.rootSessionInProc && !s.wasReplaced(session) {
 			// Simplified session management. The daemon handles one session, then exits.
 			s.quit()
 		}
@@ -359,6 +391,30 @@ func (s *service) cancelSessionReadLocked() {
 	}
 }
 
+// cancelSessionForReconnect cancels the current session, if any, so that a new session can be started in
+// its place.
+func (s *service) cancelSessionForReconnect(ctx context.Context) {
+	s.sessionLock.Lock()
+	session := s.session
+	s.replacedSession = session
+	s.sessionLock.Unlock()
+	if session != nil {
+		dlog.Info(ctx, "Cancelling the current session in order to reconnect")
+		s.cancelSession()
+	}
+}
+
+// wasReplaced returns true if the given session was cancelled in order to be replaced by a new one.
+func (s *service) wasReplaced(session userd.Session) bool {
+	s.sessionLock.Lock()
+	defer s.sessionLock.Unlock()
+	if s.replacedSession != session {
+		return false
+	}
+	s.replacedSession = nil
+	return true
+}
+
 func (s *service) cancelSession() {
 	if !atomic.CompareAndSwapInt32(&s.sessionQuitting, 0, 1) {
 		return
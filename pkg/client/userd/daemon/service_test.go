@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// existingSession is a session that reports that it's already connected, and records that it was cleared.
+type existingSession struct {
+	userd.Session
+	cleared bool
+}
+
+func (s *existingSession) UpdateStatus(context.Context, userd.ConnectRequest) *rpc.ConnectInfo {
+	return &rpc.ConnectInfo{Error: rpc.ConnectInfo_ALREADY_CONNECTED}
+}
+
+func (s *existingSession) ClearIngestsAndIntercepts(context.Context) error {
+	s.cleared = true
+	return nil
+}
+
+func newServiceWithSession(ctx context.Context) (*service, *existingSession, context.Context) {
+	session := &existingSession{}
+	sCtx, cancel := context.WithCancel(ctx)
+	s := &service{
+		session:        session,
+		sessionContext: sCtx,
+		sessionCancel:  cancel,
+	}
+	return s, session, sCtx
+}
+
+func Test_service_startSession_onExisting(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	wg := &sync.WaitGroup{}
+	connect := func(s *service, onExisting rpc.ConnectRequest_OnExisting) *rpc.ConnectInfo {
+		return s.startSession(ctx, crImpl{ConnectRequest: &rpc.ConnectRequest{OnExisting: onExisting}}, wg)
+	}
+
+	t.Run("reuse", func(t *testing.T) {
+		s, session, sCtx := newServiceWithSession(ctx)
+		ci := connect(s, rpc.ConnectRequest_REUSE)
+		assert.Equal(t, rpc.ConnectInfo_ALREADY_CONNECTED, ci.Error)
+		assert.Same(t, session, s.session)
+		assert.NoError(t, sCtx.Err())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s, session, sCtx := newServiceWithSession(ctx)
+		ci := connect(s, rpc.ConnectRequest_ERROR)
+		assert.Equal(t, rpc.ConnectInfo_SESSION_EXISTS, ci.Error)
+		assert.Equal(t, errcat.User, errcat.Category(ci.ErrorCategory))
+		assert.NotEmpty(t, ci.ErrorText)
+		assert.Same(t, session, s.session)
+		assert.NoError(t, sCtx.Err())
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		s, session, sCtx := newServiceWithSession(ctx)
+		s.cancelSessionForReconnect(ctx)
+		require.Nil(t, s.session)
+		assert.True(t, session.cleared)
+		assert.Error(t, sCtx.Err())
+
+		// The end of the replaced session is recognized once, so that it doesn't quit the process.
+		assert.True(t, s.wasReplaced(session))
+		assert.False(t, s.wasReplaced(session))
+
+		// Nothing is cancelled when there's no session.
+		s.cancelSessionForReconnect(ctx)
+		assert.Nil(t, s.replacedSession)
+	})
+}
@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"context"
+	"slices"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// DNSDomains returns the DNS search domains that a session posts to the root daemon when it maps the
+// given namespaces. The namespaces are sorted and deduplicated, and the service subdomain is appended
+// unless it's also the name of a namespace. An empty svcDomain means client.DefaultServiceSubdomain.
+func DNSDomains(namespaces []string, svcDomain string) []string {
+	if svcDomain == "" {
+		svcDomain = client.DefaultServiceSubdomain
+	}
+	domains := slices.Compact(slices.Sorted(slices.Values(namespaces)))
+	if !slices.Contains(domains, svcDomain) {
+		domains = append(domains, svcDomain)
+	}
+	return domains
+}
+
+// PreviewDNSDomains computes the DNS search domains that a session created from the given request would
+// start out with, without connecting to the cluster. The namespaces are the mapped namespaces of the request,
+// or of the client configuration when the request has none. Mapped namespaces that don't exist in the cluster
+// are dropped once the session is connected.
+func PreviewDNSDomains(ctx context.Context, cr *connector.ConnectRequest) *connector.DNSDomainsPreview {
+	cfg := client.GetConfig(ctx)
+	namespaces := cr.MappedNamespaces
+	if len(namespaces) == 1 && namespaces[0] == "all" {
+		namespaces = nil
+	} else if len(namespaces) == 0 {
+		namespaces = cfg.Cluster().MappedNamespaces
+	}
+	return &connector.DNSDomainsPreview{
+		Domains:       DNSDomains(namespaces, cfg.DNS().ServiceSubdomain),
+		AllNamespaces: len(namespaces) == 0,
+	}
+}
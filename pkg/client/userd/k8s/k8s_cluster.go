@@ -51,6 +51,12 @@ type Cluster struct {
 
 	// Namespace listener. Notified when the currentNamespaces changes
 	namespaceListeners []userd.NamespaceListener
+
+	// idLock protects managerInstallID
+	idLock sync.Mutex
+
+	// managerInstallID caches the ID returned by GetManagerInstallId. Empty until it has been found.
+	managerInstallID string
 }
 
 func (kc *Cluster) ActualNamespace(namespace string) string {
@@ -126,6 +132,19 @@ func (kc *Cluster) namespaceAccessible(namespace string) (exists bool) {
 }
 
 func NewCluster(c context.Context, kubeFlags *client.Kubeconfig, namespaces []string) (context.Context, *Cluster, error) {
+	cfg := client.GetConfig(c)
+	if cfg.Cluster().CABundle != "" {
+		// Trust the additional certificates without modifying the kubeconfig.
+		rs, err := cfg.Cluster().ApplyCABundle(kubeFlags.RestConfig)
+		if err != nil {
+			return c, nil, err
+		}
+		kf := *kubeFlags
+		kf.RestConfig = rs
+		kubeFlags = &kf
+		dlog.Debug(c, "using additional certificate authorities from cluster.caBundle")
+	}
+
 	rs := kubeFlags.RestConfig
 	cs, err := kubernetes.NewForConfig(rs)
 	if err != nil {
@@ -143,7 +162,6 @@ func NewCluster(c context.Context, kubeFlags *client.Kubeconfig, namespaces []st
 		ari:        acs,
 	}
 
-	cfg := client.GetConfig(c)
 	timedC, cancel := cfg.Timeouts().TimeoutContext(c, client.TimeoutClusterConnect)
 	defer cancel()
 	if err = ret.check(timedC); err != nil {
@@ -167,9 +185,12 @@ func NewCluster(c context.Context, kubeFlags *client.Kubeconfig, namespaces []st
 		ret.SetMappedNamespaces(c, namespaces)
 	}
 	if GetManagerNamespace(c) == "" {
-		tns, err := ret.determineTrafficManagerNamespace(c)
-		if err != nil {
-			return c, nil, err
+		tns := defaultManagerNamespace
+		if !cfg.Cluster().HasManagerCluster() {
+			// The traffic-manager of a separate manager cluster can't be searched for using this cluster.
+			if tns, err = ret.determineTrafficManagerNamespace(c); err != nil {
+				return c, nil, err
+			}
 		}
 		nc := client.GetDefaultConfig()
 		nc.Cluster().DefaultManagerNamespace = tns
@@ -235,15 +256,17 @@ func ConnectCluster(c context.Context, cr *rpc.ConnectRequest, config *client.Ku
 
 // determineTrafficManagerNamespace finds the namespace for the traffic-manager. It is determined by the following steps:
 //
-//  1. If a treffic-manager service is found in one of the currently accessible namespaces, return it.
+//  1. If a traffic-manager service, named by the cluster.managerService setting, is found in one of the currently
+//     accessible namespaces, return it.
 //  2. If the client has access to the default manager namespace, then return it.
 //  3. If the client has access to the default namespace, then return it.
 //  4. Return an error stating that it isn't possible to determine the namespace.
 func (kc *Cluster) determineTrafficManagerNamespace(c context.Context) (string, error) {
 	// Search for the traffic-manager in mapped namespaces
+	svcName := client.GetConfig(c).Cluster().ManagerService
 	nss := kc.GetCurrentNamespaces(true)
 	for _, ns := range nss {
-		if _, err := k8sapi.GetService(c, "traffic-manager", ns); err == nil {
+		if _, err := k8sapi.GetService(c, svcName, ns); err == nil {
 			return ns, nil
 		}
 	}
@@ -284,9 +307,27 @@ func (kc *Cluster) GetCurrentNamespaces(forClientAccess bool) []string {
 	return nss
 }
 
+// GetManagerInstallId returns the ID of the namespace of the traffic-manager. The ID is immutable for the
+// duration of a session, so it's only looked up until it has been found once.
 func (kc *Cluster) GetManagerInstallId(ctx context.Context) string {
-	managerID, _ := k8sapi.GetNamespaceID(ctx, GetManagerNamespace(ctx))
-	return managerID
+	kc.idLock.Lock()
+	defer kc.idLock.Unlock()
+	if kc.managerInstallID == "" {
+		managerID, err := k8sapi.GetNamespaceID(ctx, GetManagerNamespace(ctx))
+		if err != nil {
+			// The returned ID is still usable, but the lookup is retried the next time.
+			return managerID
+		}
+		kc.managerInstallID = managerID
+	}
+	return kc.managerInstallID
+}
+
+// InvalidateManagerInstallId ensures that the next call to GetManagerInstallId looks up the ID again.
+func (kc *Cluster) InvalidateManagerInstallId() {
+	kc.idLock.Lock()
+	kc.managerInstallID = ""
+	kc.idLock.Unlock()
 }
 
 func GetManagerNamespace(ctx context.Context) string {
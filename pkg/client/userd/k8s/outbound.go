@@ -3,6 +3,7 @@ package k8s
 import (
 	"context"
 	"math"
+	"slices"
 	"sort"
 	"time"
 
@@ -82,12 +83,13 @@ func (kc *Cluster) namespacesEventHandler(ctx context.Context, evCh <-chan watch
 }
 
 // canGetDefaultTrafficManagerService answers the question if this client has the RBAC permissions
-// necessary to get the traffic-manager in the default namespace.
+// necessary to get the traffic-manager service, named by the cluster.managerService setting, in the
+// default namespace.
 func canGetDefaultTrafficManagerService(ctx context.Context) bool {
 	ok, err := k8sclient.CanI(ctx, &auth.ResourceAttributes{
 		Verb:      "get",
 		Resource:  "services",
-		Name:      "traffic-manager",
+		Name:      client.GetConfig(ctx).Cluster().ManagerService,
 		Namespace: defaultManagerNamespace,
 	})
 	return err == nil && ok
@@ -170,12 +172,23 @@ func sortedStringSlicesEqual(as, bs []string) bool {
 
 func (kc *Cluster) SetMappedNamespaces(c context.Context, namespaces []string) bool {
 	sort.Strings(namespaces)
-	if !sortedStringSlicesEqual(namespaces, kc.MappedNamespaces) {
+	kc.nsLock.Lock()
+	changed := !sortedStringSlicesEqual(namespaces, kc.MappedNamespaces)
+	if changed {
 		kc.MappedNamespaces = namespaces
+	}
+	kc.nsLock.Unlock()
+	if changed {
 		kc.refreshNamespaces(c)
-		return true
 	}
-	return false
+	return changed
+}
+
+// GetMappedNamespaces returns a copy of the explicitly mapped namespaces.
+func (kc *Cluster) GetMappedNamespaces() []string {
+	kc.nsLock.Lock()
+	defer kc.nsLock.Unlock()
+	return slices.Clone(kc.MappedNamespaces)
 }
 
 func (kc *Cluster) AddNamespaceListener(c context.Context, nsListener userd.NamespaceListener) {
@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/client-go/kubernetes"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/k8sclient"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// CheckPermissions checks that the user has the permissions that a session needs in this cluster.
+func (kc *Cluster) CheckPermissions(ctx context.Context) (*rpc.PermissionsReport, error) {
+	kc.nsLock.Lock()
+	namespaces := kc.MappedNamespaces
+	if len(namespaces) == 0 && kc.namespaceWatcherSnapshot == nil {
+		// No permission to watch namespaces, so refreshNamespaces uses the namespace of the kubeconfig.
+		namespaces = []string{kc.Namespace}
+	}
+	kc.nsLock.Unlock()
+	return k8sclient.CheckPermissions(ctx, permissionsManagerNamespace(ctx), namespaces)
+}
+
+// CheckPermissions checks that the user has the permissions that a session created from the given
+// request needs, without connecting to the cluster.
+func CheckPermissions(ctx context.Context, cr *rpc.ConnectRequest) (*rpc.PermissionsReport, error) {
+	ctx, kubeFlags, err := client.DaemonKubeconfig(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(kubeFlags.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	ctx = k8sapi.WithK8sInterface(ctx, cs)
+
+	namespaces := cr.MappedNamespaces
+	if len(namespaces) == 1 && namespaces[0] == "all" {
+		namespaces = nil
+	} else if len(namespaces) == 0 {
+		namespaces = client.GetConfig(ctx).Cluster().MappedNamespaces
+	}
+	if len(namespaces) == 0 && !k8sclient.CanWatchNamespaces(ctx) {
+		namespaces = []string{kubeFlags.Namespace}
+	}
+	return k8sclient.CheckPermissions(ctx, permissionsManagerNamespace(ctx), namespaces)
+}
+
+// permissionsManagerNamespace returns the namespace of the traffic-manager, or an empty string when the
+// traffic-manager isn't in the workload cluster.
+func permissionsManagerNamespace(ctx context.Context) string {
+	cc := client.GetConfig(ctx).Cluster()
+	switch {
+	case cc.HasManagerCluster():
+		return ""
+	case cc.DefaultManagerNamespace != "":
+		return cc.DefaultManagerNamespace
+	default:
+		return defaultManagerNamespace
+	}
+}
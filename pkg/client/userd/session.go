@@ -2,6 +2,7 @@ package userd
 
 import (
 	"context"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"google.golang.org/grpc"
@@ -29,6 +30,11 @@ type WatchWorkloadsStream interface {
 	Context() context.Context
 }
 
+type AgentLogsStream interface {
+	Send(*rpc.AgentLogChunk) error
+	Context() context.Context
+}
+
 type InterceptInfo interface {
 	InterceptResult() *rpc.InterceptResult
 	PreparedIntercept() *manager.PreparedIntercept
@@ -47,7 +53,10 @@ type Session interface {
 	KubeConfig
 
 	AddIntercept(context.Context, *rpc.CreateInterceptRequest) *rpc.InterceptResult
+	AddInterceptGroup(context.Context, *rpc.CreateInterceptGroupRequest) (*rpc.InterceptGroupResult, error)
+	AddInterceptAndWait(context.Context, *rpc.CreateInterceptRequest, time.Duration) *rpc.InterceptResult
 	CanIntercept(context.Context, *rpc.CreateInterceptRequest) (InterceptInfo, *rpc.InterceptResult)
+	ValidateInterceptSpec(context.Context, *rpc.CreateInterceptRequest) []*rpc.InterceptValidationError
 	InterceptProlog(context.Context, *manager.CreateInterceptRequest) *rpc.InterceptResult
 	InterceptEpilog(context.Context, *rpc.CreateInterceptRequest, *rpc.InterceptResult) *rpc.InterceptResult
 	RemoveIntercept(context.Context, string) error
@@ -58,6 +67,7 @@ type Session interface {
 	ClearIngestsAndIntercepts(context.Context) error
 
 	GetInterceptInfo(string) *manager.InterceptInfo
+	InterceptEnvironment(ctx context.Context, id, syntax string) ([]byte, error)
 	GetInterceptSpec(string) *manager.InterceptSpec
 	InterceptsForWorkload(string, string) []*manager.InterceptSpec
 
@@ -66,14 +76,27 @@ type Session interface {
 	ManagerName() string
 	ManagerVersion() semver.Version
 	NewRemainRequest() *manager.RemainRequest
+	ConnectedClients(context.Context) ([]*manager.ConnectedClient, error)
+	AgentEnv(context.Context) (*manager.AgentEnv, error)
+	PruneSessions(context.Context) (*rpc.PruneSessionsResponse, error)
 
 	Status(context.Context) *rpc.ConnectInfo
 	UpdateStatus(context.Context, ConnectRequest) *rpc.ConnectInfo
 
 	Uninstall(context.Context, *rpc.UninstallRequest) (*common.Result, error)
+	RestartWorkload(ctx context.Context, namespace, kind, name string) error
+
+	RefreshIngressInfo(context.Context) ([]*manager.IngressInfo, error)
+	ResyncDNSDomains(context.Context) error
+	ActiveForwarders(context.Context) []*rpc.Forwarder
+	CheckPermissions(context.Context) (*rpc.PermissionsReport, error)
 
 	WatchWorkloads(context.Context, *rpc.WatchWorkloadsRequest, WatchWorkloadsStream) error
-	WorkloadInfoSnapshot(context.Context, []string, rpc.ListRequest_Filter) (*rpc.WorkloadInfoSnapshot, error)
+	WorkloadInfoSnapshot(context.Context, []string, rpc.ListRequest_Filter, []string, int, bool) (*rpc.WorkloadInfoSnapshot, error)
+	IsNamespaceWatched(namespace string) bool
+	NamespaceWatchError(namespace string) error
+	PauseWorkloadWatchFor(context.Context, time.Duration) (string, time.Duration)
+	ResumeWorkloadWatchPause(context.Context, string) bool
 
 	GetCurrentNamespaces(forClientAccess bool) []string
 	ActualNamespace(string) string
@@ -83,12 +106,15 @@ type Session interface {
 	ForeachAgentPod(ctx context.Context, fn func(context.Context, typed.PodInterface, *core.Pod), filter func(*core.Pod) bool) error
 
 	GatherLogs(context.Context, *connector.LogsRequest) (*connector.LogsResponse, error)
+	StreamAgentLogs(context.Context, *rpc.AgentLogsRequest, AgentLogsStream) error
 
 	SessionInfo() *manager.SessionInfo
 	RootDaemon() rootdRpc.DaemonClient
 
 	ApplyConfig(context.Context) error
+	ReapplyMappedNamespaces(context.Context) (added, removed []string, err error)
 	GetConfig(context.Context) (*client.SessionConfig, error)
+	ExportDiagnostics(context.Context) ([]byte, error)
 	RunSession(c context.Context) error
 	StartServices(g *dgroup.Group)
 	Remain(ctx context.Context) error
@@ -97,6 +123,15 @@ type Session interface {
 	Ingest(context.Context, *rpc.IngestRequest) (*rpc.IngestInfo, error)
 	GetIngest(*rpc.IngestIdentifier) (*rpc.IngestInfo, error)
 	LeaveIngest(context.Context, *rpc.IngestIdentifier) (*rpc.IngestInfo, error)
+	IngestsByWorkload(context.Context) map[string][]*rpc.IngestInfo
+	RecentIntercepts(context.Context) []*rpc.EndedIntercept
+	WorkloadInterceptHistory(context.Context, *rpc.WorkloadInterceptHistoryRequest) (*rpc.WorkloadInterceptHistoryResponse, error)
+	ExportActiveConfig(context.Context) *rpc.ActiveConfig
+	ImportActiveConfig(context.Context, *rpc.ActiveConfig) *rpc.ImportActiveConfigResponse
+
+	// Events returns a channel that receives the events of the session. The channel is closed when the
+	// context is done or after the session has ended.
+	Events(context.Context) <-chan *rpc.SessionEvent
 }
 
 type NewSessionFunc func(context.Context, ConnectRequest, *client.Kubeconfig) (context.Context, Session, *connector.ConnectInfo)
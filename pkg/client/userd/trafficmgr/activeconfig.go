@@ -0,0 +1,151 @@
+package trafficmgr
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// ExportActiveConfig returns the intercepts and ingests of the session in a form that can be passed to
+// ImportActiveConfig. The intercepts are sorted by name, and the ingests by workload and container.
+func (s *session) ExportActiveConfig(context.Context) *rpc.ActiveConfig {
+	cfg := &rpc.ActiveConfig{}
+	for _, ic := range s.getCurrentIntercepts() {
+		ic.Lock()
+		spec := proto.Clone(ic.Spec).(*manager.InterceptSpec)
+		ir := &rpc.CreateInterceptRequest{
+			Spec:           spec,
+			MountPoint:     ic.ClientMountPoint,
+			LocalMountPort: ic.localMountPort,
+			MountReadOnly:  ic.readOnly,
+			IdleTimeout:    durationpb.New(ic.idleTimeout),
+			DrainPeriod:    durationpb.New(time.Duration(spec.DrainPeriod)),
+		}
+		ic.Unlock()
+
+		// These are assigned by the session that creates the intercept.
+		spec.Client = ""
+		spec.ServiceUid = ""
+		spec.RoundtripLatency = 0
+		spec.DialTimeout = 0
+		spec.DrainPeriod = 0
+		cfg.Intercepts = append(cfg.Intercepts, ir)
+	}
+	slices.SortFunc(cfg.Intercepts, func(a, b *rpc.CreateInterceptRequest) int {
+		return cmp.Compare(a.Spec.Name, b.Spec.Name)
+	})
+
+	s.currentIngests.Range(func(key ingestKey, ig *ingest) bool {
+		cfg.Ingests = append(cfg.Ingests, &rpc.IngestRequest{
+			Identifier: &rpc.IngestIdentifier{
+				WorkloadName:  key.workload,
+				ContainerName: key.container,
+			},
+			MountPoint:     ig.localMountPoint,
+			LocalMountPort: ig.localMountPort,
+			LocalPorts:     slices.Clone(ig.localPorts),
+		})
+		return true
+	})
+	slices.SortFunc(cfg.Ingests, func(a, b *rpc.IngestRequest) int {
+		return cmp.Or(
+			cmp.Compare(a.Identifier.WorkloadName, b.Identifier.WorkloadName),
+			cmp.Compare(a.Identifier.ContainerName, b.Identifier.ContainerName))
+	})
+	return cfg
+}
+
+// ImportActiveConfig recreates the intercepts and the ingests of the given config, and returns the result of
+// each one of them, in the order of the config. An intercept or ingest that fails doesn't prevent that the
+// others are recreated. The given config isn't modified.
+func (s *session) ImportActiveConfig(ctx context.Context, cfg *rpc.ActiveConfig) *rpc.ImportActiveConfigResponse {
+	rsp := &rpc.ImportActiveConfigResponse{}
+	addResult := func(r *rpc.ActiveConfigResult, err error) {
+		if err != nil {
+			r.Error = err.Error()
+			r.ErrorCategory = int32(errcat.GetCategory(err))
+		}
+		rsp.Results = append(rsp.Results, r)
+	}
+
+	for _, ir := range cfg.Intercepts {
+		ir = proto.Clone(ir).(*rpc.CreateInterceptRequest)
+		spec := ir.Spec
+		if spec == nil {
+			addResult(&rpc.ActiveConfigResult{}, errcat.User.New("intercept has no spec"))
+			continue
+		}
+		r := &rpc.ActiveConfigResult{Item: &rpc.ActiveConfigResult_Intercept{Intercept: spec.Name}}
+		ns := spec.Namespace
+		if ns == "" {
+			ns = s.Namespace
+		}
+		if err := s.ensureWorkloadExists(ctx, ns, spec.Agent); err != nil {
+			addResult(r, err)
+			continue
+		}
+		addResult(r, interceptResultError(s.self.AddIntercept(ctx, ir)))
+	}
+
+	for _, ir := range cfg.Ingests {
+		ir = proto.Clone(ir).(*rpc.IngestRequest)
+		id := ir.Identifier
+		if id == nil {
+			addResult(&rpc.ActiveConfigResult{}, errcat.User.New("ingest has no identifier"))
+			continue
+		}
+		r := &rpc.ActiveConfigResult{Item: &rpc.ActiveConfigResult_Ingest{Ingest: id}}
+		if err := s.ensureWorkloadExists(ctx, s.Namespace, id.WorkloadName); err != nil {
+			addResult(r, err)
+			continue
+		}
+		_, err := s.self.Ingest(ctx, ir)
+		addResult(r, err)
+	}
+	dlog.Debugf(ctx, "Imported %d intercepts and %d ingests", len(cfg.Intercepts), len(cfg.Ingests))
+	return rsp
+}
+
+// interceptResultError returns the error of the given result of AddIntercept, or nil if the intercept was created.
+func interceptResultError(result *rpc.InterceptResult) error {
+	if result.InterceptInfo != nil && result.Error == 0 {
+		return nil
+	}
+	msg := result.ErrorText
+	if msg == "" {
+		msg = result.Error.String()
+	}
+	return errcat.Category(result.ErrorCategory).New(msg)
+}
+
+// ensureWorkloadExists returns an error if the workload watcher of the given namespace has synced and has no workload
+// with the given name. Nothing is returned when the workload isn't watched, because whether it exists can then
+// only be determined by intercepting or ingesting it.
+func (s *session) ensureWorkloadExists(ctx context.Context, namespace, name string) error {
+	if !s.watchesWorkload(name) {
+		return nil
+	}
+	s.ensureWatchers(ctx, []string{namespace})
+	if !s.IsNamespaceWatched(namespace) {
+		return nil
+	}
+	found := false
+	s.eachWorkload([]string{namespace}, func(key workloadInfoKey, _ workloadInfo) {
+		if key.name == name {
+			found = true
+		}
+	})
+	if !found {
+		return errcat.User.Newf("workload %s.%s no longer exists", name, namespace)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// importingSession records the intercepts and ingests that it's asked to create.
+type importingSession struct {
+	userd.Session
+	intercepts []*rpc.CreateInterceptRequest
+	ingests    []*rpc.IngestRequest
+}
+
+func (f *importingSession) AddIntercept(_ context.Context, ir *rpc.CreateInterceptRequest) *rpc.InterceptResult {
+	if ir.Spec.Name == "echo-fail" {
+		return InterceptError(common.InterceptError_FAILED_TO_ESTABLISH, errcat.User.New("no agent"))
+	}
+	f.intercepts = append(f.intercepts, ir)
+	return &rpc.InterceptResult{InterceptInfo: &manager.InterceptInfo{Id: ir.Spec.Name, Spec: ir.Spec}}
+}
+
+func (f *importingSession) Ingest(_ context.Context, ir *rpc.IngestRequest) (*rpc.IngestInfo, error) {
+	if ir.Identifier.ContainerName == "fail" {
+		return nil, errors.New("no such container")
+	}
+	f.ingests = append(f.ingests, ir)
+	return &rpc.IngestInfo{Workload: ir.Identifier.WorkloadName, Container: ir.Identifier.ContainerName}, nil
+}
+
+func Test_session_ExportActiveConfig_roundTrip(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+
+	// The session that exports its intercepts and ingests.
+	spec := &manager.InterceptSpec{
+		Name:             "echo-http",
+		Client:           "alice@laptop",
+		Agent:            "echo",
+		Namespace:        "default",
+		Mechanism:        "tcp",
+		TargetHost:       "127.0.0.1",
+		TargetPort:       8080,
+		PortIdentifier:   "http",
+		ServiceUid:       "abc",
+		RoundtripLatency: int64(time.Second),
+		DialTimeout:      int64(time.Second),
+		DrainPeriod:      int64(5 * time.Second),
+	}
+	src := &session{
+		currentIntercepts: map[string]*intercept{
+			"abc:echo-http": {
+				InterceptInfo:  &manager.InterceptInfo{Id: "abc:echo-http", Spec: spec, ClientMountPoint: "/tmp/echo"},
+				localMountPort: 2222,
+				readOnly:       true,
+				idleTimeout:    time.Hour,
+			},
+		},
+		currentIngests: xsync.NewMapOf[ingestKey, *ingest](),
+	}
+	src.currentIngests.Store(ingestKey{workload: "echo", container: "sidecar"}, &ingest{
+		ingestKey:       ingestKey{workload: "echo", container: "sidecar"},
+		localMountPoint: "/tmp/sidecar",
+		localPorts:      []string{"9090/TCP"},
+	})
+	src.currentIngests.Store(ingestKey{workload: "echo", container: "echo-server"}, &ingest{
+		ingestKey:      ingestKey{workload: "echo", container: "echo-server"},
+		localMountPort: 2223,
+	})
+
+	exported := src.ExportActiveConfig(ctx)
+	require.Len(t, exported.Intercepts, 1)
+	ir := exported.Intercepts[0]
+	assert.Empty(t, ir.Spec.Client, "the client is assigned by the importing session")
+	assert.Empty(t, ir.Spec.ServiceUid)
+	assert.Equal(t, "/tmp/echo", ir.MountPoint)
+	assert.Equal(t, int32(2222), ir.LocalMountPort)
+	assert.True(t, ir.MountReadOnly)
+	assert.Equal(t, time.Hour, ir.IdleTimeout.AsDuration())
+	assert.Equal(t, 5*time.Second, ir.DrainPeriod.AsDuration())
+	require.Len(t, exported.Ingests, 2)
+	assert.Equal(t, "echo-server", exported.Ingests[0].Identifier.ContainerName)
+	assert.Equal(t, "sidecar", exported.Ingests[1].Identifier.ContainerName)
+
+	// The exported config survives serialization.
+	data, err := protojson.Marshal(exported)
+	require.NoError(t, err)
+	doc := &rpc.ActiveConfig{}
+	require.NoError(t, protojson.Unmarshal(data, doc))
+	assert.Empty(t, cmpProto(exported, doc))
+
+	// The session that imports them.
+	f := &importingSession{}
+	dst := &session{
+		Cluster:          &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "default"}},
+		syncedNamespaces: map[string]struct{}{"default": {}},
+		workloads: map[workloadInfoKey]workloadInfo{
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "echo"}: {},
+		},
+	}
+	dst.self = f
+	rsp := dst.ImportActiveConfig(ctx, doc)
+	require.Len(t, rsp.Results, 3)
+	for _, r := range rsp.Results {
+		assert.Empty(t, r.Error)
+	}
+	assert.Equal(t, "echo-http", rsp.Results[0].GetIntercept())
+	assert.Equal(t, "echo-server", rsp.Results[1].GetIngest().ContainerName)
+	require.Len(t, f.intercepts, 1)
+	assert.Empty(t, cmpProto(ir, f.intercepts[0]))
+	assert.Empty(t, cmpProto(exported.Ingests[0], f.ingests[0]))
+	assert.Empty(t, cmpProto(exported.Ingests[1], f.ingests[1]))
+
+	// The imported config isn't modified.
+	assert.Empty(t, cmpProto(exported, doc))
+}
+
+func Test_session_ImportActiveConfig_failures(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	f := &importingSession{}
+	s := &session{
+		Cluster:          &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "default"}},
+		syncedNamespaces: map[string]struct{}{"default": {}},
+		workloads: map[workloadInfoKey]workloadInfo{
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "echo"}: {},
+		},
+	}
+	s.self = f
+	icept := func(name, agent string) *rpc.CreateInterceptRequest {
+		return &rpc.CreateInterceptRequest{
+			Spec:        &manager.InterceptSpec{Name: name, Agent: agent, Namespace: "default"},
+			IdleTimeout: durationpb.New(0),
+		}
+	}
+	rsp := s.ImportActiveConfig(ctx, &rpc.ActiveConfig{
+		Intercepts: []*rpc.CreateInterceptRequest{
+			icept("gone-http", "gone"),
+			icept("echo-fail", "echo"),
+			icept("echo-http", "echo"),
+			{},
+		},
+		Ingests: []*rpc.IngestRequest{
+			{Identifier: &rpc.IngestIdentifier{WorkloadName: "gone"}},
+			{Identifier: &rpc.IngestIdentifier{WorkloadName: "echo", ContainerName: "fail"}},
+			{Identifier: &rpc.IngestIdentifier{WorkloadName: "echo"}},
+		},
+	})
+
+	// Each item is reported, and one that fails doesn't prevent that the others are created.
+	require.Len(t, rsp.Results, 7)
+	errs := make([]string, len(rsp.Results))
+	for i, r := range rsp.Results {
+		errs[i] = r.Error
+	}
+	assert.Equal(t, []string{
+		"workload gone.default no longer exists",
+		"no agent",
+		"",
+		"intercept has no spec",
+		"workload gone.default no longer exists",
+		"no such container",
+		"",
+	}, errs)
+	assert.Equal(t, int32(errcat.User), rsp.Results[0].ErrorCategory)
+	assert.Equal(t, "gone-http", rsp.Results[0].GetIntercept())
+	assert.Equal(t, "gone", rsp.Results[4].GetIngest().WorkloadName)
+	require.Len(t, f.intercepts, 1)
+	assert.Equal(t, "echo-http", f.intercepts[0].Spec.Name)
+	require.Len(t, f.ingests, 1)
+	assert.Equal(t, "echo", f.ingests[0].Identifier.WorkloadName)
+}
+
+// cmpProto returns a description of the differences between the given messages, or an empty string if
+// they're equal.
+func cmpProto(a, b proto.Message) string {
+	if proto.Equal(a, b) {
+		return ""
+	}
+	return protojson.Format(a) + " != " + protojson.Format(b)
+}
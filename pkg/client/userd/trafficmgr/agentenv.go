@@ -0,0 +1,25 @@
+package trafficmgr
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// AgentEnv returns the settings that the traffic-manager applies to the environment of intercepted containers,
+// e.g. the names of the environment variables that are excluded from the environment of an intercept. A user
+// error is returned when the traffic-manager is too old to report them.
+func (s *session) AgentEnv(ctx context.Context) (*manager.AgentEnv, error) {
+	ae, err := s.ManagerClient().GetAgentEnv(ctx, s.SessionInfo())
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, errcat.User.Newf("traffic-manager version %s doesn't report its agent environment settings", s.ManagerVersion())
+		}
+		return nil, err
+	}
+	return ae, nil
+}
@@ -0,0 +1,54 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// agentEnvManager is a fakeManager that reports the given agent environment settings, or returns the given error.
+type agentEnvManager struct {
+	fakeManager
+	agentEnv *manager.AgentEnv
+	err      error
+}
+
+func (m *agentEnvManager) GetAgentEnv(context.Context, *manager.SessionInfo, ...grpc.CallOption) (*manager.AgentEnv, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.agentEnv, nil
+}
+
+func Test_session_AgentEnv(t *testing.T) {
+	mgr := &agentEnvManager{agentEnv: &manager.AgentEnv{
+		Excluded: []string{"DATABASE_PASSWORD", "AWS_SECRET_ACCESS_KEY", "KUBERNETES_SERVICE_HOST"},
+	}}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+	s.managerVersion = semver.MustParse("2.22.0")
+
+	ae, err := s.AgentEnv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DATABASE_PASSWORD", "AWS_SECRET_ACCESS_KEY", "KUBERNETES_SERVICE_HOST"}, ae.Excluded)
+
+	// An older traffic-manager that doesn't report its settings is a user error.
+	mgr.err = status.Error(codes.Unimplemented, "")
+	_, err = s.AgentEnv(ctx)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Contains(t, err.Error(), "2.22.0")
+
+	mgr.err = status.Error(codes.Unavailable, "connection lost")
+	_, err = s.AgentEnv(ctx)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
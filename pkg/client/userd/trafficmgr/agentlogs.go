@@ -0,0 +1,188 @@
+package trafficmgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// agentLogsPollInterval is how often the agents of a workload are checked for pods to re-attach to when their
+// logs are followed.
+const agentLogsPollInterval = 2 * time.Second
+
+// podLogOpener opens the log stream of a container in a pod.
+type podLogOpener func(ctx context.Context, namespace, pod string, opts *core.PodLogOptions) (io.ReadCloser, error)
+
+func openPodLog(ctx context.Context, namespace, pod string, opts *core.PodLogOptions) (io.ReadCloser, error) {
+	return k8sapi.GetK8sInterface(ctx).CoreV1().Pods(namespace).GetLogs(pod, opts).Stream(ctx)
+}
+
+// StreamAgentLogs streams the logs of the traffic-agent containers of the given workload to the stream. Each
+// chunk carries the name of the pod that it was read from. The logs of the pods are streamed one after another
+// unless they are followed. When they are followed, the agents of the workload are checked periodically, and
+// the stream is re-attached to the agents that are restarted or replaced.
+func (s *session) StreamAgentLogs(ctx context.Context, rq *rpc.AgentLogsRequest, stream userd.AgentLogsStream) error {
+	return s.streamAgentLogs(ctx, rq, stream, openPodLog)
+}
+
+// agentPods returns the names of the pods of the current agents of the given workload.
+func (s *session) agentPods(namespace, workload string) []string {
+	var pods []string
+	for _, ai := range s.getCurrentAgents() {
+		if ai.Namespace == namespace && ai.Name == workload {
+			pods = append(pods, ai.PodName)
+		}
+	}
+	return pods
+}
+
+func (s *session) streamAgentLogs(ctx context.Context, rq *rpc.AgentLogsRequest, stream userd.AgentLogsStream, open podLogOpener) error {
+	ns, wl := rq.Namespace, rq.Workload
+	pods := s.agentPods(ns, wl)
+	if len(pods) == 0 {
+		return errcat.User.Newf("no traffic-agent found for %s.%s", wl, ns)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(stream.Context(), cancel)()
+
+	var sendLock sync.Mutex
+	var sendErr error
+	send := func(pod string, data []byte) error {
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		if sendErr == nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if sendErr = stream.Send(&rpc.AgentLogChunk{Pod: pod, Data: data}); sendErr != nil {
+				cancel()
+			}
+		}
+		return sendErr
+	}
+	logOptions := func(since *meta.Time) *core.PodLogOptions {
+		opts := &core.PodLogOptions{Container: agentconfig.ContainerName, Follow: rq.Follow, TailLines: rq.TailLines}
+		if since != nil {
+			opts.SinceTime = since
+			opts.TailLines = nil
+		}
+		return opts
+	}
+
+	if !rq.Follow {
+		for _, pod := range pods {
+			rc, err := open(ctx, ns, pod, logOptions(nil))
+			if err != nil {
+				return err
+			}
+			if err = copyPodLog(rc, pod, send); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type logEnd struct {
+		pod string
+		at  *meta.Time // nil when the log could not be opened
+	}
+	clock := client.GetClock(ctx)
+	ends := make(chan logEnd)
+	attached := make(map[string]bool)
+	lastEnd := make(map[string]meta.Time)
+	wg := sync.WaitGroup{}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	attach := func(pod string) {
+		var since *meta.Time
+		if at, ok := lastEnd[pod]; ok {
+			// The agent has been streamed before, so only what it logged after that is of interest.
+			since = &at
+		}
+		opts := logOptions(since)
+		attached[pod] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			end := logEnd{pod: pod}
+			rc, err := open(ctx, ns, pod, opts)
+			if err == nil {
+				err = copyPodLog(rc, pod, send)
+				at := meta.NewTime(clock.Now())
+				end.at = &at
+			}
+			if err != nil && ctx.Err() == nil {
+				dlog.Debugf(ctx, "log stream of traffic-agent in %s.%s ended: %v", pod, ns, err)
+			}
+			select {
+			case ends <- end:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	for _, pod := range pods {
+		attach(pod)
+	}
+
+	ticker := clock.NewTicker(agentLogsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			sendLock.Lock()
+			err := sendErr
+			sendLock.Unlock()
+			return err
+		case end := <-ends:
+			delete(attached, end.pod)
+			if end.at != nil {
+				lastEnd[end.pod] = *end.at
+			}
+		case <-ticker.C():
+			for _, pod := range s.agentPods(ns, wl) {
+				if !attached[pod] {
+					attach(pod)
+				}
+			}
+		}
+	}
+}
+
+// copyPodLog sends what's read from the given log stream until it ends, and then closes it.
+func copyPodLog(rc io.ReadCloser, pod string, send func(string, []byte) error) error {
+	defer rc.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := rc.Read(buf)
+		if n > 0 {
+			if sendErr := send(pod, bytes.Clone(buf[:n])); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
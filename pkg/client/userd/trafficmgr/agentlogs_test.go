@@ -0,0 +1,134 @@
+package trafficmgr
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// fakePodLogs is a podLogOpener that returns a log stream with the content "<pod> log" and records the options
+// that each pod log was opened with.
+type fakePodLogs struct {
+	sync.Mutex
+	opened map[string][]*core.PodLogOptions
+}
+
+func (f *fakePodLogs) open(_ context.Context, _, pod string, opts *core.PodLogOptions) (io.ReadCloser, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.opened == nil {
+		f.opened = make(map[string][]*core.PodLogOptions)
+	}
+	f.opened[pod] = append(f.opened[pod], opts)
+	return io.NopCloser(strings.NewReader(pod + " log")), nil
+}
+
+func (f *fakePodLogs) openedOptions(pod string) []*core.PodLogOptions {
+	f.Lock()
+	defer f.Unlock()
+	return append([]*core.PodLogOptions(nil), f.opened[pod]...)
+}
+
+type fakeAgentLogsStream struct {
+	sync.Mutex
+	ctx    context.Context
+	chunks []*rpc.AgentLogChunk
+}
+
+func (f *fakeAgentLogsStream) Send(chunk *rpc.AgentLogChunk) error {
+	f.Lock()
+	f.chunks = append(f.chunks, chunk)
+	f.Unlock()
+	return nil
+}
+
+func (f *fakeAgentLogsStream) Context() context.Context {
+	return f.ctx
+}
+
+func agentInfo(workload, pod string) *manager.AgentInfo {
+	return &manager.AgentInfo{Name: workload, Namespace: "default", PodName: pod}
+}
+
+func Test_session_StreamAgentLogs(t *testing.T) {
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	s.setCurrentAgents([]*manager.AgentInfo{agentInfo("echo", "echo-1"), agentInfo("other", "other-1"), agentInfo("echo", "echo-2")})
+	tail := int64(10)
+
+	logs := &fakePodLogs{}
+	stream := &fakeAgentLogsStream{ctx: ctx}
+	rq := &rpc.AgentLogsRequest{Namespace: "default", Workload: "echo", TailLines: &tail}
+	require.NoError(t, s.streamAgentLogs(ctx, rq, stream, logs.open))
+	assert.Equal(t, []*rpc.AgentLogChunk{
+		{Pod: "echo-1", Data: []byte("echo-1 log")},
+		{Pod: "echo-2", Data: []byte("echo-2 log")},
+	}, stream.chunks)
+	assert.Equal(t, []*core.PodLogOptions{{Container: agentconfig.ContainerName, TailLines: &tail}}, logs.openedOptions("echo-1"))
+	assert.Empty(t, logs.openedOptions("other-1"))
+
+	rq.Workload = "missing"
+	err := s.streamAgentLogs(ctx, rq, stream, logs.open)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+}
+
+func Test_session_StreamAgentLogs_follow(t *testing.T) {
+	ctx, s, fc := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.setCurrentAgents([]*manager.AgentInfo{agentInfo("echo", "echo-1")})
+	tail := int64(10)
+
+	logs := &fakePodLogs{}
+	stream := &fakeAgentLogsStream{ctx: ctx}
+	rq := &rpc.AgentLogsRequest{Namespace: "default", Workload: "echo", Follow: true, TailLines: &tail}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.streamAgentLogs(ctx, rq, stream, logs.open)
+	}()
+
+	// The log of echo-1 ends, so the stream re-attaches to it with the time that it ended.
+	require.Eventually(t, func() bool {
+		fc.Step(agentLogsPollInterval)
+		return len(logs.openedOptions("echo-1")) > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	opts := logs.openedOptions("echo-1")
+	assert.True(t, opts[0].Follow)
+	assert.Equal(t, &tail, opts[0].TailLines)
+	assert.Nil(t, opts[0].SinceTime)
+	assert.Nil(t, opts[1].TailLines)
+	assert.NotNil(t, opts[1].SinceTime)
+
+	// An agent in a new pod is attached to from the start of its tail.
+	s.setCurrentAgents([]*manager.AgentInfo{agentInfo("echo", "echo-1"), agentInfo("echo", "echo-2")})
+	require.Eventually(t, func() bool {
+		fc.Step(agentLogsPollInterval)
+		return len(logs.openedOptions("echo-2")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	opts = logs.openedOptions("echo-2")
+	assert.Equal(t, &tail, opts[0].TailLines)
+	assert.Nil(t, opts[0].SinceTime)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamAgentLogs didn't return when its context was cancelled")
+	}
+	stream.Lock()
+	defer stream.Unlock()
+	assert.Contains(t, stream.chunks, &rpc.AgentLogChunk{Pod: "echo-2", Data: []byte("echo-2 log")})
+}
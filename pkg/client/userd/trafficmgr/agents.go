@@ -11,14 +11,34 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
 )
 
+// watchAgentsLoop watches the agents of the traffic-manager. The agents are watched again when the connection
+// to the traffic-manager is lost and could be reestablished.
 func (s *session) watchAgentsLoop(ctx context.Context) error {
-	stream, err := s.managerClient.WatchAgents(ctx, s.SessionInfo())
+	for {
+		mc := s.ManagerClient()
+		err := s.watchAgents(ctx, mc)
+		if err == nil || !managerUnavailable(err) {
+			return err
+		}
+		if rerr := s.reconnectManager(ctx, mc); rerr != nil {
+			s.handleAgentSnapshot(ctx, nil)
+			return fmt.Errorf("%w: %w", err, rerr)
+		}
+	}
+}
+
+func (s *session) watchAgents(ctx context.Context, mc manager.ManagerClient) error {
+	stream, err := mc.WatchAgents(ctx, s.SessionInfo())
 	if err != nil {
 		return fmt.Errorf("manager.WatchAgents: %w", err)
 	}
 	for ctx.Err() == nil {
 		snapshot, err := stream.Recv()
 		if err != nil {
+			if ctx.Err() == nil && managerUnavailable(err) {
+				// Retain the current agents, so that the ingests survive a reconnect.
+				return fmt.Errorf("manager.WatchAgents recv: %w", err)
+			}
 			// Handle as if we had an empty snapshot. This will ensure that port forwards and volume mounts are canceled correctly.
 			s.handleAgentSnapshot(ctx, nil)
 			if ctx.Err() != nil || errors.Is(err, io.EOF) {
@@ -28,6 +48,7 @@ func (s *session) watchAgentsLoop(ctx context.Context) error {
 			return fmt.Errorf("manager.WatchAgents recv: %w", err)
 		}
 		s.handleAgentSnapshot(ctx, snapshot.Agents)
+		s.serviceGate.setReady("agents")
 	}
 	return nil
 }
@@ -81,3 +102,17 @@ func (s *session) setCurrentAgents(agents []*manager.AgentInfo) {
 	s.currentAgents = agents
 	s.currentInterceptsLock.Unlock()
 }
+
+// getAgentVersions returns the versions of the agents in the given namespace, keyed by workload name. The
+// versions are those of the agents watcher, which only covers the namespace of the traffic-manager session,
+// so no call to the traffic-manager is made.
+func (s *session) getAgentVersions(namespace string) map[string]string {
+	cas := s.getCurrentAgents()
+	vm := make(map[string]string, len(cas))
+	for _, a := range cas {
+		if a.Namespace == namespace {
+			vm[a.Name] = a.Version
+		}
+	}
+	return vm
+}
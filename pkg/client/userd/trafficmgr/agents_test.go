@@ -0,0 +1,86 @@
+package trafficmgr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func Test_session_getAgentVersions(t *testing.T) {
+	s := &session{}
+	s.setCurrentAgents([]*manager.AgentInfo{
+		{Name: "echo", Namespace: "a", Version: "2.22.0", PodName: "echo-1"},
+		{Name: "db", Namespace: "a", Version: "2.21.3", PodName: "db-0"},
+		{Name: "web", Namespace: "b", Version: "2.22.0", PodName: "web-1"},
+	})
+	assert.Equal(t, map[string]string{"echo": "2.22.0", "db": "2.21.3"}, s.getAgentVersions("a"))
+	assert.Empty(t, s.getAgentVersions("c"))
+}
+
+func benchmarkAgents(n int) []*manager.AgentInfo {
+	agents := make([]*manager.AgentInfo, n)
+	for i := range n {
+		name := fmt.Sprintf("workload-%d", i)
+		agents[i] = &manager.AgentInfo{
+			Name:      name,
+			Namespace: "default",
+			PodName:   name + "-5d8f7c9b4-zz9kq",
+			PodIp:     "10.1.2.3",
+			Product:   "telepresence",
+			Version:   "2.22.0",
+			Mechanisms: []*manager.AgentInfo_Mechanism{
+				{Name: "tcp", Product: "telepresence", Version: "2.22.0"},
+			},
+			Containers: map[string]*manager.AgentInfo_ContainerInfo{
+				"app": {
+					MountPoint: "/tel_app_mounts/app",
+					Environment: map[string]string{
+						"HOME":                 "/root",
+						"PATH":                 "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+						"KUBERNETES_SERVICE":   "10.96.0.1",
+						"TELEPRESENCE_API_URL": "http://localhost:9980",
+					},
+				},
+			},
+		}
+	}
+	return agents
+}
+
+// BenchmarkAgentPayload compares the size of the complete agents snapshot with the size of the agent versions
+// that WorkloadInfoSnapshot needs.
+func BenchmarkAgentPayload(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		agents := benchmarkAgents(n)
+		b.Run(fmt.Sprintf("full-%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for range b.N {
+				data, _ := proto.Marshal(&manager.AgentInfoSnapshot{Agents: agents})
+				var snapshot manager.AgentInfoSnapshot
+				_ = proto.Unmarshal(data, &snapshot)
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "bytes/payload")
+		})
+		b.Run(fmt.Sprintf("versions-%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for range b.N {
+				avs := make([]*manager.AgentVersion, len(agents))
+				for i, a := range agents {
+					avs[i] = &manager.AgentVersion{Name: a.Name, Namespace: a.Namespace, Version: a.Version}
+				}
+				data, _ := proto.Marshal(&manager.AgentVersions{Agents: avs})
+				var versions manager.AgentVersions
+				_ = proto.Unmarshal(data, &versions)
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "bytes/payload")
+		})
+	}
+}
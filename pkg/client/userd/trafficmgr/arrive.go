@@ -0,0 +1,126 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// arrivalPrecondition is a known reason for the traffic-manager to refuse a client using a FailedPrecondition
+// status. The message matches the one that the traffic-manager's ArriveAsClient returns. The reason is reported
+// to scout instead of the message, because the message may contain names of namespaces and other details of the
+// cluster.
+type arrivalPrecondition struct {
+	reason  string
+	message *regexp.Regexp
+	hint    string
+}
+
+//nolint:gochecknoglobals // constant
+var arrivalPreconditions = []arrivalPrecondition{
+	{
+		reason:  "namespace_not_managed",
+		message: regexp.MustCompile(`namespace \S+ is not managed`),
+		hint: "Connect to a namespace that the traffic-manager manages using --namespace, or ask your administrator " +
+			"to add the namespace to the namespaces that the traffic-manager manages.",
+	},
+}
+
+// classifyArrivalPrecondition returns the known reason and the remediation hint for the given message of a
+// FailedPrecondition status that the traffic-manager returned from ArriveAsClient. The reason is "unknown",
+// and the hint is empty, when the message isn't recognized.
+func classifyArrivalPrecondition(msg string) (reason, hint string) {
+	for _, ap := range arrivalPreconditions {
+		if ap.message.MatchString(msg) {
+			return ap.reason, ap.hint
+		}
+	}
+	return "unknown", ""
+}
+
+// arrivalError returns the error to report when ArriveAsClient fails, or nil if the given error doesn't
+// stem from a FailedPrecondition status. A FailedPrecondition is reported to scout using its reason, and
+// is converted into a user error that contains a remediation hint when the reason is known.
+func arrivalError(ctx context.Context, err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return nil
+	}
+	reason, hint := classifyArrivalPrecondition(st.Message())
+	scout.Report(ctx, "arrive_as_client_precondition_failed", scout.Entry{Key: "reason", Value: reason})
+	if hint == "" {
+		return errcat.User.New(st.Message())
+	}
+	return errcat.User.Newf("%s. %s", st.Message(), hint)
+}
+
+// arrive returns the session of the client that the traffic-manager knows the daemon by. The session that is
+// cached for the daemon is reused when the traffic-manager still knows it, unless forceNew is true or the
+// session was created with other metadata than the given client info has, in which case the client departs
+// from it. A new session is otherwise obtained using ArriveAsClient and cached.
+func arrive(
+	ctx context.Context,
+	mClient manager.ManagerClient,
+	daemonID *daemon.Identifier,
+	managerNamespace string,
+	ci *manager.ClientInfo,
+	forceNew bool,
+) (*manager.SessionInfo, error) {
+	ss, err := loadSavedSession(ctx, daemonID)
+	if err != nil {
+		return nil, err
+	}
+	var si *manager.SessionInfo
+	if ss != nil {
+		si = ss.Session
+		if !forceNew && !maps.Equal(ss.Metadata, ci.Metadata) {
+			// The traffic-manager only receives the metadata when the client arrives.
+			dlog.Debugf(ctx, "the metadata of cached session %s differs from the requested metadata", si.SessionId)
+			forceNew = true
+		}
+		if forceNew {
+			dlog.Debugf(ctx, "departing from cached session %s to arrive as a new client", si.SessionId)
+			if _, err = mClient.Depart(ctx, si); err != nil {
+				// The session might already be gone, in which case the traffic-manager will expire it anyway.
+				dlog.Debugf(ctx, "failed to depart from cached session %s: %v", si.SessionId, err)
+			}
+			if err = DeleteSessionInfoFromUserCache(ctx, daemonID); err != nil {
+				dlog.Warnf(ctx, "failed to delete cached session %s: %v", si.SessionId, err)
+			}
+			si = nil
+		} else {
+			// Check if the session is still valid in the traffic-manager by calling Remain
+			if _, err = mClient.Remain(ctx, &manager.RemainRequest{Session: si}); err == nil {
+				if ctx.Err() != nil {
+					// Call timed out, so the traffic-manager isn't responding at all
+					return nil, ctx.Err()
+				}
+				dlog.Debugf(ctx, "client was already known to the traffic-manager as %q", ci.Name)
+				return si, nil
+			}
+		}
+	}
+
+	si, err = mClient.ArriveAsClient(ctx, ci)
+	if err != nil {
+		if pErr := arrivalError(ctx, err); pErr != nil {
+			return nil, pErr
+		}
+		return nil, client.CheckTimeout(ctx, fmt.Errorf("manager.ArriveAsClient: %w", err))
+	}
+	if err = SaveSessionInfoToUserCache(ctx, daemonID, managerNamespace, ci.Metadata, si); err != nil {
+		return nil, err
+	}
+	return si, nil
+}
@@ -0,0 +1,155 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+// fakeReporter records the reports that are made to scout.
+type fakeReporter struct {
+	scout.Reporter
+	actions []string
+	entries [][]scout.Entry
+}
+
+func (r *fakeReporter) Report(_ context.Context, action string, entries ...scout.Entry) {
+	r.actions = append(r.actions, action)
+	r.entries = append(r.entries, entries)
+}
+
+func Test_classifyArrivalPrecondition(t *testing.T) {
+	tests := []struct {
+		msg        string
+		wantReason string
+	}{
+		{"namespace team-a is not managed", "namespace_not_managed"},
+		{"no seats available", "unknown"},
+		{"failed to connect stream: EOF", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			reason, hint := classifyArrivalPrecondition(tt.msg)
+			assert.Equal(t, tt.wantReason, reason)
+			assert.Equal(t, reason == "unknown", hint == "")
+		})
+	}
+}
+
+func Test_arrivalError(t *testing.T) {
+	r := &fakeReporter{}
+	ctx := scout.WithReporter(dlog.NewTestContext(t, false), r)
+
+	// Errors that aren't a FailedPrecondition are left to the caller.
+	assert.NoError(t, arrivalError(ctx, errors.New("boom")))
+	assert.NoError(t, arrivalError(ctx, status.Error(codes.Unavailable, "namespace team-a is not managed")))
+	assert.Empty(t, r.actions)
+
+	err := arrivalError(ctx, status.Error(codes.FailedPrecondition, "namespace team-a is not managed"))
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Contains(t, err.Error(), "namespace team-a is not managed. Connect to a namespace that the traffic-manager manages")
+
+	err = arrivalError(ctx, status.Error(codes.FailedPrecondition, "something else"))
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Equal(t, "something else", err.Error())
+
+	// The reason is reported, but not the message.
+	require.Len(t, r.actions, 2)
+	assert.Equal(t, "arrive_as_client_precondition_failed", r.actions[0])
+	assert.Equal(t, []scout.Entry{{Key: "reason", Value: "namespace_not_managed"}}, r.entries[0])
+	assert.Equal(t, []scout.Entry{{Key: "reason", Value: "unknown"}}, r.entries[1])
+}
+
+// arriveManager is a fakeManager that hands out new sessions, and that knows no sessions when remainErr is set.
+type arriveManager struct {
+	fakeManager
+	remainErr  error
+	arrivals   int
+	lastClient *manager.ClientInfo
+}
+
+func (m *arriveManager) Remain(_ context.Context, _ *manager.RemainRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.remains.Add(1)
+	return &emptypb.Empty{}, m.remainErr
+}
+
+func (m *arriveManager) ArriveAsClient(_ context.Context, ci *manager.ClientInfo, _ ...grpc.CallOption) (*manager.SessionInfo, error) {
+	m.arrivals++
+	m.lastClient = ci
+	return &manager.SessionInfo{SessionId: fmt.Sprintf("session-%d", m.arrivals)}, nil
+}
+
+func Test_arrive(t *testing.T) {
+	ctx := filelocation.WithAppUserCacheDir(dlog.NewTestContext(t, false), t.TempDir())
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	ci := &manager.ClientInfo{Name: "test@localhost", Metadata: map[string]string{"build": "1234"}}
+	mc := &arriveManager{}
+
+	// No session is cached, so the client arrives with its metadata.
+	si, err := arrive(ctx, mc, daemonID, "ambassador", ci, false)
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", si.SessionId)
+	assert.Equal(t, int32(0), mc.remains.Load())
+	assert.Equal(t, map[string]string{"build": "1234"}, mc.lastClient.GetMetadata())
+
+	// The cached session is reused while the traffic-manager knows it.
+	si, err = arrive(ctx, mc, daemonID, "ambassador", ci, false)
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", si.SessionId)
+	assert.Equal(t, int32(1), mc.remains.Load())
+	assert.Equal(t, 1, mc.arrivals)
+
+	// A forced arrival departs from the cached session without checking it.
+	si, err = arrive(ctx, mc, daemonID, "ambassador", ci, true)
+	require.NoError(t, err)
+	assert.Equal(t, "session-2", si.SessionId)
+	assert.Equal(t, int32(1), mc.remains.Load())
+	assert.Equal(t, int32(1), mc.departs.Load())
+	cached, err := LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	assert.Equal(t, "session-2", cached.SessionId)
+
+	// A cached session that the traffic-manager no longer knows is replaced.
+	mc.remainErr = status.Error(codes.NotFound, "session not found")
+	si, err = arrive(ctx, mc, daemonID, "ambassador", ci, false)
+	require.NoError(t, err)
+	assert.Equal(t, "session-3", si.SessionId)
+	assert.Equal(t, int32(2), mc.remains.Load())
+	assert.Equal(t, int32(1), mc.departs.Load())
+
+	// The traffic-manager only receives the metadata on arrival, so a cached session with other metadata is
+	// departed from without checking it.
+	mc.remainErr = nil
+	ci = &manager.ClientInfo{Name: "test@localhost", Metadata: map[string]string{"build": "5678"}}
+	si, err = arrive(ctx, mc, daemonID, "ambassador", ci, false)
+	require.NoError(t, err)
+	assert.Equal(t, "session-4", si.SessionId)
+	assert.Equal(t, int32(2), mc.remains.Load())
+	assert.Equal(t, int32(2), mc.departs.Load())
+	assert.Equal(t, map[string]string{"build": "5678"}, mc.lastClient.GetMetadata())
+
+	// The session is reused when the metadata is the same.
+	si, err = arrive(ctx, mc, daemonID, "ambassador", ci, false)
+	require.NoError(t, err)
+	assert.Equal(t, "session-4", si.SessionId)
+	assert.Equal(t, int32(3), mc.remains.Load())
+	assert.Equal(t, 4, mc.arrivals)
+}
@@ -0,0 +1,194 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-json-experiment/json"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+// auditBufferSize is the number of events that can be queued for the audit log writer before
+// further events are dropped.
+const auditBufferSize = 1024
+
+const (
+	auditWorkloadAdded    = "workload-added"
+	auditWorkloadModified = "workload-modified"
+	auditWorkloadDeleted  = "workload-deleted"
+	auditInterceptStarted = "intercept-started"
+	auditInterceptEnded   = "intercept-ended"
+)
+
+type auditEvent struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	Kind      string    `json:"kind,omitempty"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	UID       string    `json:"uid,omitempty"`
+	Client    string    `json:"client"`
+}
+
+// auditLog is an append-only log of the workload events and intercept lifecycle events observed by
+// the session. Events are queued by record and written by run, so that the recording never blocks.
+// A nil *auditLog is valid and records nothing.
+type auditLog struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	clientID string
+	clock    client.Clock
+	events   chan *auditEvent
+	dropped  atomic.Int64
+
+	file *os.File
+	size int64
+}
+
+// newAuditLog returns an auditLog configured from the given config, or nil if auditing isn't enabled.
+// The events are timestamped using the Clock of the given context.
+func newAuditLog(ctx context.Context, cfg *client.Audit, clientID string) *auditLog {
+	if cfg.File == "" {
+		return nil
+	}
+	return &auditLog{
+		path:     cfg.File,
+		maxSize:  cfg.MaxSize(),
+		maxFiles: cfg.MaxFiles(),
+		clientID: clientID,
+		clock:    client.GetClock(ctx),
+		events:   make(chan *auditEvent, auditBufferSize),
+	}
+}
+
+// record queues an event for the audit log. The event is dropped if the queue is full.
+func (a *auditLog) record(event, kind, name, namespace, uid string) {
+	if a == nil {
+		return
+	}
+	ev := &auditEvent{
+		Time:      a.clock.Now(),
+		Event:     event,
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+		UID:       uid,
+		Client:    a.clientID,
+	}
+	select {
+	case a.events <- ev:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+func auditWorkloadEvent(et workload.EventType) string {
+	switch et {
+	case workload.EventTypeAdd:
+		return auditWorkloadAdded
+	case workload.EventTypeDelete:
+		return auditWorkloadDeleted
+	default:
+		return auditWorkloadModified
+	}
+}
+
+func auditRPCWorkloadEvent(et manager.WorkloadEvent_Type) string {
+	switch et {
+	case manager.WorkloadEvent_ADDED_UNSPECIFIED:
+		return auditWorkloadAdded
+	case manager.WorkloadEvent_DELETED:
+		return auditWorkloadDeleted
+	default:
+		return auditWorkloadModified
+	}
+}
+
+// run writes queued events to the audit log until the context is cancelled.
+func (a *auditLog) run(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	defer func() {
+		if a.file != nil {
+			_ = a.file.Close()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-a.events:
+			if n := a.dropped.Swap(0); n > 0 {
+				dlog.Warnf(ctx, "audit log %s dropped %d events", a.path, n)
+			}
+			if err := a.write(ev); err != nil {
+				dlog.Errorf(ctx, "failed to write audit log %s: %v", a.path, err)
+			}
+		}
+	}
+}
+
+func (a *auditLog) write(ev *auditEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if a.file != nil && a.size+int64(len(data)) > a.maxSize {
+		if err = a.rotate(); err != nil {
+			return err
+		}
+	}
+	if a.file == nil {
+		if err = a.open(); err != nil {
+			return err
+		}
+	}
+	n, err := a.file.Write(data)
+	a.size += int64(n)
+	return err
+}
+
+func (a *auditLog) open() error {
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	a.file = f
+	a.size = st.Size()
+	return nil
+}
+
+// rotate closes the current audit log and renames it to <path>.1, after first renaming <path>.N to
+// <path>.N+1 for all N < maxFiles. The oldest file is overwritten.
+func (a *auditLog) rotate() error {
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+	a.file = nil
+	a.size = 0
+	for i := a.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", a.path, i), fmt.Sprintf("%s.%d", a.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(a.path, a.path+".1")
+}
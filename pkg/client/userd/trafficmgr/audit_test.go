@@ -0,0 +1,80 @@
+package trafficmgr
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+func Test_auditLog_disabled(t *testing.T) {
+	a := newAuditLog(context.Background(), &client.Audit{}, "me@host")
+	assert.Nil(t, a)
+	a.record(auditWorkloadAdded, "Deployment", "echo", "default", "")
+}
+
+func Test_auditLog_rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	fc := clocktesting.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	ctx := client.WithClock(context.Background(), fc)
+	a := newAuditLog(ctx, &client.Audit{File: path, MaxSizeV: resource.MustParse("300"), MaxFilesV: 2}, "me@host")
+	require.NotNil(t, a)
+
+	for i := 0; i < 10; i++ {
+		a.record(auditWorkloadModified, "Deployment", "echo", "default", "1234")
+		require.NoError(t, a.write(<-a.events))
+	}
+	require.NoError(t, a.file.Close())
+
+	readEvents := func(p string) []auditEvent {
+		f, err := os.Open(p)
+		require.NoError(t, err)
+		defer f.Close()
+		var evs []auditEvent
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var ev auditEvent
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &ev))
+			evs = append(evs, ev)
+		}
+		return evs
+	}
+
+	total := 0
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		st, err := os.Stat(p)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, st.Size(), int64(300))
+		evs := readEvents(p)
+		require.NotEmpty(t, evs)
+		for _, ev := range evs {
+			assert.Equal(t, auditWorkloadModified, ev.Event)
+			assert.Equal(t, "echo", ev.Name)
+			assert.Equal(t, "me@host", ev.Client)
+			assert.True(t, fc.Now().Equal(ev.Time), "event not timestamped using the clock of the context")
+		}
+		total += len(evs)
+	}
+	assert.Less(t, total, 10, "the oldest events should have been rotated out")
+	_, err := os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_auditLog_dropsWhenFull(t *testing.T) {
+	a := newAuditLog(context.Background(), &client.Audit{File: filepath.Join(t.TempDir(), "audit.log")}, "me@host")
+	for i := 0; i < auditBufferSize+5; i++ {
+		a.record(auditWorkloadAdded, "Deployment", "echo", "default", "")
+	}
+	assert.Len(t, a.events, auditBufferSize)
+	assert.Equal(t, int64(5), a.dropped.Load())
+}
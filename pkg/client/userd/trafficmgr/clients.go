@@ -0,0 +1,27 @@
+package trafficmgr
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// ConnectedClients returns the clients that are connected to the traffic-manager, this client included. A user
+// error is returned when the traffic-manager doesn't permit the listing, or when it's too old to support it.
+func (s *session) ConnectedClients(ctx context.Context) ([]*manager.ConnectedClient, error) {
+	ccs, err := s.ManagerClient().ListClients(ctx, s.SessionInfo())
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unimplemented:
+			return nil, errcat.User.Newf("traffic-manager version %s doesn't support listing of connected clients", s.ManagerVersion())
+		case codes.PermissionDenied:
+			return nil, errcat.User.New(status.Convert(err).Message())
+		}
+		return nil, err
+	}
+	return ccs.Clients, nil
+}
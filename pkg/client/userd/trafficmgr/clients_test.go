@@ -0,0 +1,69 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// clientsManager is a fakeManager that lists the given clients, or returns the given error.
+type clientsManager struct {
+	fakeManager
+	clients     []*manager.ConnectedClient
+	err         error
+	listSession *manager.SessionInfo
+}
+
+func (m *clientsManager) ListClients(_ context.Context, si *manager.SessionInfo, _ ...grpc.CallOption) (*manager.ConnectedClients, error) {
+	m.listSession = si
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &manager.ConnectedClients{Clients: m.clients}, nil
+}
+
+func Test_session_ConnectedClients(t *testing.T) {
+	connectedAt := timestamppb.New(time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC))
+	mgr := &clientsManager{clients: []*manager.ConnectedClient{
+		{Name: "alice@host", InstallId: "1", Namespace: "a", ConnectedAt: connectedAt, InterceptCount: 2},
+		{Name: "bob@host", InstallId: "2", Namespace: "a", ConnectedAt: connectedAt},
+		{Name: "test@localhost", InstallId: "3", Namespace: "b", ConnectedAt: connectedAt, InterceptCount: 1},
+	}}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+	s.managerVersion = semver.MustParse("2.22.0")
+
+	ccs, err := s.ConnectedClients(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, mgr.clients, ccs)
+	assert.Equal(t, "session", mgr.listSession.SessionId)
+
+	// A listing that the traffic-manager doesn't permit is a user error.
+	mgr.err = status.Error(codes.PermissionDenied, "listing of connected clients is disabled by the traffic-manager")
+	_, err = s.ConnectedClients(ctx)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Contains(t, err.Error(), "disabled by the traffic-manager")
+
+	// So is a listing that an older traffic-manager doesn't support.
+	mgr.err = status.Error(codes.Unimplemented, "")
+	_, err = s.ConnectedClients(ctx)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Contains(t, err.Error(), "2.22.0")
+
+	mgr.err = status.Error(codes.Unavailable, "connection lost")
+	_, err = s.ConnectedClients(ctx)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
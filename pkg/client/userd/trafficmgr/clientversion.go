@@ -0,0 +1,51 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// checkClientVersion compares the given client version with the minimum and recommended client versions
+// announced by the traffic-manager. An error is returned when the client is older than the minimum version.
+// A warning is returned when the client is older than the recommended version. Versions that cannot be
+// parsed are ignored.
+func checkClientVersion(ctx context.Context, vi *manager.VersionInfo2, clientVersion semver.Version) (warning string, err error) {
+	parse := func(name, v string) *semver.Version {
+		if v == "" {
+			return nil
+		}
+		sv, err := semver.Parse(strings.TrimPrefix(v, "v"))
+		if err != nil {
+			dlog.Warnf(ctx, "unable to parse %s %q announced by the traffic-manager: %v", name, v, err)
+			return nil
+		}
+		return &sv
+	}
+	report := func(action string, required *semver.Version) {
+		scout.Report(ctx, action,
+			scout.Entry{Key: "client_version", Value: clientVersion.String()},
+			scout.Entry{Key: "required_version", Value: required.String()})
+	}
+
+	if mv := parse("minimum client version", vi.MinClientVersion); mv != nil && clientVersion.LT(*mv) {
+		report("client_version_below_minimum", mv)
+		return "", errcat.User.Newf(
+			"client version %s is older than the minimum version %s required by the traffic-manager, please upgrade",
+			clientVersion, mv)
+	}
+	if rv := parse("recommended client version", vi.RecommendedClientVersion); rv != nil && clientVersion.LT(*rv) {
+		report("client_version_below_recommended", rv)
+		return fmt.Sprintf(
+			"client version %s is older than the version %s recommended by the traffic-manager, please consider upgrading",
+			clientVersion, rv), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,56 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+func Test_checkClientVersion(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	vi := &manager.VersionInfo2{
+		Name:                     "Traffic Manager",
+		Version:                  "v2.22.0",
+		MinClientVersion:         "v2.20.0",
+		RecommendedClientVersion: "2.22.0",
+	}
+
+	t.Run("below minimum", func(t *testing.T) {
+		warning, err := checkClientVersion(ctx, vi, semver.MustParse("2.19.4"))
+		require.Error(t, err)
+		assert.Equal(t, errcat.User, errcat.GetCategory(err))
+		assert.Contains(t, err.Error(), "minimum version 2.20.0")
+		assert.Empty(t, warning)
+	})
+
+	t.Run("below recommended", func(t *testing.T) {
+		warning, err := checkClientVersion(ctx, vi, semver.MustParse("2.21.1"))
+		require.NoError(t, err)
+		assert.Contains(t, warning, "recommended by the traffic-manager")
+		assert.Contains(t, warning, "2.22.0")
+	})
+
+	t.Run("current", func(t *testing.T) {
+		warning, err := checkClientVersion(ctx, vi, semver.MustParse("2.22.0"))
+		require.NoError(t, err)
+		assert.Empty(t, warning)
+	})
+
+	t.Run("no requirements", func(t *testing.T) {
+		warning, err := checkClientVersion(ctx, &manager.VersionInfo2{Version: "v2.22.0"}, semver.MustParse("2.0.0"))
+		require.NoError(t, err)
+		assert.Empty(t, warning)
+	})
+
+	t.Run("unparsable", func(t *testing.T) {
+		warning, err := checkClientVersion(ctx, &manager.VersionInfo2{MinClientVersion: "latest"}, semver.MustParse("2.0.0"))
+		require.NoError(t, err)
+		assert.Empty(t, warning)
+	})
+}
@@ -0,0 +1,109 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+// waitForWaiters waits until something waits for the fake clock, so that a step is guaranteed to be observed.
+func waitForWaiters(t *testing.T, fc *clocktesting.FakeClock) {
+	t.Helper()
+	require.Eventually(t, fc.HasWaiters, 5*time.Second, time.Millisecond)
+}
+
+func Test_runWithRetry_backoff(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(client.WithClock(dlog.NewTestContext(t, false), fc))
+	defer cancel()
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = runWithRetry(ctx, "test", func(context.Context) error {
+			calls.Add(1)
+			return errors.New("failed")
+		})
+	}()
+
+	// The backoff starts at 100ms and is doubled after each failure.
+	for i, backoff := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		waitForWaiters(t, fc)
+		assert.Equal(t, int32(i+1), calls.Load())
+		fc.Step(backoff - time.Millisecond)
+		assert.Equal(t, int32(i+1), calls.Load(), "called before the backoff elapsed")
+		fc.Step(time.Millisecond)
+		require.Eventually(t, func() bool { return calls.Load() == int32(i+2) }, 5*time.Second, time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+// fakeRemainManager is a traffic-manager that counts the calls to Remain and Depart.
+type fakeRemainManager struct {
+	manager.ManagerClient
+	remains atomic.Int32
+	departs atomic.Int32
+}
+
+func (m *fakeRemainManager) Remain(context.Context, *manager.RemainRequest, ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.remains.Add(1)
+	return &emptypb.Empty{}, nil
+}
+
+func (m *fakeRemainManager) Depart(context.Context, *manager.SessionInfo, ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.departs.Add(1)
+	return &emptypb.Empty{}, nil
+}
+
+func Test_session_remainLoop(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx := filelocation.WithAppUserCacheDir(dlog.NewTestContext(t, false), t.TempDir())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+	ctx, cancel := context.WithCancel(client.WithClock(ctx, fc))
+	defer cancel()
+
+	conn, err := grpc.NewClient("passthrough:///traffic-manager", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	mgr := &fakeRemainManager{}
+	s := &session{
+		sessionInfo:   &manager.SessionInfo{SessionId: "session"},
+		managerClient: mgr,
+		managerConn:   conn,
+		daemonID:      daemonID,
+	}
+	s.self = s
+
+	done := make(chan error, 1)
+	go func() { done <- s.remainLoop(ctx) }()
+
+	for i := int32(1); i <= 3; i++ {
+		waitForWaiters(t, fc)
+		fc.Step(remainInterval)
+		require.Eventually(t, func() bool { return mgr.remains.Load() == i }, 5*time.Second, time.Millisecond)
+	}
+	assert.Zero(t, mgr.departs.Load())
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(1), mgr.departs.Load())
+}
@@ -0,0 +1,89 @@
+package trafficmgr
+
+import (
+	"context"
+	"slices"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// ensureNoClientConflict checks that no other client has an intercept that competes for the same traffic as
+// the intercept described by the given spec and container port. The check is skipped when the workload watcher
+// knows that no other client intercepts the workload. Otherwise, the traffic-manager is asked for its intercepts.
+func (s *session) ensureNoClientConflict(ctx context.Context, spec *manager.InterceptSpec, containerPort int32) *rpc.InterceptResult {
+	if !s.interceptedByOtherClient(spec.Namespace, spec.Agent) {
+		return nil
+	}
+	iis, err := s.interceptSnapshot(ctx, &manager.SessionInfo{})
+	if err != nil {
+		dlog.Warnf(ctx, "unable to check for conflicting intercepts: %v", err)
+		return nil
+	}
+	ic := findClientConflict(spec, containerPort, s.clientID, iis)
+	if ic == nil {
+		return nil
+	}
+	return InterceptError(common.InterceptError_INTERCEPT_CONFLICT, errcat.User.Newf(
+		"workload %s.%s is already intercepted by client %s using intercept %s. Use --force to intercept anyway",
+		spec.Agent, spec.Namespace, ic.Spec.Client, ic.Spec.Name))
+}
+
+// interceptedByOtherClient returns true unless the workload watcher knows that the workload with the given
+// namespace and name isn't intercepted by any other client than this one.
+func (s *session) interceptedByOtherClient(namespace, name string) bool {
+	s.workloadsLock.Lock()
+	defer s.workloadsLock.Unlock()
+	if _, ok := s.syncedNamespaces[namespace]; !ok {
+		return true
+	}
+	for k, wi := range s.workloads {
+		if k.namespace == namespace && k.name == name && slices.ContainsFunc(wi.interceptClients, func(c string) bool {
+			return c != s.clientID
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// interceptSnapshot returns the intercepts that the traffic-manager has for the given session, or the
+// intercepts of all clients when the session has no id.
+func (s *session) interceptSnapshot(ctx context.Context, si *manager.SessionInfo) ([]*manager.InterceptInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := s.ManagerClient().WatchIntercepts(ctx, si)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Intercepts, nil
+}
+
+// findClientConflict returns the first intercept in the given list that belongs to another client than the
+// given one and competes for the same traffic as the intercept described by the given spec and container port.
+// Two intercepts compete when they intercept the same container port of the same workload, and at least one of
+// them is global. Intercepts that both use request matchers are never considered to be in conflict.
+func findClientConflict(spec *manager.InterceptSpec, containerPort int32, clientID string, iis []*manager.InterceptInfo) *manager.InterceptInfo {
+	global := spec.Mechanism == "" || spec.Mechanism == "tcp"
+	for _, ii := range iis {
+		is := ii.Spec
+		switch {
+		case is.Client == clientID,
+			is.Namespace != spec.Namespace,
+			is.Agent != spec.Agent,
+			ii.Disposition == manager.InterceptDispositionType_REMOVED,
+			containerPort != 0 && is.ContainerPort != 0 && is.ContainerPort != containerPort,
+			!(global || isGlobalIntercept(ii)):
+			continue
+		}
+		return ii
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package trafficmgr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/k8s"
+)
+
+// redacted replaces the values in a diagnostics bundle that may be secret.
+const redacted = "REDACTED"
+
+// sensitiveKeyParts are the parts of a key that make its value secret when the key contains them.
+var sensitiveKeyParts = []string{"token", "secret", "password", "passwd", "credential", "apikey", "auth", "private"} //nolint:gochecknoglobals // constant
+
+// diagnosticsError is the content of a diagnostics file when its data cannot be obtained.
+type diagnosticsError struct {
+	Error string `json:"error"`
+}
+
+type interceptSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Workload    string `json:"workload"`
+	Namespace   string `json:"namespace"`
+	Mechanism   string `json:"mechanism"`
+	Disposition string `json:"disposition"`
+	Message     string `json:"message,omitempty"`
+}
+
+type ingestSummary struct {
+	Workload     string `json:"workload"`
+	WorkloadKind string `json:"workloadKind"`
+	Container    string `json:"container"`
+	PodIP        string `json:"podIP"`
+}
+
+type managerSummary struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Namespace string `json:"namespace"`
+}
+
+// ExportDiagnostics returns a zip archive with the state of the session. The archive contains one JSON file for
+// each of the effective config, the mapped namespaces, the current intercepts and ingests, the traffic-manager,
+// the errors that ended workload watchers, and the status of the root daemon. Environments and mechanism
+// arguments are left out of the summaries of intercepts and ingests, and the config values that may be secret
+// are redacted. A file that holds data that cannot be obtained, holds the error instead.
+func (s *session) ExportDiagnostics(ctx context.Context) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte) error {
+		w, err := zw.Create(name)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		return err
+	}
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			data, _ = json.Marshal(diagnosticsError{Error: err.Error()})
+		}
+		return add(name, data)
+	}
+
+	var cfg any
+	if sc, err := s.GetConfig(ctx); err != nil {
+		cfg = diagnosticsError{Error: err.Error()}
+	} else if cfg, err = redactedConfig(sc); err != nil {
+		cfg = diagnosticsError{Error: err.Error()}
+	}
+
+	ics := s.getCurrentInterceptInfos()
+	intercepts := make([]interceptSummary, len(ics))
+	for i, ii := range ics {
+		spec := ii.Spec
+		intercepts[i] = interceptSummary{
+			ID:          ii.Id,
+			Name:        spec.Name,
+			Workload:    spec.Agent,
+			Namespace:   spec.Namespace,
+			Mechanism:   spec.Mechanism,
+			Disposition: ii.Disposition.String(),
+			Message:     ii.Message,
+		}
+	}
+
+	igs := s.getCurrentIngests()
+	ingests := make([]ingestSummary, len(igs))
+	for i, ig := range igs {
+		ingests[i] = ingestSummary{Workload: ig.Workload, WorkloadKind: ig.WorkloadKind, Container: ig.Container, PodIP: ig.PodIp}
+	}
+	slices.SortFunc(ingests, func(a, b ingestSummary) int {
+		if c := strings.Compare(a.Workload, b.Workload); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Container, b.Container)
+	})
+
+	s.workloadsLock.Lock()
+	watchErrors := make(map[string]string, len(s.watchErrors))
+	for ns, err := range s.watchErrors {
+		watchErrors[ns] = err.Error()
+	}
+	s.workloadsLock.Unlock()
+
+	var rootDaemon []byte
+	if st, err := s.rootDaemon.Status(ctx, &empty.Empty{}); err != nil {
+		rootDaemon, _ = json.Marshal(diagnosticsError{Error: err.Error()})
+	} else if rootDaemon, err = (protojson.MarshalOptions{Multiline: true}).Marshal(st); err != nil {
+		rootDaemon, _ = json.Marshal(diagnosticsError{Error: err.Error()})
+	}
+
+	for _, err := range []error{
+		addJSON("config.json", cfg),
+		addJSON("namespaces.json", s.GetCurrentNamespaces(false)),
+		addJSON("intercepts.json", intercepts),
+		addJSON("ingests.json", ingests),
+		addJSON("manager.json", managerSummary{
+			Name:      s.ManagerName(),
+			Version:   s.ManagerVersion().String(),
+			Namespace: k8s.GetManagerNamespace(ctx),
+		}),
+		addJSON("watcher-errors.json", watchErrors),
+		add("root-daemon.json", rootDaemon),
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// redactedConfig returns the generic JSON representation of the given config, with the values that may be
+// secret replaced. Those are the values of environments, the mechanism arguments (which may match headers that
+// carry credentials), and the values of keys that suggest a secret.
+func redactedConfig(sc *client.SessionConfig) (any, error) {
+	data, err := client.MarshalJSON(sc)
+	if err != nil {
+		return nil, err
+	}
+	var cfg any
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return redact(cfg), nil
+}
+
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			switch {
+			case k == "env", k == "mechanismArgs":
+				v[k] = redactAll(e)
+			case isSensitiveKey(k):
+				v[k] = redacted
+			default:
+				v[k] = redact(e)
+			}
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = redact(e)
+		}
+	}
+	return v
+}
+
+// redactAll replaces all values of the given map or list, keeping its keys or length.
+func redactAll(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k := range v {
+			v[k] = redacted
+		}
+	case []any:
+		for i := range v {
+			v[i] = redacted
+		}
+	default:
+		return redacted
+	}
+	return v
+}
+
+func isSensitiveKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, p := range sensitiveKeyParts {
+		if strings.Contains(k, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package trafficmgr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	auth "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rootdRpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// configRootDaemon is a fakeRootDaemon that returns a client config from GetNetworkConfig.
+type configRootDaemon struct {
+	fakeRootDaemon
+	clientConfig []byte
+}
+
+func (d *configRootDaemon) GetNetworkConfig(context.Context, *emptypb.Empty, ...grpc.CallOption) (*rootdRpc.NetworkConfig, error) {
+	return &rootdRpc.NetworkConfig{ClientConfig: d.clientConfig}, nil
+}
+
+func (d *configRootDaemon) Status(context.Context, *emptypb.Empty, ...grpc.CallOption) (*rootdRpc.DaemonStatus, error) {
+	return &rootdRpc.DaemonStatus{Version: &common.VersionInfo{Version: "v2.21.0"}}, nil
+}
+
+func Test_session_ExportDiagnostics(t *testing.T) {
+	rd := &configRootDaemon{clientConfig: []byte(`{"intercept":{"presets":{"api":{
+		"mechanism":"http",
+		"mechanismArgs":["--http-header=Authorization=Bearer s3cr3t"],
+		"env":{"API_TOKEN":"s3cr3t"}
+	}}}}`)}
+	ctx, s, _ := newTestSession(t, &fakeManager{}, rd)
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}}
+	s.SetMappedNamespaces(ctx, []string{"a", "b"})
+	s.managerVersion = semver.MustParse("2.21.0")
+	s.currentIntercepts = map[string]*intercept{"1": {InterceptInfo: &manager.InterceptInfo{
+		Id:          "1",
+		Spec:        &manager.InterceptSpec{Name: "echo", Agent: "echo", Namespace: "a", Mechanism: "tcp"},
+		Disposition: manager.InterceptDispositionType_ACTIVE,
+		Environment: map[string]string{"DB_PASSWORD": "s3cr3t"},
+	}}}
+	s.setWatchError("b", errors.New("watch ended"))
+
+	data, err := s.ExportDiagnostics(ctx)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"config.json",
+		"namespaces.json",
+		"intercepts.json",
+		"ingests.json",
+		"manager.json",
+		"watcher-errors.json",
+		"root-daemon.json",
+	}, names)
+	for name, content := range files {
+		assert.NotContains(t, string(content), "s3cr3t", name)
+	}
+
+	var cfg struct {
+		Config struct {
+			Intercept struct {
+				Presets map[string]struct {
+					Mechanism     string            `json:"mechanism"`
+					MechanismArgs []string          `json:"mechanismArgs"`
+					Env           map[string]string `json:"env"`
+				} `json:"presets"`
+			} `json:"intercept"`
+		} `json:"clientConfig"`
+	}
+	require.NoError(t, json.Unmarshal(files["config.json"], &cfg))
+	api := cfg.Config.Intercept.Presets["api"]
+	assert.Equal(t, "http", api.Mechanism)
+	assert.Equal(t, []string{redacted}, api.MechanismArgs)
+	assert.Equal(t, map[string]string{"API_TOKEN": redacted}, api.Env)
+
+	var nss []string
+	require.NoError(t, json.Unmarshal(files["namespaces.json"], &nss))
+	assert.Equal(t, []string{"a", "b"}, nss)
+
+	var ics []interceptSummary
+	require.NoError(t, json.Unmarshal(files["intercepts.json"], &ics))
+	assert.Equal(t, []interceptSummary{{
+		ID: "1", Name: "echo", Workload: "echo", Namespace: "a", Mechanism: "tcp", Disposition: "ACTIVE",
+	}}, ics)
+
+	var igs []ingestSummary
+	require.NoError(t, json.Unmarshal(files["ingests.json"], &igs))
+	assert.Empty(t, igs)
+
+	var mgr managerSummary
+	require.NoError(t, json.Unmarshal(files["manager.json"], &mgr))
+	assert.Equal(t, "Traffic Manager", mgr.Name)
+	assert.Equal(t, "2.21.0", mgr.Version)
+
+	var wes map[string]string
+	require.NoError(t, json.Unmarshal(files["watcher-errors.json"], &wes))
+	assert.Equal(t, map[string]string{"b": "watch ended"}, wes)
+
+	assert.Contains(t, string(files["root-daemon.json"]), "v2.21.0")
+}
+
+func Test_redact(t *testing.T) {
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"timeouts":{"agentInstall":"2m"},
+		"registry":{"authToken":"x","items":[{"password":"y","name":"z"}]},
+		"env":{"A":"1"}
+	}`), &v))
+	assert.Equal(t, map[string]any{
+		"timeouts": map[string]any{"agentInstall": "2m"},
+		"registry": map[string]any{"authToken": redacted, "items": []any{map[string]any{"password": redacted, "name": "z"}}},
+		"env":      map[string]any{"A": redacted},
+	}, redact(v))
+}
@@ -7,14 +7,16 @@ import (
 )
 
 func (s *session) dialRequestWatcher(ctx context.Context) error {
-	return runWithRetry(ctx, s._dialRequestWatcher)
+	return runWithRetry(ctx, "dial-request-watcher", s._dialRequestWatcher)
 }
 
 func (s *session) _dialRequestWatcher(ctx context.Context) error {
 	// Deal with dial requests from the manager
-	dialerStream, err := s.managerClient.WatchDial(ctx, s.sessionInfo)
+	mc := s.ManagerClient()
+	dialerStream, err := mc.WatchDial(ctx, s.sessionInfo)
 	if err != nil {
 		return err
 	}
-	return tunnel.DialWaitLoop(ctx, tunnel.ManagerProvider(s.managerClient), dialerStream, s.sessionInfo.SessionId)
+	ctx = tunnel.WithDialActivity(ctx, s.dialActivity)
+	return tunnel.DialWaitLoop(ctx, tunnel.ManagerProvider(mc), dialerStream, s.sessionInfo.SessionId)
 }
@@ -0,0 +1,125 @@
+package trafficmgr
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// drain is an intercept that is draining, and the time when it's torn down.
+type drain struct {
+	ic        *intercept
+	drainedAt time.Time
+}
+
+// drainIntercept removes the given intercept from the traffic-manager, so that the traffic-agent stops
+// intercepting new connections, and then keeps the intercept handler, port-forwards, and mounts running in the
+// background for the given drain period, so that the in-flight connections can complete before the intercept
+// ends. The drain ends early when the session ends. The session status reports the intercept as draining
+// during that time.
+func (s *session) drainIntercept(c context.Context, ic *intercept, reason string, drainPeriod time.Duration) error {
+	name := ic.Spec.Name
+	clk := client.GetClock(c)
+
+	// The intercept is draining before the traffic-manager removes it, so that its port-forwards and mounts
+	// are kept when it leaves the intercept snapshot.
+	t := clk.NewTimer(drainPeriod)
+	if !s.setDraining(ic, clk.Now().Add(drainPeriod)) {
+		t.Stop()
+		dlog.Debugf(c, "Intercept %s is already draining", name)
+		return nil
+	}
+	if err := s.removeManagerIntercept(c, name); err != nil {
+		t.Stop()
+		s.clearDraining(name)
+		s.endIntercept(c, ic, reason)
+		return err
+	}
+
+	dlog.Infof(c, "Draining intercept %s for %s", name, drainPeriod)
+	c = context.WithoutCancel(c)
+	s.drains.Add(1)
+	go func() {
+		defer s.drains.Done()
+		defer t.Stop()
+		select {
+		case <-ic.ctx.Done():
+			dlog.Debugf(c, "draining of intercept %s was cancelled", name)
+		case <-t.C():
+		}
+		s.clearDraining(name)
+		s.endIntercept(c, ic, reason)
+	}()
+	return nil
+}
+
+// awaitDrains waits until all draining intercepts have ended, or until the given context is done.
+func (s *session) awaitDrains(c context.Context) {
+	drained := make(chan struct{})
+	go func() {
+		s.drains.Wait()
+		close(drained)
+	}()
+	select {
+	case <-c.Done():
+	case <-drained:
+	}
+}
+
+// setDraining records that the given intercept is draining until the given time. It returns false if the
+// intercept is already draining.
+func (s *session) setDraining(ic *intercept, drainedAt time.Time) bool {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	if s.isDrainingLocked(ic) {
+		return false
+	}
+	if s.draining == nil {
+		s.draining = make(map[string]drain)
+	}
+	s.draining[ic.Spec.Name] = drain{ic: ic, drainedAt: drainedAt}
+	return true
+}
+
+func (s *session) clearDraining(name string) {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	delete(s.draining, name)
+}
+
+// isDrainingLocked returns true if the given intercept is draining. The caller must hold the
+// currentInterceptsLock.
+func (s *session) isDrainingLocked(ic *intercept) bool {
+	d, ok := s.draining[ic.Spec.Name]
+	return ok && d.ic == ic
+}
+
+// getDrainingIntercepts returns the intercepts that are draining.
+func (s *session) getDrainingIntercepts() []*intercept {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	ics := make([]*intercept, 0, len(s.draining))
+	for _, d := range s.draining {
+		ics = append(ics, d.ic)
+	}
+	return ics
+}
+
+// getDraining returns the intercepts that are draining, sorted by name.
+func (s *session) getDraining() []*rpc.DrainingIntercept {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	dis := make([]*rpc.DrainingIntercept, 0, len(s.draining))
+	for name, d := range s.draining {
+		dis = append(dis, &rpc.DrainingIntercept{Name: name, DrainedAt: timestamppb.New(d.drainedAt)})
+	}
+	slices.SortFunc(dis, func(a, b *rpc.DrainingIntercept) int { return strings.Compare(a.Name, b.Name) })
+	return dis
+}
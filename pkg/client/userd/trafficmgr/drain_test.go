@@ -0,0 +1,112 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drainingIntercept(ctx context.Context, s *session, name string, drainPeriod time.Duration) *intercept {
+	ic := activeIntercept(name, 8080, time.Now(), 0)
+	ic.Spec.DrainPeriod = int64(drainPeriod)
+	ic.ctx, ic.cancel = context.WithCancel(ctx)
+	if s.currentIntercepts == nil {
+		s.currentIntercepts = make(map[string]*intercept)
+	}
+	s.currentIntercepts[name] = ic
+	return ic
+}
+
+func Test_session_removeIntercept_drain(t *testing.T) {
+	mgr := &fakeManager{}
+	ctx, s, fc := newTestSession(t, mgr, nil)
+	ic := drainingIntercept(ctx, s, "echo", time.Minute)
+
+	// A connection that is in-flight when the intercept is removed uses the context of the intercept.
+	inFlight := ic.ctx
+
+	// The traffic-manager is told to remove the intercept right away, so that no new connections are
+	// intercepted, and the removal doesn't wait for the drain.
+	require.NoError(t, s.removeIntercept(ctx, ic, "leave"))
+	assert.Equal(t, int32(1), mgr.removes.Load())
+	dis := s.getDraining()
+	require.Len(t, dis, 1)
+	assert.Equal(t, "echo", dis[0].Name)
+	assert.True(t, fc.Now().Add(time.Minute).Equal(dis[0].DrainedAt.AsTime()))
+
+	// The in-flight connection is kept while the intercept drains, also when the intercept leaves the
+	// snapshot of the traffic-manager.
+	s.setCurrentIntercepts(ctx, nil)
+	assert.NoError(t, inFlight.Err())
+	assert.Equal(t, []*intercept{ic}, s.getDrainingIntercepts())
+
+	// Removing it again doesn't start another drain.
+	require.NoError(t, s.removeIntercept(ctx, ic, "leave"))
+	assert.Equal(t, int32(1), mgr.removes.Load())
+
+	// The intercept ends when the drain period has passed.
+	fc.Step(time.Minute)
+	require.Eventually(t, func() bool { return inFlight.Err() != nil }, 5*time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return len(s.getDraining()) == 0 }, 5*time.Second, time.Millisecond)
+}
+
+func Test_session_removeIntercept_drainSessionEnded(t *testing.T) {
+	mgr := &fakeManager{}
+	ctx, s, _ := newTestSession(t, mgr, nil)
+	sessionCtx, cancel := context.WithCancel(ctx)
+	ic := drainingIntercept(sessionCtx, s, "echo", time.Minute)
+	require.NoError(t, s.removeIntercept(ctx, ic, "leave"))
+
+	// Ending the session ends the intercept without waiting for the drain period.
+	cancel()
+	drained := make(chan struct{})
+	go func() {
+		s.awaitDrains(ctx)
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "intercept was not removed when the session ended")
+	}
+	assert.Empty(t, s.getDraining())
+}
+
+func Test_session_removeIntercept_drainManagerError(t *testing.T) {
+	mgr := &fakeManager{removeErr: errors.New("boom")}
+	ctx, s, _ := newTestSession(t, mgr, nil)
+	ic := drainingIntercept(ctx, s, "echo", time.Minute)
+
+	// The intercept isn't drained when the traffic-manager fails to remove it, because new connections
+	// might still be intercepted.
+	require.ErrorContains(t, s.removeIntercept(ctx, ic, "leave"), "boom")
+	assert.Error(t, ic.ctx.Err())
+	assert.Empty(t, s.getDraining())
+}
+
+func Test_session_ClearIngestsAndIntercepts_drain(t *testing.T) {
+	mgr := &fakeManager{}
+	ctx, s, fc := newTestSession(t, mgr, nil)
+	echo := drainingIntercept(ctx, s, "echo", time.Minute)
+	hello := drainingIntercept(ctx, s, "hello", time.Minute)
+
+	done := make(chan error, 1)
+	go func() { done <- s.ClearIngestsAndIntercepts(ctx) }()
+
+	// The intercepts drain concurrently, so both have ended when one drain period has passed.
+	require.Eventually(t, func() bool { return len(s.getDraining()) == 2 }, 5*time.Second, time.Millisecond)
+	fc.Step(time.Minute)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "intercepts were not cleared after one drain period")
+	}
+	assert.Error(t, echo.ctx.Err())
+	assert.Error(t, hello.ctx.Err())
+	assert.Equal(t, int32(2), mgr.removes.Load())
+}
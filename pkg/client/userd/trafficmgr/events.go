@@ -0,0 +1,182 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+// sessionEventBufferSize is the number of unread events that a subscriber of the session events can have
+// before its oldest unread events are dropped.
+const sessionEventBufferSize = 256
+
+// eventSubscriber is a subscriber of the session events. The dropped count is protected by the lock of
+// the eventBus.
+type eventSubscriber struct {
+	ch      chan *rpc.SessionEvent
+	dropped int32
+}
+
+// eventBus distributes the events of a session to its subscribers. Events are published under a lock, so all
+// subscribers receive them in the same order. Publishing never blocks. A subscriber that falls behind loses its
+// oldest unread events instead, so that it always receives the most recent ones, and the next event that it
+// receives tells how many were lost. The zero value is ready to use.
+type eventBus struct {
+	sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+	ended       bool
+}
+
+// subscribe returns a channel that receives the events that are published after the call. The channel is
+// closed when the given context is done, or after the final event has been published.
+func (b *eventBus) subscribe(ctx context.Context) <-chan *rpc.SessionEvent {
+	sub := &eventSubscriber{ch: make(chan *rpc.SessionEvent, sessionEventBufferSize)}
+	b.Lock()
+	defer b.Unlock()
+	if b.ended {
+		close(sub.ch)
+		return sub.ch
+	}
+	if b.subscribers == nil {
+		b.subscribers = make(map[*eventSubscriber]struct{})
+	}
+	b.subscribers[sub] = struct{}{}
+	context.AfterFunc(ctx, func() {
+		b.Lock()
+		defer b.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	})
+	return sub.ch
+}
+
+// publish sends the given event to all subscribers. The final event is the last one that is published, and
+// the channels of all subscribers are closed once it has been sent.
+func (b *eventBus) publish(ev *rpc.SessionEvent, final bool) {
+	b.Lock()
+	defer b.Unlock()
+	if b.ended {
+		return
+	}
+	for sub := range b.subscribers {
+		sub.send(ev)
+	}
+	if final {
+		for sub := range b.subscribers {
+			close(sub.ch)
+		}
+		b.subscribers = nil
+		b.ended = true
+	}
+}
+
+// send sends the given event to the subscriber, dropping its oldest unread events until there's room.
+func (sub *eventSubscriber) send(ev *rpc.SessionEvent) {
+	for {
+		sev := ev
+		if sub.dropped > 0 {
+			sev = proto.Clone(ev).(*rpc.SessionEvent)
+			sev.Dropped = sub.dropped
+		}
+		select {
+		case sub.ch <- sev:
+			sub.dropped = 0
+			return
+		default:
+		}
+		select {
+		case old := <-sub.ch:
+			sub.dropped += 1 + old.Dropped
+		default:
+			// The subscriber made room.
+		}
+	}
+}
+
+// Events returns a channel that receives the events that this session emits after the call. The channel is
+// closed when the given context is done, or after the SessionEnded event.
+func (s *session) Events(ctx context.Context) <-chan *rpc.SessionEvent {
+	return s.events.subscribe(ctx)
+}
+
+func (s *session) emitEvent(ctx context.Context, ev *rpc.SessionEvent) {
+	ev.Time = timestamppb.New(client.GetClock(ctx).Now())
+	s.events.publish(ev, false)
+}
+
+// emitNamespacesChanged is the namespace listener that emits the NamespacesChanged events.
+func (s *session) emitNamespacesChanged(ctx context.Context) {
+	s.emitEvent(ctx, &rpc.SessionEvent{Event: &rpc.SessionEvent_NamespacesChanged_{
+		NamespacesChanged: &rpc.SessionEvent_NamespacesChanged{Namespaces: s.GetCurrentNamespaces(false)},
+	}})
+}
+
+func (s *session) emitWorkloadChanged(ctx context.Context, tp rpc.SessionEvent_WorkloadChanged_Type, key workloadInfoKey, state string) {
+	s.emitEvent(ctx, &rpc.SessionEvent{Event: &rpc.SessionEvent_WorkloadChanged_{
+		WorkloadChanged: &rpc.SessionEvent_WorkloadChanged{
+			Type:      tp,
+			Kind:      key.kind,
+			Name:      key.name,
+			Namespace: key.namespace,
+			State:     state,
+		},
+	}})
+}
+
+func (s *session) emitInterceptChanged(ctx context.Context, tp rpc.SessionEvent_InterceptChanged_Type, ii *manager.InterceptInfo) {
+	s.emitEvent(ctx, &rpc.SessionEvent{Event: &rpc.SessionEvent_InterceptChanged_{
+		InterceptChanged: &rpc.SessionEvent_InterceptChanged{
+			Type:        tp,
+			Id:          ii.Id,
+			Name:        ii.Spec.Name,
+			Workload:    ii.Spec.Agent,
+			Namespace:   ii.Spec.Namespace,
+			Disposition: ii.Disposition,
+		},
+	}})
+}
+
+// emitSessionEnded emits the final SessionEnded event, which closes the channels of all subscribers. The
+// given error is the one that ended the session, if any.
+func (s *session) emitSessionEnded(ctx context.Context, err error) {
+	se := &rpc.SessionEvent_SessionEnded{Expired: errors.Is(err, ErrSessionExpired)}
+	if err != nil {
+		se.Error = err.Error()
+	}
+	s.events.publish(&rpc.SessionEvent{
+		Time:  timestamppb.New(client.GetClock(ctx).Now()),
+		Event: &rpc.SessionEvent_SessionEnded_{SessionEnded: se},
+	}, true)
+}
+
+func workloadChangedType(et workload.EventType) rpc.SessionEvent_WorkloadChanged_Type {
+	switch et {
+	case workload.EventTypeAdd:
+		return rpc.SessionEvent_WorkloadChanged_ADDED
+	case workload.EventTypeDelete:
+		return rpc.SessionEvent_WorkloadChanged_DELETED
+	default:
+		return rpc.SessionEvent_WorkloadChanged_MODIFIED
+	}
+}
+
+func rpcWorkloadChangedType(et manager.WorkloadEvent_Type) rpc.SessionEvent_WorkloadChanged_Type {
+	switch et {
+	case manager.WorkloadEvent_ADDED_UNSPECIFIED:
+		return rpc.SessionEvent_WorkloadChanged_ADDED
+	case manager.WorkloadEvent_DELETED:
+		return rpc.SessionEvent_WorkloadChanged_DELETED
+	default:
+		return rpc.SessionEvent_WorkloadChanged_MODIFIED
+	}
+}
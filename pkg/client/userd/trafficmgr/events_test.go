@@ -0,0 +1,195 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func nextEvent(t *testing.T, evs <-chan *rpc.SessionEvent) *rpc.SessionEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-evs:
+		require.True(t, ok, "the event channel was closed")
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for session event")
+		return nil
+	}
+}
+
+func Test_session_Events_namespaces(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	ctx := client.WithClock(dlog.NewTestContext(t, false), fc)
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, fake.NewClientset(), argorolloutsfake.NewSimpleClientset())
+	s := &session{Cluster: &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}}}
+	s.SetMappedNamespaces(ctx, []string{"a"})
+	s.AddNamespaceListener(ctx, s.emitNamespacesChanged)
+
+	evs := s.Events(ctx)
+	s.SetMappedNamespaces(ctx, []string{"b", "a"})
+	ev := nextEvent(t, evs)
+	assert.Equal(t, []string{"a", "b"}, ev.GetNamespacesChanged().GetNamespaces())
+	assert.True(t, fc.Now().Equal(ev.Time.AsTime()), "event not timestamped using the clock of the context")
+	assert.Zero(t, ev.Dropped)
+}
+
+func Test_session_Events_workloads(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	wl := func(tp manager.WorkloadEvent_Type, name string, state manager.WorkloadInfo_State) *manager.WorkloadEvent {
+		return &manager.WorkloadEvent{Type: tp, Workload: &manager.WorkloadInfo{
+			Kind:  manager.WorkloadInfo_DEPLOYMENT,
+			Name:  name,
+			State: state,
+		}}
+	}
+	s := &session{
+		managerClient: &scriptedWorkloadsManager{deltas: []*manager.WorkloadEventsDelta{
+			{Events: []*manager.WorkloadEvent{
+				wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "echo", manager.WorkloadInfo_PROGRESSING),
+				wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "other", manager.WorkloadInfo_AVAILABLE),
+			}},
+			{Events: []*manager.WorkloadEvent{
+				wl(manager.WorkloadEvent_MODIFIED, "echo", manager.WorkloadInfo_AVAILABLE),
+				wl(manager.WorkloadEvent_DELETED, "other", manager.WorkloadInfo_UNKNOWN_UNSPECIFIED),
+			}},
+		}},
+		managerVersion:   semver.MustParse("2.21.0"),
+		workloads:        make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces: make(map[string]struct{}),
+	}
+	evs := s.Events(ctx)
+	go func() {
+		_ = s.workloadsWatcher(ctx, "a", nil)
+	}()
+
+	expected := []*rpc.SessionEvent_WorkloadChanged{
+		{Type: rpc.SessionEvent_WorkloadChanged_ADDED, Name: "echo", State: "Progressing"},
+		{Type: rpc.SessionEvent_WorkloadChanged_ADDED, Name: "other", State: "Available"},
+		{Type: rpc.SessionEvent_WorkloadChanged_MODIFIED, Name: "echo", State: "Available"},
+		{Type: rpc.SessionEvent_WorkloadChanged_DELETED, Name: "other"},
+	}
+	for _, x := range expected {
+		wc := nextEvent(t, evs).GetWorkloadChanged()
+		require.NotNil(t, wc)
+		assert.Equal(t, x.Type, wc.Type)
+		assert.Equal(t, manager.WorkloadInfo_DEPLOYMENT, wc.Kind)
+		assert.Equal(t, x.Name, wc.Name)
+		assert.Equal(t, "a", wc.Namespace)
+		assert.Equal(t, x.State, wc.State)
+	}
+}
+
+func Test_session_Events_intercepts(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	evs := s.Events(ctx)
+	ii := func(disposition manager.InterceptDispositionType) *manager.InterceptInfo {
+		return &manager.InterceptInfo{
+			Id:          "1234:echo",
+			Spec:        &manager.InterceptSpec{Name: "echo", Agent: "echo", Namespace: "a"},
+			Disposition: disposition,
+		}
+	}
+
+	s.setCurrentIntercepts(ctx, []*manager.InterceptInfo{ii(manager.InterceptDispositionType_WAITING)})
+	// The second call changes nothing, so it emits no event.
+	s.setCurrentIntercepts(ctx, []*manager.InterceptInfo{ii(manager.InterceptDispositionType_WAITING)})
+	s.setCurrentIntercepts(ctx, []*manager.InterceptInfo{ii(manager.InterceptDispositionType_ACTIVE)})
+	s.setCurrentIntercepts(ctx, nil)
+
+	for _, x := range []struct {
+		tp          rpc.SessionEvent_InterceptChanged_Type
+		disposition manager.InterceptDispositionType
+	}{
+		{rpc.SessionEvent_InterceptChanged_STARTED, manager.InterceptDispositionType_WAITING},
+		{rpc.SessionEvent_InterceptChanged_UPDATED, manager.InterceptDispositionType_ACTIVE},
+		{rpc.SessionEvent_InterceptChanged_ENDED, manager.InterceptDispositionType_ACTIVE},
+	} {
+		ic := nextEvent(t, evs).GetInterceptChanged()
+		require.NotNil(t, ic)
+		assert.Equal(t, x.tp, ic.Type)
+		assert.Equal(t, "1234:echo", ic.Id)
+		assert.Equal(t, "echo", ic.Name)
+		assert.Equal(t, "echo", ic.Workload)
+		assert.Equal(t, "a", ic.Namespace)
+		assert.Equal(t, x.disposition, ic.Disposition)
+	}
+	assert.Empty(t, evs)
+}
+
+func Test_session_Events_dropOldest(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	evs := s.Events(ctx)
+	emit := func(ns string) {
+		s.emitEvent(ctx, &rpc.SessionEvent{Event: &rpc.SessionEvent_NamespacesChanged_{
+			NamespacesChanged: &rpc.SessionEvent_NamespacesChanged{Namespaces: []string{ns}},
+		}})
+	}
+	for i := range sessionEventBufferSize + 3 {
+		emit(string(rune('a' + i%26)))
+	}
+
+	// The three oldest events were dropped, and the newest events report the loss.
+	var dropped int32
+	for i := range sessionEventBufferSize {
+		ev := nextEvent(t, evs)
+		assert.Equal(t, []string{string(rune('a' + (i+3)%26))}, ev.GetNamespacesChanged().GetNamespaces())
+		dropped += ev.Dropped
+	}
+	assert.Equal(t, int32(3), dropped)
+	assert.Empty(t, evs)
+
+	// A subscriber that has caught up loses nothing.
+	emit("x")
+	assert.Zero(t, nextEvent(t, evs).Dropped)
+}
+
+func Test_session_Events_sessionEnded(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	subCtx, cancel := context.WithCancel(ctx)
+	cancelled := s.Events(subCtx)
+	evs := s.Events(ctx)
+
+	// A cancelled subscription is closed without receiving more events.
+	cancel()
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-cancelled:
+			return !ok
+		default:
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+
+	s.emitSessionEnded(ctx, errors.Join(ErrSessionExpired, errors.New("gone")))
+	se := nextEvent(t, evs).GetSessionEnded()
+	require.NotNil(t, se)
+	assert.True(t, se.Expired)
+	assert.Contains(t, se.Error, "gone")
+	_, ok := <-evs
+	assert.False(t, ok, "the event channel wasn't closed after SessionEnded")
+
+	// Nothing is emitted after the session has ended, and new subscriptions are closed right away.
+	s.emitSessionEnded(ctx, nil)
+	_, ok = <-s.Events(ctx)
+	assert.False(t, ok)
+}
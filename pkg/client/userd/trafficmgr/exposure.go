@@ -0,0 +1,237 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
+)
+
+// notExposedInfo tells why the pods of a workload have no ports that can be intercepted.
+type notExposedInfo struct {
+	code   rpc.WorkloadInfo_NotInterceptableCode
+	reason string
+}
+
+// namespaceExposure tells which workloads of a namespace have no ports that can be intercepted.
+type namespaceExposure struct {
+	// notExposed maps the workloads that have no ports that can be intercepted to the reason why.
+	notExposed map[workloadInfoKey]notExposedInfo
+
+	// err is the reason why the services and workloads of the namespace couldn't be listed, in which case
+	// notExposed is empty.
+	err error
+}
+
+// exposureResources are the resources whose informers are used by the exposure precheck, in addition to the
+// rollouts of namespaces that have Rollouts.
+var exposureResources = []string{"services", "deployments", "replicasets", "statefulsets"} //nolint:gochecknoglobals // constant
+
+// startExposureInformers starts the informers that cache the services and workloads of the namespace, and returns
+// the resources that they cache. The Rollouts informer is started when hasRollouts is true.
+func (ni *namespaceInformers) startExposureInformers(hasRollouts bool) []string {
+	kf := ni.factory.GetK8sInformerFactory()
+	ni.addInformer("services", kf.Core().V1().Services().Informer())
+	apps := kf.Apps().V1()
+	ni.addInformer("deployments", apps.Deployments().Informer())
+	ni.addInformer("replicasets", apps.ReplicaSets().Informer())
+	ni.addInformer("statefulsets", apps.StatefulSets().Informer())
+	kf.Start(ni.done)
+	resources := exposureResources
+	if hasRollouts {
+		af := ni.factory.GetArgoRolloutsInformerFactory()
+		ni.addInformer("rollouts", af.Argoproj().V1alpha1().Rollouts().Informer())
+		af.Start(ni.done)
+		resources = append(slices.Clone(resources), "rollouts")
+	}
+	return resources
+}
+
+// pruneExposure removes the exposure of all namespaces that aren't in the given list of current namespaces.
+func (s *session) pruneExposure(current []string) {
+	s.exposureLock.Lock()
+	defer s.exposureLock.Unlock()
+	for ns := range s.exposure {
+		if !slices.Contains(current, ns) {
+			delete(s.exposure, ns)
+		}
+	}
+}
+
+// refreshExposure refreshes the exposure of the workloads of the given namespaces using the informers that
+// cache their services and workloads. The precheck is skipped for a namespace when its services or workloads
+// cannot be listed, and the reason is reported by getExposureErrors.
+func (s *session) refreshExposure(ctx context.Context, namespaces []string) {
+	for _, ns := range namespaces {
+		// Only the watched workloads are listed, so Rollouts that the workload name prefixes leave out don't
+		// need the Rollouts informer.
+		hasRollouts := false
+		s.eachWorkload([]string{ns}, func(key workloadInfoKey, _ workloadInfo) {
+			if key.kind == manager.WorkloadInfo_ROLLOUT {
+				hasRollouts = true
+			}
+		})
+		ni := s.getNamespaceInformers(ctx, ns)
+		ne := &namespaceExposure{}
+		if ne.err = ni.waitForSync(ctx, ni.startExposureInformers(hasRollouts)...); ne.err == nil {
+			ne.notExposed, ne.err = ni.notExposedWorkloads(ns, hasRollouts)
+		}
+		if ne.err != nil {
+			dlog.Debugf(ctx, "unable to determine the exposure of the workloads in namespace %s: %v", ns, ne.err)
+		}
+		s.exposureLock.Lock()
+		if s.exposure == nil {
+			s.exposure = make(map[string]*namespaceExposure)
+		}
+		s.exposure[ns] = ne
+		s.exposureLock.Unlock()
+	}
+}
+
+// getNotExposed returns the workloads of the given namespaces that are known to have no ports that can be
+// intercepted, mapped to the reason why.
+func (s *session) getNotExposed(namespaces []string) map[workloadInfoKey]notExposedInfo {
+	notExposed := make(map[workloadInfoKey]notExposedInfo)
+	s.exposureLock.Lock()
+	for _, ns := range namespaces {
+		if ne, ok := s.exposure[ns]; ok {
+			for k, ni := range ne.notExposed {
+				notExposed[k] = ni
+			}
+		}
+	}
+	s.exposureLock.Unlock()
+	return notExposed
+}
+
+// getExposureErrors returns the namespaces among the given ones where the exposure of the workloads couldn't be
+// determined, mapped to the reason why.
+func (s *session) getExposureErrors(namespaces []string) map[string]string {
+	var errs map[string]string
+	s.exposureLock.Lock()
+	for _, ns := range namespaces {
+		if ne, ok := s.exposure[ns]; ok && ne.err != nil {
+			if errs == nil {
+				errs = make(map[string]string)
+			}
+			errs[ns] = ne.err.Error()
+		}
+	}
+	s.exposureLock.Unlock()
+	return errs
+}
+
+// notExposedWorkloads returns the workloads of the given namespace that have no ports that can be intercepted,
+// mapped to the reason why. The services and workloads are found in the caches of the informers.
+func (ni *namespaceInformers) notExposedWorkloads(namespace string, hasRollouts bool) (map[workloadInfoKey]notExposedInfo, error) {
+	kf := ni.factory.GetK8sInformerFactory()
+	svcs, err := kf.Core().V1().Services().Lister().Services(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	tpls := make(map[workloadInfoKey]*core.PodTemplateSpec)
+	key := func(kind manager.WorkloadInfo_Kind, name string) workloadInfoKey {
+		return workloadInfoKey{kind: kind, namespace: namespace, name: name}
+	}
+	apps := kf.Apps().V1()
+	dl, err := apps.Deployments().Lister().Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dl {
+		tpls[key(manager.WorkloadInfo_DEPLOYMENT, d.Name)] = &d.Spec.Template
+	}
+	rl, err := apps.ReplicaSets().Lister().ReplicaSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rl {
+		tpls[key(manager.WorkloadInfo_REPLICASET, r.Name)] = &r.Spec.Template
+	}
+	ssl, err := apps.StatefulSets().Lister().StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, ss := range ssl {
+		tpls[key(manager.WorkloadInfo_STATEFULSET, ss.Name)] = &ss.Spec.Template
+	}
+	if hasRollouts {
+		af := ni.factory.GetArgoRolloutsInformerFactory()
+		rtl, err := af.Argoproj().V1alpha1().Rollouts().Lister().Rollouts(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range rtl {
+			if tpl := &rt.Spec.Template; len(tpl.Spec.Containers) > 0 {
+				// A rollout that refers to the template of another workload is left out.
+				tpls[key(manager.WorkloadInfo_ROLLOUT, rt.Name)] = tpl
+			}
+		}
+	}
+
+	notExposed := make(map[workloadInfoKey]notExposedInfo)
+	for k, tpl := range tpls {
+		if ni, ok := notExposedReason(tpl, svcs); ok {
+			notExposed[k] = ni
+		}
+	}
+	return notExposed, nil
+}
+
+// notExposedReason returns why the pods of the given template have no ports that can be intercepted, and true,
+// or false when they have. Injection of the traffic-agent can be disabled using the inject-traffic-agent
+// annotation. Ports are exposed by the services that select the pods, or by the service that is named using
+// the inject-service-name annotation. Container ports are exposed using the inject-container-ports annotation.
+func notExposedReason(tpl *core.PodTemplateSpec, svcs []*core.Service) (notExposedInfo, bool) {
+	a := tpl.Annotations
+	if a[agentconfig.InjectAnnotation] == "disabled" {
+		return notExposedInfo{
+			code:   rpc.WorkloadInfo_EXCLUDED,
+			reason: fmt.Sprintf("Injection of the traffic-agent is disabled using annotation %s", agentconfig.InjectAnnotation),
+		}, true
+	}
+	if a[agentmap.ContainerPortsAnnotation] != "" {
+		return notExposedInfo{}, false
+	}
+	if svcName := a[agentmap.ServiceNameAnnotation]; svcName != "" {
+		for _, svc := range svcs {
+			if svc.Name == svcName {
+				if len(svc.Spec.Ports) > 0 {
+					return notExposedInfo{}, false
+				}
+				return notExposedInfo{code: rpc.WorkloadInfo_NOT_EXPOSED, reason: fmt.Sprintf("Service %s has no ports", svcName)}, true
+			}
+		}
+		return notExposedInfo{
+			code:   rpc.WorkloadInfo_NO_MATCHING_SERVICE,
+			reason: fmt.Sprintf("Service %s specified by annotation %s not found", svcName, agentmap.ServiceNameAnnotation),
+		}, true
+	}
+	selected := false
+	if len(tpl.Labels) > 0 {
+		lbs := labels.Set(tpl.Labels)
+		for _, svc := range svcs {
+			if sel := svc.Spec.Selector; len(sel) > 0 && labels.SelectorFromValidatedSet(sel).Matches(lbs) {
+				if len(svc.Spec.Ports) > 0 {
+					return notExposedInfo{}, false
+				}
+				selected = true
+			}
+		}
+	}
+	if selected {
+		return notExposedInfo{code: rpc.WorkloadInfo_NOT_EXPOSED, reason: "The services that select the workload have no ports"}, true
+	}
+	return notExposedInfo{
+		code:   rpc.WorkloadInfo_NO_MATCHING_SERVICE,
+		reason: "No service selects the workload, and no container ports are exposed using annotation " + agentmap.ContainerPortsAnnotation,
+	}, true
+}
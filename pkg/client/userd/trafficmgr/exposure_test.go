@@ -0,0 +1,174 @@
+package trafficmgr
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func exposureDeployment(name string, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: core.PodTemplateSpec{
+			ObjectMeta: meta.ObjectMeta{Labels: map[string]string{"app": name}, Annotations: annotations},
+		}},
+	}
+}
+
+func exposureService(name, app string, ports ...core.ServicePort) *core.Service {
+	return &core.Service{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       core.ServiceSpec{Selector: map[string]string{"app": app}, Ports: ports},
+	}
+}
+
+func Test_session_getInfosForWorkloads_notExposed(t *testing.T) {
+	http := core.ServicePort{Name: "http", Port: 80}
+	cs := fake.NewClientset(
+		exposureDeployment("echo", nil),
+		exposureDeployment("no-svc", nil),
+		exposureDeployment("no-ports", nil),
+		exposureDeployment("container-ports", map[string]string{agentmap.ContainerPortsAnnotation: "http"}),
+		exposureDeployment("named-svc", map[string]string{agentmap.ServiceNameAnnotation: "echo"}),
+		exposureDeployment("missing-svc", map[string]string{agentmap.ServiceNameAnnotation: "missing"}),
+		exposureDeployment("agent", nil),
+		exposureDeployment("excluded", map[string]string{agentconfig.InjectAnnotation: "disabled"}),
+		exposureService("echo", "echo", http),
+		exposureService("no-ports", "no-ports"),
+	)
+	var lists atomic.Int32
+	cs.PrependReactor("list", "services", func(k8stesting.Action) (bool, runtime.Object, error) {
+		lists.Add(1)
+		return false, nil, nil
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+
+	available := workloadInfo{state: workload.StateAvailable}
+	key := func(name string) workloadInfoKey {
+		return workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: name}
+	}
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{
+		key("echo"):            available,
+		key("no-svc"):          available,
+		key("no-ports"):        available,
+		key("container-ports"): available,
+		key("named-svc"):       available,
+		key("missing-svc"):     available,
+		key("agent"):           {state: workload.StateAvailable, agentState: manager.WorkloadInfo_INSTALLED},
+		key("excluded"):        available,
+		key("progressing"):     {state: workload.StateProgressing},
+	}}
+	t.Cleanup(func() { s.pruneNamespaceInformers(nil) })
+
+	s.refreshExposure(ctx, []string{"default"})
+	assert.Empty(t, s.getExposureErrors([]string{"default"}))
+	wiz, _ := s.getInfosForWorkloads([]string{"default"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+	codes := make(map[string]rpc.WorkloadInfo_NotInterceptableCode, len(wiz))
+	for _, wi := range wiz {
+		codes[wi.Name] = wi.NotInterceptableCode
+		if wi.NotInterceptableCode != rpc.WorkloadInfo_INTERCEPTABLE {
+			assert.NotEmpty(t, wi.NotInterceptableReason)
+		}
+	}
+	assert.Equal(t, map[string]rpc.WorkloadInfo_NotInterceptableCode{
+		"echo":            rpc.WorkloadInfo_INTERCEPTABLE,
+		"no-svc":          rpc.WorkloadInfo_NO_MATCHING_SERVICE,
+		"no-ports":        rpc.WorkloadInfo_NOT_EXPOSED,
+		"container-ports": rpc.WorkloadInfo_INTERCEPTABLE,
+		"named-svc":       rpc.WorkloadInfo_INTERCEPTABLE,
+		"missing-svc":     rpc.WorkloadInfo_NO_MATCHING_SERVICE,
+		"agent":           rpc.WorkloadInfo_INTERCEPTABLE,
+		"excluded":        rpc.WorkloadInfo_EXCLUDED,
+		"progressing":     rpc.WorkloadInfo_PROGRESSING,
+	}, codes)
+
+	// The services are listed once. Later refreshes use the informer cache.
+	s.refreshExposure(ctx, []string{"default"})
+	assert.Equal(t, int32(1), lists.Load())
+
+	// A service that is added is found by a later refresh.
+	_, err := cs.CoreV1().Services("default").Create(ctx, exposureService("no-svc", "no-svc", http), meta.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		s.refreshExposure(ctx, []string{"default"})
+		_, ok := s.getNotExposed([]string{"default"})[key("no-svc")]
+		return !ok
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int32(1), lists.Load())
+
+	// The informers of namespaces that are no longer current are stopped.
+	s.pruneExposure([]string{"other"})
+	s.pruneNamespaceInformers([]string{"other"})
+	assert.Empty(t, s.namespaceInformers)
+	assert.Empty(t, s.getNotExposed([]string{"default"}))
+}
+
+func Test_session_refreshExposure_forbidden(t *testing.T) {
+	cs := fake.NewClientset(exposureDeployment("no-svc", nil))
+	cs.PrependReactor("list", "services", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(core.Resource("services"), "", fmt.Errorf("access denied"))
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+
+	key := workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "no-svc"}
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{key: {state: workload.StateAvailable}}}
+	t.Cleanup(func() { s.pruneNamespaceInformers(nil) })
+
+	// The error is reported, and the workload isn't considered to be not exposed.
+	s.refreshExposure(ctx, []string{"default"})
+	errs := s.getExposureErrors([]string{"default"})
+	require.Contains(t, errs, "default")
+	assert.Contains(t, errs["default"], "unable to list services")
+	wiz, _ := s.getInfosForWorkloads([]string{"default"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+	require.Len(t, wiz, 1)
+	assert.Equal(t, rpc.WorkloadInfo_INTERCEPTABLE, wiz[0].NotInterceptableCode)
+}
+
+func Test_notExposedReason(t *testing.T) {
+	tpl := &core.PodTemplateSpec{ObjectMeta: meta.ObjectMeta{Labels: map[string]string{"app": "echo"}}}
+	ni, ok := notExposedReason(tpl, nil)
+	assert.True(t, ok)
+	assert.Equal(t, notExposedInfo{
+		code:   rpc.WorkloadInfo_NO_MATCHING_SERVICE,
+		reason: "No service selects the workload, and no container ports are exposed using annotation " + agentmap.ContainerPortsAnnotation,
+	}, ni)
+
+	ni, ok = notExposedReason(tpl, []*core.Service{exposureService("echo", "echo")})
+	assert.True(t, ok)
+	assert.Equal(t, notExposedInfo{code: rpc.WorkloadInfo_NOT_EXPOSED, reason: "The services that select the workload have no ports"}, ni)
+
+	_, ok = notExposedReason(tpl, []*core.Service{exposureService("echo", "echo", core.ServicePort{Port: 80})})
+	assert.False(t, ok)
+
+	ni, ok = notExposedReason(&core.PodTemplateSpec{}, []*core.Service{exposureService("echo", "echo", core.ServicePort{Port: 80})})
+	assert.True(t, ok)
+	assert.Equal(t, rpc.WorkloadInfo_NO_MATCHING_SERVICE, ni.code)
+
+	tpl.Annotations = map[string]string{agentconfig.InjectAnnotation: "disabled"}
+	ni, ok = notExposedReason(tpl, []*core.Service{exposureService("echo", "echo", core.ServicePort{Port: 80})})
+	assert.True(t, ok)
+	assert.Equal(t, rpc.WorkloadInfo_EXCLUDED, ni.code)
+}
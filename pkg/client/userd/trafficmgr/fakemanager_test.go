@@ -0,0 +1,330 @@
+package trafficmgr
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	rootdRpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+// fakeStream is a stream that delivers the given messages, and then blocks until its context is done.
+type fakeStream[T any] struct {
+	grpc.ServerStreamingClient[T]
+	ctx  context.Context
+	msgs []*T
+}
+
+func (s *fakeStream[T]) Recv() (*T, error) {
+	if len(s.msgs) > 0 {
+		msg := s.msgs[0]
+		s.msgs = s.msgs[1:]
+		return msg, nil
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+// fakeManager is an in-memory traffic-manager that serves the calls made by the services of a session. Each
+// watcher receives one snapshot of the agents and intercepts, and then blocks until its context is done.
+type fakeManager struct {
+	manager.ManagerClient
+	sync.Mutex
+	agents     []*manager.AgentInfo
+	intercepts []*manager.InterceptInfo
+
+	remains          atomic.Int32
+	lastRemain       atomic.Pointer[manager.RemainRequest]
+	departs          atomic.Int32
+	agentWatches     atomic.Int32
+	interceptWatches atomic.Int32
+	dialWatches      atomic.Int32
+	removes          atomic.Int32
+
+	// removeErr is returned by RemoveIntercept.
+	removeErr error
+}
+
+func (m *fakeManager) Remain(_ context.Context, rr *manager.RemainRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.remains.Add(1)
+	m.lastRemain.Store(rr)
+	return &emptypb.Empty{}, nil
+}
+
+func (m *fakeManager) Depart(context.Context, *manager.SessionInfo, ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.departs.Add(1)
+	return &emptypb.Empty{}, nil
+}
+
+func (m *fakeManager) RemoveIntercept(context.Context, *manager.RemoveInterceptRequest2, ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.removes.Add(1)
+	if m.removeErr != nil {
+		return nil, m.removeErr
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *fakeManager) WatchAgents(ctx context.Context, _ *manager.SessionInfo, _ ...grpc.CallOption) (manager.Manager_WatchAgentsClient, error) {
+	m.agentWatches.Add(1)
+	m.Lock()
+	defer m.Unlock()
+	return &fakeStream[manager.AgentInfoSnapshot]{ctx: ctx, msgs: []*manager.AgentInfoSnapshot{{Agents: m.agents}}}, nil
+}
+
+func (m *fakeManager) WatchIntercepts(ctx context.Context, _ *manager.SessionInfo, _ ...grpc.CallOption) (manager.Manager_WatchInterceptsClient, error) {
+	m.interceptWatches.Add(1)
+	m.Lock()
+	defer m.Unlock()
+	return &fakeStream[manager.InterceptInfoSnapshot]{ctx: ctx, msgs: []*manager.InterceptInfoSnapshot{{Intercepts: m.intercepts}}}, nil
+}
+
+func (m *fakeManager) WatchDial(ctx context.Context, _ *manager.SessionInfo, _ ...grpc.CallOption) (manager.Manager_WatchDialClient, error) {
+	m.dialWatches.Add(1)
+	return &fakeStream[manager.DialRequest]{ctx: ctx}, nil
+}
+
+// fakeWorkloadsManager is a traffic-manager whose workload streams deliver their initial sync when released.
+type fakeWorkloadsManager struct {
+	manager.ManagerClient
+	watches atomic.Int32
+	release chan struct{}
+}
+
+type fakeWorkloadsStream struct {
+	manager.Manager_WatchWorkloadsClient
+	ctx     context.Context
+	release <-chan struct{}
+	synced  bool
+}
+
+func (m *fakeWorkloadsManager) WatchWorkloads(ctx context.Context, _ *manager.WorkloadEventsRequest, _ ...grpc.CallOption) (manager.Manager_WatchWorkloadsClient, error) {
+	m.watches.Add(1)
+	return &fakeWorkloadsStream{ctx: ctx, release: m.release}, nil
+}
+
+func (s *fakeWorkloadsStream) Recv() (*manager.WorkloadEventsDelta, error) {
+	if !s.synced {
+		select {
+		case <-s.release:
+			s.synced = true
+			return &manager.WorkloadEventsDelta{}, nil
+		case <-s.ctx.Done():
+		}
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+// failingWorkloadsManager is a fakeWorkloadsManager whose workload watches fail while failing is set.
+type failingWorkloadsManager struct {
+	fakeWorkloadsManager
+	failing atomic.Bool
+}
+
+func (m *failingWorkloadsManager) WatchWorkloads(ctx context.Context, rq *manager.WorkloadEventsRequest, opts ...grpc.CallOption) (manager.Manager_WatchWorkloadsClient, error) {
+	if m.failing.Load() {
+		m.watches.Add(1)
+		return nil, status.Error(codes.PermissionDenied, "workloads is forbidden")
+	}
+	return m.fakeWorkloadsManager.WatchWorkloads(ctx, rq, opts...)
+}
+
+// steppedWorkloadsManager is a traffic-manager whose workload streams deliver the deltas that are sent to it.
+type steppedWorkloadsManager struct {
+	manager.ManagerClient
+	deltas chan *manager.WorkloadEventsDelta
+}
+
+type steppedWorkloadsStream struct {
+	manager.Manager_WatchWorkloadsClient
+	ctx    context.Context
+	deltas <-chan *manager.WorkloadEventsDelta
+}
+
+func (m *steppedWorkloadsManager) WatchWorkloads(ctx context.Context, _ *manager.WorkloadEventsRequest, _ ...grpc.CallOption) (manager.Manager_WatchWorkloadsClient, error) {
+	return &steppedWorkloadsStream{ctx: ctx, deltas: m.deltas}, nil
+}
+
+func (s *steppedWorkloadsStream) Recv() (*manager.WorkloadEventsDelta, error) {
+	select {
+	case d := <-s.deltas:
+		return d, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// scriptedWorkloadsManager is a traffic-manager whose workload streams deliver the given deltas.
+type scriptedWorkloadsManager struct {
+	manager.ManagerClient
+	deltas []*manager.WorkloadEventsDelta
+}
+
+func (m *scriptedWorkloadsManager) WatchWorkloads(ctx context.Context, _ *manager.WorkloadEventsRequest, _ ...grpc.CallOption) (manager.Manager_WatchWorkloadsClient, error) {
+	return &fakeStream[manager.WorkloadEventsDelta]{ctx: ctx, msgs: m.deltas}, nil
+}
+
+// newTestSession returns a session that is wired to the given traffic-manager and root daemon, together with
+// a context that has a fake clock, the default configuration, a fake user daemon service, and a temporary
+// user cache. The session's services can be started using RunSession.
+func newTestSession(t *testing.T, mc manager.ManagerClient, rd rootdRpc.DaemonClient) (context.Context, *session, *clocktesting.FakeClock) {
+	t.Helper()
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx := filelocation.WithAppUserCacheDir(dlog.NewTestContext(t, false), t.TempDir())
+	ctx = client.WithClock(client.WithConfig(ctx, client.GetDefaultConfig()), fc)
+	ctx = userd.WithService(ctx, &fakeService{})
+
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	s := initSession(&session{
+		daemonID:         daemonID,
+		clientID:         "test@localhost",
+		managerClient:    mc,
+		managerNamespace: "ambassador",
+		managerName:      "Traffic Manager",
+		sessionInfo:      &manager.SessionInfo{SessionId: "session"},
+		rootDaemon:       rd,
+	})
+	return ctx, s, fc
+}
+
+func Test_session_RunSession(t *testing.T) {
+	mgr := &fakeManager{intercepts: []*manager.InterceptInfo{waitingIntercept("a")}}
+	rd := &fakeRootDaemon{networkUp: true}
+	ctx, s, fc := newTestSession(t, mgr, rd)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- s.RunSession(ctx) }()
+
+	// The watchers subscribe, and the intercepts of the traffic-manager become the current intercepts.
+	require.Eventually(t, func() bool {
+		return mgr.agentWatches.Load() == 1 && mgr.interceptWatches.Load() == 1 && mgr.dialWatches.Load() == 1
+	}, 5*time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return len(s.getCurrentIntercepts()) == 1 }, 5*time.Second, time.Millisecond)
+
+	// The session is kept alive.
+	for i := int32(1); i <= 2; i++ {
+		waitForWaiters(t, fc)
+		fc.Step(remainInterval)
+		require.Eventually(t, func() bool { return mgr.remains.Load() >= i }, 5*time.Second, time.Millisecond)
+	}
+	assert.Zero(t, mgr.departs.Load())
+
+	// Ending the session departs from the traffic-manager and disconnects the root daemon.
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunSession didn't return when its context was cancelled")
+	}
+	assert.Equal(t, int32(1), mgr.departs.Load())
+	assert.Equal(t, 1, rd.disconnects)
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("session isn't done")
+	}
+}
+
+func Test_session_RunSession_maxLifetime(t *testing.T) {
+	mgr := &fakeManager{}
+	rd := &fakeRootDaemon{networkUp: true}
+	ctx, s, fc := newTestSession(t, mgr, rd)
+	client.GetConfig(ctx).Session().MaxLifetime = time.Minute
+	start := fc.Now()
+	evs := s.Events(ctx)
+
+	done := make(chan error, 1)
+	go func() { done <- s.RunSession(ctx) }()
+	require.Eventually(t, func() bool { return mgr.agentWatches.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	// The session ends by itself once it has been alive for its max lifetime.
+	var err error
+	require.Eventually(t, func() bool {
+		select {
+		case err = <-done:
+			return true
+		default:
+			fc.Step(time.Second)
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	require.ErrorIs(t, err, ErrSessionMaxLifetime)
+	assert.False(t, fc.Now().Before(start.Add(time.Minute)), "the session ended before its max lifetime")
+	assert.Equal(t, int32(1), mgr.departs.Load())
+
+	var last *rpc.SessionEvent
+	for ev := range evs {
+		last = ev
+	}
+	require.NotNil(t, last.GetSessionEnded())
+	assert.False(t, last.GetSessionEnded().Expired)
+	assert.Contains(t, last.GetSessionEnded().Error, "session reached max lifetime")
+}
+
+func Test_session_NewRemainRequest(t *testing.T) {
+	mgr := &fakeManager{}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+
+	// By default, the request contains just the session.
+	require.NoError(t, s.Remain(ctx))
+	assert.True(t, proto.Equal(&manager.RemainRequest{Session: s.SessionInfo()}, mgr.lastRemain.Load()))
+
+	org := AmendRemainRequestFunc
+	t.Cleanup(func() { AmendRemainRequestFunc = org })
+	AmendRemainRequestFunc = func(us userd.Session, rr *manager.RemainRequest) {
+		assert.Same(t, s, us)
+		rr.ApiKey = "key-" + us.SessionInfo().SessionId
+	}
+	require.NoError(t, s.Remain(ctx))
+	assert.Equal(t, "key-session", mgr.lastRemain.Load().ApiKey)
+	assert.Equal(t, int32(2), mgr.remains.Load())
+}
+
+func Test_session_Remain_errorCodes(t *testing.T) {
+	mgr := &fakeDroppingManager{}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+	remain := func(ctx context.Context, code codes.Code) error {
+		mgr.remainErr = status.Error(code, "remain failed")
+		return s.Remain(ctx)
+	}
+
+	// By default, NotFound and Unavailable expire the session, and all other codes are logged.
+	for _, code := range []codes.Code{codes.NotFound, codes.Unavailable} {
+		assert.ErrorIs(t, remain(ctx, code), ErrSessionExpired, code.String())
+	}
+	for _, code := range []codes.Code{codes.DeadlineExceeded, codes.Internal, codes.Unknown} {
+		assert.NoError(t, remain(ctx, code), code.String())
+	}
+
+	// Transient codes take precedence over expired codes.
+	cfg := client.GetDefaultConfig()
+	cfg.Session().ExpiredCodes = []string{"NOT_FOUND", "Unavailable", "internal"}
+	cfg.Session().TransientCodes = []string{"Unavailable"}
+	ctx = client.WithConfig(ctx, cfg)
+	assert.ErrorIs(t, remain(ctx, codes.NotFound), ErrSessionExpired)
+	assert.ErrorIs(t, remain(ctx, codes.Internal), ErrSessionExpired)
+	assert.NoError(t, remain(ctx, codes.Unavailable))
+	assert.NoError(t, remain(ctx, codes.DeadlineExceeded))
+}
@@ -0,0 +1,70 @@
+package trafficmgr
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+)
+
+// ActiveForwarders returns a snapshot of the intercepts, ingests, and agent REST API servers that are
+// active in this session, sorted by kind and id.
+func (s *session) ActiveForwarders(context.Context) []*rpc.Forwarder {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+
+	fws := make([]*rpc.Forwarder, 0, len(s.currentIntercepts)+s.currentIngests.Size()+len(s.currentAPIServers))
+	for _, ic := range s.currentIntercepts {
+		spec := ic.Spec
+		fw := &rpc.Forwarder{
+			Kind:             rpc.Forwarder_INTERCEPT,
+			Id:               ic.Id,
+			LocalAddress:     net.JoinHostPort(spec.TargetHost, strconv.Itoa(int(spec.TargetPort))),
+			LocalPorts:       spec.LocalPorts,
+			State:            ic.Disposition.String(),
+			Pid:              int32(ic.pid),
+			HandlerContainer: ic.handlerContainer,
+		}
+		if ic.PodIp != "" {
+			fw.Target = net.JoinHostPort(ic.PodIp, strconv.Itoa(int(spec.ContainerPort)))
+		}
+		fws = append(fws, fw)
+	}
+	s.currentIngests.Range(func(key ingestKey, ig *ingest) bool {
+		state := "ACTIVE"
+		if ig.ctx.Err() != nil {
+			state = "ENDED"
+		}
+		fws = append(fws, &rpc.Forwarder{
+			Kind:             rpc.Forwarder_INGEST,
+			Id:               key.workload + "/" + key.container,
+			LocalPorts:       ig.localPorts,
+			Target:           ig.PodIp,
+			State:            state,
+			Pid:              int32(ig.pid),
+			HandlerContainer: ig.handlerContainer,
+		})
+		return true
+	})
+	for port, as := range s.currentAPIServers {
+		state := "ACTIVE"
+		if as.err != nil {
+			state = "FAILED"
+		}
+		fws = append(fws, &rpc.Forwarder{
+			Kind:         rpc.Forwarder_API_SERVER,
+			Id:           strconv.Itoa(port),
+			LocalAddress: ":" + strconv.Itoa(port),
+			State:        state,
+		})
+	}
+	sort.Slice(fws, func(i, j int) bool {
+		if fws[i].Kind != fws[j].Kind {
+			return fws[i].Kind < fws[j].Kind
+		}
+		return fws[i].Id < fws[j].Id
+	})
+	return fws
+}
@@ -0,0 +1,76 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func Test_session_ActiveForwarders(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	igCtx, igCancel := context.WithCancel(ctx)
+	s := &session{
+		currentIngests: xsync.NewMapOf[ingestKey, *ingest](),
+		currentIntercepts: map[string]*intercept{
+			"b": {InterceptInfo: &manager.InterceptInfo{
+				Id:          "b",
+				Disposition: manager.InterceptDispositionType_WAITING,
+				Spec:        &manager.InterceptSpec{TargetHost: "127.0.0.1", TargetPort: 8080},
+			}},
+			"a": {
+				InterceptInfo: &manager.InterceptInfo{
+					Id:          "a",
+					Disposition: manager.InterceptDispositionType_ACTIVE,
+					PodIp:       "10.1.0.4",
+					Spec: &manager.InterceptSpec{
+						TargetHost:    "127.0.0.1",
+						TargetPort:    9000,
+						ContainerPort: 8080,
+						LocalPorts:    []string{"5432"},
+					},
+				},
+				pid: 1234,
+			},
+		},
+		currentAPIServers: map[int]*apiServer{9900: {}},
+	}
+	s.currentIngests.Store(ingestKey{workload: "echo", container: "echo-server"}, &ingest{
+		AgentInfo:  &manager.AgentInfo{PodIp: "10.1.0.5"},
+		ctx:        igCtx,
+		localPorts: []string{"8081"},
+	})
+
+	fws := s.ActiveForwarders(ctx)
+	require.Len(t, fws, 4)
+
+	assert.Equal(t, rpc.Forwarder_INTERCEPT, fws[0].Kind)
+	assert.Equal(t, "a", fws[0].Id)
+	assert.Equal(t, "127.0.0.1:9000", fws[0].LocalAddress)
+	assert.Equal(t, "10.1.0.4:8080", fws[0].Target)
+	assert.Equal(t, []string{"5432"}, fws[0].LocalPorts)
+	assert.Equal(t, "ACTIVE", fws[0].State)
+	assert.Equal(t, int32(1234), fws[0].Pid)
+
+	assert.Equal(t, "b", fws[1].Id)
+	assert.Equal(t, "WAITING", fws[1].State)
+	assert.Empty(t, fws[1].Target)
+
+	assert.Equal(t, rpc.Forwarder_INGEST, fws[2].Kind)
+	assert.Equal(t, "echo/echo-server", fws[2].Id)
+	assert.Equal(t, "10.1.0.5", fws[2].Target)
+	assert.Equal(t, "ACTIVE", fws[2].State)
+
+	assert.Equal(t, rpc.Forwarder_API_SERVER, fws[3].Kind)
+	assert.Equal(t, "9900", fws[3].Id)
+
+	igCancel()
+	fws = s.ActiveForwarders(ctx)
+	assert.Equal(t, "ENDED", fws[2].State)
+}
@@ -0,0 +1,142 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// Reasons why an intercept ended, as recorded in the intercept history.
+const (
+	endReasonLeave     = "leave"
+	endReasonIdle      = "idle"
+	endReasonRollback  = "rollback"
+	endReasonUninstall = "uninstall"
+	endReasonCleared   = "cleared"
+)
+
+type endReasonKey struct{}
+
+// withEndReason returns a context that makes RemoveIntercept record the given reason in the intercept history.
+func withEndReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, endReasonKey{}, reason)
+}
+
+// endReason returns the reason set using withEndReason, or endReasonLeave when no reason was set.
+func endReason(ctx context.Context) string {
+	if reason, ok := ctx.Value(endReasonKey{}).(string); ok {
+		return reason
+	}
+	return endReasonLeave
+}
+
+// interceptHistory is a ring buffer of the intercepts that have ended in a session. A nil history records
+// nothing.
+type interceptHistory struct {
+	sync.Mutex
+	entries []*rpc.EndedIntercept
+	next    int
+}
+
+func newInterceptHistory(size int) *interceptHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &interceptHistory{entries: make([]*rpc.EndedIntercept, 0, size)}
+}
+
+// add records that the given intercept ended at the given time, replacing the oldest entry when the
+// history is full.
+func (h *interceptHistory) add(ic *intercept, reason string, endedAt time.Time) {
+	if h == nil {
+		return
+	}
+	spec := ic.Spec
+	e := &rpc.EndedIntercept{
+		Id:             ic.Id,
+		Name:           spec.Name,
+		Workload:       spec.Agent,
+		Namespace:      spec.Namespace,
+		PortIdentifier: spec.PortIdentifier,
+		Target:         fmt.Sprintf("%s:%d", spec.TargetHost, spec.TargetPort),
+		EndedAt:        timestamppb.New(endedAt),
+		Reason:         reason,
+	}
+	if !ic.activeSince.IsZero() {
+		e.StartedAt = timestamppb.New(ic.activeSince)
+	}
+	h.Lock()
+	defer h.Unlock()
+	if len(h.entries) < cap(h.entries) {
+		h.entries = append(h.entries, e)
+	} else {
+		h.entries[h.next] = e
+		h.next = (h.next + 1) % len(h.entries)
+	}
+}
+
+// recent returns the recorded intercepts, most recent first.
+func (h *interceptHistory) recent() []*rpc.EndedIntercept {
+	if h == nil {
+		return nil
+	}
+	h.Lock()
+	defer h.Unlock()
+	n := len(h.entries)
+	es := make([]*rpc.EndedIntercept, n)
+	for i := range n {
+		// The most recent entry is the one before next.
+		es[i] = h.entries[(h.next-1-i+2*n)%n]
+	}
+	return es
+}
+
+// RecentIntercepts returns the intercepts that ended recently in this session, most recent first.
+func (s *session) RecentIntercepts(context.Context) []*rpc.EndedIntercept {
+	return s.interceptHistory.recent()
+}
+
+// recordEndedIntercept adds the given intercept to the intercept history.
+func (s *session) recordEndedIntercept(ctx context.Context, ic *intercept, reason string) {
+	s.interceptHistory.add(ic, reason, client.GetClock(ctx).Now())
+}
+
+// WorkloadInterceptHistory returns the intercepts of a workload that the traffic-manager has recorded, regardless
+// of what client created them. The response is empty, with a note that explains why, when the traffic-manager
+// doesn't record the intercept history of workloads.
+func (s *session) WorkloadInterceptHistory(
+	ctx context.Context,
+	rq *rpc.WorkloadInterceptHistoryRequest,
+) (*rpc.WorkloadInterceptHistoryResponse, error) {
+	ns := s.ActualNamespace(rq.Namespace)
+	if ns == "" {
+		if rq.Namespace == "" {
+			rq.Namespace = s.Namespace
+		}
+		return nil, errcat.User.Newf("namespace %s is not mapped", rq.Namespace)
+	}
+	h, err := s.ManagerClient().GetWorkloadInterceptHistory(ctx, &manager.WorkloadInterceptHistoryRequest{
+		Session:   s.SessionInfo(),
+		Name:      rq.Name,
+		Namespace: ns,
+	})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return &rpc.WorkloadInterceptHistoryResponse{
+				Note: fmt.Sprintf("traffic-manager version %s doesn't record the intercept history of workloads", s.ManagerVersion()),
+			}, nil
+		}
+		return nil, err
+	}
+	return &rpc.WorkloadInterceptHistoryResponse{Entries: h.Entries}, nil
+}
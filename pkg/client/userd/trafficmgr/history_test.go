@@ -0,0 +1,148 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	auth "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func Test_endReason(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, endReasonLeave, endReason(ctx))
+	assert.Equal(t, endReasonIdle, endReason(withEndReason(ctx, endReasonIdle)))
+}
+
+func Test_session_RecentIntercepts(t *testing.T) {
+	start := time.Now()
+	fc := clocktesting.NewFakeClock(start)
+	ctx := client.WithClock(dlog.NewTestContext(t, false), fc)
+
+	// A session without history records nothing.
+	s := &session{}
+	s.recordEndedIntercept(ctx, activeIntercept("a", 8080, start, 0), endReasonLeave)
+	assert.Empty(t, s.RecentIntercepts(ctx))
+
+	s = &session{interceptHistory: newInterceptHistory(2)}
+	s.recordEndedIntercept(ctx, activeIntercept("a", 8080, start, 0), endReasonLeave)
+	fc.Step(time.Second)
+	s.recordEndedIntercept(ctx, activeIntercept("b", 8081, start, 0), endReasonIdle)
+
+	eis := s.RecentIntercepts(ctx)
+	require.Len(t, eis, 2)
+	assert.Equal(t, "b", eis[0].Name)
+	assert.Equal(t, endReasonIdle, eis[0].Reason)
+	assert.Equal(t, "127.0.0.1:8081", eis[0].Target)
+	assert.WithinDuration(t, start, eis[0].StartedAt.AsTime(), 0)
+	assert.WithinDuration(t, fc.Now(), eis[0].EndedAt.AsTime(), 0)
+	assert.Equal(t, "a", eis[1].Name)
+
+	// The oldest entries are dropped when the history is full.
+	s.recordEndedIntercept(ctx, activeIntercept("c", 8082, start, 0), endReasonRollback)
+	s.recordEndedIntercept(ctx, activeIntercept("d", 8083, time.Time{}, 0), endReasonUninstall)
+	eis = s.RecentIntercepts(ctx)
+	require.Len(t, eis, 2)
+	assert.Equal(t, "d", eis[0].Name)
+	assert.Nil(t, eis[0].StartedAt)
+	assert.Equal(t, "c", eis[1].Name)
+}
+
+// historyManager is a fakeManager that returns the given intercept history, or the given error.
+type historyManager struct {
+	fakeManager
+	entries []*manager.InterceptHistoryEntry
+	err     error
+	lastReq *manager.WorkloadInterceptHistoryRequest
+}
+
+func (m *historyManager) GetWorkloadInterceptHistory(
+	_ context.Context,
+	rq *manager.WorkloadInterceptHistoryRequest,
+	_ ...grpc.CallOption,
+) (*manager.WorkloadInterceptHistory, error) {
+	m.lastReq = rq
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &manager.WorkloadInterceptHistory{Entries: m.entries}, nil
+}
+
+func Test_session_WorkloadInterceptHistory(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	mgr := &historyManager{entries: []*manager.InterceptHistoryEntry{
+		{Name: "echo-b", Client: "bob@laptop", PortIdentifier: "http", StartedAt: timestamppb.New(start.Add(30 * time.Minute))},
+		{
+			Name:           "echo-a",
+			Client:         "alice@desktop",
+			PortIdentifier: "http",
+			StartedAt:      timestamppb.New(start),
+			EndedAt:        timestamppb.New(start.Add(10 * time.Minute)),
+		},
+	}}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+	s.managerVersion = semver.MustParse("2.22.0")
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "b"}}
+	s.SetMappedNamespaces(ctx, []string{"a", "b"})
+
+	// The namespace of the session is used when no namespace is given.
+	rsp, err := s.WorkloadInterceptHistory(ctx, &rpc.WorkloadInterceptHistoryRequest{Name: "echo"})
+	require.NoError(t, err)
+	assert.Empty(t, rsp.Note)
+	require.Len(t, rsp.Entries, 2)
+	assert.Equal(t, "bob@laptop", rsp.Entries[0].Client)
+	assert.Nil(t, rsp.Entries[0].EndedAt)
+	assert.Equal(t, "alice@desktop", rsp.Entries[1].Client)
+	assert.Equal(t, 10*time.Minute, rsp.Entries[1].EndedAt.AsTime().Sub(rsp.Entries[1].StartedAt.AsTime()))
+	assert.Equal(t, "echo", mgr.lastReq.Name)
+	assert.Equal(t, "b", mgr.lastReq.Namespace)
+	assert.Equal(t, "session", mgr.lastReq.Session.SessionId)
+
+	_, err = s.WorkloadInterceptHistory(ctx, &rpc.WorkloadInterceptHistoryRequest{Name: "echo", Namespace: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "a", mgr.lastReq.Namespace)
+
+	// A namespace that isn't mapped is a user error.
+	_, err = s.WorkloadInterceptHistory(ctx, &rpc.WorkloadInterceptHistoryRequest{Name: "echo", Namespace: "c"})
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+
+	// An older traffic-manager that doesn't record the history results in an empty response with a note.
+	mgr.err = status.Error(codes.Unimplemented, "")
+	rsp, err = s.WorkloadInterceptHistory(ctx, &rpc.WorkloadInterceptHistoryRequest{Name: "echo"})
+	require.NoError(t, err)
+	assert.Empty(t, rsp.Entries)
+	assert.Contains(t, rsp.Note, "2.22.0")
+
+	mgr.err = status.Error(codes.Unavailable, "connection lost")
+	_, err = s.WorkloadInterceptHistory(ctx, &rpc.WorkloadInterceptHistoryRequest{Name: "echo"})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,201 @@
+package trafficmgr
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+)
+
+// idleSweepInterval is the interval between checks for intercepts that have exceeded their idle timeout.
+const idleSweepInterval = 5 * time.Second
+
+// idleCandidate is an active intercept that has an idle timeout.
+type idleCandidate struct {
+	id          string
+	name        string
+	targetHost  string
+	targetPort  uint16
+	activeSince time.Time
+	idleTimeout time.Duration
+}
+
+// resolvedTarget is the result of resolving the target host of an intercept.
+type resolvedTarget struct {
+	addrs []netip.Addr
+	err   error
+}
+
+// idleTargets are the resolved target hosts of the idle candidates, keyed by intercept ID, so that the target
+// host of an intercept is resolved once.
+type idleTargets map[string]resolvedTarget
+
+// idleInterceptsLoop periodically removes the active intercepts that haven't had any connections within
+// their idle timeout.
+func (s *session) idleInterceptsLoop(ctx context.Context) error {
+	clk := client.GetClock(ctx)
+	ticker := clk.NewTicker(idleSweepInterval)
+	defer ticker.Stop()
+	targets := make(idleTargets)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+		}
+		s.removeIdleIntercepts(ctx, clk.Now(), targets)
+	}
+}
+
+func (s *session) removeIdleIntercepts(ctx context.Context, now time.Time, targets idleTargets) {
+	ics := s.idleCandidates()
+	targets.retain(ics)
+	if len(ics) == 0 {
+		// No need to ask the root daemon for its dial activity.
+		return
+	}
+	for _, ic := range idleIntercepts(ctx, now, ics, s.lastDials(ctx, now), targets) {
+		dlog.Infof(ctx, "Removing intercept %s because it has been idle for more than %s", ic.name, ic.idleTimeout)
+		if err := s.self.RemoveIntercept(withEndReason(ctx, endReasonIdle), ic.name); err != nil {
+			dlog.Errorf(ctx, "unable to remove idle intercept %s: %v", ic.name, err)
+			continue
+		}
+		s.recordIdleRemoval(ic, now)
+		scout.Report(ctx, "intercept_idle_removed", scout.Entry{Key: "idle_timeout", Value: ic.idleTimeout.String()})
+	}
+}
+
+// recordIdleRemoval records that the given intercept was removed because it was idle, so that the
+// removal is reported by the session status.
+func (s *session) recordIdleRemoval(ic idleCandidate, now time.Time) {
+	ir := &rpc.IdleRemovedIntercept{
+		Name:        ic.name,
+		IdleTimeout: durationpb.New(ic.idleTimeout),
+		RemovedAt:   timestamppb.New(now),
+	}
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	for i, r := range s.idleRemoved {
+		if r.Name == ic.name {
+			s.idleRemoved[i] = ir
+			return
+		}
+	}
+	s.idleRemoved = append(s.idleRemoved, ir)
+}
+
+// getIdleRemoved returns the intercepts that were removed because they were idle.
+func (s *session) getIdleRemoved() []*rpc.IdleRemovedIntercept {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	return append([]*rpc.IdleRemovedIntercept(nil), s.idleRemoved...)
+}
+
+// lastDials returns the time of the most recent activity for each destination, merged from the dial
+// requests received by this session and those received by the root daemon. Destinations that have
+// connections that haven't ended are active at the given time.
+func (s *session) lastDials(ctx context.Context, now time.Time) map[netip.AddrPort]time.Time {
+	lds := s.dialActivity.Snapshot(now)
+	if s.rootDaemon == nil {
+		return lds
+	}
+	da, err := s.rootDaemon.GetDialActivity(ctx, &emptypb.Empty{})
+	if err != nil {
+		dlog.Debugf(ctx, "unable to get dial activity from root daemon: %v", err)
+		return lds
+	}
+	for k, v := range da.LastDial {
+		ap, err := netip.ParseAddrPort(k)
+		if err != nil {
+			continue
+		}
+		if t := v.AsTime(); t.After(lds[ap]) {
+			lds[ap] = t
+		}
+	}
+	return lds
+}
+
+// idleCandidates returns the active intercepts that have an idle timeout.
+func (s *session) idleCandidates() []idleCandidate {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	var ics []idleCandidate
+	for _, ic := range s.currentIntercepts {
+		if ic.idleTimeout <= 0 || ic.Disposition != manager.InterceptDispositionType_ACTIVE {
+			continue
+		}
+		ics = append(ics, idleCandidate{
+			id:          ic.Id,
+			name:        ic.Spec.Name,
+			targetHost:  ic.Spec.TargetHost,
+			targetPort:  uint16(ic.Spec.TargetPort),
+			activeSince: ic.activeSince,
+			idleTimeout: ic.idleTimeout,
+		})
+	}
+	return ics
+}
+
+// idleIntercepts returns the given candidates that haven't had any activity to their target since their
+// idle timeout. A candidate with a target host that cannot be resolved is never considered idle, because
+// its activity cannot be matched.
+func idleIntercepts(ctx context.Context, now time.Time, ics []idleCandidate, lastDials map[netip.AddrPort]time.Time, targets idleTargets) []idleCandidate {
+	var idle []idleCandidate
+	for _, ic := range ics {
+		addrs, err := targets.addrs(ctx, ic)
+		if err != nil {
+			dlog.Debugf(ctx, "unable to match dial activity of intercept %s: %v", ic.name, err)
+			continue
+		}
+		lastActive := ic.activeSince
+		for _, addr := range addrs {
+			if t := lastDials[netip.AddrPortFrom(addr.Unmap(), ic.targetPort)]; t.After(lastActive) {
+				lastActive = t
+			}
+		}
+		if now.Sub(lastActive) >= ic.idleTimeout {
+			idle = append(idle, ic)
+		}
+	}
+	return idle
+}
+
+// addrs returns the IP addresses of the target host of the given candidate. The host is resolved the first
+// time that the addresses of the candidate are requested.
+func (ts idleTargets) addrs(ctx context.Context, ic idleCandidate) ([]netip.Addr, error) {
+	rt, ok := ts[ic.id]
+	if !ok {
+		rt.addrs, rt.err = targetAddrs(ctx, ic.targetHost)
+		ts[ic.id] = rt
+	}
+	return rt.addrs, rt.err
+}
+
+// retain removes the addresses of intercepts that aren't among the given candidates.
+func (ts idleTargets) retain(ics []idleCandidate) {
+	for id := range ts {
+		if !slices.ContainsFunc(ics, func(ic idleCandidate) bool { return ic.id == id }) {
+			delete(ts, id)
+		}
+	}
+}
+
+// targetAddrs returns the IP addresses of the given intercept target host.
+func targetAddrs(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
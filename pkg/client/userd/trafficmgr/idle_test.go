@@ -0,0 +1,124 @@
+package trafficmgr
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func activeIntercept(name string, port int32, since time.Time, idleTimeout time.Duration) *intercept {
+	return &intercept{
+		InterceptInfo: &manager.InterceptInfo{
+			Id:          name,
+			Disposition: manager.InterceptDispositionType_ACTIVE,
+			Spec:        &manager.InterceptSpec{Name: name, TargetHost: "127.0.0.1", TargetPort: port},
+		},
+		idleTimeout: idleTimeout,
+		activeSince: since,
+	}
+}
+
+func idleNames(ics []idleCandidate) []string {
+	names := make([]string, len(ics))
+	for i, ic := range ics {
+		names[i] = ic.name
+	}
+	return names
+}
+
+func Test_session_idleIntercepts(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	now := time.Now()
+	s := &session{currentIntercepts: map[string]*intercept{
+		"busy":    activeIntercept("busy", 8080, now.Add(-time.Hour), time.Minute),
+		"idle":    activeIntercept("idle", 8081, now.Add(-time.Hour), time.Minute),
+		"new":     activeIntercept("new", 8082, now.Add(-time.Second), time.Minute),
+		"forever": activeIntercept("forever", 8083, now.Add(-time.Hour), 0),
+	}}
+	waiting := activeIntercept("waiting", 8084, time.Time{}, time.Minute)
+	waiting.Disposition = manager.InterceptDispositionType_WAITING
+	s.currentIntercepts["waiting"] = waiting
+
+	lastDials := map[netip.AddrPort]time.Time{
+		netip.MustParseAddrPort("127.0.0.1:8080"): now.Add(-10 * time.Second),
+		netip.MustParseAddrPort("127.0.0.1:8081"): now.Add(-2 * time.Minute),
+	}
+	ics := s.idleCandidates()
+	assert.ElementsMatch(t, []string{"busy", "idle", "new"}, idleNames(ics))
+	assert.Equal(t, []string{"idle"}, idleNames(idleIntercepts(ctx, now, ics, lastDials, make(idleTargets))))
+
+	// A new dial resets the idle time.
+	lastDials[netip.MustParseAddrPort("127.0.0.1:8081")] = now
+	assert.Empty(t, idleIntercepts(ctx, now, ics, lastDials, make(idleTargets)))
+}
+
+func Test_idleIntercepts_hostName(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	now := time.Now()
+	ics := []idleCandidate{
+		{name: "named", targetHost: "localhost", targetPort: 8080, activeSince: now.Add(-time.Hour), idleTimeout: time.Minute},
+		{name: "unresolved", targetHost: "no-such-host.invalid", targetPort: 8080, activeSince: now.Add(-time.Hour), idleTimeout: time.Minute},
+	}
+
+	// A host name is resolved so that its dial activity is matched, and a host that cannot be resolved
+	// is never removed, because its activity cannot be matched.
+	lastDials := map[netip.AddrPort]time.Time{
+		netip.MustParseAddrPort("127.0.0.1:8080"): now,
+		netip.MustParseAddrPort("[::1]:8080"):     now,
+	}
+	targets := make(idleTargets)
+	assert.Empty(t, idleIntercepts(ctx, now, ics, lastDials, targets))
+	assert.Equal(t, []string{"named"}, idleNames(idleIntercepts(ctx, now, ics, nil, targets)))
+}
+
+func Test_idleTargets(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	now := time.Now()
+	ics := []idleCandidate{
+		{id: "a", name: "a", targetHost: "no-such-host.invalid", targetPort: 8080, activeSince: now.Add(-time.Hour), idleTimeout: time.Minute},
+	}
+
+	// The target host is resolved once, so the address that it resolved to is used until the intercept is gone.
+	targets := idleTargets{"a": {addrs: []netip.Addr{netip.MustParseAddr("10.0.0.1")}}}
+	lastDials := map[netip.AddrPort]time.Time{netip.MustParseAddrPort("10.0.0.1:8080"): now}
+	assert.Empty(t, idleIntercepts(ctx, now, ics, lastDials, targets))
+	assert.Equal(t, []string{"a"}, idleNames(idleIntercepts(ctx, now, ics, nil, targets)))
+
+	targets.retain(ics)
+	assert.Contains(t, targets, "a")
+	targets.retain(nil)
+	assert.Empty(t, targets)
+}
+
+func Test_session_removeIdleIntercepts_noCandidates(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	rd := &fakeRootDaemon{}
+	s := &session{
+		rootDaemon:        rd,
+		currentIntercepts: map[string]*intercept{"forever": activeIntercept("forever", 8080, time.Now().Add(-time.Hour), 0)},
+	}
+
+	// The root daemon's embedded DaemonClient is nil, so asking it for dial activity would panic.
+	require.NotPanics(t, func() { s.removeIdleIntercepts(ctx, time.Now(), make(idleTargets)) })
+}
+
+func Test_session_recordIdleRemoval(t *testing.T) {
+	now := time.Now()
+	s := &session{}
+	s.recordIdleRemoval(idleCandidate{name: "a", idleTimeout: time.Minute}, now.Add(-time.Hour))
+	s.recordIdleRemoval(idleCandidate{name: "b", idleTimeout: time.Hour}, now.Add(-time.Minute))
+	s.recordIdleRemoval(idleCandidate{name: "a", idleTimeout: 2 * time.Minute}, now)
+
+	irs := s.getIdleRemoved()
+	require.Len(t, irs, 2)
+	assert.Equal(t, "a", irs[0].Name)
+	assert.Equal(t, 2*time.Minute, irs[0].IdleTimeout.AsDuration())
+	assert.WithinDuration(t, now, irs[0].RemovedAt.AsTime(), 0)
+	assert.Equal(t, "b", irs[1].Name)
+}
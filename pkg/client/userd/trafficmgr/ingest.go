@@ -3,6 +3,8 @@ package trafficmgr
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -69,6 +71,8 @@ func (ig *ingest) response() *rpc.IngestInfo {
 		FtpPort:          ig.FtpPort,
 		MountPoint:       cn.MountPoint,
 		ClientMountPoint: ig.localMountPoint,
+		LocalMountPort:   ig.localMountPort,
+		LocalPorts:       ig.localPorts,
 		Environment:      cn.Environment,
 	}
 	if ig.handlerContainer != "" {
@@ -94,7 +98,7 @@ func (s *session) getSingleContainerName(ai *manager.AgentInfo) (name string, er
 
 func (s *session) validateAgentForIngest(ai *manager.AgentInfo) error {
 	if len(ai.Containers) == 0 {
-		return status.Error(codes.Unimplemented, fmt.Sprintf("traffic-manager %s have no support for ingest", s.managerVersion))
+		return status.Error(codes.Unimplemented, fmt.Sprintf("traffic-manager %s have no support for ingest", s.ManagerVersion()))
 	}
 	return nil
 }
@@ -132,10 +136,11 @@ func (s *session) Ingest(ctx context.Context, rq *rpc.IngestRequest) (ir *rpc.In
 	if err != nil {
 		return nil, err
 	}
+	s.autoMapNamespace(ctx, s.Namespace)
 
 	if ai == nil {
 		var as *manager.AgentInfoSnapshot
-		as, err = s.managerClient.EnsureAgent(ctx, &manager.EnsureAgentRequest{Session: s.sessionInfo, Name: ik.workload})
+		as, err = s.ManagerClient().EnsureAgent(ctx, &manager.EnsureAgentRequest{Session: s.sessionInfo, Name: ik.workload})
 		if err != nil {
 			return nil, err
 		}
@@ -205,6 +210,20 @@ func (s *session) getCurrentIngests() []*rpc.IngestInfo {
 	return ingests
 }
 
+// IngestsByWorkload returns the active ingests grouped by the name of the ingested workload. The ingests
+// of each workload are sorted by container name.
+func (s *session) IngestsByWorkload(context.Context) map[string][]*rpc.IngestInfo {
+	igMap := make(map[string][]*rpc.IngestInfo)
+	s.currentIngests.Range(func(key ingestKey, ig *ingest) bool {
+		igMap[key.workload] = append(igMap[key.workload], ig.response())
+		return true
+	})
+	for _, igs := range igMap {
+		slices.SortFunc(igs, func(a, b *rpc.IngestInfo) int { return strings.Compare(a.Container, b.Container) })
+	}
+	return igMap
+}
+
 func (s *session) getIngest(rq *rpc.IngestIdentifier) (ig *ingest, err error) {
 	if rq.ContainerName == "" {
 		// Valid if there's only one ingest for the given workload.
@@ -0,0 +1,59 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func Test_session_IngestsByWorkload(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{currentIngests: xsync.NewMapOf[ingestKey, *ingest]()}
+	assert.Empty(t, s.IngestsByWorkload(ctx))
+
+	ai := &manager.AgentInfo{
+		Kind:  "Deployment",
+		PodIp: "10.1.0.5",
+		Containers: map[string]*manager.AgentInfo_ContainerInfo{
+			"echo-server": {MountPoint: "/tel_app_mounts/echo-server"},
+			"sidecar":     {MountPoint: "/tel_app_mounts/sidecar"},
+		},
+	}
+	s.currentIngests.Store(ingestKey{workload: "echo", container: "sidecar"}, &ingest{
+		AgentInfo:  ai,
+		ingestKey:  ingestKey{workload: "echo", container: "sidecar"},
+		localPorts: []string{"9090/UDP"},
+	})
+	s.currentIngests.Store(ingestKey{workload: "echo", container: "echo-server"}, &ingest{
+		AgentInfo:       ai,
+		ingestKey:       ingestKey{workload: "echo", container: "echo-server"},
+		localMountPoint: "/tmp/echo",
+		localMountPort:  2222,
+		localPorts:      []string{"8080"},
+	})
+	s.currentIngests.Store(ingestKey{workload: "hello", container: "hello"}, &ingest{
+		AgentInfo: &manager.AgentInfo{Containers: map[string]*manager.AgentInfo_ContainerInfo{"hello": {}}},
+		ingestKey: ingestKey{workload: "hello", container: "hello"},
+	})
+
+	igMap := s.IngestsByWorkload(ctx)
+	require.Len(t, igMap, 2)
+	require.Len(t, igMap["hello"], 1)
+
+	igs := igMap["echo"]
+	require.Len(t, igs, 2)
+	assert.Equal(t, "echo-server", igs[0].Container)
+	assert.Equal(t, "Deployment", igs[0].WorkloadKind)
+	assert.Equal(t, "10.1.0.5", igs[0].PodIp)
+	assert.Equal(t, "/tel_app_mounts/echo-server", igs[0].MountPoint)
+	assert.Equal(t, "/tmp/echo", igs[0].ClientMountPoint)
+	assert.Equal(t, int32(2222), igs[0].LocalMountPort)
+	assert.Equal(t, []string{"8080"}, igs[0].LocalPorts)
+	assert.Equal(t, "sidecar", igs[1].Container)
+	assert.Equal(t, []string{"9090/UDP"}, igs[1].LocalPorts)
+}
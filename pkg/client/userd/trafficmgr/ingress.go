@@ -0,0 +1,114 @@
+package trafficmgr
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	networking "k8s.io/api/networking/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// ingressInfoMaxAge is the age after which ingress info that was retrieved from the cluster is
+// considered stale, and refreshed by the next status request.
+const ingressInfoMaxAge = 5 * time.Minute
+
+// RefreshIngressInfo queries the cluster for the ingresses in the mapped namespaces and replaces the
+// cached ingress info with the result.
+func (s *session) RefreshIngressInfo(ctx context.Context) ([]*manager.IngressInfo, error) {
+	var iis []*manager.IngressInfo
+	api := k8sapi.GetK8sInterface(ctx).NetworkingV1()
+	for _, ns := range s.GetCurrentNamespaces(true) {
+		il, err := api.Ingresses(ns).List(ctx, meta.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range il.Items {
+			iis = append(iis, ingressInfos(&il.Items[i])...)
+		}
+	}
+	dlog.Debugf(ctx, "refreshed ingress info, found %d entries", len(iis))
+	s.currentInterceptsLock.Lock()
+	s.ingressInfo = iis
+	s.ingressInfoRefreshed = client.GetClock(ctx).Now()
+	s.currentInterceptsLock.Unlock()
+	return iis, nil
+}
+
+// clearIngressInfo clears the cached ingress info. Used when the set of mapped namespaces changes.
+func (s *session) clearIngressInfo() {
+	s.currentInterceptsLock.Lock()
+	s.ingressInfo = nil
+	s.ingressInfoRefreshed = time.Time{}
+	s.currentInterceptsLock.Unlock()
+}
+
+// ingressInfoStatus returns the cached ingress info along with the time of its last refresh and an
+// indication of whether it is stale. The info is refreshed first if it has never been retrieved, or
+// if it is older than ingressInfoMaxAge. A failing refresh is logged, and the old info is then
+// returned and reported as stale. A nil value is returned when the info has never been retrieved.
+func (s *session) ingressInfoStatus(ctx context.Context) *rpc.IngressInfoStatus {
+	clk := client.GetClock(ctx)
+	s.currentInterceptsLock.Lock()
+	refreshed := s.ingressInfoRefreshed
+	s.currentInterceptsLock.Unlock()
+	if refreshed.IsZero() || clk.Since(refreshed) > ingressInfoMaxAge {
+		if _, err := s.RefreshIngressInfo(ctx); err != nil {
+			dlog.Debugf(ctx, "unable to refresh ingress info: %v", err)
+		}
+	}
+
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	if s.ingressInfoRefreshed.IsZero() {
+		return nil
+	}
+	return &rpc.IngressInfoStatus{
+		Ingresses:     s.ingressInfo,
+		LastRefreshed: timestamppb.New(s.ingressInfoRefreshed),
+		Stale:         clk.Since(s.ingressInfoRefreshed) > ingressInfoMaxAge,
+	}
+}
+
+// ingressInfos returns the ingress info for each host that the given ingress exposes through
+// each of its load balancer ingress points.
+func ingressInfos(ing *networking.Ingress) []*manager.IngressInfo {
+	var tlsHosts []string
+	for _, tls := range ing.Spec.TLS {
+		tlsHosts = append(tlsHosts, tls.Hosts...)
+	}
+	var iis []*manager.IngressInfo
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		l3Host := lb.Hostname
+		if l3Host == "" {
+			l3Host = lb.IP
+		}
+		if l3Host == "" {
+			continue
+		}
+		for _, rule := range ing.Spec.Rules {
+			l5Host := rule.Host
+			if l5Host == "" {
+				l5Host = l3Host
+			}
+			ii := &manager.IngressInfo{
+				Host:   l3Host,
+				Port:   80,
+				L5Host: l5Host,
+			}
+			if slices.Contains(tlsHosts, rule.Host) {
+				ii.Port = 443
+				ii.UseTls = true
+			}
+			iis = append(iis, ii)
+		}
+	}
+	return iis
+}
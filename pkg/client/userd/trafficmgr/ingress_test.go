@@ -0,0 +1,72 @@
+package trafficmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func Test_session_status_ingressInfo(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithClock(client.WithConfig(ctx, client.GetDefaultConfig()), fc)
+
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	s := &session{
+		Cluster:        &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}},
+		daemonID:       daemonID,
+		rootDaemon:     &fakeRootDaemon{},
+		currentIngests: xsync.NewMapOf[ingestKey, *ingest](),
+	}
+	s.SetMappedNamespaces(ctx, []string{"a"})
+	require.Equal(t, []string{"a"}, s.GetCurrentNamespaces(true))
+
+	// The first status retrieves the ingress info.
+	ii := s.Status(ctx).IngressInfo
+	require.NotNil(t, ii)
+	assert.WithinDuration(t, fc.Now(), ii.LastRefreshed.AsTime(), 0)
+	assert.False(t, ii.Stale)
+
+	// Fresh info is not refreshed.
+	fc.Step(time.Minute)
+	ii = s.Status(ctx).IngressInfo
+	assert.WithinDuration(t, fc.Now().Add(-time.Minute), ii.LastRefreshed.AsTime(), 0)
+
+	// Old info is refreshed.
+	fc.Step(ingressInfoMaxAge)
+	ii = s.Status(ctx).IngressInfo
+	assert.WithinDuration(t, fc.Now(), ii.LastRefreshed.AsTime(), 0)
+	assert.False(t, ii.Stale)
+
+	// Old info that cannot be refreshed is reported as stale.
+	refreshed := fc.Now()
+	cs.PrependReactor("list", "ingresses", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, assert.AnError
+	})
+	fc.Step(ingressInfoMaxAge + time.Second)
+	ii = s.Status(ctx).IngressInfo
+	assert.WithinDuration(t, refreshed, ii.LastRefreshed.AsTime(), 0)
+	assert.True(t, ii.Stale)
+}
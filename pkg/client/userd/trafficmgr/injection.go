@@ -0,0 +1,70 @@
+package trafficmgr
+
+import (
+	"context"
+	"strings"
+
+	events "k8s.io/api/events/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+// agentInjectionFailures returns, for each of the given events, a description of why the traffic-agent couldn't be
+// injected into the pods of its workload, or an empty string when no such failure is known. It's used by the
+// localWorkloadsWatcher, which has no traffic-manager that tells. The ReplicaFailure condition of each workload is
+// used, and when a workload that isn't available has none, the warning events of its replica sets and pods are
+// inspected on a best-effort basis. The events of the namespace are listed at most once.
+func agentInjectionFailures(ctx context.Context, namespace string, wes []workload.Event) []string {
+	failures := make([]string, len(wes))
+	var warnings []events.Event
+	listed := false
+	for i, we := range wes {
+		wl := we.Workload
+		if we.Type == workload.EventTypeDelete {
+			continue
+		}
+		if failures[i] = workload.AgentInjectionFailure(wl); failures[i] != "" || workload.GetWorkloadState(wl) == workload.StateAvailable {
+			continue
+		}
+		if !listed {
+			listed = true
+			el, err := k8sapi.GetK8sInterface(ctx).EventsV1().Events(namespace).List(ctx, meta.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("type", "Warning").String(),
+			})
+			if err != nil {
+				// The client might not be allowed to list events. That's OK, they're only inspected on a best-effort basis.
+				dlog.Debugf(ctx, "unable to list events in namespace %s: %v", namespace, err)
+			} else {
+				warnings = el.Items
+			}
+		}
+		failures[i] = agentInjectionFailureEvent(wl, warnings)
+	}
+	return failures
+}
+
+// agentInjectionFailureEvent returns the note of the most recent of the given warning events that concerns the given
+// workload, or one of its replica sets or pods, and tells that the traffic-agent couldn't be injected.
+func agentInjectionFailureEvent(wl k8sapi.Workload, warnings []events.Event) string {
+	name := wl.GetName()
+	prefix := name + "-"
+	expected := workload.AgentExpected(wl)
+	var latest *events.Event
+	for i := range warnings {
+		e := &warnings[i]
+		if n := e.Regarding.Name; n != name && !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		if workload.IsAgentInjectionFailure(e.Note, expected) && (latest == nil || latest.CreationTimestamp.Before(&e.CreationTimestamp)) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Note
+}
@@ -0,0 +1,91 @@
+package trafficmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	events "k8s.io/api/events/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func Test_agentInjectionFailures(t *testing.T) {
+	const (
+		webhookFailure = `Error creating: Internal error occurred: failed calling webhook "agent-injector-default.getambassador.io": connection refused`
+		oldFailure     = `Error creating: admission webhook "agent-injector-default.getambassador.io" denied the request: old`
+		agentFailure   = `Back-off restarting failed container traffic-agent in pod echo-7d9c-x2b4f_default`
+	)
+	now := time.Now()
+	warning := func(name, regarding, note string, age time.Duration) *events.Event {
+		return &events.Event{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: meta.NewTime(now.Add(-age))},
+			Type:       "Warning",
+			Regarding:  core.ObjectReference{Name: regarding, Namespace: "default"},
+			Note:       note,
+		}
+	}
+	cs := fake.NewClientset(
+		warning("e1", "echo-7d9c", oldFailure, time.Hour),
+		warning("e2", "echo-7d9c", webhookFailure, time.Minute),
+		warning("e3", "hello-5f8b-k2j4s", agentFailure, time.Minute),
+		warning("e4", "other-6c7d", webhookFailure, time.Minute),
+	)
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+
+	deployment := func(name string, conds ...appsv1.DeploymentCondition) k8sapi.Workload {
+		return k8sapi.Deployment(&appsv1.Deployment{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     appsv1.DeploymentStatus{Conditions: conds},
+		})
+	}
+	progressing := appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: core.ConditionTrue}
+	available := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: core.ConditionTrue}
+	failed := appsv1.DeploymentCondition{Type: appsv1.DeploymentReplicaFailure, Status: core.ConditionTrue, Message: "from condition: agent-injector"}
+
+	failures := agentInjectionFailures(ctx, "default", []workload.Event{
+		{Type: workload.EventTypeAdd, Workload: deployment("echo", progressing)},
+		{Type: workload.EventTypeAdd, Workload: deployment("hello", progressing)},
+		{Type: workload.EventTypeAdd, Workload: deployment("cond", failed)},
+		{Type: workload.EventTypeAdd, Workload: deployment("other", available)},
+		{Type: workload.EventTypeAdd, Workload: deployment("quiet", progressing)},
+		{Type: workload.EventTypeDelete, Workload: deployment("echo", progressing)},
+	})
+	require.Len(t, failures, 6)
+	assert.Equal(t, webhookFailure, failures[0], "the most recent event is used")
+	assert.Equal(t, agentFailure, failures[1])
+	assert.Equal(t, "from condition: agent-injector", failures[2])
+	assert.Empty(t, failures[3], "events aren't inspected for available workloads")
+	assert.Empty(t, failures[4])
+	assert.Empty(t, failures[5])
+}
+
+func Test_session_getInfosForWorkloads_injectionFailed(t *testing.T) {
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}: {
+			state:            workload.StateFailure,
+			agentState:       manager.WorkloadInfo_INJECTION_FAILED,
+			agentStateReason: "failed calling webhook",
+		},
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "hello"}: {
+			state:      workload.StateAvailable,
+			agentState: manager.WorkloadInfo_INSTALLED,
+		},
+	}}
+	wiz, _ := s.getInfosForWorkloads([]string{"a"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+	require.Len(t, wiz, 2)
+	assert.Equal(t, "echo", wiz[0].Name)
+	assert.Equal(t, "failed calling webhook", wiz[0].AgentInjectionFailure)
+	assert.Equal(t, rpc.WorkloadInfo_NOT_AVAILABLE, wiz[0].NotInterceptableCode)
+	assert.Empty(t, wiz[1].AgentInjectionFailure)
+}
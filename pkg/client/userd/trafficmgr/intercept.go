@@ -5,15 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/netip"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/blang/semver/v4"
 	grpcCodes "google.golang.org/grpc/codes"
 	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/rpc/v2/common"
@@ -25,6 +31,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/client/docker"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/remotefs"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/envsyntax"
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/maps"
 	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
@@ -67,8 +74,37 @@ type intercept struct {
 
 	// Mount read-only
 	readOnly bool
+
+	// group is the name of the intercept group that the intercept was created with, if any.
+	group string
+
+	// idleTimeout is the time that the intercept can remain active without traffic before it's removed.
+	// Zero means never.
+	idleTimeout time.Duration
+
+	// activeSince is the time when the intercept became active.
+	activeSince time.Time
+
+	// setupErrors contains the errors that prevented the local port-forwards, mounts, or API server of
+	// the intercept from starting, keyed by what failed. Protected by the intercept's mutex.
+	setupErrors map[string]string
+
+	// setupPending contains the local port-forwards and mounts that have been started but aren't ready
+	// yet, keyed like the setupErrors. Protected by the intercept's mutex.
+	setupPending map[string]struct{}
+
+	// setupChanged is closed when the setupErrors or setupPending change. A new channel is created when
+	// it's requested again. Protected by the intercept's mutex.
+	setupChanged chan struct{}
 }
 
+// Keys of the intercept's setupErrors that aren't port-forwards.
+const (
+	setupAgentAccess = "agent-access"
+	setupMount       = "mount"
+	setupAPIServer   = "api-server"
+)
+
 // interceptResult is what gets written to the awaitIntercept's waitCh channel when the
 // awaited intercept arrives.
 type interceptResult struct {
@@ -88,8 +124,9 @@ type awaitIntercept struct {
 	// the mount to take place in a host
 	mountPort int32
 
-	readOnly bool
-	waitCh   chan<- interceptResult
+	readOnly    bool
+	idleTimeout time.Duration
+	waitCh      chan<- interceptResult
 }
 
 func (ic *intercept) localPorts() []string {
@@ -104,6 +141,66 @@ func (ic *intercept) localPorts() []string {
 	return ps
 }
 
+// setSetupPending records that the given part of the intercept has been started, and clears a previously
+// recorded error. The part is pending until setSetupError is called for it.
+func (ic *intercept) setSetupPending(key string) {
+	ic.Lock()
+	defer ic.Unlock()
+	delete(ic.setupErrors, key)
+	if ic.setupPending == nil {
+		ic.setupPending = make(map[string]struct{})
+	}
+	ic.setupPending[key] = struct{}{}
+	ic.notifySetupChanged()
+}
+
+// setSetupError records the error that prevented the given part of the intercept from starting. A nil
+// error means that the part is ready, and clears a previously recorded error.
+func (ic *intercept) setSetupError(key string, err error) {
+	ic.Lock()
+	defer ic.Unlock()
+	delete(ic.setupPending, key)
+	if err == nil {
+		delete(ic.setupErrors, key)
+	} else {
+		if ic.setupErrors == nil {
+			ic.setupErrors = make(map[string]string)
+		}
+		ic.setupErrors[key] = err.Error()
+	}
+	ic.notifySetupChanged()
+}
+
+// notifySetupChanged closes the setupChanged channel. The caller must hold the intercept's mutex.
+func (ic *intercept) notifySetupChanged() {
+	if ic.setupChanged != nil {
+		close(ic.setupChanged)
+		ic.setupChanged = nil
+	}
+}
+
+// setupError returns the recorded setup errors of the intercept, or an empty string if there are none.
+func (ic *intercept) setupError() string {
+	ic.Lock()
+	defer ic.Unlock()
+	return strings.Join(maps.ToSortedSlice(ic.setupErrors), "; ")
+}
+
+// setupState returns the sorted keys of the pending parts of the intercept, its recorded setup errors, and a
+// channel that is closed when either of them changes.
+func (ic *intercept) setupState() (pending []string, setupErr string, changed <-chan struct{}) {
+	ic.Lock()
+	defer ic.Unlock()
+	if ic.setupChanged == nil {
+		ic.setupChanged = make(chan struct{})
+	}
+	for key := range ic.setupPending {
+		pending = append(pending, key)
+	}
+	sort.Strings(pending)
+	return pending, strings.Join(maps.ToSortedSlice(ic.setupErrors), "; "), ic.setupChanged
+}
+
 func (ic *intercept) podAccess(rd daemon.DaemonClient) *podAccess {
 	pa := &podAccess{
 		ctx:              ic.ctx,
@@ -118,10 +215,14 @@ func (ic *intercept) podAccess(rd daemon.DaemonClient) *podAccess {
 		localMountPort:   ic.localMountPort,
 		readOnly:         ic.readOnly,
 		mounter:          &ic.Mounter,
+		setupStarted:     ic.setSetupPending,
+		setupFailed:      ic.setSetupError,
 	}
-	if err := pa.ensureAccess(ic.ctx, rd); err != nil {
+	err := pa.ensureAccess(ic.ctx, rd)
+	if err != nil {
 		dlog.Error(ic.ctx, err)
 	}
+	ic.setSetupError(setupAgentAccess, err)
 	return pa
 }
 
@@ -133,18 +234,57 @@ func (s *session) watchInterceptsHandler(ctx context.Context) error {
 	//     their exit statuses is just a memory leak
 	//  3. because we want a per-worker cancel, we'd have to implement our own Context
 	//     management on top anyway, so dgroup wouldn't actually save us any complexity.
-	return runWithRetry(ctx, s.watchInterceptsLoop)
+	//
+	// The pod access tracker outlives each watch, so that the port forwards and volume mounts of the
+	// current intercepts are retained when the connection to the traffic-manager is reestablished.
+	pat := newPodAccessTracker()
+	return runWithRetry(ctx, "intercept-port-forward", func(ctx context.Context) error {
+		return s.watchInterceptsLoop(ctx, pat)
+	})
 }
 
-func (s *session) watchInterceptsLoop(ctx context.Context) error {
-	stream, err := s.managerClient.WatchIntercepts(ctx, s.SessionInfo())
+func (s *session) watchInterceptsLoop(ctx context.Context, pat *podAccessTracker) error {
+	mc := s.ManagerClient()
+	stream, err := mc.WatchIntercepts(ctx, s.SessionInfo())
 	if err != nil {
+		if managerUnavailable(err) {
+			if rerr := s.reconnectManager(ctx, mc); rerr != nil {
+				return fmt.Errorf("manager.WatchIntercepts dial: %w: %w", err, rerr)
+			}
+			return nil
+		}
 		return fmt.Errorf("manager.WatchIntercepts dial: %w", err)
 	}
-	pat := newPodAccessTracker()
-	for ctx.Err() == nil {
-		snapshot, err := stream.Recv()
-		if err != nil {
+
+	// Receive snapshots in a separate goroutine, so that snapshots from the intercept reconciliation
+	// can be handled in between.
+	snapshots := make(chan []*manager.InterceptInfo)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			snapshot, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case snapshots <- snapshot.Intercepts:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	for {
+		select {
+		case err := <-recvErr:
+			if ctx.Err() == nil && managerUnavailable(err) {
+				rerr := s.reconnectManager(ctx, mc)
+				if rerr == nil {
+					// Watch again using the new connection. The current intercepts are retained.
+					return nil
+				}
+				err = fmt.Errorf("%w: %w", err, rerr)
+			}
 			// Handle as if we had an empty snapshot. This will ensure that port forwards and volume mounts are cancelled correctly.
 			s.handleInterceptSnapshot(ctx, pat, nil)
 			if ctx.Err() != nil || errors.Is(err, io.EOF) {
@@ -152,10 +292,25 @@ func (s *session) watchInterceptsLoop(ctx context.Context) error {
 				return nil
 			}
 			return fmt.Errorf("manager.WatchIntercepts recv: %w", err)
+		case intercepts := <-snapshots:
+			s.handleInterceptSnapshot(ctx, pat, intercepts)
+			s.serviceGate.setReady("intercept-port-forward")
+		case <-s.interceptResync:
+			s.resyncIntercepts(ctx, pat)
 		}
-		s.handleInterceptSnapshot(ctx, pat, snapshot.Intercepts)
 	}
-	return nil
+}
+
+// resyncIntercepts fetches the intercepts of this session from the traffic-manager and applies them. The
+// snapshot is fetched here rather than by the reconciliation, so that a snapshot which is older than one
+// that the watcher has received in the meantime is never applied.
+func (s *session) resyncIntercepts(ctx context.Context, pat *podAccessTracker) {
+	intercepts, err := s.interceptSnapshot(ctx, s.SessionInfo())
+	if err != nil {
+		dlog.Warnf(ctx, "unable to fetch the intercepts to reconcile: %v", err)
+		return
+	}
+	s.handleInterceptSnapshot(ctx, pat, intercepts)
 }
 
 func (s *session) handleInterceptSnapshot(ctx context.Context, pat *podAccessTracker, intercepts []*manager.InterceptInfo) {
@@ -217,6 +372,17 @@ func (s *session) handleInterceptSnapshot(ctx context.Context, pat *podAccessTra
 		}
 		pat.start(pa)
 	}
+
+	// The traffic-manager no longer knows the draining intercepts, but their port-forwards and mounts are
+	// kept until the drains end.
+	for _, ic := range s.getDrainingIntercepts() {
+		pa := ic.podAccess(s.rootDaemon)
+		if s.isPodDaemon {
+			pa.ftpPort = 0
+			pa.sftpPort = 0
+		}
+		pat.start(pa)
+	}
 	pat.cancelUnwanted(ctx)
 }
 
@@ -236,7 +402,12 @@ func (s *session) getCurrentInterceptInfos() []*manager.InterceptInfo {
 	ics := s.getCurrentIntercepts()
 	ifs := make([]*manager.InterceptInfo, len(ics))
 	for idx, ic := range ics {
-		ifs[idx] = ic.InterceptInfo
+		ii := ic.InterceptInfo
+		if se := ic.setupError(); se != "" {
+			ii = proto.Clone(ii).(*manager.InterceptInfo)
+			ii.SetupError = se
+		}
+		ifs[idx] = ii
 	}
 	return ifs
 }
@@ -252,15 +423,30 @@ func (s *session) setCurrentIntercepts(ctx context.Context, iis []*manager.Inter
 		if ok {
 			// retain ClientMountPoint, it's assigned in the client and never passed from the traffic-manager
 			ii.ClientMountPoint = ic.ClientMountPoint
+			if ii.Disposition == manager.InterceptDispositionType_ACTIVE && ic.Disposition != manager.InterceptDispositionType_ACTIVE {
+				ic.activeSince = client.GetClock(ctx).Now()
+			}
+			if ii.Disposition != ic.Disposition {
+				s.emitInterceptChanged(ctx, rpc.SessionEvent_InterceptChanged_UPDATED, ii)
+			}
 			ic.InterceptInfo = ii
 		} else {
-			ic = &intercept{InterceptInfo: ii}
+			ic = &intercept{
+				InterceptInfo: ii,
+				group:         s.interceptGroups[ii.Spec.Name],
+			}
+			if ii.Disposition == manager.InterceptDispositionType_ACTIVE {
+				ic.activeSince = client.GetClock(ctx).Now()
+			}
 			ic.ctx, ic.cancel = context.WithCancel(ctx)
 			dlog.Debugf(ctx, "Received new intercept %s", ic.Spec.Name)
+			s.audit.record(auditInterceptStarted, ii.Spec.WorkloadKind, ii.Spec.Agent, ii.Spec.Namespace, "")
+			s.emitInterceptChanged(ctx, rpc.SessionEvent_InterceptChanged_STARTED, ii)
 			if aw, ok := s.interceptWaiters[ii.Spec.Name]; ok {
 				ic.ClientMountPoint = aw.mountPoint
 				ic.localMountPort = aw.mountPort
 				ic.readOnly = aw.readOnly
+				ic.idleTimeout = aw.idleTimeout
 			}
 		}
 		intercepts[ii.Id] = ic
@@ -277,8 +463,16 @@ func (s *session) setCurrentIntercepts(ctx context.Context, iis []*manager.Inter
 	// Cancel those that no longer exists
 	for id, ic := range s.currentIntercepts {
 		if _, ok := intercepts[id]; !ok {
-			dlog.Debugf(ctx, "Cancelling context for intercept %s", ic.Spec.Name)
-			ic.cancel()
+			if s.isDrainingLocked(ic) {
+				// The drain cancels the intercept when it ends.
+				dlog.Debugf(ctx, "Keeping context for draining intercept %s", ic.Spec.Name)
+			} else {
+				dlog.Debugf(ctx, "Cancelling context for intercept %s", ic.Spec.Name)
+				ic.cancel()
+			}
+			delete(s.interceptGroups, ic.Spec.Name)
+			s.audit.record(auditInterceptEnded, ic.Spec.WorkloadKind, ic.Spec.Agent, ic.Spec.Namespace, "")
+			s.emitInterceptChanged(ctx, rpc.SessionEvent_InterceptChanged_ENDED, ic.InterceptInfo)
 		}
 	}
 	s.currentIntercepts = intercepts
@@ -348,6 +542,52 @@ func (s *session) ensureNoInterceptConflict(ir *rpc.CreateInterceptRequest) *rpc
 	return nil
 }
 
+// mirrorAgentVersion is the first traffic-agent version that mirrors the traffic of an intercept. Older agents
+// don't know about InterceptSpec.mirror, and would redirect the traffic instead.
+var mirrorAgentVersion = semver.Version{Major: 2, Minor: 22} //nolint:gochecknoglobals // constant
+
+// ensureMirrorSupport checks that a mirrored intercept doesn't replace the containers of the workload, and that
+// none of the known traffic-agents of the workload predates mirroring.
+func (s *session) ensureMirrorSupport(spec *manager.InterceptSpec) *rpc.InterceptResult {
+	if !spec.Mirror {
+		return nil
+	}
+	if spec.Replace {
+		return InterceptError(common.InterceptError_INVALID_VALUE, errcat.User.New("mirror cannot be combined with replace"))
+	}
+	for _, a := range s.getCurrentAgents() {
+		if a.Name != spec.Agent || a.Namespace != spec.Namespace {
+			continue
+		}
+		v, err := semver.Parse(strings.TrimPrefix(a.Version, "v"))
+		if err == nil {
+			v = semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+		}
+		if err != nil || v.LT(mirrorAgentVersion) {
+			return InterceptError(common.InterceptError_INVALID_VALUE, errcat.User.Newf(
+				"the traffic-agent of %s.%s has version %s and cannot mirror traffic; version %s or later is required",
+				spec.Agent, spec.Namespace, a.Version, mirrorAgentVersion))
+		}
+	}
+	return nil
+}
+
+// ensureInterceptCapacity checks that the session hasn't reached the maximum number of concurrent intercepts
+// given by the intercept.maxConcurrent setting of the client configuration.
+func (s *session) ensureInterceptCapacity(c context.Context) *rpc.InterceptResult {
+	maxConcurrent := client.GetConfig(c).Intercept().MaxConcurrent
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	s.currentInterceptsLock.Lock()
+	n := len(s.currentIntercepts)
+	s.currentInterceptsLock.Unlock()
+	if n >= maxConcurrent {
+		return InterceptError(common.InterceptError_TOO_MANY_INTERCEPTS, errcat.User.New(strconv.Itoa(maxConcurrent)))
+	}
+	return nil
+}
+
 // CanIntercept checks if it is possible to create an intercept for the given request. The intercept can proceed
 // only if the returned rpc.InterceptResult is nil. The returned runtime.Object is either nil, indicating a local
 // intercept, or the workload for the intercept.
@@ -360,9 +600,18 @@ func (s *session) CanIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 	}
 
 	self := s.self
+	if er := applyInterceptPreset(c, ir); er != nil {
+		return nil, er
+	}
 	if er := s.ensureNoInterceptConflict(ir); er != nil {
 		return nil, er
 	}
+	if er := s.ensureInterceptCapacity(c); er != nil {
+		return nil, er
+	}
+
+	// The namespace is mapped once the request is known to be valid, so that a refused intercept doesn't map it.
+	s.autoMapNamespace(c, spec.Namespace)
 	if spec.Agent == "" {
 		return nil, nil
 	}
@@ -374,7 +623,7 @@ func (s *session) CanIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 	if er := self.InterceptProlog(c, mgrIr); er != nil {
 		return nil, er
 	}
-	pi, err := s.managerClient.PrepareIntercept(c, mgrIr)
+	pi, err := s.ManagerClient().PrepareIntercept(c, mgrIr)
 	if err != nil {
 		if st, ok := grpcStatus.FromError(err); ok {
 			if st.Code() == grpcCodes.FailedPrecondition {
@@ -392,6 +641,14 @@ func (s *session) CanIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 			return nil, er
 		}
 	}
+	if !ir.Force {
+		if er := s.ensureNoClientConflict(c, spec, pi.ContainerPort); er != nil {
+			return nil, er
+		}
+	}
+	if er := s.ensureMirrorSupport(spec); er != nil {
+		return nil, er
+	}
 
 	iInfo := &interceptInfo{preparedIntercept: pi}
 	return iInfo, nil
@@ -421,6 +678,13 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 	if spec.Mechanism == "" {
 		spec.Mechanism = "tcp"
 	}
+	var generatedHeaders map[string]string
+	if ir.GenerateHeader {
+		var err error
+		if generatedHeaders, err = s.generateInterceptHeader(spec); err != nil {
+			return InterceptError(common.InterceptError_INVALID_VALUE, err)
+		}
+	}
 
 	mgrClient := self.ManagerClient()
 
@@ -442,6 +706,7 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 	spec.Protocol = pi.Protocol
 	spec.ContainerPort = pi.ContainerPort
 	result = iInfo.InterceptResult()
+	result.GeneratedHeaders = generatedHeaders
 
 	spec.ServiceUid = result.ServiceUid
 	spec.WorkloadKind = result.WorkloadKind
@@ -450,6 +715,10 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 	tos := client.GetConfig(c).Timeouts()
 	spec.RoundtripLatency = int64(tos.Get(client.TimeoutRoundtripLatency)) * 2 // Account for extra hop
 	spec.DialTimeout = int64(tos.Get(client.TimeoutEndpointDial))
+	spec.DrainPeriod = int64(client.GetConfig(c).Intercept().DrainPeriod)
+	if ir.DrainPeriod != nil {
+		spec.DrainPeriod = int64(ir.DrainPeriod.AsDuration())
+	}
 	c, cancel := tos.TimeoutContext(c, client.TimeoutIntercept)
 	defer cancel()
 
@@ -457,11 +726,16 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 	// should become active within a few seconds.
 	waitCh := make(chan interceptResult, 2) // Need a buffer because reply can come before we're reading the channel,
 	s.currentInterceptsLock.Lock()
+	idleTimeout := client.GetConfig(c).Intercept().IdleTimeout
+	if ir.IdleTimeout != nil {
+		idleTimeout = ir.IdleTimeout.AsDuration()
+	}
 	s.interceptWaiters[spec.Name] = &awaitIntercept{
-		mountPoint: ir.MountPoint,
-		mountPort:  ir.LocalMountPort,
-		readOnly:   ir.MountReadOnly,
-		waitCh:     waitCh,
+		mountPoint:  ir.MountPoint,
+		mountPort:   ir.LocalMountPort,
+		readOnly:    ir.MountReadOnly,
+		idleTimeout: idleTimeout,
+		waitCh:      waitCh,
 	}
 	s.currentInterceptsLock.Unlock()
 	defer func() {
@@ -490,7 +764,7 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 			// context is already done.
 			rc, cancel := context.WithTimeout(context.WithoutCancel(c), 5*time.Second)
 			defer cancel()
-			if removeErr := self.RemoveIntercept(rc, ii.Spec.Name); removeErr != nil {
+			if removeErr := self.RemoveIntercept(withEndReason(rc, endReasonRollback), ii.Spec.Name); removeErr != nil {
 				dlog.Warnf(c, "failed to remove failed intercept %s: %v", ii.Spec.Name, removeErr)
 			}
 		}
@@ -512,6 +786,11 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 				continue
 			}
 			result.InterceptInfo = ii
+
+			// An agent might have been injected or replaced while the intercept was created.
+			if er := s.ensureMirrorSupport(spec); er != nil {
+				return er
+			}
 			select {
 			case <-c.Done():
 				return InterceptError(common.InterceptError_FAILED_TO_ESTABLISH, client.CheckTimeout(c, c.Err()))
@@ -526,6 +805,7 @@ func (s *session) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest
 				return InterceptError(common.InterceptError_INTERNAL, client.CheckTimeout(c, err))
 			}
 			result.InterceptInfo.Environment = env.Env
+			addPresetEnv(c, ir, result.InterceptInfo)
 			success = true // Prevent removal in deferred function
 			return result
 		}
@@ -548,21 +828,37 @@ func (s *session) RemoveIntercept(c context.Context, name string) error {
 		dlog.Debugf(c, "Intercept %s was already removed", name)
 		return nil
 	}
-	return s.removeIntercept(c, ii)
+	return s.removeIntercept(c, ii, endReason(c))
 }
 
-func (s *session) removeIntercept(c context.Context, ic *intercept) error {
-	name := ic.Spec.Name
-	s.stopHandler(c, name, ic.handlerContainer, ic.pid)
+// removeIntercept ends the given intercept and records it in the intercept history together with the
+// given reason. An intercept with a drain period is removed from the traffic-manager right away, and ends in
+// the background when it has drained.
+func (s *session) removeIntercept(c context.Context, ic *intercept, reason string) error {
+	if drainPeriod := time.Duration(ic.Spec.DrainPeriod); drainPeriod > 0 {
+		return s.drainIntercept(c, ic, reason, drainPeriod)
+	}
 
 	// Unmount filesystems before telling the manager to remove the intercept
+	s.endIntercept(c, ic, reason)
+	return s.removeManagerIntercept(c, ic.Spec.Name)
+}
+
+// endIntercept stops the handler of the given intercept, cancels its port-forwards and mounts, and records
+// it in the intercept history together with the given reason.
+func (s *session) endIntercept(c context.Context, ic *intercept, reason string) {
+	s.stopHandler(c, ic.Spec.Name, ic.handlerContainer, ic.pid)
 	ic.cancel()
 	ic.wg.Wait()
+	s.recordEndedIntercept(c, ic, reason)
+}
 
+// removeManagerIntercept tells the traffic-manager to remove the intercept with the given name.
+func (s *session) removeManagerIntercept(c context.Context, name string) error {
 	dlog.Debugf(c, "telling manager to remove intercept %s", name)
 	c, cancel := client.GetConfig(c).Timeouts().TimeoutContext(c, client.TimeoutTrafficManagerAPI)
 	defer cancel()
-	_, err := s.managerClient.RemoveIntercept(c, &manager.RemoveInterceptRequest2{
+	_, err := s.ManagerClient().RemoveIntercept(c, &manager.RemoveInterceptRequest2{
 		Session: s.SessionInfo(),
 		Name:    name,
 	})
@@ -658,6 +954,28 @@ func (s *session) GetInterceptInfo(name string) *manager.InterceptInfo {
 	return nil
 }
 
+// InterceptEnvironment returns the environment of the current intercept with the given id or name, formatted
+// using the given syntax, which is one of the syntaxes that the --env-syntax flag accepts.
+func (s *session) InterceptEnvironment(_ context.Context, id, syntax string) ([]byte, error) {
+	var es envsyntax.Syntax
+	if err := es.Set(syntax); err != nil {
+		return nil, errcat.User.New(err)
+	}
+	s.currentInterceptsLock.Lock()
+	ic, ok := s.currentIntercepts[id]
+	s.currentInterceptsLock.Unlock()
+	if !ok {
+		if ic = s.getInterceptByName(id); ic == nil {
+			return nil, errcat.User.Newf("intercept %q not found", id)
+		}
+	}
+	ev := maps.Copy(ic.Environment)
+	if ic.handlerContainer != "" {
+		ev["TELEPRESENCE_HANDLER_CONTAINER_NAME"] = ic.handlerContainer
+	}
+	return es.Format(ev)
+}
+
 // GetInterceptSpec returns the InterceptSpec for the given name, or nil if no such spec exists.
 func (s *session) getInterceptByName(name string) (found *intercept) {
 	s.currentInterceptsLock.Lock()
@@ -682,11 +1000,12 @@ func (s *session) InterceptsForWorkload(workloadName, namespace string) []*manag
 	return wlis
 }
 
-// ClearIngestsAndIntercepts removes all intercepts.
+// ClearIngestsAndIntercepts removes all intercepts, and waits for those that drain to end. The intercepts
+// drain concurrently.
 func (s *session) ClearIngestsAndIntercepts(c context.Context) error {
 	for _, ic := range s.getCurrentIntercepts() {
 		dlog.Debugf(c, "Clearing intercept %s", ic.Spec.Name)
-		err := s.removeIntercept(c, ic)
+		err := s.removeIntercept(c, ic, endReasonCleared)
 		if err != nil && grpcStatus.Code(err) != grpcCodes.NotFound {
 			return err
 		}
@@ -696,32 +1015,21 @@ func (s *session) ClearIngestsAndIntercepts(c context.Context) error {
 		s.stopHandler(c, key.workload+"/"+key.container, ig.handlerContainer, ig.pid)
 		return true
 	})
+	s.awaitDrains(c)
 	return nil
 }
 
 // reconcileAPIServers start/stop API servers as needed based on the TELEPRESENCE_API_PORT environment variable
 // of the currently intercepted agent's env.
 func (s *session) reconcileAPIServers(ctx context.Context) {
-	wantedPorts := make(map[int]struct{})
+	wantedPorts := make(map[int][]*intercept)
 	wantedMatchers := make(map[string]*manager.InterceptInfo)
 
-	agentAPIPort := func(ii *manager.InterceptInfo) int {
-		is := ii.Spec
-		if ps, ok := ii.Environment[agentconfig.EnvAPIPort]; ok {
-			port, err := strconv.ParseUint(ps, 10, 16)
-			if err == nil {
-				return int(port)
-			}
-			dlog.Errorf(ctx, "unable to parse TELEPRESENCE_API_PORT(%q) to a port number in agent %s.%s: %v", ps, is.Agent, is.Namespace, err)
-		}
-		return 0
-	}
-
 	for _, ic := range s.currentIntercepts {
 		ii := ic.InterceptInfo
 		if ic.Disposition == manager.InterceptDispositionType_ACTIVE {
-			if port := agentAPIPort(ii); port > 0 {
-				wantedPorts[port] = struct{}{}
+			if port := agentAPIPort(ctx, ii); port > 0 {
+				wantedPorts[port] = append(wantedPorts[port], ic)
 				wantedMatchers[ic.Id] = ii
 			}
 		}
@@ -732,18 +1040,26 @@ func (s *session) reconcileAPIServers(ctx context.Context) {
 			delete(s.currentAPIServers, p)
 		}
 	}
-	for p := range wantedPorts {
-		if _, ok := s.currentAPIServers[p]; !ok {
+	for p, ics := range wantedPorts {
+		as, ok := s.currentAPIServers[p]
+		if !ok {
 			s.newAPIServerForPort(ctx, p)
+			continue
+		}
+		if as.err != nil {
+			// The server failed to start before these intercepts arrived.
+			for _, ic := range ics {
+				ic.setSetupError(setupAPIServer, as.err)
+			}
 		}
 	}
-	for id := range s.currentMatchers {
-		if _, ok := wantedMatchers[id]; !ok {
-			delete(s.currentMatchers, id)
+	for key := range s.currentMatchers {
+		if _, ok := wantedMatchers[key.id]; !ok {
+			delete(s.currentMatchers, key)
 		}
 	}
 	for id, ic := range wantedMatchers {
-		if _, ok := s.currentMatchers[id]; !ok {
+		if _, ok := s.currentMatchers[apiMatcherKey{id: id}]; !ok {
 			s.newMatcher(ctx, ic)
 		}
 	}
@@ -759,42 +1075,127 @@ func (s *session) newAPIServerForPort(ctx context.Context, port int) {
 		s.currentAPIServers[port] = &as
 	}
 	go func() {
-		if err := svr.ListenAndServe(ctx, port); err != nil {
+		if err := svr.ListenAndServe(ctx, port); err != nil && ctx.Err() == nil {
 			dlog.Error(ctx, err)
+			s.apiServerFailed(ctx, port, &as, localPortError("API server port", strconv.Itoa(port), err))
 		}
 	}()
 }
 
-func (s *session) newMatcher(ctx context.Context, ic *manager.InterceptInfo) {
-	m, err := matcher.NewRequestFromMap(ic.Headers)
-	if err != nil {
-		dlog.Error(ctx, err)
-		return
+// apiServerFailed records the error of an API server that failed, so that it's reported as a setup error
+// by the active intercepts that use the API server's port.
+func (s *session) apiServerFailed(ctx context.Context, port int, as *apiServer, err error) {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	as.err = err
+	for _, ic := range s.currentIntercepts {
+		if ic.Disposition == manager.InterceptDispositionType_ACTIVE && agentAPIPort(ctx, ic.InterceptInfo) == port {
+			ic.setSetupError(setupAPIServer, err)
+		}
 	}
-	if s.currentMatchers == nil {
-		s.currentMatchers = make(map[string]*apiMatcher)
+}
+
+// agentAPIPort returns the port of the API server that the given intercept's agent declares in its
+// TELEPRESENCE_API_PORT environment variable, or zero if it declares none.
+func agentAPIPort(ctx context.Context, ii *manager.InterceptInfo) int {
+	is := ii.Spec
+	if ps, ok := ii.Environment[agentconfig.EnvAPIPort]; ok {
+		port, err := strconv.ParseUint(ps, 10, 16)
+		if err == nil {
+			return int(port)
+		}
+		dlog.Errorf(ctx, "unable to parse TELEPRESENCE_API_PORT(%q) to a port number in agent %s.%s: %v", ps, is.Agent, is.Namespace, err)
+	}
+	return 0
+}
+
+// localPortError returns an error that tells why the given local port couldn't be used.
+func localPortError(what, port string, err error) error {
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return fmt.Errorf("%s %s in use", what, port)
+	}
+	return fmt.Errorf("%s %s: %w", what, port, err)
+}
+
+// isGlobalIntercept returns true if the given intercept forwards all TCP connections for the intercepted
+// port rather than individual HTTP requests.
+func isGlobalIntercept(ii *manager.InterceptInfo) bool {
+	return ii.GetSpec().GetMechanism() == "tcp"
+}
+
+// matcherMap returns the map that a request matcher for the given intercept is created from. It contains
+// the given headers and the source CIDRs and gRPC methods of the intercept's spec.
+func matcherMap(ic *manager.InterceptInfo, headers map[string]string) map[string]string {
+	scs := ic.GetSpec().GetSourceCidrs()
+	gms := ic.GetSpec().GetGrpcMethods()
+	if len(scs) == 0 && len(gms) == 0 {
+		return headers
+	}
+	m := make(map[string]string, len(headers)+2)
+	maps.Merge(m, headers)
+	if len(scs) > 0 {
+		m[":source-cidr:"] = strings.Join(scs, ",")
+	}
+	if len(gms) > 0 {
+		m[":grpc-method:"] = strings.Join(gms, ",")
+	}
+	return m
+}
+
+// newMatcher adds the default matcher of the given intercept, which uses the intercept's headers, and one
+// matcher for each container port that the intercept's spec has port headers for.
+func (s *session) newMatcher(ctx context.Context, ic *manager.InterceptInfo) {
+	s.addMatcher(ctx, apiMatcherKey{id: ic.Id}, ic, ic.Headers)
+	for port, ph := range ic.GetSpec().GetPortHeaders() {
+		if port <= 0 || port > math.MaxUint16 {
+			dlog.Errorf(ctx, "intercept %s has headers for invalid port %d", ic.Spec.Name, port)
+			continue
+		}
+		s.addMatcher(ctx, apiMatcherKey{id: ic.Id, port: uint16(port)}, ic, ph.GetHeaders())
 	}
-	s.currentMatchers[ic.Id] = &apiMatcher{
-		requestMatcher: m,
+}
+
+func (s *session) addMatcher(ctx context.Context, key apiMatcherKey, ic *manager.InterceptInfo, headers map[string]string) {
+	am := &apiMatcher{
 		metadata:       ic.Metadata,
+		allConnections: ic.GetSpec().GetAllConnections(),
+	}
+	if !am.allConnections {
+		m, err := matcher.NewRequestFromMap(matcherMap(ic, headers))
+		if err != nil {
+			dlog.Error(ctx, err)
+			return
+		}
+		am.requestMatcher = m
 	}
+	if s.currentMatchers == nil {
+		s.currentMatchers = make(map[apiMatcherKey]*apiMatcher)
+	}
+	s.currentMatchers[key] = am
 }
 
-func (s *session) InterceptInfo(ctx context.Context, callerID, path string, _ uint16, headers http.Header) (*restapi.InterceptInfo, error) {
+func (s *session) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, sourceIP netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
 	s.currentInterceptsLock.Lock()
 	defer s.currentInterceptsLock.Unlock()
 
 	r := &restapi.InterceptInfo{ClientSide: true}
-	am := s.currentMatchers[callerID]
+	am, ok := s.currentMatchers[apiMatcherKey{id: callerID, port: containerPort}]
+	if !ok {
+		am = s.currentMatchers[apiMatcherKey{id: callerID}]
+	}
 	switch {
 	case am == nil:
 		dlog.Debugf(ctx, "no matcher found for callerID %s", callerID)
-	case am.requestMatcher.Matches(path, headers):
-		dlog.Debugf(ctx, "%s: matcher %s\nmatches path %q and headers\n%s", callerID, am.requestMatcher, path, matcher.HeaderStringer(headers))
+	case am.allConnections:
+		dlog.Debugf(ctx, "%s: intercept of all connections matches all requests", callerID)
+		r.Intercepted = true
+		r.Metadata = am.metadata
+	case am.requestMatcher.Matches(path, sourceIP, headers):
+		dlog.Debugf(ctx, "%s: matcher %s\nmatches path %q, source %s, and headers\n%s", callerID, am.requestMatcher, path, sourceIP, matcher.HeaderStringer(headers))
 		r.Intercepted = true
 		r.Metadata = am.metadata
 	default:
-		dlog.Debugf(ctx, "%s: matcher %s\nmatches path %q and headers\n%s", callerID, am.requestMatcher, path, matcher.HeaderStringer(headers))
+		dlog.Debugf(ctx, "%s: matcher %s\ndoes not match path %q, source %s, and headers\n%s", callerID, am.requestMatcher, path, sourceIP, matcher.HeaderStringer(headers))
 	}
 	return r, nil
 }
@@ -0,0 +1,474 @@
+package trafficmgr
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func Test_session_InterceptInfo_tcp(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	s.newMatcher(ctx, &manager.InterceptInfo{
+		Id:       "tcp",
+		Spec:     &manager.InterceptSpec{Mechanism: "tcp"},
+		Metadata: map[string]string{"a": "A"},
+	})
+	s.newMatcher(ctx, &manager.InterceptInfo{
+		Id:      "tcp-headers",
+		Spec:    &manager.InterceptSpec{Mechanism: "tcp"},
+		Headers: map[string]string{"X-Route": "me"},
+	})
+	s.newMatcher(ctx, &manager.InterceptInfo{
+		Id:       "all",
+		Spec:     &manager.InterceptSpec{Mechanism: "tcp", AllConnections: true, PortHeaders: map[int32]*manager.PortHeaders{8080: {Headers: map[string]string{"X-Port": "me"}}}},
+		Headers:  map[string]string{"X-Route": "me"},
+		Metadata: map[string]string{"b": "B"},
+	})
+	require.Len(t, s.currentMatchers, 4)
+	assert.False(t, s.currentMatchers[apiMatcherKey{id: "tcp-headers"}].allConnections)
+	assert.True(t, s.currentMatchers[apiMatcherKey{id: "all"}].allConnections)
+	assert.Nil(t, s.currentMatchers[apiMatcherKey{id: "all"}].requestMatcher)
+
+	tests := []struct {
+		name     string
+		callerID string
+		headers  http.Header
+		want     bool
+	}{
+		{"tcp without request matchers", "tcp", nil, true},
+		{"tcp with headers - not matched", "tcp-headers", http.Header{"X-Route": {"other"}}, false},
+		{"tcp with headers - matched", "tcp-headers", http.Header{"X-Route": {"me"}}, true},
+		{"all connections without matching headers", "all", http.Header{"X-Route": {"other"}}, true},
+		{"all connections with matching headers", "all", http.Header{"X-Route": {"me"}}, true},
+		{"unknown caller", "unknown", http.Header{"X-Route": {"me"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ii, err := s.InterceptInfo(ctx, tt.callerID, "/some/path", 0, netip.Addr{}, tt.headers)
+			require.NoError(t, err)
+			assert.True(t, ii.ClientSide)
+			assert.Equal(t, tt.want, ii.Intercepted)
+		})
+	}
+
+	ii, err := s.InterceptInfo(ctx, "tcp", "/", 0, netip.Addr{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "A"}, ii.Metadata)
+
+	// The headers of a port don't apply to an intercept of all connections.
+	ii, err = s.InterceptInfo(ctx, "all", "/", 8080, netip.Addr{}, nil)
+	require.NoError(t, err)
+	assert.True(t, ii.Intercepted)
+	assert.Equal(t, map[string]string{"b": "B"}, ii.Metadata)
+}
+
+func Test_session_InterceptInfo_sourceCIDRs(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	s.newMatcher(ctx, &manager.InterceptInfo{
+		Id:      "src",
+		Spec:    &manager.InterceptSpec{Mechanism: "http", SourceCidrs: []string{"10.1.0.0/16", "192.168.1.5"}},
+		Headers: map[string]string{"X-Route": "me"},
+	})
+	require.NotNil(t, s.currentMatchers[apiMatcherKey{id: "src"}].requestMatcher)
+
+	tests := []struct {
+		name     string
+		sourceIP netip.Addr
+		headers  http.Header
+		want     bool
+	}{
+		{"source in CIDR", netip.MustParseAddr("10.1.2.3"), http.Header{"X-Route": {"me"}}, true},
+		{"source equal to IP", netip.MustParseAddr("192.168.1.5"), http.Header{"X-Route": {"me"}}, true},
+		{"source outside CIDRs", netip.MustParseAddr("10.2.2.3"), http.Header{"X-Route": {"me"}}, false},
+		{"unknown source", netip.Addr{}, http.Header{"X-Route": {"me"}}, false},
+		{"source in CIDR without matching headers", netip.MustParseAddr("10.1.2.3"), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ii, err := s.InterceptInfo(ctx, "src", "/", 0, tt.sourceIP, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ii.Intercepted)
+		})
+	}
+}
+
+func Test_session_InterceptInfo_grpcMethods(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	s.newMatcher(ctx, &manager.InterceptInfo{
+		Id:      "grpc",
+		Spec:    &manager.InterceptSpec{Mechanism: "http", GrpcMethods: []string{"/pkg.Service/Method", "pkg.Other/*"}},
+		Headers: map[string]string{"X-Route": "me"},
+	})
+	require.NotNil(t, s.currentMatchers[apiMatcherKey{id: "grpc"}].requestMatcher)
+
+	grpc := func(route string) http.Header {
+		return http.Header{"Content-Type": {"application/grpc"}, "X-Route": {route}}
+	}
+	tests := []struct {
+		name    string
+		path    string
+		headers http.Header
+		want    bool
+	}{
+		{"exact method", "/pkg.Service/Method", grpc("me"), true},
+		{"other method", "/pkg.Service/Other", grpc("me"), false},
+		{"method with prefix", "/pkg.Other/Method", grpc("me"), true},
+		{"method without matching headers", "/pkg.Service/Method", grpc("you"), false},
+		{"request that isn't gRPC", "/pkg.Service/Method", http.Header{"X-Route": {"me"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ii, err := s.InterceptInfo(ctx, "grpc", tt.path, 0, netip.Addr{}, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ii.Intercepted)
+		})
+	}
+}
+
+func Test_session_InterceptInfo_portHeaders(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{}
+	s.newMatcher(ctx, &manager.InterceptInfo{
+		Id: "multi",
+		Spec: &manager.InterceptSpec{
+			Mechanism:   "http",
+			SourceCidrs: []string{"10.1.0.0/16"},
+			PortHeaders: map[int32]*manager.PortHeaders{
+				80:   {Headers: map[string]string{"X-Route": "web"}},
+				8080: {Headers: map[string]string{"X-Tenant": "acme"}},
+				0:    {Headers: map[string]string{"X-Route": "invalid"}},
+			},
+		},
+		Headers:  map[string]string{"X-Route": "me"},
+		Metadata: map[string]string{"a": "A"},
+	})
+	// The invalid port is skipped.
+	require.Len(t, s.currentMatchers, 3)
+	require.NotNil(t, s.currentMatchers[apiMatcherKey{id: "multi", port: 8080}])
+
+	src := netip.MustParseAddr("10.1.2.3")
+	tests := []struct {
+		name     string
+		port     uint16
+		sourceIP netip.Addr
+		headers  http.Header
+		want     bool
+	}{
+		{"port 80 by its header", 80, src, http.Header{"X-Route": {"web"}}, true},
+		{"port 80 not by the header of port 8080", 80, src, http.Header{"X-Tenant": {"acme"}}, false},
+		{"port 80 not by the intercept header", 80, src, http.Header{"X-Route": {"me"}}, false},
+		{"port 8080 by its header", 8080, src, http.Header{"X-Tenant": {"acme"}}, true},
+		{"port 8080 not by the header of port 80", 8080, src, http.Header{"X-Route": {"web"}}, false},
+		{"port 8080 requires the source CIDR", 8080, netip.MustParseAddr("10.2.2.3"), http.Header{"X-Tenant": {"acme"}}, false},
+		{"other port by the intercept header", 9090, src, http.Header{"X-Route": {"me"}}, true},
+		{"other port not by a port header", 9090, src, http.Header{"X-Route": {"web"}}, false},
+		{"unknown port by the intercept header", 0, src, http.Header{"X-Route": {"me"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ii, err := s.InterceptInfo(ctx, "multi", "/", tt.port, tt.sourceIP, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ii.Intercepted)
+			if tt.want {
+				assert.Equal(t, map[string]string{"a": "A"}, ii.Metadata)
+			}
+		})
+	}
+
+	// All matchers of the intercept are removed when it ends.
+	s.reconcileAPIServers(ctx)
+	assert.Empty(t, s.currentMatchers)
+}
+
+func Test_findClientConflict(t *testing.T) {
+	other := func(name, mechanism string, port int32) *manager.InterceptInfo {
+		return &manager.InterceptInfo{
+			Disposition: manager.InterceptDispositionType_ACTIVE,
+			Spec: &manager.InterceptSpec{
+				Name:          name,
+				Client:        "other@host",
+				Agent:         "echo",
+				Namespace:     "default",
+				Mechanism:     mechanism,
+				ContainerPort: port,
+			},
+		}
+	}
+	spec := func(mechanism string) *manager.InterceptSpec {
+		return &manager.InterceptSpec{Name: "mine", Agent: "echo", Namespace: "default", Mechanism: mechanism}
+	}
+	removed := other("removed", "tcp", 8080)
+	removed.Disposition = manager.InterceptDispositionType_REMOVED
+	own := other("own", "tcp", 8080)
+	own.Spec.Client = "me@host"
+	elsewhere := other("elsewhere", "tcp", 8080)
+	elsewhere.Spec.Namespace = "other"
+
+	tests := []struct {
+		name     string
+		spec     *manager.InterceptSpec
+		port     int32
+		existing []*manager.InterceptInfo
+		want     string
+	}{
+		{"global vs global", spec("tcp"), 8080, []*manager.InterceptInfo{other("theirs", "tcp", 8080)}, "theirs"},
+		{"default mechanism vs global", spec(""), 8080, []*manager.InterceptInfo{other("theirs", "tcp", 8080)}, "theirs"},
+		{"global vs http", spec("tcp"), 8080, []*manager.InterceptInfo{other("theirs", "http", 8080)}, "theirs"},
+		{"http vs global", spec("http"), 8080, []*manager.InterceptInfo{other("theirs", "tcp", 8080)}, "theirs"},
+		{"unresolved port", spec("tcp"), 0, []*manager.InterceptInfo{other("theirs", "tcp", 8080)}, "theirs"},
+		{"http vs http", spec("http"), 8080, []*manager.InterceptInfo{other("theirs", "http", 8080)}, ""},
+		{"different port", spec("tcp"), 8081, []*manager.InterceptInfo{other("theirs", "tcp", 8080)}, ""},
+		{"different workload", spec("tcp"), 8080, []*manager.InterceptInfo{elsewhere}, ""},
+		{"same client", spec("tcp"), 8080, []*manager.InterceptInfo{own}, ""},
+		{"removed", spec("tcp"), 8080, []*manager.InterceptInfo{removed}, ""},
+		{"none", spec("tcp"), 8080, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := findClientConflict(tt.spec, tt.port, "me@host", tt.existing)
+			if tt.want == "" {
+				assert.Nil(t, ic)
+			} else {
+				require.NotNil(t, ic)
+				assert.Equal(t, tt.want, ic.Spec.Name)
+			}
+		})
+	}
+}
+
+func Test_session_interceptedByOtherClient(t *testing.T) {
+	s := &session{
+		clientID: "me@host",
+		workloads: map[workloadInfoKey]workloadInfo{
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "mine"}:   {interceptClients: []string{"me@host"}},
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "theirs"}: {interceptClients: []string{"me@host", "other@host"}},
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "free"}:   {},
+		},
+		syncedNamespaces: map[string]struct{}{"a": {}},
+	}
+	assert.False(t, s.interceptedByOtherClient("a", "mine"))
+	assert.False(t, s.interceptedByOtherClient("a", "free"))
+	assert.True(t, s.interceptedByOtherClient("a", "theirs"))
+
+	// The workload watcher knows nothing about namespaces that aren't synced, so the traffic-manager must be asked.
+	assert.True(t, s.interceptedByOtherClient("b", "free"))
+}
+
+func Test_session_CanIntercept_autoMapNamespace(t *testing.T) {
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "b"}}
+	s.SetMappedNamespaces(ctx, []string{"a"})
+
+	// The workload watcher of the connected namespace has synced.
+	s.syncedNamespaces["b"] = struct{}{}
+	ir := func() *rpc.CreateInterceptRequest {
+		return &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "x", TargetPort: 8080}}
+	}
+
+	// The namespace isn't mapped unless the config says so.
+	_, er := s.CanIntercept(ctx, ir())
+	require.Nil(t, er)
+	assert.Equal(t, []string{"a"}, s.GetMappedNamespaces())
+	assert.Empty(t, s.ActualNamespace("b"))
+
+	// A refused intercept doesn't map the namespace.
+	client.GetConfig(ctx).Namespaces().AutoMapOnIntercept = true
+	bad := ir()
+	bad.Preset = "nope"
+	_, er = s.CanIntercept(ctx, bad)
+	require.NotNil(t, er)
+	assert.Equal(t, []string{"a"}, s.GetMappedNamespaces())
+
+	_, er = s.CanIntercept(ctx, ir())
+	require.Nil(t, er)
+	assert.Equal(t, []string{"a", "b"}, s.GetMappedNamespaces())
+	assert.Equal(t, "b", s.ActualNamespace("b"))
+	assert.Equal(t, []string{"a", "b"}, s.GetCurrentNamespaces(true))
+	assert.True(t, s.IsNamespaceWatched("b"))
+}
+
+func Test_session_CanIntercept_maxConcurrent(t *testing.T) {
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "b"}}
+	client.GetConfig(ctx).Intercept().MaxConcurrent = 2
+	canIntercept := func(current int) *rpc.InterceptResult {
+		s.currentIntercepts = make(map[string]*intercept, current)
+		for i := range current {
+			name := "ic-" + strconv.Itoa(i)
+			s.currentIntercepts[name] = activeIntercept(name, int32(9000+i), time.Now(), 0)
+		}
+		_, er := s.CanIntercept(ctx, &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "x", TargetPort: 8080}})
+		return er
+	}
+
+	// Below the limit.
+	assert.Nil(t, canIntercept(1))
+
+	// At and above the limit.
+	for _, current := range []int{2, 3} {
+		er := canIntercept(current)
+		require.NotNil(t, er)
+		assert.Equal(t, common.InterceptError_TOO_MANY_INTERCEPTS, er.Error)
+		assert.Equal(t, "2", er.ErrorText)
+		assert.Equal(t, int32(errcat.User), er.ErrorCategory)
+	}
+
+	// Zero means unlimited.
+	client.GetConfig(ctx).Intercept().MaxConcurrent = 0
+	assert.Nil(t, canIntercept(3))
+}
+
+func Test_session_InterceptEnvironment(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{currentIntercepts: map[string]*intercept{"1": {
+		InterceptInfo: &manager.InterceptInfo{
+			Id:          "1",
+			Spec:        &manager.InterceptSpec{Name: "echo"},
+			Environment: map[string]string{"GREETING": "say \"hi\"\nand bye"},
+		},
+		handlerContainer: "echo-handler",
+	}}}
+
+	data, err := s.InterceptEnvironment(ctx, "1", "dotenv")
+	require.NoError(t, err)
+	assert.Equal(t, "GREETING=\"say \\\"hi\\\"\\nand bye\"\nTELEPRESENCE_HANDLER_CONTAINER_NAME=echo-handler\n", string(data))
+
+	// The intercept can be found by name.
+	data, err = s.InterceptEnvironment(ctx, "echo", "sh:export")
+	require.NoError(t, err)
+	assert.Equal(t, "export GREETING='say \"hi\"\nand bye'\nexport TELEPRESENCE_HANDLER_CONTAINER_NAME=echo-handler\n", string(data))
+	assert.Len(t, s.currentIntercepts["1"].Environment, 1, "the environment of the intercept was modified")
+
+	_, err = s.InterceptEnvironment(ctx, "1", "toml")
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	_, err = s.InterceptEnvironment(ctx, "2", "json")
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+}
+
+// occupiedPort returns a local port that is in use until the test ends.
+func occupiedPort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func Test_intercept_setupError_portForward(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	port := occupiedPort(t)
+	ic := &intercept{InterceptInfo: &manager.InterceptInfo{
+		Id:   "1234:echo",
+		Spec: &manager.InterceptSpec{Name: "echo"},
+	}}
+	pa := &podAccess{podIP: "127.0.0.1", localPorts: []string{port}, setupFailed: ic.setSetupError}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	pa.workerPortForward(ctx, port, &wg)
+	assert.Equal(t, "local port "+port+" in use", ic.setupError())
+
+	// The error is reported in the InterceptInfo of the status, without altering the one from the traffic-manager.
+	s := &session{currentIntercepts: map[string]*intercept{ic.Id: ic}}
+	iis := s.getCurrentInterceptInfos()
+	require.Len(t, iis, 1)
+	assert.Equal(t, "local port "+port+" in use", iis[0].SetupError)
+	assert.Empty(t, ic.SetupError)
+
+	// A port-forward that is started again clears the error.
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	wg.Add(1)
+	pa.workerPortForward(cancelledCtx, port, &wg)
+	assert.Empty(t, ic.setupError())
+	assert.Empty(t, s.getCurrentInterceptInfos()[0].SetupError)
+}
+
+func Test_intercept_setupPending_portForward(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, l.Close())
+	ic := &intercept{InterceptInfo: &manager.InterceptInfo{
+		Id:   "1234:echo",
+		Spec: &manager.InterceptSpec{Name: "echo"},
+	}}
+	pa := &podAccess{podIP: "127.0.0.1", localPorts: []string{port}, setupStarted: ic.setSetupPending, setupFailed: ic.setSetupError}
+
+	// The port-forward is pending until it listens.
+	pa.reportStarted(port)
+	pending, _, _ := ic.setupState()
+	assert.Equal(t, []string{port}, pending)
+	var wg sync.WaitGroup
+	pa.startForwards(ctx, &wg)
+	assert.Eventually(t, func() bool {
+		pending, setupErr, _ := ic.setupState()
+		return len(pending) == 0 && setupErr == ""
+	}, 5*time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+}
+
+func Test_intercept_setupError_apiServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	port := occupiedPort(t)
+	activeIntercept := func(name string) *intercept {
+		return &intercept{InterceptInfo: &manager.InterceptInfo{
+			Id:          "1234:" + name,
+			Spec:        &manager.InterceptSpec{Name: name, Agent: name},
+			Disposition: manager.InterceptDispositionType_ACTIVE,
+			Environment: map[string]string{agentconfig.EnvAPIPort: port},
+		}}
+	}
+	echo := activeIntercept("echo")
+	s := &session{currentIntercepts: map[string]*intercept{echo.Id: echo}}
+	s.currentInterceptsLock.Lock()
+	s.reconcileAPIServers(ctx)
+	s.currentInterceptsLock.Unlock()
+
+	want := "API server port " + port + " in use"
+	require.Eventually(t, func() bool { return echo.setupError() == want }, 5*time.Second, 10*time.Millisecond)
+
+	// An intercept that arrives after the failure uses the same API server, and reports the same error.
+	other := activeIntercept("other")
+	s.currentInterceptsLock.Lock()
+	s.currentIntercepts[other.Id] = other
+	s.reconcileAPIServers(ctx)
+	s.currentInterceptsLock.Unlock()
+	assert.Equal(t, want, other.setupError())
+}
@@ -0,0 +1,121 @@
+package trafficmgr
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// AddInterceptGroup creates the intercepts of the given group one by one. If one of them fails, the intercepts
+// of the group that were already created are removed again, and the result of the failing intercept is returned.
+func (s *session) AddInterceptGroup(c context.Context, gr *rpc.CreateInterceptGroupRequest) (*rpc.InterceptGroupResult, error) {
+	if err := validateInterceptGroup(gr); err != nil {
+		return nil, err
+	}
+	if err := s.registerInterceptGroup(gr); err != nil {
+		return nil, err
+	}
+
+	self := s.self
+	results := make([]*rpc.InterceptResult, 0, len(gr.Intercepts))
+	for i, ir := range gr.Intercepts {
+		result := self.AddIntercept(c, ir)
+		if result.Error != common.InterceptError_UNSPECIFIED {
+			dlog.Debugf(c, "intercept %s of group %s failed to create, will remove the rest of the group", ir.Spec.Name, gr.Name)
+			s.rollbackInterceptGroup(c, gr, gr.Intercepts[:i])
+			return &rpc.InterceptGroupResult{Results: []*rpc.InterceptResult{result}}, nil
+		}
+		results = append(results, result)
+	}
+	return &rpc.InterceptGroupResult{Results: results}, nil
+}
+
+// validateInterceptGroup checks that the given group has a name and that its intercepts have unique names and
+// target the same workload.
+func validateInterceptGroup(gr *rpc.CreateInterceptGroupRequest) error {
+	if gr.Name == "" {
+		return errcat.User.New("an intercept group must have a name")
+	}
+	if len(gr.Intercepts) == 0 {
+		return errcat.User.Newf("intercept group %s has no intercepts", gr.Name)
+	}
+	names := make([]string, 0, len(gr.Intercepts))
+	first := gr.Intercepts[0].GetSpec()
+	for _, ir := range gr.Intercepts {
+		spec := ir.GetSpec()
+		switch {
+		case spec.GetName() == "":
+			return errcat.User.Newf("intercept group %s has an intercept without a name", gr.Name)
+		case slices.Contains(names, spec.Name):
+			return errcat.User.Newf("intercept group %s has more than one intercept named %s", gr.Name, spec.Name)
+		case spec.Agent == "":
+			return errcat.User.Newf("intercept %s of group %s has no workload", spec.Name, gr.Name)
+		case spec.Agent != first.Agent || spec.Namespace != first.Namespace:
+			return errcat.User.Newf("all intercepts of group %s must target the same workload", gr.Name)
+		}
+		names = append(names, spec.Name)
+	}
+	return nil
+}
+
+// registerInterceptGroup assigns the intercepts of the given group to that group, so that they are
+// grouped when they arrive in the currentIntercepts.
+func (s *session) registerInterceptGroup(gr *rpc.CreateInterceptGroupRequest) error {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	for _, ir := range gr.Intercepts {
+		if g, ok := s.interceptGroups[ir.Spec.Name]; ok {
+			return errcat.User.Newf("intercept %s already belongs to group %s", ir.Spec.Name, g)
+		}
+	}
+	if s.interceptGroups == nil {
+		s.interceptGroups = make(map[string]string)
+	}
+	for _, ir := range gr.Intercepts {
+		s.interceptGroups[ir.Spec.Name] = gr.Name
+	}
+	return nil
+}
+
+// rollbackInterceptGroup removes the given intercepts that were created for the given group, and
+// forgets that group.
+func (s *session) rollbackInterceptGroup(c context.Context, gr *rpc.CreateInterceptGroupRequest, created []*rpc.CreateInterceptRequest) {
+	// Make an attempt to remove the created intercepts using a time limited Context. Our
+	// context might already be done.
+	rc, cancel := context.WithTimeout(context.WithoutCancel(c), 5*time.Second)
+	defer cancel()
+	rc = withEndReason(rc, endReasonRollback)
+	for i := len(created) - 1; i >= 0; i-- {
+		name := created[i].Spec.Name
+		if err := s.self.RemoveIntercept(rc, name); err != nil {
+			dlog.Warnf(c, "failed to remove intercept %s of failed group %s: %v", name, gr.Name, err)
+		}
+	}
+	s.currentInterceptsLock.Lock()
+	for _, ir := range gr.Intercepts {
+		delete(s.interceptGroups, ir.Spec.Name)
+	}
+	s.currentInterceptsLock.Unlock()
+}
+
+// getInterceptGroups returns a map of the names of current intercepts that belong to a group to the
+// name of that group.
+func (s *session) getInterceptGroups() map[string]string {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	var groups map[string]string
+	for _, ic := range s.currentIntercepts {
+		if ic.group != "" {
+			if groups == nil {
+				groups = make(map[string]string)
+			}
+			groups[ic.Spec.Name] = ic.group
+		}
+	}
+	return groups
+}
@@ -0,0 +1,161 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// fakeInterceptSession creates and removes intercepts without talking to a traffic-manager.
+type fakeInterceptSession struct {
+	userd.Session
+	s        *session
+	failOn   string
+	pending  []string
+	setupErr error
+	created  []string
+	removed  []string
+}
+
+func (f *fakeInterceptSession) AddIntercept(ctx context.Context, ir *rpc.CreateInterceptRequest) *rpc.InterceptResult {
+	name := ir.Spec.Name
+	if name == f.failOn {
+		return InterceptError(common.InterceptError_FAILED_TO_ESTABLISH, errcat.User.New(name))
+	}
+	f.created = append(f.created, name)
+	f.s.setCurrentIntercepts(ctx, f.interceptInfos())
+	ic := f.s.getInterceptByName(name)
+	for _, key := range f.pending {
+		ic.setSetupPending(key)
+	}
+	if f.setupErr != nil {
+		ic.setSetupError(setupMount, f.setupErr)
+	}
+	return &rpc.InterceptResult{InterceptInfo: &manager.InterceptInfo{Id: name, Spec: ir.Spec}}
+}
+
+func (f *fakeInterceptSession) RemoveIntercept(ctx context.Context, name string) error {
+	f.removed = append(f.removed, name)
+	for i, c := range f.created {
+		if c == name {
+			f.created = append(f.created[:i], f.created[i+1:]...)
+			break
+		}
+	}
+	f.s.setCurrentIntercepts(ctx, f.interceptInfos())
+	return nil
+}
+
+func (f *fakeInterceptSession) interceptInfos() []*manager.InterceptInfo {
+	iis := make([]*manager.InterceptInfo, len(f.created))
+	for i, name := range f.created {
+		iis[i] = &manager.InterceptInfo{
+			Id:          name,
+			Spec:        &manager.InterceptSpec{Name: name, Agent: "echo", Namespace: "default"},
+			Disposition: manager.InterceptDispositionType_ACTIVE,
+			PodIp:       "10.1.2.3",
+		}
+	}
+	return iis
+}
+
+func newFakeInterceptSession(failOn string) (*session, *fakeInterceptSession) {
+	s := &session{interceptWaiters: make(map[string]*awaitIntercept)}
+	f := &fakeInterceptSession{s: s, failOn: failOn}
+	s.self = f
+	return s, f
+}
+
+func interceptGroupRequest(name string, icepts ...string) *rpc.CreateInterceptGroupRequest {
+	gr := &rpc.CreateInterceptGroupRequest{Name: name}
+	for _, ic := range icepts {
+		gr.Intercepts = append(gr.Intercepts, &rpc.CreateInterceptRequest{
+			Spec: &manager.InterceptSpec{Name: ic, Agent: "echo", Namespace: "default"},
+		})
+	}
+	return gr
+}
+
+func Test_session_AddInterceptGroup(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("")
+
+	result, err := s.AddInterceptGroup(ctx, interceptGroupRequest("echo", "echo-http", "echo-grpc"))
+	require.NoError(t, err)
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, "echo-http", result.Results[0].InterceptInfo.Id)
+	assert.Equal(t, "echo-grpc", result.Results[1].InterceptInfo.Id)
+	assert.Equal(t, []string{"echo-http", "echo-grpc"}, f.created)
+	assert.Equal(t, map[string]string{"echo-http": "echo", "echo-grpc": "echo"}, s.getInterceptGroups())
+
+	// The intercepts leave the group when they end.
+	require.NoError(t, f.RemoveIntercept(ctx, "echo-http"))
+	assert.Equal(t, map[string]string{"echo-grpc": "echo"}, s.getInterceptGroups())
+	_, ok := s.interceptGroups["echo-http"]
+	assert.False(t, ok)
+}
+
+func Test_session_AddInterceptGroup_rollback(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("echo-metrics")
+
+	result, err := s.AddInterceptGroup(ctx, interceptGroupRequest("echo", "echo-http", "echo-grpc", "echo-metrics", "echo-admin"))
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, common.InterceptError_FAILED_TO_ESTABLISH, result.Results[0].Error)
+	assert.Equal(t, "echo-metrics", result.Results[0].ErrorText)
+
+	// The intercepts that were created are removed in reverse order, and the one after the failing one is never created.
+	assert.Equal(t, []string{"echo-grpc", "echo-http"}, f.removed)
+	assert.Empty(t, f.created)
+	assert.Empty(t, s.currentIntercepts)
+	assert.Empty(t, s.interceptGroups)
+	assert.Nil(t, s.getInterceptGroups())
+
+	// The group can be created again once the problem is fixed.
+	f.failOn = ""
+	result, err = s.AddInterceptGroup(ctx, interceptGroupRequest("echo", "echo-http", "echo-grpc"))
+	require.NoError(t, err)
+	assert.Len(t, result.Results, 2)
+}
+
+func Test_session_AddInterceptGroup_invalid(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("")
+	_, err := s.AddInterceptGroup(ctx, interceptGroupRequest("echo", "echo-http"))
+	require.NoError(t, err)
+
+	otherWorkload := interceptGroupRequest("mixed", "a", "b")
+	otherWorkload.Intercepts[1].Spec.Agent = "other"
+
+	tests := []struct {
+		name string
+		gr   *rpc.CreateInterceptGroupRequest
+		msg  string
+	}{
+		{"no name", interceptGroupRequest("", "a"), "must have a name"},
+		{"no intercepts", interceptGroupRequest("empty"), "has no intercepts"},
+		{"duplicate intercept", interceptGroupRequest("dup", "a", "a"), "more than one intercept named a"},
+		{"different workloads", otherWorkload, "must target the same workload"},
+		{"intercept in other group", interceptGroupRequest("again", "a", "echo-http"), "already belongs to group echo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.AddInterceptGroup(ctx, tt.gr)
+			require.Error(t, err)
+			assert.Equal(t, errcat.User, errcat.GetCategory(err))
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+	assert.Equal(t, []string{"echo-http"}, f.created)
+	assert.Equal(t, map[string]string{"echo-http": "echo"}, s.interceptGroups)
+}
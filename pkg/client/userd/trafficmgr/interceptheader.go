@@ -0,0 +1,97 @@
+package trafficmgr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/maps"
+	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
+	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
+)
+
+// httpHeaderArgPrefix is the prefix of a mechanism argument that makes an "http" intercept match on a header.
+const httpHeaderArgPrefix = "--http-header="
+
+// maxInterceptHeaderAttempts is the number of header values that are tried before giving up on finding one
+// that no other intercept matches.
+const maxInterceptHeaderAttempts = 16
+
+// interceptHeaderValue returns the value of a generated intercept header. The value is derived from the
+// client ID and the intercept name, so that the same intercept gets the same value every time it's created.
+// A non-zero attempt yields an alternative value for use when the first one collides.
+func interceptHeaderValue(clientID, name string, attempt int) string {
+	h := sha256.New()
+	h.Write([]byte(clientID))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	if attempt > 0 {
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(attempt)))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// headerArgs returns the headers that the --http-header arguments of the given mechanism arguments match.
+func headerArgs(args []string) map[string]string {
+	hs := make(map[string]string)
+	for _, arg := range args {
+		if kv, ok := strings.CutPrefix(arg, httpHeaderArgPrefix); ok {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				hs[k] = v
+			}
+		}
+	}
+	return hs
+}
+
+// generateInterceptHeader generates an x-telepresence-intercept-id header for an "http" intercept that
+// doesn't match on any header, when the CreateInterceptRequest asks for it using generate_header, and adds it to the mechanism arguments of the given spec. A value that
+// the header matchers of the other current intercepts would match is regenerated, so that requests that
+// carry the header reach this intercept only. The generated header is returned, or nil if the spec
+// didn't need one.
+func (s *session) generateInterceptHeader(spec *manager.InterceptSpec) (map[string]string, error) {
+	if spec.Mechanism != "http" || len(headerArgs(spec.MechanismArgs)) > 0 {
+		return nil, nil
+	}
+	others := s.interceptHeaderMatchers(spec.Name)
+	for attempt := 0; attempt < maxInterceptHeaderAttempts; attempt++ {
+		value := interceptHeaderValue(s.clientID, spec.Name, attempt)
+		h := http.Header{}
+		h.Set(restapi.HeaderInterceptID, value)
+		if slices.ContainsFunc(others, func(m matcher.Headers) bool { return m.Matches(h) }) {
+			continue
+		}
+		spec.MechanismArgs = append(spec.MechanismArgs, httpHeaderArgPrefix+restapi.HeaderInterceptID+"="+value)
+		return map[string]string{restapi.HeaderInterceptID: value}, nil
+	}
+	return nil, errcat.User.Newf("unable to generate a %s header that no other intercept matches", restapi.HeaderInterceptID)
+}
+
+// interceptHeaderMatchers returns the header matchers of the current intercepts, except the one with the
+// given name. The headers are those of the intercept's --http-header arguments together with the headers
+// reported by the traffic-agent. Intercepts that don't match on headers are skipped.
+func (s *session) interceptHeaderMatchers(name string) []matcher.Headers {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	var hms []matcher.Headers
+	for _, ic := range s.currentIntercepts {
+		if ic.Spec.Name == name {
+			continue
+		}
+		hs := headerArgs(ic.Spec.MechanismArgs)
+		maps.Merge(hs, ic.Headers)
+		if len(hs) == 0 {
+			continue
+		}
+		if hm, err := matcher.NewHeaders(hs); err == nil {
+			hms = append(hms, hm)
+		}
+	}
+	return hms
+}
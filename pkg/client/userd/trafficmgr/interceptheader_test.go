@@ -0,0 +1,83 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
+)
+
+func Test_headerArgs(t *testing.T) {
+	assert.Equal(t, map[string]string{"x-user": "me", "x-env": "a=b"}, headerArgs([]string{
+		"--http-header=x-user=me",
+		"--http-path-prefix=/api",
+		"--http-header=x-env=a=b",
+		"--http-header=invalid",
+	}))
+	assert.Empty(t, headerArgs(nil))
+}
+
+func Test_session_generateInterceptHeader(t *testing.T) {
+	s := &session{clientID: "test@localhost", currentIntercepts: make(map[string]*intercept)}
+	addCurrent := func(name string, args []string, headers map[string]string) {
+		s.currentIntercepts[name] = &intercept{InterceptInfo: &manager.InterceptInfo{
+			Id:      name,
+			Spec:    &manager.InterceptSpec{Name: name, Mechanism: "http", MechanismArgs: args},
+			Headers: headers,
+		}}
+	}
+	headerArg := func(value string) string {
+		return httpHeaderArgPrefix + restapi.HeaderInterceptID + "=" + value
+	}
+	value := func(attempt int) string {
+		return interceptHeaderValue("test@localhost", "echo", attempt)
+	}
+
+	// No header is generated for a "tcp" intercept, nor for an intercept that matches on a header already.
+	spec := &manager.InterceptSpec{Name: "echo", Mechanism: "tcp"}
+	hs, err := s.generateInterceptHeader(spec)
+	require.NoError(t, err)
+	assert.Nil(t, hs)
+	assert.Empty(t, spec.MechanismArgs)
+
+	spec = &manager.InterceptSpec{Name: "echo", Mechanism: "http", MechanismArgs: []string{"--http-header=x-user=me"}}
+	hs, err = s.generateInterceptHeader(spec)
+	require.NoError(t, err)
+	assert.Nil(t, hs)
+	assert.Equal(t, []string{"--http-header=x-user=me"}, spec.MechanismArgs)
+
+	// The value is the same every time the intercept is created.
+	for range 2 {
+		spec = &manager.InterceptSpec{Name: "echo", Mechanism: "http", MechanismArgs: []string{"--http-path-prefix=/api"}}
+		hs, err = s.generateInterceptHeader(spec)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{restapi.HeaderInterceptID: value(0)}, hs)
+		assert.Equal(t, []string{"--http-path-prefix=/api", headerArg(value(0))}, spec.MechanismArgs)
+	}
+	assert.NotEqual(t, value(0), interceptHeaderValue("test@localhost", "other", 0))
+	assert.NotEqual(t, value(0), interceptHeaderValue("other@localhost", "echo", 0))
+
+	// A value that another intercept matches, by its arguments or by the headers reported by its agent,
+	// is regenerated.
+	addCurrent("a", []string{headerArg(value(0))}, nil)
+	addCurrent("b", nil, map[string]string{restapi.HeaderInterceptID: value(1)})
+	addCurrent("c", []string{"--http-header=x-user=me", headerArg(value(2))}, nil) // doesn't match the value alone
+	addCurrent("echo", []string{headerArg(value(2))}, nil)                         // the intercept itself is ignored
+	spec = &manager.InterceptSpec{Name: "echo", Mechanism: "http"}
+	hs, err = s.generateInterceptHeader(spec)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{restapi.HeaderInterceptID: value(2)}, hs)
+	assert.Equal(t, []string{headerArg(value(2))}, spec.MechanismArgs)
+
+	// An intercept that matches any value makes generation impossible.
+	addCurrent("any", []string{httpHeaderArgPrefix + restapi.HeaderInterceptID + "=.*"}, nil)
+	spec = &manager.InterceptSpec{Name: "echo", Mechanism: "http"}
+	_, err = s.generateInterceptHeader(spec)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Empty(t, spec.MechanismArgs)
+}
@@ -0,0 +1,72 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// AddInterceptAndWait creates the given intercept and waits until it's ready, i.e. until its local port-forwards
+// listen and its mounts are established. The intercept timeout is used when the given timeout is zero. An
+// intercept that fails to start, or that isn't ready when the timeout expires, is removed.
+func (s *session) AddInterceptAndWait(c context.Context, ir *rpc.CreateInterceptRequest, timeout time.Duration) *rpc.InterceptResult {
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		c, cancel = context.WithTimeout(c, timeout)
+	} else {
+		c, cancel = client.GetConfig(c).Timeouts().TimeoutContext(c, client.TimeoutIntercept)
+	}
+	defer cancel()
+
+	self := s.self
+	result := self.AddIntercept(c, ir)
+	if result.Error != common.InterceptError_UNSPECIFIED || result.InterceptInfo == nil {
+		return result
+	}
+	name := ir.Spec.Name
+	if err := s.awaitInterceptReady(c, name); err != nil {
+		dlog.Debugf(c, "intercept %s didn't become ready, will remove...", name)
+
+		// Make an attempt to remove the intercept using a time limited Context. Our
+		// context is already done.
+		rc, cancel := context.WithTimeout(context.WithoutCancel(c), 5*time.Second)
+		defer cancel()
+		if removeErr := self.RemoveIntercept(withEndReason(rc, endReasonRollback), name); removeErr != nil {
+			dlog.Warnf(c, "failed to remove intercept %s: %v", name, removeErr)
+		}
+		return InterceptError(common.InterceptError_FAILED_TO_ESTABLISH, err)
+	}
+	return result
+}
+
+// awaitInterceptReady waits until the port-forwards and mounts of the intercept with the given name are ready.
+// They are started, and hence pending, when AddIntercept returns. An error is returned as soon as one of them
+// fails, or when the intercept ends or the given context is done.
+func (s *session) awaitInterceptReady(ctx context.Context, name string) error {
+	ic := s.getInterceptByName(name)
+	if ic == nil {
+		return fmt.Errorf("intercept %s is not among the current intercepts", name)
+	}
+	for {
+		pending, setupErr, changed := ic.setupState()
+		if setupErr != "" {
+			return fmt.Errorf("intercept %s failed to start: %s", name, setupErr)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("intercept %s is not ready: waiting for %s: %w", name, strings.Join(pending, ", "), client.CheckTimeout(ctx, ctx.Err()))
+		case <-ic.ctx.Done():
+			return fmt.Errorf("intercept %s ended before it was ready", name)
+		case <-changed:
+		}
+	}
+}
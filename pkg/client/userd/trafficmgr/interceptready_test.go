@@ -0,0 +1,106 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func interceptRequest(name string) *rpc.CreateInterceptRequest {
+	return &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: name, Agent: "echo", Namespace: "default"}}
+}
+
+func Test_session_AddInterceptAndWait(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("")
+
+	result := s.AddInterceptAndWait(ctx, interceptRequest("echo"), 5*time.Second)
+	require.Equal(t, common.InterceptError_UNSPECIFIED, result.Error, result.ErrorText)
+	assert.Equal(t, "echo", result.InterceptInfo.Id)
+	assert.Equal(t, []string{"echo"}, f.created)
+	assert.Empty(t, f.removed)
+}
+
+func Test_session_AddInterceptAndWait_pending(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("")
+	f.pending = []string{"8080", setupMount}
+
+	done := make(chan *rpc.InterceptResult, 1)
+	go func() { done <- s.AddInterceptAndWait(ctx, interceptRequest("echo"), 5*time.Second) }()
+
+	// The intercept isn't ready until its port-forward and mount are.
+	var ic *intercept
+	require.Eventually(t, func() bool {
+		if ic = s.getInterceptByName("echo"); ic == nil {
+			return false
+		}
+		pending, _, _ := ic.setupState()
+		return len(pending) == 2
+	}, 5*time.Second, time.Millisecond)
+	ic.setSetupError("8080", nil)
+	select {
+	case <-done:
+		t.Fatal("intercept reported ready while its mount is pending")
+	case <-time.After(50 * time.Millisecond):
+	}
+	ic.setSetupError(setupMount, nil)
+
+	select {
+	case result := <-done:
+		require.Equal(t, common.InterceptError_UNSPECIFIED, result.Error, result.ErrorText)
+		assert.Empty(t, f.removed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("intercept didn't become ready")
+	}
+}
+
+func Test_session_AddInterceptAndWait_timeout(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("")
+	f.pending = []string{"8080"}
+
+	result := s.AddInterceptAndWait(ctx, interceptRequest("echo"), 200*time.Millisecond)
+	assert.Equal(t, common.InterceptError_FAILED_TO_ESTABLISH, result.Error)
+	assert.Contains(t, result.ErrorText, "waiting for 8080")
+	assert.Contains(t, result.ErrorText, context.DeadlineExceeded.Error())
+
+	// The intercept that didn't become ready is removed.
+	assert.Equal(t, []string{"echo"}, f.removed)
+	assert.Empty(t, f.created)
+	assert.Empty(t, s.currentIntercepts)
+}
+
+func Test_session_AddInterceptAndWait_setupError(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("")
+	f.pending = []string{"8080"}
+	f.setupErr = errors.New("mount failed")
+
+	// The wait ends as soon as something fails, without waiting for the pending port-forward.
+	start := time.Now()
+	result := s.AddInterceptAndWait(ctx, interceptRequest("echo"), 5*time.Second)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, common.InterceptError_FAILED_TO_ESTABLISH, result.Error)
+	assert.Contains(t, result.ErrorText, "mount failed")
+	assert.Equal(t, []string{"echo"}, f.removed)
+}
+
+func Test_session_AddInterceptAndWait_failed(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s, f := newFakeInterceptSession("echo")
+
+	result := s.AddInterceptAndWait(ctx, interceptRequest("echo"), 5*time.Second)
+	assert.Equal(t, common.InterceptError_FAILED_TO_ESTABLISH, result.Error)
+	assert.Equal(t, "echo", result.ErrorText)
+	assert.Empty(t, f.removed)
+}
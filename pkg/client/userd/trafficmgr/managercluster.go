@@ -0,0 +1,41 @@
+package trafficmgr
+
+import (
+	"context"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	argorollouts "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/portforward"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// withManagerCluster returns a context that directs the Kubernetes API calls and port-forwards that are used when
+// connecting to the traffic-manager to the cluster given by cluster.managerKubeconfig and cluster.managerContext.
+// The given context is returned unchanged when no such cluster is configured. The returned context must not be
+// used for anything that concerns the workloads, such as the namespace and workload watchers.
+func withManagerCluster(ctx context.Context) (context.Context, error) {
+	rc, err := client.GetConfig(ctx).Cluster().ManagerRestConfig()
+	if err != nil || rc == nil {
+		return ctx, err
+	}
+	cs, err := kubernetes.NewForConfig(rc)
+	if err != nil {
+		return ctx, err
+	}
+	acs, err := argorollouts.NewForConfig(rc)
+	if err != nil {
+		return ctx, err
+	}
+	dlog.Infof(ctx, "Will look for traffic manager in cluster %s", rc.Host)
+	return withManagerClients(ctx, cs, acs, rc), nil
+}
+
+// withManagerClients returns a context that uses the given clients and rest.Config of the traffic-manager cluster.
+func withManagerClients(ctx context.Context, ki kubernetes.Interface, ari argorollouts.Interface, rc *rest.Config) context.Context {
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, ki, ari)
+	return portforward.WithRestConfig(ctx, rc)
+}
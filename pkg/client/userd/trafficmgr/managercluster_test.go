@@ -0,0 +1,46 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func Test_withManagerCluster(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+	workloadCS := fake.NewClientset(&core.Service{ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "default"}})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, workloadCS, argorolloutsfake.NewSimpleClientset())
+
+	// Without a manager cluster, the workload cluster is used.
+	mgrCtx, err := withManagerCluster(ctx)
+	require.NoError(t, err)
+	assert.Same(t, workloadCS, k8sapi.GetK8sInterface(mgrCtx))
+
+	// An invalid manager cluster is a config error.
+	cfg := client.GetDefaultConfig()
+	cfg.Cluster().ManagerKubeconfig = "/does/not/exist"
+	_, err = withManagerCluster(client.WithConfig(ctx, cfg))
+	assert.Error(t, err)
+
+	managerCS := fake.NewClientset(&core.Service{ObjectMeta: meta.ObjectMeta{Name: "traffic-manager", Namespace: "hub"}})
+	mgrCtx = withManagerClients(ctx, managerCS, argorolloutsfake.NewSimpleClientset(), &rest.Config{Host: "https://hub.example.com"})
+
+	// The traffic-manager is found in the manager cluster only.
+	assert.NoError(t, CheckTrafficManagerService(mgrCtx, "hub"))
+	assert.ErrorIs(t, CheckTrafficManagerService(ctx, "hub"), ErrTrafficManagerNotFound)
+
+	// The context used for the workloads is unaffected.
+	assert.Same(t, workloadCS, k8sapi.GetK8sInterface(ctx))
+	assert.Same(t, managerCS, k8sapi.GetK8sInterface(mgrCtx))
+}
@@ -0,0 +1,78 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// managerDisplayName returns the name of the traffic-manager that reported the given version info.
+func managerDisplayName(vi *manager.VersionInfo2) string {
+	if vi.Name == "" {
+		// Older traffic-managers doesn't distinguish between OSS and pro versions
+		return "Traffic Manager"
+	}
+	return vi.Name
+}
+
+// managerVersionLoop checks the name and version of the traffic-manager every session.managerRefreshInterval,
+// using the clock of the context. It returns right away when no interval is configured.
+func (s *session) managerVersionLoop(ctx context.Context) error {
+	interval := client.GetConfig(ctx).Session().ManagerRefreshInterval
+	if interval <= 0 {
+		return nil
+	}
+	ticker := client.GetClock(ctx).NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+		}
+		if err := s.refreshManagerVersion(ctx, s.ManagerClient()); err != nil {
+			dlog.Warnf(ctx, "unable to refresh the traffic-manager version: %v", err)
+		}
+	}
+}
+
+// refreshManagerVersion asks the given client for the name and version of the traffic-manager and updates the
+// session with them. A ManagerChanged event is emitted when they differ from the ones that the session had.
+func (s *session) refreshManagerVersion(ctx context.Context, mc manager.ManagerClient) error {
+	ctx, cancel := client.GetConfig(ctx).Timeouts().TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	vi, err := mc.Version(ctx, &emptypb.Empty{})
+	if err != nil {
+		return client.CheckTimeout(ctx, err)
+	}
+	version, err := semver.Parse(strings.TrimPrefix(vi.Version, "v"))
+	if err != nil {
+		return fmt.Errorf("unable to parse manager.Version: %w", err)
+	}
+	name := managerDisplayName(vi)
+
+	s.managerLock.Lock()
+	prevName, prevVersion := s.managerName, s.managerVersion
+	s.managerName, s.managerVersion = name, version
+	s.managerLock.Unlock()
+	if name == prevName && version.EQ(prevVersion) {
+		return nil
+	}
+
+	dlog.Infof(ctx, "Traffic-manager changed from %s %s to %s %s", prevName, prevVersion, name, version)
+	s.emitEvent(ctx, &rpc.SessionEvent{Event: &rpc.SessionEvent_ManagerChanged_{
+		ManagerChanged: &rpc.SessionEvent_ManagerChanged{
+			Previous: &manager.VersionInfo2{Name: prevName, Version: "v" + prevVersion.String()},
+			Current:  &manager.VersionInfo2{Name: name, Version: "v" + version.String()},
+		},
+	}})
+	return nil
+}
@@ -0,0 +1,63 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+func Test_session_refreshManagerVersion(t *testing.T) {
+	mgr := &fakeDroppingManager{version: &manager.VersionInfo2{Version: "v2.21.0"}}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+	s.managerVersion = semver.MustParse("2.21.0")
+	evs := s.Events(ctx)
+
+	// A traffic-manager that reports no name is an older OSS traffic-manager, so nothing changed.
+	require.NoError(t, s.refreshManagerVersion(ctx, mgr))
+	assert.Equal(t, "Traffic Manager", s.ManagerName())
+	assert.Empty(t, evs)
+
+	// The traffic-manager is upgraded to a pro variant during the session.
+	mgr.version = &manager.VersionInfo2{Name: "Telepresence Pro", Version: "v2.22.0"}
+	require.NoError(t, s.refreshManagerVersion(ctx, mgr))
+	assert.Equal(t, "Telepresence Pro", s.ManagerName())
+	assert.Equal(t, semver.MustParse("2.22.0"), s.ManagerVersion())
+	mc := nextEvent(t, evs).GetManagerChanged()
+	require.NotNil(t, mc)
+	assert.Equal(t, "Traffic Manager", mc.Previous.Name)
+	assert.Equal(t, "v2.21.0", mc.Previous.Version)
+	assert.Equal(t, "Telepresence Pro", mc.Current.Name)
+	assert.Equal(t, "v2.22.0", mc.Current.Version)
+
+	// A version that can't be parsed leaves the session untouched.
+	mgr.version = &manager.VersionInfo2{Name: "Telepresence Pro", Version: "latest"}
+	assert.Error(t, s.refreshManagerVersion(ctx, mgr))
+	assert.Equal(t, semver.MustParse("2.22.0"), s.ManagerVersion())
+	assert.Empty(t, evs)
+}
+
+func Test_session_managerVersionLoop(t *testing.T) {
+	mgr := &fakeDroppingManager{version: &manager.VersionInfo2{Name: "Telepresence Pro", Version: "v2.22.0"}}
+	ctx, s, fc := newTestSession(t, mgr, &fakeRootDaemon{})
+	cfg := client.GetDefaultConfig()
+	cfg.Session().ManagerRefreshInterval = time.Minute
+	ctx, cancel := context.WithCancel(client.WithConfig(ctx, cfg))
+	done := make(chan error, 1)
+	go func() { done <- s.managerVersionLoop(ctx) }()
+
+	waitForWaiters(t, fc)
+	assert.Equal(t, "Traffic Manager", s.ManagerName())
+	fc.Step(time.Minute)
+	require.Eventually(t, func() bool { return s.ManagerName() == "Telepresence Pro" }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, semver.MustParse("2.22.0"), s.ManagerVersion())
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -3,6 +3,7 @@ package trafficmgr
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"google.golang.org/grpc/codes"
@@ -64,9 +65,18 @@ func (pa *podAccess) startMount(ctx context.Context, iceptWG, podWG *sync.WaitGr
 		}
 		*pa.mounter = m
 	}
+	pa.reportStarted(setupMount)
 	err := m.Start(mountCtx, pa.workload, pa.container, pa.clientMountPoint, pa.mountPoint, iputil.Parse(pa.podIP), uint16(port), pa.readOnly)
-	if err != nil && ctx.Err() == nil {
+	if err == nil {
+		pa.reportSetup(setupMount, nil)
+	} else if ctx.Err() == nil {
 		dlog.Error(ctx, err)
+		if pa.localMountPort != 0 {
+			err = localPortError("local mount port", strconv.Itoa(int(pa.localMountPort)), err)
+		} else {
+			err = fmt.Errorf("remote mount failed: %w", err)
+		}
+		pa.reportSetup(setupMount, err)
 	}
 }
 
@@ -0,0 +1,131 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/informer"
+)
+
+// informerSyncTimeout is the maximum time that a snapshot of the workloads waits for the informers of a
+// namespace to sync.
+const informerSyncTimeout = 5 * time.Second
+
+// informerSyncPoll is the interval at which the sync of the informers, and their errors, are checked.
+const informerSyncPoll = 50 * time.Millisecond
+
+// namespaceInformers are the informers that cache the resources of a namespace that the exposure precheck and
+// the pod listing need. An informer is started the first time its resource is requested, and runs until the
+// namespace is pruned or the session ends.
+type namespaceInformers struct {
+	factory informer.GlobalFactory
+	done    <-chan struct{}
+	cancel  context.CancelFunc
+
+	// synced and errs are keyed by the resource, e.g. "services". The errs hold the last watch error of each
+	// informer.
+	lock   sync.Mutex
+	synced map[string]cache.InformerSynced
+	errs   map[string]error
+}
+
+// addInformer registers the informer of the given resource, so that it's waited for and its errors are reported.
+// An informer that is already registered is left as is. The caller must start the factory of the informer.
+func (ni *namespaceInformers) addInformer(resource string, ix cache.SharedIndexInformer) {
+	ni.lock.Lock()
+	defer ni.lock.Unlock()
+	if _, ok := ni.synced[resource]; ok {
+		return
+	}
+	_ = ix.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		ni.lock.Lock()
+		ni.errs[resource] = fmt.Errorf("unable to list %s: %w", resource, err)
+		ni.lock.Unlock()
+	})
+	ni.synced[resource] = ix.HasSynced
+}
+
+// syncState returns the resources among the given ones whose informers haven't synced, and the error of the
+// first of them that has failed, if any.
+func (ni *namespaceInformers) syncState(resources []string) ([]string, error) {
+	ni.lock.Lock()
+	defer ni.lock.Unlock()
+	var pending []string
+	for _, r := range resources {
+		if synced, ok := ni.synced[r]; ok && synced() {
+			continue
+		}
+		if err := ni.errs[r]; err != nil {
+			return nil, err
+		}
+		pending = append(pending, r)
+	}
+	return pending, nil
+}
+
+// waitForSync waits for the informers of the given resources to sync. An informer that has failed isn't waited
+// for, so a namespace where a resource cannot be listed, e.g. because RBAC doesn't permit it, doesn't delay each
+// snapshot. Its error is returned instead. An error is also returned when the informers don't sync within the
+// informerSyncTimeout.
+func (ni *namespaceInformers) waitForSync(ctx context.Context, resources ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, informerSyncTimeout)
+	defer cancel()
+	ticker := time.NewTicker(informerSyncPoll)
+	defer ticker.Stop()
+	for {
+		pending, err := ni.syncState(resources)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for the %s to sync", strings.Join(pending, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// getNamespaceInformers returns the informers of the given namespace. They share the informer factory of the
+// namespace, which is created the first time the namespace is requested.
+func (s *session) getNamespaceInformers(ctx context.Context, namespace string) *namespaceInformers {
+	s.namespaceInformersLock.Lock()
+	defer s.namespaceInformersLock.Unlock()
+	ni, ok := s.namespaceInformers[namespace]
+	if !ok {
+		iCtx, cancel := context.WithCancel(ctx)
+		ni = &namespaceInformers{
+			factory: informer.GetFactory(informer.WithFactory(iCtx, namespace), namespace),
+			done:    iCtx.Done(),
+			cancel:  cancel,
+			synced:  make(map[string]cache.InformerSynced),
+			errs:    make(map[string]error),
+		}
+		if s.namespaceInformers == nil {
+			s.namespaceInformers = make(map[string]*namespaceInformers)
+		}
+		s.namespaceInformers[namespace] = ni
+	}
+	return ni
+}
+
+// pruneNamespaceInformers stops the informers of all namespaces that aren't in the given list of current
+// namespaces.
+func (s *session) pruneNamespaceInformers(current []string) {
+	s.namespaceInformersLock.Lock()
+	defer s.namespaceInformersLock.Unlock()
+	for ns, ni := range s.namespaceInformers {
+		if !slices.Contains(current, ns) {
+			ni.cancel()
+			delete(s.namespaceInformers, ns)
+		}
+	}
+}
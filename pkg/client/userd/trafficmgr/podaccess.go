@@ -3,6 +3,7 @@ package trafficmgr
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/netip"
 	"sync"
 	"time"
@@ -47,6 +48,14 @@ type podAccess struct {
 
 	// Mount read-only
 	readOnly bool
+
+	// setupStarted, when set, is called when a port-forward or mount is started, keyed by what was
+	// started.
+	setupStarted func(key string)
+
+	// setupFailed, when set, is called with the error of a port-forward or mount that failed to
+	// start, keyed by what failed, and with a nil error when it's ready.
+	setupFailed func(key string, err error)
 }
 
 // podAccessKey identifies an intercepted pod. Although an ingest or intercept may span multiple
@@ -84,6 +93,18 @@ type podAccessTracker struct {
 	mountsReady map[podAccessKey]chan struct{}
 }
 
+func (pa *podAccess) reportStarted(key string) {
+	if pa.setupStarted != nil {
+		pa.setupStarted(key)
+	}
+}
+
+func (pa *podAccess) reportSetup(key string, err error) {
+	if pa.setupFailed != nil {
+		pa.setupFailed(key, err)
+	}
+}
+
 func (pa *podAccess) shouldForward() bool {
 	return len(pa.localPorts) > 0
 }
@@ -98,6 +119,9 @@ func (pa *podAccess) startForwards(ctx context.Context, wg *sync.WaitGroup) {
 		} else {
 			pfCtx = dgroup.WithGoroutineName(ctx, fmt.Sprintf("/%s:%s", pa.podIP, port))
 		}
+		// The port-forward is reported as started before its goroutine runs, so that it's pending when the
+		// intercept that it belongs to is returned.
+		pa.reportStarted(port)
 		wg.Add(1)
 		go pa.workerPortForward(pfCtx, port, wg)
 	}
@@ -132,17 +156,35 @@ func (pa *podAccess) workerPortForward(ctx context.Context, port string, wg *syn
 	pp, err := agentconfig.NewPortAndProto(port)
 	if err != nil {
 		dlog.Errorf(ctx, "malformed extra port %q: %v", port, err)
+		pa.reportSetup(port, fmt.Errorf("malformed local port %q: %w", port, err))
 		return
 	}
 	addr, err := pp.Addr()
 	if err != nil {
 		dlog.Errorf(ctx, "unable to resolve extra port %q: %v", port, err)
+		pa.reportSetup(port, fmt.Errorf("unable to resolve local port %q: %w", port, err))
 		return
 	}
 	f := forwarder.NewInterceptor(addr, pa.podIP, pp.Port)
-	err = f.Serve(ctx, nil)
+	pa.reportStarted(port)
+
+	// The port-forward is ready when it listens.
+	initCh := make(chan net.Addr, 1)
+	served := make(chan struct{})
+	go func() {
+		select {
+		case <-served:
+		case _, ok := <-initCh:
+			if ok {
+				pa.reportSetup(port, nil)
+			}
+		}
+	}()
+	err = f.Serve(ctx, initCh)
+	close(served)
 	if err != nil && ctx.Err() == nil {
 		dlog.Errorf(ctx, "port-forwarder failed with %v", err)
+		pa.reportSetup(port, localPortError("local port", port, err))
 	}
 }
 
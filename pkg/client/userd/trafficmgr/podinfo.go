@@ -0,0 +1,111 @@
+package trafficmgr
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// rolloutPodTemplateHashKey is the label that Argo Rollouts adds to the pods and replica sets of a Rollout.
+const rolloutPodTemplateHashKey = "rollouts-pod-template-hash"
+
+// podLister returns a lister for the pods in the given namespace. The informer that backs it is started
+// the first time the namespace is requested, and runs until the namespace is pruned or the session ends.
+// An error is returned when the pods cannot be listed, e.g. because RBAC doesn't permit it, or when the
+// informer doesn't sync in time.
+func (s *session) podLister(ctx context.Context, namespace string) (listers.PodLister, error) {
+	ni := s.getNamespaceInformers(ctx, namespace)
+	kf := ni.factory.GetK8sInformerFactory()
+	pods := kf.Core().V1().Pods()
+	ni.addInformer("pods", pods.Informer())
+	kf.Start(ni.done)
+	if err := ni.waitForSync(ctx, "pods"); err != nil {
+		return nil, err
+	}
+	return pods.Lister(), nil
+}
+
+// setPodInfos assigns the pods of each of the given workloads, as found in the pod informer cache of
+// its namespace. The namespaces where the pods couldn't be listed are returned, mapped to the reason why.
+func (s *session) setPodInfos(ctx context.Context, wis []*rpc.WorkloadInfo) map[string]string {
+	var errs map[string]string
+	podsByNs := make(map[string][]*core.Pod)
+	for _, wi := range wis {
+		pods, ok := podsByNs[wi.Namespace]
+		if !ok {
+			pl, err := s.podLister(ctx, wi.Namespace)
+			if err == nil {
+				pods, err = pl.Pods(wi.Namespace).List(labels.Everything())
+			}
+			if err != nil {
+				dlog.Errorf(ctx, "unable to list the pods in namespace %s: %v", wi.Namespace, err)
+				if errs == nil {
+					errs = make(map[string]string)
+				}
+				errs[wi.Namespace] = err.Error()
+			}
+			podsByNs[wi.Namespace] = pods
+		}
+		kind := rpcKind(wi.WorkloadResourceType)
+		var pis []*rpc.PodInfo
+		for _, pod := range pods {
+			if podBelongsTo(pod, kind, wi.Name) {
+				pis = append(pis, podInfo(pod))
+			}
+		}
+		slices.SortFunc(pis, func(a, b *rpc.PodInfo) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		wi.Pods = pis
+	}
+	return errs
+}
+
+// podBelongsTo returns true if the given pod is controlled by the workload with the given kind and name.
+// The pods of a Deployment or a Rollout are controlled by a replica set whose name is the name of the
+// workload followed by the pod-template-hash.
+func podBelongsTo(pod *core.Pod, kind manager.WorkloadInfo_Kind, name string) bool {
+	owner := meta.GetControllerOf(pod)
+	if owner == nil {
+		return false
+	}
+	switch kind {
+	case manager.WorkloadInfo_DEPLOYMENT:
+		hash, ok := pod.Labels[apps.DefaultDeploymentUniqueLabelKey]
+		return ok && owner.Kind == "ReplicaSet" && owner.Name == name+"-"+hash
+	case manager.WorkloadInfo_ROLLOUT:
+		hash, ok := pod.Labels[rolloutPodTemplateHashKey]
+		return ok && owner.Kind == "ReplicaSet" && owner.Name == name+"-"+hash
+	case manager.WorkloadInfo_REPLICASET:
+		return owner.Kind == "ReplicaSet" && owner.Name == name
+	case manager.WorkloadInfo_STATEFULSET:
+		return owner.Kind == "StatefulSet" && owner.Name == name
+	default:
+		return false
+	}
+}
+
+func podInfo(pod *core.Pod) *rpc.PodInfo {
+	pi := &rpc.PodInfo{
+		Name:  pod.Name,
+		Phase: string(pod.Status.Phase),
+		Node:  pod.Spec.NodeName,
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == core.PodReady {
+			pi.Ready = c.Status == core.ConditionTrue
+			break
+		}
+	}
+	return pi
+}
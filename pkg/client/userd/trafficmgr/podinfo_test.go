@@ -0,0 +1,147 @@
+package trafficmgr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func testPod(name, ownerKind, ownerName, hash string, ready bool) *core.Pod {
+	isController := true
+	readyStatus := core.ConditionFalse
+	if ready {
+		readyStatus = core.ConditionTrue
+	}
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            name,
+			Namespace:       "a",
+			OwnerReferences: []meta.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &isController}},
+		},
+		Spec: core.PodSpec{NodeName: "node-1"},
+		Status: core.PodStatus{
+			Phase:      core.PodRunning,
+			Conditions: []core.PodCondition{{Type: core.PodReady, Status: readyStatus}},
+		},
+	}
+	if hash != "" {
+		pod.Labels = map[string]string{"pod-template-hash": hash}
+	}
+	return pod
+}
+
+func Test_podBelongsTo(t *testing.T) {
+	pod := testPod("echo-5d8f7-x2m4q", "ReplicaSet", "echo-5d8f7", "5d8f7", true)
+	assert.True(t, podBelongsTo(pod, manager.WorkloadInfo_DEPLOYMENT, "echo"))
+	assert.True(t, podBelongsTo(pod, manager.WorkloadInfo_REPLICASET, "echo-5d8f7"))
+	assert.False(t, podBelongsTo(pod, manager.WorkloadInfo_DEPLOYMENT, "echo-5d8f7"))
+	assert.False(t, podBelongsTo(pod, manager.WorkloadInfo_REPLICASET, "echo"))
+	assert.False(t, podBelongsTo(pod, manager.WorkloadInfo_ROLLOUT, "echo"))
+	assert.False(t, podBelongsTo(pod, manager.WorkloadInfo_STATEFULSET, "echo"))
+
+	// A replica set that happens to have the name of a deployment followed by a dash doesn't belong to it.
+	pod = testPod("echo-extra-abcde", "ReplicaSet", "echo-extra", "", true)
+	assert.False(t, podBelongsTo(pod, manager.WorkloadInfo_DEPLOYMENT, "echo"))
+
+	pod = testPod("db-0", "StatefulSet", "db", "", true)
+	assert.True(t, podBelongsTo(pod, manager.WorkloadInfo_STATEFULSET, "db"))
+	assert.False(t, podBelongsTo(pod, manager.WorkloadInfo_REPLICASET, "db"))
+}
+
+func Test_session_WorkloadInfoSnapshot_pods(t *testing.T) {
+	cs := fake.NewClientset(
+		testPod("echo-5d8f7-zz9kq", "ReplicaSet", "echo-5d8f7", "5d8f7", false),
+		testPod("echo-5d8f7-x2m4q", "ReplicaSet", "echo-5d8f7", "5d8f7", true),
+		testPod("db-0", "StatefulSet", "db", "", true),
+		testPod("orphan", "", "", "", true),
+	)
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "a"}}
+	available := workloadInfo{state: workload.StateAvailable}
+	s.workloads = map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}: available,
+		{kind: manager.WorkloadInfo_STATEFULSET, namespace: "a", name: "db"}:  available,
+	}
+	s.syncedNamespaces = map[string]struct{}{"a": {}}
+
+	// No pods unless they are asked for.
+	ws, err := s.WorkloadInfoSnapshot(ctx, []string{"a"}, rpc.ListRequest_EVERYTHING, nil, 0, false)
+	require.NoError(t, err)
+	require.Len(t, ws.Workloads, 2)
+	for _, wi := range ws.Workloads {
+		assert.Nil(t, wi.Pods)
+	}
+	require.Contains(t, s.namespaceInformers, "a")
+	assert.NotContains(t, s.namespaceInformers["a"].synced, "pods")
+
+	ws, err = s.WorkloadInfoSnapshot(ctx, []string{"a"}, rpc.ListRequest_EVERYTHING, nil, 0, true)
+	require.NoError(t, err)
+	require.Len(t, ws.Workloads, 2)
+	assert.Empty(t, ws.PodErrors)
+	pods := make(map[string][]*rpc.PodInfo, len(ws.Workloads))
+	for _, wi := range ws.Workloads {
+		pods[wi.Name] = wi.Pods
+	}
+	expected := map[string][]*rpc.PodInfo{
+		"db": {{Name: "db-0", Phase: "Running", Ready: true, Node: "node-1"}},
+		"echo": {
+			{Name: "echo-5d8f7-x2m4q", Phase: "Running", Ready: true, Node: "node-1"},
+			{Name: "echo-5d8f7-zz9kq", Phase: "Running", Node: "node-1"},
+		},
+	}
+	require.Len(t, pods, len(expected))
+	for name, epis := range expected {
+		require.Len(t, pods[name], len(epis), name)
+		for i, epi := range epis {
+			assert.True(t, proto.Equal(epi, pods[name][i]), "%s: expected %v, got %v", name, epi, pods[name][i])
+		}
+	}
+
+	// The pod informer is stopped when the namespace is pruned.
+	s.pruneNamespaceInformers(nil)
+	assert.Empty(t, s.namespaceInformers)
+}
+
+func Test_session_WorkloadInfoSnapshot_podsForbidden(t *testing.T) {
+	cs := fake.NewClientset(testPod("db-0", "StatefulSet", "db", "", true))
+	cs.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(core.Resource("pods"), "", fmt.Errorf("access denied"))
+	})
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "a"}}
+	s.workloads = map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_STATEFULSET, namespace: "a", name: "db"}: {state: workload.StateAvailable},
+	}
+	s.syncedNamespaces = map[string]struct{}{"a": {}}
+	t.Cleanup(func() { s.pruneNamespaceInformers(nil) })
+
+	// The listing doesn't wait for the informer to sync, and reports why the pods are missing.
+	start := time.Now()
+	ws, err := s.WorkloadInfoSnapshot(ctx, []string{"a"}, rpc.ListRequest_EVERYTHING, nil, 0, true)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), informerSyncTimeout)
+	require.Len(t, ws.Workloads, 1)
+	assert.Nil(t, ws.Workloads[0].Pods)
+	require.Contains(t, ws.PodErrors, "a")
+	assert.Contains(t, ws.PodErrors["a"], "unable to list pods")
+}
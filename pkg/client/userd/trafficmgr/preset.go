@@ -0,0 +1,80 @@
+package trafficmgr
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// applyInterceptPreset uses the values of the intercept preset that the given request refers to for the
+// options that the request doesn't set. The intercept can proceed only if the returned rpc.InterceptResult
+// is nil.
+func applyInterceptPreset(ctx context.Context, ir *rpc.CreateInterceptRequest) *rpc.InterceptResult {
+	if ir.Preset == "" {
+		return nil
+	}
+	ic := client.GetConfig(ctx).Intercept()
+	p, ok := ic.Presets[ir.Preset]
+	if !ok || p == nil {
+		return InterceptError(common.InterceptError_UNKNOWN_PRESET, errcat.User.New(ir.Preset))
+	}
+	spec := ir.Spec
+	if spec.Mechanism == "" {
+		spec.Mechanism = p.Mechanism
+	}
+	if len(spec.MechanismArgs) == 0 {
+		spec.MechanismArgs = slices.Clone(p.MechanismArgs)
+	}
+	if spec.PortIdentifier == "" && p.Port != "" {
+		if err := agentconfig.PortIdentifier(p.Port).Validate(); err != nil {
+			return InterceptError(common.InterceptError_INVALID_VALUE, errcat.Config.Newf("intercept preset %s: port %s: %v", ir.Preset, p.Port, err))
+		}
+		spec.PortIdentifier = p.Port
+	}
+	if spec.TargetPort == 0 {
+		// The CLI leaves the defaultPort to the user daemon when a preset is used.
+		spec.TargetPort = int32(p.TargetPort)
+		if spec.TargetPort == 0 {
+			spec.TargetPort = int32(ic.DefaultPort)
+		}
+	}
+	if len(spec.LocalPorts) == 0 {
+		for _, lp := range p.LocalPorts {
+			pp, err := agentconfig.NewPortAndProto(lp)
+			if err != nil {
+				return InterceptError(common.InterceptError_INVALID_VALUE, errcat.Config.Newf("intercept preset %s: %v", ir.Preset, err))
+			}
+			spec.LocalPorts = append(spec.LocalPorts, pp.String())
+		}
+	}
+	if ir.LocalMountPort == 0 {
+		ir.LocalMountPort = int32(p.LocalMountPort)
+	}
+	if p.MountReadOnly && !ir.MountReadWrite {
+		ir.MountReadOnly = true
+	}
+	return nil
+}
+
+// addPresetEnv adds the environment of the intercept preset that the given request refers to, to the
+// environment of the given intercept.
+func addPresetEnv(ctx context.Context, ir *rpc.CreateInterceptRequest, ii *manager.InterceptInfo) {
+	if ir.Preset == "" {
+		return
+	}
+	p := client.GetConfig(ctx).Intercept().Presets[ir.Preset]
+	if p == nil || len(p.Env) == 0 {
+		return
+	}
+	if ii.Environment == nil {
+		ii.Environment = make(map[string]string, len(p.Env))
+	}
+	maps.Copy(ii.Environment, p.Env)
+}
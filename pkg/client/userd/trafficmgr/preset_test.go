@@ -0,0 +1,124 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+func Test_applyInterceptPreset(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	ic := cfg.Intercept()
+	ic.DefaultPort = 8000
+	ic.Presets = map[string]*client.InterceptPreset{
+		"orders": {
+			Mechanism:      "http",
+			MechanismArgs:  []string{"--http-header=x-user=me"},
+			Port:           "http",
+			TargetPort:     8081,
+			LocalPorts:     []string{"9090", "53/udp"},
+			Env:            map[string]string{"LOG_LEVEL": "debug"},
+			MountReadOnly:  true,
+			LocalMountPort: 2222,
+		},
+		"headers":      {MechanismArgs: []string{"--http-header=x-user=you"}},
+		"invalid":      {LocalPorts: []string{"not-a-port"}},
+		"invalid-port": {Port: "http/SCTP"},
+	}
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+
+	t.Run("no preset", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "echo"}}
+		require.Nil(t, applyInterceptPreset(ctx, ir))
+		assert.Zero(t, ir.Spec.TargetPort)
+		assert.Empty(t, ir.Spec.MechanismArgs)
+	})
+
+	t.Run("preset values", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "echo"}, Preset: "orders"}
+		require.Nil(t, applyInterceptPreset(ctx, ir))
+		spec := ir.Spec
+		assert.Equal(t, "http", spec.Mechanism)
+		assert.Equal(t, []string{"--http-header=x-user=me"}, spec.MechanismArgs)
+		assert.Equal(t, "http", spec.PortIdentifier)
+		assert.Equal(t, int32(8081), spec.TargetPort)
+		assert.Equal(t, []string{"9090", "53/UDP"}, spec.LocalPorts)
+		assert.True(t, ir.MountReadOnly)
+		assert.Equal(t, int32(2222), ir.LocalMountPort)
+
+		ii := &manager.InterceptInfo{Environment: map[string]string{"LOG_LEVEL": "info", "HOME": "/app"}}
+		addPresetEnv(ctx, ir, ii)
+		assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "HOME": "/app"}, ii.Environment)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{
+			Spec: &manager.InterceptSpec{
+				Name:           "echo",
+				Mechanism:      "tcp",
+				MechanismArgs:  []string{"--http-header=x-user=them"},
+				PortIdentifier: "grpc",
+				TargetPort:     9000,
+				LocalPorts:     []string{"8443/TCP"},
+			},
+			LocalMountPort: 3333,
+			MountReadWrite: true,
+			Preset:         "orders",
+		}
+		require.Nil(t, applyInterceptPreset(ctx, ir))
+		spec := ir.Spec
+		assert.Equal(t, "tcp", spec.Mechanism)
+		assert.Equal(t, []string{"--http-header=x-user=them"}, spec.MechanismArgs)
+		assert.Equal(t, "grpc", spec.PortIdentifier)
+		assert.Equal(t, int32(9000), spec.TargetPort)
+		assert.Equal(t, []string{"8443/TCP"}, spec.LocalPorts)
+		assert.Equal(t, int32(3333), ir.LocalMountPort)
+		assert.False(t, ir.MountReadOnly)
+	})
+
+	t.Run("default port", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "echo"}, Preset: "headers"}
+		require.Nil(t, applyInterceptPreset(ctx, ir))
+		assert.Equal(t, []string{"--http-header=x-user=you"}, ir.Spec.MechanismArgs)
+		assert.Equal(t, int32(8000), ir.Spec.TargetPort)
+		assert.False(t, ir.MountReadOnly)
+
+		ii := &manager.InterceptInfo{}
+		addPresetEnv(ctx, ir, ii)
+		assert.Nil(t, ii.Environment)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "echo"}, Preset: "nope"}
+		r := applyInterceptPreset(ctx, ir)
+		require.NotNil(t, r)
+		assert.Equal(t, common.InterceptError_UNKNOWN_PRESET, r.Error)
+		assert.Equal(t, "nope", r.ErrorText)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "echo"}, Preset: "invalid"}
+		r := applyInterceptPreset(ctx, ir)
+		require.NotNil(t, r)
+		assert.Equal(t, common.InterceptError_INVALID_VALUE, r.Error)
+		assert.Equal(t, int32(errcat.Config), r.ErrorCategory)
+		assert.Contains(t, r.ErrorText, "intercept preset invalid")
+	})
+
+	t.Run("invalid port", func(t *testing.T) {
+		ir := &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{Name: "echo"}, Preset: "invalid-port"}
+		r := applyInterceptPreset(ctx, ir)
+		require.NotNil(t, r)
+		assert.Equal(t, common.InterceptError_INVALID_VALUE, r.Error)
+		assert.Equal(t, int32(errcat.Config), r.ErrorCategory)
+		assert.Contains(t, r.ErrorText, "intercept preset invalid-port")
+	})
+}
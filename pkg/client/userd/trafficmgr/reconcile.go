@@ -0,0 +1,84 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// reconcileInterceptsLoop periodically compares the currentIntercepts with the intercepts that the traffic-manager
+// has for this session, so that a divergence caused by a lost watcher event heals itself.
+func (s *session) reconcileInterceptsLoop(ctx context.Context) error {
+	interval := client.GetConfig(ctx).Intercept().ReconcileInterval
+	if interval <= 0 {
+		return nil
+	}
+	ticker := client.GetClock(ctx).NewTicker(interval)
+	defer ticker.Stop()
+	suspect := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+		}
+		var err error
+		if suspect, err = s.reconcileIntercepts(ctx, suspect); err != nil {
+			dlog.Warnf(ctx, "unable to reconcile intercepts with the traffic-manager: %v", err)
+		}
+	}
+}
+
+// reconcileIntercepts lists the intercepts of this session from the traffic-manager and compares them with the
+// currentIntercepts. A divergence is returned in the form of a description. The intercept watcher is told to resync
+// only when the same divergence was found by the previous call, given as suspect, because a divergence that is seen
+// just once might be an event that is still in flight.
+func (s *session) reconcileIntercepts(ctx context.Context, suspect string) (string, error) {
+	iis, err := s.interceptSnapshot(ctx, s.SessionInfo())
+	if err != nil {
+		return "", err
+	}
+	missing, orphans := s.interceptDiscrepancies(iis)
+	if len(missing) == 0 && len(orphans) == 0 {
+		return "", nil
+	}
+	divergence := fmt.Sprintf("missing [%s], orphaned [%s]", strings.Join(missing, ","), strings.Join(orphans, ","))
+	if divergence != suspect {
+		dlog.Debugf(ctx, "intercepts might diverge from the traffic-manager: %s", divergence)
+		return divergence, nil
+	}
+	dlog.Warnf(ctx, "intercepts diverge from the traffic-manager: %s. Reconciling", divergence)
+	select {
+	case s.interceptResync <- struct{}{}:
+	default:
+		// A resync is already pending.
+	}
+	return "", nil
+}
+
+// interceptDiscrepancies returns the sorted names of the given intercepts that are missing in the currentIntercepts,
+// and of the currentIntercepts that are missing in the given intercepts.
+func (s *session) interceptDiscrepancies(iis []*manager.InterceptInfo) (missing, orphans []string) {
+	s.currentInterceptsLock.Lock()
+	defer s.currentInterceptsLock.Unlock()
+	ids := make(map[string]struct{}, len(iis))
+	for _, ii := range iis {
+		ids[ii.Id] = struct{}{}
+		if _, ok := s.currentIntercepts[ii.Id]; !ok {
+			missing = append(missing, ii.Spec.Name)
+		}
+	}
+	for id, ic := range s.currentIntercepts {
+		if _, ok := ids[id]; !ok {
+			orphans = append(orphans, ic.Spec.Name)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(orphans)
+	return missing, orphans
+}
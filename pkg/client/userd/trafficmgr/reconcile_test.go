@@ -0,0 +1,128 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// fakeInterceptManager is a traffic-manager that streams one snapshot of intercepts to each watcher.
+type fakeInterceptManager struct {
+	manager.ManagerClient
+	intercepts []*manager.InterceptInfo
+}
+
+type fakeInterceptStream struct {
+	manager.Manager_WatchInterceptsClient
+	ctx      context.Context
+	snapshot *manager.InterceptInfoSnapshot
+}
+
+func (m *fakeInterceptManager) WatchIntercepts(ctx context.Context, _ *manager.SessionInfo, _ ...grpc.CallOption) (manager.Manager_WatchInterceptsClient, error) {
+	return &fakeInterceptStream{ctx: ctx, snapshot: &manager.InterceptInfoSnapshot{Intercepts: m.intercepts}}, nil
+}
+
+func (s *fakeInterceptStream) Recv() (*manager.InterceptInfoSnapshot, error) {
+	if snapshot := s.snapshot; snapshot != nil {
+		s.snapshot = nil
+		return snapshot, nil
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func waitingIntercept(id string) *manager.InterceptInfo {
+	return &manager.InterceptInfo{
+		Id:          id,
+		Disposition: manager.InterceptDispositionType_WAITING,
+		Spec:        &manager.InterceptSpec{Name: id, Agent: "echo", Namespace: "default"},
+	}
+}
+
+func Test_session_reconcileIntercepts(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{
+		sessionInfo:     &manager.SessionInfo{SessionId: "session"},
+		managerClient:   &fakeInterceptManager{intercepts: []*manager.InterceptInfo{waitingIntercept("a"), waitingIntercept("b")}},
+		interceptResync: make(chan struct{}, 1),
+	}
+
+	// Inject a divergence: "b" was never received and "c" was never removed.
+	s.setCurrentIntercepts(ctx, []*manager.InterceptInfo{waitingIntercept("a"), waitingIntercept("c")})
+	orphan := s.currentIntercepts["c"]
+
+	// The first time a divergence is seen, it's just a suspect.
+	suspect, err := s.reconcileIntercepts(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "missing [b], orphaned [c]", suspect)
+	assert.Empty(t, s.interceptResync)
+
+	// The second time, the intercept watcher is told to resync.
+	suspect, err = s.reconcileIntercepts(ctx, suspect)
+	require.NoError(t, err)
+	assert.Empty(t, suspect)
+	require.Len(t, s.interceptResync, 1)
+	<-s.interceptResync
+	s.resyncIntercepts(ctx, newPodAccessTracker())
+
+	assert.Len(t, s.currentIntercepts, 2)
+	assert.Contains(t, s.currentIntercepts, "a")
+	assert.Contains(t, s.currentIntercepts, "b")
+	assert.Error(t, orphan.ctx.Err(), "orphaned intercept should be cancelled")
+
+	// Nothing diverges once healed.
+	suspect, err = s.reconcileIntercepts(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, suspect)
+	assert.Empty(t, s.interceptResync)
+}
+
+func Test_session_reconcileIntercepts_transient(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{
+		sessionInfo:     &manager.SessionInfo{SessionId: "session"},
+		managerClient:   &fakeInterceptManager{intercepts: []*manager.InterceptInfo{waitingIntercept("a")}},
+		interceptResync: make(chan struct{}, 1),
+	}
+
+	suspect, err := s.reconcileIntercepts(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "missing [a], orphaned []", suspect)
+
+	// The watcher delivers the intercept before the next reconciliation, so there's nothing to heal.
+	s.setCurrentIntercepts(ctx, []*manager.InterceptInfo{waitingIntercept("a")})
+	suspect, err = s.reconcileIntercepts(ctx, suspect)
+	require.NoError(t, err)
+	assert.Empty(t, suspect)
+	assert.Empty(t, s.interceptResync)
+}
+
+func Test_session_resyncIntercepts_fresh(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	mgr := &fakeInterceptManager{intercepts: []*manager.InterceptInfo{waitingIntercept("a")}}
+	s := &session{
+		sessionInfo:     &manager.SessionInfo{SessionId: "session"},
+		managerClient:   mgr,
+		interceptResync: make(chan struct{}, 1),
+	}
+
+	suspect, err := s.reconcileIntercepts(ctx, "")
+	require.NoError(t, err)
+	_, err = s.reconcileIntercepts(ctx, suspect)
+	require.NoError(t, err)
+	require.Len(t, s.interceptResync, 1)
+
+	// The watcher receives a newer snapshot before it handles the resync. The resync must not revert it.
+	mgr.intercepts = []*manager.InterceptInfo{waitingIntercept("a"), waitingIntercept("b")}
+	s.handleInterceptSnapshot(ctx, newPodAccessTracker(), mgr.intercepts)
+	<-s.interceptResync
+	s.resyncIntercepts(ctx, newPodAccessTracker())
+	assert.Len(t, s.currentIntercepts, 2)
+	assert.Contains(t, s.currentIntercepts, "b")
+}
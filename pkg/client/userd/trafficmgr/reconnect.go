@@ -0,0 +1,79 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+)
+
+// managerUnavailable returns true if the given error means that the connection to the traffic-manager was lost.
+func managerUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// managerClientSetter is implemented by a root daemon that runs in-process, because it shares the manager
+// client of the session.
+type managerClientSetter interface {
+	SetManagerClient(manager.ManagerClient)
+}
+
+// reconnectManager replaces the connection to the traffic-manager with a new one, unless the given stale
+// client has already been replaced by another caller. The session, and with it the current intercepts and
+// ingests, is retained, so the caller only needs to subscribe again. ErrSessionExpired is returned when the
+// traffic-manager no longer knows the session, because then the whole session must be recreated.
+func (s *session) reconnectManager(ctx context.Context, stale manager.ManagerClient) error {
+	s.managerReconnectLock.Lock()
+	defer s.managerReconnectLock.Unlock()
+	if s.ManagerClient() != stale {
+		// Someone else reconnected while we were waiting for the lock.
+		return nil
+	}
+	if s.dialManager == nil {
+		return errors.New("unable to reconnect to the traffic-manager")
+	}
+
+	dlog.Info(ctx, "Connection to the traffic-manager was lost, reconnecting")
+	conn, mClient, err := s.dialManager(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to reconnect to the traffic-manager: %w", err)
+	}
+	if _, err = mClient.Remain(ctx, &manager.RemainRequest{Session: s.SessionInfo()}); err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		if status.Code(err) == codes.NotFound {
+			return ErrSessionExpired
+		}
+		return fmt.Errorf("unable to reconnect to the traffic-manager: %w", err)
+	}
+
+	s.managerLock.Lock()
+	oldConn := s.managerConn
+	s.managerClient = mClient
+	s.managerConn = conn
+	s.managerLock.Unlock()
+	if oldConn != nil {
+		oldConn.Close()
+	}
+	userd.GetService(ctx).SetManagerClient(mClient, managerCallOptions(ctx)...)
+	if ms, ok := s.rootDaemon.(managerClientSetter); ok {
+		ms.SetManagerClient(mClient)
+	}
+
+	// The traffic-manager might have been reinstalled in a recreated namespace.
+	s.InvalidateManagerInstallId()
+
+	// It might also have been upgraded.
+	if err = s.refreshManagerVersion(ctx, mClient); err != nil {
+		dlog.Warnf(ctx, "unable to refresh the traffic-manager version: %v", err)
+	}
+	dlog.Info(ctx, "Reconnected to the traffic-manager")
+	return nil
+}
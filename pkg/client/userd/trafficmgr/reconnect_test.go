@@ -0,0 +1,145 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+)
+
+// fakeDroppingManager is a traffic-manager that streams one snapshot of intercepts to each watcher, and then
+// ends the stream with recvErr, or keeps it open when recvErr is nil.
+type fakeDroppingManager struct {
+	manager.ManagerClient
+	intercepts []*manager.InterceptInfo
+	recvErr    error
+	remainErr  error
+	version    *manager.VersionInfo2
+}
+
+type fakeDroppingStream struct {
+	manager.Manager_WatchInterceptsClient
+	ctx      context.Context
+	snapshot *manager.InterceptInfoSnapshot
+	err      error
+}
+
+func (m *fakeDroppingManager) WatchIntercepts(ctx context.Context, _ *manager.SessionInfo, _ ...grpc.CallOption) (manager.Manager_WatchInterceptsClient, error) {
+	return &fakeDroppingStream{ctx: ctx, snapshot: &manager.InterceptInfoSnapshot{Intercepts: m.intercepts}, err: m.recvErr}, nil
+}
+
+func (m *fakeDroppingManager) Remain(context.Context, *manager.RemainRequest, ...grpc.CallOption) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, m.remainErr
+}
+
+func (m *fakeDroppingManager) Version(context.Context, *emptypb.Empty, ...grpc.CallOption) (*manager.VersionInfo2, error) {
+	if m.version == nil {
+		return nil, status.Error(codes.Unimplemented, "no version")
+	}
+	return m.version, nil
+}
+
+func (s *fakeDroppingStream) Recv() (*manager.InterceptInfoSnapshot, error) {
+	if snapshot := s.snapshot; snapshot != nil {
+		s.snapshot = nil
+		return snapshot, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+// fakeService is a user daemon service that records the manager client that it's given.
+type fakeService struct {
+	userd.Service
+	managerClient manager.ManagerClient
+}
+
+func (s *fakeService) SetManagerClient(mc manager.ManagerClient, _ ...grpc.CallOption) {
+	s.managerClient = mc
+}
+
+// fakeInProcRootDaemon is a root daemon that records the manager client that it's given, like one that
+// runs in-process.
+type fakeInProcRootDaemon struct {
+	fakeRootDaemon
+	managerClient manager.ManagerClient
+}
+
+func (rd *fakeInProcRootDaemon) SetManagerClient(mc manager.ManagerClient) {
+	rd.managerClient = mc
+}
+
+func Test_session_reconnectManager(t *testing.T) {
+	svc := &fakeService{}
+	ctx := userd.WithService(client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig()), svc)
+
+	dropped := &fakeDroppingManager{
+		intercepts: []*manager.InterceptInfo{waitingIntercept("a")},
+		recvErr:    status.Error(codes.Unavailable, "connection lost"),
+	}
+	restored := &fakeDroppingManager{
+		intercepts: []*manager.InterceptInfo{waitingIntercept("a")},
+		version:    &manager.VersionInfo2{Name: "Telepresence Pro", Version: "v2.22.0"},
+	}
+	dials := 0
+	rd := &fakeInProcRootDaemon{}
+	s := &session{
+		rootDaemon:     rd,
+		Cluster:        &k8s.Cluster{},
+		sessionInfo:    &manager.SessionInfo{SessionId: "session"},
+		managerClient:  dropped,
+		managerName:    "Traffic Manager",
+		managerVersion: semver.MustParse("2.21.0"),
+		dialManager: func(context.Context) (*grpc.ClientConn, manager.ManagerClient, error) {
+			dials++
+			return nil, restored, nil
+		},
+	}
+
+	// The watcher receives the intercept, loses the connection, and reconnects without dropping the intercept.
+	pat := newPodAccessTracker()
+	require.NoError(t, s.watchInterceptsLoop(ctx, pat))
+	assert.Equal(t, 1, dials)
+	assert.Equal(t, restored, s.ManagerClient())
+	assert.Equal(t, restored, svc.managerClient)
+	assert.Equal(t, restored, rd.managerClient)
+	require.Contains(t, s.currentIntercepts, "a")
+	ic := s.currentIntercepts["a"]
+	assert.NoError(t, ic.ctx.Err())
+
+	// The traffic-manager was upgraded while the connection was lost.
+	assert.Equal(t, "Telepresence Pro", s.ManagerName())
+	assert.Equal(t, semver.MustParse("2.22.0"), s.ManagerVersion())
+
+	// A watcher that lost the same connection doesn't reconnect again.
+	require.NoError(t, s.reconnectManager(ctx, dropped))
+	assert.Equal(t, 1, dials)
+
+	// The watcher subscribes again using the restored connection.
+	wCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- s.watchInterceptsLoop(wCtx, pat) }()
+	cancel()
+	require.NoError(t, <-done)
+
+	// A session that the traffic-manager no longer knows cannot be retained.
+	restored.remainErr = status.Error(codes.NotFound, "no such session")
+	s.managerClient = dropped
+	assert.ErrorIs(t, s.reconnectManager(ctx, dropped), ErrSessionExpired)
+	assert.Equal(t, dropped, s.ManagerClient())
+}
@@ -0,0 +1,92 @@
+package trafficmgr
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// redactableField removes a field from a user of a kubeconfig, along with the kubectl flags that set it.
+type redactableField struct {
+	redact func(*api.AuthInfo)
+	flags  []string
+}
+
+// redactableFields are the fields that can be listed in the cluster.rootDaemonRedact setting.
+var redactableFields = map[string]redactableField{ //nolint:gochecknoglobals // constant
+	"exec": {
+		redact: func(ai *api.AuthInfo) { ai.Exec = nil },
+	},
+	"authProvider": {
+		redact: func(ai *api.AuthInfo) { ai.AuthProvider = nil },
+	},
+	"token": {
+		redact: func(ai *api.AuthInfo) { ai.Token, ai.TokenFile = "", "" },
+		flags:  []string{"token"},
+	},
+	"clientCertificate": {
+		redact: func(ai *api.AuthInfo) { ai.ClientCertificate, ai.ClientCertificateData = "", nil },
+		flags:  []string{"client-certificate"},
+	},
+	"clientKey": {
+		redact: func(ai *api.AuthInfo) { ai.ClientKey, ai.ClientKeyData = "", nil },
+		flags:  []string{"client-key"},
+	},
+	"basicAuth": {
+		redact: func(ai *api.AuthInfo) { ai.Username, ai.Password = "", "" },
+		flags:  []string{"username", "password"},
+	},
+	"impersonate": {
+		redact: func(ai *api.AuthInfo) {
+			ai.Impersonate, ai.ImpersonateUID, ai.ImpersonateGroups, ai.ImpersonateUserExtra = "", "", nil, nil
+		},
+		flags: []string{"as", "as-uid", "as-group"},
+	},
+}
+
+// redactKubeconfig returns the kubectl flags and kubeconfig data that are passed to the root daemon, with the
+// given fields removed from all users of the kubeconfig. The kubeconfig is passed as data, so the kubeconfig
+// flag is removed. The given flags and data are returned as is when no fields are given.
+func redactKubeconfig(ctx context.Context, kubeFlags map[string]string, kubeData []byte, fields []string) (map[string]string, []byte, error) {
+	if len(fields) == 0 {
+		return kubeFlags, kubeData, nil
+	}
+	for _, f := range fields {
+		if _, ok := redactableFields[f]; !ok {
+			names := slices.Sorted(maps.Keys(redactableFields))
+			return nil, nil, errcat.Config.Newf("cluster.rootDaemonRedact: unknown kubeconfig field %q, must be one of %s", f, strings.Join(names, ", "))
+		}
+	}
+	cl, err := client.ConfigLoader(ctx, kubeFlags, kubeData)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := cl.RawConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	flags := maps.Clone(kubeFlags)
+	delete(flags, "kubeconfig")
+	for _, f := range fields {
+		rf := redactableFields[f]
+		for _, ai := range config.AuthInfos {
+			rf.redact(ai)
+		}
+		for _, flag := range rf.flags {
+			delete(flags, flag)
+		}
+	}
+	if kubeData, err = clientcmd.Write(config); err != nil {
+		return nil, nil, err
+	}
+	dlog.Debugf(ctx, "Removed %s from the kubeconfig that is passed to the root daemon", strings.Join(fields, ", "))
+	return flags, kubeData, nil
+}
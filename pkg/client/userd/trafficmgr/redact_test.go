@@ -0,0 +1,104 @@
+package trafficmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+)
+
+const redactKubeconfigYAML = `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: ctx
+  context:
+    cluster: cluster
+    user: user
+current-context: ctx
+users:
+- name: user
+  user:
+    token: secret-token
+    client-key-data: c2VjcmV0LWtleQ==
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: get-credentials
+      interactiveMode: Never
+`
+
+func writeKubeconfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(redactKubeconfigYAML), 0o600))
+	return path
+}
+
+func Test_redactKubeconfig(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	kubeFlags := map[string]string{"kubeconfig": writeKubeconfig(t), "namespace": "b", "token": "flag-token"}
+
+	// Nothing is changed when no fields are redacted.
+	flags, data, err := redactKubeconfig(ctx, kubeFlags, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, kubeFlags, flags)
+	assert.Nil(t, data)
+
+	flags, data, err = redactKubeconfig(ctx, kubeFlags, nil, []string{"exec", "token"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"namespace": "b"}, flags)
+	assert.NotContains(t, string(data), "get-credentials")
+	assert.NotContains(t, string(data), "secret-token")
+
+	// The fields that aren't redacted are retained.
+	cfg, err := clientcmd.Load(data)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx", cfg.CurrentContext)
+	assert.Equal(t, "https://127.0.0.1:6443", cfg.Clusters["cluster"].Server)
+	assert.Equal(t, []byte("secret-key"), cfg.AuthInfos["user"].ClientKeyData)
+
+	// Kubeconfig data is redacted too.
+	flags, data, err = redactKubeconfig(ctx, map[string]string{}, []byte(redactKubeconfigYAML), []string{"clientKey"})
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+	cfg, err = clientcmd.Load(data)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.AuthInfos["user"].ClientKeyData)
+	assert.Equal(t, "secret-token", cfg.AuthInfos["user"].Token)
+
+	_, _, err = redactKubeconfig(ctx, kubeFlags, nil, []string{"exec", "password"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown kubeconfig field "password"`)
+}
+
+func Test_session_getNetworkInfo_redact(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	cc := client.GetConfig(ctx).Cluster()
+	cc.RootDaemonRedact = []string{"exec"}
+	s := &session{Cluster: &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "b"}}}
+	cr := &rpc.ConnectRequest{KubeFlags: map[string]string{"kubeconfig": writeKubeconfig(t)}}
+
+	// The kubeconfig is passed as is when the root daemon uses it to connect to the cluster.
+	nc, err := s.getNetworkInfo(ctx, cr)
+	require.NoError(t, err)
+	assert.Equal(t, cr.KubeFlags, nc.KubeFlags)
+	assert.Empty(t, nc.KubeconfigData)
+
+	cc.ConnectFromRootDaemon = false
+	nc, err = s.getNetworkInfo(ctx, cr)
+	require.NoError(t, err)
+	assert.Empty(t, nc.KubeFlags)
+	assert.NotEmpty(t, nc.KubeconfigData)
+	assert.NotContains(t, string(nc.KubeconfigData), "get-credentials")
+	assert.Contains(t, string(nc.KubeconfigData), "secret-token")
+}
@@ -0,0 +1,55 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+// restartableKind returns true if a change to the pod template of a workload of the given kind triggers a rolling
+// restart. That's not the case for a ReplicaSet, which leaves its existing pods as they are.
+func restartableKind(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "Rollout":
+		return true
+	default:
+		return false
+	}
+}
+
+// RestartWorkload triggers a rolling restart of the given workload by setting the AnnRestartedAt annotation of
+// its pod template to the current time. The restart re-injects or refreshes the traffic-agent of the workload
+// without an uninstall of the agent. The connected namespace is used when the namespace is empty. The kind is
+// optional, and the workload is searched for like k8sapi.GetWorkload does when it is empty. A user error is
+// returned when the namespace isn't mapped, or when the workload kind doesn't support a rolling restart.
+func (s *session) RestartWorkload(ctx context.Context, namespace, kind, name string) error {
+	if namespace == "" {
+		namespace = s.Namespace
+	}
+	if s.ActualNamespace(namespace) == "" {
+		return errcat.User.Newf("unable to restart %s.%s: namespace %s is not mapped", name, namespace, namespace)
+	}
+	if kind != "" && !restartableKind(kind) {
+		return errcat.User.Newf("unable to restart %s %s.%s: the workload kind doesn't support a rolling restart", kind, name, namespace)
+	}
+	wl, err := k8sapi.GetWorkload(ctx, name, namespace, kind)
+	if err != nil {
+		return err
+	}
+	if kind = wl.GetKind(); !restartableKind(kind) {
+		return errcat.User.Newf("unable to restart %s %s.%s: the workload kind doesn't support a rolling restart", kind, name, namespace)
+	}
+	patch := workload.RestartAnnotationPatch(wl.GetPodTemplate(), client.GetClock(ctx).Now())
+	if err = wl.Patch(ctx, types.JSONPatchType, patch); err != nil {
+		return fmt.Errorf("unable to patch %s %s.%s: %w", kind, name, namespace, err)
+	}
+	dlog.Infof(ctx, "Triggered a rolling restart of %s %s.%s", kind, name, namespace)
+	return nil
+}
@@ -0,0 +1,87 @@
+package trafficmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	auth "k8s.io/api/authorization/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func Test_session_RestartWorkload(t *testing.T) {
+	cs := fake.NewClientset(
+		&appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "default"}},
+		&appsv1.StatefulSet{
+			ObjectMeta: meta.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{Template: core.PodTemplateSpec{ObjectMeta: meta.ObjectMeta{Annotations: map[string]string{
+				"a":                     "A",
+				workload.AnnRestartedAt: "2026-01-01T00:00:00Z",
+			}}}},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: meta.ObjectMeta{Name: "rs", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "other"}},
+	)
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	fc := clocktesting.NewFakeClock(time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC))
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithClock(client.WithConfig(ctx, client.GetDefaultConfig()), fc)
+	s := &session{Cluster: &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "default"}}}
+	s.SetMappedNamespaces(ctx, []string{"default"})
+
+	// Workloads in namespaces that aren't mapped cannot be restarted.
+	err := s.RestartWorkload(ctx, "other", "Deployment", "echo")
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	other, err := cs.AppsV1().Deployments("other").Get(ctx, "echo", meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, other.Spec.Template.Annotations)
+
+	// The annotation is added to a template without annotations.
+	require.NoError(t, s.RestartWorkload(ctx, "default", "Deployment", "echo"))
+	dep, err := cs.AppsV1().Deployments("default").Get(ctx, "echo", meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{workload.AnnRestartedAt: "2026-10-16T12:00:00Z"}, dep.Spec.Template.Annotations)
+
+	// An existing annotation is replaced, the kind is found when it's not given, and the connected namespace
+	// is used when no namespace is given.
+	require.NoError(t, s.RestartWorkload(ctx, "", "", "db"))
+	ss, err := cs.AppsV1().StatefulSets("default").Get(ctx, "db", meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "A", workload.AnnRestartedAt: "2026-10-16T12:00:00Z"}, ss.Spec.Template.Annotations)
+
+	// Kinds that don't support a rolling restart are rejected.
+	for _, kind := range []string{"Job", "CronJob"} {
+		err = s.RestartWorkload(ctx, "default", kind, "echo")
+		require.Error(t, err)
+		assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	}
+	err = s.RestartWorkload(ctx, "default", "", "rs")
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	rs, err := cs.AppsV1().ReplicaSets("default").Get(ctx, "rs", meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, rs.Spec.Template.Annotations)
+
+	// A missing workload is reported.
+	assert.Error(t, s.RestartWorkload(ctx, "default", "Deployment", "missing"))
+}
@@ -0,0 +1,59 @@
+package trafficmgr
+
+import (
+	"context"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	argorollouts "github.com/datawire/argo-rollouts-go-client/pkg/apis/rollouts/v1alpha1"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+// rolloutsAvailable uses the discovery API to find out if the Argo Rollouts CRD is installed in the cluster.
+// The CRD is assumed to be installed when the discovery fails for other reasons than its absence, so that
+// such failures are reported by the Rollouts informer.
+func rolloutsAvailable(ctx context.Context) bool {
+	rl, err := k8sapi.GetK8sInterface(ctx).Discovery().ServerResourcesForGroupVersion(argorollouts.SchemeGroupVersion.String())
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false
+		}
+		dlog.Debugf(ctx, "unable to discover the %s resources: %v", argorollouts.SchemeGroupVersion, err)
+		return true
+	}
+	for _, r := range rl.APIResources {
+		if r.Name == argorollouts.RolloutGVR.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledWorkloadKinds returns the workload kinds that correspond to the given kinds known by the
+// traffic-manager. The RolloutKind is dropped when the Argo Rollouts CRD isn't installed, because the
+// Rollouts informer would then fail repeatedly. A warning is logged the first time that happens.
+func (s *session) enabledWorkloadKinds(ctx context.Context, kinds []manager.WorkloadInfo_Kind) []workload.Kind {
+	wks := make([]workload.Kind, 0, len(kinds))
+	for _, kind := range kinds {
+		switch kind {
+		case manager.WorkloadInfo_DEPLOYMENT:
+			wks = append(wks, workload.DeploymentKind)
+		case manager.WorkloadInfo_REPLICASET:
+			wks = append(wks, workload.ReplicaSetKind)
+		case manager.WorkloadInfo_STATEFULSET:
+			wks = append(wks, workload.StatefulSetKind)
+		case manager.WorkloadInfo_ROLLOUT:
+			if !rolloutsAvailable(ctx) {
+				s.rolloutsMissingWarning.Do(func() {
+					dlog.Warn(ctx, "Argo Rollouts are enabled in the traffic-manager, but the Rollout CRD is not installed in the cluster. Rollouts will not be watched")
+				})
+				continue
+			}
+			wks = append(wks, workload.RolloutKind)
+		}
+	}
+	return wks
+}
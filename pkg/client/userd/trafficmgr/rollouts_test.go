@@ -0,0 +1,47 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func Test_session_enabledWorkloadKinds(t *testing.T) {
+	kinds := []manager.WorkloadInfo_Kind{
+		manager.WorkloadInfo_DEPLOYMENT,
+		manager.WorkloadInfo_REPLICASET,
+		manager.WorkloadInfo_STATEFULSET,
+		manager.WorkloadInfo_ROLLOUT,
+	}
+	cs := fake.NewClientset()
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	s := &session{}
+
+	// The discovery reports no Rollout CRD, so Rollouts are dropped, every time.
+	for range 2 {
+		assert.Equal(t, []workload.Kind{workload.DeploymentKind, workload.ReplicaSetKind, workload.StatefulSetKind}, s.enabledWorkloadKinds(ctx, kinds))
+	}
+
+	// The group exists, but without rollouts.
+	cs.Resources = []*meta.APIResourceList{{
+		GroupVersion: "argoproj.io/v1alpha1",
+		APIResources: []meta.APIResource{{Name: "analysisruns", Kind: "AnalysisRun"}},
+	}}
+	assert.NotContains(t, s.enabledWorkloadKinds(ctx, kinds), workload.RolloutKind)
+
+	cs.Resources[0].APIResources = append(cs.Resources[0].APIResources, meta.APIResource{Name: "rollouts", Kind: "Rollout"})
+	assert.Equal(t, []workload.Kind{
+		workload.DeploymentKind,
+		workload.ReplicaSetKind,
+		workload.StatefulSetKind,
+		workload.RolloutKind,
+	}, s.enabledWorkloadKinds(ctx, kinds))
+}
@@ -0,0 +1,412 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	rootdRpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// fakeRootDaemon is a root daemon that initially runs a stale session.
+type fakeRootDaemon struct {
+	rootdRpc.DaemonClient
+	session     *manager.SessionInfo
+	connects    int
+	disconnects int
+	domains     [][]string
+	suffixes    *rootdRpc.Domains // the include and exclude suffixes of the last SetDNSTopLevelDomains
+
+	connectErr    error // returned by Connect
+	noSession     bool  // Connect reports no session, like a root daemon of another version
+	stickySession bool  // Disconnect doesn't end the session
+	stickyCount   int   // Disconnect doesn't end the session the first stickyCount times it's called
+	networkErr    error // returned by WaitForNetwork, which blocks until its context is done when nil
+	networkUp     bool  // WaitForNetwork returns immediately
+}
+
+func (d *fakeRootDaemon) Connect(_ context.Context, nc *rootdRpc.NetworkConfig, _ ...grpc.CallOption) (*rootdRpc.DaemonStatus, error) {
+	d.connects++
+	if d.connectErr != nil {
+		return nil, d.connectErr
+	}
+	if d.noSession {
+		return &rootdRpc.DaemonStatus{}, nil
+	}
+	if d.session == nil {
+		d.session = nc.Session
+	}
+	return &rootdRpc.DaemonStatus{OutboundConfig: &rootdRpc.NetworkConfig{Session: d.session}}, nil
+}
+
+func (d *fakeRootDaemon) Disconnect(context.Context, *emptypb.Empty, ...grpc.CallOption) (*emptypb.Empty, error) {
+	d.disconnects++
+	if !d.stickySession && d.disconnects > d.stickyCount {
+		d.session = nil
+	}
+	d.domains = nil
+	return &emptypb.Empty{}, nil
+}
+
+func (d *fakeRootDaemon) WaitForNetwork(ctx context.Context, _ *emptypb.Empty, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	if d.networkErr != nil {
+		return nil, d.networkErr
+	}
+	if d.networkUp {
+		return &emptypb.Empty{}, nil
+	}
+	<-ctx.Done()
+	return nil, status.FromContextError(ctx.Err()).Err()
+}
+
+func (d *fakeRootDaemon) Status(context.Context, *emptypb.Empty, ...grpc.CallOption) (*rootdRpc.DaemonStatus, error) {
+	return &rootdRpc.DaemonStatus{}, nil
+}
+
+func (d *fakeRootDaemon) SetDNSTopLevelDomains(_ context.Context, in *rootdRpc.Domains, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	d.domains = append(d.domains, in.Domains)
+	d.suffixes = &rootdRpc.Domains{IncludeSuffixes: in.IncludeSuffixes, ExcludeSuffixes: in.ExcludeSuffixes}
+	return &emptypb.Empty{}, nil
+}
+
+func Test_connectRootSession_reconnect(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	nc := &rootdRpc.NetworkConfig{Session: &manager.SessionInfo{SessionId: "new"}}
+
+	rd := &fakeRootDaemon{session: &manager.SessionInfo{SessionId: "old"}}
+	reconnected, err := connectRootSession(ctx, rd, nc)
+	require.NoError(t, err)
+	assert.True(t, reconnected)
+	assert.Equal(t, 2, rd.connects)
+	assert.Equal(t, 1, rd.disconnects)
+
+	rd = &fakeRootDaemon{}
+	reconnected, err = connectRootSession(ctx, rd, nc)
+	require.NoError(t, err)
+	assert.False(t, reconnected)
+	assert.Equal(t, 1, rd.connects)
+	assert.Zero(t, rd.disconnects)
+}
+
+func Test_connectRootSession_attempts(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	cfg.Cluster().RootSessionAttempts = 4
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx := client.WithClock(client.WithConfig(dlog.NewTestContext(t, false), cfg), fc)
+	nc := &rootdRpc.NetworkConfig{Session: &manager.SessionInfo{SessionId: "new"}}
+
+	connect := func(rd *fakeRootDaemon) (bool, error) {
+		type result struct {
+			reconnected bool
+			err         error
+		}
+		done := make(chan result, 1)
+		go func() {
+			reconnected, err := connectRootSession(ctx, rd, nc)
+			done <- result{reconnected, err}
+		}()
+		for {
+			select {
+			case r := <-done:
+				return r.reconnected, r.err
+			case <-time.After(time.Millisecond):
+				if fc.HasWaiters() {
+					fc.Step(rootSessionRetryDelay)
+				}
+			}
+		}
+	}
+
+	// The old session survives two disconnects, so the fourth attempt succeeds.
+	rd := &fakeRootDaemon{session: &manager.SessionInfo{SessionId: "old"}, stickyCount: 2}
+	reconnected, err := connect(rd)
+	require.NoError(t, err)
+	assert.True(t, reconnected)
+	assert.Equal(t, 4, rd.connects)
+	assert.Equal(t, 3, rd.disconnects)
+
+	// The old session survives all disconnects, so the attempts are exhausted.
+	rd = &fakeRootDaemon{session: &manager.SessionInfo{SessionId: "old"}, stickySession: true}
+	_, err = connect(rd)
+	require.Error(t, err)
+	assert.Equal(t, errcat.Unknown, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "kept running session old instead of session new after 4 connect attempts")
+	assert.Equal(t, 4, rd.connects)
+	assert.Equal(t, 3, rd.disconnects)
+}
+
+func Test_session_ResyncDNSDomains(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	rd := &fakeRootDaemon{}
+	s := &session{Cluster: &k8s.Cluster{}, rootDaemon: rd}
+	require.NoError(t, s.ResyncDNSDomains(ctx))
+
+	// Simulate that the root daemon restarts and thereby loses its domains.
+	_, err := rd.Disconnect(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Empty(t, rd.domains)
+
+	require.NoError(t, s.ResyncDNSDomains(ctx))
+	assert.Equal(t, [][]string{{"svc"}}, rd.domains)
+}
+
+func Test_session_connectRootDaemonClient_resyncsDNS(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	nc := &rootdRpc.NetworkConfig{Session: &manager.SessionInfo{SessionId: "new"}}
+
+	// A root daemon that restarted, or that still runs a stale session, is reconnected and given the DNS domains again.
+	rd := &fakeRootDaemon{session: &manager.SessionInfo{SessionId: "old"}, networkUp: true}
+	s := &session{Cluster: &k8s.Cluster{}, rootDaemon: rd}
+	require.NoError(t, s.connectRootDaemonClient(ctx, rd, nc))
+	assert.Equal(t, 1, rd.disconnects)
+	assert.Equal(t, [][]string{{"svc"}}, rd.domains)
+
+	// A root daemon that runs the current session keeps its domains.
+	rd = &fakeRootDaemon{networkUp: true}
+	s = &session{Cluster: &k8s.Cluster{}, rootDaemon: rd}
+	require.NoError(t, s.connectRootDaemonClient(ctx, rd, nc))
+	assert.Zero(t, rd.disconnects)
+	assert.Empty(t, rd.domains)
+}
+
+func Test_session_ResyncDNSDomains_serviceSubdomain(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	cfg.DNS().ServiceSubdomain = "services"
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+	rd := &fakeRootDaemon{}
+	s := &session{Cluster: &k8s.Cluster{}, rootDaemon: rd}
+	require.NoError(t, s.ResyncDNSDomains(ctx))
+	assert.Equal(t, [][]string{{"services"}}, rd.domains)
+}
+
+func Test_session_ResyncDNSDomains_suffixes(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+	rd := &fakeRootDaemon{}
+	s := &session{Cluster: &k8s.Cluster{}, rootDaemon: rd}
+
+	// The default exclude suffixes aren't posted, so that the root daemon retains those of the traffic-manager.
+	require.NoError(t, s.ResyncDNSDomains(ctx))
+	assert.Empty(t, rd.suffixes.IncludeSuffixes)
+	assert.Empty(t, rd.suffixes.ExcludeSuffixes)
+
+	cfg.DNS().IncludeSuffixes = []string{".svc.corp"}
+	cfg.DNS().ExcludeSuffixes = []string{".corp"}
+	require.NoError(t, s.ResyncDNSDomains(ctx))
+	assert.Equal(t, [][]string{{"svc"}, {"svc"}}, rd.domains)
+	assert.Equal(t, []string{".svc.corp"}, rd.suffixes.IncludeSuffixes)
+	assert.Equal(t, []string{".corp"}, rd.suffixes.ExcludeSuffixes)
+}
+
+func Test_PreviewDNSDomains(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), fake.NewClientset(), argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, cfg)
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		subdomain  string
+	}{
+		{name: "default subdomain", namespaces: []string{"b", "a"}},
+		{name: "custom subdomain", namespaces: []string{"a"}, subdomain: "services"},
+		{name: "subdomain is a namespace", namespaces: []string{"svc", "a"}},
+		{name: "duplicates", namespaces: []string{"a", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.DNS().ServiceSubdomain = tt.subdomain
+			preview := k8s.PreviewDNSDomains(ctx, &rpc.ConnectRequest{MappedNamespaces: tt.namespaces})
+			assert.False(t, preview.AllNamespaces)
+
+			// The preview must equal what the session posts to the root daemon.
+			rd := &fakeRootDaemon{}
+			s := &session{Cluster: &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}}, rootDaemon: rd}
+			s.SetMappedNamespaces(ctx, slices.Clone(tt.namespaces))
+			require.NoError(t, s.ResyncDNSDomains(ctx))
+			assert.Equal(t, [][]string{preview.Domains}, rd.domains)
+		})
+	}
+
+	// Without mapped namespaces, all namespaces are mapped, and they aren't known in advance.
+	cfg.DNS().ServiceSubdomain = ""
+	preview := k8s.PreviewDNSDomains(ctx, &rpc.ConnectRequest{MappedNamespaces: []string{"all"}})
+	assert.True(t, preview.AllNamespaces)
+	assert.Equal(t, []string{"svc"}, preview.Domains)
+
+	// The mapped namespaces of the configuration are used when the request has none.
+	cfg.Cluster().MappedNamespaces = []string{"c", "a"}
+	preview = k8s.PreviewDNSDomains(ctx, &rpc.ConnectRequest{})
+	assert.False(t, preview.AllNamespaces)
+	assert.Equal(t, []string{"a", "c", "svc"}, preview.Domains)
+}
+
+func Test_rootDaemonDialError(t *testing.T) {
+	err := rootDaemonDialError("/tmp/root.socket", fmt.Errorf("dial: %w", os.ErrNotExist))
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "root daemon is not running")
+
+	err = rootDaemonDialError("/tmp/root.socket", errors.New("connection refused"))
+	assert.Equal(t, errcat.Unknown, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func Test_connectRootSession_errors(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	nc := &rootdRpc.NetworkConfig{Session: &manager.SessionInfo{SessionId: "new"}}
+	tests := []struct {
+		name string
+		rd   *fakeRootDaemon
+		cat  errcat.Category
+		msg  string
+	}{
+		{
+			"unreachable",
+			&fakeRootDaemon{connectErr: status.Error(codes.Unavailable, "connection refused")},
+			errcat.User,
+			"not reachable: connection refused",
+		},
+		{
+			"connect failure",
+			&fakeRootDaemon{connectErr: status.Error(codes.Internal, "boom")},
+			errcat.Unknown,
+			"failed to connect the session",
+		},
+		{
+			"version mismatch",
+			&fakeRootDaemon{noSession: true},
+			errcat.User,
+			"version differs",
+		},
+		{
+			"session mismatch",
+			&fakeRootDaemon{session: &manager.SessionInfo{SessionId: "old"}, stickySession: true},
+			errcat.Unknown,
+			"kept running session old",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := connectRootSession(ctx, tt.rd, nc)
+			require.Error(t, err)
+			assert.Equal(t, tt.cat, errcat.GetCategory(err))
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func Test_waitForRootNetwork(t *testing.T) {
+	cfg := client.GetDefaultConfig()
+	cfg.Timeouts().PrivateTrafficManagerAPI = 50 * time.Millisecond
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+	tests := []struct {
+		name string
+		err  error
+		cat  errcat.Category
+		msg  string
+	}{
+		{
+			"timeout",
+			nil,
+			errcat.Config,
+			"timeouts.trafficManagerAPI",
+		},
+		{
+			"TUN device permission denied",
+			status.Error(codes.PermissionDenied, "failed to open TUN device /dev/net/tun: operation not permitted"),
+			errcat.User,
+			"denied permission to create the TUN device",
+		},
+		{
+			"utun permission denied",
+			status.Error(codes.PermissionDenied, "failed to open DGRAM socket: operation not permitted"),
+			errcat.User,
+			"denied permission to create the TUN device: failed to open DGRAM socket",
+		},
+		{
+			"TUN device failure",
+			status.Error(codes.FailedPrecondition, "failed to set TUN device flags: device busy"),
+			errcat.Unknown,
+			"failed to create the TUN device",
+		},
+		{
+			"DNS failure",
+			status.Error(codes.Unavailable, "dns server failed to start"),
+			errcat.Unknown,
+			"failed to set up the network: dns server failed to start",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := waitForRootNetwork(ctx, &fakeRootDaemon{networkErr: tt.err})
+			require.Error(t, err)
+			assert.Equal(t, tt.cat, errcat.GetCategory(err))
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func Test_dialRootDaemon_unresponsive(t *testing.T) {
+	// The socket exists, but nothing ever answers on it.
+	sockname := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := net.Listen("unix", sockname)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := client.GetDefaultConfig()
+	cfg.Timeouts().PrivateRootDaemonDial = 200 * time.Millisecond
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+
+	start := time.Now()
+	conn, err := dialRootDaemon(ctx, sockname)
+	assert.Nil(t, conn)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "might be stuck")
+	assert.ErrorContains(t, err, "telepresence quit -s")
+	assert.ErrorContains(t, err, "timeouts.rootDaemonDial")
+}
+
+func Test_dialRootDaemon_unresponsiveLongTimeout(t *testing.T) {
+	// The socket exists, but nothing ever answers on it.
+	sockname := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := net.Listen("unix", sockname)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// A timeout that is longer than the time that the socket package waits by default must still be
+	// reported as a root daemon that might be stuck.
+	cfg := client.GetDefaultConfig()
+	cfg.Timeouts().PrivateRootDaemonDial = 3500 * time.Millisecond
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), cfg)
+
+	conn, err := dialRootDaemon(ctx, sockname)
+	assert.Nil(t, conn)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "might be stuck")
+	assert.ErrorContains(t, err, "timeouts.rootDaemonDial")
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"os"
 	"os/user"
@@ -33,7 +34,6 @@ import (
 	"github.com/datawire/dlib/derror"
 	"github.com/datawire/dlib/dgroup"
 	"github.com/datawire/dlib/dlog"
-	"github.com/datawire/dlib/dtime"
 	"github.com/telepresenceio/telepresence/rpc/v2/authenticator"
 	"github.com/telepresenceio/telepresence/rpc/v2/common"
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
@@ -55,31 +55,49 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/informer"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
 	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
 	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
 	"github.com/telepresenceio/telepresence/v2/pkg/workload"
 )
 
 type apiServer struct {
 	restapi.Server
 	cancel context.CancelFunc
+
+	// err is the error that made the server fail, if any.
+	err error
 }
 
 type apiMatcher struct {
 	requestMatcher matcher.Request
 	metadata       map[string]string
+
+	// allConnections is true when the intercept forwards all connections for the intercepted port, in which
+	// case all requests are considered intercepted and the requestMatcher is nil.
+	allConnections bool
+}
+
+// apiMatcherKey identifies the apiMatcher of an intercept for a container port. The port is zero for the
+// intercept's default matcher, which is used for container ports that have no matcher of their own.
+type apiMatcherKey struct {
+	id   string
+	port uint16
 }
 
 type workloadInfoKey struct {
-	kind manager.WorkloadInfo_Kind
-	name string
+	kind      manager.WorkloadInfo_Kind
+	namespace string
+	name      string
 }
 
 type workloadInfo struct {
 	uid              types.UID
 	state            workload.State
 	agentState       manager.WorkloadInfo_AgentState
+	agentStateReason string
 	interceptClients []string
 }
 
@@ -92,18 +110,43 @@ type session struct {
 	installID string // telepresence's install ID
 	clientID  string // "laptop-username@laptop-hostname"
 
+	// managerLock protects managerClient and managerConn, which are replaced when the connection to the
+	// traffic-manager is reestablished.
+	managerLock sync.RWMutex
+
 	// manager client
 	managerClient manager.ManagerClient
 
 	// manager client connection
 	managerConn *grpc.ClientConn
 
-	// name reported by the manager
+	// managerReconnectLock ensures that only one reconnect to the traffic-manager is made at a time.
+	managerReconnectLock sync.Mutex
+
+	// dialManager creates a new connection to the traffic-manager. A session without it cannot reconnect.
+	dialManager func(context.Context) (*grpc.ClientConn, manager.ManagerClient, error)
+
+	// namespace of the traffic-manager
+	managerNamespace string
+
+	// name reported by the manager, protected by the managerLock
 	managerName string
 
-	// version reported by the manager
+	// version reported by the manager, protected by the managerLock
 	managerVersion semver.Version
 
+	// warning to show when the client is older than the version recommended by the manager
+	versionWarning string
+
+	// metadata that the client attached to the session when it arrived
+	metadata map[string]string
+
+	// configLock serializes the calls to ApplyConfig and protects the configMappedNamespaces.
+	configLock sync.Mutex
+
+	// configMappedNamespaces are the sorted cluster.mappedNamespaces of the configuration that was last applied.
+	configMappedNamespaces []string
+
 	// The identifier for this daemon
 	daemonID *daemon.Identifier
 
@@ -111,18 +154,56 @@ type session struct {
 
 	workloadsLock sync.Mutex
 
-	// Map of manager.WorkloadInfo split into namespace, key of kind and name, and workloadInfo
-	workloads map[string]map[workloadInfoKey]workloadInfo
+	// Map of manager.WorkloadInfo keyed by kind, namespace, and name
+	workloads map[workloadInfoKey]workloadInfo
+
+	// Namespaces for which a workload watcher has delivered its initial sync
+	syncedNamespaces map[string]struct{}
+
+	// Functions that stop the workload watchers, keyed by namespace
+	watcherCancels map[string]context.CancelFunc
+
+	// Wait groups that are done when a running workload watcher has delivered its initial sync or ended,
+	// keyed by namespace
+	watcherSyncs map[string]*sync.WaitGroup
 
 	workloadSubscribers map[uuid.UUID]chan struct{}
 
+	// workloadWatchPauses is the number of calls to PauseWorkloadWatch that haven't yet been matched by a
+	// call to ResumeWorkloadWatch. The workloadSubscribers aren't notified while it's greater than zero.
+	workloadWatchPauses int
+
+	// workloadWatchPauseIDs are the channels that end the pauses made using PauseWorkloadWatchFor, keyed by
+	// the ID of the pause.
+	workloadWatchPauseIDs map[string]chan struct{}
+
+	// workloadPrefixes limits the workloads that the workload watchers store to those whose name starts with
+	// one of the prefixes. All workloads are stored when it's empty.
+	workloadPrefixes []string
+
+	// audit is the optional audit log of observed workload and intercept events.
+	audit *auditLog
+
+	// events distributes the session events to the subscribers of Events.
+	events eventBus
+
+	// watchErrors contains the error that ended the last workload watcher for a namespace. A namespace
+	// has no entry unless its last watcher ended with an error.
+	watchErrors map[string]error
+
+	// Backoffs of the namespaces whose workload watchers have failed since they last synced.
+	watchBackoffs map[string]*watchBackoff
+
+	// rolloutsMissingWarning ensures that the absence of the Argo Rollouts CRD is reported once.
+	rolloutsMissingWarning sync.Once
+
 	// currentIngests is tracks the ingests that are active in this session.
 	currentIngests *xsync.MapOf[ingestKey, *ingest]
 
 	ingestTracker *podAccessTracker
 
 	// currentInterceptsLock ensures that all accesses to currentAgents, currentIntercepts, currentMatchers,
-	// currentAPIServers, interceptWaiters, and ingressInfo are synchronized
+	// currentAPIServers, interceptWaiters, interceptGroups, and ingressInfo are synchronized
 	//
 	currentInterceptsLock sync.Mutex
 
@@ -134,7 +215,7 @@ type session struct {
 	currentIntercepts map[string]*intercept
 
 	// currentMatches hold the matchers used when using the APIServer.
-	currentMatchers map[string]*apiMatcher
+	currentMatchers map[apiMatcherKey]*apiMatcher
 
 	// currentAPIServers contains the APIServer in use. Typically zero or only one, but since the
 	// port is determined by the intercept, there might theoretically be serveral.
@@ -145,10 +226,50 @@ type session struct {
 	// are deleted as soon as the intercept arrives and gets stored in currentIntercepts
 	interceptWaiters map[string]*awaitIntercept
 
-	ingressInfo []*manager.IngressInfo
+	// Map of intercept names to the name of the intercept group that they were created with. Entries
+	// are added before the intercepts are created, and deleted when the intercepts end.
+	interceptGroups map[string]string
+
+	// Intercepts that were removed because they were idle, protected by the currentInterceptsLock.
+	idleRemoved []*rpc.IdleRemovedIntercept
+
+	// Intercepts that are draining, keyed by name. Protected by the currentInterceptsLock.
+	draining map[string]drain
+
+	// drains is the group of the goroutines that end the draining intercepts.
+	drains sync.WaitGroup
+
+	// interceptHistory records the intercepts that have ended in this session.
+	interceptHistory *interceptHistory
+
+	// interceptResync tells the intercept watcher to fetch and apply the intercepts of the traffic-manager
+	// in order to heal a divergence between them and the currentIntercepts.
+	interceptResync chan struct{}
+
+	// dialActivity records the dial requests that the traffic-manager sends to this session, so that
+	// intercepts without traffic can be removed.
+	dialActivity *tunnel.DialActivity
+
+	// ingressInfo is the ingress info last retrieved from the cluster, and ingressInfoRefreshed the
+	// time when that happened. A zero ingressInfoRefreshed means that the info hasn't been retrieved.
+	ingressInfo          []*manager.IngressInfo
+	ingressInfoRefreshed time.Time
+
+	// exposure tells which workloads of each namespace have no ports that can be intercepted, as found in
+	// the caches of the namespaceInformers.
+	exposureLock sync.Mutex
+	exposure     map[string]*namespaceExposure
+
+	// namespaceInformers are the informers, keyed by namespace, that cache the resources used by the exposure
+	// precheck and the pod listing.
+	namespaceInformersLock sync.Mutex
+	namespaceInformers     map[string]*namespaceInformers
 
 	isPodDaemon bool
 
+	// serviceGate decides when the services started by StartServices can start.
+	serviceGate *serviceGate
+
 	// done is closed when the session ends
 	done chan struct{}
 
@@ -162,9 +283,11 @@ func NewSession(
 	config *client.Kubeconfig,
 ) (rc context.Context, _ userd.Session, info *connector.ConnectInfo) {
 	dlog.Info(ctx, "-- Starting new session")
+	ctx = scout.WithRateLimit(ctx, client.GetConfig(ctx).Telemetry().RateLimit)
 
 	cr := cri.Request()
-	connectStart := time.Now()
+	clk := client.GetClock(ctx)
+	connectStart := clk.Now()
 	defer func() {
 		if r := recover(); r != nil {
 			rc = ctx
@@ -176,7 +299,7 @@ func NewSession(
 			scout.Report(ctx, "connect",
 				scout.Entry{
 					Key:   "time_to_connect",
-					Value: time.Since(connectStart).Seconds(),
+					Value: clk.Since(connectStart).Seconds(),
 				}, scout.Entry{
 					Key:   "mapped_namespaces",
 					Value: len(cr.MappedNamespaces),
@@ -194,7 +317,7 @@ func NewSession(
 					Value: info.ErrorCategory,
 				}, scout.Entry{
 					Key:   "time_to_fail",
-					Value: time.Since(connectStart).Seconds(),
+					Value: clk.Since(connectStart).Seconds(),
 				}, scout.Entry{
 					Key:   "mapped_namespaces",
 					Value: len(cr.MappedNamespaces),
@@ -203,6 +326,7 @@ func NewSession(
 	}()
 
 	dlog.Info(ctx, "Connecting to k8s cluster...")
+	userd.ReportConnectProgress(ctx, rpc.ConnectProgress_CONNECTING_TO_CLUSTER)
 	ctx, cluster, err := k8s.ConnectCluster(ctx, cr, config)
 	if err != nil {
 		dlog.Errorf(ctx, "unable to track k8s cluster: %+v", err)
@@ -213,14 +337,30 @@ func NewSession(
 
 	ctx = cluster.WithJoinedClientSetInterface(ctx)
 
+	if cr.CheckPermissions {
+		dlog.Info(ctx, "Checking RBAC permissions...")
+		userd.ReportConnectProgress(ctx, rpc.ConnectProgress_CHECKING_PERMISSIONS)
+		report, err := cluster.CheckPermissions(ctx)
+		if err == nil {
+			err = k8sclient.MissingPermissionsError(report)
+		}
+		if err != nil {
+			return ctx, nil, connectError(rpc.ConnectInfo_CLUSTER_FAILED, err)
+		}
+	}
+
 	dlog.Info(ctx, "Connecting to traffic manager...")
+	userd.ReportConnectProgress(ctx, rpc.ConnectProgress_CONNECTING_TO_MANAGER)
 	installID, err := client.InstallID(ctx)
 	if err != nil {
 		return ctx, nil, connectError(rpc.ConnectInfo_TRAFFIC_MANAGER_FAILED, err)
 	}
-	tmgr, err := connectMgr(ctx, cluster, installID, cr)
+	tmgr, err := connectMgrWithRetry(ctx, cluster, installID, cr)
 	if err != nil {
 		dlog.Errorf(ctx, "Unable to connect to session: %s", err)
+		if errors.Is(err, ErrTrafficManagerNotFound) {
+			return ctx, nil, connectError(rpc.ConnectInfo_TRAFFIC_MANAGER_NOT_FOUND, err)
+		}
 		return ctx, nil, connectError(rpc.ConnectInfo_TRAFFIC_MANAGER_FAILED, err)
 	}
 
@@ -235,20 +375,41 @@ func NewSession(
 		}
 		tmCfg = client.GetDefaultConfig()
 	} else {
-		tmCfg, err = client.ParseConfigYAML(ctx, "client configuration from cluster", cliCfg.ConfigYaml)
+		// The traffic-manager validates this config, and it may have keys that this client doesn't know about.
+		tmCfg, err = client.ParseConfigYAML(client.WithStrictConfig(ctx, false), "client configuration from cluster", cliCfg.ConfigYaml)
 		if err != nil {
 			dlog.Warn(ctx, err.Error())
 		}
 	}
 
-	// Merge traffic-manager's reported config, but get priority to the local config.
+	// Merge traffic-manager's reported config, but get priority to the local config unless the
+	// traffic-manager's config declares another merge strategy for a key.
 	cfg := client.GetConfig(ctx)
+
+	// The audit log is written by this process, so its location is never taken from the cluster.
+	auditFile := cfg.Audit().File
 	if tmCfg != nil {
-		cfg = tmCfg.Merge(cfg)
-		rt := cfg.Routing()
-		rt.NeverProxy = append(rt.NeverProxy, tmCfg.Routing().NeverProxy...)
-		ctx = client.WithConfig(ctx, cfg)
+		if mergedCfg, err := client.MergeClusterConfig(tmCfg, cfg); err != nil {
+			// Merging without the strategies could override a cluster policy, so the local config is kept as is.
+			dlog.Errorf(ctx, "Failed to merge client configuration from cluster, using the local configuration only: %v", err)
+		} else {
+			cfg = mergedCfg
+			ctx = client.WithConfig(ctx, cfg)
+		}
+	}
+	auditCfg := *cfg.Audit()
+	if auditCfg.File != auditFile {
+		dlog.Warnf(ctx, "Ignoring audit.file %q of client configuration from cluster", auditCfg.File)
+		auditCfg.File = auditFile
 	}
+	tmgr.audit = newAuditLog(ctx, &auditCfg, tmgr.clientID)
+	tmgr.interceptHistory = newInterceptHistory(cfg.Intercept().HistorySize)
+	tmgr.workloadPrefixes = cr.WorkloadPrefixes
+	if len(tmgr.workloadPrefixes) == 0 {
+		tmgr.workloadPrefixes = cfg.Workloads().NamePrefixes
+	}
+	// ApplyConfig reapplies the mapped namespaces of the config when they differ from these.
+	tmgr.configMappedNamespaces = sortedNamespaces(cfg.Cluster().MappedNamespaces)
 	if err = tmgr.ApplyConfig(ctx); err != nil {
 		dlog.Warn(ctx, err.Error())
 	}
@@ -262,15 +423,20 @@ func NewSession(
 		}
 	}
 
-	oi := tmgr.getNetworkInfo(ctx, cr)
+	userd.ReportConnectProgress(ctx, rpc.ConnectProgress_WAITING_FOR_NETWORK)
+	oi, err := tmgr.getNetworkInfo(ctx, cr)
+	if err != nil {
+		return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, err)
+	}
 	if !userd.GetService(ctx).RootSessionInProcess() {
 		// Connect to the root daemon if it is running. It's the CLI that starts it initially
-		rootRunning, err := socket.IsRunning(ctx, socket.RootDaemonPath(ctx))
+		rdPath := socket.RootDaemonPath(ctx)
+		rootRunning, err := socket.IsRunning(ctx, rdPath)
 		if err != nil {
-			return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, err)
+			return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, rootDaemonDialError(rdPath, err))
 		}
 		if !rootRunning {
-			return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, errors.New("root daemon is not running"))
+			return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, rootDaemonDialError(rdPath, os.ErrNotExist))
 		}
 
 		if client.GetConfig(ctx).Cluster().ConnectFromRootDaemon {
@@ -300,6 +466,8 @@ func NewSession(
 	dlog.Debug(ctx, "Finished connecting to traffic manager")
 
 	tmgr.AddNamespaceListener(ctx, tmgr.updateDaemonNamespaces)
+	tmgr.AddNamespaceListener(ctx, tmgr.pruneWorkloadNamespaces)
+	tmgr.AddNamespaceListener(ctx, tmgr.emitNamespacesChanged)
 	return ctx, tmgr, tmgr.status(ctx, true)
 }
 
@@ -315,10 +483,11 @@ func (s *session) SetSelf(self userd.Session) {
 //   - (3) listen on the appropriate local ports and forward them to the intercepted
 //     Services, and
 //   - (4) mount the appropriate remote volumes.
-func (s *session) RunSession(c context.Context) error {
+func (s *session) RunSession(c context.Context) (err error) {
 	self := s.self
 	g := dgroup.NewGroup(c, dgroup.GroupConfig{})
 	defer func() {
+		s.emitSessionEnded(c, err)
 		self.Epilog(c)
 	}()
 	self.StartServices(g)
@@ -330,21 +499,61 @@ func (s *session) RootDaemon() rootdRpc.DaemonClient {
 }
 
 func (s *session) ManagerClient() manager.ManagerClient {
+	s.managerLock.RLock()
+	defer s.managerLock.RUnlock()
 	return s.managerClient
 }
 
 func (s *session) ManagerConn() *grpc.ClientConn {
+	s.managerLock.RLock()
+	defer s.managerLock.RUnlock()
 	return s.managerConn
 }
 
 func (s *session) ManagerName() string {
+	s.managerLock.RLock()
+	defer s.managerLock.RUnlock()
 	return s.managerName
 }
 
 func (s *session) ManagerVersion() semver.Version {
+	s.managerLock.RLock()
+	defer s.managerLock.RUnlock()
 	return s.managerVersion
 }
 
+// connectMgrWithRetry calls connectMgr. A pod daemon that fails to connect will retry with backoff until it
+// succeeds or the podDaemonConnect timeout expires, so that it can ride out a traffic-manager rollout instead
+// of exiting and ending up in a crash-loop.
+func connectMgrWithRetry(
+	ctx context.Context,
+	cluster *k8s.Cluster,
+	installID string,
+	cr *rpc.ConnectRequest,
+) (*session, error) {
+	if !cr.IsPodDaemon {
+		return connectMgr(ctx, cluster, installID, cr)
+	}
+	clk := client.GetClock(ctx)
+	deadline := clk.Now().Add(client.GetConfig(ctx).Timeouts().Get(client.TimeoutPodDaemonConnect))
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		tmgr, err := connectMgr(ctx, cluster, installID, cr)
+		if err == nil {
+			return tmgr, nil
+		}
+		if ctx.Err() != nil || errcat.GetCategory(err) == errcat.User || clk.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+		dlog.Warnf(ctx, "Connect attempt %d to traffic manager failed, retrying in %s: %v", attempt, backoff, err)
+		client.SleepWithContext(ctx, backoff)
+		backoff *= 2
+		if backoff > 10*time.Second {
+			backoff = 10 * time.Second
+		}
+	}
+}
+
 // connectMgr returns a session for the given cluster that is connected to the traffic-manager.
 func connectMgr(
 	ctx context.Context,
@@ -357,13 +566,18 @@ func connectMgr(
 	ctx, cancel := tos.TimeoutContext(ctx, client.TimeoutTrafficManagerConnect)
 	defer cancel()
 
+	// The traffic-manager might be installed in a cluster other than the one where the workloads are.
+	mgrCtx, err := withManagerCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
 	mgrNs := k8s.GetManagerNamespace(ctx)
-	err := CheckTrafficManagerService(ctx, mgrNs)
+	err = CheckTrafficManagerService(mgrCtx, mgrNs)
 	if err != nil {
 		return nil, err
 	}
 
-	conn, mClient, vi, err := k8sclient.ConnectToManager(ctx, mgrNs)
+	conn, mClient, vi, err := k8sclient.ConnectToManager(mgrCtx, mgrNs)
 	if err != nil {
 		return nil, err
 	}
@@ -371,6 +585,11 @@ func connectMgr(
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse manager.Version: %w", err)
 	}
+	versionWarning, err := checkClientVersion(ctx, vi, client.Semver())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
 
 	clientID := cr.ClientId
 	if clientID == "" {
@@ -390,115 +609,159 @@ func connectMgr(
 	if err != nil {
 		return nil, err
 	}
-	si, err := LoadSessionInfoFromUserCache(ctx, daemonID)
+	dlog.Debugf(ctx, "traffic-manager port-forward established, making client known to the traffic-manager as %q", clientID)
+	si, err := arrive(ctx, mClient, daemonID, mgrNs, &manager.ClientInfo{
+		Name:      clientID,
+		Namespace: cluster.Namespace,
+		InstallId: installID,
+		Product:   "telepresence",
+		Version:   client.Version(),
+		Metadata:  cr.Metadata,
+	}, cr.ForceNewSession)
 	if err != nil {
 		return nil, err
 	}
 
 	svc := userd.GetService(ctx)
-	if si != nil {
-		// Check if the session is still valid in the traffic-manager by calling Remain
-		_, err = mClient.Remain(ctx, &manager.RemainRequest{Session: si})
-		if err == nil {
-			if ctx.Err() != nil {
-				// Call timed out, so the traffic-manager isn't responding at all
-				return nil, ctx.Err()
-			}
-			dlog.Debugf(ctx, "traffic-manager port-forward established, client was already known to the traffic-manager as %q", clientID)
-		} else {
-			si = nil
-		}
-	}
+	svc.SetManagerClient(mClient, managerCallOptions(ctx)...)
 
-	if si == nil {
-		dlog.Debugf(ctx, "traffic-manager port-forward established, making client known to the traffic-manager as %q", clientID)
-		si, err = mClient.ArriveAsClient(ctx, &manager.ClientInfo{
-			Name:      clientID,
-			Namespace: cluster.Namespace,
-			InstallId: installID,
-			Product:   "telepresence",
-			Version:   client.Version(),
-		})
-		if err != nil {
-			if st, ok := status.FromError(err); ok && st.Code() == codes.FailedPrecondition {
-				return nil, errcat.User.New(st.Message())
-			}
-			return nil, client.CheckTimeout(ctx, fmt.Errorf("manager.ArriveAsClient: %w", err))
-		}
-		if err = SaveSessionInfoToUserCache(ctx, daemonID, si); err != nil {
-			return nil, err
-		}
-	}
-
-	var opts []grpc.CallOption
-	cfg := client.GetConfig(ctx)
-	if mz := cfg.Grpc().MaxReceiveSize(); mz > 0 {
-		opts = append(opts, grpc.MaxCallRecvMsgSize(int(mz)))
-	}
-	svc.SetManagerClient(mClient, opts...)
-
-	managerName := vi.Name
-	if managerName == "" {
-		// Older traffic-managers doesn't distinguish between OSS and pro versions
-		managerName = "Traffic Manager"
-	}
-
-	sess := &session{
+	return initSession(&session{
 		Cluster:            cluster,
 		installID:          installID,
 		daemonID:           daemonID,
 		clientID:           clientID,
 		managerClient:      mClient,
 		managerConn:        conn,
-		managerName:        managerName,
+		managerNamespace:   mgrNs,
+		managerName:        managerDisplayName(vi),
 		managerVersion:     managerVersion,
+		versionWarning:     versionWarning,
+		metadata:           cr.Metadata,
 		sessionInfo:        si,
-		currentIngests:     xsync.NewMapOf[ingestKey, *ingest](),
-		ingestTracker:      newPodAccessTracker(),
-		workloads:          make(map[string]map[workloadInfoKey]workloadInfo),
-		interceptWaiters:   make(map[string]*awaitIntercept),
 		isPodDaemon:        cr.IsPodDaemon,
-		done:               make(chan struct{}),
 		subnetViaWorkloads: cr.SubnetViaWorkloads,
+		dialManager: func(ctx context.Context) (*grpc.ClientConn, manager.ManagerClient, error) {
+			ctx, cancel := client.GetConfig(ctx).Timeouts().TimeoutContext(ctx, client.TimeoutTrafficManagerConnect)
+			defer cancel()
+			mgrCtx, err := withManagerCluster(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+			conn, mClient, _, err := k8sclient.ConnectToManager(mgrCtx, mgrNs)
+			return conn, mClient, err
+		},
+	}), nil
+}
+
+// initSession initializes the internal state of the given session, which must be configured with its
+// cluster, traffic-manager client, and session info, and returns it. The managerConn may be nil, which
+// is the case when the traffic-manager client is an in-memory fake.
+func initSession(s *session) *session {
+	s.currentIngests = xsync.NewMapOf[ingestKey, *ingest]()
+	s.ingestTracker = newPodAccessTracker()
+	s.workloads = make(map[workloadInfoKey]workloadInfo)
+	s.syncedNamespaces = make(map[string]struct{})
+	s.interceptWaiters = make(map[string]*awaitIntercept)
+	s.interceptResync = make(chan struct{}, 1)
+	s.dialActivity = tunnel.NewDialActivity()
+	s.done = make(chan struct{})
+	s.self = s
+	return s
+}
+
+// managerCallOptions returns the options to use when calling the traffic-manager on behalf of clients of the
+// user daemon.
+func managerCallOptions(ctx context.Context) []grpc.CallOption {
+	var opts []grpc.CallOption
+	if mz := client.GetConfig(ctx).Grpc().MaxReceiveSize(); mz > 0 {
+		opts = append(opts, grpc.MaxCallRecvMsgSize(int(mz)))
 	}
-	sess.self = sess
-	return sess, nil
+	return opts
 }
 
+// AmendRemainRequestFunc is called with each new RemainRequest and the session that sends it, so that extensions
+// can populate fields beyond the session, e.g. to tell the traffic-manager about the health of the client. It's
+// called from the remain loop, concurrently with the other services of the session, and must therefore only use
+// the thread-safe methods of the session. It must be assigned before any session is started.
+var AmendRemainRequestFunc = func(userd.Session, *manager.RemainRequest) {} //nolint:gochecknoglobals // extension point
+
 func (s *session) NewRemainRequest() *manager.RemainRequest {
-	return &manager.RemainRequest{Session: s.SessionInfo()}
+	rr := &manager.RemainRequest{Session: s.SessionInfo()}
+	AmendRemainRequestFunc(s.self, rr)
+	return rr
 }
 
 func (s *session) Remain(ctx context.Context) error {
 	self := s.self
 	ctx, cancel := client.GetConfig(ctx).Timeouts().TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
 	defer cancel()
-	_, err := self.ManagerClient().Remain(ctx, self.NewRemainRequest())
+	mc := self.ManagerClient()
+	_, err := mc.Remain(ctx, self.NewRemainRequest())
 	if err != nil {
-		if status.Code(err) == codes.NotFound || status.Code(err) == codes.Unavailable {
+		code := status.Code(err)
+		if code == codes.Unavailable && s.reconnectManager(ctx, mc) == nil {
+			// The connection was lost, but the session survived a reconnect.
+			return nil
+		}
+		cfg := client.GetConfig(ctx).Session()
+		switch {
+		case cfg.IsTransientCode(code):
+			dlog.Warnf(ctx, "transient error calling Remain: %v", client.CheckTimeout(ctx, err))
+		case cfg.IsExpiredCode(code):
 			// The session has expired. We need to cancel the owner session and reconnect.
 			return ErrSessionExpired
+		default:
+			dlog.Errorf(ctx, "error calling Remain: %v", client.CheckTimeout(ctx, err))
 		}
-		dlog.Errorf(ctx, "error calling Remain: %v", client.CheckTimeout(ctx, err))
 	}
 	return nil
 }
 
+// ErrTrafficManagerNotFound is wrapped by the error that CheckTrafficManagerService returns when the traffic-manager
+// service doesn't exist, so that callers can offer to install it. The same condition is reported to clients of the
+// user daemon using the connector.ConnectInfo_TRAFFIC_MANAGER_NOT_FOUND error type.
+var ErrTrafficManagerNotFound = errors.New("traffic manager not found") //nolint:gochecknoglobals // constant
+
+// ErrTrafficManagerServiceRenamed is wrapped by the error that CheckTrafficManagerService returns when the
+// traffic-manager is installed in the namespace, but its service has another name than the configured one.
+var ErrTrafficManagerServiceRenamed = errors.New("traffic manager service renamed") //nolint:gochecknoglobals // constant
+
+// managerServiceSelector selects the service of a traffic-manager that was installed using the Helm chart.
+const managerServiceSelector = "app=traffic-manager,telepresence=manager"
+
+// CheckTrafficManagerService checks that the traffic-manager service, named by the cluster.managerService setting,
+// exists in the given namespace. When it doesn't, the namespace is searched for a service with the labels of the
+// traffic-manager service, in order to tell a traffic-manager that isn't installed in the namespace from one that
+// has a renamed service. The returned error wraps ErrTrafficManagerNotFound in the former case, and
+// ErrTrafficManagerServiceRenamed in the latter.
 func CheckTrafficManagerService(ctx context.Context, namespace string) error {
 	dlog.Debug(ctx, "checking that traffic-manager exists")
+	svcName := client.GetConfig(ctx).Cluster().ManagerService
 	coreV1 := k8sapi.GetK8sInterface(ctx).CoreV1()
-	if _, err := coreV1.Services(namespace).Get(ctx, "traffic-manager", meta.GetOptions{}); err != nil {
-		msg := fmt.Sprintf("unable to get service traffic-manager in %s: %v", namespace, err)
-		se := &k8serrors.StatusError{}
-		if errors.As(err, &se) {
-			if se.Status().Code == http.StatusNotFound {
-				msg = "traffic manager not found, if it is not installed, please run 'telepresence helm install'. " +
-					"If it is installed, try connecting with a --manager-namespace to point telepresence to the namespace it's installed in."
+	_, err := coreV1.Services(namespace).Get(ctx, svcName, meta.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	se := &k8serrors.StatusError{}
+	if !errors.As(err, &se) || se.Status().Code != http.StatusNotFound {
+		return errcat.User.Newf("unable to get service %s in %s: %v", svcName, namespace, err)
+	}
+
+	// The lack of permission to list services just means that a renamed service can't be detected.
+	if sl, err := coreV1.Services(namespace).List(ctx, meta.ListOptions{LabelSelector: managerServiceSelector}); err == nil {
+		for _, svc := range sl.Items {
+			if svc.Name != svcName {
+				return errcat.User.Newf("%w: the traffic-manager service in namespace %s is named %s, not %s. "+
+					"Set cluster.managerService to %s in the client configuration.",
+					ErrTrafficManagerServiceRenamed, namespace, svc.Name, svcName, svc.Name)
 			}
 		}
-		return errcat.User.New(msg)
+	} else {
+		dlog.Debugf(ctx, "unable to list services in %s: %v", namespace, err)
 	}
-	return nil
+	return errcat.User.Newf("%w in namespace %s, if it is not installed, please run 'telepresence helm install'. "+
+		"If it is installed, try connecting with a --manager-namespace to point telepresence to the namespace it's installed in.",
+		ErrTrafficManagerNotFound, namespace)
 }
 
 func connectError(t rpc.ConnectInfo_ErrType, err error) *rpc.ConnectInfo {
@@ -519,21 +782,37 @@ func connectError(t rpc.ConnectInfo_ErrType, err error) *rpc.ConnectInfo {
 	}
 }
 
-// updateDaemonNamespacesLocked will create a new DNS search path from the given namespaces and
-// send it to the DNS-resolver in the daemon.
+// updateDaemonNamespaces is the namespace listener that keeps the DNS top-level domains of the
+// root daemon in sync with the mapped namespaces.
 func (s *session) updateDaemonNamespaces(c context.Context) {
-	const svcDomain = "svc"
+	if err := s.ResyncDNSDomains(c); err != nil {
+		dlog.Error(c, err)
+	}
+}
 
-	domains := s.GetCurrentNamespaces(false)
-	if !slices.Contains(domains, svcDomain) {
-		domains = append(domains, svcDomain)
+// ResyncDNSDomains creates a DNS search path from the current namespaces and posts it to
+// the DNS-resolver in the root daemon. It's called whenever the mapped namespaces change,
+// and when the root daemon has lost its state, e.g. due to a restart.
+func (s *session) ResyncDNSDomains(c context.Context) error {
+	return s.postDNSDomains(c, s.rootDaemon)
+}
+
+func (s *session) postDNSDomains(c context.Context, rd rootdRpc.DaemonClient) error {
+	dns := client.GetConfig(c).DNS()
+	domains := k8s.DNSDomains(s.GetCurrentNamespaces(false), dns.ServiceSubdomain)
+	rq := &rootdRpc.Domains{Domains: domains, IncludeSuffixes: dns.IncludeSuffixes}
+	if !slices.Equal(dns.ExcludeSuffixes, client.DefaultExcludeSuffixes) {
+		// The root daemon retains the exclude suffixes of the traffic-manager unless the defaults are overridden.
+		rq.ExcludeSuffixes = dns.ExcludeSuffixes
 	}
-	dlog.Debugf(c, "posting top-level domains %v to root daemon", domains)
+	dlog.Debugf(c, "posting top-level domains %v, include suffixes %v, and exclude suffixes %v to root daemon",
+		domains, rq.IncludeSuffixes, rq.ExcludeSuffixes)
 
-	if _, err := s.rootDaemon.SetDNSTopLevelDomains(c, &rootdRpc.Domains{Domains: domains}); err != nil {
-		dlog.Errorf(c, "error posting domains %v to root daemon: %v", domains, err)
+	if _, err := rd.SetDNSTopLevelDomains(c, rq); err != nil {
+		return fmt.Errorf("error posting domains %v to root daemon: %w", domains, err)
 	}
 	dlog.Debug(c, "domains posted successfully")
+	return nil
 }
 
 func (s *session) Epilog(ctx context.Context) {
@@ -543,22 +822,65 @@ func (s *session) Epilog(ctx context.Context) {
 }
 
 func (s *session) StartServices(g *dgroup.Group) {
-	g.Go("remain", s.remainLoop)
-	g.Go("agents", s.watchAgentsLoop)
-	g.Go("intercept-port-forward", s.watchInterceptsHandler)
-	g.Go("dial-request-watcher", s.dialRequestWatcher)
+	services := []sessionService{
+		{"remain", s.remainLoop},
+		{"manager-watchdog", s.managerWatchdog},
+		{"agents", s.watchAgentsLoop},
+		{"intercept-port-forward", s.watchInterceptsHandler},
+		{"intercept-reconcile", s.reconcileInterceptsLoop},
+		{"intercept-idle", s.idleInterceptsLoop},
+		{"dial-request-watcher", s.dialRequestWatcher},
+		{"workloads-prewarm", s.prewarmWatchers},
+		{"max-lifetime", s.maxLifetimeLoop},
+		{"manager-version", s.managerVersionLoop},
+	}
+	if s.audit != nil {
+		services = append(services, sessionService{"audit-log", s.audit.run})
+	}
+	s.startServices(g, services)
 }
 
-func runWithRetry(ctx context.Context, f func(context.Context) error) error {
+// retryStormThreshold is the number of consecutive failures that a loop started by runWithRetry
+// must reach before a "retry_storm" scout event is reported.
+const retryStormThreshold = 10
+
+// runWithRetry calls the given function repeatedly until the context is cancelled, backing off
+// exponentially when it returns an error. The name identifies the loop in logs and telemetry.
+//
+// A "retry_storm" scout event is reported once when the number of consecutive failures reaches
+// retryStormThreshold. The failure count is reset when the function returns without error. The backoff
+// uses the clock of the context.
+func runWithRetry(ctx context.Context, name string, f func(context.Context) error) error {
 	backoff := 100 * time.Millisecond
+	retries := 0
 	for ctx.Err() == nil {
-		if err := f(ctx); err != nil {
-			dlog.Error(ctx, err)
-			dtime.SleepWithContext(ctx, backoff)
-			backoff *= 2
-			if backoff > 3*time.Second {
-				backoff = 3 * time.Second
-			}
+		err := f(ctx)
+		if err == nil {
+			retries = 0
+			continue
+		}
+		dlog.Error(ctx, err)
+		retries++
+		if retries == retryStormThreshold {
+			scout.Report(ctx, "retry_storm",
+				scout.Entry{
+					Key:   "loop",
+					Value: name,
+				}, scout.Entry{
+					Key:   "retries",
+					Value: retries,
+				}, scout.Entry{
+					Key:   "error_category",
+					Value: common.Result_ErrorCategory(errcat.GetCategory(err)).String(),
+				}, scout.Entry{
+					Key:   "error_code",
+					Value: status.Code(err).String(),
+				})
+		}
+		client.SleepWithContext(ctx, backoff)
+		backoff *= 2
+		if backoff > 3*time.Second {
+			backoff = 3 * time.Second
 		}
 	}
 	return nil
@@ -572,40 +894,86 @@ func (s *session) SessionInfo() *manager.SessionInfo {
 	return s.sessionInfo
 }
 
+// ApplyConfig applies the current configuration to the session. The mapped namespaces are reapplied when the
+// cluster.mappedNamespaces of the configuration have changed since they were last applied.
 func (s *session) ApplyConfig(ctx context.Context) error {
 	err := client.ReloadDaemonLogLevel(ctx, false)
 	if err != nil {
 		return err
 	}
-	if len(s.MappedNamespaces) == 0 {
-		mns := client.GetConfig(ctx).Cluster().MappedNamespaces
-		if len(mns) > 0 {
+	s.configLock.Lock()
+	defer s.configLock.Unlock()
+	mns := sortedNamespaces(client.GetConfig(ctx).Cluster().MappedNamespaces)
+	if slices.Equal(mns, s.configMappedNamespaces) {
+		if len(mns) > 0 && len(s.GetMappedNamespaces()) == 0 {
 			s.SetMappedNamespaces(ctx, mns)
 		}
+		return nil
 	}
-	return nil
+	s.configMappedNamespaces = mns
+	_, _, err = s.ReapplyMappedNamespaces(ctx)
+	return err
+}
+
+func sortedNamespaces(namespaces []string) []string {
+	namespaces = slices.Clone(namespaces)
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// ReapplyMappedNamespaces replaces the mapped namespaces of the session with the cluster.mappedNamespaces of the
+// current configuration, so that an edited configuration takes effect without a reconnect. Workload watchers are
+// started for the namespaces that were added, the DNS domains of the root daemon are updated, and the cached
+// ingress info is cleared. The namespaces that were added to and removed from the current namespaces are returned.
+func (s *session) ReapplyMappedNamespaces(ctx context.Context) (added, removed []string, err error) {
+	namespaces := client.GetConfig(ctx).Cluster().MappedNamespaces
+	before := s.GetCurrentNamespaces(true)
+	if !s.SetMappedNamespaces(ctx, namespaces) {
+		return nil, nil, nil
+	}
+	if len(namespaces) == 0 && k8sclient.CanWatchNamespaces(ctx) {
+		s.StartNamespaceWatcher(ctx)
+	}
+	s.clearIngressInfo()
+	added, removed = namespaceChanges(before, s.GetCurrentNamespaces(true))
+	dlog.Infof(ctx, "Reapplied mapped namespaces %v, added %v, removed %v", namespaces, added, removed)
+	if len(added) > 0 {
+		s.ensureWatchers(ctx, added)
+	}
+	return added, removed, s.ResyncDNSDomains(ctx)
 }
 
 // getInfosForWorkloads returns a list of workloads found in the given namespace that fulfils the given filter criteria.
+// Only workloads of the given kinds are included, unless kinds is nil. The list is sorted by name, namespace, and kind.
+// When limit is greater than zero, at most limit entries are returned. The total number of matching workloads is
+// returned along with the list.
 func (s *session) getInfosForWorkloads(
 	namespaces []string,
 	iMap map[string][]*manager.InterceptInfo,
 	gMap map[string][]*rpc.IngestInfo,
 	sMap map[string]string,
 	filter rpc.ListRequest_Filter,
-) []*rpc.WorkloadInfo {
-	wiMap := make(map[string]*rpc.WorkloadInfo)
-	s.eachWorkload(namespaces, func(wlKind manager.WorkloadInfo_Kind, name, namespace string, info workloadInfo) {
-		kind := wlKind.String()
+	kinds []manager.WorkloadInfo_Kind,
+	limit int,
+) ([]*rpc.WorkloadInfo, int) {
+	wiMap := make(map[workloadInfoKey]*rpc.WorkloadInfo)
+	notExposed := s.getNotExposed(namespaces)
+	s.eachWorkload(namespaces, func(key workloadInfoKey, info workloadInfo) {
+		if kinds != nil && !slices.Contains(kinds, key.kind) {
+			return
+		}
+		kind := key.kind.String()
+		name, namespace := key.name, key.namespace
 		wlInfo := &rpc.WorkloadInfo{
 			Name:                 name,
 			Namespace:            namespace,
 			WorkloadResourceType: kind,
 			Uid:                  string(info.uid),
 		}
-		if info.state != workload.StateAvailable {
-			wlInfo.NotInterceptableReason = info.state.String()
+		if info.agentState == manager.WorkloadInfo_INJECTION_FAILED {
+			wlInfo.AgentInjectionFailure = info.agentStateReason
 		}
+		wlInfo.NotInterceptableCode, wlInfo.NotInterceptableReason = notInterceptable(key.kind, info.state)
 
 		var ok bool
 		filterMatch := rpc.ListRequest_EVERYTHING
@@ -618,10 +986,16 @@ func (s *session) getInfosForWorkloads(
 		if wlInfo.AgentVersion, ok = sMap[name]; !ok {
 			filterMatch &= ^rpc.ListRequest_INSTALLED_AGENTS
 		}
+		if wlInfo.NotInterceptableCode == rpc.WorkloadInfo_INTERCEPTABLE && wlInfo.AgentVersion == "" && info.agentState == manager.WorkloadInfo_NO_AGENT_UNSPECIFIED {
+			// A workload that has an agent has already been found to have ports that can be intercepted.
+			if ni, ok := notExposed[key]; ok {
+				wlInfo.NotInterceptableCode, wlInfo.NotInterceptableReason = ni.code, ni.reason
+			}
+		}
 		if filter != 0 && filter&filterMatch == 0 {
 			return
 		}
-		wiMap[fmt.Sprintf("%s:%s.%s", kind, name, namespace)] = wlInfo
+		wiMap[key] = wlInfo
 	})
 	wiz := make([]*rpc.WorkloadInfo, len(wiMap))
 	i := 0
@@ -629,13 +1003,71 @@ func (s *session) getInfosForWorkloads(
 		wiz[i] = wi
 		i++
 	}
-	sort.Slice(wiz, func(i, j int) bool { return wiz[i].Name < wiz[j].Name })
-	return wiz
+	sortWorkloadInfos(wiz)
+	total := len(wiz)
+	if limit > 0 && total > limit {
+		wiz = wiz[:limit]
+	}
+	return wiz, total
+}
+
+// notInterceptable returns a code and a reason that tell why a workload of the given kind and state cannot be
+// intercepted. The code is INTERCEPTABLE and the reason is empty when it can be intercepted.
+func notInterceptable(kind manager.WorkloadInfo_Kind, state workload.State) (rpc.WorkloadInfo_NotInterceptableCode, string) {
+	switch kind {
+	case manager.WorkloadInfo_DEPLOYMENT, manager.WorkloadInfo_REPLICASET, manager.WorkloadInfo_STATEFULSET, manager.WorkloadInfo_ROLLOUT:
+	default:
+		return rpc.WorkloadInfo_UNSUPPORTED_KIND, "Unsupported kind " + kind.String()
+	}
+	switch state {
+	case workload.StateAvailable:
+		return rpc.WorkloadInfo_INTERCEPTABLE, ""
+	case workload.StateProgressing:
+		return rpc.WorkloadInfo_PROGRESSING, state.String()
+	default:
+		return rpc.WorkloadInfo_NOT_AVAILABLE, state.String()
+	}
+}
+
+// setNamespaceAliases sets the NamespaceAlias of the given workloads whose namespaces have an alias. The
+// alias is only used for display, so the Namespace is retained.
+func setNamespaceAliases(wiz []*rpc.WorkloadInfo, aliases map[string]string) {
+	if len(aliases) == 0 {
+		return
+	}
+	for _, wi := range wiz {
+		wi.NamespaceAlias = aliases[wi.Namespace]
+	}
 }
 
+// sortWorkloadInfos sorts the given workloads by name, namespace, and kind, so that the order is stable
+// also when a limit is applied.
+func sortWorkloadInfos(wiz []*rpc.WorkloadInfo) {
+	sort.Slice(wiz, func(i, j int) bool {
+		a, b := wiz[i], wiz[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.WorkloadResourceType < b.WorkloadResourceType
+	})
+}
+
+// WatchWorkloads sends a snapshot of the workloads in the requested namespaces to the given stream, and then
+// sends a new snapshot each time the workloads change. Change notifications are coalesced, so a snapshot
+// always reflects the latest known state but a stream may not see every intermediate state.
+//
+// The SyncedNamespaces of a snapshot lists the namespaces whose initial synchronization completed since the
+// previous snapshot was sent. Each namespace is reported once per stream, and the snapshot that reports it
+// is guaranteed to contain the complete set of workloads for that namespace.
+//
+// When the request asks for deltas, each snapshot after the first one contains only the workloads that were
+// added or changed, and lists the workloads that were removed. Deltas that carry no change aren't sent.
 func (s *session) WatchWorkloads(c context.Context, wr *rpc.WatchWorkloadsRequest, stream userd.WatchWorkloadsStream) error {
 	id := uuid.New()
-	ch := make(chan struct{})
+	ch := make(chan struct{}, 1)
 	s.workloadsLock.Lock()
 	if s.workloadSubscribers == nil {
 		s.workloadSubscribers = make(map[uuid.UUID]chan struct{})
@@ -649,11 +1081,27 @@ func (s *session) WatchWorkloads(c context.Context, wr *rpc.WatchWorkloadsReques
 		s.workloadsLock.Unlock()
 	}()
 
+	reported := make(map[string]struct{})
+	var deltas *workloadDeltas
+	if wr.Deltas {
+		deltas = &workloadDeltas{}
+	}
 	send := func() error {
-		ws, err := s.WorkloadInfoSnapshot(c, wr.Namespaces, rpc.ListRequest_EVERYTHING)
+		// Determine the synced namespaces prior to taking the snapshot, so that the snapshot is
+		// guaranteed to include everything that was present when the sync was detected.
+		nss := s.snapshotNamespaces(wr.Namespaces, rpc.ListRequest_EVERYTHING)
+		s.ensureWatchers(c, nss)
+		synced := s.newlySyncedNamespaces(nss, reported)
+		ws, err := s.WorkloadInfoSnapshot(c, wr.Namespaces, rpc.ListRequest_EVERYTHING, wr.Kinds, 0, false)
 		if err != nil {
 			return err
 		}
+		ws.SyncedNamespaces = synced
+		if deltas != nil {
+			if ws = deltas.reduce(ws); isEmptyDelta(ws) {
+				return nil
+			}
+		}
 		return stream.Send(ws)
 	}
 
@@ -676,72 +1124,296 @@ func (s *session) WatchWorkloads(c context.Context, wr *rpc.WatchWorkloadsReques
 func (s *session) ensureWatchers(ctx context.Context,
 	namespaces []string,
 ) {
-	v := s.managerVersion
+	v := s.ManagerVersion()
 	managerHasWatcherSupport := v.Major > 2 || v.Major == 2 && v.Minor > 20
+	clk := client.GetClock(ctx)
 
 	dlog.Debugf(ctx, "Ensure watchers %v", namespaces)
-	wg := sync.WaitGroup{}
-	wg.Add(len(namespaces))
+	var syncs []*sync.WaitGroup
 	for _, ns := range namespaces {
 		s.workloadsLock.Lock()
-		_, ok := s.workloads[ns]
+		if _, ok := s.syncedNamespaces[ns]; ok {
+			s.workloadsLock.Unlock()
+			continue
+		}
+		if wg, ok := s.watcherSyncs[ns]; ok {
+			// A watcher started by another caller is still running, so wait for its sync instead.
+			s.workloadsLock.Unlock()
+			syncs = append(syncs, wg)
+			continue
+		}
+		if b, ok := s.watchBackoffs[ns]; ok && clk.Now().Before(b.retryAt) {
+			// The last watcher failed, so don't restart it until its backoff has expired.
+			s.workloadsLock.Unlock()
+			dlog.Debugf(ctx, "watcher for namespace %s is retried in %s", ns, b.retryAt.Sub(clk.Now()))
+			continue
+		}
+		if cancel, ok := s.watcherCancels[ns]; ok {
+			// Release the watcher that ended without syncing before starting a new one.
+			cancel()
+		}
+		wCtx, cancel := context.WithCancel(log.WithSubsystem(ctx, log.SubsystemWorkload))
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		if s.watcherCancels == nil {
+			s.watcherCancels = make(map[string]context.CancelFunc)
+		}
+		if s.watcherSyncs == nil {
+			s.watcherSyncs = make(map[string]*sync.WaitGroup)
+		}
+		s.watcherCancels[ns] = cancel
+		s.watcherSyncs[ns] = wg
 		s.workloadsLock.Unlock()
-		if ok {
-			wg.Done()
-		} else {
-			go func() {
-				var err error
-				if managerHasWatcherSupport {
-					err = s.workloadsWatcher(ctx, ns, &wg)
-				} else {
-					err = s.localWorkloadsWatcher(ctx, ns, &wg)
-				}
-				if err != nil {
-					dlog.Errorf(ctx, "error ensuring watcher for namespace %s: %v", ns, err)
-					return
+		syncs = append(syncs, wg)
+
+		go func() {
+			var err error
+			if managerHasWatcherSupport {
+				synced := wg
+				for {
+					// Watch again when the connection to the traffic-manager could be reestablished.
+					mc := s.ManagerClient()
+					err = s.workloadsWatcher(wCtx, ns, synced)
+					if wCtx.Err() != nil || !managerUnavailable(err) || s.reconnectManager(wCtx, mc) != nil {
+						break
+					}
+					synced = nil
 				}
-			}()
-			dlog.Debugf(ctx, "watcher for namespace %s started", ns)
-		}
+			} else {
+				err = s.localWorkloadsWatcher(wCtx, ns, wg)
+			}
+			s.workloadsLock.Lock()
+			if s.watcherSyncs[ns] == wg {
+				delete(s.watcherSyncs, ns)
+			}
+			s.workloadsLock.Unlock()
+			if wCtx.Err() != nil {
+				// The watcher was stopped.
+				err = nil
+			}
+			s.setWatchError(ns, err)
+			if err != nil {
+				delay := s.watcherFailed(clk, ns)
+				dlog.Errorf(ctx, "error ensuring watcher for namespace %s, retrying in %s: %v", ns, delay, err)
+				s.scheduleWatcherRetry(ctx, wCtx, clk, ns, delay)
+				return
+			}
+			s.resetWatcherBackoff(ns)
+		}()
+		dlog.Debugf(ctx, "watcher for namespace %s started", ns)
+	}
+	for _, wg := range syncs {
+		wg.Wait()
 	}
-	wg.Wait()
 	dlog.Debugf(ctx, "watchers for %q synced", namespaces)
 }
 
+// prewarmWatchers starts the workload watchers of all mapped namespaces when workloads.prewarm is enabled,
+// so that the first list of workloads doesn't have to wait for them to sync.
+func (s *session) prewarmWatchers(ctx context.Context) error {
+	if !client.GetConfig(ctx).Workloads().Prewarm {
+		return nil
+	}
+	nss := s.GetCurrentNamespaces(true)
+	if len(nss) == 0 {
+		return nil
+	}
+	s.ensureWatchers(ctx, nss)
+	dlog.Debugf(ctx, "prewarmed workload watchers for %v", nss)
+	return nil
+}
+
+// IsNamespaceWatched returns true if a workload watcher for the given namespace has delivered its initial
+// sync, which means that workload info for the namespace is available.
+func (s *session) IsNamespaceWatched(namespace string) bool {
+	s.workloadsLock.Lock()
+	_, ok := s.syncedNamespaces[namespace]
+	s.workloadsLock.Unlock()
+	return ok
+}
+
+// NamespaceWatchError returns the error that ended the last workload watcher for the given namespace, or
+// nil if no watcher has ended with an error.
+func (s *session) NamespaceWatchError(namespace string) error {
+	s.workloadsLock.Lock()
+	err := s.watchErrors[namespace]
+	s.workloadsLock.Unlock()
+	return err
+}
+
+func (s *session) setWatchError(namespace string, err error) {
+	s.workloadsLock.Lock()
+	if err == nil {
+		delete(s.watchErrors, namespace)
+	} else {
+		if s.watchErrors == nil {
+			s.watchErrors = make(map[string]error)
+		}
+		s.watchErrors[namespace] = err
+	}
+	s.workloadsLock.Unlock()
+}
+
+// setSyncedLocked marks the given namespace as synced and resets the backoff of its watcher. The
+// workloadsLock must be held when calling this method.
+func (s *session) setSyncedLocked(namespace string) {
+	s.syncedNamespaces[namespace] = struct{}{}
+	delete(s.watchBackoffs, namespace)
+}
+
+// pruneWorkloadNamespaces is a namespace listener that stops the workload watchers and exposure informers, and
+// discards the workloads of namespaces that are no longer mapped, e.g. because they were deleted from the cluster.
+// Namespaces that are deleted from the cluster while being explicitly mapped are dropped from the mapped
+// namespaces unless the client config says that they should be kept.
+func (s *session) pruneWorkloadNamespaces(ctx context.Context) {
+	current := s.GetCurrentNamespaces(false)
+	s.pruneExposure(current)
+	s.pruneNamespaceInformers(current)
+	gone := s.pruneWorkloads(current)
+	if len(gone) == 0 {
+		return
+	}
+	dlog.Infof(ctx, "namespaces %v are no longer mapped, their workload watchers are stopped", gone)
+	if client.GetConfig(ctx).Cluster().KeepDeletedNamespaces {
+		return
+	}
+	if mns, dropped := dropDeletedNamespaces(s.GetMappedNamespaces(), gone); len(dropped) > 0 {
+		dlog.Infof(ctx, "dropping deleted namespaces %v from the mapped namespaces", dropped)
+		s.SetMappedNamespaces(ctx, mns)
+	}
+}
+
+// autoMapNamespace adds the given namespace to the mapped namespaces when it isn't mapped and the client
+// config enables namespaces.autoMapOnIntercept, so that the workloads of an intercept or ingest in that
+// namespace are watched and its services are resolved by DNS. The namespace remains mapped for the duration
+// of the session.
+func (s *session) autoMapNamespace(ctx context.Context, namespace string) {
+	if !client.GetConfig(ctx).Namespaces().AutoMapOnIntercept {
+		return
+	}
+	mns := s.GetMappedNamespaces()
+	if len(mns) == 0 || slices.Contains(mns, namespace) {
+		// All namespaces are mapped when no namespaces are mapped explicitly.
+		return
+	}
+	dlog.Infof(ctx, "namespace %s is not mapped, it is mapped for the duration of the session", namespace)
+	s.SetMappedNamespaces(ctx, append(mns, namespace))
+	s.ensureWatchers(ctx, []string{namespace})
+}
+
+// pruneWorkloads stops the watchers and removes the workloads of all namespaces that aren't in the given
+// list of current namespaces, and notifies the workload subscribers. The pruned namespaces are returned.
+func (s *session) pruneWorkloads(current []string) (gone []string) {
+	s.workloadsLock.Lock()
+	defer s.workloadsLock.Unlock()
+	for ns, cancel := range s.watcherCancels {
+		if !slices.Contains(current, ns) {
+			cancel()
+			delete(s.watcherCancels, ns)
+			delete(s.watcherSyncs, ns)
+			delete(s.watchErrors, ns)
+			delete(s.watchBackoffs, ns)
+			gone = append(gone, ns)
+		}
+	}
+	for ns := range s.syncedNamespaces {
+		if !slices.Contains(current, ns) {
+			delete(s.syncedNamespaces, ns)
+			delete(s.watchErrors, ns)
+			delete(s.watchBackoffs, ns)
+			if !slices.Contains(gone, ns) {
+				gone = append(gone, ns)
+			}
+		}
+	}
+	if len(gone) == 0 {
+		return nil
+	}
+	for key := range s.workloads {
+		if slices.Contains(gone, key.namespace) {
+			delete(s.workloads, key)
+		}
+	}
+	s.notifyWorkloadSubscribersLocked()
+	sort.Strings(gone)
+	return gone
+}
+
+// dropDeletedNamespaces returns the given mapped namespaces without the given deleted namespaces, along
+// with the namespaces that were dropped. The last mapped namespace is never dropped, because an empty
+// list of mapped namespaces means that all namespaces are mapped.
+func dropDeletedNamespaces(mapped, deleted []string) (remaining, dropped []string) {
+	remaining = make([]string, 0, len(mapped))
+	for _, ns := range mapped {
+		if slices.Contains(deleted, ns) {
+			dropped = append(dropped, ns)
+		} else {
+			remaining = append(remaining, ns)
+		}
+	}
+	if len(remaining) == 0 {
+		return mapped, nil
+	}
+	return remaining, dropped
+}
+
+// newlySyncedNamespaces returns the namespaces among the given ones that have completed their initial
+// sync and that aren't yet present in the reported set. The returned namespaces are added to that set.
+func (s *session) newlySyncedNamespaces(namespaces []string, reported map[string]struct{}) (synced []string) {
+	s.workloadsLock.Lock()
+	for _, ns := range namespaces {
+		if _, ok := reported[ns]; ok {
+			continue
+		}
+		// A namespace is added to the syncedNamespaces set when its watcher delivers the first event.
+		if _, ok := s.syncedNamespaces[ns]; ok {
+			reported[ns] = struct{}{}
+			synced = append(synced, ns)
+		}
+	}
+	s.workloadsLock.Unlock()
+	return synced
+}
+
+// snapshotNamespaces returns the namespaces that a WorkloadInfoSnapshot will consider for the given
+// namespaces and filter.
+func (s *session) snapshotNamespaces(namespaces []string, filter rpc.ListRequest_Filter) []string {
+	if filter&(rpc.ListRequest_INTERCEPTS|rpc.ListRequest_INGESTS|rpc.ListRequest_INSTALLED_AGENTS) != 0 {
+		// Special case, we don't care about namespaces in general. Instead, we use the connected namespace
+		return []string{s.Namespace}
+	}
+	nss := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		ns = s.ActualNamespace(ns)
+		if ns != "" {
+			nss = append(nss, ns)
+		}
+	}
+	return nss
+}
+
 func (s *session) WorkloadInfoSnapshot(
 	ctx context.Context,
 	namespaces []string,
 	filter rpc.ListRequest_Filter,
+	kinds []string,
+	limit int,
+	includePods bool,
 ) (*rpc.WorkloadInfoSnapshot, error) {
 	is := s.getCurrentIntercepts()
 
-	var nss []string
 	var sMap map[string]string
-	if filter&(rpc.ListRequest_INTERCEPTS|rpc.ListRequest_INGESTS|rpc.ListRequest_INSTALLED_AGENTS) != 0 {
-		// Special case, we don't care about namespaces in general. Instead, we use the connected namespace
-		nss = []string{s.Namespace}
-	} else {
-		nss = make([]string, 0, len(namespaces))
-		for _, ns := range namespaces {
-			ns = s.ActualNamespace(ns)
-			if ns != "" {
-				nss = append(nss, ns)
-			}
-		}
-	}
+	nss := s.snapshotNamespaces(namespaces, filter)
 	if len(nss) == 0 {
 		// none of the namespaces are currently mapped
 		dlog.Debug(ctx, "No namespaces are mapped")
 		return &rpc.WorkloadInfoSnapshot{}, nil
 	}
 	if len(nss) == 1 && nss[0] == s.Namespace {
-		cas := s.getCurrentAgents()
-		sMap = make(map[string]string, len(cas))
-		for _, a := range cas {
-			sMap[a.Name] = a.Version
-		}
+		sMap = s.getAgentVersions(nss[0])
 	}
 	s.ensureWatchers(ctx, nss)
+	s.refreshExposure(ctx, nss)
 	iMap := make(map[string][]*manager.InterceptInfo, len(is))
 nextIs:
 	for _, i := range is {
@@ -758,20 +1430,59 @@ nextIs:
 		return true
 	})
 
-	workloadInfos := s.getInfosForWorkloads(nss, iMap, gMap, sMap, filter)
-	return &rpc.WorkloadInfoSnapshot{Workloads: workloadInfos}, nil
+	workloadInfos, total := s.getInfosForWorkloads(nss, iMap, gMap, sMap, filter, listKinds(ctx, kinds), limit)
+	setNamespaceAliases(workloadInfos, client.GetConfig(ctx).Namespaces().Aliases)
+	var podErrors map[string]string
+	if includePods {
+		podErrors = s.setPodInfos(ctx, workloadInfos)
+	}
+	return &rpc.WorkloadInfoSnapshot{
+		Workloads:      workloadInfos,
+		Truncated:      len(workloadInfos) < total,
+		Total:          int32(total),
+		ExposureErrors: s.getExposureErrors(nss),
+		PodErrors:      podErrors,
+	}, nil
 }
 
 var ErrSessionExpired = errors.New("session expired")
 
+// ErrSessionMaxLifetime is returned by RunSession when the session was ended because it had been alive for the
+// duration of the session.maxLifetime config.
+var ErrSessionMaxLifetime = errors.New("session reached max lifetime")
+
+// maxLifetimeLoop ends the session once it has been alive for the duration of the session.maxLifetime config,
+// using the clock of the context. It returns right away when no max lifetime is configured.
+func (s *session) maxLifetimeLoop(ctx context.Context) error {
+	maxLifetime := client.GetConfig(ctx).Session().MaxLifetime
+	if maxLifetime <= 0 {
+		return nil
+	}
+	timer := client.GetClock(ctx).NewTimer(maxLifetime)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-timer.C():
+		dlog.Infof(ctx, "Ending session because it has been alive for %s", maxLifetime)
+		scout.Report(ctx, "session_max_lifetime", scout.Entry{Key: "max_lifetime", Value: maxLifetime.String()})
+		return ErrSessionMaxLifetime
+	}
+}
+
+// remainInterval is the interval between the calls to Remain that keep the session alive.
+const remainInterval = 5 * time.Second
+
+// remainLoop tells the traffic-manager that the session is still alive every remainInterval, using the
+// clock of the context, and departs from the traffic-manager when the context is cancelled.
 func (s *session) remainLoop(c context.Context) error {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := client.GetClock(c).NewTicker(remainInterval)
 	defer func() {
 		ticker.Stop()
 		c = dcontext.WithoutCancel(c)
 		c, cancel := context.WithTimeout(c, 3*time.Second)
 		defer cancel()
-		if _, err := s.managerClient.Depart(c, s.SessionInfo()); err != nil {
+		if _, err := s.ManagerClient().Depart(c, s.SessionInfo()); err != nil {
 			dlog.Errorf(c, "failed to depart from manager: %v", err)
 		} else {
 			// Depart succeeded so the traffic-manager has dropped the session. We should too
@@ -779,14 +1490,16 @@ func (s *session) remainLoop(c context.Context) error {
 				dlog.Errorf(c, "failed to delete session from user cache: %v", err)
 			}
 		}
-		s.managerConn.Close()
+		if conn := s.ManagerConn(); conn != nil {
+			conn.Close()
+		}
 	}()
 
 	for {
 		select {
 		case <-c.Done():
 			return nil
-		case <-ticker.C:
+		case <-ticker.C():
 			if err := s.self.Remain(c); err != nil {
 				return err
 			}
@@ -794,6 +1507,22 @@ func (s *session) remainLoop(c context.Context) error {
 	}
 }
 
+// environmentEqual returns true if the environment of this process is consistent with the given environment
+// of a connect request. A key prefixed with '-' means that the variable with the name that follows the '-'
+// must be unset. All other keys must be set to the given value.
+func environmentEqual(env map[string]string) bool {
+	for k, v := range env {
+		if name, ok := strings.CutPrefix(k, "-"); ok {
+			if _, ok := os.LookupEnv(name); ok {
+				return false
+			}
+		} else if ov, ok := os.LookupEnv(k); !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *session) UpdateStatus(c context.Context, cri userd.ConnectRequest) *rpc.ConnectInfo {
 	cr := cri.Request()
 	c, config, err := client.DaemonKubeconfig(c, cr)
@@ -802,21 +1531,7 @@ func (s *session) UpdateStatus(c context.Context, cri userd.ConnectRequest) *rpc
 	}
 
 	if !cr.IsPodDaemon {
-		envEQ := true
-		for k, v := range cr.Environment {
-			if k[0] == '-' {
-				if _, ok := os.LookupEnv(k[:1]); ok {
-					envEQ = false
-					break
-				}
-			} else {
-				if ov, ok := os.LookupEnv(k); !ok || ov != v {
-					envEQ = false
-					break
-				}
-			}
-		}
-		if !(envEQ && s.Kubeconfig.ContextServiceAndFlagsEqual(config)) {
+		if !(environmentEqual(cr.Environment) && s.Kubeconfig.ContextServiceAndFlagsEqual(config)) {
 			return &rpc.ConnectInfo{
 				Error:            rpc.ConnectInfo_MUST_RESTART,
 				ClusterContext:   s.Kubeconfig.Context,
@@ -834,16 +1549,36 @@ func (s *session) UpdateStatus(c context.Context, cri userd.ConnectRequest) *rpc
 		namespaces = client.GetConfig(c).Cluster().MappedNamespaces
 	}
 
+	var added, removed []string
+	before := s.GetCurrentNamespaces(true)
 	if s.SetMappedNamespaces(c, namespaces) {
 		if len(namespaces) == 0 && k8sclient.CanWatchNamespaces(c) {
 			s.StartNamespaceWatcher(c)
 		}
-		s.currentInterceptsLock.Lock()
-		s.ingressInfo = nil
-		s.currentInterceptsLock.Unlock()
+		s.clearIngressInfo()
+		added, removed = namespaceChanges(before, s.GetCurrentNamespaces(true))
 	}
 	s.subnetViaWorkloads = cr.SubnetViaWorkloads
-	return s.Status(c)
+	ci := s.Status(c)
+	ci.AddedNamespaces = added
+	ci.RemovedNamespaces = removed
+	return ci
+}
+
+// namespaceChanges returns the namespaces that are in after but not in before, and the namespaces that are
+// in before but not in after. Both arguments must be sorted.
+func namespaceChanges(before, after []string) (added, removed []string) {
+	for _, ns := range after {
+		if _, found := slices.BinarySearch(before, ns); !found {
+			added = append(added, ns)
+		}
+	}
+	for _, ns := range before {
+		if _, found := slices.BinarySearch(after, ns); !found {
+			removed = append(removed, ns)
+		}
+	}
+	return added, removed
 }
 
 func (s *session) Status(c context.Context) *rpc.ConnectInfo {
@@ -853,21 +1588,28 @@ func (s *session) Status(c context.Context) *rpc.ConnectInfo {
 func (s *session) status(c context.Context, initial bool) *rpc.ConnectInfo {
 	cfg := s.Kubeconfig
 	ret := &rpc.ConnectInfo{
-		ClusterContext:   cfg.Context,
-		ClusterServer:    cfg.Server,
-		ManagerInstallId: s.GetManagerInstallId(c),
-		SessionInfo:      s.SessionInfo(),
-		ConnectionName:   s.daemonID.Name,
-		KubeFlags:        s.OriginalFlagMap,
-		Namespace:        s.Namespace,
-		Ingests:          s.getCurrentIngests(),
-		Intercepts:       &manager.InterceptInfoSnapshot{Intercepts: s.getCurrentInterceptInfos()},
+		ClusterContext:        cfg.Context,
+		ClusterServer:         cfg.Server,
+		ManagerInstallId:      s.GetManagerInstallId(c),
+		SessionInfo:           s.SessionInfo(),
+		ConnectionName:        s.daemonID.Name,
+		KubeFlags:             s.OriginalFlagMap,
+		Namespace:             s.Namespace,
+		Ingests:               s.getCurrentIngests(),
+		Intercepts:            &manager.InterceptInfoSnapshot{Intercepts: s.getCurrentInterceptInfos()},
+		InterceptGroups:       s.getInterceptGroups(),
+		IdleRemovedIntercepts: s.getIdleRemoved(),
+		DrainingIntercepts:    s.getDraining(),
+		WatcherRetries:        s.getWatcherRetries(),
 		ManagerVersion: &manager.VersionInfo2{
-			Name:    s.managerName,
-			Version: "v" + s.managerVersion.String(),
+			Name:    s.ManagerName(),
+			Version: "v" + s.ManagerVersion().String(),
 		},
 		ManagerNamespace:   k8s.GetManagerNamespace(c),
 		SubnetViaWorkloads: s.subnetViaWorkloads,
+		IngressInfo:        s.ingressInfoStatus(c),
+		VersionWarning:     s.versionWarning,
+		Metadata:           s.metadata,
 		Version: &common.VersionInfo{
 			ApiVersion: client.APIVersion,
 			Version:    client.Version(),
@@ -894,22 +1636,9 @@ func (s *session) status(c context.Context, initial bool) *rpc.ConnectInfo {
 // Uninstalling all or specific agents require that the client can get and update the agents ConfigMap.
 func (s *session) Uninstall(ctx context.Context, ur *rpc.UninstallRequest) (*common.Result, error) {
 	api := k8sapi.GetK8sInterface(ctx).CoreV1()
-	loadAgentConfigMap := func(ns string) (*core.ConfigMap, error) {
-		cm, err := api.ConfigMaps(ns).Get(ctx, agentconfig.ConfigMap, meta.GetOptions{})
-		if err != nil {
-			if k8serrors.IsNotFound(err) {
-				// there are no agents to remove
-				return nil, nil
-			}
-			// TODO: find out if this is due to lack of access credentials and if so, report using errcat.User with more meaningful message
-			return nil, err
-		}
-		return cm, nil
-	}
-
 	updateAgentConfigMap := func(ns string, cm *core.ConfigMap) error {
 		_, err := api.ConfigMaps(ns).Update(ctx, cm, meta.UpdateOptions{})
-		return err
+		return agentConfigMapAccessError(err, "update", cm.Name, ns)
 	}
 
 	// Removal of agents requested. We need the agents ConfigMap in order to do that.
@@ -925,7 +1654,12 @@ func (s *session) Uninstall(ctx context.Context, ur *rpc.UninstallRequest) (*com
 			// namespace is not mapped
 			return errcat.ToResult(errcat.User.Newf("namespace %s is not mapped", ur.Namespace)), nil
 		}
-		cm, err := loadAgentConfigMap(namespace)
+		if !ur.Force {
+			if err := s.ensureNoForeignIntercepts(ctx, namespace, ur.Agents); err != nil {
+				return errcat.ToResult(err), nil
+			}
+		}
+		cm, err := loadAgentConfigMap(ctx, namespace)
 		if err != nil || cm == nil {
 			return errcat.ToResult(err), nil
 		}
@@ -934,7 +1668,7 @@ func (s *session) Uninstall(ctx context.Context, ur *rpc.UninstallRequest) (*com
 		for _, an := range ur.Agents {
 			for _, ic := range ics {
 				if ic.Spec.Namespace == namespace && ic.Spec.Agent == an {
-					_ = s.removeIntercept(ctx, ic)
+					_ = s.removeIntercept(ctx, ic, endReasonUninstall)
 					break
 				}
 			}
@@ -953,55 +1687,110 @@ func (s *session) Uninstall(ctx context.Context, ur *rpc.UninstallRequest) (*com
 	}
 
 	_ = s.ClearIngestsAndIntercepts(ctx)
-	clearAgentsConfigMap := func(ns string) error {
-		cm, err := loadAgentConfigMap(ns)
-		if err != nil {
-			return err
-		}
-		if cm == nil {
-			return nil
-		}
-		if len(cm.Data) > 0 {
-			cm.Data = nil
-			return updateAgentConfigMap(ns, cm)
-		}
-		return nil
+	clearAgentsConfigMap := func(ctx context.Context, ns string) (int, error) {
+		return s.clearAgentsConfigMap(ctx, ns, ur.Force)
 	}
 
+	var namespaces []string
 	if ur.Namespace != "" {
-		if ur.Namespace == "" {
-			ur.Namespace = s.Namespace
-		}
 		namespace := s.ActualNamespace(ur.Namespace)
 		if namespace == "" {
 			// namespace is not mapped
 			return errcat.ToResult(errcat.User.Newf("namespace %s is not mapped", ur.Namespace)), nil
 		}
-		return errcat.ToResult(clearAgentsConfigMap(namespace)), nil
+		namespaces = []string{namespace}
 	} else {
-		// Load all effected configmaps
-		for _, ns := range s.GetCurrentNamespaces(true) {
-			err := clearAgentsConfigMap(ns)
-			if err != nil {
-				return errcat.ToResult(err), nil
+		// Clear all effected configmaps. A failure in one namespace will not prevent the others from being cleared.
+		namespaces = s.GetCurrentNamespaces(true)
+	}
+	return namespaceClearsResult(clearNamespaces(ctx, namespaces, maxConcurrentNamespaceClears, clearAgentsConfigMap)), nil
+}
+
+// clearAgentsConfigMap removes all agents from the agents ConfigMap of the given namespace and returns the
+// number of agents that were removed. Unless forced, nothing is removed when other clients are intercepting
+// any of the agents.
+func (s *session) clearAgentsConfigMap(ctx context.Context, ns string, force bool) (int, error) {
+	cm, err := loadAgentConfigMap(ctx, ns)
+	if err != nil || cm == nil {
+		return 0, err
+	}
+	removed := len(cm.Data)
+	if removed == 0 {
+		return 0, nil
+	}
+	if !force {
+		agents := slices.Sorted(maps.Keys(cm.Data))
+		if err = s.ensureNoForeignIntercepts(ctx, ns, agents); err != nil {
+			return 0, err
+		}
+	}
+	cm.Data = nil
+	if _, err = k8sapi.GetK8sInterface(ctx).CoreV1().ConfigMaps(ns).Update(ctx, cm, meta.UpdateOptions{}); err != nil {
+		return 0, agentConfigMapAccessError(err, "update", cm.Name, ns)
+	}
+	return removed, nil
+}
+
+// loadAgentConfigMap loads the agents ConfigMap of the given namespace. A nil ConfigMap is returned when it
+// doesn't exist, because that means that there are no agents to remove, unless the ConfigMap name has been
+// explicitly configured, in which case a missing ConfigMap is a configuration error.
+func loadAgentConfigMap(ctx context.Context, ns string) (*core.ConfigMap, error) {
+	cmName := agentConfigMapName(ctx)
+	cm, err := k8sapi.GetK8sInterface(ctx).CoreV1().ConfigMaps(ns).Get(ctx, cmName, meta.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			if client.GetConfig(ctx).Cluster().AgentConfigMap != "" {
+				return nil, errcat.User.Newf("the configured cluster.agentConfigMap %s.%s does not exist", cmName, ns)
 			}
+			// there are no agents to remove
+			dlog.Debugf(ctx, "ConfigMap %s.%s not found, no agents to remove", cmName, ns)
+			return nil, nil
 		}
+		return nil, agentConfigMapAccessError(err, "get", cmName, ns)
+	}
+	return cm, nil
+}
+
+// agentConfigMapAccessError returns an error that explains what RBAC permissions the user lacks when the given
+// error means that the given verb was forbidden on the agents ConfigMap. Other errors are returned as is.
+func agentConfigMapAccessError(err error, verb, cmName, ns string) error {
+	if !k8serrors.IsForbidden(err) {
+		return err
 	}
-	return errcat.ToResult(nil), nil
+	return errcat.User.Newf(
+		"you are not allowed to %s the agents ConfigMap %s.%s. Removing traffic-agents requires a Role in namespace %s "+
+			"that grants the verbs \"get\" and \"update\" on the resource \"configmaps\" with resource name %q: %w",
+		verb, cmName, ns, ns, cmName, err)
 }
 
-func (s *session) getNetworkInfo(ctx context.Context, cr *rpc.ConnectRequest) *rootdRpc.NetworkConfig {
+// agentConfigMapName returns the name of the ConfigMap that holds the traffic-agent configurations. The name
+// can be set in the client configuration, which includes the configuration reported by the traffic-manager.
+func agentConfigMapName(ctx context.Context) string {
+	if name := client.GetConfig(ctx).Cluster().AgentConfigMap; name != "" {
+		return name
+	}
+	return agentconfig.ConfigMap
+}
+
+func (s *session) getNetworkInfo(ctx context.Context, cr *rpc.ConnectRequest) (*rootdRpc.NetworkConfig, error) {
 	cfg := client.GetConfig(ctx)
 	jsonCfg, _ := client.MarshalJSON(cfg)
+	kubeFlags, kubeData := cr.KubeFlags, cr.KubeconfigData
+	if cc := cfg.Cluster(); !cc.ConnectFromRootDaemon {
+		var err error
+		if kubeFlags, kubeData, err = redactKubeconfig(ctx, kubeFlags, kubeData, cc.RootDaemonRedact); err != nil {
+			return nil, err
+		}
+	}
 	return &rootdRpc.NetworkConfig{
 		Session:            s.sessionInfo,
 		ClientConfig:       jsonCfg,
 		HomeDir:            homedir.HomeDir(),
 		Namespace:          s.Namespace,
 		SubnetViaWorkloads: s.subnetViaWorkloads,
-		KubeFlags:          cr.KubeFlags,
-		KubeconfigData:     cr.KubeconfigData,
-	}
+		KubeFlags:          kubeFlags,
+		KubeconfigData:     kubeData,
+	}, nil
 }
 
 func (s *session) connectRootDaemon(ctx context.Context, nc *rootdRpc.NetworkConfig, isPodDaemon bool) (rd rootdRpc.DaemonClient, err error) {
@@ -1010,19 +1799,23 @@ func (s *session) connectRootDaemon(ctx context.Context, nc *rootdRpc.NetworkCon
 	svc := userd.GetService(ctx)
 	if svc.RootSessionInProcess() {
 		// Just run the root session in-process.
-		_, rootSession, err := rootd.NewInProcSession(ctx, nc, s.managerClient, s.managerVersion, isPodDaemon)
+		_, rootSession, err := rootd.NewInProcSession(ctx, nc, s.ManagerClient(), s.ManagerVersion(), isPodDaemon)
 		if err != nil {
 			return nil, err
 		}
 		if err = rootSession.Start(ctx, dgroup.NewGroup(ctx, dgroup.GroupConfig{})); err != nil {
 			return nil, err
 		}
+		if err = waitForRootNetwork(ctx, rootSession); err != nil {
+			return nil, err
+		}
 		rd = rootSession
 	} else {
 		var conn *grpc.ClientConn
-		conn, err = socket.Dial(ctx, socket.RootDaemonPath(ctx), true)
+		rdPath := socket.RootDaemonPath(ctx)
+		conn, err = dialRootDaemon(ctx, rdPath)
 		if err != nil {
-			return nil, fmt.Errorf("unable open root daemon socket: %w", err)
+			return nil, err
 		}
 		defer func() {
 			if err != nil {
@@ -1030,64 +1823,171 @@ func (s *session) connectRootDaemon(ctx context.Context, nc *rootdRpc.NetworkCon
 			}
 		}()
 		rd = rootdRpc.NewDaemonClient(conn)
+		if err = s.connectRootDaemonClient(ctx, rd, nc); err != nil {
+			return nil, err
+		}
+	}
+	dlog.Debug(ctx, "Connected to root daemon")
+	return rd, nil
+}
 
-		tmTimeout := client.GetConfig(ctx).Timeouts().Get(client.TimeoutTrafficManagerConnect)
-		for attempt := 1; ; attempt++ {
-			var rootStatus *rootdRpc.DaemonStatus
-			tCtx, tCancel := context.WithTimeout(ctx, tmTimeout/2)
-			rootStatus, err = rd.Connect(tCtx, nc)
-			tCancel()
-			if err != nil {
-				return nil, fmt.Errorf("failed to connect to root daemon: %w", err)
-			}
-			oc := rootStatus.OutboundConfig
-			if oc == nil || oc.Session == nil {
-				// This is an internal error. Something is wrong with the root daemon.
-				return nil, errors.New("root daemon's OutboundConfig has no Session")
-			}
-			if oc.Session.SessionId == nc.Session.SessionId {
-				break
-			}
+// connectRootDaemonClient connects the given client of a root daemon that runs in a separate process to the
+// session described by the given network config, and waits for the root daemon to set up the network. The
+// DNS domains are posted again when the root daemon was reconnected, because it then lost the state of the
+// session.
+func (s *session) connectRootDaemonClient(ctx context.Context, rd rootdRpc.DaemonClient, nc *rootdRpc.NetworkConfig) error {
+	resync, err := connectRootSession(ctx, rd, nc)
+	if err != nil {
+		return err
+	}
+	if err = waitForRootNetwork(ctx, rd); err != nil {
+		return err
+	}
+	if resync {
+		if err = s.postDNSDomains(ctx, rd); err != nil {
+			dlog.Error(ctx, err)
+		}
+	}
+	return nil
+}
 
-			// Root daemon was running an old session. This indicates that this daemon somehow
-			// crashed without disconnecting. So let's do that now, and then reconnect...
-			if attempt == 2 {
-				// ...or not, since we've already done it.
-				return nil, errors.New("unable to reconnect to root daemon")
-			}
-			if _, err = rd.Disconnect(ctx, &empty.Empty{}); err != nil {
-				return nil, fmt.Errorf("failed to disconnect from the root daemon: %w", err)
+// rootSessionRetryDelay is the time to wait after disconnecting a root daemon that runs an old session, before
+// asking it to connect again.
+const rootSessionRetryDelay = 200 * time.Millisecond
+
+// connectRootSession connects the given root daemon client to a session described by the given
+// network config. If the root daemon is running an old session, that session is disconnected and a
+// new connect attempt is made, until the number of attempts given by the cluster.rootSessionAttempts
+// config is exhausted. The returned boolean is true when such a reconnect took place.
+func connectRootSession(ctx context.Context, rd rootdRpc.DaemonClient, nc *rootdRpc.NetworkConfig) (reconnected bool, err error) {
+	cfg := client.GetConfig(ctx)
+	tmTimeout := cfg.Timeouts().Get(client.TimeoutTrafficManagerConnect)
+	maxAttempts := max(cfg.Cluster().RootSessionAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		var rootStatus *rootdRpc.DaemonStatus
+		tCtx, tCancel := context.WithTimeout(ctx, tmTimeout/2)
+		rootStatus, err = rd.Connect(tCtx, nc)
+		tCancel()
+		if err != nil {
+			if status.Code(err) == codes.Unavailable {
+				return false, errcat.User.Newf("the root daemon is not reachable: %s. Use \"telepresence quit -s\" and connect again to restart it",
+					status.Convert(err).Message())
 			}
+			return false, errcat.Unknown.Newf("the root daemon failed to connect the session: %w", err)
+		}
+		oc := rootStatus.OutboundConfig
+		if oc == nil || oc.Session == nil {
+			// Something is wrong with the root daemon. Most likely, it is of a different version than this client.
+			return false, errcat.User.New("the root daemon didn't report a session, which indicates that its version differs from the " +
+				"version of this client. Use \"telepresence quit -s\" and connect again to restart it")
+		}
+		if oc.Session.SessionId == nc.Session.SessionId {
+			return attempt > 1, nil
+		}
+
+		// Root daemon was running an old session. This indicates that this daemon somehow
+		// crashed without disconnecting. So let's do that now, and then reconnect...
+		if attempt >= maxAttempts {
+			// ...or not, since we've already tried enough times.
+			return false, errcat.Unknown.Newf("the root daemon kept running session %s instead of session %s after %d connect attempts",
+				oc.Session.SessionId, nc.Session.SessionId, attempt)
+		}
+		dlog.Infof(ctx, "root daemon was running session %s, reconnecting (attempt %d of %d)", oc.Session.SessionId, attempt+1, maxAttempts)
+		if _, err = rd.Disconnect(ctx, &empty.Empty{}); err != nil {
+			return false, errcat.Unknown.Newf("failed to disconnect the root daemon from session %s: %w", oc.Session.SessionId, err)
+		}
+		client.SleepWithContext(ctx, rootSessionRetryDelay)
+		if err = ctx.Err(); err != nil {
+			return false, err
 		}
 	}
+}
 
-	// The root daemon needs time to set up the TUN-device and DNS, which involves interacting
-	// with the cluster-side traffic-manager. We know that the traffic-manager is up and
-	// responding at this point, so it shouldn't take too long.
-	ctx, cancel := client.GetConfig(ctx).Timeouts().TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+// rootDaemonDialError returns a categorized error for a failure to dial the root daemon socket at the given path.
+func rootDaemonDialError(path string, err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return errcat.User.Newf("the root daemon is not running (no socket found at %s). Use \"telepresence quit -s\" and connect again to start it", path)
+	}
+	return errcat.Unknown.Newf("unable to open root daemon socket %s: %w", path, err)
+}
+
+// dialRootDaemon dials the root daemon socket at the given path. The dial, including the wait for the root
+// daemon to accept the connection, is limited by the rootDaemonDial timeout, so that a root daemon that has
+// locked up can't block the connect.
+func dialRootDaemon(ctx context.Context, path string) (*grpc.ClientConn, error) {
+	tos := client.GetConfig(ctx).Timeouts()
+	tCtx, cancel := tos.TimeoutContext(ctx, client.TimeoutRootDaemonDial)
 	defer cancel()
-	if _, err = rd.WaitForNetwork(ctx, &empty.Empty{}); err != nil {
-		if se, ok := status.FromError(err); ok {
-			err = se.Err()
+	conn, err := socket.DialWithTimeout(tCtx, path, true, tos.Get(client.TimeoutRootDaemonDial))
+	if err != nil {
+		if errors.Is(tCtx.Err(), context.DeadlineExceeded) {
+			return nil, errcat.User.Newf("the root daemon didn't respond on socket %s and might be stuck. "+
+				"Use \"telepresence quit -s\" and connect again to restart it: %w", path, client.CheckTimeout(tCtx, err))
 		}
-		return nil, fmt.Errorf("failed to connect to root daemon: %v", err)
+		return nil, rootDaemonDialError(path, err)
 	}
-	dlog.Debug(ctx, "Connected to root daemon")
-	return rd, nil
+	return conn, nil
 }
 
-func (s *session) eachWorkload(namespaces []string, do func(kind manager.WorkloadInfo_Kind, name, namespace string, info workloadInfo)) {
+// waitForRootNetwork waits for the root daemon to set up the TUN-device and DNS, which involves interacting
+// with the cluster-side traffic-manager. We know that the traffic-manager is up and responding at this point,
+// so it shouldn't take too long. A failure is returned as a categorized error that tells a timeout apart from
+// a failure to create the TUN-device.
+func waitForRootNetwork(ctx context.Context, rd rootdRpc.DaemonClient) error {
+	tCtx, cancel := client.GetConfig(ctx).Timeouts().TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	_, err := rd.WaitForNetwork(tCtx, &empty.Empty{})
+	if err == nil {
+		return nil
+	}
+	if errors.Is(tCtx.Err(), context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+		return errcat.Config.Newf("the root daemon didn't set up the network in time: %w", client.CheckTimeout(tCtx, err))
+	}
+	// The root daemon reports failures to create the TUN device using dedicated status codes.
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.PermissionDenied:
+		return errcat.User.Newf("the root daemon was denied permission to create the TUN device: %s", st.Message())
+	case codes.FailedPrecondition:
+		return errcat.Unknown.Newf("the root daemon failed to create the TUN device: %s", st.Message())
+	default:
+		return errcat.Unknown.Newf("the root daemon failed to set up the network: %s", st.Message())
+	}
+}
+
+// eachWorkload calls the given function for each known workload in the given namespaces. The workloadsLock
+// is held during the calls.
+func (s *session) eachWorkload(namespaces []string, do func(key workloadInfoKey, info workloadInfo)) {
 	s.workloadsLock.Lock()
-	for _, ns := range namespaces {
-		if workloads, ok := s.workloads[ns]; ok {
-			for key, info := range workloads {
-				do(key.kind, key.name, ns, info)
-			}
+	for key, info := range s.workloads {
+		if slices.Contains(namespaces, key.namespace) {
+			do(key, info)
 		}
 	}
 	s.workloadsLock.Unlock()
 }
 
+// listKinds returns the kinds of the workloads to list. The given kinds are used unless they're empty, in
+// which case the list.defaultKinds of the client configuration is used. All kinds except ReplicaSet are listed
+// when both are empty.
+func listKinds(ctx context.Context, kinds []string) []manager.WorkloadInfo_Kind {
+	if len(kinds) == 0 {
+		kinds = client.GetConfig(ctx).List().DefaultKinds
+		if len(kinds) == 0 {
+			return []manager.WorkloadInfo_Kind{manager.WorkloadInfo_DEPLOYMENT, manager.WorkloadInfo_STATEFULSET, manager.WorkloadInfo_ROLLOUT}
+		}
+	}
+	wks := make([]manager.WorkloadInfo_Kind, 0, len(kinds))
+	for _, k := range kinds {
+		if wk := rpcKind(k); wk != manager.WorkloadInfo_UNSPECIFIED {
+			wks = append(wks, wk)
+		} else {
+			dlog.Warnf(ctx, "ignoring unknown workload kind %q", k)
+		}
+	}
+	return wks
+}
+
 func rpcKind(s string) manager.WorkloadInfo_Kind {
 	switch strings.ToLower(s) {
 	case "deployment":
@@ -1111,7 +2011,7 @@ func (s *session) localWorkloadsWatcher(ctx context.Context, namespace string, s
 		dlog.Debug(ctx, "client workload watcher ended")
 	}()
 
-	knownWorkloadKinds, err := s.managerClient.GetKnownWorkloadKinds(ctx, s.sessionInfo)
+	knownWorkloadKinds, err := s.ManagerClient().GetKnownWorkloadKinds(ctx, s.sessionInfo)
 	if err != nil {
 		if status.Code(err) != codes.Unimplemented {
 			return fmt.Errorf("failed to get known workload kinds: %w", err)
@@ -1124,27 +2024,23 @@ func (s *session) localWorkloadsWatcher(ctx context.Context, namespace string, s
 		}}
 	}
 
-	dlog.Debugf(ctx, "Watching workloads from client due to lack of workload watcher support in traffic-manager %s", s.managerVersion)
+	dlog.Debugf(ctx, "Watching workloads from client due to lack of workload watcher support in traffic-manager %s", s.ManagerVersion())
 	fc := informer.GetFactory(ctx, namespace)
 	if fc == nil {
-		ctx = informer.WithFactory(ctx, namespace)
+		ctx = informer.WithResyncingFactory(ctx, namespace, client.GetConfig(ctx).Workloads().ResyncPeriod)
 		fc = informer.GetFactory(ctx, namespace)
 	}
 
-	enabledWorkloadKinds := make([]workload.Kind, len(knownWorkloadKinds.Kinds))
-	for i, kind := range knownWorkloadKinds.Kinds {
+	enabledWorkloadKinds := s.enabledWorkloadKinds(ctx, knownWorkloadKinds.Kinds)
+	for _, kind := range enabledWorkloadKinds {
 		switch kind {
-		case manager.WorkloadInfo_DEPLOYMENT:
-			enabledWorkloadKinds[i] = workload.DeploymentKind
+		case workload.DeploymentKind:
 			workload.StartDeployments(ctx, namespace)
-		case manager.WorkloadInfo_REPLICASET:
-			enabledWorkloadKinds[i] = workload.ReplicaSetKind
+		case workload.ReplicaSetKind:
 			workload.StartReplicaSets(ctx, namespace)
-		case manager.WorkloadInfo_STATEFULSET:
-			enabledWorkloadKinds[i] = workload.StatefulSetKind
+		case workload.StatefulSetKind:
 			workload.StartStatefulSets(ctx, namespace)
-		case manager.WorkloadInfo_ROLLOUT:
-			enabledWorkloadKinds[i] = workload.RolloutKind
+		case workload.RolloutKind:
 			workload.StartRollouts(ctx, namespace)
 			af := fc.GetArgoRolloutsInformerFactory()
 			af.Start(ctx.Done())
@@ -1169,29 +2065,41 @@ func (s *session) localWorkloadsWatcher(ctx context.Context, namespace string, s
 			if wls == nil {
 				return nil
 			}
+			if len(s.workloadPrefixes) > 0 {
+				wls = slices.DeleteFunc(slices.Clone(wls), func(we workload.Event) bool { return !s.watchesWorkload(we.Workload.GetName()) })
+			}
+			// The failures are found before the lock is acquired, because finding them may require API calls.
+			failures := agentInjectionFailures(ctx, namespace, wls)
 			s.workloadsLock.Lock()
-			workloads, ok := s.workloads[namespace]
-			if !ok {
-				workloads = make(map[workloadInfoKey]workloadInfo)
-				s.workloads[namespace] = workloads
+			if ctx.Err() != nil {
+				// The watcher was stopped, and the namespace may have been pruned.
+				s.workloadsLock.Unlock()
+				return nil
 			}
-			for _, we := range wls {
+			_, wasSynced := s.syncedNamespaces[namespace]
+			s.setSyncedLocked(namespace)
+			for i, we := range wls {
 				w := we.Workload
-				key := workloadInfoKey{kind: rpcKind(w.GetKind()), name: w.GetName()}
+				key := workloadInfoKey{kind: rpcKind(w.GetKind()), namespace: namespace, name: w.GetName()}
+				s.audit.record(auditWorkloadEvent(we.Type), w.GetKind(), w.GetName(), namespace, string(w.GetUID()))
 				if we.Type == workload.EventTypeDelete {
-					delete(workloads, key)
+					delete(s.workloads, key)
+					s.emitWorkloadChanged(ctx, rpc.SessionEvent_WorkloadChanged_DELETED, key, "")
 				} else {
-					workloads[key] = workloadInfo{
+					wi := workloadInfo{
 						state: workload.GetWorkloadState(w),
 						uid:   w.GetUID(),
 					}
+					s.emitWorkloadChanged(ctx, workloadChangedType(we.Type), key, wi.state.String())
+					if failures[i] != "" {
+						wi.agentState = manager.WorkloadInfo_INJECTION_FAILED
+						wi.agentStateReason = failures[i]
+					}
+					s.workloads[key] = wi
 				}
 			}
-			for _, subscriber := range s.workloadSubscribers {
-				select {
-				case subscriber <- struct{}{}:
-				default:
-				}
+			if !wasSynced || len(wls) > 0 {
+				s.notifyWorkloadSubscribersLocked()
 			}
 			s.workloadsLock.Unlock()
 			if synced != nil {
@@ -1202,13 +2110,21 @@ func (s *session) localWorkloadsWatcher(ctx context.Context, namespace string, s
 	}
 }
 
+// watchesWorkload returns true if the workload watchers store the workload with the given name.
+func (s *session) watchesWorkload(name string) bool {
+	if len(s.workloadPrefixes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(s.workloadPrefixes, func(p string) bool { return strings.HasPrefix(name, p) })
+}
+
 func (s *session) workloadsWatcher(ctx context.Context, namespace string, synced *sync.WaitGroup) error {
 	defer func() {
 		if synced != nil {
 			synced.Done()
 		}
 	}()
-	wlc, err := s.managerClient.WatchWorkloads(ctx, &manager.WorkloadEventsRequest{SessionInfo: s.sessionInfo, Namespace: namespace})
+	wlc, err := s.ManagerClient().WatchWorkloads(ctx, &manager.WorkloadEventsRequest{SessionInfo: s.sessionInfo, Namespace: namespace})
 	if err != nil {
 		if st, ok := status.FromError(err); ok && st.Code() == codes.FailedPrecondition {
 			return errcat.User.New(st.Message())
@@ -1223,18 +2139,28 @@ func (s *session) workloadsWatcher(ctx context.Context, namespace string, synced
 		}
 
 		s.workloadsLock.Lock()
-		workloads, ok := s.workloads[namespace]
-		if !ok {
-			workloads = make(map[workloadInfoKey]workloadInfo)
-			s.workloads[namespace] = workloads
+		if ctx.Err() != nil {
+			// The watcher was stopped, and the namespace may have been pruned.
+			s.workloadsLock.Unlock()
+			return nil
 		}
+		_, wasSynced := s.syncedNamespaces[namespace]
+		s.setSyncedLocked(namespace)
 
+		// Subscribers are notified of the initial sync, and of changes to the workloads that are stored.
+		notify := !wasSynced
 		for _, we := range wls.GetEvents() {
 			w := we.Workload
-			key := workloadInfoKey{kind: w.Kind, name: w.Name}
+			if !s.watchesWorkload(w.Name) {
+				continue
+			}
+			notify = true
+			key := workloadInfoKey{kind: w.Kind, namespace: namespace, name: w.Name}
+			s.audit.record(auditRPCWorkloadEvent(we.Type), w.Kind.String(), w.Name, namespace, w.Uid)
 			if we.Type == manager.WorkloadEvent_DELETED {
 				dlog.Debugf(ctx, "Deleting workload %s/%s.%s", key.kind, key.name, namespace)
-				delete(workloads, key)
+				delete(s.workloads, key)
+				s.emitWorkloadChanged(ctx, rpc.SessionEvent_WorkloadChanged_DELETED, key, "")
 			} else {
 				var clients []string
 				if lc := len(w.InterceptClients); lc > 0 {
@@ -1244,19 +2170,18 @@ func (s *session) workloadsWatcher(ctx context.Context, namespace string, synced
 					}
 				}
 				dlog.Debugf(ctx, "Adding workload %s/%s.%s", key.kind, key.name, namespace)
-				workloads[key] = workloadInfo{
+				s.workloads[key] = workloadInfo{
 					uid:              types.UID(w.Uid),
 					state:            workload.StateFromRPC(w.State),
 					agentState:       w.AgentState,
+					agentStateReason: w.AgentStateReason,
 					interceptClients: clients,
 				}
+				s.emitWorkloadChanged(ctx, rpcWorkloadChangedType(we.Type), key, s.workloads[key].state.String())
 			}
 		}
-		for _, subscriber := range s.workloadSubscribers {
-			select {
-			case subscriber <- struct{}{}:
-			default:
-			}
+		if notify {
+			s.notifyWorkloadSubscribersLocked()
 		}
 		s.workloadsLock.Unlock()
 		if synced != nil {
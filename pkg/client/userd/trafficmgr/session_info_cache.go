@@ -2,50 +2,242 @@ package trafficmgr
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
+	"github.com/puzpuzpuz/xsync/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cache"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/dos"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
 )
 
-func sessionInfoFile(daemonID *daemon.Identifier) string {
-	return filepath.Join("sessions", daemonID.InfoFileName())
+// SessionCacheDirEnv is the name of the environment variable that overrides the directory where
+// the session info is cached.
+const SessionCacheDirEnv = "TELEPRESENCE_SESSION_CACHE_DIR"
+
+// memorySessions holds the session info that couldn't be saved to the user cache, keyed by file name.
+var memorySessions = xsync.NewMapOf[string, *SavedSession]() //nolint:gochecknoglobals // in-memory fallback cache
+
+// sessionCacheDir returns the context to use when accessing the cached session infos, and the directory
+// that holds them. The directory is relative to the user cache directory, unless it is overridden using
+// the SessionCacheDirEnv environment variable, in which case it is empty.
+func sessionCacheDir(ctx context.Context) (context.Context, string) {
+	if dir := os.Getenv(SessionCacheDirEnv); dir != "" {
+		return filelocation.WithAppUserCacheDir(ctx, dir), ""
+	}
+	return ctx, "sessions"
+}
+
+// sessionInfoFile returns the context to use when accessing the session info of the given daemon, and the
+// name of the file that holds it.
+func sessionInfoFile(ctx context.Context, daemonID *daemon.Identifier) (context.Context, string) {
+	ctx, dir := sessionCacheDir(ctx)
+	return ctx, filepath.Join(dir, daemonID.InfoFileName())
 }
 
+// SavedSession is the cached session of a daemon. The ManagerNamespace is the namespace of the traffic-manager
+// that created the session, and the Metadata is the metadata that the client arrived with. Both are empty for
+// sessions cached by older versions.
 type SavedSession struct {
-	KubeContext string               `json:"kubeContext"`
-	Namespace   string               `json:"namespace"`
-	Session     *manager.SessionInfo `json:"session"`
+	KubeContext      string               `json:"kubeContext"`
+	Namespace        string               `json:"namespace"`
+	ManagerNamespace string               `json:"managerNamespace,omitempty"`
+	Metadata         map[string]string    `json:"metadata,omitempty"`
+	Session          *manager.SessionInfo `json:"session"`
 }
 
-// SaveSessionInfoToUserCache saves the provided SessionInfo to user cache and returns an error if
-// something goes wrong while marshalling or persisting.
-func SaveSessionInfoToUserCache(ctx context.Context, daemonID *daemon.Identifier, session *manager.SessionInfo) error {
-	return cache.SaveToUserCache(ctx, &SavedSession{
-		KubeContext: daemonID.KubeContext,
-		Namespace:   daemonID.Namespace,
-		Session:     session,
-	}, sessionInfoFile(daemonID), cache.Public)
+// SaveSessionInfoToUserCache saves the provided SessionInfo, obtained from the traffic-manager in the given
+// namespace by a client with the given metadata, to user cache. If the cache directory isn't
+// writable, a warning is logged and the SessionInfo is kept in memory only. Other errors, such as a failure
+// to marshal the SessionInfo, are returned.
+func SaveSessionInfoToUserCache(
+	ctx context.Context,
+	daemonID *daemon.Identifier,
+	managerNamespace string,
+	metadata map[string]string,
+	session *manager.SessionInfo,
+) error {
+	ctx, file := sessionInfoFile(ctx, daemonID)
+	ss := &SavedSession{
+		KubeContext:      daemonID.KubeContext,
+		Namespace:        daemonID.Namespace,
+		ManagerNamespace: managerNamespace,
+		Metadata:         metadata,
+		Session:          session,
+	}
+	if err := cache.SaveToUserCache(ctx, ss, file, cache.Public); err != nil {
+		var pe *fs.PathError
+		if !errors.As(err, &pe) {
+			return err
+		}
+		dlog.Warnf(ctx, "unable to save session info to %s, keeping it in memory only: %v",
+			filepath.Join(filelocation.AppUserCacheDir(ctx), file), err)
+		memorySessions.Store(file, ss)
+		return nil
+	}
+	memorySessions.Delete(file)
+	return nil
 }
 
 // LoadSessionInfoFromUserCache gets the SessionInfo from cache or returns an error if something goes
-// wrong while loading or unmarshalling.
+// wrong while unmarshalling. A cache directory that cannot be read is treated as an empty cache.
 func LoadSessionInfoFromUserCache(ctx context.Context, daemonID *daemon.Identifier) (*manager.SessionInfo, error) {
-	var ss *SavedSession
-	err := cache.LoadFromUserCache(ctx, &ss, sessionInfoFile(daemonID))
-	if err == nil && ss.KubeContext == daemonID.KubeContext && ss.Namespace == daemonID.Namespace {
-		return ss.Session, nil
+	ss, err := loadSavedSession(ctx, daemonID)
+	if err != nil || ss == nil {
+		return nil, err
+	}
+	return ss.Session, nil
+}
+
+// loadSavedSession gets the cached session of the given daemon, or nil when no session is cached for it.
+func loadSavedSession(ctx context.Context, daemonID *daemon.Identifier) (*SavedSession, error) {
+	ctx, file := sessionInfoFile(ctx, daemonID)
+	ss, ok := memorySessions.Load(file)
+	if !ok {
+		err := cache.LoadFromUserCache(ctx, &ss, file)
+		if err != nil {
+			var pe *fs.PathError
+			switch {
+			case os.IsNotExist(err):
+				err = nil
+			case errors.As(err, &pe):
+				dlog.Warnf(ctx, "unable to load session info: %v", err)
+				err = nil
+			}
+			return nil, err
+		}
 	}
-	if err != nil && os.IsNotExist(err) {
-		err = nil
+	if ss != nil && ss.Session != nil && ss.KubeContext == daemonID.KubeContext && ss.Namespace == daemonID.Namespace {
+		return ss, nil
 	}
-	return nil, err
+	return nil, nil
 }
 
 // DeleteSessionInfoFromUserCache removes SessionInfo cache if existing or returns an error. An attempt
-// to remove a non-existing cache is a no-op and the function returns nil.
+// to remove a non-existing cache is a no-op and the function returns nil. Errors are ignored when the
+// SessionInfo was kept in memory, because the cache directory then isn't writable.
 func DeleteSessionInfoFromUserCache(ctx context.Context, daemonID *daemon.Identifier) error {
-	return cache.DeleteFromUserCache(ctx, sessionInfoFile(daemonID))
+	return deleteSessionInfoFile(sessionInfoFile(ctx, daemonID))
+}
+
+func deleteSessionInfoFile(ctx context.Context, file string) error {
+	_, inMemory := memorySessions.LoadAndDelete(file)
+	if err := cache.DeleteFromUserCache(ctx, file); err != nil && !inMemory {
+		return err
+	}
+	return nil
+}
+
+// SessionValidator validates a cached session. It returns nil when the traffic-manager knows the session,
+// and an error with a NotFound status when it doesn't. Any other error means that the session couldn't be
+// validated.
+type SessionValidator func(context.Context, *SavedSession) error
+
+// PruneSessionInfoFromUserCache validates all sessions in the user cache, including those kept in memory,
+// using the given validator. Sessions that the validator reports as not found, and files that don't contain
+// a valid session, are removed. Sessions that cannot be validated are left untouched.
+func PruneSessionInfoFromUserCache(ctx context.Context, validate SessionValidator) (*rpc.PruneSessionsResponse, error) {
+	ctx, dir := sessionCacheDir(ctx)
+	sessions := make(map[string]*SavedSession)
+	memorySessions.Range(func(file string, ss *SavedSession) bool {
+		sessions[file] = ss
+		return true
+	})
+	entries, err := dos.ReadDir(ctx, filepath.Join(filelocation.AppUserCacheDir(ctx), dir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	rsp := &rpc.PruneSessionsResponse{}
+	prune := func(cs *rpc.CachedSession) {
+		if err := deleteSessionInfoFile(ctx, cs.File); err != nil {
+			cs.Reason = fmt.Sprintf("%s, but it could not be removed: %v", cs.Reason, err)
+			rsp.Unreachable = append(rsp.Unreachable, cs)
+			return
+		}
+		rsp.Pruned = append(rsp.Pruned, cs)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		file := filepath.Join(dir, name)
+		if entry.IsDir() || filepath.Ext(name) != ".json" || sessions[file] != nil {
+			continue
+		}
+		var ss *SavedSession
+		if err := cache.LoadFromUserCache(ctx, &ss, file); err != nil {
+			cs := &rpc.CachedSession{File: file, Reason: err.Error()}
+			var pe *fs.PathError
+			if errors.As(err, &pe) {
+				rsp.Unreachable = append(rsp.Unreachable, cs)
+			} else {
+				prune(cs)
+			}
+			continue
+		}
+		sessions[file] = ss
+	}
+
+	for _, file := range slices.Sorted(maps.Keys(sessions)) {
+		ss := sessions[file]
+		cs := &rpc.CachedSession{File: file}
+		if ss == nil || ss.Session == nil {
+			cs.Reason = "no session info"
+			prune(cs)
+			continue
+		}
+		cs.KubeContext = ss.KubeContext
+		cs.Namespace = ss.Namespace
+		cs.SessionId = ss.Session.SessionId
+		switch err := validate(ctx, ss); status.Code(err) {
+		case codes.OK:
+			rsp.Valid = append(rsp.Valid, cs)
+		case codes.NotFound:
+			cs.Reason = "unknown to the traffic-manager"
+			prune(cs)
+		default:
+			cs.Reason = status.Convert(err).Message()
+			rsp.Unreachable = append(rsp.Unreachable, cs)
+		}
+	}
+	return rsp, nil
+}
+
+// PruneSessions validates the cached sessions of all daemons and removes the ones that the traffic-manager of
+// this session no longer knows. Sessions that were cached for another kube context or traffic-manager namespace
+// are left untouched, because their traffic-manager cannot be reached from this session. The sessions are
+// probed using GetAgentVersions, because unlike Remain, it doesn't extend their leases.
+func (s *session) PruneSessions(ctx context.Context) (*rpc.PruneSessionsResponse, error) {
+	return PruneSessionInfoFromUserCache(ctx, func(ctx context.Context, ss *SavedSession) error {
+		if ss.KubeContext != s.daemonID.KubeContext {
+			return status.Errorf(codes.Unavailable, "the traffic-manager of kube context %q is not connected", ss.KubeContext)
+		}
+		if ss.ManagerNamespace == "" {
+			return status.Error(codes.Unavailable, "the namespace of its traffic-manager is unknown")
+		}
+		if ss.ManagerNamespace != s.managerNamespace {
+			return status.Errorf(codes.Unavailable, "the traffic-manager in namespace %q is not connected", ss.ManagerNamespace)
+		}
+		if ss.Session.SessionId == s.SessionInfo().SessionId {
+			return nil
+		}
+		ctx, cancel := client.GetConfig(ctx).Timeouts().TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+		defer cancel()
+		_, err := s.ManagerClient().GetAgentVersions(ctx, &manager.AgentsRequest{Session: ss.Session})
+		if status.Code(err) == codes.Unimplemented {
+			return status.Errorf(codes.Unavailable, "the sessions cannot be validated by %s %s", s.ManagerName(), s.ManagerVersion())
+		}
+		return err
+	})
 }
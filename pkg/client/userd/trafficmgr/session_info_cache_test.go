@@ -0,0 +1,201 @@
+package trafficmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+func Test_SessionInfoUserCache_default(t *testing.T) {
+	cacheDir := t.TempDir()
+	ctx := filelocation.WithAppUserCacheDir(dlog.NewTestContext(t, false), cacheDir)
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	si := &manager.SessionInfo{SessionId: "default-session"}
+
+	require.NoError(t, SaveSessionInfoToUserCache(ctx, daemonID, "ambassador", nil, si))
+	assert.FileExists(t, filepath.Join(cacheDir, "sessions", daemonID.InfoFileName()))
+}
+
+func Test_SessionInfoUserCache_override(t *testing.T) {
+	cacheDir := t.TempDir()
+	sessionDir := filepath.Join(t.TempDir(), "sessions")
+	t.Setenv(SessionCacheDirEnv, sessionDir)
+	ctx := filelocation.WithAppUserCacheDir(dlog.NewTestContext(t, false), cacheDir)
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	si := &manager.SessionInfo{SessionId: "override-session"}
+
+	require.NoError(t, SaveSessionInfoToUserCache(ctx, daemonID, "ambassador", nil, si))
+	assert.FileExists(t, filepath.Join(sessionDir, daemonID.InfoFileName()))
+	assert.NoDirExists(t, filepath.Join(cacheDir, "sessions"))
+
+	loaded, err := LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, si.SessionId, loaded.SessionId)
+
+	require.NoError(t, DeleteSessionInfoFromUserCache(ctx, daemonID))
+	assert.NoFileExists(t, filepath.Join(sessionDir, daemonID.InfoFileName()))
+	loaded, err = LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func Test_SessionInfoUserCache_unwritable(t *testing.T) {
+	// A directory below a regular file can be neither created nor read, not even by root.
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+	t.Setenv(SessionCacheDirEnv, filepath.Join(blocker, "sessions"))
+	ctx := dlog.NewTestContext(t, false)
+	daemonID, err := daemon.NewIdentifier("", "ctx", "unwritable", false)
+	require.NoError(t, err)
+	si := &manager.SessionInfo{SessionId: "memory-session"}
+
+	// Without a prior save, the cache is just empty.
+	loaded, err := LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	require.NoError(t, SaveSessionInfoToUserCache(ctx, daemonID, "ambassador", nil, si))
+	loaded, err = LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, si.SessionId, loaded.SessionId)
+
+	require.NoError(t, DeleteSessionInfoFromUserCache(ctx, daemonID))
+	loaded, err = LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func saveTestSession(ctx context.Context, t *testing.T, kubeContext, namespace, managerNamespace, sessionID string) string {
+	t.Helper()
+	daemonID, err := daemon.NewIdentifier("", kubeContext, namespace, false)
+	require.NoError(t, err)
+	require.NoError(t, SaveSessionInfoToUserCache(ctx, daemonID, managerNamespace, nil, &manager.SessionInfo{SessionId: sessionID}))
+	return filepath.Join("sessions", daemonID.InfoFileName())
+}
+
+func sessionFiles(css []*rpc.CachedSession) []string {
+	files := make([]string, len(css))
+	for i, cs := range css {
+		files[i] = cs.File
+	}
+	return files
+}
+
+func Test_PruneSessionInfoFromUserCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	ctx := filelocation.WithAppUserCacheDir(dlog.NewTestContext(t, false), cacheDir)
+	valid := saveTestSession(ctx, t, "ctx", "a", "ambassador", "valid")
+	stale := saveTestSession(ctx, t, "ctx", "b", "ambassador", "stale")
+	unreachable := saveTestSession(ctx, t, "ctx", "c", "ambassador", "unreachable")
+	broken := filepath.Join("sessions", "broken.json")
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, broken), []byte("{"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "sessions", "README"), nil, 0o644))
+
+	rsp, err := PruneSessionInfoFromUserCache(ctx, func(_ context.Context, ss *SavedSession) error {
+		switch ss.Session.SessionId {
+		case "valid":
+			return nil
+		case "stale":
+			return status.Error(codes.NotFound, "session not found")
+		default:
+			return status.Error(codes.Unavailable, "connection refused")
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{valid}, sessionFiles(rsp.Valid))
+	assert.ElementsMatch(t, []string{stale, broken}, sessionFiles(rsp.Pruned))
+	assert.Equal(t, []string{unreachable}, sessionFiles(rsp.Unreachable))
+	assert.Equal(t, "connection refused", rsp.Unreachable[0].Reason)
+
+	assert.FileExists(t, filepath.Join(cacheDir, valid))
+	assert.FileExists(t, filepath.Join(cacheDir, unreachable))
+	assert.FileExists(t, filepath.Join(cacheDir, "sessions", "README"))
+	assert.NoFileExists(t, filepath.Join(cacheDir, stale))
+	assert.NoFileExists(t, filepath.Join(cacheDir, broken))
+}
+
+// agentVersionsManager is a traffic-manager that only knows the given sessions. It counts the calls to Remain,
+// which extend the leases of the sessions.
+type agentVersionsManager struct {
+	manager.ManagerClient
+	known   map[string]bool
+	remains atomic.Int32
+}
+
+func (m *agentVersionsManager) GetAgentVersions(_ context.Context, ar *manager.AgentsRequest, _ ...grpc.CallOption) (*manager.AgentVersions, error) {
+	if !m.known[ar.Session.SessionId] {
+		return nil, status.Errorf(codes.NotFound, "Client session %q not found", ar.Session.SessionId)
+	}
+	return &manager.AgentVersions{}, nil
+}
+
+func (m *agentVersionsManager) Remain(context.Context, *manager.RemainRequest, ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.remains.Add(1)
+	return &emptypb.Empty{}, nil
+}
+
+func Test_session_PruneSessions(t *testing.T) {
+	mgr := &agentVersionsManager{known: map[string]bool{"alive": true}}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{})
+	own := saveTestSession(ctx, t, "ctx", "default", "ambassador", s.SessionInfo().SessionId)
+	alive := saveTestSession(ctx, t, "ctx", "alive", "ambassador", "alive")
+	gone := saveTestSession(ctx, t, "ctx", "gone", "ambassador", "gone")
+	elsewhere := saveTestSession(ctx, t, "other", "default", "ambassador", "elsewhere")
+	otherManager := saveTestSession(ctx, t, "ctx", "other-manager", "other", "gone")
+	legacy := saveTestSession(ctx, t, "ctx", "legacy", "", "gone")
+
+	rsp, err := s.PruneSessions(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{own, alive}, sessionFiles(rsp.Valid))
+	assert.Equal(t, []string{gone}, sessionFiles(rsp.Pruned))
+	assert.ElementsMatch(t, []string{elsewhere, otherManager, legacy}, sessionFiles(rsp.Unreachable))
+
+	// Validating the sessions doesn't extend their leases.
+	assert.Equal(t, int32(0), mgr.remains.Load())
+
+	// The session of the other kube context is left untouched.
+	daemonID, err := daemon.NewIdentifier("", "other", "default", false)
+	require.NoError(t, err)
+	si, err := LoadSessionInfoFromUserCache(ctx, daemonID)
+	require.NoError(t, err)
+	require.NotNil(t, si)
+	assert.Equal(t, "elsewhere", si.SessionId)
+}
+
+type unimplementedAgentVersionsManager struct {
+	manager.ManagerClient
+}
+
+func (unimplementedAgentVersionsManager) GetAgentVersions(context.Context, *manager.AgentsRequest, ...grpc.CallOption) (*manager.AgentVersions, error) {
+	return nil, status.Error(codes.Unimplemented, "")
+}
+
+func Test_session_PruneSessions_unimplemented(t *testing.T) {
+	ctx, s, _ := newTestSession(t, unimplementedAgentVersionsManager{}, &fakeRootDaemon{})
+	other := saveTestSession(ctx, t, "ctx", "other", "ambassador", "other")
+
+	// An older traffic-manager cannot tell if it knows the session, so the session is left untouched.
+	rsp, err := s.PruneSessions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rsp.Pruned)
+	assert.Equal(t, []string{other}, sessionFiles(rsp.Unreachable))
+}
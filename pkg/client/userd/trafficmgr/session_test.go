@@ -0,0 +1,141 @@
+package trafficmgr
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func TestCheckTrafficManagerService(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+
+	managerLabels := map[string]string{"app": "traffic-manager", "telepresence": "manager"}
+	cs := fake.NewClientset(
+		&core.Service{ObjectMeta: meta.ObjectMeta{Name: "traffic-manager", Namespace: "ambassador", Labels: managerLabels}},
+		&core.Service{ObjectMeta: meta.ObjectMeta{Name: "tm", Namespace: "renamed", Labels: managerLabels}},
+		&core.Service{ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "other", Labels: map[string]string{"app": "echo"}}},
+	)
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	assert.NoError(t, CheckTrafficManagerService(ctx, "ambassador"))
+
+	// Not installed.
+	err := CheckTrafficManagerService(ctx, "other")
+	assert.ErrorIs(t, err, ErrTrafficManagerNotFound)
+	assert.NotErrorIs(t, err, ErrTrafficManagerServiceRenamed)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "telepresence helm install")
+
+	// Installed, but the service has been renamed.
+	err = CheckTrafficManagerService(ctx, "renamed")
+	assert.ErrorIs(t, err, ErrTrafficManagerServiceRenamed)
+	assert.NotErrorIs(t, err, ErrTrafficManagerNotFound)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "Set cluster.managerService to tm")
+
+	// The renamed service is found when it's configured.
+	cfg := client.GetDefaultConfig()
+	cfg.Cluster().ManagerService = "tm"
+	assert.NoError(t, CheckTrafficManagerService(client.WithConfig(ctx, cfg), "renamed"))
+
+	// Other failures are not reported as not found.
+	cs.PrependReactor("get", "services", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	err = CheckTrafficManagerService(ctx, "ambassador")
+	assert.NotErrorIs(t, err, ErrTrafficManagerNotFound)
+	assert.ErrorContains(t, err, "unable to get service traffic-manager in ambassador: connection refused")
+}
+
+func Test_session_GetManagerInstallId_cached(t *testing.T) {
+	cs := fake.NewClientset(&core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "ambassador", UID: "6b0e3b4c-6d0a-4a5f-9c52-0f8e1c6b7a10"}})
+	var lookups atomic.Int32
+	var failing atomic.Bool
+	cs.PrependReactor("get", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		lookups.Add(1)
+		if failing.Load() {
+			return true, nil, errors.New("connection refused")
+		}
+		return false, nil, nil
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	cfg := client.GetDefaultConfig()
+	cfg.Cluster().DefaultManagerNamespace = "ambassador"
+	ctx = client.WithConfig(ctx, cfg)
+	s := &session{Cluster: &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}}}
+
+	// A failed lookup isn't cached.
+	failing.Store(true)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", s.GetManagerInstallId(ctx))
+	failing.Store(false)
+	for range 5 {
+		assert.Equal(t, "6b0e3b4c-6d0a-4a5f-9c52-0f8e1c6b7a10", s.GetManagerInstallId(ctx))
+	}
+	assert.Equal(t, int32(2), lookups.Load())
+
+	// The ID is looked up again after a reconnect.
+	s.InvalidateManagerInstallId()
+	assert.Equal(t, "6b0e3b4c-6d0a-4a5f-9c52-0f8e1c6b7a10", s.GetManagerInstallId(ctx))
+	assert.Equal(t, int32(3), lookups.Load())
+}
+
+func Test_session_Status_metadata(t *testing.T) {
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), fake.NewClientset(), argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+	daemonID, err := daemon.NewIdentifier("", "ctx", "default", false)
+	require.NoError(t, err)
+	md := map[string]string{"build": "1234", "pr": "42"}
+	s := &session{
+		Cluster:        &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}},
+		daemonID:       daemonID,
+		rootDaemon:     &fakeRootDaemon{},
+		currentIngests: xsync.NewMapOf[ingestKey, *ingest](),
+		metadata:       md,
+	}
+	assert.Equal(t, md, s.Status(ctx).Metadata)
+}
+
+func Test_environmentEqual(t *testing.T) {
+	t.Setenv("TP_TEST_SET", "value")
+	t.Setenv("TP_TEST_EMPTY", "")
+	t.Setenv("TP_TEST_UNSET", "") // ensures that the variable is restored when the test ends
+	require.NoError(t, os.Unsetenv("TP_TEST_UNSET"))
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"no environment", nil, true},
+		{"must be set", map[string]string{"TP_TEST_SET": "value"}, true},
+		{"must be set to empty", map[string]string{"TP_TEST_EMPTY": ""}, true},
+		{"set to other value", map[string]string{"TP_TEST_SET": "other"}, false},
+		{"must be set but is unset", map[string]string{"TP_TEST_UNSET": ""}, false},
+		{"must be unset", map[string]string{"-TP_TEST_UNSET": ""}, true},
+		{"must be unset but is set", map[string]string{"-TP_TEST_SET": ""}, false},
+		{"must be unset but is set to empty", map[string]string{"-TP_TEST_EMPTY": ""}, false},
+		{"mixed", map[string]string{"TP_TEST_SET": "value", "-TP_TEST_UNSET": ""}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, environmentEqual(tt.env))
+		})
+	}
+}
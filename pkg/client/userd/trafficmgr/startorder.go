@@ -0,0 +1,155 @@
+package trafficmgr
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datawire/dlib/dgroup"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// sessionService is a named service that is started by StartServices.
+type sessionService struct {
+	name string
+	run  func(context.Context) error
+}
+
+// selfReportingServices are the services that report that they're ready once they have synced with the
+// traffic-manager. All other services are ready as soon as they start.
+var selfReportingServices = []string{"agents", "intercept-port-forward"} //nolint:gochecknoglobals // constant
+
+// serviceGate makes services wait for the services that they must start after to become ready. A service
+// is also considered ready when it ends, so that no service waits for one that will never become ready. A nil
+// gate never makes a service wait.
+type serviceGate struct {
+	sync.Mutex
+	known map[string]struct{}
+	ready map[string]chan struct{}
+}
+
+func newServiceGate(services []sessionService) *serviceGate {
+	g := &serviceGate{
+		known: make(map[string]struct{}, len(services)),
+		ready: make(map[string]chan struct{}, len(services)),
+	}
+	for _, svc := range services {
+		g.known[svc.name] = struct{}{}
+		g.ready[svc.name] = make(chan struct{})
+	}
+	return g
+}
+
+// setReady reports that the named service is ready.
+func (g *serviceGate) setReady(name string) {
+	if g == nil {
+		return
+	}
+	g.Lock()
+	defer g.Unlock()
+	if ch, ok := g.ready[name]; ok {
+		select {
+		case <-ch:
+		default:
+			close(ch)
+		}
+	}
+}
+
+// wait waits until all the named services are ready, the timeout has passed, or the context is done. The
+// services that weren't ready when the timeout passed are returned.
+func (g *serviceGate) wait(ctx context.Context, names []string, timeout time.Duration) (notReady []string, err error) {
+	t := client.GetClock(ctx).NewTimer(timeout)
+	defer t.Stop()
+	timedOut := false
+	for _, name := range names {
+		g.Lock()
+		ch := g.ready[name]
+		g.Unlock()
+		if timedOut {
+			select {
+			case <-ch:
+			default:
+				notReady = append(notReady, name)
+			}
+			continue
+		}
+		select {
+		case <-ch:
+		case <-t.C():
+			timedOut = true
+			notReady = append(notReady, name)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return notReady, nil
+}
+
+// startAfter returns the known services that the named service must start after, according to the
+// session.startAfter setting of the client configuration. Nothing is returned when the setting makes the
+// service depend on itself, because it would then never start.
+func (g *serviceGate) startAfter(ctx context.Context, name string) []string {
+	order := client.GetConfig(ctx).Session().StartAfter
+	var deps []string
+	for _, dep := range order[name] {
+		if _, ok := g.known[dep]; ok {
+			deps = append(deps, dep)
+		} else {
+			dlog.Warnf(ctx, "session.startAfter: %s cannot start after unknown service %s", name, dep)
+		}
+	}
+	if dependsOn(order, deps, name, nil) {
+		dlog.Errorf(ctx, "session.startAfter: ignoring the start order of %s because it depends on itself", name)
+		return nil
+	}
+	return deps
+}
+
+// dependsOn returns true if any of the given services transitively must start after the named service.
+func dependsOn(order map[string][]string, deps []string, name string, visited []string) bool {
+	for _, dep := range deps {
+		if dep == name {
+			return true
+		}
+		if slices.Contains(visited, dep) {
+			continue
+		}
+		visited = append(visited, dep)
+		if dependsOn(order, order[dep], name, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// startServices starts the given services in the given group. A service that must start after other
+// services waits for them to become ready before it starts. All services start right away when no start
+// order is configured.
+func (s *session) startServices(g *dgroup.Group, services []sessionService) {
+	s.serviceGate = newServiceGate(services)
+	for _, svc := range services {
+		g.Go(svc.name, func(ctx context.Context) error {
+			defer s.serviceGate.setReady(svc.name)
+			if deps := s.serviceGate.startAfter(ctx, svc.name); len(deps) > 0 {
+				dlog.Debugf(ctx, "waiting for %s to become ready", strings.Join(deps, ", "))
+				timeout := client.GetConfig(ctx).Session().GetStartAfterTimeout()
+				notReady, err := s.serviceGate.wait(ctx, deps, timeout)
+				if err != nil {
+					return nil
+				}
+				if len(notReady) > 0 {
+					dlog.Warnf(ctx, "starting without waiting for %s, which did not become ready within %s",
+						strings.Join(notReady, ", "), timeout)
+				}
+			}
+			if !slices.Contains(selfReportingServices, svc.name) {
+				s.serviceGate.setReady(svc.name)
+			}
+			return svc.run(ctx)
+		})
+	}
+}
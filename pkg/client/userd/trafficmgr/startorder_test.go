@@ -0,0 +1,143 @@
+package trafficmgr
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// slowAgentsManager is a fakeManager that doesn't deliver the agent snapshot until release is closed.
+type slowAgentsManager struct {
+	fakeManager
+	release  chan struct{}
+	watching atomic.Bool
+}
+
+func (m *slowAgentsManager) WatchAgents(ctx context.Context, si *manager.SessionInfo, opts ...grpc.CallOption) (manager.Manager_WatchAgentsClient, error) {
+	m.watching.Store(true)
+	select {
+	case <-m.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.fakeManager.WatchAgents(ctx, si, opts...)
+}
+
+func Test_session_StartServices_startAfter(t *testing.T) {
+	mgr := &slowAgentsManager{release: make(chan struct{})}
+	ctx, s, _ := newTestSession(t, mgr, &fakeRootDaemon{networkUp: true})
+	client.GetConfig(ctx).Session().StartAfter = map[string][]string{
+		"intercept-port-forward": {"agents"},
+		"dial-request-watcher":   {"intercept-port-forward"},
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.RunSession(ctx) }()
+
+	// The services that depend on the agents don't start until the agents have synced.
+	require.Eventually(t, mgr.watching.Load, 5*time.Second, time.Millisecond)
+	assert.Never(t, func() bool {
+		return mgr.interceptWatches.Load() > 0 || mgr.dialWatches.Load() > 0
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	// Once they have, the intercept watcher starts, and the dial request watcher starts once it has synced.
+	close(mgr.release)
+	require.Eventually(t, func() bool {
+		return mgr.interceptWatches.Load() == 1 && mgr.dialWatches.Load() == 1
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunSession didn't return when its context was cancelled")
+	}
+}
+
+func Test_serviceGate_startAfter(t *testing.T) {
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+	g := newServiceGate([]sessionService{{name: "a"}, {name: "b"}, {name: "c"}})
+
+	// All services start right away by default.
+	assert.Empty(t, g.startAfter(ctx, "a"))
+
+	order := map[string][]string{
+		"a": {"b", "unknown"},
+		"b": {"c"},
+	}
+	client.GetConfig(ctx).Session().StartAfter = order
+	assert.Equal(t, []string{"b"}, g.startAfter(ctx, "a"))
+	assert.Equal(t, []string{"c"}, g.startAfter(ctx, "b"))
+	assert.Empty(t, g.startAfter(ctx, "c"))
+
+	// A start order that makes a service wait for itself is ignored.
+	order["c"] = []string{"a"}
+	assert.Empty(t, g.startAfter(ctx, "a"))
+	assert.Empty(t, g.startAfter(ctx, "c"))
+}
+
+func Test_serviceGate_wait(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	g := newServiceGate([]sessionService{{name: "a"}, {name: "b"}})
+	waited := make(chan error, 1)
+	go func() {
+		notReady, err := g.wait(ctx, []string{"a", "b"}, time.Minute)
+		assert.Empty(t, notReady)
+		waited <- err
+	}()
+
+	g.setReady("a")
+	select {
+	case <-waited:
+		t.Fatal("wait returned before all services were ready")
+	case <-time.After(50 * time.Millisecond):
+	}
+	g.setReady("b")
+	g.setReady("b") // Reporting twice is harmless.
+	require.NoError(t, <-waited)
+
+	// A nil gate accepts ready reports.
+	var ng *serviceGate
+	ng.setReady("a")
+}
+
+func Test_serviceGate_waitTimeout(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx := client.WithClock(dlog.NewTestContext(t, false), fc)
+	g := newServiceGate([]sessionService{{name: "a"}, {name: "b"}, {name: "c"}})
+	g.setReady("b")
+
+	type waitResult struct {
+		notReady []string
+		err      error
+	}
+	waited := make(chan waitResult, 1)
+	go func() {
+		notReady, err := g.wait(ctx, []string{"a", "b", "c"}, time.Minute)
+		waited <- waitResult{notReady, err}
+	}()
+
+	// The services that aren't ready when the timeout passes are returned, so that the waiting service can
+	// start anyway.
+	waitForWaiters(t, fc)
+	fc.Step(time.Minute)
+	select {
+	case r := <-waited:
+		require.NoError(t, r.err)
+		assert.Equal(t, []string{"a", "c"}, r.notReady)
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait didn't return when the timeout passed")
+	}
+}
@@ -0,0 +1,129 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/datawire/dlib/dlog"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// maxConcurrentNamespaceClears is the maximum number of namespaces that will have their agents
+// removed concurrently when all agents are uninstalled.
+const maxConcurrentNamespaceClears = 8
+
+// namespaceClear is the outcome of removing the agents from one namespace.
+type namespaceClear struct {
+	namespace string
+	removed   int
+	err       error
+}
+
+// clearNamespaces calls clear for each of the given namespaces using at most maxConcurrent concurrent
+// calls. An error in one namespace doesn't prevent the remaining namespaces from being cleared. The
+// outcomes are returned in the same order as the given namespaces.
+func clearNamespaces(
+	ctx context.Context,
+	namespaces []string,
+	maxConcurrent int,
+	clear func(context.Context, string) (int, error),
+) []namespaceClear {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	results := make([]namespaceClear, len(namespaces))
+	sem := make(chan struct{}, maxConcurrent)
+	var wg sync.WaitGroup
+	wg.Add(len(namespaces))
+	for i, ns := range namespaces {
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r := &results[i]
+			r.namespace = ns
+			r.removed, r.err = clear(ctx, ns)
+			if r.err != nil {
+				dlog.Errorf(ctx, "failed to remove agents from namespace %s: %v", ns, r.err)
+			} else {
+				dlog.Infof(ctx, "removed %d agent(s) from namespace %s", r.removed, ns)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// namespaceClearsResult creates a result that summarizes the given outcomes. The result will list the
+// number of agents removed from each namespace where agents were found, and, when some namespaces could
+// not be cleared, carry an error that lists those namespaces together with the reason for the failure.
+func namespaceClearsResult(results []namespaceClear) *common.Result {
+	var sb strings.Builder
+	var failed []*namespaceClear
+	for i := range results {
+		r := &results[i]
+		switch {
+		case r.err != nil:
+			failed = append(failed, r)
+		case r.removed > 0:
+			fmt.Fprintf(&sb, "Removed %d agent(s) from namespace %s\n", r.removed, r.namespace)
+		}
+	}
+	if len(failed) == 0 {
+		return &common.Result{Data: []byte(sb.String())}
+	}
+	fmt.Fprintf(&sb, "failed to remove agents from %d of %d namespaces:", len(failed), len(results))
+	for _, f := range failed {
+		fmt.Fprintf(&sb, "\n  %s: %v", f.namespace, f.err)
+	}
+	return &common.Result{
+		Data:          []byte(sb.String()),
+		ErrorCategory: common.Result_ErrorCategory(errcat.GetCategory(failed[0].err)),
+	}
+}
+
+// ensureNoForeignIntercepts returns an error that lists the intercepts of other clients on the given agents
+// in the given namespace, or nil if there are none. The traffic-manager is consulted unless the workload
+// watcher knows that no other client intercepts any of the agents.
+func (s *session) ensureNoForeignIntercepts(ctx context.Context, namespace string, agents []string) error {
+	if !slices.ContainsFunc(agents, func(agent string) bool { return s.interceptedByOtherClient(namespace, agent) }) {
+		return nil
+	}
+	iis, err := s.interceptSnapshot(ctx, &manager.SessionInfo{})
+	if err != nil {
+		return fmt.Errorf("unable to check for intercepts of other clients: %w", err)
+	}
+	foreign := findForeignIntercepts(namespace, agents, s.clientID, iis)
+	if len(foreign) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("refusing to uninstall agents that other clients are intercepting:")
+	for _, ii := range foreign {
+		fmt.Fprintf(&sb, "\n  %s.%s is intercepted by client %s using intercept %s", ii.Spec.Agent, ii.Spec.Namespace, ii.Spec.Client, ii.Spec.Name)
+	}
+	sb.WriteString("\nUse --force to uninstall anyway")
+	return errcat.User.New(sb.String())
+}
+
+// findForeignIntercepts returns the intercepts in the given list that belong to other clients than the given
+// one and that intercept one of the given agents in the given namespace.
+func findForeignIntercepts(namespace string, agents []string, clientID string, iis []*manager.InterceptInfo) []*manager.InterceptInfo {
+	var foreign []*manager.InterceptInfo
+	for _, ii := range iis {
+		is := ii.Spec
+		if is.Client != clientID && is.Namespace == namespace && slices.Contains(agents, is.Agent) &&
+			ii.Disposition != manager.InterceptDispositionType_REMOVED {
+			foreign = append(foreign, ii)
+		}
+	}
+	return foreign
+}
@@ -0,0 +1,247 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func Test_clearNamespaces(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	agentCounts := map[string]int{"alpha": 2, "beta": 0, "gamma": 3, "delta": 1}
+	namespaces := []string{"alpha", "beta", "broken", "gamma", "delta"}
+
+	var running, maxRunning atomic.Int32
+	clear := func(_ context.Context, ns string) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if ns == "broken" {
+			return 0, errcat.User.New("configmaps is forbidden")
+		}
+		return agentCounts[ns], nil
+	}
+
+	results := clearNamespaces(ctx, namespaces, 2, clear)
+	require.Len(t, results, len(namespaces))
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	for i, r := range results {
+		assert.Equal(t, namespaces[i], r.namespace)
+		if r.namespace == "broken" {
+			assert.Error(t, r.err)
+		} else {
+			assert.NoError(t, r.err)
+			assert.Equal(t, agentCounts[r.namespace], r.removed)
+		}
+	}
+
+	result := namespaceClearsResult(results)
+	assert.Equal(t, common.Result_USER, result.ErrorCategory)
+	assert.Equal(t, `Removed 2 agent(s) from namespace alpha
+Removed 3 agent(s) from namespace gamma
+Removed 1 agent(s) from namespace delta
+failed to remove agents from 1 of 5 namespaces:
+  broken: configmaps is forbidden`, string(result.Data))
+}
+
+func Test_namespaceClearsResult(t *testing.T) {
+	result := namespaceClearsResult([]namespaceClear{
+		{namespace: "alpha", removed: 2},
+		{namespace: "beta"},
+	})
+	assert.Equal(t, common.Result_UNSPECIFIED, result.ErrorCategory)
+	assert.Equal(t, "Removed 2 agent(s) from namespace alpha\n", string(result.Data))
+
+	result = namespaceClearsResult([]namespaceClear{
+		{namespace: "alpha", err: errors.New("boom")},
+		{namespace: "beta", err: errcat.User.New("forbidden")},
+	})
+	assert.Equal(t, common.Result_UNKNOWN, result.ErrorCategory)
+	assert.Equal(t, "failed to remove agents from 2 of 2 namespaces:\n  alpha: boom\n  beta: forbidden", string(result.Data))
+	assert.NoError(t, errcat.FromResult(namespaceClearsResult(nil)))
+}
+
+func clientIntercept(name, client, agent string, disposition manager.InterceptDispositionType) *manager.InterceptInfo {
+	return &manager.InterceptInfo{
+		Id:          client + ":" + name,
+		Disposition: disposition,
+		Spec:        &manager.InterceptSpec{Name: name, Client: client, Agent: agent, Namespace: "a"},
+	}
+}
+
+func Test_session_ensureNoForeignIntercepts(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := &session{
+		clientID: "me@host",
+		managerClient: &fakeInterceptManager{intercepts: []*manager.InterceptInfo{
+			clientIntercept("mine", "me@host", "echo", manager.InterceptDispositionType_ACTIVE),
+			clientIntercept("theirs", "other@host", "echo", manager.InterceptDispositionType_ACTIVE),
+			clientIntercept("waiting", "third@host", "hello", manager.InterceptDispositionType_WAITING),
+			clientIntercept("gone", "other@host", "bye", manager.InterceptDispositionType_REMOVED),
+		}},
+		workloads: map[workloadInfoKey]workloadInfo{
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}:  {interceptClients: []string{"me@host", "other@host"}},
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "hello"}: {interceptClients: []string{"third@host"}},
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "bye"}:   {interceptClients: []string{"other@host"}},
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "free"}:  {},
+		},
+		syncedNamespaces: map[string]struct{}{"a": {}},
+	}
+
+	err := s.ensureNoForeignIntercepts(ctx, "a", []string{"echo", "hello", "free"})
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.Equal(t, `refusing to uninstall agents that other clients are intercepting:
+  echo.a is intercepted by client other@host using intercept theirs
+  hello.a is intercepted by client third@host using intercept waiting
+Use --force to uninstall anyway`, err.Error())
+
+	// Removed intercepts, and intercepts in other namespaces, don't count.
+	assert.NoError(t, s.ensureNoForeignIntercepts(ctx, "a", []string{"bye", "free"}))
+	assert.NoError(t, s.ensureNoForeignIntercepts(ctx, "b", []string{"echo"}))
+
+	// The traffic-manager isn't consulted when the workload watcher knows that no other client intercepts the agents.
+	s.managerClient = nil
+	assert.NoError(t, s.ensureNoForeignIntercepts(ctx, "a", []string{"free"}))
+}
+
+func Test_loadAgentConfigMap(t *testing.T) {
+	cs := fake.NewClientset(
+		&core.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: agentconfig.ConfigMap, Namespace: "a"}},
+		&core.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: "custom-agents", Namespace: "a"}},
+	)
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+
+	// A missing default ConfigMap means that there are no agents.
+	dctx := client.WithConfig(ctx, client.GetDefaultConfig())
+	cm, err := loadAgentConfigMap(dctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, agentconfig.ConfigMap, cm.Name)
+	cm, err = loadAgentConfigMap(dctx, "b")
+	require.NoError(t, err)
+	assert.Nil(t, cm)
+
+	// A missing configured ConfigMap is an error.
+	cfg := client.GetDefaultConfig()
+	cfg.Cluster().AgentConfigMap = "custom-agents"
+	cctx := client.WithConfig(ctx, cfg)
+	cm, err = loadAgentConfigMap(cctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "custom-agents", cm.Name)
+	_, err = loadAgentConfigMap(cctx, "b")
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "custom-agents.b")
+}
+
+func Test_loadAgentConfigMap_forbidden(t *testing.T) {
+	cs := fake.NewClientset()
+	cs.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "a" {
+			return true, nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, agentconfig.ConfigMap, errors.New("no RBAC policy matched"))
+		}
+		return true, nil, errors.New("connection refused")
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+
+	// The user is told what permissions are missing.
+	_, err := loadAgentConfigMap(ctx, "a")
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.True(t, k8serrors.IsForbidden(err))
+	assert.ErrorContains(t, err, "not allowed to get the agents ConfigMap telepresence-agents.a")
+	assert.ErrorContains(t, err, `verbs "get" and "update" on the resource "configmaps" with resource name "telepresence-agents"`)
+
+	// Other errors are returned as is.
+	_, err = loadAgentConfigMap(ctx, "b")
+	require.Error(t, err)
+	assert.Equal(t, errcat.Unknown, errcat.GetCategory(err))
+	assert.EqualError(t, err, "connection refused")
+}
+
+func Test_session_clearAgentsConfigMap_forbidden(t *testing.T) {
+	cs := fake.NewClientset(&core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: agentconfig.ConfigMap, Namespace: "a"},
+		Data:       map[string]string{"echo": ""},
+	})
+	cs.PrependReactor("update", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, agentconfig.ConfigMap, errors.New("no RBAC policy matched"))
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+	s := &session{}
+
+	_, err := s.clearAgentsConfigMap(ctx, "a", true)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "not allowed to update the agents ConfigMap telepresence-agents.a")
+}
+
+func Test_session_clearAgentsConfigMap(t *testing.T) {
+	cs := fake.NewClientset(&core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: agentconfig.ConfigMap, Namespace: "a"},
+		Data:       map[string]string{"echo": "", "free": ""},
+	})
+	ctx := k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset())
+	ctx = client.WithConfig(ctx, client.GetDefaultConfig())
+	s := &session{
+		clientID: "me@host",
+		managerClient: &fakeInterceptManager{intercepts: []*manager.InterceptInfo{
+			clientIntercept("theirs", "other@host", "echo", manager.InterceptDispositionType_ACTIVE),
+		}},
+		workloads: map[workloadInfoKey]workloadInfo{
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}: {interceptClients: []string{"other@host"}},
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "free"}: {},
+		},
+		syncedNamespaces: map[string]struct{}{"a": {}},
+	}
+	agentCount := func() int {
+		cm, err := cs.CoreV1().ConfigMaps("a").Get(ctx, agentconfig.ConfigMap, meta.GetOptions{})
+		require.NoError(t, err)
+		return len(cm.Data)
+	}
+
+	// Nothing is removed from a namespace where another client intercepts an agent.
+	_, err := s.clearAgentsConfigMap(ctx, "a", false)
+	require.Error(t, err)
+	assert.Equal(t, errcat.User, errcat.GetCategory(err))
+	assert.ErrorContains(t, err, "echo.a is intercepted by client other@host using intercept theirs")
+	assert.Equal(t, 2, agentCount())
+
+	// Namespaces without agents are fine.
+	removed, err := s.clearAgentsConfigMap(ctx, "b", false)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	// Forced removal ignores the other clients.
+	removed, err = s.clearAgentsConfigMap(ctx, "a", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Zero(t, agentCount())
+}
@@ -0,0 +1,134 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
+)
+
+// ValidateInterceptSpec performs the checks that are performed when an intercept is created for the given
+// request, and returns the errors that they find. Neither the request nor the cluster is modified, so the
+// traffic-manager isn't asked to prepare the intercept. Conflicts with intercepts of other clients are
+// therefore not detected, because they depend on the container port that the traffic-manager resolves.
+func (s *session) ValidateInterceptSpec(ctx context.Context, ir *rpc.CreateInterceptRequest) []*rpc.InterceptValidationError {
+	var errs []*rpc.InterceptValidationError
+	addError := func(field string, tp common.InterceptError, format string, args ...any) {
+		errs = append(errs, &rpc.InterceptValidationError{Field: field, Error: tp, ErrorText: fmt.Sprintf(format, args...)})
+	}
+
+	// The preset is applied to a copy, because it modifies the request.
+	ir = proto.Clone(ir).(*rpc.CreateInterceptRequest)
+	if ir.Spec == nil {
+		ir.Spec = &manager.InterceptSpec{}
+	}
+	if er := applyInterceptPreset(ctx, ir); er != nil {
+		addError("preset", er.Error, "%s", er.ErrorText)
+	}
+
+	spec := ir.Spec
+	if spec.Name == "" {
+		addError("spec.name", common.InterceptError_INVALID_VALUE, "an intercept name is required")
+	}
+	if spec.Namespace == "" {
+		spec.Namespace = s.Namespace
+	} else if s.Namespace != spec.Namespace {
+		addError("spec.namespace", common.InterceptError_NAMESPACE_AMBIGUITY, "%s,%s", s.Namespace, spec.Namespace)
+	}
+	if spec.PortIdentifier != "" {
+		if err := agentconfig.PortIdentifier(spec.PortIdentifier).Validate(); err != nil {
+			addError("spec.port_identifier", common.InterceptError_INVALID_VALUE, "%s: %v", spec.PortIdentifier, err)
+		}
+	}
+	if spec.TargetPort < 0 || spec.TargetPort > 0xffff {
+		addError("spec.target_port", common.InterceptError_INVALID_VALUE, "%d is not a valid port number", spec.TargetPort)
+	}
+	for _, lp := range spec.LocalPorts {
+		if _, err := agentconfig.NewPortAndProto(lp); err != nil {
+			addError("spec.local_ports", common.InterceptError_INVALID_VALUE, "%s: %v", lp, err)
+		}
+	}
+	if len(spec.SourceCidrs) > 0 {
+		if _, err := matcher.NewRequestFromMap(map[string]string{":source-cidr:": strings.Join(spec.SourceCidrs, ",")}); err != nil {
+			addError("spec.source_cidrs", common.InterceptError_INVALID_VALUE, "%v", err)
+		}
+	}
+	for _, gm := range spec.GrpcMethods {
+		if _, err := matcher.NewGRPCMethod(gm); err != nil {
+			addError("spec.grpc_methods", common.InterceptError_INVALID_VALUE, "%v", err)
+		}
+	}
+	if spec.AllConnections && (len(spec.SourceCidrs) > 0 || len(spec.GrpcMethods) > 0 || len(spec.PortHeaders) > 0) {
+		addError("spec.all_connections", common.InterceptError_INVALID_VALUE, "cannot be combined with source CIDRs, gRPC methods, or port headers")
+	}
+	if er := s.ensureMirrorSupport(spec); er != nil {
+		addError("spec.mirror", er.Error, "%s", er.ErrorText)
+	}
+	if ir.LocalMountPort < 0 || ir.LocalMountPort > 0xffff {
+		addError("local_mount_port", common.InterceptError_INVALID_VALUE, "%d is not a valid port number", ir.LocalMountPort)
+	}
+	if ir.IdleTimeout != nil && ir.IdleTimeout.AsDuration() < 0 {
+		addError("idle_timeout", common.InterceptError_INVALID_VALUE, "%s is negative", ir.IdleTimeout.AsDuration())
+	}
+	if ir.DrainPeriod != nil && ir.DrainPeriod.AsDuration() < 0 {
+		addError("drain_period", common.InterceptError_INVALID_VALUE, "%s is negative", ir.DrainPeriod.AsDuration())
+	}
+
+	if err := s.ensureNoMountConflict(ir.MountPoint, 0); err != nil {
+		addError("mount_point", common.InterceptError_MOUNT_POINT_BUSY, "%s", status.Convert(err).Message())
+	}
+	if err := s.ensureNoMountConflict("", ir.LocalMountPort); err != nil {
+		addError("local_mount_port", common.InterceptError_MOUNT_POINT_BUSY, "%s", status.Convert(err).Message())
+	}
+	s.currentInterceptsLock.Lock()
+	for _, ic := range s.currentIntercepts {
+		switch {
+		case spec.Name != "" && ic.Spec.Name == spec.Name:
+			addError("spec.name", common.InterceptError_ALREADY_EXISTS, "%s", spec.Name)
+		case spec.TargetPort != 0 && ic.Spec.TargetPort == spec.TargetPort && ic.Spec.TargetHost == spec.TargetHost:
+			addError("spec.target_port", common.InterceptError_LOCAL_TARGET_IN_USE, "%d is the target of intercept %s", spec.TargetPort, ic.Spec.Name)
+		}
+	}
+	s.currentInterceptsLock.Unlock()
+
+	if spec.Agent != "" && spec.Namespace == s.Namespace {
+		if code, reason, ok := s.workloadInterceptable(spec); !ok {
+			addError("spec.agent", common.InterceptError_NOT_FOUND, "workload %s.%s not found", spec.Agent, spec.Namespace)
+		} else if code != rpc.WorkloadInfo_INTERCEPTABLE {
+			tp := common.InterceptError_NO_ACCEPTABLE_WORKLOAD
+			if code == rpc.WorkloadInfo_UNSUPPORTED_KIND {
+				tp = common.InterceptError_UNSUPPORTED_WORKLOAD
+			}
+			addError("spec.agent", tp, "workload %s.%s cannot be intercepted: %s", spec.Agent, spec.Namespace, reason)
+		}
+	}
+	return errs
+}
+
+// workloadInterceptable uses the workload watcher of the namespace of the given spec to tell if its workload
+// can be intercepted. No watcher is started, so the returned ok is false only when an existing watcher knows
+// that the workload doesn't exist. A workload that the watchers don't store, because its name doesn't match
+// the workload name prefixes, is never reported as missing.
+func (s *session) workloadInterceptable(spec *manager.InterceptSpec) (code rpc.WorkloadInfo_NotInterceptableCode, reason string, ok bool) {
+	if !s.watchesWorkload(spec.Agent) || !s.IsNamespaceWatched(spec.Namespace) {
+		// The existence of the workload cannot be determined. Creating the intercept will tell.
+		return rpc.WorkloadInfo_INTERCEPTABLE, "", true
+	}
+	kind := rpcKind(spec.WorkloadKind)
+	s.eachWorkload([]string{spec.Namespace}, func(key workloadInfoKey, info workloadInfo) {
+		if key.name == spec.Agent && (kind == manager.WorkloadInfo_UNSPECIFIED || key.kind == kind) && (!ok || code != rpc.WorkloadInfo_INTERCEPTABLE) {
+			// Workloads of different kinds may have the same name. One that can be intercepted is preferred.
+			code, reason = notInterceptable(key.kind, info.state)
+			ok = true
+		}
+	})
+	return code, reason, ok
+}
@@ -0,0 +1,136 @@
+package trafficmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func Test_session_ValidateInterceptSpec(t *testing.T) {
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "default"}}
+	client.GetConfig(ctx).Intercept().Presets = map[string]*client.InterceptPreset{
+		"bad-ports": {LocalPorts: []string{"x/TCP"}},
+		"port-8080": {TargetPort: 8080},
+	}
+
+	// The workload watcher of the namespace has synced.
+	s.syncedNamespaces["default"] = struct{}{}
+	s.workloads[workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "echo"}] = workloadInfo{state: workload.StateAvailable}
+	s.workloads[workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "rolling"}] = workloadInfo{state: workload.StateProgressing}
+	s.workloads[workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "failed"}] = workloadInfo{state: workload.StateFailure}
+	s.workloads[workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "default", name: "legacy"}] = workloadInfo{state: workload.StateAvailable}
+	s.setCurrentAgents([]*manager.AgentInfo{
+		{Name: "echo", Namespace: "default", Version: "2.22.0-rc.1"},
+		{Name: "legacy", Namespace: "default", Version: "2.21.1"},
+	})
+
+	existing := activeIntercept("existing", 8080, time.Now(), 0)
+	existing.ClientMountPoint = "/tmp/existing"
+	existing.localMountPort = 2222
+	s.currentIntercepts = map[string]*intercept{existing.Id: existing}
+
+	valid := func() *rpc.CreateInterceptRequest {
+		return &rpc.CreateInterceptRequest{Spec: &manager.InterceptSpec{
+			Name:       "echo",
+			Agent:      "echo",
+			TargetHost: "127.0.0.1",
+			TargetPort: 8081,
+		}}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*rpc.CreateInterceptRequest)
+		field  string
+		error  common.InterceptError
+	}{
+		{"unknown preset", func(ir *rpc.CreateInterceptRequest) { ir.Preset = "nope" }, "preset", common.InterceptError_UNKNOWN_PRESET},
+		{"invalid preset", func(ir *rpc.CreateInterceptRequest) { ir.Preset = "bad-ports" }, "preset", common.InterceptError_INVALID_VALUE},
+		{"no name", func(ir *rpc.CreateInterceptRequest) { ir.Spec.Name = "" }, "spec.name", common.InterceptError_INVALID_VALUE},
+		{"name in use", func(ir *rpc.CreateInterceptRequest) { ir.Spec.Name = "existing" }, "spec.name", common.InterceptError_ALREADY_EXISTS},
+		{"other namespace", func(ir *rpc.CreateInterceptRequest) { ir.Spec.Namespace = "other" }, "spec.namespace", common.InterceptError_NAMESPACE_AMBIGUITY},
+		{"invalid port", func(ir *rpc.CreateInterceptRequest) { ir.Spec.PortIdentifier = "http/SCTP" }, "spec.port_identifier", common.InterceptError_INVALID_VALUE},
+		{"invalid target port", func(ir *rpc.CreateInterceptRequest) { ir.Spec.TargetPort = 70000 }, "spec.target_port", common.InterceptError_INVALID_VALUE},
+		{"target in use", func(ir *rpc.CreateInterceptRequest) { ir.Spec.TargetPort = 8080 }, "spec.target_port", common.InterceptError_LOCAL_TARGET_IN_USE},
+		{"preset target in use", func(ir *rpc.CreateInterceptRequest) {
+			ir.Spec.TargetPort = 0
+			ir.Preset = "port-8080"
+		}, "spec.target_port", common.InterceptError_LOCAL_TARGET_IN_USE},
+		{"invalid local port", func(ir *rpc.CreateInterceptRequest) { ir.Spec.LocalPorts = []string{"53/ICMP"} }, "spec.local_ports", common.InterceptError_INVALID_VALUE},
+		{"invalid source CIDR", func(ir *rpc.CreateInterceptRequest) { ir.Spec.SourceCidrs = []string{"10.0.0.0/33"} }, "spec.source_cidrs", common.InterceptError_INVALID_VALUE},
+		{"invalid gRPC method", func(ir *rpc.CreateInterceptRequest) { ir.Spec.GrpcMethods = []string{"/pkg.Service"} }, "spec.grpc_methods", common.InterceptError_INVALID_VALUE},
+		{"all connections with source CIDRs", func(ir *rpc.CreateInterceptRequest) {
+			ir.Spec.AllConnections = true
+			ir.Spec.SourceCidrs = []string{"10.0.0.0/8"}
+		}, "spec.all_connections", common.InterceptError_INVALID_VALUE},
+		{"mirror with replace", func(ir *rpc.CreateInterceptRequest) {
+			ir.Spec.Mirror = true
+			ir.Spec.Replace = true
+		}, "spec.mirror", common.InterceptError_INVALID_VALUE},
+		{"mirror with old agent", func(ir *rpc.CreateInterceptRequest) {
+			ir.Spec.Mirror = true
+			ir.Spec.Agent = "legacy"
+		}, "spec.mirror", common.InterceptError_INVALID_VALUE},
+		{"invalid mount port", func(ir *rpc.CreateInterceptRequest) { ir.LocalMountPort = -1 }, "local_mount_port", common.InterceptError_INVALID_VALUE},
+		{"mount port in use", func(ir *rpc.CreateInterceptRequest) { ir.LocalMountPort = 2222 }, "local_mount_port", common.InterceptError_MOUNT_POINT_BUSY},
+		{"mount point in use", func(ir *rpc.CreateInterceptRequest) { ir.MountPoint = "/tmp/existing" }, "mount_point", common.InterceptError_MOUNT_POINT_BUSY},
+		{"negative idle timeout", func(ir *rpc.CreateInterceptRequest) { ir.IdleTimeout = durationpb.New(-1) }, "idle_timeout", common.InterceptError_INVALID_VALUE},
+		{"no workload", func(ir *rpc.CreateInterceptRequest) { ir.Spec.Agent = "nope" }, "spec.agent", common.InterceptError_NOT_FOUND},
+		{"no workload of kind", func(ir *rpc.CreateInterceptRequest) { ir.Spec.WorkloadKind = "StatefulSet" }, "spec.agent", common.InterceptError_NOT_FOUND},
+		{"progressing workload", func(ir *rpc.CreateInterceptRequest) { ir.Spec.Agent = "rolling" }, "spec.agent", common.InterceptError_NO_ACCEPTABLE_WORKLOAD},
+		{"failed workload", func(ir *rpc.CreateInterceptRequest) { ir.Spec.Agent = "failed" }, "spec.agent", common.InterceptError_NO_ACCEPTABLE_WORKLOAD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := valid()
+			tt.modify(ir)
+			org := proto.Clone(ir)
+			errs := s.ValidateInterceptSpec(ctx, ir)
+			require.Len(t, errs, 1, "%v", errs)
+			assert.Equal(t, tt.field, errs[0].Field)
+			assert.Equal(t, tt.error, errs[0].Error)
+			assert.NotEmpty(t, errs[0].ErrorText)
+			assert.True(t, proto.Equal(org, ir), "the request was modified")
+		})
+	}
+
+	// A valid request yields no errors, and several invalid fields yield several errors.
+	assert.Empty(t, s.ValidateInterceptSpec(ctx, valid()))
+	ir := valid()
+	ir.Spec.Mirror = true
+	assert.Empty(t, s.ValidateInterceptSpec(ctx, ir))
+	ir = valid()
+	ir.Spec.Name = ""
+	ir.Spec.TargetPort = -1
+	ir.Spec.Agent = "nope"
+	assert.Len(t, s.ValidateInterceptSpec(ctx, ir), 3)
+
+	// A workload that doesn't match the workload name prefixes isn't stored by the watcher, so it isn't missing.
+	s.workloadPrefixes = []string{"ech"}
+	ir = valid()
+	ir.Spec.Agent = "nope"
+	assert.Empty(t, s.ValidateInterceptSpec(ctx, ir))
+	ir.Spec.Agent = "echo-nope"
+	assert.Len(t, s.ValidateInterceptSpec(ctx, ir), 1)
+	s.workloadPrefixes = nil
+
+	// A namespace that isn't watched doesn't get a watcher, so a workload that doesn't exist isn't detected.
+	delete(s.syncedNamespaces, "default")
+	ir = valid()
+	ir.Spec.Agent = "nope"
+	assert.Empty(t, s.ValidateInterceptSpec(ctx, ir))
+	assert.Empty(t, s.watcherSyncs)
+	assert.Empty(t, s.watcherCancels)
+}
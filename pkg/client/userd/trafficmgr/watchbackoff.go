@@ -0,0 +1,103 @@
+package trafficmgr
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+const (
+	// watcherBackoffInitial is the time that a failed workload watcher waits before it's restarted.
+	watcherBackoffInitial = time.Second
+
+	// watcherBackoffMax caps the time that a repeatedly failing workload watcher waits before it's restarted.
+	watcherBackoffMax = 2 * time.Minute
+)
+
+// watchBackoff is the backoff of a namespace whose workload watcher has failed since it last synced.
+type watchBackoff struct {
+	failures int
+	retryAt  time.Time
+}
+
+// watcherBackoffDelay returns the time to wait before restarting a watcher that has failed the given number
+// of consecutive times. The delay doubles with each failure until it reaches watcherBackoffMax.
+func watcherBackoffDelay(failures int) time.Duration {
+	delay := watcherBackoffInitial
+	for i := 1; i < failures && delay < watcherBackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, watcherBackoffMax)
+}
+
+// watcherFailed records a failure of the workload watcher for the given namespace and returns the delay
+// until it may be restarted.
+func (s *session) watcherFailed(clk client.Clock, namespace string) time.Duration {
+	s.workloadsLock.Lock()
+	defer s.workloadsLock.Unlock()
+	b, ok := s.watchBackoffs[namespace]
+	if !ok {
+		b = &watchBackoff{}
+		if s.watchBackoffs == nil {
+			s.watchBackoffs = make(map[string]*watchBackoff)
+		}
+		s.watchBackoffs[namespace] = b
+	}
+	b.failures++
+	delay := watcherBackoffDelay(b.failures)
+	b.retryAt = clk.Now().Add(delay)
+	return delay
+}
+
+// resetWatcherBackoff removes the backoff of the given namespace, so that a later failure of its workload watcher
+// is delayed by watcherBackoffInitial.
+func (s *session) resetWatcherBackoff(namespace string) {
+	s.workloadsLock.Lock()
+	delete(s.watchBackoffs, namespace)
+	s.workloadsLock.Unlock()
+}
+
+// scheduleWatcherRetry restarts the workload watcher of the given namespace when the given delay has passed. The
+// retry is dropped if the watcher is stopped before that, e.g. because the namespace is no longer mapped, or
+// because another caller restarted it.
+func (s *session) scheduleWatcherRetry(ctx, wCtx context.Context, clk client.Clock, namespace string, delay time.Duration) {
+	t := clk.NewTimer(delay)
+	go func() {
+		defer t.Stop()
+		select {
+		case <-wCtx.Done():
+		case <-t.C():
+			s.ensureWatchers(ctx, []string{namespace})
+		}
+	}()
+}
+
+// getWatcherRetries returns the backoffs of the failed workload watchers, sorted by namespace.
+func (s *session) getWatcherRetries() []*rpc.WatcherRetry {
+	s.workloadsLock.Lock()
+	defer s.workloadsLock.Unlock()
+	if len(s.watchBackoffs) == 0 {
+		return nil
+	}
+	wrs := make([]*rpc.WatcherRetry, 0, len(s.watchBackoffs))
+	for ns, b := range s.watchBackoffs {
+		wr := &rpc.WatcherRetry{
+			Namespace: ns,
+			Failures:  int32(b.failures),
+			RetryAt:   timestamppb.New(b.retryAt),
+		}
+		if err := s.watchErrors[ns]; err != nil {
+			wr.Error = err.Error()
+		}
+		wrs = append(wrs, wr)
+	}
+	sort.Slice(wrs, func(i, j int) bool {
+		return wrs[i].Namespace < wrs[j].Namespace
+	})
+	return wrs
+}
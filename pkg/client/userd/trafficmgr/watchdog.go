@@ -0,0 +1,69 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// connStateWatcher is the part of a grpc.ClientConn that the manager watchdog uses to observe the state of
+// the connection.
+type connStateWatcher interface {
+	GetState() connectivity.State
+	WaitForStateChange(context.Context, connectivity.State) bool
+}
+
+// managerWatchdog reconnects to the traffic-manager as soon as the connection to it fails, e.g. because the
+// port-forward that it uses was dropped, instead of waiting for the next call to Remain to detect it.
+func (s *session) managerWatchdog(ctx context.Context) error {
+	return s.watchManagerConn(ctx, func() (connStateWatcher, manager.ManagerClient) {
+		s.managerLock.RLock()
+		defer s.managerLock.RUnlock()
+		if s.managerConn == nil {
+			return nil, s.managerClient
+		}
+		return s.managerConn, s.managerClient
+	})
+}
+
+// watchManagerConn waits for the connection returned by the given function to transition to a failed state,
+// and then reconnects the client that uses it. The function is called again after each reconnect, because
+// a reconnect replaces the connection. ErrSessionExpired is returned when the traffic-manager no longer knows
+// the session.
+func (s *session) watchManagerConn(ctx context.Context, current func() (connStateWatcher, manager.ManagerClient)) error {
+	backoff := 100 * time.Millisecond
+	for {
+		conn, mc := current()
+		if conn == nil {
+			dlog.Debug(ctx, "there's no connection to the traffic-manager to watch")
+			return nil
+		}
+		state := conn.GetState()
+		for state != connectivity.TransientFailure && state != connectivity.Shutdown {
+			if !conn.WaitForStateChange(ctx, state) {
+				return nil
+			}
+			state = conn.GetState()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		dlog.Debugf(ctx, "connection to the traffic-manager is in state %s", state)
+		if err := s.reconnectManager(ctx, mc); err != nil {
+			if errors.Is(err, ErrSessionExpired) {
+				return err
+			}
+			dlog.Warn(ctx, err)
+			client.SleepWithContext(ctx, backoff)
+			backoff = min(2*backoff, 3*time.Second)
+			continue
+		}
+		backoff = 100 * time.Millisecond
+	}
+}
@@ -0,0 +1,126 @@
+package trafficmgr
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+)
+
+// fakeConnState is a connection whose state is set by the test.
+type fakeConnState struct {
+	sync.Mutex
+	state   connectivity.State
+	changed chan struct{}
+}
+
+func newFakeConnState() *fakeConnState {
+	return &fakeConnState{state: connectivity.Ready, changed: make(chan struct{})}
+}
+
+func (c *fakeConnState) GetState() connectivity.State {
+	c.Lock()
+	defer c.Unlock()
+	return c.state
+}
+
+func (c *fakeConnState) WaitForStateChange(ctx context.Context, state connectivity.State) bool {
+	for {
+		c.Lock()
+		if c.state != state {
+			c.Unlock()
+			return true
+		}
+		changed := c.changed
+		c.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (c *fakeConnState) set(state connectivity.State) {
+	c.Lock()
+	defer c.Unlock()
+	c.state = state
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func Test_session_watchManagerConn(t *testing.T) {
+	ctx := userd.WithService(client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig()), &fakeService{})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dropped := &fakeDroppingManager{}
+	restored := &fakeDroppingManager{}
+	expired := &fakeDroppingManager{remainErr: status.Error(codes.NotFound, "no such session")}
+	var dials atomic.Int32
+	s := &session{
+		Cluster:        &k8s.Cluster{},
+		sessionInfo:    &manager.SessionInfo{SessionId: "session"},
+		managerClient:  dropped,
+		managerVersion: semver.MustParse("2.21.0"),
+		dialManager: func(context.Context) (*grpc.ClientConn, manager.ManagerClient, error) {
+			if dials.Add(1) == 1 {
+				return nil, restored, nil
+			}
+			return nil, expired, nil
+		},
+	}
+	conns := map[manager.ManagerClient]*fakeConnState{dropped: newFakeConnState(), restored: newFakeConnState()}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.watchManagerConn(ctx, func() (connStateWatcher, manager.ManagerClient) {
+			mc := s.ManagerClient()
+			return conns[mc], mc
+		})
+	}()
+
+	// A connection that is reestablished by gRPC isn't replaced.
+	conns[dropped].set(connectivity.Connecting)
+	conns[dropped].set(connectivity.Ready)
+	assert.Never(t, func() bool { return dials.Load() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	// A connection that fails is replaced right away.
+	conns[dropped].set(connectivity.TransientFailure)
+	require.Eventually(t, func() bool { return s.ManagerClient() == restored }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), dials.Load())
+
+	// The replaced connection is no longer watched.
+	conns[dropped].set(connectivity.Shutdown)
+	assert.Never(t, func() bool { return dials.Load() > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	// The watchdog ends the session when the traffic-manager no longer knows it.
+	conns[restored].set(connectivity.Shutdown)
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrSessionExpired)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watchdog didn't end when the session expired")
+	}
+	assert.Equal(t, int32(2), dials.Load())
+	assert.Equal(t, restored, s.ManagerClient())
+}
+
+func Test_session_managerWatchdog_noConn(t *testing.T) {
+	s := &session{managerClient: &fakeDroppingManager{}}
+	assert.NoError(t, s.managerWatchdog(dlog.NewTestContext(t, false)))
+}
@@ -0,0 +1,70 @@
+package trafficmgr
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+)
+
+type workloadDeltaKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func deltaKey(wi *rpc.WorkloadInfo) workloadDeltaKey {
+	return workloadDeltaKey{kind: wi.WorkloadResourceType, namespace: wi.Namespace, name: wi.Name}
+}
+
+// workloadDeltas keeps track of the workloads that a WatchWorkloads stream has sent, so that each new
+// snapshot can be reduced to a delta.
+type workloadDeltas struct {
+	sent map[workloadDeltaKey]*rpc.WorkloadInfo
+}
+
+// reduce turns the given complete snapshot into a delta containing the workloads that were added or
+// changed, and the workloads that were removed, since the previous call. The first call returns the
+// snapshot unchanged.
+func (wd *workloadDeltas) reduce(ws *rpc.WorkloadInfoSnapshot) *rpc.WorkloadInfoSnapshot {
+	sent := make(map[workloadDeltaKey]*rpc.WorkloadInfo, len(ws.Workloads))
+	for _, wi := range ws.Workloads {
+		sent[deltaKey(wi)] = wi
+	}
+	prev := wd.sent
+	wd.sent = sent
+	if prev == nil {
+		return ws
+	}
+
+	var changed []*rpc.WorkloadInfo
+	for _, wi := range ws.Workloads {
+		if pwi, ok := prev[deltaKey(wi)]; !ok || !proto.Equal(pwi, wi) {
+			changed = append(changed, wi)
+		}
+	}
+	var removed []*rpc.WorkloadInfo
+	for k, pwi := range prev {
+		if _, ok := sent[k]; !ok {
+			removed = append(removed, &rpc.WorkloadInfo{
+				Name:                 pwi.Name,
+				Namespace:            pwi.Namespace,
+				WorkloadResourceType: pwi.WorkloadResourceType,
+				Uid:                  pwi.Uid,
+			})
+		}
+	}
+	sortWorkloadInfos(removed)
+	return &rpc.WorkloadInfoSnapshot{
+		Workloads:        changed,
+		SyncedNamespaces: ws.SyncedNamespaces,
+		Total:            ws.Total,
+		Delta:            true,
+		Removed:          removed,
+		ExposureErrors:   ws.ExposureErrors,
+	}
+}
+
+// isEmptyDelta returns true if the given snapshot is a delta that carries no information.
+func isEmptyDelta(ws *rpc.WorkloadInfoSnapshot) bool {
+	return ws.Delta && len(ws.Workloads) == 0 && len(ws.Removed) == 0 && len(ws.SyncedNamespaces) == 0
+}
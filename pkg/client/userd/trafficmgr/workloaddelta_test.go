@@ -0,0 +1,84 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+)
+
+// applyWorkloadDelta applies the given snapshot to the state in the way that a client of WatchWorkloads would.
+func applyWorkloadDelta(state map[workloadDeltaKey]*rpc.WorkloadInfo, ws *rpc.WorkloadInfoSnapshot) map[workloadDeltaKey]*rpc.WorkloadInfo {
+	if !ws.Delta {
+		state = make(map[workloadDeltaKey]*rpc.WorkloadInfo, len(ws.Workloads))
+	}
+	for _, wi := range ws.Removed {
+		delete(state, deltaKey(wi))
+	}
+	for _, wi := range ws.Workloads {
+		state[deltaKey(wi)] = wi
+	}
+	return state
+}
+
+func workloadState(wiz []*rpc.WorkloadInfo) []*rpc.WorkloadInfo {
+	wiz = append([]*rpc.WorkloadInfo(nil), wiz...)
+	sortWorkloadInfos(wiz)
+	return wiz
+}
+
+func Test_workloadDeltas_reduce(t *testing.T) {
+	wl := func(name, ns, kind, reason string) *rpc.WorkloadInfo {
+		return &rpc.WorkloadInfo{Name: name, Namespace: ns, WorkloadResourceType: kind, NotInterceptableReason: reason, Uid: name + "-" + ns}
+	}
+	snapshots := [][]*rpc.WorkloadInfo{
+		{wl("echo", "a", "Deployment", ""), wl("echo", "a", "StatefulSet", ""), wl("zeta", "b", "Deployment", "")},
+		{wl("echo", "a", "Deployment", ""), wl("echo", "a", "StatefulSet", ""), wl("zeta", "b", "Deployment", "")},
+		{wl("echo", "a", "Deployment", "progressing"), wl("echo", "a", "StatefulSet", ""), wl("zeta", "b", "Deployment", "")},
+		{wl("echo", "a", "Deployment", "progressing"), wl("zeta", "b", "Deployment", ""), wl("alpha", "b", "Deployment", "")},
+		{},
+		{wl("echo", "a", "Deployment", "")},
+	}
+
+	wd := &workloadDeltas{}
+	var state map[workloadDeltaKey]*rpc.WorkloadInfo
+	for i, full := range snapshots {
+		ws := wd.reduce(&rpc.WorkloadInfoSnapshot{Workloads: full})
+		assert.Equal(t, i > 0, ws.Delta, "snapshot %d", i)
+		state = applyWorkloadDelta(state, ws)
+
+		var reconstructed []*rpc.WorkloadInfo
+		for _, wi := range state {
+			reconstructed = append(reconstructed, wi)
+		}
+		expected, actual := workloadState(full), workloadState(reconstructed)
+		require.Len(t, actual, len(expected), "snapshot %d", i)
+		for j := range expected {
+			assert.True(t, proto.Equal(expected[j], actual[j]), "snapshot %d, workload %d", i, j)
+		}
+	}
+}
+
+func Test_workloadDeltas_reduce_content(t *testing.T) {
+	echo := &rpc.WorkloadInfo{Name: "echo", Namespace: "a", WorkloadResourceType: "Deployment", Uid: "1", AgentVersion: "2.21.0"}
+	zeta := &rpc.WorkloadInfo{Name: "zeta", Namespace: "a", WorkloadResourceType: "Deployment", Uid: "2"}
+	wd := &workloadDeltas{}
+	wd.reduce(&rpc.WorkloadInfoSnapshot{Workloads: []*rpc.WorkloadInfo{echo, zeta}})
+
+	// Unchanged workloads yield an empty delta.
+	ws := wd.reduce(&rpc.WorkloadInfoSnapshot{Workloads: []*rpc.WorkloadInfo{echo, zeta}})
+	assert.True(t, isEmptyDelta(ws))
+
+	// A delta that reports a synced namespace isn't empty.
+	ws = wd.reduce(&rpc.WorkloadInfoSnapshot{Workloads: []*rpc.WorkloadInfo{echo, zeta}, SyncedNamespaces: []string{"a"}})
+	assert.False(t, isEmptyDelta(ws))
+
+	// Removals are signaled explicitly, with the identifying fields only.
+	ws = wd.reduce(&rpc.WorkloadInfoSnapshot{Workloads: []*rpc.WorkloadInfo{zeta}})
+	assert.Empty(t, ws.Workloads)
+	require.Len(t, ws.Removed, 1)
+	assert.True(t, proto.Equal(&rpc.WorkloadInfo{Name: "echo", Namespace: "a", WorkloadResourceType: "Deployment", Uid: "1"}, ws.Removed[0]))
+}
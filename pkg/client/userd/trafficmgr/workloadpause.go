@@ -0,0 +1,111 @@
+package trafficmgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// maxWorkloadWatchPause is the longest pause that PauseWorkloadWatchFor makes, so that a client that never
+// resumes cannot silence the workload watch for the rest of the session.
+const maxWorkloadWatchPause = 10 * time.Minute
+
+// PauseWorkloadWatch stops the notifications of the streams of WatchWorkloads, e.g. during a large helm
+// upgrade or rollout where the intermediate states of the workloads are of no interest. The workload
+// watchers keep running while paused, so the workloads of the session are kept up to date and no watch
+// stream of the traffic-manager is dropped. Calls can be nested, and each call must be matched by a call
+// to ResumeWorkloadWatch.
+func (s *session) PauseWorkloadWatch(ctx context.Context) {
+	s.workloadsLock.Lock()
+	defer s.workloadsLock.Unlock()
+	s.workloadWatchPauses++
+	if s.workloadWatchPauses == 1 {
+		dlog.Debug(ctx, "workload watch paused")
+	}
+}
+
+// ResumeWorkloadWatch undoes a call to PauseWorkloadWatch. Once all calls have been undone, each stream of
+// WatchWorkloads sends a single snapshot that reflects all changes that were made while it was paused.
+func (s *session) ResumeWorkloadWatch(ctx context.Context) {
+	s.workloadsLock.Lock()
+	defer s.workloadsLock.Unlock()
+	switch s.workloadWatchPauses {
+	case 0:
+		dlog.Warn(ctx, "workload watch resumed without being paused")
+	case 1:
+		s.workloadWatchPauses = 0
+		dlog.Debug(ctx, "workload watch resumed")
+		s.notifyWorkloadSubscribersLocked()
+	default:
+		s.workloadWatchPauses--
+	}
+}
+
+// PauseWorkloadWatchFor pauses the workload watch like PauseWorkloadWatch. The pause ends when
+// ResumeWorkloadWatchPause is called with the returned ID, or automatically when the given duration has passed.
+// The duration is capped at maxWorkloadWatchPause, and maxWorkloadWatchPause is used when it's zero. The given
+// context must be the one of the session, because the pause also ends when it's done. The ID and the duration of
+// the pause are returned.
+func (s *session) PauseWorkloadWatchFor(ctx context.Context, d time.Duration) (string, time.Duration) {
+	if d <= 0 || d > maxWorkloadWatchPause {
+		d = maxWorkloadWatchPause
+	}
+	id := uuid.NewString()
+	resume := make(chan struct{})
+	s.workloadsLock.Lock()
+	if s.workloadWatchPauseIDs == nil {
+		s.workloadWatchPauseIDs = make(map[string]chan struct{})
+	}
+	s.workloadWatchPauseIDs[id] = resume
+	s.workloadsLock.Unlock()
+	s.PauseWorkloadWatch(ctx)
+
+	t := client.GetClock(ctx).NewTimer(d)
+	go func() {
+		defer t.Stop()
+		select {
+		case <-resume:
+		case <-ctx.Done():
+		case <-t.C():
+			s.workloadsLock.Lock()
+			delete(s.workloadWatchPauseIDs, id)
+			s.workloadsLock.Unlock()
+			dlog.Infof(ctx, "workload watch pause %s ended after %s", id, d)
+		}
+		s.ResumeWorkloadWatch(ctx)
+	}()
+	return id, d
+}
+
+// ResumeWorkloadWatchPause ends the pause with the given ID that was made using PauseWorkloadWatchFor. It returns
+// false if no such pause exists, e.g. because it has already ended.
+func (s *session) ResumeWorkloadWatchPause(_ context.Context, id string) bool {
+	s.workloadsLock.Lock()
+	resume, ok := s.workloadWatchPauseIDs[id]
+	delete(s.workloadWatchPauseIDs, id)
+	s.workloadsLock.Unlock()
+	if ok {
+		close(resume)
+	}
+	return ok
+}
+
+// notifyWorkloadSubscribersLocked tells the streams of WatchWorkloads to send a new snapshot, unless the
+// workload watch is paused. A notification is dropped when a stream already has one pending, because
+// the snapshot that it sends for that one will include the new changes. Must be called with the
+// workloadsLock held.
+func (s *session) notifyWorkloadSubscribersLocked() {
+	if s.workloadWatchPauses > 0 {
+		return
+	}
+	for _, subscriber := range s.workloadSubscribers {
+		select {
+		case subscriber <- struct{}{}:
+		default:
+		}
+	}
+}
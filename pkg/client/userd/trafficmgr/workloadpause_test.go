@@ -0,0 +1,87 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func Test_session_PauseWorkloadWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	mgr := &steppedWorkloadsManager{deltas: make(chan *manager.WorkloadEventsDelta)}
+	sub := make(chan struct{}, 1)
+	s := &session{
+		managerClient:       mgr,
+		managerVersion:      semver.MustParse("2.21.0"),
+		workloads:           make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces:    make(map[string]struct{}),
+		workloadSubscribers: map[uuid.UUID]chan struct{}{uuid.New(): sub},
+	}
+	go func() {
+		_ = s.workloadsWatcher(ctx, "a", nil)
+	}()
+
+	// step delivers the given events, and returns true if the subscriber was notified. The empty delta that
+	// follows the events is received once the events have been handled.
+	step := func(evs ...*manager.WorkloadEvent) bool {
+		mgr.deltas <- &manager.WorkloadEventsDelta{Events: evs}
+		mgr.deltas <- &manager.WorkloadEventsDelta{}
+		return notified(sub)
+	}
+	wl := func(tp manager.WorkloadEvent_Type, name string) *manager.WorkloadEvent {
+		return &manager.WorkloadEvent{Type: tp, Workload: &manager.WorkloadInfo{Kind: manager.WorkloadInfo_DEPLOYMENT, Name: name}}
+	}
+	names := func() []string {
+		wiz, _ := s.getInfosForWorkloads([]string{"a"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+		ns := make([]string, len(wiz))
+		for i, wi := range wiz {
+			ns[i] = wi.Name
+		}
+		return ns
+	}
+
+	assert.True(t, step(wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "echo")))
+
+	// The subscriber isn't notified while paused, but the workloads are kept up to date.
+	s.PauseWorkloadWatch(ctx)
+	assert.False(t, step(wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "alpha")))
+	assert.False(t, step(wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "beta")))
+	assert.False(t, step(wl(manager.WorkloadEvent_DELETED, "echo")))
+	assert.Equal(t, []string{"alpha", "beta"}, names())
+
+	// Pauses nest, so the watch is resumed when the last pause is undone.
+	s.PauseWorkloadWatch(ctx)
+	s.ResumeWorkloadWatch(ctx)
+	assert.False(t, notified(sub))
+	assert.False(t, step(wl(manager.WorkloadEvent_MODIFIED, "alpha")))
+
+	// A single notification is sent on resume, and the snapshot that it results in has the latest state.
+	s.ResumeWorkloadWatch(ctx)
+	assert.True(t, notified(sub))
+	assert.False(t, notified(sub))
+	assert.Equal(t, []string{"alpha", "beta"}, names())
+
+	// Changes notify the subscriber again after the resume, and resuming without a pause is harmless.
+	assert.True(t, step(wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "gamma")))
+	s.ResumeWorkloadWatch(ctx)
+	assert.False(t, notified(sub))
+	assert.True(t, step(wl(manager.WorkloadEvent_DELETED, "gamma")))
+}
+
+// notified returns true if the given subscriber has a pending notification, and consumes it.
+func notified(sub <-chan struct{}) bool {
+	select {
+	case <-sub:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,617 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/workload"
+)
+
+func Test_session_getInfosForWorkloads_limit(t *testing.T) {
+	available := workloadInfo{state: workload.StateAvailable}
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}:  available,
+		{kind: manager.WorkloadInfo_STATEFULSET, namespace: "a", name: "echo"}: available,
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "zeta"}:  available,
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "b", name: "echo"}:  available,
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "b", name: "alpha"}: available,
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "c", name: "beta"}:  available,
+	}}
+	names := func(wiz []*rpc.WorkloadInfo) []string {
+		ns := make([]string, len(wiz))
+		for i, wi := range wiz {
+			ns[i] = wi.WorkloadResourceType + ":" + wi.Name + "." + wi.Namespace
+		}
+		return ns
+	}
+	nss := []string{"a", "b"}
+
+	wiz, total := s.getInfosForWorkloads(nss, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []string{
+		"DEPLOYMENT:alpha.b",
+		"DEPLOYMENT:echo.a",
+		"STATEFULSET:echo.a",
+		"DEPLOYMENT:echo.b",
+		"DEPLOYMENT:zeta.a",
+	}, names(wiz))
+
+	for i := 0; i < 10; i++ {
+		wiz, total = s.getInfosForWorkloads(nss, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 3)
+		assert.Equal(t, 5, total)
+		assert.Equal(t, []string{
+			"DEPLOYMENT:alpha.b",
+			"DEPLOYMENT:echo.a",
+			"STATEFULSET:echo.a",
+		}, names(wiz))
+	}
+
+	wiz, total = s.getInfosForWorkloads(nss, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 10)
+	assert.Equal(t, 5, total)
+	assert.Len(t, wiz, 5)
+}
+
+func Test_session_getInfosForWorkloads_kinds(t *testing.T) {
+	available := workloadInfo{state: workload.StateAvailable}
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}:         available,
+		{kind: manager.WorkloadInfo_REPLICASET, namespace: "a", name: "echo-5d8f7"}:   available,
+		{kind: manager.WorkloadInfo_STATEFULSET, namespace: "a", name: "db"}:          available,
+		{kind: manager.WorkloadInfo_REPLICASET, namespace: "a", name: "standalone-1"}: available,
+	}}
+	names := func(kinds []manager.WorkloadInfo_Kind) []string {
+		wiz, total := s.getInfosForWorkloads([]string{"a"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, kinds, 0)
+		assert.Equal(t, len(wiz), total)
+		ns := make([]string, len(wiz))
+		for i, wi := range wiz {
+			ns[i] = wi.Name
+		}
+		return ns
+	}
+	ctx := client.WithConfig(dlog.NewTestContext(t, false), client.GetDefaultConfig())
+
+	// ReplicaSets are excluded by default.
+	assert.Equal(t, []string{"db", "echo"}, names(listKinds(ctx, nil)))
+
+	// They are included when requested explicitly.
+	assert.Equal(t, []string{"echo-5d8f7", "standalone-1"}, names(listKinds(ctx, []string{"replicaset"})))
+	assert.Equal(t, []string{"db", "echo", "echo-5d8f7", "standalone-1"}, names(listKinds(ctx, []string{"Deployment", "ReplicaSet", "StatefulSet"})))
+
+	// The list.defaultKinds of the config replaces the default, and explicit kinds replace the config.
+	client.GetConfig(ctx).List().DefaultKinds = []string{"StatefulSet", "ReplicaSet"}
+	assert.Equal(t, []string{"db", "echo-5d8f7", "standalone-1"}, names(listKinds(ctx, nil)))
+	assert.Equal(t, []string{"echo"}, names(listKinds(ctx, []string{"Deployment"})))
+}
+
+func Test_setNamespaceAliases(t *testing.T) {
+	available := workloadInfo{state: workload.StateAvailable}
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "prod-team-xyz-1234", name: "echo"}: available,
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}:                  available,
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "b", name: "alpha"}:                 available,
+	}}
+	wiz, _ := s.getInfosForWorkloads([]string{"a", "b", "prod-team-xyz-1234"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+	setNamespaceAliases(wiz, map[string]string{"prod-team-xyz-1234": "prod", "b": "zz"})
+
+	// The real namespaces are retained, and the order is still decided by them.
+	type nsAndAlias struct{ ns, alias string }
+	got := make([]nsAndAlias, len(wiz))
+	for i, wi := range wiz {
+		got[i] = nsAndAlias{wi.Namespace, wi.NamespaceAlias}
+	}
+	assert.Equal(t, []nsAndAlias{{"b", "zz"}, {"a", ""}, {"prod-team-xyz-1234", "prod"}}, got)
+}
+
+func Test_notInterceptable(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   manager.WorkloadInfo_Kind
+		state  workload.State
+		code   rpc.WorkloadInfo_NotInterceptableCode
+		reason string
+	}{
+		{"available", manager.WorkloadInfo_DEPLOYMENT, workload.StateAvailable, rpc.WorkloadInfo_INTERCEPTABLE, ""},
+		{"available rollout", manager.WorkloadInfo_ROLLOUT, workload.StateAvailable, rpc.WorkloadInfo_INTERCEPTABLE, ""},
+		{"progressing", manager.WorkloadInfo_STATEFULSET, workload.StateProgressing, rpc.WorkloadInfo_PROGRESSING, "Progressing"},
+		{"failure", manager.WorkloadInfo_REPLICASET, workload.StateFailure, rpc.WorkloadInfo_NOT_AVAILABLE, "Failure"},
+		{"unknown", manager.WorkloadInfo_DEPLOYMENT, workload.StateUnknown, rpc.WorkloadInfo_NOT_AVAILABLE, "Unknown"},
+		{"unsupported kind", manager.WorkloadInfo_UNSPECIFIED, workload.StateAvailable, rpc.WorkloadInfo_UNSUPPORTED_KIND, "Unsupported kind UNSPECIFIED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, reason := notInterceptable(tt.kind, tt.state)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+
+	s := &session{workloads: map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}: {state: workload.StateProgressing},
+	}}
+	wiz, _ := s.getInfosForWorkloads([]string{"a"}, nil, nil, nil, rpc.ListRequest_EVERYTHING, nil, 0)
+	assert.Equal(t, rpc.WorkloadInfo_PROGRESSING, wiz[0].NotInterceptableCode)
+	assert.Equal(t, "Progressing", wiz[0].NotInterceptableReason)
+}
+
+func Test_session_pruneWorkloads(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	aCtx, aCancel := context.WithCancel(ctx)
+	defer aCancel()
+	bCtx, bCancel := context.WithCancel(ctx)
+	defer bCancel()
+
+	available := workloadInfo{state: workload.StateAvailable}
+	sub := make(chan struct{}, 1)
+	s := &session{
+		workloads: map[workloadInfoKey]workloadInfo{
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}: available,
+			{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "b", name: "echo"}: available,
+		},
+		syncedNamespaces:    map[string]struct{}{"a": {}, "b": {}},
+		watcherCancels:      map[string]context.CancelFunc{"a": aCancel, "b": bCancel},
+		watchErrors:         map[string]error{"b": errors.New("boom")},
+		workloadSubscribers: map[uuid.UUID]chan struct{}{uuid.New(): sub},
+	}
+
+	// Namespace "b" is deleted.
+	assert.Equal(t, []string{"b"}, s.pruneWorkloads([]string{"a"}))
+	assert.Error(t, bCtx.Err(), "watcher for b should be stopped")
+	assert.NoError(t, aCtx.Err())
+	assert.True(t, s.IsNamespaceWatched("a"))
+	assert.False(t, s.IsNamespaceWatched("b"))
+	assert.NoError(t, s.NamespaceWatchError("b"))
+	assert.Len(t, s.workloads, 1)
+	select {
+	case <-sub:
+	default:
+		t.Fatal("subscriber was not notified")
+	}
+
+	// Namespace "b" is recreated. Nothing is pruned, and the new watcher and its workloads stay put.
+	_, bCancel = context.WithCancel(ctx)
+	s.watcherCancels["b"] = bCancel
+	s.syncedNamespaces["b"] = struct{}{}
+	s.workloads[workloadInfoKey{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "b", name: "echo"}] = available
+	assert.Nil(t, s.pruneWorkloads([]string{"a", "b"}))
+	assert.True(t, s.IsNamespaceWatched("b"))
+	assert.Len(t, s.workloads, 2)
+	select {
+	case <-sub:
+		t.Fatal("subscriber should not be notified when nothing is pruned")
+	default:
+	}
+}
+
+func Test_session_ensureWatchers_concurrent(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	mgr := &fakeWorkloadsManager{release: make(chan struct{})}
+	s := &session{
+		managerClient:    mgr,
+		managerVersion:   semver.MustParse("2.21.0"),
+		workloads:        make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces: make(map[string]struct{}),
+	}
+
+	ensure := func() <-chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			s.ensureWatchers(ctx, []string{"a"})
+			close(done)
+		}()
+		return done
+	}
+	done1 := ensure()
+	assert.Eventually(t, func() bool { return mgr.watches.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	// A second caller waits for the running watcher rather than replacing it.
+	done2 := ensure()
+	for _, done := range []<-chan struct{}{done1, done2} {
+		select {
+		case <-done:
+			t.Fatal("ensureWatchers returned before the watcher synced")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	close(mgr.release)
+	for _, done := range []<-chan struct{}{done1, done2} {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("ensureWatchers didn't return when the watcher synced")
+		}
+	}
+	assert.Equal(t, int32(1), mgr.watches.Load())
+	assert.True(t, s.IsNamespaceWatched("a"))
+}
+
+func Test_session_ensureWatchers_backoff(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(client.WithClock(dlog.NewTestContext(t, false), fc))
+	defer cancel()
+	mgr := &failingWorkloadsManager{fakeWorkloadsManager: fakeWorkloadsManager{release: make(chan struct{})}}
+	mgr.failing.Store(true)
+	s := &session{
+		managerClient:    mgr,
+		managerVersion:   semver.MustParse("2.21.0"),
+		workloads:        make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces: make(map[string]struct{}),
+	}
+	retry := func() *rpc.WatcherRetry {
+		if wrs := s.getWatcherRetries(); len(wrs) == 1 {
+			return wrs[0]
+		}
+		return nil
+	}
+
+	var delays []time.Duration
+	s.ensureWatchers(ctx, []string{"a"})
+	for i := int32(1); i <= 9; i++ {
+		// The failure is recorded, and the retry is scheduled, when the watcher has ended.
+		require.Eventually(t, func() bool {
+			wr := retry()
+			return wr != nil && wr.Failures == i && fc.HasWaiters()
+		}, 5*time.Second, time.Millisecond)
+		require.Equal(t, i, mgr.watches.Load())
+		wr := retry()
+		assert.Equal(t, "a", wr.Namespace)
+		assert.Contains(t, wr.Error, "workloads is forbidden")
+		delay := wr.RetryAt.AsTime().Sub(fc.Now())
+		delays = append(delays, delay)
+
+		// The watcher isn't restarted until its backoff has expired.
+		s.ensureWatchers(ctx, []string{"a"})
+		fc.Step(delay - time.Millisecond)
+		s.ensureWatchers(ctx, []string{"a"})
+		assert.Equal(t, i, mgr.watches.Load())
+		if i == 9 {
+			// A successful sync resets the backoff.
+			mgr.failing.Store(false)
+			close(mgr.release)
+		}
+
+		// The watcher is restarted by the scheduled retry when its backoff has expired.
+		fc.Step(time.Millisecond)
+		require.Eventually(t, func() bool { return mgr.watches.Load() == i+1 }, 5*time.Second, time.Millisecond)
+	}
+	assert.Equal(t, []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		32 * time.Second,
+		64 * time.Second,
+		watcherBackoffMax,
+		watcherBackoffMax,
+	}, delays)
+
+	assert.Eventually(t, func() bool { return s.IsNamespaceWatched("a") }, 5*time.Second, time.Millisecond)
+	assert.Empty(t, s.getWatcherRetries())
+	assert.Equal(t, int32(10), mgr.watches.Load())
+}
+
+func Test_session_ensureWatchers_retryDropped(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(client.WithClock(dlog.NewTestContext(t, false), fc))
+	defer cancel()
+	mgr := &failingWorkloadsManager{fakeWorkloadsManager: fakeWorkloadsManager{release: make(chan struct{})}}
+	mgr.failing.Store(true)
+	s := &session{
+		managerClient:    mgr,
+		managerVersion:   semver.MustParse("2.21.0"),
+		workloads:        make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces: make(map[string]struct{}),
+	}
+	s.ensureWatchers(ctx, []string{"a"})
+	require.Eventually(t, fc.HasWaiters, 5*time.Second, time.Millisecond)
+
+	// The scheduled retry is dropped when the namespace is pruned.
+	s.pruneWorkloads(nil)
+	require.Eventually(t, func() bool { return !fc.HasWaiters() }, 5*time.Second, time.Millisecond)
+	fc.Step(watcherBackoffMax)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), mgr.watches.Load())
+	assert.Empty(t, s.getWatcherRetries())
+}
+
+func Test_dropDeletedNamespaces(t *testing.T) {
+	remaining, dropped := dropDeletedNamespaces([]string{"a", "b", "c"}, []string{"b", "x"})
+	assert.Equal(t, []string{"a", "c"}, remaining)
+	assert.Equal(t, []string{"b"}, dropped)
+
+	remaining, dropped = dropDeletedNamespaces([]string{"a", "b"}, nil)
+	assert.Equal(t, []string{"a", "b"}, remaining)
+	assert.Empty(t, dropped)
+
+	// The last mapped namespace is kept, because no mapped namespaces means all namespaces.
+	remaining, dropped = dropDeletedNamespaces([]string{"a"}, []string{"a"})
+	assert.Equal(t, []string{"a"}, remaining)
+	assert.Empty(t, dropped)
+}
+
+func Test_namespaceChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  []string
+		after   []string
+		added   []string
+		removed []string
+	}{
+		{"unchanged", []string{"a", "b"}, []string{"a", "b"}, nil, nil},
+		{"added", []string{"a"}, []string{"a", "b", "c"}, []string{"b", "c"}, nil},
+		{"removed", []string{"a", "b", "c"}, []string{"b"}, nil, []string{"a", "c"}},
+		{"replaced", []string{"a", "c"}, []string{"b", "c", "d"}, []string{"b", "d"}, []string{"a"}},
+		{"from none", nil, []string{"a"}, []string{"a"}, nil},
+		{"to none", []string{"a"}, nil, nil, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := namespaceChanges(tt.before, tt.after)
+			assert.Equal(t, tt.added, added)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+}
+
+func Test_session_ReapplyMappedNamespaces(t *testing.T) {
+	rd := &fakeRootDaemon{}
+	ctx, s, _ := newTestSession(t, &fakeManager{}, rd)
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "a"}}
+	s.SetMappedNamespaces(ctx, []string{"a", "b"})
+	s.ingressInfo = []*manager.IngressInfo{{Host: "echo.a", L5Host: "echo.example.com", Port: 443}}
+	s.ingressInfoRefreshed = time.Now()
+
+	// The workload watcher of the namespace that is added has synced.
+	s.syncedNamespaces["c"] = struct{}{}
+
+	// The namespaces of an edited config are applied live.
+	client.GetConfig(ctx).Cluster().MappedNamespaces = []string{"c", "b"}
+	added, removed, err := s.ReapplyMappedNamespaces(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, added)
+	assert.Equal(t, []string{"a"}, removed)
+	assert.Equal(t, []string{"b", "c"}, s.GetMappedNamespaces())
+	assert.Equal(t, []string{"b", "c"}, s.GetCurrentNamespaces(true))
+	assert.Nil(t, s.ingressInfo)
+	require.Len(t, rd.domains, 1)
+	assert.Equal(t, []string{"b", "c", client.DefaultServiceSubdomain}, rd.domains[0])
+
+	// Nothing happens when the namespaces of the config are already applied.
+	added, removed, err = s.ReapplyMappedNamespaces(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+	assert.Len(t, rd.domains, 1)
+}
+
+func Test_session_ApplyConfig_mappedNamespaces(t *testing.T) {
+	rd := &fakeRootDaemon{}
+	ctx, s, _ := newTestSession(t, &fakeManager{}, rd)
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "a"}}
+	s.SetMappedNamespaces(ctx, []string{"a", "b"})
+	s.configMappedNamespaces = []string{"a", "b"}
+	s.syncedNamespaces["c"] = struct{}{}
+
+	// A reloaded config with the same mapped namespaces leaves the session alone.
+	client.GetConfig(ctx).Cluster().MappedNamespaces = []string{"b", "a"}
+	require.NoError(t, s.ApplyConfig(ctx))
+	assert.Empty(t, rd.domains)
+
+	// A reloaded config with other mapped namespaces is applied to the session.
+	client.GetConfig(ctx).Cluster().MappedNamespaces = []string{"c", "b"}
+	require.NoError(t, s.ApplyConfig(ctx))
+	assert.Equal(t, []string{"b", "c"}, s.GetMappedNamespaces())
+	require.Len(t, rd.domains, 1)
+	assert.Equal(t, []string{"b", "c", client.DefaultServiceSubdomain}, rd.domains[0])
+}
+
+func Test_session_prewarmWatchers(t *testing.T) {
+	cs := fake.NewClientset()
+	cs.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &auth.SelfSubjectRulesReview{Status: auth.SubjectRulesReviewStatus{
+			ResourceRules: []auth.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		}}, nil
+	})
+	ctx, cancel := context.WithCancel(k8sapi.WithJoinedClientSetInterface(dlog.NewTestContext(t, false), cs, argorolloutsfake.NewSimpleClientset()))
+	defer cancel()
+
+	mgr := &fakeWorkloadsManager{release: make(chan struct{})}
+	close(mgr.release)
+	s := &session{
+		Cluster:          &k8s.Cluster{Kubeconfig: &client.Kubeconfig{}},
+		managerClient:    mgr,
+		managerVersion:   semver.MustParse("2.21.0"),
+		workloads:        make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces: make(map[string]struct{}),
+	}
+	cfg := client.GetDefaultConfig()
+	s.SetMappedNamespaces(client.WithConfig(ctx, cfg), []string{"a", "b"})
+
+	// Nothing is watched unless prewarm is enabled.
+	assert.NoError(t, s.prewarmWatchers(client.WithConfig(ctx, cfg)))
+	assert.Zero(t, mgr.watches.Load())
+
+	cfg.Workloads().Prewarm = true
+	assert.NoError(t, s.prewarmWatchers(client.WithConfig(ctx, cfg)))
+	assert.Equal(t, int32(2), mgr.watches.Load())
+	assert.True(t, s.IsNamespaceWatched("a"))
+	assert.True(t, s.IsNamespaceWatched("b"))
+}
+
+func Test_session_watchesWorkload(t *testing.T) {
+	s := &session{}
+	assert.True(t, s.watchesWorkload("anything"))
+
+	s.workloadPrefixes = []string{"frontend-", "web"}
+	assert.True(t, s.watchesWorkload("frontend-echo"))
+	assert.True(t, s.watchesWorkload("webapp"))
+	assert.False(t, s.watchesWorkload("frontend"))
+	assert.False(t, s.watchesWorkload("backend-echo"))
+}
+
+func Test_session_workloadsWatcher_prefixes(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	mgr := &steppedWorkloadsManager{deltas: make(chan *manager.WorkloadEventsDelta)}
+	sub := make(chan struct{}, 1)
+	s := &session{
+		managerClient:       mgr,
+		managerVersion:      semver.MustParse("2.21.0"),
+		workloads:           make(map[workloadInfoKey]workloadInfo),
+		syncedNamespaces:    make(map[string]struct{}),
+		workloadSubscribers: map[uuid.UUID]chan struct{}{uuid.New(): sub},
+		workloadPrefixes:    []string{"frontend-"},
+	}
+	go func() {
+		_ = s.workloadsWatcher(ctx, "a", nil)
+	}()
+
+	// step delivers the given events, and returns true if the subscriber was notified. The empty delta that
+	// follows the events is received once the events have been handled, and it never notifies.
+	step := func(evs ...*manager.WorkloadEvent) bool {
+		mgr.deltas <- &manager.WorkloadEventsDelta{Events: evs}
+		mgr.deltas <- &manager.WorkloadEventsDelta{}
+		select {
+		case <-sub:
+			return true
+		default:
+			return false
+		}
+	}
+	wl := func(tp manager.WorkloadEvent_Type, name string) *manager.WorkloadEvent {
+		return &manager.WorkloadEvent{Type: tp, Workload: &manager.WorkloadInfo{Kind: manager.WorkloadInfo_DEPLOYMENT, Name: name}}
+	}
+	names := func() []string {
+		s.workloadsLock.Lock()
+		defer s.workloadsLock.Unlock()
+		var ns []string
+		for key := range s.workloads {
+			ns = append(ns, key.name)
+		}
+		return ns
+	}
+
+	// Only the workloads that match a prefix are stored.
+	assert.True(t, step(
+		wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "frontend-echo"),
+		wl(manager.WorkloadEvent_ADDED_UNSPECIFIED, "backend-echo"),
+	))
+	assert.Equal(t, []string{"frontend-echo"}, names())
+	assert.True(t, s.IsNamespaceWatched("a"))
+
+	// Events for workloads that don't match are ignored, and don't notify the subscribers.
+	assert.False(t, step(wl(manager.WorkloadEvent_MODIFIED, "backend-echo")))
+	assert.False(t, step(wl(manager.WorkloadEvent_DELETED, "backend-echo")))
+	assert.Equal(t, []string{"frontend-echo"}, names())
+
+	assert.True(t, step(wl(manager.WorkloadEvent_DELETED, "frontend-echo")))
+	assert.Empty(t, names())
+}
+
+// snapshotStream is a WatchWorkloadsStream that cancels its context when it has received the first snapshot.
+type snapshotStream struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	snapshots []*rpc.WorkloadInfoSnapshot
+}
+
+func (s *snapshotStream) Send(ws *rpc.WorkloadInfoSnapshot) error {
+	s.snapshots = append(s.snapshots, ws)
+	s.cancel()
+	return nil
+}
+
+func (s *snapshotStream) Context() context.Context {
+	return s.ctx
+}
+
+func Test_session_WatchWorkloads_kinds(t *testing.T) {
+	ctx, s, _ := newTestSession(t, &fakeManager{}, &fakeRootDaemon{})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, fake.NewClientset(), argorolloutsfake.NewSimpleClientset())
+	s.Cluster = &k8s.Cluster{Kubeconfig: &client.Kubeconfig{Namespace: "a"}}
+	available := workloadInfo{state: workload.StateAvailable}
+	s.workloads = map[workloadInfoKey]workloadInfo{
+		{kind: manager.WorkloadInfo_DEPLOYMENT, namespace: "a", name: "echo"}:       available,
+		{kind: manager.WorkloadInfo_REPLICASET, namespace: "a", name: "echo-5d8f7"}: available,
+	}
+	s.syncedNamespaces = map[string]struct{}{"a": {}}
+	t.Cleanup(func() { s.pruneNamespaceInformers(nil) })
+
+	names := func(kinds []string) []string {
+		sCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream := &snapshotStream{ctx: sCtx, cancel: cancel}
+		require.NoError(t, s.WatchWorkloads(sCtx, &rpc.WatchWorkloadsRequest{Namespaces: []string{"a"}, Kinds: kinds}, stream))
+		require.Len(t, stream.snapshots, 1)
+		var ns []string
+		for _, wi := range stream.snapshots[0].Workloads {
+			ns = append(ns, wi.Name)
+		}
+		return ns
+	}
+
+	// The ReplicaSets are only watched when asked for.
+	assert.Equal(t, []string{"echo"}, names(nil))
+	assert.Equal(t, []string{"echo-5d8f7"}, names([]string{"ReplicaSet"}))
+}
+
+func Test_session_PauseWorkloadWatchFor(t *testing.T) {
+	fc := clocktesting.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(client.WithClock(dlog.NewTestContext(t, false), fc))
+	defer cancel()
+	sub := make(chan struct{}, 1)
+	s := &session{workloadSubscribers: map[uuid.UUID]chan struct{}{uuid.New(): sub}}
+
+	// A pause ends when it's resumed using its ID, and only once.
+	id, d := s.PauseWorkloadWatchFor(ctx, time.Minute)
+	assert.Equal(t, time.Minute, d)
+	s.workloadsLock.Lock()
+	s.notifyWorkloadSubscribersLocked()
+	s.workloadsLock.Unlock()
+	assert.False(t, notified(sub))
+	assert.True(t, s.ResumeWorkloadWatchPause(ctx, id))
+	require.Eventually(t, func() bool { return notified(sub) }, 5*time.Second, time.Millisecond)
+	assert.False(t, s.ResumeWorkloadWatchPause(ctx, id))
+
+	// A pause ends automatically when its duration has passed, and is capped at the maximum pause.
+	id, d = s.PauseWorkloadWatchFor(ctx, time.Hour)
+	assert.Equal(t, maxWorkloadWatchPause, d)
+	waitForWaiters(t, fc)
+	fc.Step(maxWorkloadWatchPause)
+	require.Eventually(t, func() bool { return notified(sub) }, 5*time.Second, time.Millisecond)
+	assert.False(t, s.ResumeWorkloadWatchPause(ctx, id))
+}
@@ -1,8 +1,7 @@
-package env
+package envsyntax
 
 import (
-	"bufio"
-	"errors"
+	"bytes"
 	"fmt"
 	"os"
 	"slices"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/go-json-experiment/json"
 	"github.com/go-json-experiment/json/jsontext"
+	"sigs.k8s.io/yaml"
 
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/shellquote"
@@ -29,6 +29,8 @@ const (
 	SyntaxPSExport
 	SyntaxCmd
 	SyntaxJSON
+	SyntaxYAML
+	SyntaxDotenv
 )
 
 var syntaxNames = []string{ //nolint:gochecknoglobals // constant
@@ -42,10 +44,12 @@ var syntaxNames = []string{ //nolint:gochecknoglobals // constant
 	"ps:export",
 	"cmd",
 	"json",
+	"yaml",
+	"dotenv",
 }
 
 func SyntaxUsage() string {
-	return `"docker", "compose", "sh", "csh", "cmd", "json", and "ps"; where "sh", "csh", and "ps" can be suffixed with ":export"`
+	return `"docker", "compose", "dotenv", "sh", "csh", "cmd", "json", "yaml", and "ps"; where "sh", "csh", and "ps" can be suffixed with ":export"`
 }
 
 // Set uses a pointer receiver intentionally, even though the internal type is int, because
@@ -63,7 +67,7 @@ func (e *Syntax) Set(n string) error {
 
 //goland:noinspection GoMixedReceiverTypes
 func (e Syntax) String() string {
-	if e >= 0 && e <= SyntaxCmd {
+	if e >= 0 && int(e) < len(syntaxNames) {
 		return syntaxNames[e]
 	}
 	return "unknown"
@@ -74,8 +78,11 @@ func (e Syntax) Type() string {
 	return "string"
 }
 
+// WriteFile writes the given environment to the named file using this syntax. The environment is written to
+// stdout when the name is "-".
+//
 //goland:noinspection GoMixedReceiverTypes
-func (e Syntax) writeFile(fileName string, env map[string]string) error {
+func (e Syntax) WriteFile(fileName string, env map[string]string) error {
 	var file *os.File
 	if fileName == "-" {
 		file = os.Stdout
@@ -90,20 +97,33 @@ func (e Syntax) writeFile(fileName string, env map[string]string) error {
 }
 
 //goland:noinspection GoMixedReceiverTypes
-func (e Syntax) WriteToFileAndClose(file *os.File, env map[string]string) (err error) {
-	if e == SyntaxJSON {
-		data, err := json.Marshal(env, jsontext.WithIndent("  "))
+func (e Syntax) WriteToFileAndClose(file *os.File, env map[string]string) error {
+	defer file.Close()
+	data, err := e.Format(env)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+// Format returns the given environment in this syntax. The entries of all syntaxes but JSON and YAML are
+// written one per line, sorted by key.
+//
+//goland:noinspection GoMixedReceiverTypes
+func (e Syntax) Format(env map[string]string) ([]byte, error) {
+	switch e {
+	case SyntaxJSON:
+		data, err := json.Marshal(env, jsontext.WithIndent("  "), json.Deterministic(true))
 		if err != nil {
 			// Creating JSON from a map[string]string should never fail
 			panic(err)
 		}
-		_, err = file.Write(data)
-		return err
+		return data, nil
+	case SyntaxYAML:
+		return yaml.Marshal(env)
 	}
 
-	defer file.Close()
-	w := bufio.NewWriter(file)
-
 	keys := make([]string, len(env))
 	i := 0
 	for k := range env {
@@ -112,16 +132,16 @@ func (e Syntax) WriteToFileAndClose(file *os.File, env map[string]string) (err e
 	}
 	sort.Strings(keys)
 
+	var buf bytes.Buffer
 	for _, k := range keys {
 		r, err := e.WriteEntry(k, env[k])
 		if err != nil {
-			return err
-		}
-		if _, err = fmt.Fprintln(w, r); err != nil {
-			return err
+			return nil, err
 		}
+		buf.WriteString(r)
+		buf.WriteByte('\n')
 	}
-	return w.Flush()
+	return buf.Bytes(), nil
 }
 
 // WriteEntry will write the environment variable in a form that will make the target shell parse it correctly and verbatim.
@@ -135,7 +155,7 @@ func (e Syntax) WriteEntry(k, v string) (r string, err error) {
 			return "", fmt.Errorf("docker run/build does not support multi-line environment values: key: %s, value %s", k, v)
 		}
 		r = fmt.Sprintf("%s=%s", k, v)
-	case SyntaxCompose:
+	case SyntaxCompose, SyntaxDotenv:
 		r = fmt.Sprintf("%s=%s", k, quoteCompose(v))
 	case SyntaxSh:
 		r = fmt.Sprintf("%s=%s", k, shellquote.Unix(v))
@@ -154,8 +174,8 @@ func (e Syntax) WriteEntry(k, v string) (r string, err error) {
 			return "", fmt.Errorf("cmd does not support multi-line environment values: key: %s, value %s", k, v)
 		}
 		r = fmt.Sprintf("set %s=%s", k, v)
-	case SyntaxJSON:
-		return "", errors.New("WriteEntry isn't supported for json")
+	case SyntaxJSON, SyntaxYAML:
+		return "", fmt.Errorf("WriteEntry isn't supported for %s", e)
 	}
 	return r, nil
 }
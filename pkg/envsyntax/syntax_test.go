@@ -1,9 +1,10 @@
-package env
+package envsyntax
 
 import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
 )
 
 func TestSyntax_WriteEntry(t *testing.T) {
@@ -114,3 +115,56 @@ func TestSyntax_WriteEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestSyntax_Format(t *testing.T) {
+	env := map[string]string{
+		"B": "it's \"quoted\"",
+		"A": "line1\nline2",
+		"C": "plain",
+	}
+	tests := []struct {
+		syntax Syntax
+		want   string
+	}{
+		{
+			SyntaxDotenv,
+			"A=\"line1\\nline2\"\nB='it\\'s \"quoted\"'\nC=plain\n",
+		},
+		{
+			SyntaxShExport,
+			"export A='line1\nline2'\nexport B=it\\''s \"quoted\"'\nexport C=plain\n",
+		},
+		{
+			SyntaxJSON,
+			"{\n  \"A\": \"line1\\nline2\",\n  \"B\": \"it's \\\"quoted\\\"\",\n  \"C\": \"plain\"\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.syntax.String(), func(t *testing.T) {
+			data, err := tt.syntax.Format(env)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(data))
+		})
+	}
+
+	// The YAML representation of the values is left to the YAML encoder, so it's verified by decoding it.
+	data, err := SyntaxYAML.Format(env)
+	require.NoError(t, err)
+	var decoded map[string]string
+	require.NoError(t, yaml.Unmarshal(data, &decoded))
+	require.Equal(t, env, decoded)
+
+	// Docker env-files can't represent values with newlines.
+	_, err = SyntaxDocker.Format(env)
+	require.Error(t, err)
+}
+
+func TestSyntax_Set(t *testing.T) {
+	for _, name := range []string{"dotenv", "json", "yaml", "sh:export"} {
+		var s Syntax
+		require.NoError(t, s.Set(name))
+		require.Equal(t, name, s.String())
+	}
+	var s Syntax
+	require.Error(t, s.Set("toml"))
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
@@ -115,8 +116,14 @@ func (f *interceptor) SetIntercepting(intercept *manager.InterceptInfo) {
 		}
 	}
 
-	// Drop existing connections
-	f.tCancel()
+	// Drop existing connections, or let them drain when a removed intercept has a drain period.
+	if intercept == nil && f.intercept != nil && f.intercept.Spec.DrainPeriod > 0 {
+		drainPeriod := time.Duration(f.intercept.Spec.DrainPeriod)
+		dlog.Debugf(f.lCtx, "Draining connections of intercept %s for %s", iceptInfo(f.intercept), drainPeriod)
+		time.AfterFunc(drainPeriod, f.tCancel)
+	} else {
+		f.tCancel()
+	}
 
 	// Set up new target and lifetime
 	f.tCtx, f.tCancel = context.WithCancel(f.lCtx)
@@ -0,0 +1,95 @@
+package forwarder
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func startTCP(t *testing.T) *tcp {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	f := NewInterceptor(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}, "127.0.0.1", 8080).(*tcp)
+	initCh := make(chan net.Addr)
+	done := make(chan error)
+	go func() {
+		done <- f.Serve(ctx, initCh)
+	}()
+	<-initCh
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return f
+}
+
+// inFlight returns the context that a connection accepted by the forwarder right now would use.
+func inFlight(f *tcp) context.Context {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tCtx
+}
+
+func testIntercept(drainPeriod time.Duration) *manager.InterceptInfo {
+	return &manager.InterceptInfo{
+		Id: "abc:echo",
+		Spec: &manager.InterceptSpec{
+			Name:        "echo",
+			Client:      "client",
+			TargetPort:  8080,
+			DrainPeriod: int64(drainPeriod),
+		},
+	}
+}
+
+func Test_interceptor_SetIntercepting_drain(t *testing.T) {
+	f := startTCP(t)
+	f.SetIntercepting(testIntercept(200 * time.Millisecond))
+	conn := inFlight(f)
+
+	// The in-flight connection is kept while it drains, but new connections are no longer intercepted.
+	f.SetIntercepting(nil)
+	assert.Empty(t, f.InterceptId())
+	newConn := inFlight(f)
+	assert.NotEqual(t, conn, newConn)
+	assert.NoError(t, conn.Err())
+
+	// The in-flight connection is closed when the drain period expires.
+	select {
+	case <-conn.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "in-flight connection was not closed after the drain period")
+	}
+	assert.NoError(t, newConn.Err())
+}
+
+func Test_interceptor_SetIntercepting_noDrain(t *testing.T) {
+	f := startTCP(t)
+	f.SetIntercepting(testIntercept(0))
+	conn := inFlight(f)
+
+	// Without a drain period, the in-flight connection is closed right away.
+	f.SetIntercepting(nil)
+	assert.Error(t, conn.Err())
+	assert.NoError(t, inFlight(f).Err())
+}
+
+func Test_interceptor_SetIntercepting_replaced(t *testing.T) {
+	f := startTCP(t)
+	f.SetIntercepting(testIntercept(time.Minute))
+	conn := inFlight(f)
+
+	// The drain period only applies when an intercept is removed. Connections are closed right away when
+	// another intercept takes over.
+	next := testIntercept(0)
+	next.Id = "def:echo"
+	f.SetIntercepting(next)
+	assert.Error(t, conn.Err())
+	assert.Equal(t, "def:echo", f.InterceptId())
+}
@@ -0,0 +1,88 @@
+package forwarder
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// mirrorQueueSize is the number of reads from a mirrored connection that can be queued for the client of the
+// intercept before the mirror is given up.
+const mirrorQueueSize = 64
+
+// mirrorConn forwards the given connection to the target, just like when the port isn't intercepted, and sends
+// a copy of the data received from the connection to the client of the given intercept. What the client
+// responds is discarded, so the response always comes from the target.
+func (f *tcp) mirrorConn(ctx context.Context, conn *net.TCPConn, targetHost string, targetPort uint16, iCept *manager.InterceptInfo) error {
+	local, remote := net.Pipe()
+	m := &mirror{ch: make(chan []byte, mirrorQueueSize)}
+	go func() {
+		defer local.Close()
+		for data := range m.ch {
+			if _, err := local.Write(data); err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		// Discard the responses from the client.
+		_, _ = io.Copy(io.Discard, local)
+	}()
+	go func() {
+		defer remote.Close()
+		if err := f.interceptConn(ctx, &mirroredConn{Conn: remote, remoteAddr: conn.RemoteAddr()}, iCept); err != nil {
+			dlog.Errorf(ctx, "unable to mirror connection from %s: %v", conn.RemoteAddr(), err)
+		}
+	}()
+	return f.targetConn(ctx, conn, targetHost, targetPort, m)
+}
+
+// mirroredConn is the connection that is sent to the client of an intercept. It reports the remote address of
+// the connection that it mirrors.
+type mirroredConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+func (c *mirroredConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// mirror is an io.WriteCloser that queues copies of what's written to it. Writes never block or fail, so the
+// mirrored connection is never slowed down by the mirror. The queue is closed when it's full, which ends
+// the mirror.
+type mirror struct {
+	sync.Mutex
+	ch     chan []byte
+	closed bool
+}
+
+func (m *mirror) Write(data []byte) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	if !m.closed {
+		select {
+		case m.ch <- bytes.Clone(data):
+		default:
+			// The client can't keep up. A partial copy is better than an inconsistent one.
+			m.closed = true
+			close(m.ch)
+		}
+	}
+	return len(data), nil
+}
+
+func (m *mirror) Close() error {
+	m.Lock()
+	defer m.Unlock()
+	if !m.closed {
+		m.closed = true
+		close(m.ch)
+	}
+	return nil
+}
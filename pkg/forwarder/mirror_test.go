@@ -0,0 +1,149 @@
+package forwarder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// pipeStreamProvider creates client streams that are served by a dialer in the same process, so that the
+// connections of an intercept end up at the intercept's target host and port.
+type pipeStreamProvider struct {
+	err error
+}
+
+func (p *pipeStreamProvider) CreateClientStream(
+	ctx context.Context,
+	clientSessionID string,
+	id tunnel.ConnID,
+	_, _ time.Duration,
+) (tunnel.Stream, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	a, b := tunnel.NewPipe(id, clientSessionID)
+	tunnel.NewDialer(b, func() {}, nil, nil).Start(ctx)
+	return a, nil
+}
+
+func (p *pipeStreamProvider) ReportMetrics(context.Context, *manager.TunnelMetrics) {}
+
+// serveOnce starts a server that reads a message of the given size from one connection, sends it on the
+// returned channel, and responds with the message prefixed by the given prefix.
+func serveOnce(t *testing.T, size int, prefix string) (uint16, <-chan string) {
+	l, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		msg := make([]byte, size)
+		if _, err = io.ReadFull(conn, msg); err != nil {
+			return
+		}
+		received <- string(msg)
+		_, _ = conn.Write([]byte(prefix + string(msg)))
+	}()
+	return uint16(l.Addr().(*net.TCPAddr).Port), received
+}
+
+func startMirror(t *testing.T, clusterPort, localPort uint16, sp tunnel.ClientStreamProvider) net.Addr {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	f := NewInterceptor(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}, "127.0.0.1", clusterPort)
+	f.SetStreamProvider(sp)
+	initCh := make(chan net.Addr)
+	done := make(chan error)
+	go func() {
+		done <- f.Serve(ctx, initCh)
+	}()
+	addr := <-initCh
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	f.SetIntercepting(&manager.InterceptInfo{
+		Id:            "abc:echo",
+		ClientSession: &manager.SessionInfo{SessionId: "abc"},
+		Spec: &manager.InterceptSpec{
+			Name:       "echo",
+			Client:     "client",
+			TargetHost: "127.0.0.1",
+			TargetPort: int32(localPort),
+			Mirror:     true,
+		},
+	})
+	return addr
+}
+
+// request sends the given message to the given address and returns the response.
+func request(t *testing.T, addr net.Addr, msg string) string {
+	conn, err := net.DialTCP("tcp", nil, addr.(*net.TCPAddr))
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte(msg))
+	require.NoError(t, err)
+	rsp, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	return string(rsp)
+}
+
+func Test_tcp_mirror(t *testing.T) {
+	const msg = "hello"
+	clusterPort, clusterReceived := serveOnce(t, len(msg), "cluster: ")
+	localPort, localReceived := serveOnce(t, len(msg), "local: ")
+	addr := startMirror(t, clusterPort, localPort, &pipeStreamProvider{})
+
+	// The response comes from the cluster, and both the cluster and the local handler receive the request.
+	assert.Equal(t, "cluster: hello", request(t, addr, msg))
+	assert.Equal(t, msg, <-clusterReceived)
+	select {
+	case got := <-localReceived:
+		assert.Equal(t, msg, got)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the local handler didn't receive a copy of the request")
+	}
+}
+
+func Test_tcp_mirror_failing(t *testing.T) {
+	const msg = "hello"
+	clusterPort, clusterReceived := serveOnce(t, len(msg), "cluster: ")
+	addr := startMirror(t, clusterPort, 1, &pipeStreamProvider{err: errors.New("no stream")})
+
+	// A mirror that fails doesn't affect the traffic to the cluster.
+	assert.Equal(t, "cluster: hello", request(t, addr, msg))
+	assert.Equal(t, msg, <-clusterReceived)
+}
+
+func Test_mirror_overflow(t *testing.T) {
+	m := &mirror{ch: make(chan []byte, 2)}
+	for range 3 {
+		n, err := m.Write([]byte("data"))
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+	}
+
+	// The queue is closed when it overflows, and writes still succeed after that.
+	var got []string
+	for data := range m.ch {
+		got = append(got, string(data))
+	}
+	assert.Equal(t, []string{"data", "data"}, got)
+	_, err := m.Write([]byte("more"))
+	assert.NoError(t, err)
+	assert.NoError(t, m.Close())
+}
@@ -94,9 +94,20 @@ func (f *tcp) forwardConn(clientConn *net.TCPConn) error {
 	intercept := f.intercept
 	f.mu.Unlock()
 	if intercept != nil {
+		if intercept.Spec.Mirror {
+			return f.mirrorConn(ctx, clientConn, targetHost, targetPort, intercept)
+		}
 		return f.interceptConn(ctx, clientConn, intercept)
 	}
+	return f.targetConn(ctx, clientConn, targetHost, targetPort, nil)
+}
 
+// targetConn forwards the given connection to the target. Data received from the connection is also written
+// to the given mirror unless it is nil.
+func (f *tcp) targetConn(ctx context.Context, clientConn *net.TCPConn, targetHost string, targetPort uint16, mirror io.WriteCloser) error {
+	if mirror != nil {
+		defer mirror.Close()
+	}
 	targetAddr, err := net.ResolveTCPAddr("tcp", iputil.JoinHostPort(targetHost, targetPort))
 	if err != nil {
 		return fmt.Errorf("error on resolve(%s): %w", iputil.JoinHostPort(targetHost, targetPort), err)
@@ -118,10 +129,17 @@ func (f *tcp) forwardConn(clientConn *net.TCPConn) error {
 	done := make(chan struct{})
 
 	go func() {
-		if _, err := io.Copy(targetConn, clientConn); err != nil {
+		var dst io.Writer = targetConn
+		if mirror != nil {
+			dst = io.MultiWriter(targetConn, mirror)
+		}
+		if _, err := io.Copy(dst, clientConn); err != nil {
 			dlog.Debugf(ctx, "Error clientConn->targetConn: %+v", err)
 		}
 		_ = targetConn.CloseWrite()
+		if mirror != nil {
+			_ = mirror.Close()
+		}
 		done <- struct{}{}
 	}()
 	go func() {
@@ -78,7 +78,8 @@ func (f *udp) Serve(ctx context.Context, initCh chan<- net.Addr) error {
 
 func (f *udp) forward(ctx context.Context, conn *net.UDPConn, intercept *manager.InterceptInfo) error {
 	defer conn.Close()
-	if intercept != nil {
+	// UDP traffic isn't mirrored. It's served by the target when the intercept is a mirror.
+	if intercept != nil && !intercept.Spec.Mirror {
 		f.interceptConn(ctx, conn, intercept)
 		return nil
 	}
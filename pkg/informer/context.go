@@ -2,6 +2,7 @@ package informer
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/client-go/informers"
 
@@ -21,10 +22,16 @@ func getOpts(ns string) (k8sOpts []informers.SharedInformerOption, argoOpts []ar
 }
 
 func WithFactory(ctx context.Context, ns string) context.Context {
+	return WithResyncingFactory(ctx, ns, 0)
+}
+
+// WithResyncingFactory is like WithFactory but the informers of the factory will resync their caches at the
+// given interval. Informers never resync when the interval is zero.
+func WithResyncingFactory(ctx context.Context, ns string, resync time.Duration) context.Context {
 	k8sOpts, argoOpts := getOpts(ns)
 	i := k8sapi.GetJoinedClientSetInterface(ctx)
-	k8sFactory := informers.NewSharedInformerFactoryWithOptions(i, 0, k8sOpts...)
-	argoRolloutFactory := argorolloutsinformer.NewSharedInformerFactoryWithOptions(i, 0, argoOpts...)
+	k8sFactory := informers.NewSharedInformerFactoryWithOptions(i, resync, k8sOpts...)
+	argoRolloutFactory := argorolloutsinformer.NewSharedInformerFactoryWithOptions(i, resync, argoOpts...)
 	return context.WithValue(ctx, factoryKey(ns), NewDefaultGlobalFactory(k8sFactory, argoRolloutFactory))
 }
 
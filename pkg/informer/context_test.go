@@ -0,0 +1,50 @@
+package informer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	argorolloutsfake "github.com/datawire/argo-rollouts-go-client/pkg/client/clientset/versioned/fake"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// countResyncs starts the pod informer of a factory created with the given resync period and returns the
+// number of updates that it delivers for an unchanged pod during the given duration.
+func countResyncs(t *testing.T, resync, during time.Duration) int32 {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	cs := fake.NewClientset(&core.Pod{ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "default"}})
+	ctx = k8sapi.WithJoinedClientSetInterface(ctx, cs, argorolloutsfake.NewSimpleClientset())
+	ctx = WithResyncingFactory(ctx, "default", resync)
+
+	kf := GetK8sFactory(ctx, "default")
+	var updates atomic.Int32
+	_, err := kf.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, _ any) { updates.Add(1) },
+	})
+	require.NoError(t, err)
+	kf.Start(ctx.Done())
+	kf.WaitForCacheSync(ctx.Done())
+	time.Sleep(during)
+	return updates.Load()
+}
+
+func TestWithResyncingFactory(t *testing.T) {
+	// The unchanged pod is delivered again each time the informer resyncs. Informers won't resync more
+	// often than once per second.
+	assert.Positive(t, countResyncs(t, time.Second, 2500*time.Millisecond))
+}
+
+func TestWithResyncingFactory_noResync(t *testing.T) {
+	assert.Zero(t, countResyncs(t, 0, 2500*time.Millisecond))
+}
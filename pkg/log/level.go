@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/datawire/dlib/dlog"
 )
 
 type setLogLevelContextKey struct{}
@@ -24,10 +26,18 @@ func SetLevel(ctx context.Context, logLevelStr string) {
 }
 
 // WithLevelSetter enables setting the log-level of the given Logger by using the returned context as
-// an argument to the SetLevel function.
+// an argument to the SetLevel function. The returned context logs to the given Logger, and the levels of
+// individual subsystems can be set using SetSubsystemLevels.
 func WithLevelSetter(ctx context.Context, logrusLogger *logrus.Logger) context.Context {
+	l := &levels{logger: logrusLogger}
+	l.base.Store(uint32(logrusLogger.Level))
+	l.subsystems.Store(&map[string]logrus.Level{})
+	ctx = context.WithValue(ctx, levelsContextKey{}, l)
+	ctx = dlog.WithLogger(ctx, &subsystemLogger{Logger: dlog.WrapLogrus(logrusLogger), levels: l})
 	return context.WithValue(ctx, setLogLevelContextKey{}, func(logLevelStr string) {
 		SetLogrusLevel(logrusLogger, logLevelStr, true)
+		l.base.Store(uint32(logrusLogger.Level))
+		l.raise()
 	})
 }
 
@@ -0,0 +1,148 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+// Names of the subsystems that can be given a log level of their own using the logLevels.subsystems
+// setting of the client configuration.
+const (
+	// SubsystemDNS is the DNS server of the root daemon.
+	SubsystemDNS = "dns"
+
+	// SubsystemWorkload is the workload watchers of the user daemon.
+	SubsystemWorkload = "workload"
+)
+
+// subsystemField is the field that WithSubsystem uses to tell a subsystemLogger which subsystem it logs for.
+const subsystemField = "subsystem"
+
+type levelsContextKey struct{}
+
+// levels keeps track of the level set using SetLevel, and of the levels of individual subsystems. The
+// logrus logger is set to the most verbose of those levels, and the subsystemLoggers drop the entries that
+// are more verbose than the level of their subsystem.
+type levels struct {
+	logger     *logrus.Logger
+	base       atomic.Uint32
+	subsystems atomic.Pointer[map[string]logrus.Level]
+}
+
+func (l *levels) level(subsystem string) logrus.Level {
+	if subsystem != "" {
+		if lv, ok := (*l.subsystems.Load())[subsystem]; ok {
+			return lv
+		}
+	}
+	return logrus.Level(l.base.Load())
+}
+
+// raise sets the level of the logrus logger to the most verbose of the base level and the subsystem levels.
+func (l *levels) raise() {
+	lv := logrus.Level(l.base.Load())
+	for _, sl := range *l.subsystems.Load() {
+		lv = max(lv, sl)
+	}
+	if l.logger.Level != lv {
+		l.logger.SetLevel(lv)
+	}
+}
+
+// subsystemLogger is a dlog.Logger that only logs entries that are enabled by the level of its subsystem.
+type subsystemLogger struct {
+	dlog.Logger
+	levels    *levels
+	subsystem string
+}
+
+func (s *subsystemLogger) enabled(level dlog.LogLevel) bool {
+	// dlog levels start at error, logrus levels start at panic.
+	return logrus.Level(level)+logrus.ErrorLevel <= s.levels.level(s.subsystem)
+}
+
+func (s *subsystemLogger) WithField(key string, value any) dlog.Logger {
+	if key == subsystemField {
+		if name, ok := value.(string); ok {
+			return &subsystemLogger{Logger: s.Logger, levels: s.levels, subsystem: name}
+		}
+	}
+	return &subsystemLogger{Logger: s.Logger.WithField(key, value), levels: s.levels, subsystem: s.subsystem}
+}
+
+func (s *subsystemLogger) StdLogger(level dlog.LogLevel) *log.Logger {
+	if !s.enabled(level) {
+		return log.New(io.Discard, "", 0)
+	}
+	return s.Logger.StdLogger(level)
+}
+
+func (s *subsystemLogger) MaxLevel() dlog.LogLevel {
+	return dlog.LogLevel(s.levels.level(s.subsystem) - logrus.ErrorLevel)
+}
+
+func (s *subsystemLogger) Log(level dlog.LogLevel, msg string) {
+	if s.enabled(level) {
+		s.Logger.Log(level, msg)
+	}
+}
+
+// We need to implement the UnformattedXXX functions to prevent that
+// dlog formats the messages that are dropped.
+
+func (s *subsystemLogger) UnformattedLog(level dlog.LogLevel, args ...any) {
+	if s.enabled(level) {
+		if ol, ok := s.Logger.(dlog.OptimizedLogger); ok {
+			ol.UnformattedLog(level, args...)
+		} else {
+			s.Logger.Log(level, fmt.Sprint(args...))
+		}
+	}
+}
+
+func (s *subsystemLogger) UnformattedLogf(level dlog.LogLevel, format string, args ...any) {
+	if s.enabled(level) {
+		if ol, ok := s.Logger.(dlog.OptimizedLogger); ok {
+			ol.UnformattedLogf(level, format, args...)
+		} else {
+			s.Logger.Log(level, fmt.Sprintf(format, args...))
+		}
+	}
+}
+
+func (s *subsystemLogger) UnformattedLogln(level dlog.LogLevel, args ...any) {
+	if s.enabled(level) {
+		if ol, ok := s.Logger.(dlog.OptimizedLogger); ok {
+			ol.UnformattedLogln(level, args...)
+		} else {
+			s.Logger.Log(level, fmt.Sprintln(args...))
+		}
+	}
+}
+
+// WithSubsystem returns a context that logs using the log level of the given subsystem when such a level has
+// been set using SetSubsystemLevels, and using the level set by SetLevel otherwise.
+func WithSubsystem(ctx context.Context, subsystem string) context.Context {
+	if _, ok := ctx.Value(levelsContextKey{}).(*levels); !ok {
+		return ctx
+	}
+	return dlog.WithField(ctx, subsystemField, subsystem)
+}
+
+// SetSubsystemLevels sets the log levels of the subsystems of the logger of the given context. Subsystems
+// that are absent from the given map log using the level set by SetLevel.
+func SetSubsystemLevels(ctx context.Context, subsystemLevels map[string]logrus.Level) {
+	if l, ok := ctx.Value(levelsContextKey{}).(*levels); ok {
+		sls := maps.Clone(subsystemLevels)
+		l.subsystems.Store(&sls)
+		l.raise()
+	}
+}
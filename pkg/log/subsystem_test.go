@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+func TestSubsystemLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	ctx := WithLevelSetter(context.Background(), logger)
+	SetLevel(ctx, "info")
+	SetSubsystemLevels(ctx, map[string]logrus.Level{SubsystemWorkload: logrus.DebugLevel})
+	wlCtx := dlog.WithField(WithSubsystem(ctx, SubsystemWorkload), "THREAD", "watcher")
+	dnsCtx := WithSubsystem(ctx, SubsystemDNS)
+	assert.Equal(t, logrus.DebugLevel, logger.Level)
+
+	buf.Reset()
+	dlog.Debug(ctx, "base debug")
+	dlog.Info(ctx, "base info")
+	dlog.Debug(wlCtx, "workload debug")
+	dlog.Debug(dnsCtx, "dns debug")
+	out := buf.String()
+	assert.NotContains(t, out, "base debug")
+	assert.Contains(t, out, "base info")
+	assert.Contains(t, out, "workload debug")
+	assert.Contains(t, out, "THREAD=watcher")
+	assert.NotContains(t, out, "dns debug")
+	assert.Equal(t, dlog.LogLevelDebug, dlog.MaxLogLevel(wlCtx))
+	assert.Equal(t, dlog.LogLevelInfo, dlog.MaxLogLevel(dnsCtx))
+
+	// A subsystem can also be less verbose than the rest, and removed overrides no longer apply.
+	SetSubsystemLevels(ctx, map[string]logrus.Level{SubsystemDNS: logrus.WarnLevel})
+	assert.Equal(t, logrus.InfoLevel, logger.Level)
+	buf.Reset()
+	dlog.Info(dnsCtx, "dns info")
+	dlog.Warn(dnsCtx, "dns warning")
+	dlog.Debug(wlCtx, "workload debug")
+	dlog.Info(wlCtx, "workload info")
+	out = buf.String()
+	assert.NotContains(t, out, "dns info")
+	assert.Contains(t, out, "dns warning")
+	assert.NotContains(t, out, "workload debug")
+	assert.Contains(t, out, "workload info")
+}
@@ -0,0 +1,61 @@
+package matcher
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NewGRPCMethod returns a Value that matches the gRPC method of a request, i.e. the :path pseudo-header of
+// an HTTP/2 gRPC request, which has the form /<package>.<Service>/<Method>. A pattern that ends with "*" is a
+// prefix matcher, e.g. "/pkg.Service/*" matches all methods of a service. Other patterns must name exactly one
+// method. The leading slash of the pattern is optional.
+func NewGRPCMethod(pattern string) (Value, error) {
+	p := strings.TrimSpace(pattern)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	if prefix, ok := strings.CutSuffix(p, "*"); ok {
+		if prefix == "/" || strings.Contains(prefix, "*") {
+			return nil, fmt.Errorf("%q is not a valid gRPC method prefix", pattern)
+		}
+		return NewPrefix(prefix), nil
+	}
+	svc, method, ok := strings.Cut(p[1:], "/")
+	if !ok || svc == "" || method == "" || strings.Contains(method, "/") || strings.Contains(p, "*") {
+		return nil, fmt.Errorf("%q is not a valid gRPC method. It must be of the form /<package>.<Service>/<Method>", pattern)
+	}
+	return NewEqual(p), nil
+}
+
+// IsGRPC returns true if the given headers have a gRPC content type, i.e. application/grpc optionally
+// followed by a +<format> suffix.
+func IsGRPC(headers http.Header) bool {
+	mt, _, err := mime.ParseMediaType(headers.Get("Content-Type"))
+	return err == nil && (mt == "application/grpc" || strings.HasPrefix(mt, "application/grpc+"))
+}
+
+// parseGRPCMethods parses a comma separated list of gRPC method patterns.
+func parseGRPCMethods(v string) ([]Value, error) {
+	var ms []Value
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		m, err := NewGRPCMethod(s)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// grpcMethodPattern returns the pattern that NewGRPCMethod would create the given Value from.
+func grpcMethodPattern(m Value) string {
+	if _, ok := m.(prefixValue); ok {
+		return m.String() + "*"
+	}
+	return m.String()
+}
@@ -3,16 +3,23 @@ package matcher
 import (
 	"fmt"
 	"net/http"
+	"net/netip"
 	"net/textproto"
 	"strings"
 
 	"github.com/telepresenceio/telepresence/v2/pkg/maps"
 )
 
-// The Request matcher uses a Value matcher and a Headers matcher to match the path and headers of a http request.
+// The Request matcher uses a Value matcher and a Headers matcher to match the path and headers of a http request,
+// an optional set of IP prefixes to match the source IP of that request, and an optional set of gRPC method
+// matchers that a gRPC request must match.
 type Request interface {
 	fmt.Stringer
 
+	// GRPCMethods returns the matchers of which one must match the gRPC method of a request, or nil if
+	// the request doesn't need to be a gRPC request.
+	GRPCMethods() []Value
+
 	// Headers returns Headers of this instance.
 	Headers() Headers
 
@@ -20,27 +27,42 @@ type Request interface {
 	// used as an argument to NewRequest to create an identical Request.
 	Map() map[string]string
 
-	// Matches returns true if both the path Value matcher and the Headers matcher in this instance are
-	// matched by the given http.Request.
-	Matches(path string, headers http.Header) bool
+	// Matches returns true if the path Value matcher, the source prefixes, the gRPC method matchers, and the
+	// Headers matcher in this instance are all matched by the given path, source IP, and headers.
+	Matches(path string, source netip.Addr, headers http.Header) bool
 
 	// Path returns the path
 	Path() Value
+
+	// Sources returns the prefixes that the source IP must be contained in, or nil if any source is accepted.
+	Sources() []netip.Prefix
 }
 
 type request struct {
-	path    Value
-	headers HeaderMap
+	path        Value
+	sources     []netip.Prefix
+	grpcMethods []Value
+	headers     HeaderMap
 }
 
 // NewRequestFromMap creates a new Request based on the values of the given map. Aside from http headers,
-// the map may contain one of three special keys.
+// the map may contain one of three special path keys.
 //
 //	:path-equal: path will match if equal to the value
 //	:path-prefix: path will match prefixed by the value
 //	:path-regex: path will match it matches the regexp value
+//
+// and the special source key
+//
+//	:source-cidr: source IP will match if contained in one of the comma separated IPs or CIDRs of the value
+//
+// and the special gRPC key
+//
+//	:grpc-method: a gRPC request will match if its method matches one of the comma separated patterns of the value
 func NewRequestFromMap(m map[string]string) (Request, error) {
 	var pm Value
+	var sources []netip.Prefix
+	var grpcMethods []Value
 	hm := make(HeaderMap, len(m))
 
 	var err error
@@ -54,6 +76,14 @@ func NewRequestFromMap(m map[string]string) (Request, error) {
 			if pm, err = NewRegex(v); err != nil {
 				return nil, err
 			}
+		case ":source-cidr:":
+			if sources, err = parseSources(v); err != nil {
+				return nil, fmt.Errorf("the value of match %s=%s is invalid: %w", k, v, err)
+			}
+		case ":grpc-method:":
+			if grpcMethods, err = parseGRPCMethods(v); err != nil {
+				return nil, fmt.Errorf("the value of match %s=%s is invalid: %w", k, v, err)
+			}
 		default:
 			vm, err := NewValue(v)
 			if err != nil {
@@ -62,7 +92,10 @@ func NewRequestFromMap(m map[string]string) (Request, error) {
 			hm[textproto.CanonicalMIMEHeaderKey(k)] = vm
 		}
 	}
-	return NewRequest(pm, hm), nil
+	if len(hm) == 0 {
+		hm = nil
+	}
+	return &request{path: pm, sources: sources, grpcMethods: grpcMethods, headers: hm}, nil
 }
 
 func NewRequest(path Value, hm HeaderMap) Request {
@@ -72,6 +105,31 @@ func NewRequest(path Value, hm HeaderMap) Request {
 	return &request{path: path, headers: hm}
 }
 
+// parseSources parses a comma separated list of IPs and CIDRs into a slice of prefixes. An IP
+// is converted into a single address prefix.
+func parseSources(v string) ([]netip.Prefix, error) {
+	var ps []netip.Prefix
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if strings.ContainsRune(s, '/') {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, p.Masked())
+		} else {
+			a, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, netip.PrefixFrom(a, a.BitLen()))
+		}
+	}
+	return ps, nil
+}
+
 // Map returns the map correspondence of this instance. The returned value can be
 // used as an argument to NewRequest to create an identical Request.
 func (r *request) Map() map[string]string {
@@ -80,7 +138,7 @@ func (r *request) Map() map[string]string {
 		m = r.headers.Map()
 	}
 	if p := r.path; p != nil {
-		pm := make(map[string]string, len(m)+1)
+		pm := make(map[string]string, len(m)+2)
 		switch p.(type) {
 		case textValue:
 			pm[":path-equal:"] = p.String()
@@ -92,18 +150,73 @@ func (r *request) Map() map[string]string {
 		maps.Merge(pm, m)
 		m = pm
 	}
+	if len(r.sources) > 0 {
+		if m == nil {
+			m = make(map[string]string, 1)
+		}
+		m[":source-cidr:"] = r.sourcesString()
+	}
+	if len(r.grpcMethods) > 0 {
+		if m == nil {
+			m = make(map[string]string, 1)
+		}
+		m[":grpc-method:"] = r.grpcMethodsString(",")
+	}
 	return m
 }
 
+// GRPCMethods returns the matchers of which one must match the gRPC method of a request, or nil if
+// the request doesn't need to be a gRPC request.
+func (r *request) GRPCMethods() []Value {
+	return r.grpcMethods
+}
+
 // Headers returns Headers of this instance.
 func (r *request) Headers() Headers {
 	return r.headers
 }
 
-// Matches returns true if both the path Value matcher and the Headers matcher in this instance are
-// matched by the given http.Request.
-func (r *request) Matches(path string, headers http.Header) bool {
-	return r == nil || (r.path == nil || r.path.Matches(path)) && (r.headers == nil || r.headers.Matches(headers))
+// Matches returns true if the path Value matcher, the source prefixes, the gRPC method matchers, and the
+// Headers matcher in this instance are all matched by the given path, source IP, and headers. An invalid
+// source IP never matches when source prefixes are present. The path is the gRPC method of a request with
+// a gRPC content type, and a request without one never matches when gRPC method matchers are present.
+func (r *request) Matches(path string, source netip.Addr, headers http.Header) bool {
+	return r == nil ||
+		(r.path == nil || r.path.Matches(path)) &&
+			r.matchesSource(source) &&
+			r.matchesGRPCMethod(path, headers) &&
+			(r.headers == nil || r.headers.Matches(headers))
+}
+
+func (r *request) matchesGRPCMethod(path string, headers http.Header) bool {
+	if len(r.grpcMethods) == 0 {
+		return true
+	}
+	if !IsGRPC(headers) {
+		return false
+	}
+	for _, m := range r.grpcMethods {
+		if m.Matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *request) matchesSource(source netip.Addr) bool {
+	if len(r.sources) == 0 {
+		return true
+	}
+	if !source.IsValid() {
+		return false
+	}
+	source = source.Unmap()
+	for _, p := range r.sources {
+		if p.Contains(source) {
+			return true
+		}
+	}
+	return false
 }
 
 // Path returns the path.
@@ -111,21 +224,67 @@ func (r *request) Path() Value {
 	return r.path
 }
 
+// Sources returns the prefixes that the source IP must be contained in, or nil if any source is accepted.
+func (r *request) Sources() []netip.Prefix {
+	return r.sources
+}
+
+func (r *request) sourcesString() string {
+	ss := make([]string, len(r.sources))
+	for i, p := range r.sources {
+		ss[i] = p.String()
+	}
+	return strings.Join(ss, ",")
+}
+
+func (r *request) grpcMethodsString(sep string) string {
+	ms := make([]string, len(r.grpcMethods))
+	for i, m := range r.grpcMethods {
+		ms[i] = grpcMethodPattern(m)
+	}
+	return strings.Join(ms, sep)
+}
+
 func (r *request) String() string {
 	sb := strings.Builder{}
-	if r == nil || r.path == nil && len(r.headers) == 0 {
+	if r == nil || r.path == nil && len(r.sources) == 0 && len(r.grpcMethods) == 0 && len(r.headers) == 0 {
 		return "all requests"
 	}
 	sb.WriteString("requests with")
+	multi := 0
 	if r.path != nil {
-		if r.headers != nil {
+		multi++
+	}
+	if len(r.sources) > 0 {
+		multi++
+	}
+	if len(r.grpcMethods) > 0 {
+		multi++
+	}
+	if r.headers != nil {
+		multi++
+	}
+	if r.path != nil {
+		if multi > 1 {
 			sb.WriteString("\n ")
 		}
 		fmt.Fprintf(&sb, " path %s %s", r.path.Op(), r.path.String())
 	}
+	if len(r.sources) > 0 {
+		if multi > 1 {
+			sb.WriteString("\n ")
+		}
+		fmt.Fprintf(&sb, " source in %s", r.sourcesString())
+	}
+	if len(r.grpcMethods) > 0 {
+		if multi > 1 {
+			sb.WriteString("\n ")
+		}
+		fmt.Fprintf(&sb, " gRPC method in %s", r.grpcMethodsString(", "))
+	}
 	if r.headers != nil {
 		indent := "  "
-		if r.path != nil {
+		if multi > 1 {
 			indent += "  "
 			sb.WriteString("\n ")
 		}
@@ -3,6 +3,7 @@ package matcher
 import (
 	"fmt"
 	"net/http"
+	"net/netip"
 	"regexp"
 	"testing"
 
@@ -40,6 +41,20 @@ func TestNewRequest(t *testing.T) {
 			args: map[string]string{":path-regex:": ".*/path", "A": "b"},
 			want: &request{path: rxValue{regexp.MustCompile(".*/path")}, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
 		},
+		{
+			name: "source-cidr",
+			args: map[string]string{":source-cidr:": "10.1.2.3/16, 192.168.1.5,fd00::/8"},
+			want: &request{sources: []netip.Prefix{
+				netip.MustParsePrefix("10.1.0.0/16"),
+				netip.MustParsePrefix("192.168.1.5/32"),
+				netip.MustParsePrefix("fd00::/8"),
+			}},
+		},
+		{
+			name: "grpc-method",
+			args: map[string]string{":grpc-method:": "/pkg.Service/Method, pkg.Other/*"},
+			want: &request{grpcMethods: []Value{NewEqual("/pkg.Service/Method"), NewPrefix("/pkg.Other/")}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -52,6 +67,20 @@ func TestNewRequest(t *testing.T) {
 	}
 }
 
+func TestNewRequest_invalidGRPCMethod(t *testing.T) {
+	for _, v := range []string{"/pkg.Service", "/pkg.Service/Method/Extra", "*", "/pkg.*/Method"} {
+		_, err := NewRequestFromMap(map[string]string{":grpc-method:": v})
+		assert.Errorf(t, err, "NewRequest(:grpc-method:=%s)", v)
+	}
+}
+
+func TestNewRequest_invalidSource(t *testing.T) {
+	for _, v := range []string{"10.1.2.3/33", "10.1.2", "example.com"} {
+		_, err := NewRequestFromMap(map[string]string{":source-cidr:": v})
+		assert.Errorf(t, err, "NewRequest(:source-cidr:=%s)", v)
+	}
+}
+
 func Test_request_Map(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -83,6 +112,25 @@ func Test_request_Map(t *testing.T) {
 			request{path: rxValue{regexp.MustCompile(".*/path")}, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
 			map[string]string{":path-regex:": ".*/path", "A": "b"},
 		},
+		{
+			"source-cidr",
+			request{sources: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("192.168.1.5/32")}},
+			map[string]string{":source-cidr:": "10.1.0.0/16,192.168.1.5/32"},
+		},
+		{
+			"path-prefix, source-cidr, and headers",
+			request{
+				path:    NewPrefix("/some"),
+				sources: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")},
+				headers: HeaderMap(map[string]Value{"A": NewEqual("b")}),
+			},
+			map[string]string{":path-prefix:": "/some", ":source-cidr:": "10.1.0.0/16", "A": "b"},
+		},
+		{
+			"grpc-method",
+			request{grpcMethods: []Value{NewEqual("/pkg.Service/Method"), NewPrefix("/pkg.Other/")}},
+			map[string]string{":grpc-method:": "/pkg.Service/Method,/pkg.Other/*"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -92,10 +140,14 @@ func Test_request_Map(t *testing.T) {
 }
 
 func Test_request_Matches(t *testing.T) {
+	sources := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("192.168.1.5/32")}
+	grpcMethods := []Value{NewEqual("/pkg.Service/Method"), NewPrefix("/pkg.Other/")}
+	grpc := http.Header(map[string][]string{"Content-Type": {"application/grpc"}})
 	tests := []struct {
 		name    string
 		request request
 		path    string
+		source  netip.Addr
 		headers http.Header
 		want    bool
 	}{
@@ -163,10 +215,109 @@ func Test_request_Matches(t *testing.T) {
 			path:    "/some/road",
 			want:    false,
 		},
+		{
+			name:    "source in range",
+			request: request{sources: sources},
+			source:  netip.MustParseAddr("10.1.200.7"),
+			want:    true,
+		},
+		{
+			name:    "source equal to single IP",
+			request: request{sources: sources},
+			source:  netip.MustParseAddr("192.168.1.5"),
+			want:    true,
+		},
+		{
+			name:    "source IPv4 mapped IPv6",
+			request: request{sources: sources},
+			source:  netip.MustParseAddr("::ffff:10.1.0.1"),
+			want:    true,
+		},
+		{
+			name:    "source out of range",
+			request: request{sources: sources},
+			source:  netip.MustParseAddr("10.2.0.1"),
+			want:    false,
+		},
+		{
+			name:    "source unknown",
+			request: request{sources: sources},
+			want:    false,
+		},
+		{
+			name:    "source ignored when not in matcher",
+			request: request{path: NewPrefix("/some")},
+			path:    "/some/path",
+			source:  netip.MustParseAddr("10.2.0.1"),
+			want:    true,
+		},
+		{
+			name:    "source and headers",
+			request: request{sources: sources, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
+			source:  netip.MustParseAddr("10.1.0.1"),
+			headers: http.Header(map[string][]string{"A": {"b"}}),
+			want:    true,
+		},
+		{
+			name:    "source match doesn't override header mismatch",
+			request: request{sources: sources, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
+			source:  netip.MustParseAddr("10.1.0.1"),
+			headers: http.Header(map[string][]string{"A": {"c"}}),
+			want:    false,
+		},
+		{
+			name:    "header match doesn't override source mismatch",
+			request: request{sources: sources, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
+			source:  netip.MustParseAddr("10.2.0.1"),
+			headers: http.Header(map[string][]string{"A": {"b"}}),
+			want:    false,
+		},
+		{
+			name:    "grpc exact method",
+			request: request{grpcMethods: grpcMethods},
+			path:    "/pkg.Service/Method",
+			headers: grpc,
+			want:    true,
+		},
+		{
+			name:    "grpc exact method mismatch",
+			request: request{grpcMethods: grpcMethods},
+			path:    "/pkg.Service/Other",
+			headers: grpc,
+			want:    false,
+		},
+		{
+			name:    "grpc method prefix",
+			request: request{grpcMethods: grpcMethods},
+			path:    "/pkg.Other/Method",
+			headers: http.Header(map[string][]string{"Content-Type": {"application/grpc+proto"}}),
+			want:    true,
+		},
+		{
+			name:    "grpc method of request that isn't gRPC",
+			request: request{grpcMethods: grpcMethods},
+			path:    "/pkg.Service/Method",
+			headers: http.Header(map[string][]string{"Content-Type": {"application/json"}}),
+			want:    false,
+		},
+		{
+			name:    "grpc method and headers",
+			request: request{grpcMethods: grpcMethods, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
+			path:    "/pkg.Service/Method",
+			headers: http.Header(map[string][]string{"Content-Type": {"application/grpc"}, "A": {"b"}}),
+			want:    true,
+		},
+		{
+			name:    "grpc method match doesn't override header mismatch",
+			request: request{grpcMethods: grpcMethods, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
+			path:    "/pkg.Service/Method",
+			headers: http.Header(map[string][]string{"Content-Type": {"application/grpc"}, "A": {"c"}}),
+			want:    false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, tt.request.Matches(tt.path, tt.headers), "Matches(%v, %v)", tt.path, tt.headers)
+			assert.Equalf(t, tt.want, tt.request.Matches(tt.path, tt.source, tt.headers), "Matches(%v, %v, %v)", tt.path, tt.source, tt.headers)
 		})
 	}
 }
@@ -206,6 +357,33 @@ func Test_request_String(t *testing.T) {
 			request: request{path: rxValue{regexp.MustCompile(".*/path")}, headers: HeaderMap(map[string]Value{"A": NewEqual("b")})},
 			want:    "requests with\n  path =~ .*/path\n  headers\n    'A: b'",
 		},
+		{
+			name:    "source",
+			request: request{sources: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}},
+			want:    "requests with source in 10.1.0.0/16",
+		},
+		{
+			name: "path, source, and headers",
+			request: request{
+				path:    NewPrefix("/some"),
+				sources: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")},
+				headers: HeaderMap(map[string]Value{"A": NewEqual("b")}),
+			},
+			want: "requests with\n  path prefix /some\n  source in 10.1.0.0/16\n  headers\n    'A: b'",
+		},
+		{
+			name:    "grpc method",
+			request: request{grpcMethods: []Value{NewEqual("/pkg.Service/Method"), NewPrefix("/pkg.Other/")}},
+			want:    "requests with gRPC method in /pkg.Service/Method, /pkg.Other/*",
+		},
+		{
+			name: "grpc method and headers",
+			request: request{
+				grpcMethods: []Value{NewEqual("/pkg.Service/Method")},
+				headers:     HeaderMap(map[string]Value{"A": NewEqual("b")}),
+			},
+			want: "requests with\n  gRPC method in /pkg.Service/Method\n  headers\n    'A: b'",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
+	"strings"
 
 	"github.com/go-json-experiment/json"
 
 	"github.com/datawire/dlib/dhttp"
 	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 )
 
 const (
@@ -33,8 +36,9 @@ type InterceptInfo struct {
 
 type AgentState interface {
 	// InterceptInfo returns information about an ongoing intercept that matches
-	// the given arguments.
-	InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, headers http.Header) (*InterceptInfo, error)
+	// the given arguments. The sourceIP is invalid when the source of the request
+	// is unknown.
+	InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, sourceIP netip.Addr, headers http.Header) (*InterceptInfo, error)
 }
 
 type Server interface {
@@ -46,14 +50,53 @@ type ErrorResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
-func NewServer(agent AgentState) Server {
+// NewServer creates a new API server that consults the given agent. The sourceIP form value and the
+// X-Forwarded-For header are only honored when the request originates from one of the given trusted
+// proxies. Loopback addresses are trusted when no trusted proxies are given.
+func NewServer(agent AgentState, trustedProxies ...netip.Prefix) Server {
+	if len(trustedProxies) == 0 {
+		trustedProxies = defaultTrustedProxies
+	}
 	return &server{
-		agent: agent,
+		agent:          agent,
+		trustedProxies: trustedProxies,
 	}
 }
 
+var defaultTrustedProxies = []netip.Prefix{ //nolint:gochecknoglobals // constant
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
 type server struct {
-	agent AgentState
+	agent          AgentState
+	trustedProxies []netip.Prefix
+}
+
+func (s *server) isTrusted(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range s.trustedProxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the address closest to this server in the given X-Forwarded-For header
+// value that isn't a trusted proxy.
+func (s *server) forwardedFor(xff string) (netip.Addr, bool) {
+	hops := strings.Split(xff, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if !s.isTrusted(ip) {
+			return ip, true
+		}
+	}
+	return netip.Addr{}, false
 }
 
 // ListenAndServe is like Serve but creates a TCP listener on "localhost:<apiPort>".
@@ -65,8 +108,8 @@ func (s *server) ListenAndServe(c context.Context, apiPort int) error {
 	return s.Serve(c, ln)
 }
 
-func (s *server) interceptInfo(c context.Context, p string, cp uint16, h http.Header) (*InterceptInfo, error) {
-	return s.agent.InterceptInfo(c, h.Get(HeaderCallerInterceptID), p, cp, h)
+func (s *server) interceptInfo(c context.Context, p string, cp uint16, ip netip.Addr, h http.Header) (*InterceptInfo, error) {
+	return s.agent.InterceptInfo(c, h.Get(HeaderCallerInterceptID), p, cp, ip, h)
 }
 
 // Serve starts the API server. It terminates when the given context is done.
@@ -91,6 +134,47 @@ func (s *server) Serve(c context.Context, ln net.Listener) error {
 		return 0, true
 	}
 
+	// sourceIP returns the source IP of the request that the query concerns. It's taken from the sourceIP
+	// form value or, when that is absent, from the X-Forwarded-For header. Neither is honored unless the
+	// query originates from a trusted proxy, in which case the source is unknown.
+	sourceIP := func(w http.ResponseWriter, r *http.Request) (netip.Addr, bool) {
+		peer, err := netip.ParseAddrPort(r.RemoteAddr)
+		if err != nil || !s.isTrusted(peer.Addr()) {
+			dlog.Debugf(c, "ignoring source IP supplied by untrusted peer %s", r.RemoteAddr)
+			return netip.Addr{}, true
+		}
+		if sv := r.FormValue("sourceIP"); sv != "" {
+			ip, err := netip.ParseAddr(sv)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("sourceIP: %w", err))
+				return netip.Addr{}, false
+			}
+			return ip, true
+		}
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			if ip, ok := s.forwardedFor(xff); ok {
+				return ip, true
+			}
+		}
+		return netip.Addr{}, true
+	}
+
+	// requestPath returns the path of the request that the query concerns, and the headers to match it with.
+	// The path is taken from the grpcMethod form value when present. That value is the :path pseudo-header
+	// of a gRPC request, and the headers are given a gRPC content type unless they already have one, so that
+	// the request is recognized as a gRPC request. The path is otherwise taken from the path form value.
+	requestPath := func(r *http.Request) (string, http.Header) {
+		if gm := r.FormValue("grpcMethod"); gm != "" {
+			h := r.Header
+			if !matcher.IsGRPC(h) {
+				h = h.Clone()
+				h.Set("Content-Type", "application/grpc")
+			}
+			return gm, h
+		}
+		return r.FormValue("path"), r.Header
+	}
+
 	mux.HandleFunc(EndPointConsumeHere, func(w http.ResponseWriter, r *http.Request) {
 		dlog.Debugf(c, "Received %s", EndPointConsumeHere)
 		w.Header().Set("Content-Type", "application/json")
@@ -98,7 +182,12 @@ func (s *server) Serve(c context.Context, ln net.Listener) error {
 		if !ok {
 			return
 		}
-		if ii, err := s.interceptInfo(c, r.FormValue("path"), cp, r.Header); err != nil {
+		ip, ok := sourceIP(w, r)
+		if !ok {
+			return
+		}
+		p, h := requestPath(r)
+		if ii, err := s.interceptInfo(c, p, cp, ip, h); err != nil {
 			writeError(w, http.StatusInternalServerError, err)
 		} else {
 			// Client must consume intercepted messages. Agent must not.
@@ -118,7 +207,12 @@ func (s *server) Serve(c context.Context, ln net.Listener) error {
 		if !ok {
 			return
 		}
-		if ii, err := s.interceptInfo(c, r.FormValue("path"), cp, r.Header); err != nil {
+		ip, ok := sourceIP(w, r)
+		if !ok {
+			return
+		}
+		p, h := requestPath(r)
+		if ii, err := s.interceptInfo(c, p, cp, ip, h); err != nil {
 			writeError(w, http.StatusInternalServerError, err)
 		} else if err = json.MarshalWrite(w, &ii); err != nil {
 			dlog.Errorf(c, "error %v when responding with %v", err, ii)
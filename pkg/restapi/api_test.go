@@ -2,17 +2,21 @@ package restapi_test
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
+	"net/netip"
 	"sync"
 	"testing"
 
 	"github.com/go-json-experiment/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/v2/pkg/log"
+	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
 )
 
@@ -21,11 +25,11 @@ type (
 	yesNoCluster bool
 )
 
-func (yn yesNoClient) InterceptInfo(_ context.Context, _, _ string, _ uint16, _ http.Header) (*restapi.InterceptInfo, error) {
+func (yn yesNoClient) InterceptInfo(_ context.Context, _, _ string, _ uint16, _ netip.Addr, _ http.Header) (*restapi.InterceptInfo, error) {
 	return &restapi.InterceptInfo{Intercepted: bool(yn), ClientSide: true}, nil
 }
 
-func (yn yesNoCluster) InterceptInfo(_ context.Context, _, _ string, _ uint16, _ http.Header) (*restapi.InterceptInfo, error) {
+func (yn yesNoCluster) InterceptInfo(_ context.Context, _, _ string, _ uint16, _ netip.Addr, _ http.Header) (*restapi.InterceptInfo, error) {
 	return &restapi.InterceptInfo{Intercepted: bool(yn), ClientSide: false}, nil
 }
 
@@ -44,11 +48,11 @@ func (t textMatcher) intercepted(header http.Header) bool {
 	return true
 }
 
-func (t textMatcherClient) InterceptInfo(_ context.Context, _, _ string, _ uint16, headers http.Header) (*restapi.InterceptInfo, error) {
+func (t textMatcherClient) InterceptInfo(_ context.Context, _, _ string, _ uint16, _ netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
 	return &restapi.InterceptInfo{Intercepted: textMatcher(t).intercepted(headers), ClientSide: true}, nil
 }
 
-func (t textMatcherCluster) InterceptInfo(_ context.Context, _, _ string, _ uint16, headers http.Header) (*restapi.InterceptInfo, error) {
+func (t textMatcherCluster) InterceptInfo(_ context.Context, _, _ string, _ uint16, _ netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
 	return &restapi.InterceptInfo{Intercepted: textMatcher(t).intercepted(headers), ClientSide: false}, nil
 }
 
@@ -57,24 +61,30 @@ type matcherWithMetadata struct {
 	meta map[string]string
 }
 
-func (t *matcherWithMetadata) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, headers http.Header) (*restapi.InterceptInfo, error) {
-	ret, _ := t.textMatcherCluster.InterceptInfo(ctx, callerID, path, containerPort, headers)
+func (t *matcherWithMetadata) InterceptInfo(ctx context.Context, callerID, path string, containerPort uint16, sourceIP netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
+	ret, _ := t.textMatcherCluster.InterceptInfo(ctx, callerID, path, containerPort, sourceIP, headers)
 	ret.Metadata = t.meta
 	return ret, nil
 }
 
 type callerIdMatcherClient string
 
-func (c callerIdMatcherClient) InterceptInfo(_ context.Context, callerID, _ string, _ uint16, _ http.Header) (*restapi.InterceptInfo, error) {
+func (c callerIdMatcherClient) InterceptInfo(_ context.Context, callerID, _ string, _ uint16, _ netip.Addr, _ http.Header) (*restapi.InterceptInfo, error) {
 	return &restapi.InterceptInfo{Intercepted: callerID == string(c), ClientSide: true}, nil
 }
 
 type callerIdMatcherCluster string
 
-func (c callerIdMatcherCluster) InterceptInfo(_ context.Context, callerID, _ string, _ uint16, _ http.Header) (*restapi.InterceptInfo, error) {
+func (c callerIdMatcherCluster) InterceptInfo(_ context.Context, callerID, _ string, _ uint16, _ netip.Addr, _ http.Header) (*restapi.InterceptInfo, error) {
 	return &restapi.InterceptInfo{Intercepted: callerID == string(c), ClientSide: false}, nil
 }
 
+type sourceMatcherClient netip.Prefix
+
+func (c sourceMatcherClient) InterceptInfo(_ context.Context, _, _ string, _ uint16, sourceIP netip.Addr, _ http.Header) (*restapi.InterceptInfo, error) {
+	return &restapi.InterceptInfo{Intercepted: netip.Prefix(c).Contains(sourceIP), ClientSide: true}, nil
+}
+
 func Test_server_intercepts(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -240,6 +250,56 @@ func Test_server_intercepts(t *testing.T) {
 				Metadata:    nil,
 			},
 		},
+		{
+			"client source ip - match",
+			sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16")),
+			nil,
+			restapi.EndPointConsumeHere + "?sourceIP=10.1.2.3",
+			true,
+		},
+		{
+			"client source ip - no match",
+			sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16")),
+			nil,
+			restapi.EndPointConsumeHere + "?sourceIP=10.2.2.3",
+			false,
+		},
+		{
+			"client source ip from forwarded-for - match",
+			sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16")),
+			map[string]string{
+				"X-Forwarded-For": "10.2.2.3, 10.1.2.3",
+			},
+			restapi.EndPointConsumeHere,
+			true,
+		},
+		{
+			"client source ip from forwarded-for skips trusted proxies",
+			sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16")),
+			map[string]string{
+				"X-Forwarded-For": "10.2.2.3, 10.1.2.3, 127.0.0.1",
+			},
+			restapi.EndPointConsumeHere,
+			true,
+		},
+		{
+			"client source ip from forwarded-for uses nearest untrusted hop",
+			sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16")),
+			map[string]string{
+				"X-Forwarded-For": "10.1.2.3, 10.2.2.3",
+			},
+			restapi.EndPointConsumeHere,
+			false,
+		},
+		{
+			"client source ip form value has precedence over forwarded-for",
+			sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16")),
+			map[string]string{
+				"X-Forwarded-For": "10.1.2.3",
+			},
+			restapi.EndPointConsumeHere + "?sourceIP=10.2.2.3",
+			false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -277,3 +337,153 @@ func Test_server_intercepts(t *testing.T) {
 		})
 	}
 }
+
+func Test_server_untrustedSourceIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		endpoint string
+	}{
+		{
+			"source ip form value",
+			nil,
+			restapi.EndPointConsumeHere + "?sourceIP=10.1.2.3",
+		},
+		{
+			"forwarded-for",
+			map[string]string{
+				"X-Forwarded-For": "10.1.2.3",
+			},
+			restapi.EndPointConsumeHere,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := dlog.WithLogger(context.Background(), log.NewTestLogger(t, dlog.LogLevelWarn))
+			c, cancel := context.WithCancel(c)
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				agent := sourceMatcherClient(netip.MustParsePrefix("10.1.0.0/16"))
+				assert.NoError(t, restapi.NewServer(agent, netip.MustParsePrefix("192.0.2.0/24")).Serve(c, ln))
+			}()
+			rq, err := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+tt.endpoint, nil)
+			require.NoError(t, err)
+			for k, v := range tt.headers {
+				rq.Header.Set(k, v)
+			}
+			r, err := http.DefaultClient.Do(rq)
+			require.NoError(t, err)
+			defer r.Body.Close()
+			assert.Equal(t, r.StatusCode, http.StatusOK)
+			var rpl bool
+			require.NoError(t, json.UnmarshalRead(r.Body, &rpl))
+			assert.False(t, rpl)
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+// requestMatcherClient is a client that intercepts the requests that are matched by a request matcher.
+type requestMatcherClient struct {
+	matcher.Request
+}
+
+func (c requestMatcherClient) InterceptInfo(_ context.Context, _, path string, _ uint16, sourceIP netip.Addr, headers http.Header) (*restapi.InterceptInfo, error) {
+	return &restapi.InterceptInfo{Intercepted: c.Matches(path, sourceIP, headers), ClientSide: true}, nil
+}
+
+func Test_server_grpcMethod(t *testing.T) {
+	rm, err := matcher.NewRequestFromMap(map[string]string{
+		":grpc-method:": "/pkg.Service/Method,/pkg.Other/*",
+		"X-Route":       "me",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		endpoint string
+		want     bool
+	}{
+		{
+			"exact method",
+			map[string]string{"X-Route": "me"},
+			restapi.EndPointConsumeHere + "?grpcMethod=/pkg.Service/Method",
+			true,
+		},
+		{
+			"exact method mismatch",
+			map[string]string{"X-Route": "me"},
+			restapi.EndPointConsumeHere + "?grpcMethod=/pkg.Service/Other",
+			false,
+		},
+		{
+			"method prefix",
+			map[string]string{"X-Route": "me"},
+			restapi.EndPointConsumeHere + "?grpcMethod=/pkg.Other/Method",
+			true,
+		},
+		{
+			"method prefix header mismatch",
+			map[string]string{"X-Route": "you"},
+			restapi.EndPointConsumeHere + "?grpcMethod=/pkg.Other/Method",
+			false,
+		},
+		{
+			"path of gRPC request",
+			map[string]string{"X-Route": "me", "Content-Type": "application/grpc+proto"},
+			restapi.EndPointConsumeHere + "?path=/pkg.Service/Method",
+			true,
+		},
+		{
+			"path of request that isn't gRPC",
+			map[string]string{"X-Route": "me"},
+			restapi.EndPointConsumeHere + "?path=/pkg.Service/Method",
+			false,
+		},
+	}
+
+	// gRPC applications are likely to query the API server using HTTP/2 without TLS.
+	hc := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := dlog.WithLogger(context.Background(), log.NewTestLogger(t, dlog.LogLevelWarn))
+			c, cancel := context.WithCancel(c)
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, restapi.NewServer(requestMatcherClient{rm}).Serve(c, ln))
+			}()
+			rq, err := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+tt.endpoint, nil)
+			require.NoError(t, err)
+			for k, v := range tt.headers {
+				rq.Header.Set(k, v)
+			}
+			r, err := hc.Do(rq)
+			require.NoError(t, err)
+			defer r.Body.Close()
+			assert.Equal(t, 2, r.ProtoMajor)
+			assert.Equal(t, r.StatusCode, http.StatusOK)
+			var rpl bool
+			require.NoError(t, json.UnmarshalRead(r.Body, &rpl))
+			assert.Equal(t, tt.want, rpl)
+			cancel()
+			wg.Wait()
+		})
+	}
+}
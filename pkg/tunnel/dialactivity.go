@@ -0,0 +1,77 @@
+package tunnel
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// DialActivity records the time of the most recent activity for each destination, and the number of
+// connections to it that haven't ended. It's used to determine if an intercept, which has its local target
+// as the destination, is receiving traffic.
+type DialActivity struct {
+	sync.Mutex
+	lastDial map[netip.AddrPort]time.Time
+	active   map[netip.AddrPort]int
+}
+
+func NewDialActivity() *DialActivity {
+	return &DialActivity{lastDial: make(map[netip.AddrPort]time.Time), active: make(map[netip.AddrPort]int)}
+}
+
+// Record records that a dial request for the destination of the given id was received at the given time.
+// The returned function must be called with the time when the connection that was dialed ends.
+func (da *DialActivity) Record(id ConnID, at time.Time) func(time.Time) {
+	dst, _ := netip.AddrFromSlice(id.Destination())
+	key := netip.AddrPortFrom(dst.Unmap(), id.DestinationPort())
+	da.Lock()
+	da.touch(key, at)
+	da.active[key]++
+	da.Unlock()
+	return func(at time.Time) {
+		da.Lock()
+		da.touch(key, at)
+		if da.active[key]--; da.active[key] <= 0 {
+			delete(da.active, key)
+		}
+		da.Unlock()
+	}
+}
+
+func (da *DialActivity) touch(key netip.AddrPort, at time.Time) {
+	if at.After(da.lastDial[key]) {
+		da.lastDial[key] = at
+	}
+}
+
+// Snapshot returns a copy of the recorded times. The given time is used for the destinations that have
+// connections that haven't ended, because those connections are still in use.
+func (da *DialActivity) Snapshot(now time.Time) map[netip.AddrPort]time.Time {
+	da.Lock()
+	defer da.Unlock()
+	snapshot := make(map[netip.AddrPort]time.Time, len(da.lastDial))
+	for k, v := range da.lastDial {
+		if da.active[k] > 0 && now.After(v) {
+			v = now
+		}
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+type dialActivityKey struct{}
+
+// WithDialActivity returns a context with the given DialActivity. The DialWaitLoop records each dial
+// request that it receives in the DialActivity of its context.
+func WithDialActivity(ctx context.Context, da *DialActivity) context.Context {
+	return context.WithValue(ctx, dialActivityKey{}, da)
+}
+
+func GetDialActivity(ctx context.Context) *DialActivity {
+	da, ok := ctx.Value(dialActivityKey{}).(*DialActivity)
+	if !ok {
+		return nil
+	}
+	return da
+}
@@ -0,0 +1,37 @@
+package tunnel
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+)
+
+func TestDialActivity(t *testing.T) {
+	da := NewDialActivity()
+	dst := netip.MustParseAddrPort("127.0.0.1:8080")
+	id := NewConnID(ipproto.TCP, net.ParseIP("10.0.0.1"), net.ParseIP("127.0.0.1"), 4711, dst.Port())
+	start := time.Now().Add(-time.Hour)
+
+	// A connection that hasn't ended is in use, so its destination is active now.
+	end := da.Record(id, start)
+	now := time.Now()
+	assert.Equal(t, now, da.Snapshot(now)[dst])
+
+	// A connection that has ended was last active when it ended.
+	ended := start.Add(time.Minute)
+	end(ended)
+	assert.Equal(t, ended, da.Snapshot(now)[dst])
+
+	// The destination remains active until all its connections have ended.
+	end1 := da.Record(id, start)
+	end2 := da.Record(id, start)
+	end1(ended)
+	assert.Equal(t, now, da.Snapshot(now)[dst])
+	end2(ended)
+	assert.Equal(t, ended, da.Snapshot(now)[dst])
+}
@@ -321,8 +321,9 @@ func readLoop(ctx context.Context, h streamReader, trafficProbe *CounterProbe) {
 }
 
 // DialWaitLoop reads from the given dialStream. A new goroutine that creates a Tunnel to the manager and then
-// attaches a dialer Endpoint to that tunnel is spawned for each request that arrives. The method blocks until
-// the dialStream is closed.
+// attaches a dialer Endpoint to that tunnel is spawned for each request that arrives. Each request, and the end
+// of the connection that it dials, is recorded in the DialActivity of the context, if any. The method blocks
+// until the dialStream is closed.
 func DialWaitLoop(
 	ctx context.Context,
 	tunnelProvider Provider,
@@ -332,6 +333,7 @@ func DialWaitLoop(
 	// create ctx to cleanup leftover dialRespond if waitloop dies
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	da := GetDialActivity(ctx)
 	for ctx.Err() == nil {
 		dr, err := dialStream.Recv()
 		if err != nil {
@@ -340,7 +342,16 @@ func DialWaitLoop(
 			}
 			return nil
 		}
-		go dialRespond(ctx, tunnelProvider, dr, sessionID)
+		var end func(time.Time)
+		if da != nil {
+			end = da.Record(ConnID(dr.ConnId), time.Now())
+		}
+		go func() {
+			dialRespond(ctx, tunnelProvider, dr, sessionID)
+			if end != nil {
+				end(time.Now())
+			}
+		}()
 	}
 	return nil
 }
@@ -40,11 +40,24 @@ const defaultDevOutQueueLen = 1024
 
 var _ Device = (*device)(nil)
 
+// OpenTunError is the error returned by OpenTun when the TUN device cannot be created.
+type OpenTunError struct {
+	Err error
+}
+
+func (e *OpenTunError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *OpenTunError) Unwrap() error {
+	return e.Err
+}
+
 // OpenTun creates a new TUN device and ensures that it is up and running.
 func OpenTun(ctx context.Context) (Device, error) {
 	dev, err := openTun(ctx)
 	if err != nil {
-		return nil, err
+		return nil, &OpenTunError{Err: err}
 	}
 
 	return &device{
@@ -0,0 +1,76 @@
+package workload
+
+import (
+	"regexp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+
+	argorollouts "github.com/datawire/argo-rollouts-go-client/pkg/apis/rollouts/v1alpha1"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+var (
+	// agentInjectionRx matches messages that refer to the agent-injector webhook or to the containers that it injects.
+	agentInjectionRx = regexp.MustCompile(`agent-injector|` + agentconfig.ContainerName + `|` + agentconfig.InitContainerName)
+
+	// resourceLimitRx matches messages of pods that are rejected because a resource quota or limit range is exceeded.
+	resourceLimitRx = regexp.MustCompile(`exceeded quota|forbidden: \[?(maximum|minimum) \S+ usage per (Container|Pod)|must be less than or equal to \S+ limit`)
+)
+
+// IsAgentInjectionFailure tells if the given message of a failure event or condition stems from the injection of the
+// traffic-agent. A message about an exceeded resource quota or limit is only attributed to the injection when
+// agentExpected is true, because such messages don't name the container that caused them.
+func IsAgentInjectionFailure(msg string, agentExpected bool) bool {
+	return msg != "" && (agentInjectionRx.MatchString(msg) || (agentExpected && resourceLimitRx.MatchString(msg)))
+}
+
+// AgentInjectionFailure returns the message of the ReplicaFailure condition of the given workload when it tells that
+// the traffic-agent couldn't be injected into the workload's pods, e.g. because the agent-injector webhook failed or
+// because the pod with the injected traffic-agent exceeds a resource quota or limit. An empty string is returned when
+// the workload shows no such failure.
+func AgentInjectionFailure(wl k8sapi.Workload) string {
+	if msg := replicaFailureMessage(wl); IsAgentInjectionFailure(msg, AgentExpected(wl)) {
+		return msg
+	}
+	return ""
+}
+
+// AgentExpected tells if the pod template of the given workload has been annotated to get a traffic-agent.
+func AgentExpected(wl k8sapi.Workload) bool {
+	tpl := wl.GetPodTemplate()
+	if tpl == nil {
+		return false
+	}
+	a := tpl.Annotations
+	_, restarted := a[AnnRestartedAt]
+	return restarted || a[InjectAnnotation] == "enabled"
+}
+
+// replicaFailureMessage returns the message of the true ReplicaFailure condition of the given workload, or an empty
+// string when there is no such condition.
+func replicaFailureMessage(wl k8sapi.Workload) string {
+	if d, ok := k8sapi.DeploymentImpl(wl); ok {
+		for _, c := range d.Status.Conditions {
+			if c.Type == appsv1.DeploymentReplicaFailure && c.Status == core.ConditionTrue {
+				return c.Message
+			}
+		}
+	}
+	if r, ok := k8sapi.ReplicaSetImpl(wl); ok {
+		for _, c := range r.Status.Conditions {
+			if c.Type == appsv1.ReplicaSetReplicaFailure && c.Status == core.ConditionTrue {
+				return c.Message
+			}
+		}
+	}
+	if rt, ok := k8sapi.RolloutImpl(wl); ok {
+		for _, c := range rt.Status.Conditions {
+			if c.Type == argorollouts.RolloutReplicaFailure && c.Status == core.ConditionTrue {
+				return c.Message
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package workload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+const (
+	webhookFailure = `Internal error occurred: failed calling webhook "agent-injector-ambassador.getambassador.io": ` +
+		`failed to call webhook: Post "https://agent-injector.ambassador:443/traffic-agent?timeout=5s": context deadline exceeded`
+	quotaFailure = `pods "echo-7d9c-x2b4f" is forbidden: exceeded quota: compute, requested: limits.cpu=200m, used: limits.cpu=1, limited: limits.cpu=1`
+)
+
+func failingDeployment(message string, annotations map[string]string) k8sapi.Workload {
+	return k8sapi.Deployment(&appsv1.Deployment{
+		ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: core.PodTemplateSpec{ObjectMeta: meta.ObjectMeta{Annotations: annotations}},
+		},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{
+			Type:    appsv1.DeploymentReplicaFailure,
+			Status:  core.ConditionTrue,
+			Reason:  "FailedCreate",
+			Message: message,
+		}}},
+	})
+}
+
+func TestAgentInjectionFailure(t *testing.T) {
+	restarted := map[string]string{AnnRestartedAt: "2026-10-16T12:00:00Z"}
+	tests := []struct {
+		name string
+		wl   k8sapi.Workload
+		want string
+	}{
+		{"webhook failure", failingDeployment(webhookFailure, nil), webhookFailure},
+		{"quota without agent", failingDeployment(quotaFailure, nil), ""},
+		{"quota with restarted agent", failingDeployment(quotaFailure, restarted), quotaFailure},
+		{"quota with enabled agent", failingDeployment(quotaFailure, map[string]string{InjectAnnotation: "enabled"}), quotaFailure},
+		{"unrelated failure", failingDeployment(`pods "echo-7d9c-x2b4f" is forbidden: error looking up service account default/echo`, restarted), ""},
+		{"no failure", k8sapi.Deployment(&appsv1.Deployment{}), ""},
+		{"replicaset webhook failure", k8sapi.ReplicaSet(&appsv1.ReplicaSet{
+			Status: appsv1.ReplicaSetStatus{Conditions: []appsv1.ReplicaSetCondition{{
+				Type:    appsv1.ReplicaSetReplicaFailure,
+				Status:  core.ConditionTrue,
+				Message: webhookFailure,
+			}}},
+		}), webhookFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AgentInjectionFailure(tt.wl))
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package workload
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
@@ -23,3 +28,25 @@ func FromAny(obj any) (k8sapi.Workload, bool) {
 	}
 	return nil, false
 }
+
+// RestartAnnotationPatch returns a JSON patch that adds or updates the AnnRestartedAt annotation of the given pod
+// template, so that the workload that owns the template performs a rollout. This particular patch type is used
+// because argo-rollouts does not support strategic merge patches.
+func RestartAnnotationPatch(podTemplate *core.PodTemplateSpec, restartedAt time.Time) []byte {
+	basePointer := "/spec/template/metadata/annotations"
+	pointer := fmt.Sprintf(
+		basePointer+"/%s",
+		strings.ReplaceAll(AnnRestartedAt, "/", "~1"),
+	)
+	ts := restartedAt.Format(time.RFC3339)
+
+	if _, ok := podTemplate.Annotations[AnnRestartedAt]; ok {
+		return []byte(fmt.Sprintf(`[{"op": "replace", "path": "%s", "value": "%s"}]`, pointer, ts))
+	}
+
+	if len(podTemplate.Annotations) == 0 {
+		return []byte(fmt.Sprintf(`[{"op": "add", "path": "%s", "value": {}}, {"op": "add", "path": "%s", "value": "%s"}]`, basePointer, pointer, ts))
+	}
+
+	return []byte(fmt.Sprintf(`[{"op": "add", "path": "%s", "value": "%s"}]`, pointer, ts))
+}
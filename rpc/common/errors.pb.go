@@ -43,6 +43,10 @@ const (
 	InterceptError_MOUNT_POINT_BUSY           InterceptError = 13
 	InterceptError_UNKNOWN_FLAG               InterceptError = 15
 	InterceptError_EXEC_CMD                   InterceptError = 16 // External exec command failed
+	InterceptError_INTERCEPT_CONFLICT         InterceptError = 18 // Another client intercepts the same traffic
+	InterceptError_UNKNOWN_PRESET             InterceptError = 19 // The intercept preset is not in the client configuration
+	InterceptError_INVALID_VALUE              InterceptError = 20 // A value of the intercept request is invalid
+	InterceptError_TOO_MANY_INTERCEPTS        InterceptError = 21 // The session has reached its maximum number of concurrent intercepts
 )
 
 // Enum value maps for InterceptError.
@@ -66,6 +70,10 @@ var (
 		13: "MOUNT_POINT_BUSY",
 		15: "UNKNOWN_FLAG",
 		16: "EXEC_CMD",
+		18: "INTERCEPT_CONFLICT",
+		19: "UNKNOWN_PRESET",
+		20: "INVALID_VALUE",
+		21: "TOO_MANY_INTERCEPTS",
 	}
 	InterceptError_value = map[string]int32{
 		"UNSPECIFIED":                0,
@@ -86,6 +94,10 @@ var (
 		"MOUNT_POINT_BUSY":           13,
 		"UNKNOWN_FLAG":               15,
 		"EXEC_CMD":                   16,
+		"INTERCEPT_CONFLICT":         18,
+		"UNKNOWN_PRESET":             19,
+		"INVALID_VALUE":              20,
+		"TOO_MANY_INTERCEPTS":        21,
 	}
 )
 
@@ -242,7 +254,7 @@ var file_common_errors_proto_rawDesc = []byte{
 	0x04, 0x55, 0x53, 0x45, 0x52, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x4f, 0x4e, 0x46, 0x49,
 	0x47, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x4e, 0x4f, 0x5f, 0x44, 0x41, 0x45, 0x4d, 0x4f, 0x4e,
 	0x5f, 0x4c, 0x4f, 0x47, 0x53, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
-	0x57, 0x4e, 0x10, 0x04, 0x2a, 0xa0, 0x03, 0x0a, 0x0e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65,
+	0x57, 0x4e, 0x10, 0x04, 0x2a, 0xf8, 0x03, 0x0a, 0x0e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65,
 	0x70, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x50, 0x45,
 	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x49, 0x4e, 0x54, 0x45,
 	0x52, 0x4e, 0x41, 0x4c, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x4e, 0x4f, 0x5f, 0x43, 0x4f, 0x4e,
@@ -268,11 +280,16 @@ var file_common_errors_proto_rawDesc = []byte{
 	0x0c, 0x12, 0x14, 0x0a, 0x10, 0x4d, 0x4f, 0x55, 0x4e, 0x54, 0x5f, 0x50, 0x4f, 0x49, 0x4e, 0x54,
 	0x5f, 0x42, 0x55, 0x53, 0x59, 0x10, 0x0d, 0x12, 0x10, 0x0a, 0x0c, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
 	0x57, 0x4e, 0x5f, 0x46, 0x4c, 0x41, 0x47, 0x10, 0x0f, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x58, 0x45,
-	0x43, 0x5f, 0x43, 0x4d, 0x44, 0x10, 0x10, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x65, 0x6c, 0x65, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e,
-	0x63, 0x65, 0x69, 0x6f, 0x2f, 0x74, 0x65, 0x6c, 0x65, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x63,
-	0x65, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76, 0x32, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x43, 0x5f, 0x43, 0x4d, 0x44, 0x10, 0x10, 0x12, 0x16, 0x0a, 0x12, 0x49, 0x4e, 0x54, 0x45, 0x52,
+	0x43, 0x45, 0x50, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x4c, 0x49, 0x43, 0x54, 0x10, 0x12, 0x12,
+	0x12, 0x0a, 0x0e, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x50, 0x52, 0x45, 0x53, 0x45,
+	0x54, 0x10, 0x13, 0x12, 0x11, 0x0a, 0x0d, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x56,
+	0x41, 0x4c, 0x55, 0x45, 0x10, 0x14, 0x12, 0x17, 0x0a, 0x13, 0x54, 0x4f, 0x4f, 0x5f, 0x4d, 0x41,
+	0x4e, 0x59, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x43, 0x45, 0x50, 0x54, 0x53, 0x10, 0x15, 0x42,
+	0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x65,
+	0x6c, 0x65, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x63, 0x65, 0x69, 0x6f, 0x2f, 0x74, 0x65, 0x6c,
+	0x65, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x63, 0x65, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76, 0x32,
+	0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -23,37 +23,60 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Connector_Version_FullMethodName                 = "/telepresence.connector.Connector/Version"
-	Connector_RootDaemonVersion_FullMethodName       = "/telepresence.connector.Connector/RootDaemonVersion"
-	Connector_TrafficManagerVersion_FullMethodName   = "/telepresence.connector.Connector/TrafficManagerVersion"
-	Connector_AgentImageFQN_FullMethodName           = "/telepresence.connector.Connector/AgentImageFQN"
-	Connector_GetIntercept_FullMethodName            = "/telepresence.connector.Connector/GetIntercept"
-	Connector_Connect_FullMethodName                 = "/telepresence.connector.Connector/Connect"
-	Connector_Disconnect_FullMethodName              = "/telepresence.connector.Connector/Disconnect"
-	Connector_GetClusterSubnets_FullMethodName       = "/telepresence.connector.Connector/GetClusterSubnets"
-	Connector_Status_FullMethodName                  = "/telepresence.connector.Connector/Status"
-	Connector_CanIntercept_FullMethodName            = "/telepresence.connector.Connector/CanIntercept"
-	Connector_Ingest_FullMethodName                  = "/telepresence.connector.Connector/Ingest"
-	Connector_GetIngest_FullMethodName               = "/telepresence.connector.Connector/GetIngest"
-	Connector_LeaveIngest_FullMethodName             = "/telepresence.connector.Connector/LeaveIngest"
-	Connector_CreateIntercept_FullMethodName         = "/telepresence.connector.Connector/CreateIntercept"
-	Connector_RemoveIntercept_FullMethodName         = "/telepresence.connector.Connector/RemoveIntercept"
-	Connector_UpdateIntercept_FullMethodName         = "/telepresence.connector.Connector/UpdateIntercept"
-	Connector_Uninstall_FullMethodName               = "/telepresence.connector.Connector/Uninstall"
-	Connector_List_FullMethodName                    = "/telepresence.connector.Connector/List"
-	Connector_WatchWorkloads_FullMethodName          = "/telepresence.connector.Connector/WatchWorkloads"
-	Connector_SetLogLevel_FullMethodName             = "/telepresence.connector.Connector/SetLogLevel"
-	Connector_Quit_FullMethodName                    = "/telepresence.connector.Connector/Quit"
-	Connector_GatherLogs_FullMethodName              = "/telepresence.connector.Connector/GatherLogs"
-	Connector_AddInterceptor_FullMethodName          = "/telepresence.connector.Connector/AddInterceptor"
-	Connector_RemoveInterceptor_FullMethodName       = "/telepresence.connector.Connector/RemoveInterceptor"
-	Connector_GetNamespaces_FullMethodName           = "/telepresence.connector.Connector/GetNamespaces"
-	Connector_GetKnownWorkloadKinds_FullMethodName   = "/telepresence.connector.Connector/GetKnownWorkloadKinds"
-	Connector_RemoteMountAvailability_FullMethodName = "/telepresence.connector.Connector/RemoteMountAvailability"
-	Connector_GetConfig_FullMethodName               = "/telepresence.connector.Connector/GetConfig"
-	Connector_SetDNSExcludes_FullMethodName          = "/telepresence.connector.Connector/SetDNSExcludes"
-	Connector_SetDNSMappings_FullMethodName          = "/telepresence.connector.Connector/SetDNSMappings"
-	Connector_GetAgentConfig_FullMethodName          = "/telepresence.connector.Connector/GetAgentConfig"
+	Connector_Version_FullMethodName                  = "/telepresence.connector.Connector/Version"
+	Connector_RootDaemonVersion_FullMethodName        = "/telepresence.connector.Connector/RootDaemonVersion"
+	Connector_TrafficManagerVersion_FullMethodName    = "/telepresence.connector.Connector/TrafficManagerVersion"
+	Connector_AgentImageFQN_FullMethodName            = "/telepresence.connector.Connector/AgentImageFQN"
+	Connector_GetIntercept_FullMethodName             = "/telepresence.connector.Connector/GetIntercept"
+	Connector_Connect_FullMethodName                  = "/telepresence.connector.Connector/Connect"
+	Connector_WatchConnectProgress_FullMethodName     = "/telepresence.connector.Connector/WatchConnectProgress"
+	Connector_Disconnect_FullMethodName               = "/telepresence.connector.Connector/Disconnect"
+	Connector_GetClusterSubnets_FullMethodName        = "/telepresence.connector.Connector/GetClusterSubnets"
+	Connector_Status_FullMethodName                   = "/telepresence.connector.Connector/Status"
+	Connector_CanIntercept_FullMethodName             = "/telepresence.connector.Connector/CanIntercept"
+	Connector_ValidateInterceptSpec_FullMethodName    = "/telepresence.connector.Connector/ValidateInterceptSpec"
+	Connector_Ingest_FullMethodName                   = "/telepresence.connector.Connector/Ingest"
+	Connector_GetIngest_FullMethodName                = "/telepresence.connector.Connector/GetIngest"
+	Connector_LeaveIngest_FullMethodName              = "/telepresence.connector.Connector/LeaveIngest"
+	Connector_IngestsByWorkload_FullMethodName        = "/telepresence.connector.Connector/IngestsByWorkload"
+	Connector_CreateIntercept_FullMethodName          = "/telepresence.connector.Connector/CreateIntercept"
+	Connector_CreateInterceptGroup_FullMethodName     = "/telepresence.connector.Connector/CreateInterceptGroup"
+	Connector_RemoveIntercept_FullMethodName          = "/telepresence.connector.Connector/RemoveIntercept"
+	Connector_UpdateIntercept_FullMethodName          = "/telepresence.connector.Connector/UpdateIntercept"
+	Connector_Uninstall_FullMethodName                = "/telepresence.connector.Connector/Uninstall"
+	Connector_List_FullMethodName                     = "/telepresence.connector.Connector/List"
+	Connector_WatchWorkloads_FullMethodName           = "/telepresence.connector.Connector/WatchWorkloads"
+	Connector_PauseWorkloadWatch_FullMethodName       = "/telepresence.connector.Connector/PauseWorkloadWatch"
+	Connector_ResumeWorkloadWatch_FullMethodName      = "/telepresence.connector.Connector/ResumeWorkloadWatch"
+	Connector_SetLogLevel_FullMethodName              = "/telepresence.connector.Connector/SetLogLevel"
+	Connector_Quit_FullMethodName                     = "/telepresence.connector.Connector/Quit"
+	Connector_GatherLogs_FullMethodName               = "/telepresence.connector.Connector/GatherLogs"
+	Connector_AddInterceptor_FullMethodName           = "/telepresence.connector.Connector/AddInterceptor"
+	Connector_RemoveInterceptor_FullMethodName        = "/telepresence.connector.Connector/RemoveInterceptor"
+	Connector_GetNamespaces_FullMethodName            = "/telepresence.connector.Connector/GetNamespaces"
+	Connector_GetKnownWorkloadKinds_FullMethodName    = "/telepresence.connector.Connector/GetKnownWorkloadKinds"
+	Connector_RemoteMountAvailability_FullMethodName  = "/telepresence.connector.Connector/RemoteMountAvailability"
+	Connector_GetConfig_FullMethodName                = "/telepresence.connector.Connector/GetConfig"
+	Connector_ExportDiagnostics_FullMethodName        = "/telepresence.connector.Connector/ExportDiagnostics"
+	Connector_SetDNSExcludes_FullMethodName           = "/telepresence.connector.Connector/SetDNSExcludes"
+	Connector_SetDNSMappings_FullMethodName           = "/telepresence.connector.Connector/SetDNSMappings"
+	Connector_GetAgentConfig_FullMethodName           = "/telepresence.connector.Connector/GetAgentConfig"
+	Connector_ActiveForwarders_FullMethodName         = "/telepresence.connector.Connector/ActiveForwarders"
+	Connector_CheckPermissions_FullMethodName         = "/telepresence.connector.Connector/CheckPermissions"
+	Connector_RecentIntercepts_FullMethodName         = "/telepresence.connector.Connector/RecentIntercepts"
+	Connector_WorkloadInterceptHistory_FullMethodName = "/telepresence.connector.Connector/WorkloadInterceptHistory"
+	Connector_ExportActiveConfig_FullMethodName       = "/telepresence.connector.Connector/ExportActiveConfig"
+	Connector_ImportActiveConfig_FullMethodName       = "/telepresence.connector.Connector/ImportActiveConfig"
+	Connector_InterceptEnvironment_FullMethodName     = "/telepresence.connector.Connector/InterceptEnvironment"
+	Connector_StreamAgentLogs_FullMethodName          = "/telepresence.connector.Connector/StreamAgentLogs"
+	Connector_ConnectedClients_FullMethodName         = "/telepresence.connector.Connector/ConnectedClients"
+	Connector_AgentEnv_FullMethodName                 = "/telepresence.connector.Connector/AgentEnv"
+	Connector_RoutingTable_FullMethodName             = "/telepresence.connector.Connector/RoutingTable"
+	Connector_PreviewDNSDomains_FullMethodName        = "/telepresence.connector.Connector/PreviewDNSDomains"
+	Connector_WatchSessionEvents_FullMethodName       = "/telepresence.connector.Connector/WatchSessionEvents"
+	Connector_PruneSessions_FullMethodName            = "/telepresence.connector.Connector/PruneSessions"
+	Connector_ConnectAndIntercept_FullMethodName      = "/telepresence.connector.Connector/ConnectAndIntercept"
+	Connector_RestartWorkload_FullMethodName          = "/telepresence.connector.Connector/RestartWorkload"
 )
 
 // ConnectorClient is the client API for Connector service.
@@ -80,6 +103,11 @@ type ConnectorClient interface {
 	// MUST_RESTART is returned, based on whether the current connection
 	// is in agreement with the ConnectionRequest.
 	Connect(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*ConnectInfo, error)
+	// WatchConnectProgress streams the phases that a Connect goes through. The first
+	// message is sent immediately and carries the current phase, which is UNSPECIFIED
+	// when no connect is in progress. A client that wants to render the progress of a
+	// Connect should receive that first message before it calls Connect.
+	WatchConnectProgress(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConnectProgress], error)
 	// Disconnects the cluster
 	Disconnect(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// GetClusterSubnets gets the outbound info that has been set on daemon
@@ -89,15 +117,24 @@ type ConnectorClient interface {
 	Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ConnectInfo, error)
 	// Queries the connector whether it is possible to create the given intercept.
 	CanIntercept(ctx context.Context, in *CreateInterceptRequest, opts ...grpc.CallOption) (*InterceptResult, error)
+	// Validates the given intercept request without creating the intercept, and without
+	// changing anything in the cluster. All validation errors are returned.
+	ValidateInterceptSpec(ctx context.Context, in *CreateInterceptRequest, opts ...grpc.CallOption) (*InterceptValidationResult, error)
 	// Starts an Ingest session.
 	Ingest(ctx context.Context, in *IngestRequest, opts ...grpc.CallOption) (*IngestInfo, error)
 	// Get info about an ongoing Ingest.
 	GetIngest(ctx context.Context, in *IngestIdentifier, opts ...grpc.CallOption) (*IngestInfo, error)
 	// Ends an Ingest session.
 	LeaveIngest(ctx context.Context, in *IngestIdentifier, opts ...grpc.CallOption) (*IngestInfo, error)
+	// Lists the active ingests grouped by the name of the ingested workload.
+	IngestsByWorkload(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*IngestsByWorkloadResponse, error)
 	// Adds an intercept to a workload.  Requires having already called
 	// Connect.
 	CreateIntercept(ctx context.Context, in *CreateInterceptRequest, opts ...grpc.CallOption) (*InterceptResult, error)
+	// Adds a group of intercepts, typically of different ports of the same workload,
+	// as one operation. Either all intercepts in the group are created, or none of them.
+	// Requires having already called Connect.
+	CreateInterceptGroup(ctx context.Context, in *CreateInterceptGroupRequest, opts ...grpc.CallOption) (*InterceptGroupResult, error)
 	// Deactivates and removes an existent workload intercept.
 	// Requires having already called Connect.
 	RemoveIntercept(ctx context.Context, in *manager.RemoveInterceptRequest2, opts ...grpc.CallOption) (*InterceptResult, error)
@@ -110,6 +147,14 @@ type ConnectorClient interface {
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*WorkloadInfoSnapshot, error)
 	// Watch all workloads in the mapped namespaces
 	WatchWorkloads(ctx context.Context, in *WatchWorkloadsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WorkloadInfoSnapshot], error)
+	// PauseWorkloadWatch stops the notifications of the WatchWorkloads streams, e.g.
+	// during a large rollout, until ResumeWorkloadWatch is called with the returned
+	// ID, or the duration of the pause has passed.
+	PauseWorkloadWatch(ctx context.Context, in *PauseWorkloadWatchRequest, opts ...grpc.CallOption) (*PauseWorkloadWatchResponse, error)
+	// ResumeWorkloadWatch ends a pause made using PauseWorkloadWatch. Each stream of
+	// WatchWorkloads sends a snapshot that reflects the changes made while it was
+	// paused once no pause remains.
+	ResumeWorkloadWatch(ctx context.Context, in *ResumeWorkloadWatchRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// SetLogLevel will temporarily change the log-level of the traffic-manager, traffic-agent, and user and root daemons.
 	SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Quits (terminates) the connector process.
@@ -131,12 +176,73 @@ type ConnectorClient interface {
 	RemoteMountAvailability(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*common.Result, error)
 	// GetConfig returns the current configuration
 	GetConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ClientConfig, error)
+	// ExportDiagnostics returns a zip archive with the state of the session: the effective
+	// config, the mapped namespaces, the current intercepts and ingests, the traffic-manager,
+	// the workload watcher errors, and the status of the root daemon. Secrets are redacted.
+	ExportDiagnostics(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DiagnosticsBundle, error)
 	// SetDNSExcludes sets the excludes field of DNSConfig.
 	SetDNSExcludes(ctx context.Context, in *daemon.SetDNSExcludesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// SetDNSMappings sets the Mappings field of DNSConfig.
 	SetDNSMappings(ctx context.Context, in *daemon.SetDNSMappingsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// GetAgentConfig returns the agent configuration for a specific workload.
 	GetAgentConfig(ctx context.Context, in *manager.AgentConfigRequest, opts ...grpc.CallOption) (*manager.AgentConfigResponse, error)
+	// ActiveForwarders returns a snapshot of all forwarders that are active in the
+	// current session. Intended for debugging.
+	ActiveForwarders(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ActiveForwardersResponse, error)
+	// CheckPermissions checks that the user has the RBAC permissions that a session
+	// will need, and reports the ones that are missing. The cluster of the current
+	// session is checked, or the cluster given by the request when not connected.
+	CheckPermissions(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*PermissionsReport, error)
+	// RecentIntercepts returns the intercepts that ended recently in the current
+	// session, most recent first. The number of intercepts that are retained is
+	// controlled by the intercept.historySize setting of the client configuration.
+	RecentIntercepts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RecentInterceptsResponse, error)
+	// WorkloadInterceptHistory returns the intercepts of a workload that the
+	// traffic-manager has recorded, regardless of what client created them.
+	WorkloadInterceptHistory(ctx context.Context, in *WorkloadInterceptHistoryRequest, opts ...grpc.CallOption) (*WorkloadInterceptHistoryResponse, error)
+	// ExportActiveConfig returns the intercepts and ingests of the current session
+	// in a form that can be saved, and later be passed to ImportActiveConfig.
+	ExportActiveConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ActiveConfig, error)
+	// ImportActiveConfig recreates the intercepts and ingests of the given config in
+	// the current session, and reports the result of each one of them.
+	ImportActiveConfig(ctx context.Context, in *ActiveConfig, opts ...grpc.CallOption) (*ImportActiveConfigResponse, error)
+	// InterceptEnvironment returns the environment of a current intercept in the
+	// requested syntax.
+	InterceptEnvironment(ctx context.Context, in *InterceptEnvironmentRequest, opts ...grpc.CallOption) (*InterceptEnvironmentResponse, error)
+	// StreamAgentLogs streams the logs of the traffic-agent containers of a workload.
+	StreamAgentLogs(ctx context.Context, in *AgentLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentLogChunk], error)
+	// ConnectedClients returns the clients that are connected to the traffic-manager.
+	ConnectedClients(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*manager.ConnectedClients, error)
+	// AgentEnv returns the settings that the traffic-manager applies to the
+	// environment of intercepted containers, e.g. the names of the variables
+	// that it excludes.
+	AgentEnv(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*manager.AgentEnv, error)
+	// RoutingTable returns the effective routing table of the session, i.e.
+	// the subnets that are proxied, never proxied, or routed via a workload.
+	RoutingTable(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.RoutingTable, error)
+	// PreviewDNSDomains returns the DNS search domains that a session created from
+	// the given request would post to the root daemon. Nothing is connected, and the
+	// root daemon isn't contacted.
+	PreviewDNSDomains(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*DNSDomainsPreview, error)
+	// WatchSessionEvents streams the events of the current session, such as changes to
+	// the mapped namespaces, workload transitions, and the intercept lifecycle. Events
+	// are sent in the order that they were emitted. A subscriber that falls behind loses
+	// its oldest unread events, and the number of lost events is reported by the next
+	// event that it receives. The stream ends after the SessionEnded event.
+	WatchSessionEvents(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SessionEvent], error)
+	// PruneSessions validates the sessions that the user cache holds for all daemons
+	// and removes the ones that the traffic-manager no longer knows. Sessions that
+	// belong to the cluster of another kube context, or whose traffic-manager cannot
+	// be reached, are left untouched.
+	PruneSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PruneSessionsResponse, error)
+	// ConnectAndIntercept connects, or reuses the current session, creates an intercept,
+	// and waits until the intercept is active and its port-forwards, mounts, and API
+	// server have started. The intercept is removed again when it isn't ready before the
+	// timeout expires.
+	ConnectAndIntercept(ctx context.Context, in *ConnectAndInterceptRequest, opts ...grpc.CallOption) (*ConnectAndInterceptResponse, error)
+	// RestartWorkload triggers a rolling restart of a workload in a mapped namespace, which
+	// re-injects or refreshes its traffic-agent.
+	RestartWorkload(ctx context.Context, in *RestartWorkloadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type connectorClient struct {
@@ -207,6 +313,25 @@ func (c *connectorClient) Connect(ctx context.Context, in *ConnectRequest, opts
 	return out, nil
 }
 
+func (c *connectorClient) WatchConnectProgress(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConnectProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[0], Connector_WatchConnectProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, ConnectProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Connector_WatchConnectProgressClient = grpc.ServerStreamingClient[ConnectProgress]
+
 func (c *connectorClient) Disconnect(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -247,6 +372,16 @@ func (c *connectorClient) CanIntercept(ctx context.Context, in *CreateInterceptR
 	return out, nil
 }
 
+func (c *connectorClient) ValidateInterceptSpec(ctx context.Context, in *CreateInterceptRequest, opts ...grpc.CallOption) (*InterceptValidationResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InterceptValidationResult)
+	err := c.cc.Invoke(ctx, Connector_ValidateInterceptSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *connectorClient) Ingest(ctx context.Context, in *IngestRequest, opts ...grpc.CallOption) (*IngestInfo, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(IngestInfo)
@@ -277,6 +412,16 @@ func (c *connectorClient) LeaveIngest(ctx context.Context, in *IngestIdentifier,
 	return out, nil
 }
 
+func (c *connectorClient) IngestsByWorkload(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*IngestsByWorkloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IngestsByWorkloadResponse)
+	err := c.cc.Invoke(ctx, Connector_IngestsByWorkload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *connectorClient) CreateIntercept(ctx context.Context, in *CreateInterceptRequest, opts ...grpc.CallOption) (*InterceptResult, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(InterceptResult)
@@ -287,6 +432,16 @@ func (c *connectorClient) CreateIntercept(ctx context.Context, in *CreateInterce
 	return out, nil
 }
 
+func (c *connectorClient) CreateInterceptGroup(ctx context.Context, in *CreateInterceptGroupRequest, opts ...grpc.CallOption) (*InterceptGroupResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InterceptGroupResult)
+	err := c.cc.Invoke(ctx, Connector_CreateInterceptGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *connectorClient) RemoveIntercept(ctx context.Context, in *manager.RemoveInterceptRequest2, opts ...grpc.CallOption) (*InterceptResult, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(InterceptResult)
@@ -329,7 +484,7 @@ func (c *connectorClient) List(ctx context.Context, in *ListRequest, opts ...grp
 
 func (c *connectorClient) WatchWorkloads(ctx context.Context, in *WatchWorkloadsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WorkloadInfoSnapshot], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[0], Connector_WatchWorkloads_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[1], Connector_WatchWorkloads_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -346,6 +501,26 @@ func (c *connectorClient) WatchWorkloads(ctx context.Context, in *WatchWorkloads
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Connector_WatchWorkloadsClient = grpc.ServerStreamingClient[WorkloadInfoSnapshot]
 
+func (c *connectorClient) PauseWorkloadWatch(ctx context.Context, in *PauseWorkloadWatchRequest, opts ...grpc.CallOption) (*PauseWorkloadWatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseWorkloadWatchResponse)
+	err := c.cc.Invoke(ctx, Connector_PauseWorkloadWatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) ResumeWorkloadWatch(ctx context.Context, in *ResumeWorkloadWatchRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Connector_ResumeWorkloadWatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *connectorClient) SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -436,6 +611,16 @@ func (c *connectorClient) GetConfig(ctx context.Context, in *emptypb.Empty, opts
 	return out, nil
 }
 
+func (c *connectorClient) ExportDiagnostics(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DiagnosticsBundle, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiagnosticsBundle)
+	err := c.cc.Invoke(ctx, Connector_ExportDiagnostics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *connectorClient) SetDNSExcludes(ctx context.Context, in *daemon.SetDNSExcludesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -466,6 +651,184 @@ func (c *connectorClient) GetAgentConfig(ctx context.Context, in *manager.AgentC
 	return out, nil
 }
 
+func (c *connectorClient) ActiveForwarders(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ActiveForwardersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActiveForwardersResponse)
+	err := c.cc.Invoke(ctx, Connector_ActiveForwarders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) CheckPermissions(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*PermissionsReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PermissionsReport)
+	err := c.cc.Invoke(ctx, Connector_CheckPermissions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) RecentIntercepts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RecentInterceptsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecentInterceptsResponse)
+	err := c.cc.Invoke(ctx, Connector_RecentIntercepts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) WorkloadInterceptHistory(ctx context.Context, in *WorkloadInterceptHistoryRequest, opts ...grpc.CallOption) (*WorkloadInterceptHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WorkloadInterceptHistoryResponse)
+	err := c.cc.Invoke(ctx, Connector_WorkloadInterceptHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) ExportActiveConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ActiveConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActiveConfig)
+	err := c.cc.Invoke(ctx, Connector_ExportActiveConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) ImportActiveConfig(ctx context.Context, in *ActiveConfig, opts ...grpc.CallOption) (*ImportActiveConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportActiveConfigResponse)
+	err := c.cc.Invoke(ctx, Connector_ImportActiveConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) InterceptEnvironment(ctx context.Context, in *InterceptEnvironmentRequest, opts ...grpc.CallOption) (*InterceptEnvironmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InterceptEnvironmentResponse)
+	err := c.cc.Invoke(ctx, Connector_InterceptEnvironment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) StreamAgentLogs(ctx context.Context, in *AgentLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentLogChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[2], Connector_StreamAgentLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AgentLogsRequest, AgentLogChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Connector_StreamAgentLogsClient = grpc.ServerStreamingClient[AgentLogChunk]
+
+func (c *connectorClient) ConnectedClients(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*manager.ConnectedClients, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(manager.ConnectedClients)
+	err := c.cc.Invoke(ctx, Connector_ConnectedClients_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) AgentEnv(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*manager.AgentEnv, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(manager.AgentEnv)
+	err := c.cc.Invoke(ctx, Connector_AgentEnv_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) RoutingTable(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.RoutingTable, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(daemon.RoutingTable)
+	err := c.cc.Invoke(ctx, Connector_RoutingTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) PreviewDNSDomains(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*DNSDomainsPreview, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DNSDomainsPreview)
+	err := c.cc.Invoke(ctx, Connector_PreviewDNSDomains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) WatchSessionEvents(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SessionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[3], Connector_WatchSessionEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, SessionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Connector_WatchSessionEventsClient = grpc.ServerStreamingClient[SessionEvent]
+
+func (c *connectorClient) PruneSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PruneSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PruneSessionsResponse)
+	err := c.cc.Invoke(ctx, Connector_PruneSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) ConnectAndIntercept(ctx context.Context, in *ConnectAndInterceptRequest, opts ...grpc.CallOption) (*ConnectAndInterceptResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConnectAndInterceptResponse)
+	err := c.cc.Invoke(ctx, Connector_ConnectAndIntercept_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) RestartWorkload(ctx context.Context, in *RestartWorkloadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Connector_RestartWorkload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConnectorServer is the server API for Connector service.
 // All implementations must embed UnimplementedConnectorServer
 // for forward compatibility.
@@ -490,6 +853,11 @@ type ConnectorServer interface {
 	// MUST_RESTART is returned, based on whether the current connection
 	// is in agreement with the ConnectionRequest.
 	Connect(context.Context, *ConnectRequest) (*ConnectInfo, error)
+	// WatchConnectProgress streams the phases that a Connect goes through. The first
+	// message is sent immediately and carries the current phase, which is UNSPECIFIED
+	// when no connect is in progress. A client that wants to render the progress of a
+	// Connect should receive that first message before it calls Connect.
+	WatchConnectProgress(*emptypb.Empty, grpc.ServerStreamingServer[ConnectProgress]) error
 	// Disconnects the cluster
 	Disconnect(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	// GetClusterSubnets gets the outbound info that has been set on daemon
@@ -499,15 +867,24 @@ type ConnectorServer interface {
 	Status(context.Context, *emptypb.Empty) (*ConnectInfo, error)
 	// Queries the connector whether it is possible to create the given intercept.
 	CanIntercept(context.Context, *CreateInterceptRequest) (*InterceptResult, error)
+	// Validates the given intercept request without creating the intercept, and without
+	// changing anything in the cluster. All validation errors are returned.
+	ValidateInterceptSpec(context.Context, *CreateInterceptRequest) (*InterceptValidationResult, error)
 	// Starts an Ingest session.
 	Ingest(context.Context, *IngestRequest) (*IngestInfo, error)
 	// Get info about an ongoing Ingest.
 	GetIngest(context.Context, *IngestIdentifier) (*IngestInfo, error)
 	// Ends an Ingest session.
 	LeaveIngest(context.Context, *IngestIdentifier) (*IngestInfo, error)
+	// Lists the active ingests grouped by the name of the ingested workload.
+	IngestsByWorkload(context.Context, *emptypb.Empty) (*IngestsByWorkloadResponse, error)
 	// Adds an intercept to a workload.  Requires having already called
 	// Connect.
 	CreateIntercept(context.Context, *CreateInterceptRequest) (*InterceptResult, error)
+	// Adds a group of intercepts, typically of different ports of the same workload,
+	// as one operation. Either all intercepts in the group are created, or none of them.
+	// Requires having already called Connect.
+	CreateInterceptGroup(context.Context, *CreateInterceptGroupRequest) (*InterceptGroupResult, error)
 	// Deactivates and removes an existent workload intercept.
 	// Requires having already called Connect.
 	RemoveIntercept(context.Context, *manager.RemoveInterceptRequest2) (*InterceptResult, error)
@@ -520,6 +897,14 @@ type ConnectorServer interface {
 	List(context.Context, *ListRequest) (*WorkloadInfoSnapshot, error)
 	// Watch all workloads in the mapped namespaces
 	WatchWorkloads(*WatchWorkloadsRequest, grpc.ServerStreamingServer[WorkloadInfoSnapshot]) error
+	// PauseWorkloadWatch stops the notifications of the WatchWorkloads streams, e.g.
+	// during a large rollout, until ResumeWorkloadWatch is called with the returned
+	// ID, or the duration of the pause has passed.
+	PauseWorkloadWatch(context.Context, *PauseWorkloadWatchRequest) (*PauseWorkloadWatchResponse, error)
+	// ResumeWorkloadWatch ends a pause made using PauseWorkloadWatch. Each stream of
+	// WatchWorkloads sends a snapshot that reflects the changes made while it was
+	// paused once no pause remains.
+	ResumeWorkloadWatch(context.Context, *ResumeWorkloadWatchRequest) (*emptypb.Empty, error)
 	// SetLogLevel will temporarily change the log-level of the traffic-manager, traffic-agent, and user and root daemons.
 	SetLogLevel(context.Context, *LogLevelRequest) (*emptypb.Empty, error)
 	// Quits (terminates) the connector process.
@@ -541,12 +926,73 @@ type ConnectorServer interface {
 	RemoteMountAvailability(context.Context, *emptypb.Empty) (*common.Result, error)
 	// GetConfig returns the current configuration
 	GetConfig(context.Context, *emptypb.Empty) (*ClientConfig, error)
+	// ExportDiagnostics returns a zip archive with the state of the session: the effective
+	// config, the mapped namespaces, the current intercepts and ingests, the traffic-manager,
+	// the workload watcher errors, and the status of the root daemon. Secrets are redacted.
+	ExportDiagnostics(context.Context, *emptypb.Empty) (*DiagnosticsBundle, error)
 	// SetDNSExcludes sets the excludes field of DNSConfig.
 	SetDNSExcludes(context.Context, *daemon.SetDNSExcludesRequest) (*emptypb.Empty, error)
 	// SetDNSMappings sets the Mappings field of DNSConfig.
 	SetDNSMappings(context.Context, *daemon.SetDNSMappingsRequest) (*emptypb.Empty, error)
 	// GetAgentConfig returns the agent configuration for a specific workload.
 	GetAgentConfig(context.Context, *manager.AgentConfigRequest) (*manager.AgentConfigResponse, error)
+	// ActiveForwarders returns a snapshot of all forwarders that are active in the
+	// current session. Intended for debugging.
+	ActiveForwarders(context.Context, *emptypb.Empty) (*ActiveForwardersResponse, error)
+	// CheckPermissions checks that the user has the RBAC permissions that a session
+	// will need, and reports the ones that are missing. The cluster of the current
+	// session is checked, or the cluster given by the request when not connected.
+	CheckPermissions(context.Context, *ConnectRequest) (*PermissionsReport, error)
+	// RecentIntercepts returns the intercepts that ended recently in the current
+	// session, most recent first. The number of intercepts that are retained is
+	// controlled by the intercept.historySize setting of the client configuration.
+	RecentIntercepts(context.Context, *emptypb.Empty) (*RecentInterceptsResponse, error)
+	// WorkloadInterceptHistory returns the intercepts of a workload that the
+	// traffic-manager has recorded, regardless of what client created them.
+	WorkloadInterceptHistory(context.Context, *WorkloadInterceptHistoryRequest) (*WorkloadInterceptHistoryResponse, error)
+	// ExportActiveConfig returns the intercepts and ingests of the current session
+	// in a form that can be saved, and later be passed to ImportActiveConfig.
+	ExportActiveConfig(context.Context, *emptypb.Empty) (*ActiveConfig, error)
+	// ImportActiveConfig recreates the intercepts and ingests of the given config in
+	// the current session, and reports the result of each one of them.
+	ImportActiveConfig(context.Context, *ActiveConfig) (*ImportActiveConfigResponse, error)
+	// InterceptEnvironment returns the environment of a current intercept in the
+	// requested syntax.
+	InterceptEnvironment(context.Context, *InterceptEnvironmentRequest) (*InterceptEnvironmentResponse, error)
+	// StreamAgentLogs streams the logs of the traffic-agent containers of a workload.
+	StreamAgentLogs(*AgentLogsRequest, grpc.ServerStreamingServer[AgentLogChunk]) error
+	// ConnectedClients returns the clients that are connected to the traffic-manager.
+	ConnectedClients(context.Context, *emptypb.Empty) (*manager.ConnectedClients, error)
+	// AgentEnv returns the settings that the traffic-manager applies to the
+	// environment of intercepted containers, e.g. the names of the variables
+	// that it excludes.
+	AgentEnv(context.Context, *emptypb.Empty) (*manager.AgentEnv, error)
+	// RoutingTable returns the effective routing table of the session, i.e.
+	// the subnets that are proxied, never proxied, or routed via a workload.
+	RoutingTable(context.Context, *emptypb.Empty) (*daemon.RoutingTable, error)
+	// PreviewDNSDomains returns the DNS search domains that a session created from
+	// the given request would post to the root daemon. Nothing is connected, and the
+	// root daemon isn't contacted.
+	PreviewDNSDomains(context.Context, *ConnectRequest) (*DNSDomainsPreview, error)
+	// WatchSessionEvents streams the events of the current session, such as changes to
+	// the mapped namespaces, workload transitions, and the intercept lifecycle. Events
+	// are sent in the order that they were emitted. A subscriber that falls behind loses
+	// its oldest unread events, and the number of lost events is reported by the next
+	// event that it receives. The stream ends after the SessionEnded event.
+	WatchSessionEvents(*emptypb.Empty, grpc.ServerStreamingServer[SessionEvent]) error
+	// PruneSessions validates the sessions that the user cache holds for all daemons
+	// and removes the ones that the traffic-manager no longer knows. Sessions that
+	// belong to the cluster of another kube context, or whose traffic-manager cannot
+	// be reached, are left untouched.
+	PruneSessions(context.Context, *emptypb.Empty) (*PruneSessionsResponse, error)
+	// ConnectAndIntercept connects, or reuses the current session, creates an intercept,
+	// and waits until the intercept is active and its port-forwards, mounts, and API
+	// server have started. The intercept is removed again when it isn't ready before the
+	// timeout expires.
+	ConnectAndIntercept(context.Context, *ConnectAndInterceptRequest) (*ConnectAndInterceptResponse, error)
+	// RestartWorkload triggers a rolling restart of a workload in a mapped namespace, which
+	// re-injects or refreshes its traffic-agent.
+	RestartWorkload(context.Context, *RestartWorkloadRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedConnectorServer()
 }
 
@@ -575,6 +1021,9 @@ func (UnimplementedConnectorServer) GetIntercept(context.Context, *manager.GetIn
 func (UnimplementedConnectorServer) Connect(context.Context, *ConnectRequest) (*ConnectInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Connect not implemented")
 }
+func (UnimplementedConnectorServer) WatchConnectProgress(*emptypb.Empty, grpc.ServerStreamingServer[ConnectProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchConnectProgress not implemented")
+}
 func (UnimplementedConnectorServer) Disconnect(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Disconnect not implemented")
 }
@@ -587,6 +1036,9 @@ func (UnimplementedConnectorServer) Status(context.Context, *emptypb.Empty) (*Co
 func (UnimplementedConnectorServer) CanIntercept(context.Context, *CreateInterceptRequest) (*InterceptResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CanIntercept not implemented")
 }
+func (UnimplementedConnectorServer) ValidateInterceptSpec(context.Context, *CreateInterceptRequest) (*InterceptValidationResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateInterceptSpec not implemented")
+}
 func (UnimplementedConnectorServer) Ingest(context.Context, *IngestRequest) (*IngestInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ingest not implemented")
 }
@@ -596,9 +1048,15 @@ func (UnimplementedConnectorServer) GetIngest(context.Context, *IngestIdentifier
 func (UnimplementedConnectorServer) LeaveIngest(context.Context, *IngestIdentifier) (*IngestInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LeaveIngest not implemented")
 }
+func (UnimplementedConnectorServer) IngestsByWorkload(context.Context, *emptypb.Empty) (*IngestsByWorkloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IngestsByWorkload not implemented")
+}
 func (UnimplementedConnectorServer) CreateIntercept(context.Context, *CreateInterceptRequest) (*InterceptResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateIntercept not implemented")
 }
+func (UnimplementedConnectorServer) CreateInterceptGroup(context.Context, *CreateInterceptGroupRequest) (*InterceptGroupResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInterceptGroup not implemented")
+}
 func (UnimplementedConnectorServer) RemoveIntercept(context.Context, *manager.RemoveInterceptRequest2) (*InterceptResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveIntercept not implemented")
 }
@@ -614,6 +1072,12 @@ func (UnimplementedConnectorServer) List(context.Context, *ListRequest) (*Worklo
 func (UnimplementedConnectorServer) WatchWorkloads(*WatchWorkloadsRequest, grpc.ServerStreamingServer[WorkloadInfoSnapshot]) error {
 	return status.Errorf(codes.Unimplemented, "method WatchWorkloads not implemented")
 }
+func (UnimplementedConnectorServer) PauseWorkloadWatch(context.Context, *PauseWorkloadWatchRequest) (*PauseWorkloadWatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseWorkloadWatch not implemented")
+}
+func (UnimplementedConnectorServer) ResumeWorkloadWatch(context.Context, *ResumeWorkloadWatchRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeWorkloadWatch not implemented")
+}
 func (UnimplementedConnectorServer) SetLogLevel(context.Context, *LogLevelRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
 }
@@ -641,6 +1105,9 @@ func (UnimplementedConnectorServer) RemoteMountAvailability(context.Context, *em
 func (UnimplementedConnectorServer) GetConfig(context.Context, *emptypb.Empty) (*ClientConfig, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
 }
+func (UnimplementedConnectorServer) ExportDiagnostics(context.Context, *emptypb.Empty) (*DiagnosticsBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportDiagnostics not implemented")
+}
 func (UnimplementedConnectorServer) SetDNSExcludes(context.Context, *daemon.SetDNSExcludesRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetDNSExcludes not implemented")
 }
@@ -650,6 +1117,54 @@ func (UnimplementedConnectorServer) SetDNSMappings(context.Context, *daemon.SetD
 func (UnimplementedConnectorServer) GetAgentConfig(context.Context, *manager.AgentConfigRequest) (*manager.AgentConfigResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAgentConfig not implemented")
 }
+func (UnimplementedConnectorServer) ActiveForwarders(context.Context, *emptypb.Empty) (*ActiveForwardersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActiveForwarders not implemented")
+}
+func (UnimplementedConnectorServer) CheckPermissions(context.Context, *ConnectRequest) (*PermissionsReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPermissions not implemented")
+}
+func (UnimplementedConnectorServer) RecentIntercepts(context.Context, *emptypb.Empty) (*RecentInterceptsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecentIntercepts not implemented")
+}
+func (UnimplementedConnectorServer) WorkloadInterceptHistory(context.Context, *WorkloadInterceptHistoryRequest) (*WorkloadInterceptHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WorkloadInterceptHistory not implemented")
+}
+func (UnimplementedConnectorServer) ExportActiveConfig(context.Context, *emptypb.Empty) (*ActiveConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportActiveConfig not implemented")
+}
+func (UnimplementedConnectorServer) ImportActiveConfig(context.Context, *ActiveConfig) (*ImportActiveConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportActiveConfig not implemented")
+}
+func (UnimplementedConnectorServer) InterceptEnvironment(context.Context, *InterceptEnvironmentRequest) (*InterceptEnvironmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InterceptEnvironment not implemented")
+}
+func (UnimplementedConnectorServer) StreamAgentLogs(*AgentLogsRequest, grpc.ServerStreamingServer[AgentLogChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAgentLogs not implemented")
+}
+func (UnimplementedConnectorServer) ConnectedClients(context.Context, *emptypb.Empty) (*manager.ConnectedClients, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConnectedClients not implemented")
+}
+func (UnimplementedConnectorServer) AgentEnv(context.Context, *emptypb.Empty) (*manager.AgentEnv, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AgentEnv not implemented")
+}
+func (UnimplementedConnectorServer) RoutingTable(context.Context, *emptypb.Empty) (*daemon.RoutingTable, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RoutingTable not implemented")
+}
+func (UnimplementedConnectorServer) PreviewDNSDomains(context.Context, *ConnectRequest) (*DNSDomainsPreview, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewDNSDomains not implemented")
+}
+func (UnimplementedConnectorServer) WatchSessionEvents(*emptypb.Empty, grpc.ServerStreamingServer[SessionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSessionEvents not implemented")
+}
+func (UnimplementedConnectorServer) PruneSessions(context.Context, *emptypb.Empty) (*PruneSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneSessions not implemented")
+}
+func (UnimplementedConnectorServer) ConnectAndIntercept(context.Context, *ConnectAndInterceptRequest) (*ConnectAndInterceptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConnectAndIntercept not implemented")
+}
+func (UnimplementedConnectorServer) RestartWorkload(context.Context, *RestartWorkloadRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestartWorkload not implemented")
+}
 func (UnimplementedConnectorServer) mustEmbedUnimplementedConnectorServer() {}
 func (UnimplementedConnectorServer) testEmbeddedByValue()                   {}
 
@@ -779,6 +1294,17 @@ func _Connector_Connect_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_WatchConnectProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConnectorServer).WatchConnectProgress(m, &grpc.GenericServerStream[emptypb.Empty, ConnectProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Connector_WatchConnectProgressServer = grpc.ServerStreamingServer[ConnectProgress]
+
 func _Connector_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -851,6 +1377,24 @@ func _Connector_CanIntercept_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_ValidateInterceptSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInterceptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ValidateInterceptSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ValidateInterceptSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ValidateInterceptSpec(ctx, req.(*CreateInterceptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Connector_Ingest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(IngestRequest)
 	if err := dec(in); err != nil {
@@ -905,62 +1449,98 @@ func _Connector_LeaveIngest_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Connector_CreateIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateInterceptRequest)
+func _Connector_IngestsByWorkload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ConnectorServer).CreateIntercept(ctx, in)
+		return srv.(ConnectorServer).IngestsByWorkload(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Connector_CreateIntercept_FullMethodName,
+		FullMethod: Connector_IngestsByWorkload_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ConnectorServer).CreateIntercept(ctx, req.(*CreateInterceptRequest))
+		return srv.(ConnectorServer).IngestsByWorkload(ctx, req.(*emptypb.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Connector_RemoveIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(manager.RemoveInterceptRequest2)
+func _Connector_CreateIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInterceptRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ConnectorServer).RemoveIntercept(ctx, in)
+		return srv.(ConnectorServer).CreateIntercept(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Connector_RemoveIntercept_FullMethodName,
+		FullMethod: Connector_CreateIntercept_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ConnectorServer).RemoveIntercept(ctx, req.(*manager.RemoveInterceptRequest2))
+		return srv.(ConnectorServer).CreateIntercept(ctx, req.(*CreateInterceptRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Connector_UpdateIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(manager.UpdateInterceptRequest)
+func _Connector_CreateInterceptGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInterceptGroupRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ConnectorServer).UpdateIntercept(ctx, in)
+		return srv.(ConnectorServer).CreateInterceptGroup(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Connector_UpdateIntercept_FullMethodName,
+		FullMethod: Connector_CreateInterceptGroup_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ConnectorServer).UpdateIntercept(ctx, req.(*manager.UpdateInterceptRequest))
+		return srv.(ConnectorServer).CreateInterceptGroup(ctx, req.(*CreateInterceptGroupRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Connector_Uninstall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UninstallRequest)
+func _Connector_RemoveIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(manager.RemoveInterceptRequest2)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).RemoveIntercept(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_RemoveIntercept_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).RemoveIntercept(ctx, req.(*manager.RemoveInterceptRequest2))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_UpdateIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(manager.UpdateInterceptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).UpdateIntercept(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_UpdateIntercept_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).UpdateIntercept(ctx, req.(*manager.UpdateInterceptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_Uninstall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UninstallRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -1006,6 +1586,42 @@ func _Connector_WatchWorkloads_Handler(srv interface{}, stream grpc.ServerStream
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Connector_WatchWorkloadsServer = grpc.ServerStreamingServer[WorkloadInfoSnapshot]
 
+func _Connector_PauseWorkloadWatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseWorkloadWatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).PauseWorkloadWatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_PauseWorkloadWatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).PauseWorkloadWatch(ctx, req.(*PauseWorkloadWatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_ResumeWorkloadWatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeWorkloadWatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ResumeWorkloadWatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ResumeWorkloadWatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ResumeWorkloadWatch(ctx, req.(*ResumeWorkloadWatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Connector_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LogLevelRequest)
 	if err := dec(in); err != nil {
@@ -1168,6 +1784,24 @@ func _Connector_GetConfig_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_ExportDiagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ExportDiagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ExportDiagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ExportDiagnostics(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Connector_SetDNSExcludes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(daemon.SetDNSExcludesRequest)
 	if err := dec(in); err != nil {
@@ -1222,6 +1856,280 @@ func _Connector_GetAgentConfig_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_ActiveForwarders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ActiveForwarders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ActiveForwarders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ActiveForwarders(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_CheckPermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).CheckPermissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_CheckPermissions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).CheckPermissions(ctx, req.(*ConnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_RecentIntercepts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).RecentIntercepts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_RecentIntercepts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).RecentIntercepts(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_WorkloadInterceptHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorkloadInterceptHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).WorkloadInterceptHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_WorkloadInterceptHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).WorkloadInterceptHistory(ctx, req.(*WorkloadInterceptHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_ExportActiveConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ExportActiveConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ExportActiveConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ExportActiveConfig(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_ImportActiveConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActiveConfig)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ImportActiveConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ImportActiveConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ImportActiveConfig(ctx, req.(*ActiveConfig))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_InterceptEnvironment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InterceptEnvironmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).InterceptEnvironment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_InterceptEnvironment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).InterceptEnvironment(ctx, req.(*InterceptEnvironmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_StreamAgentLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AgentLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConnectorServer).StreamAgentLogs(m, &grpc.GenericServerStream[AgentLogsRequest, AgentLogChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Connector_StreamAgentLogsServer = grpc.ServerStreamingServer[AgentLogChunk]
+
+func _Connector_ConnectedClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ConnectedClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ConnectedClients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ConnectedClients(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_AgentEnv_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).AgentEnv(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_AgentEnv_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).AgentEnv(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_RoutingTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).RoutingTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_RoutingTable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).RoutingTable(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_PreviewDNSDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).PreviewDNSDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_PreviewDNSDomains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).PreviewDNSDomains(ctx, req.(*ConnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_WatchSessionEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConnectorServer).WatchSessionEvents(m, &grpc.GenericServerStream[emptypb.Empty, SessionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Connector_WatchSessionEventsServer = grpc.ServerStreamingServer[SessionEvent]
+
+func _Connector_PruneSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).PruneSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_PruneSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).PruneSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_ConnectAndIntercept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectAndInterceptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).ConnectAndIntercept(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_ConnectAndIntercept_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).ConnectAndIntercept(ctx, req.(*ConnectAndInterceptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_RestartWorkload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartWorkloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).RestartWorkload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_RestartWorkload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).RestartWorkload(ctx, req.(*RestartWorkloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Connector_ServiceDesc is the grpc.ServiceDesc for Connector service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1269,6 +2177,10 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CanIntercept",
 			Handler:    _Connector_CanIntercept_Handler,
 		},
+		{
+			MethodName: "ValidateInterceptSpec",
+			Handler:    _Connector_ValidateInterceptSpec_Handler,
+		},
 		{
 			MethodName: "Ingest",
 			Handler:    _Connector_Ingest_Handler,
@@ -1281,10 +2193,18 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "LeaveIngest",
 			Handler:    _Connector_LeaveIngest_Handler,
 		},
+		{
+			MethodName: "IngestsByWorkload",
+			Handler:    _Connector_IngestsByWorkload_Handler,
+		},
 		{
 			MethodName: "CreateIntercept",
 			Handler:    _Connector_CreateIntercept_Handler,
 		},
+		{
+			MethodName: "CreateInterceptGroup",
+			Handler:    _Connector_CreateInterceptGroup_Handler,
+		},
 		{
 			MethodName: "RemoveIntercept",
 			Handler:    _Connector_RemoveIntercept_Handler,
@@ -1301,6 +2221,14 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _Connector_List_Handler,
 		},
+		{
+			MethodName: "PauseWorkloadWatch",
+			Handler:    _Connector_PauseWorkloadWatch_Handler,
+		},
+		{
+			MethodName: "ResumeWorkloadWatch",
+			Handler:    _Connector_ResumeWorkloadWatch_Handler,
+		},
 		{
 			MethodName: "SetLogLevel",
 			Handler:    _Connector_SetLogLevel_Handler,
@@ -1337,6 +2265,10 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfig",
 			Handler:    _Connector_GetConfig_Handler,
 		},
+		{
+			MethodName: "ExportDiagnostics",
+			Handler:    _Connector_ExportDiagnostics_Handler,
+		},
 		{
 			MethodName: "SetDNSExcludes",
 			Handler:    _Connector_SetDNSExcludes_Handler,
@@ -1349,13 +2281,84 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAgentConfig",
 			Handler:    _Connector_GetAgentConfig_Handler,
 		},
+		{
+			MethodName: "ActiveForwarders",
+			Handler:    _Connector_ActiveForwarders_Handler,
+		},
+		{
+			MethodName: "CheckPermissions",
+			Handler:    _Connector_CheckPermissions_Handler,
+		},
+		{
+			MethodName: "RecentIntercepts",
+			Handler:    _Connector_RecentIntercepts_Handler,
+		},
+		{
+			MethodName: "WorkloadInterceptHistory",
+			Handler:    _Connector_WorkloadInterceptHistory_Handler,
+		},
+		{
+			MethodName: "ExportActiveConfig",
+			Handler:    _Connector_ExportActiveConfig_Handler,
+		},
+		{
+			MethodName: "ImportActiveConfig",
+			Handler:    _Connector_ImportActiveConfig_Handler,
+		},
+		{
+			MethodName: "InterceptEnvironment",
+			Handler:    _Connector_InterceptEnvironment_Handler,
+		},
+		{
+			MethodName: "ConnectedClients",
+			Handler:    _Connector_ConnectedClients_Handler,
+		},
+		{
+			MethodName: "AgentEnv",
+			Handler:    _Connector_AgentEnv_Handler,
+		},
+		{
+			MethodName: "RoutingTable",
+			Handler:    _Connector_RoutingTable_Handler,
+		},
+		{
+			MethodName: "PreviewDNSDomains",
+			Handler:    _Connector_PreviewDNSDomains_Handler,
+		},
+		{
+			MethodName: "PruneSessions",
+			Handler:    _Connector_PruneSessions_Handler,
+		},
+		{
+			MethodName: "ConnectAndIntercept",
+			Handler:    _Connector_ConnectAndIntercept_Handler,
+		},
+		{
+			MethodName: "RestartWorkload",
+			Handler:    _Connector_RestartWorkload_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchConnectProgress",
+			Handler:       _Connector_WatchConnectProgress_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "WatchWorkloads",
 			Handler:       _Connector_WatchWorkloads_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamAgentLogs",
+			Handler:       _Connector_StreamAgentLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSessionEvents",
+			Handler:       _Connector_WatchSessionEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "connector/connector.proto",
 }
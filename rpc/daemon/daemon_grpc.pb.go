@@ -35,6 +35,8 @@ const (
 	Daemon_TranslateEnvIPs_FullMethodName       = "/telepresence.daemon.Daemon/TranslateEnvIPs"
 	Daemon_WaitForNetwork_FullMethodName        = "/telepresence.daemon.Daemon/WaitForNetwork"
 	Daemon_WaitForAgentIP_FullMethodName        = "/telepresence.daemon.Daemon/WaitForAgentIP"
+	Daemon_GetDialActivity_FullMethodName       = "/telepresence.daemon.Daemon/GetDialActivity"
+	Daemon_GetRoutingTable_FullMethodName       = "/telepresence.daemon.Daemon/GetRoutingTable"
 )
 
 // DaemonClient is the client API for Daemon service.
@@ -70,6 +72,11 @@ type DaemonClient interface {
 	WaitForNetwork(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// WaitForAgentIP waits for the network of an intercepted agent to become ready.
 	WaitForAgentIP(ctx context.Context, in *WaitForAgentIPRequest, opts ...grpc.CallOption) (*WaitForAgentIPResponse, error)
+	// GetDialActivity returns the time of the most recent dial request that the root daemon
+	// received from a traffic-agent, for each destination.
+	GetDialActivity(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DialActivity, error)
+	// GetRoutingTable returns the effective routing table of the currently connected session.
+	GetRoutingTable(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RoutingTable, error)
 }
 
 type daemonClient struct {
@@ -210,6 +217,26 @@ func (c *daemonClient) WaitForAgentIP(ctx context.Context, in *WaitForAgentIPReq
 	return out, nil
 }
 
+func (c *daemonClient) GetDialActivity(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DialActivity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DialActivity)
+	err := c.cc.Invoke(ctx, Daemon_GetDialActivity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) GetRoutingTable(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RoutingTable, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RoutingTable)
+	err := c.cc.Invoke(ctx, Daemon_GetRoutingTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DaemonServer is the server API for Daemon service.
 // All implementations must embed UnimplementedDaemonServer
 // for forward compatibility.
@@ -243,6 +270,11 @@ type DaemonServer interface {
 	WaitForNetwork(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	// WaitForAgentIP waits for the network of an intercepted agent to become ready.
 	WaitForAgentIP(context.Context, *WaitForAgentIPRequest) (*WaitForAgentIPResponse, error)
+	// GetDialActivity returns the time of the most recent dial request that the root daemon
+	// received from a traffic-agent, for each destination.
+	GetDialActivity(context.Context, *emptypb.Empty) (*DialActivity, error)
+	// GetRoutingTable returns the effective routing table of the currently connected session.
+	GetRoutingTable(context.Context, *emptypb.Empty) (*RoutingTable, error)
 	mustEmbedUnimplementedDaemonServer()
 }
 
@@ -292,6 +324,12 @@ func (UnimplementedDaemonServer) WaitForNetwork(context.Context, *emptypb.Empty)
 func (UnimplementedDaemonServer) WaitForAgentIP(context.Context, *WaitForAgentIPRequest) (*WaitForAgentIPResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method WaitForAgentIP not implemented")
 }
+func (UnimplementedDaemonServer) GetDialActivity(context.Context, *emptypb.Empty) (*DialActivity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDialActivity not implemented")
+}
+func (UnimplementedDaemonServer) GetRoutingTable(context.Context, *emptypb.Empty) (*RoutingTable, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoutingTable not implemented")
+}
 func (UnimplementedDaemonServer) mustEmbedUnimplementedDaemonServer() {}
 func (UnimplementedDaemonServer) testEmbeddedByValue()                {}
 
@@ -547,6 +585,42 @@ func _Daemon_WaitForAgentIP_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Daemon_GetDialActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetDialActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetDialActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetDialActivity(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_GetRoutingTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetRoutingTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetRoutingTable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetRoutingTable(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Daemon_ServiceDesc is the grpc.ServiceDesc for Daemon service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -606,6 +680,14 @@ var Daemon_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "WaitForAgentIP",
 			Handler:    _Daemon_WaitForAgentIP_Handler,
 		},
+		{
+			MethodName: "GetDialActivity",
+			Handler:    _Daemon_GetDialActivity_Handler,
+		},
+		{
+			MethodName: "GetRoutingTable",
+			Handler:    _Daemon_GetRoutingTable_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "daemon/daemon.proto",
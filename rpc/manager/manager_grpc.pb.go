@@ -24,41 +24,45 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Manager_Version_FullMethodName                   = "/telepresence.manager.Manager/Version"
-	Manager_GetAgentImageFQN_FullMethodName          = "/telepresence.manager.Manager/GetAgentImageFQN"
-	Manager_GetAgentConfig_FullMethodName            = "/telepresence.manager.Manager/GetAgentConfig"
-	Manager_GetLicense_FullMethodName                = "/telepresence.manager.Manager/GetLicense"
-	Manager_CanConnectAmbassadorCloud_FullMethodName = "/telepresence.manager.Manager/CanConnectAmbassadorCloud"
-	Manager_GetCloudConfig_FullMethodName            = "/telepresence.manager.Manager/GetCloudConfig"
-	Manager_GetClientConfig_FullMethodName           = "/telepresence.manager.Manager/GetClientConfig"
-	Manager_GetTelepresenceAPI_FullMethodName        = "/telepresence.manager.Manager/GetTelepresenceAPI"
-	Manager_ArriveAsClient_FullMethodName            = "/telepresence.manager.Manager/ArriveAsClient"
-	Manager_ArriveAsAgent_FullMethodName             = "/telepresence.manager.Manager/ArriveAsAgent"
-	Manager_Remain_FullMethodName                    = "/telepresence.manager.Manager/Remain"
-	Manager_Depart_FullMethodName                    = "/telepresence.manager.Manager/Depart"
-	Manager_SetLogLevel_FullMethodName               = "/telepresence.manager.Manager/SetLogLevel"
-	Manager_GetLogs_FullMethodName                   = "/telepresence.manager.Manager/GetLogs"
-	Manager_WatchAgentPods_FullMethodName            = "/telepresence.manager.Manager/WatchAgentPods"
-	Manager_WatchAgents_FullMethodName               = "/telepresence.manager.Manager/WatchAgents"
-	Manager_WatchAgentsNS_FullMethodName             = "/telepresence.manager.Manager/WatchAgentsNS"
-	Manager_WatchIntercepts_FullMethodName           = "/telepresence.manager.Manager/WatchIntercepts"
-	Manager_WatchWorkloads_FullMethodName            = "/telepresence.manager.Manager/WatchWorkloads"
-	Manager_WatchClusterInfo_FullMethodName          = "/telepresence.manager.Manager/WatchClusterInfo"
-	Manager_EnsureAgent_FullMethodName               = "/telepresence.manager.Manager/EnsureAgent"
-	Manager_PrepareIntercept_FullMethodName          = "/telepresence.manager.Manager/PrepareIntercept"
-	Manager_CreateIntercept_FullMethodName           = "/telepresence.manager.Manager/CreateIntercept"
-	Manager_RemoveIntercept_FullMethodName           = "/telepresence.manager.Manager/RemoveIntercept"
-	Manager_UpdateIntercept_FullMethodName           = "/telepresence.manager.Manager/UpdateIntercept"
-	Manager_GetIntercept_FullMethodName              = "/telepresence.manager.Manager/GetIntercept"
-	Manager_ReviewIntercept_FullMethodName           = "/telepresence.manager.Manager/ReviewIntercept"
-	Manager_GetKnownWorkloadKinds_FullMethodName     = "/telepresence.manager.Manager/GetKnownWorkloadKinds"
-	Manager_LookupDNS_FullMethodName                 = "/telepresence.manager.Manager/LookupDNS"
-	Manager_AgentLookupDNSResponse_FullMethodName    = "/telepresence.manager.Manager/AgentLookupDNSResponse"
-	Manager_WatchLookupDNS_FullMethodName            = "/telepresence.manager.Manager/WatchLookupDNS"
-	Manager_WatchLogLevel_FullMethodName             = "/telepresence.manager.Manager/WatchLogLevel"
-	Manager_Tunnel_FullMethodName                    = "/telepresence.manager.Manager/Tunnel"
-	Manager_ReportMetrics_FullMethodName             = "/telepresence.manager.Manager/ReportMetrics"
-	Manager_WatchDial_FullMethodName                 = "/telepresence.manager.Manager/WatchDial"
+	Manager_Version_FullMethodName                     = "/telepresence.manager.Manager/Version"
+	Manager_GetAgentImageFQN_FullMethodName            = "/telepresence.manager.Manager/GetAgentImageFQN"
+	Manager_GetAgentConfig_FullMethodName              = "/telepresence.manager.Manager/GetAgentConfig"
+	Manager_GetLicense_FullMethodName                  = "/telepresence.manager.Manager/GetLicense"
+	Manager_CanConnectAmbassadorCloud_FullMethodName   = "/telepresence.manager.Manager/CanConnectAmbassadorCloud"
+	Manager_GetCloudConfig_FullMethodName              = "/telepresence.manager.Manager/GetCloudConfig"
+	Manager_GetClientConfig_FullMethodName             = "/telepresence.manager.Manager/GetClientConfig"
+	Manager_GetAgentEnv_FullMethodName                 = "/telepresence.manager.Manager/GetAgentEnv"
+	Manager_GetWorkloadInterceptHistory_FullMethodName = "/telepresence.manager.Manager/GetWorkloadInterceptHistory"
+	Manager_GetTelepresenceAPI_FullMethodName          = "/telepresence.manager.Manager/GetTelepresenceAPI"
+	Manager_ArriveAsClient_FullMethodName              = "/telepresence.manager.Manager/ArriveAsClient"
+	Manager_ArriveAsAgent_FullMethodName               = "/telepresence.manager.Manager/ArriveAsAgent"
+	Manager_Remain_FullMethodName                      = "/telepresence.manager.Manager/Remain"
+	Manager_Depart_FullMethodName                      = "/telepresence.manager.Manager/Depart"
+	Manager_ListClients_FullMethodName                 = "/telepresence.manager.Manager/ListClients"
+	Manager_SetLogLevel_FullMethodName                 = "/telepresence.manager.Manager/SetLogLevel"
+	Manager_GetLogs_FullMethodName                     = "/telepresence.manager.Manager/GetLogs"
+	Manager_WatchAgentPods_FullMethodName              = "/telepresence.manager.Manager/WatchAgentPods"
+	Manager_WatchAgents_FullMethodName                 = "/telepresence.manager.Manager/WatchAgents"
+	Manager_WatchAgentsNS_FullMethodName               = "/telepresence.manager.Manager/WatchAgentsNS"
+	Manager_GetAgentVersions_FullMethodName            = "/telepresence.manager.Manager/GetAgentVersions"
+	Manager_WatchIntercepts_FullMethodName             = "/telepresence.manager.Manager/WatchIntercepts"
+	Manager_WatchWorkloads_FullMethodName              = "/telepresence.manager.Manager/WatchWorkloads"
+	Manager_WatchClusterInfo_FullMethodName            = "/telepresence.manager.Manager/WatchClusterInfo"
+	Manager_EnsureAgent_FullMethodName                 = "/telepresence.manager.Manager/EnsureAgent"
+	Manager_PrepareIntercept_FullMethodName            = "/telepresence.manager.Manager/PrepareIntercept"
+	Manager_CreateIntercept_FullMethodName             = "/telepresence.manager.Manager/CreateIntercept"
+	Manager_RemoveIntercept_FullMethodName             = "/telepresence.manager.Manager/RemoveIntercept"
+	Manager_UpdateIntercept_FullMethodName             = "/telepresence.manager.Manager/UpdateIntercept"
+	Manager_GetIntercept_FullMethodName                = "/telepresence.manager.Manager/GetIntercept"
+	Manager_ReviewIntercept_FullMethodName             = "/telepresence.manager.Manager/ReviewIntercept"
+	Manager_GetKnownWorkloadKinds_FullMethodName       = "/telepresence.manager.Manager/GetKnownWorkloadKinds"
+	Manager_LookupDNS_FullMethodName                   = "/telepresence.manager.Manager/LookupDNS"
+	Manager_AgentLookupDNSResponse_FullMethodName      = "/telepresence.manager.Manager/AgentLookupDNSResponse"
+	Manager_WatchLookupDNS_FullMethodName              = "/telepresence.manager.Manager/WatchLookupDNS"
+	Manager_WatchLogLevel_FullMethodName               = "/telepresence.manager.Manager/WatchLogLevel"
+	Manager_Tunnel_FullMethodName                      = "/telepresence.manager.Manager/Tunnel"
+	Manager_ReportMetrics_FullMethodName               = "/telepresence.manager.Manager/ReportMetrics"
+	Manager_WatchDial_FullMethodName                   = "/telepresence.manager.Manager/WatchDial"
 )
 
 // ManagerClient is the client API for Manager service.
@@ -82,6 +86,12 @@ type ManagerClient interface {
 	GetCloudConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*AmbassadorCloudConfig, error)
 	// GetClientConfig returns the config that connected clients should use for this manager.
 	GetClientConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CLIConfig, error)
+	// GetAgentEnv returns the settings that the traffic-manager applies to the
+	// environment of intercepted containers before it's passed to the clients.
+	GetAgentEnv(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (*AgentEnv, error)
+	// GetWorkloadInterceptHistory returns the intercepts of a workload that the
+	// traffic-manager has recorded, regardless of what client created them.
+	GetWorkloadInterceptHistory(ctx context.Context, in *WorkloadInterceptHistoryRequest, opts ...grpc.CallOption) (*WorkloadInterceptHistory, error)
 	// GetTelepresenceAPI returns information about the TelepresenceAPI server
 	GetTelepresenceAPI(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TelepresenceAPIInfo, error)
 	// ArriveAsClient establishes a session between a client and the Manager.
@@ -93,6 +103,9 @@ type ManagerClient interface {
 	Remain(ctx context.Context, in *RemainRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Depart terminates a session.
 	Depart(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListClients returns the clients that have a session with the manager. The
+	// caller must be a client with a session.
+	ListClients(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (*ConnectedClients, error)
 	// SetLogLevel will temporarily set the log-level for the traffic-manager and all
 	// traffic-agents for a duration that is determined b the request.
 	SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
@@ -111,6 +124,10 @@ type ManagerClient interface {
 	WatchAgents(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentInfoSnapshot], error)
 	// WatchAgentsNS is like WatchAgents but limited to a set of namespaces
 	WatchAgentsNS(ctx context.Context, in *AgentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentInfoSnapshot], error)
+	// GetAgentVersions returns the name and version of the known agents in the
+	// namespaces of the request. It's a lightweight alternative to WatchAgentsNS
+	// for clients that need nothing else.
+	GetAgentVersions(ctx context.Context, in *AgentsRequest, opts ...grpc.CallOption) (*AgentVersions, error)
 	// WatchIntercepts notifies a client or agent of the set of intercepts
 	// relevant to that client or agent.
 	//
@@ -257,6 +274,26 @@ func (c *managerClient) GetClientConfig(ctx context.Context, in *emptypb.Empty,
 	return out, nil
 }
 
+func (c *managerClient) GetAgentEnv(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (*AgentEnv, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentEnv)
+	err := c.cc.Invoke(ctx, Manager_GetAgentEnv_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managerClient) GetWorkloadInterceptHistory(ctx context.Context, in *WorkloadInterceptHistoryRequest, opts ...grpc.CallOption) (*WorkloadInterceptHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WorkloadInterceptHistory)
+	err := c.cc.Invoke(ctx, Manager_GetWorkloadInterceptHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *managerClient) GetTelepresenceAPI(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TelepresenceAPIInfo, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TelepresenceAPIInfo)
@@ -307,6 +344,16 @@ func (c *managerClient) Depart(ctx context.Context, in *SessionInfo, opts ...grp
 	return out, nil
 }
 
+func (c *managerClient) ListClients(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (*ConnectedClients, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConnectedClients)
+	err := c.cc.Invoke(ctx, Manager_ListClients_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *managerClient) SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -384,6 +431,16 @@ func (c *managerClient) WatchAgentsNS(ctx context.Context, in *AgentsRequest, op
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Manager_WatchAgentsNSClient = grpc.ServerStreamingClient[AgentInfoSnapshot]
 
+func (c *managerClient) GetAgentVersions(ctx context.Context, in *AgentsRequest, opts ...grpc.CallOption) (*AgentVersions, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentVersions)
+	err := c.cc.Invoke(ctx, Manager_GetAgentVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *managerClient) WatchIntercepts(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (grpc.ServerStreamingClient[InterceptInfoSnapshot], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &Manager_ServiceDesc.Streams[3], Manager_WatchIntercepts_FullMethodName, cOpts...)
@@ -642,6 +699,12 @@ type ManagerServer interface {
 	GetCloudConfig(context.Context, *emptypb.Empty) (*AmbassadorCloudConfig, error)
 	// GetClientConfig returns the config that connected clients should use for this manager.
 	GetClientConfig(context.Context, *emptypb.Empty) (*CLIConfig, error)
+	// GetAgentEnv returns the settings that the traffic-manager applies to the
+	// environment of intercepted containers before it's passed to the clients.
+	GetAgentEnv(context.Context, *SessionInfo) (*AgentEnv, error)
+	// GetWorkloadInterceptHistory returns the intercepts of a workload that the
+	// traffic-manager has recorded, regardless of what client created them.
+	GetWorkloadInterceptHistory(context.Context, *WorkloadInterceptHistoryRequest) (*WorkloadInterceptHistory, error)
 	// GetTelepresenceAPI returns information about the TelepresenceAPI server
 	GetTelepresenceAPI(context.Context, *emptypb.Empty) (*TelepresenceAPIInfo, error)
 	// ArriveAsClient establishes a session between a client and the Manager.
@@ -653,6 +716,9 @@ type ManagerServer interface {
 	Remain(context.Context, *RemainRequest) (*emptypb.Empty, error)
 	// Depart terminates a session.
 	Depart(context.Context, *SessionInfo) (*emptypb.Empty, error)
+	// ListClients returns the clients that have a session with the manager. The
+	// caller must be a client with a session.
+	ListClients(context.Context, *SessionInfo) (*ConnectedClients, error)
 	// SetLogLevel will temporarily set the log-level for the traffic-manager and all
 	// traffic-agents for a duration that is determined b the request.
 	SetLogLevel(context.Context, *LogLevelRequest) (*emptypb.Empty, error)
@@ -671,6 +737,10 @@ type ManagerServer interface {
 	WatchAgents(*SessionInfo, grpc.ServerStreamingServer[AgentInfoSnapshot]) error
 	// WatchAgentsNS is like WatchAgents but limited to a set of namespaces
 	WatchAgentsNS(*AgentsRequest, grpc.ServerStreamingServer[AgentInfoSnapshot]) error
+	// GetAgentVersions returns the name and version of the known agents in the
+	// namespaces of the request. It's a lightweight alternative to WatchAgentsNS
+	// for clients that need nothing else.
+	GetAgentVersions(context.Context, *AgentsRequest) (*AgentVersions, error)
 	// WatchIntercepts notifies a client or agent of the set of intercepts
 	// relevant to that client or agent.
 	//
@@ -768,6 +838,12 @@ func (UnimplementedManagerServer) GetCloudConfig(context.Context, *emptypb.Empty
 func (UnimplementedManagerServer) GetClientConfig(context.Context, *emptypb.Empty) (*CLIConfig, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetClientConfig not implemented")
 }
+func (UnimplementedManagerServer) GetAgentEnv(context.Context, *SessionInfo) (*AgentEnv, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAgentEnv not implemented")
+}
+func (UnimplementedManagerServer) GetWorkloadInterceptHistory(context.Context, *WorkloadInterceptHistoryRequest) (*WorkloadInterceptHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkloadInterceptHistory not implemented")
+}
 func (UnimplementedManagerServer) GetTelepresenceAPI(context.Context, *emptypb.Empty) (*TelepresenceAPIInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTelepresenceAPI not implemented")
 }
@@ -783,6 +859,9 @@ func (UnimplementedManagerServer) Remain(context.Context, *RemainRequest) (*empt
 func (UnimplementedManagerServer) Depart(context.Context, *SessionInfo) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Depart not implemented")
 }
+func (UnimplementedManagerServer) ListClients(context.Context, *SessionInfo) (*ConnectedClients, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
+}
 func (UnimplementedManagerServer) SetLogLevel(context.Context, *LogLevelRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
 }
@@ -798,6 +877,9 @@ func (UnimplementedManagerServer) WatchAgents(*SessionInfo, grpc.ServerStreaming
 func (UnimplementedManagerServer) WatchAgentsNS(*AgentsRequest, grpc.ServerStreamingServer[AgentInfoSnapshot]) error {
 	return status.Errorf(codes.Unimplemented, "method WatchAgentsNS not implemented")
 }
+func (UnimplementedManagerServer) GetAgentVersions(context.Context, *AgentsRequest) (*AgentVersions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAgentVersions not implemented")
+}
 func (UnimplementedManagerServer) WatchIntercepts(*SessionInfo, grpc.ServerStreamingServer[InterceptInfoSnapshot]) error {
 	return status.Errorf(codes.Unimplemented, "method WatchIntercepts not implemented")
 }
@@ -999,6 +1081,42 @@ func _Manager_GetClientConfig_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Manager_GetAgentEnv_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).GetAgentEnv(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_GetAgentEnv_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).GetAgentEnv(ctx, req.(*SessionInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Manager_GetWorkloadInterceptHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorkloadInterceptHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).GetWorkloadInterceptHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_GetWorkloadInterceptHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).GetWorkloadInterceptHistory(ctx, req.(*WorkloadInterceptHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Manager_GetTelepresenceAPI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -1089,6 +1207,24 @@ func _Manager_Depart_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Manager_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).ListClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_ListClients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).ListClients(ctx, req.(*SessionInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Manager_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LogLevelRequest)
 	if err := dec(in); err != nil {
@@ -1158,6 +1294,24 @@ func _Manager_WatchAgentsNS_Handler(srv interface{}, stream grpc.ServerStream) e
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Manager_WatchAgentsNSServer = grpc.ServerStreamingServer[AgentInfoSnapshot]
 
+func _Manager_GetAgentVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).GetAgentVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_GetAgentVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).GetAgentVersions(ctx, req.(*AgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Manager_WatchIntercepts_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(SessionInfo)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1464,6 +1618,14 @@ var Manager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetClientConfig",
 			Handler:    _Manager_GetClientConfig_Handler,
 		},
+		{
+			MethodName: "GetAgentEnv",
+			Handler:    _Manager_GetAgentEnv_Handler,
+		},
+		{
+			MethodName: "GetWorkloadInterceptHistory",
+			Handler:    _Manager_GetWorkloadInterceptHistory_Handler,
+		},
 		{
 			MethodName: "GetTelepresenceAPI",
 			Handler:    _Manager_GetTelepresenceAPI_Handler,
@@ -1484,6 +1646,10 @@ var Manager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Depart",
 			Handler:    _Manager_Depart_Handler,
 		},
+		{
+			MethodName: "ListClients",
+			Handler:    _Manager_ListClients_Handler,
+		},
 		{
 			MethodName: "SetLogLevel",
 			Handler:    _Manager_SetLogLevel_Handler,
@@ -1492,6 +1658,10 @@ var Manager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLogs",
 			Handler:    _Manager_GetLogs_Handler,
 		},
+		{
+			MethodName: "GetAgentVersions",
+			Handler:    _Manager_GetAgentVersions_Handler,
+		},
 		{
 			MethodName: "EnsureAgent",
 			Handler:    _Manager_EnsureAgent_Handler,